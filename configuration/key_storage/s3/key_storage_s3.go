@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"path"
 	"strings"
 
@@ -13,6 +12,7 @@ import (
 	jwt "github.com/dgrijalva/jwt-go"
 	s3Storage "github.com/madappgang/identifo/external_services/storage/s3"
 	ijwt "github.com/madappgang/identifo/jwt"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 )
 
@@ -48,7 +48,7 @@ func (ks *KeyStorage) InsertKeys(keys *model.JWTKeys) error {
 		ks.PrivateKeyPath: keys.Private,
 		ks.PublicKeyPath:  keys.Public,
 	}
-	log.Println("Putting new keys to S3...")
+	logging.Info("Putting new keys to S3...")
 
 	for name, file := range keysMap {
 		reader, ok := file.(io.ReadSeeker)
@@ -65,7 +65,7 @@ func (ks *KeyStorage) InsertKeys(keys *model.JWTKeys) error {
 			ContentType:  aws.String("application/x-pem-file"),
 		})
 		if err == nil {
-			log.Printf("Successfully put %s to S3\n", name)
+			logging.Infof("Successfully put %s to S3\n", name)
 		}
 	}
 	return nil
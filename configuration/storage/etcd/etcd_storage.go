@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"reflect"
 	"time"
 
@@ -12,6 +11,7 @@ import (
 	keyStorageS3 "github.com/madappgang/identifo/configuration/key_storage/s3"
 	configStorageFile "github.com/madappgang/identifo/configuration/storage/file"
 	ijwt "github.com/madappgang/identifo/jwt"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	"go.etcd.io/etcd/clientv3"
 )
@@ -153,15 +153,15 @@ func (cs *ConfigurationStorage) idleInsertConfig() {
 	key := cs.settingsKey
 	settings := new(model.ServerSettings)
 	if err := cs.LoadServerSettings(settings); err != nil {
-		log.Println("Error while idle config insert: could not load server settings.", err)
+		logging.Error("Error while idle config insert: could not load server settings.", err)
 		return
 	}
 	if key == "" {
-		log.Println("Error while idle config insert: empty key.")
+		logging.Error("Error while idle config insert: empty key.")
 		return
 	}
 	if err := cs.InsertConfig(key, settings); err != nil {
-		log.Println("Error while idle config insert.", err)
+		logging.Error("Error while idle config insert.", err)
 		return
 	}
 }
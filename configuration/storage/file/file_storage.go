@@ -3,13 +3,13 @@ package file
 import (
 	"fmt"
 	"io/ioutil"
-	"log"
 	"os"
 	"path/filepath"
 
 	keyStorageLocal "github.com/madappgang/identifo/configuration/key_storage/local"
 	keyStorageS3 "github.com/madappgang/identifo/configuration/key_storage/s3"
 	ijwt "github.com/madappgang/identifo/jwt"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	"gopkg.in/yaml.v2"
 )
@@ -60,7 +60,7 @@ func (cs *ConfigurationStorage) InsertConfig(key string, value interface{}) erro
 	// Indicate config update. To prevent writing to a closed channel, make a check.
 	go func() {
 		if cs.updateChanClosed {
-			log.Println("Attempted to write to closed UpdateChan")
+			logging.Warn("Attempted to write to closed UpdateChan")
 			return
 		}
 		cs.UpdateChan <- struct{}{}
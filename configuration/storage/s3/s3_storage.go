@@ -3,7 +3,6 @@ package s3
 import (
 	"bytes"
 	"fmt"
-	"log"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -11,6 +10,7 @@ import (
 	keyStorageS3 "github.com/madappgang/identifo/configuration/key_storage/s3"
 	s3Storage "github.com/madappgang/identifo/external_services/storage/s3"
 	ijwt "github.com/madappgang/identifo/jwt"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	"gopkg.in/yaml.v2"
 )
@@ -82,7 +82,7 @@ func (cs *ConfigurationStorage) LoadServerSettings(settings *model.ServerSetting
 
 // InsertConfig puts new configuration into the storage.
 func (cs *ConfigurationStorage) InsertConfig(key string, value interface{}) error {
-	log.Println("Putting new config to S3...")
+	logging.Info("Putting new config to S3...")
 
 	valueBytes, err := yaml.Marshal(value)
 	if err != nil {
@@ -100,13 +100,13 @@ func (cs *ConfigurationStorage) InsertConfig(key string, value interface{}) erro
 	})
 
 	if err == nil {
-		log.Println("Successfully put new configuration to S3")
+		logging.Info("Successfully put new configuration to S3")
 	}
 
 	// Indicate config update. To prevent writing to a closed channel, make a check.
 	go func() {
 		if cs.updateChanClosed {
-			log.Println("Attempted to write to closed UpdateChan")
+			logging.Warn("Attempted to write to closed UpdateChan")
 			return
 		}
 		cs.UpdateChan <- struct{}{}
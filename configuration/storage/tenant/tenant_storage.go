@@ -0,0 +1,59 @@
+package tenant
+
+import (
+	"errors"
+	"fmt"
+
+	keyStorageLocal "github.com/madappgang/identifo/configuration/key_storage/local"
+	keyStorageS3 "github.com/madappgang/identifo/configuration/key_storage/s3"
+	ijwt "github.com/madappgang/identifo/jwt"
+	"github.com/madappgang/identifo/model"
+)
+
+// ErrSettingsReadOnly is returned when tenant server tries to overwrite server settings.
+var ErrSettingsReadOnly = errors.New("Tenant settings are part of the default tenant server settings")
+
+// ConfigurationStorage is a tenant view of the shared configuration storage.
+// It keeps tenant keys in the tenant key storage, and leaves server settings to the default tenant.
+type ConfigurationStorage struct {
+	model.ConfigurationStorage
+	keyStorage model.KeyStorage
+}
+
+// NewConfigurationStorage creates and returns new tenant configuration storage.
+func NewConfigurationStorage(cs model.ConfigurationStorage, settings model.KeyStorageSettings) (*ConfigurationStorage, error) {
+	var keyStorage model.KeyStorage
+	var err error
+
+	switch settings.Type {
+	case model.KeyStorageTypeLocal:
+		keyStorage, err = keyStorageLocal.NewKeyStorage(settings)
+	case model.KeyStorageTypeS3:
+		keyStorage, err = keyStorageS3.NewKeyStorage(settings)
+	default:
+		return nil, fmt.Errorf("Unknown key storage type: %s", settings.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfigurationStorage{
+		ConfigurationStorage: cs,
+		keyStorage:           keyStorage,
+	}, nil
+}
+
+// InsertConfig refuses to change server settings.
+func (cs *ConfigurationStorage) InsertConfig(key string, value interface{}) error {
+	return ErrSettingsReadOnly
+}
+
+// InsertKeys inserts new public and private keys into the tenant key storage.
+func (cs *ConfigurationStorage) InsertKeys(keys *model.JWTKeys) error {
+	return cs.keyStorage.InsertKeys(keys)
+}
+
+// LoadKeys loads public and private keys from the tenant key storage.
+func (cs *ConfigurationStorage) LoadKeys(alg ijwt.TokenSignatureAlgorithm) (*model.JWTKeys, error) {
+	return cs.keyStorage.LoadKeys(alg)
+}
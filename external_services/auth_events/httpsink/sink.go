@@ -0,0 +1,45 @@
+package httpsink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/tracing"
+)
+
+// AuthEventSink posts authentication events as JSON to the configured URL.
+type AuthEventSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewAuthEventSink creates new HTTP sink.
+func NewAuthEventSink(settings model.AuthEventSinkSettings) *AuthEventSink {
+	return &AuthEventSink{
+		url:    settings.URL,
+		client: &http.Client{Timeout: 10 * time.Second, Transport: tracing.Transport(nil)},
+	}
+}
+
+// SendAuthEvent posts the event. Any non-2xx response is an error.
+func (s *AuthEventSink) SendAuthEvent(event model.AuthEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Auth event sink responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package stdout
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/madappgang/identifo/model"
+)
+
+// AuthEventSink writes authentication events as JSON lines, so they can be picked up by log collectors.
+type AuthEventSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewAuthEventSink creates new sink writing to stdout.
+func NewAuthEventSink() *AuthEventSink {
+	return &AuthEventSink{w: os.Stdout}
+}
+
+// SendAuthEvent writes the event as a single JSON line.
+func (s *AuthEventSink) SendAuthEvent(event model.AuthEvent) error {
+	line := struct {
+		Kind string `json:"kind"`
+		model.AuthEvent
+	}{
+		Kind:      "auth_event",
+		AuthEvent: event,
+	}
+
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,109 @@
+package authhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/madappgang/identifo/external_services/signature"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/tracing"
+)
+
+// hookHeaderKey header holds the hook type. Requests are signed with the hooks secret, see package signature.
+const hookHeaderKey = "X-Identifo-Hook"
+
+const defaultTimeout = 5 * time.Second
+
+// AuthHookService posts hook requests to the configured URL.
+type AuthHookService struct {
+	url          string
+	secret       string
+	hooks        map[model.AuthHookType]bool
+	allowOnError bool
+	client       *http.Client
+}
+
+// NewAuthHookService creates new auth hook service. Without URL all hooks are disabled.
+func NewAuthHookService(settings model.AuthHooksSettings) *AuthHookService {
+	timeout := time.Duration(settings.Timeout) * time.Millisecond
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	hooks := make(map[model.AuthHookType]bool)
+	for _, hook := range settings.Hooks {
+		hooks[hook] = true
+	}
+
+	return &AuthHookService{
+		url:          settings.URL,
+		secret:       settings.Secret,
+		hooks:        hooks,
+		allowOnError: settings.AllowOnError,
+		client:       &http.Client{Timeout: timeout, Transport: tracing.Transport(nil)},
+	}
+}
+
+// Enabled checks if the hook is configured.
+func (s *AuthHookService) Enabled(hook model.AuthHookType) bool {
+	if s.url == "" {
+		return false
+	}
+	return hook == model.AuthHookAuthorization || s.hooks[hook]
+}
+
+// Call posts the hook request. Failed requests deny the step, unless it is allowed on error.
+func (s *AuthHookService) Call(req model.AuthHookRequest) (model.AuthHookResponse, error) {
+	if !s.Enabled(req.Hook) {
+		return model.AuthHookResponse{Allow: true}, nil
+	}
+
+	resp, err := s.post(req)
+	if err != nil {
+		logging.Errorf("Auth hook %s failed: %s\n", req.Hook, err)
+		if s.allowOnError {
+			return model.AuthHookResponse{Allow: true}, nil
+		}
+		return model.AuthHookResponse{}, model.ErrAuthHookDenied
+	}
+
+	if !resp.Allow {
+		return resp, model.ErrAuthHookDenied
+	}
+	return resp, nil
+}
+
+func (s *AuthHookService) post(hookReq model.AuthHookRequest) (model.AuthHookResponse, error) {
+	var hookResp model.AuthHookResponse
+
+	body, err := json.Marshal(hookReq)
+	if err != nil {
+		return hookResp, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return hookResp, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	signature.SetHeaders(req, body, s.secret)
+	req.Header.Set(hookHeaderKey, string(hookReq.Hook))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return hookResp, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return hookResp, fmt.Errorf("Auth hook responded with status %d", resp.StatusCode)
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&hookResp); err != nil {
+		return hookResp, fmt.Errorf("Cannot decode auth hook response: %s", err)
+	}
+	return hookResp, nil
+}
@@ -0,0 +1,71 @@
+package authhooks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/madappgang/identifo/external_services/signature"
+	"github.com/madappgang/identifo/model"
+)
+
+func TestAuthHookCall(t *testing.T) {
+	const secret = "hook-secret"
+
+	var resp model.AuthHookResponse
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := signature.Verify(r.Header, body, secret, time.Now()); err != nil {
+			t.Errorf("signature err = %v, want nil", err)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	s := NewAuthHookService(model.AuthHooksSettings{
+		URL:    srv.URL,
+		Secret: secret,
+		Hooks:  []model.AuthHookType{model.AuthHookPreToken},
+	})
+
+	// Hook which is not configured is not called.
+	resp = model.AuthHookResponse{Allow: false}
+	if _, err := s.Call(model.AuthHookRequest{Hook: model.AuthHookPreLogin}); err != nil {
+		t.Errorf("disabled hook err = %v, want nil", err)
+	}
+	if _, err := s.Call(model.AuthHookRequest{Hook: model.AuthHookPreToken}); err != model.ErrAuthHookDenied {
+		t.Errorf("denied hook err = %v, want %v", err, model.ErrAuthHookDenied)
+	}
+
+	resp = model.AuthHookResponse{Allow: true, Claims: map[string]interface{}{"tier": "gold"}}
+	got, err := s.Call(model.AuthHookRequest{Hook: model.AuthHookPreToken})
+	if err != nil || got.Claims["tier"] != "gold" {
+		t.Errorf("allowed hook = %+v, %v", got, err)
+	}
+}
+
+func TestAuthHookCallError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	settings := model.AuthHooksSettings{
+		URL:    srv.URL,
+		Secret: "secret",
+		Hooks:  []model.AuthHookType{model.AuthHookPreLogin},
+	}
+	req := model.AuthHookRequest{Hook: model.AuthHookPreLogin}
+
+	if _, err := NewAuthHookService(settings).Call(req); err != model.ErrAuthHookDenied {
+		t.Errorf("err = %v, want %v", err, model.ErrAuthHookDenied)
+	}
+
+	settings.AllowOnError = true
+	if _, err := NewAuthHookService(settings).Call(req); err != nil {
+		t.Errorf("allow on error err = %v, want nil", err)
+	}
+}
@@ -3,12 +3,12 @@ package ses
 import (
 	"bytes"
 	"html/template"
-	"log"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 )
 
@@ -133,18 +133,18 @@ func logAWSError(err error) {
 
 	aerr, ok := err.(awserr.Error)
 	if !ok {
-		log.Println("Could not cast the error to AWS error:", err)
+		logging.Error("Could not cast the error to AWS error:", err)
 		return
 	}
 
 	switch aerr.Code() {
 	case ses.ErrCodeMessageRejected:
-		log.Println(ses.ErrCodeMessageRejected, aerr.Error())
+		logging.Error(ses.ErrCodeMessageRejected, aerr.Error())
 	case ses.ErrCodeMailFromDomainNotVerifiedException:
-		log.Println(ses.ErrCodeMailFromDomainNotVerifiedException, aerr.Error())
+		logging.Error(ses.ErrCodeMailFromDomainNotVerifiedException, aerr.Error())
 	case ses.ErrCodeConfigurationSetDoesNotExistException:
-		log.Println(ses.ErrCodeConfigurationSetDoesNotExistException, aerr.Error())
+		logging.Error(ses.ErrCodeConfigurationSetDoesNotExistException, aerr.Error())
 	default:
-		log.Println(aerr.Error())
+		logging.Error(aerr.Error())
 	}
 }
@@ -0,0 +1,69 @@
+// Package signature signs requests Identifo sends to auth hooks.
+//
+// Signature is a base64 encoded HMAC-SHA256 of the timestamp, a dot and the body, keyed with the shared secret.
+// It is sent in the Digest header with the SHA-256= prefix, the timestamp (Unix seconds) in the X-Identifo-Timestamp header.
+// Because the timestamp is signed, receivers should reject requests older than MaxAge, so captured requests cannot be replayed.
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request headers.
+const (
+	HeaderKey          = "Digest"
+	HeaderValuePrefix  = "SHA-256="
+	TimestampHeaderKey = "X-Identifo-Timestamp"
+)
+
+// MaxAge is the freshness window receivers are expected to enforce, in both directions to allow for clock skew.
+const MaxAge = 5 * time.Minute
+
+var (
+	// ErrInvalid means the signature does not match the request.
+	ErrInvalid = errors.New("Request signature is invalid")
+	// ErrExpired means the request timestamp is out of the freshness window.
+	ErrExpired = errors.New("Request timestamp is out of the freshness window")
+)
+
+// Sign returns signature of the body sent at the timestamp.
+func Sign(body []byte, timestamp int64, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SetHeaders signs the request body with the current time and sets signature and timestamp headers.
+func SetHeaders(req *http.Request, body []byte, secret string) {
+	now := time.Now().Unix()
+	req.Header.Set(HeaderKey, HeaderValuePrefix+Sign(body, now, secret))
+	req.Header.Set(TimestampHeaderKey, strconv.FormatInt(now, 10))
+}
+
+// Verify checks signature headers of the request with the body, as receivers should do.
+func Verify(header http.Header, body []byte, secret string, now time.Time) error {
+	timestamp, err := strconv.ParseInt(header.Get(TimestampHeaderKey), 10, 64)
+	if err != nil {
+		return ErrInvalid
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > MaxAge || age < -MaxAge {
+		return ErrExpired
+	}
+
+	value := header.Get(HeaderKey)
+	if !strings.HasPrefix(value, HeaderValuePrefix) {
+		return ErrInvalid
+	}
+	if !hmac.Equal([]byte(value[len(HeaderValuePrefix):]), []byte(Sign(body, timestamp, secret))) {
+		return ErrInvalid
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package signature
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "secret"
+	body := []byte(`{"event":"user.created"}`)
+	now := time.Now()
+
+	signed := func(timestamp int64) http.Header {
+		h := http.Header{}
+		h.Set(HeaderKey, HeaderValuePrefix+Sign(body, timestamp, secret))
+		h.Set(TimestampHeaderKey, strconv.FormatInt(timestamp, 10))
+		return h
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com", nil)
+	SetHeaders(req, body, secret)
+
+	replayed := signed(now.Add(-time.Hour).Unix())
+	replayed.Set(TimestampHeaderKey, strconv.FormatInt(now.Unix(), 10))
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		body     []byte
+		secret   string
+		expected error
+	}{
+		{"signed request", req.Header, body, secret, nil},
+		{"within the window", signed(now.Add(-MaxAge + time.Minute).Unix()), body, secret, nil},
+		{"expired", signed(now.Add(-MaxAge - time.Minute).Unix()), body, secret, ErrExpired},
+		{"from the future", signed(now.Add(MaxAge + time.Minute).Unix()), body, secret, ErrExpired},
+		{"timestamp replaced", replayed, body, secret, ErrInvalid},
+		{"body replaced", signed(now.Unix()), []byte(`{}`), secret, ErrInvalid},
+		{"another secret", signed(now.Unix()), body, "another", ErrInvalid},
+		{"no headers", http.Header{}, body, secret, ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.header, tt.body, tt.secret, now); err != tt.expected {
+				t.Errorf("Verify() = %v, expected %v", err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/tracing"
+	"github.com/rs/xid"
+)
+
+// Request headers. Signature uses the same scheme as signed API requests:
+// base64 encoded HMAC-SHA256 of the body, keyed with the webhook secret.
+const (
+	signatureHeaderKey         = "Digest"
+	signatureHeaderValuePrefix = "SHA-256="
+	timestampHeaderKey         = "X-Identifo-Timestamp"
+	eventHeaderKey             = "X-Identifo-Event"
+	deliveryHeaderKey          = "X-Identifo-Delivery"
+)
+
+const (
+	pollInterval   = 5 * time.Second
+	batchSize      = 100
+	maxAttempts    = 8
+	initialBackoff = 10 * time.Second
+	maxBackoff     = time.Hour
+)
+
+// Event is a payload sent to webhooks.
+// Event ID is the same for all webhooks notified about the event and can be used for deduplication.
+type Event struct {
+	ID        string                 `json:"id"`
+	Type      model.WebhookEventType `json:"type"`
+	AppID     string                 `json:"app_id,omitempty"`
+	CreatedAt int64                  `json:"created_at"`
+	User      User                   `json:"user"`
+}
+
+// User is a user data sent to webhooks.
+type User struct {
+	ID           string   `json:"id"`
+	Username     string   `json:"username,omitempty"`
+	Email        string   `json:"email,omitempty"`
+	Phone        string   `json:"phone,omitempty"`
+	FederatedIDs []string `json:"federated_ids,omitempty"`
+	AccessRole   string   `json:"access_role,omitempty"`
+	Active       bool     `json:"active"`
+	Anonymous    bool     `json:"anonymous"`
+}
+
+// Dispatcher is a default webhook service.
+// Deliveries are saved to the storage first and sent by the background worker, so they survive restarts.
+// Delivery is at least once: receivers should deduplicate events by ID.
+type Dispatcher struct {
+	webhookStorage model.WebhookStorage
+	userStorage    model.UserStorage
+	client         *http.Client
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewDispatcher creates new webhook dispatcher.
+// It also implements model.AuthEventSink, turning successful logins and registrations into webhook events.
+func NewDispatcher(ws model.WebhookStorage, us model.UserStorage) *Dispatcher {
+	return &Dispatcher{
+		webhookStorage: ws,
+		userStorage:    us,
+		client:         &http.Client{Timeout: 10 * time.Second, Transport: tracing.Transport(nil)},
+		wake:           make(chan struct{}, 1),
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+}
+
+// Notify saves deliveries of the event for all subscribed webhooks.
+func (d *Dispatcher) Notify(eventType model.WebhookEventType, appID string, user model.User) {
+	if user == nil {
+		return
+	}
+
+	webhooks, err := d.webhookStorage.FetchWebhooks()
+	if err != nil {
+		logging.Errorf("Cannot fetch webhooks for %s event: %s\n", eventType, err)
+		return
+	}
+
+	var payload []byte
+	now := time.Now().Unix()
+	queued := false
+
+	for _, webhook := range webhooks {
+		if !webhook.Subscribed(eventType, appID) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = newPayload(eventType, appID, user, now); err != nil {
+				logging.Errorf("Cannot encode %s event: %s\n", eventType, err)
+				return
+			}
+		}
+
+		if _, err := d.webhookStorage.AddWebhookDelivery(model.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     eventType,
+			Payload:       payload,
+			Status:        model.WebhookDeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}); err != nil {
+			logging.Errorf("Cannot save %s event delivery to webhook %s: %s\n", eventType, webhook.ID, err)
+			continue
+		}
+		queued = true
+	}
+
+	if queued {
+		d.signal()
+	}
+}
+
+// SendAuthEvent implements model.AuthEventSink.
+func (d *Dispatcher) SendAuthEvent(event model.AuthEvent) error {
+	if !event.Success || event.UserID == "" {
+		return nil
+	}
+
+	var eventType model.WebhookEventType
+	switch event.Type {
+	case model.AuthEventLogin:
+		eventType = model.WebhookEventUserLogin
+	case model.AuthEventRegistration:
+		eventType = model.WebhookEventUserCreated
+	default:
+		return nil
+	}
+
+	user, err := d.userStorage.UserByID(event.UserID)
+	if err != nil {
+		return err
+	}
+	d.Notify(eventType, event.AppID, user)
+	return nil
+}
+
+// Replay resets the delivery, so it is sent again with the full number of attempts.
+func (d *Dispatcher) Replay(deliveryID string) (model.WebhookDelivery, error) {
+	delivery, err := d.webhookStorage.WebhookDeliveryByID(deliveryID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	delivery.Status = model.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now().Unix()
+	delivery.DeliveredAt = 0
+	if err = d.webhookStorage.UpdateWebhookDelivery(delivery); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	d.signal()
+	return delivery, nil
+}
+
+// Start runs the delivery worker.
+func (d *Dispatcher) Start() {
+	go d.run()
+}
+
+// Stop stops the delivery worker and waits for the current attempt to finish.
+// Pending deliveries stay in the storage and are sent after the next start.
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+	<-d.done
+}
+
+func (d *Dispatcher) signal() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.deliverPending()
+
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+func (d *Dispatcher) deliverPending() {
+	deliveries, err := d.webhookStorage.PendingWebhookDeliveries(time.Now().Unix(), batchSize)
+	if err != nil {
+		logging.Error("Cannot fetch pending webhook deliveries:", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		select {
+		case <-d.stop:
+			return
+		default:
+		}
+
+		d.deliver(&delivery)
+		if err := d.webhookStorage.UpdateWebhookDelivery(delivery); err != nil {
+			logging.Errorf("Cannot update webhook delivery %s: %s\n", delivery.ID, err)
+		}
+	}
+}
+
+// deliver makes a delivery attempt and updates the delivery with its result.
+func (d *Dispatcher) deliver(delivery *model.WebhookDelivery) {
+	webhook, err := d.webhookStorage.WebhookByID(delivery.WebhookID)
+	if err == nil && !webhook.Active {
+		err = fmt.Errorf("Webhook is inactive")
+	}
+	if err != nil {
+		// Keep it for replay after the webhook is fixed.
+		delivery.Status = model.WebhookDeliveryDead
+		delivery.LastError = err.Error()
+		return
+	}
+
+	delivery.Attempts++
+	delivery.LastStatusCode, err = d.send(webhook, *delivery)
+	if err == nil {
+		delivery.Status = model.WebhookDeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = time.Now().Unix()
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= maxAttempts {
+		delivery.Status = model.WebhookDeliveryDead
+		return
+	}
+	delivery.NextAttemptAt = time.Now().Add(backoff(delivery.Attempts)).Unix()
+}
+
+// send posts signed payload to the webhook. Any non-2xx response is an error.
+func (d *Dispatcher) send(webhook model.Webhook, delivery model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(signatureHeaderKey, signatureHeaderValuePrefix+sign(delivery.Payload, webhook.Secret))
+	req.Header.Set(timestampHeaderKey, strconv.FormatInt(time.Now().Unix(), 10))
+	req.Header.Set(eventHeaderKey, string(delivery.EventType))
+	req.Header.Set(deliveryHeaderKey, delivery.ID)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("Webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns delay before the next attempt, doubling with every failed one.
+func backoff(attempts int) time.Duration {
+	delay := initialBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+func sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newPayload(eventType model.WebhookEventType, appID string, user model.User, createdAt int64) ([]byte, error) {
+	return json.Marshal(Event{
+		ID:        xid.New().String(),
+		Type:      eventType,
+		AppID:     appID,
+		CreatedAt: createdAt,
+		User: User{
+			ID:           user.ID(),
+			Username:     user.Username(),
+			Email:        user.Email(),
+			Phone:        user.Phone(),
+			FederatedIDs: user.FederatedIDs(),
+			AccessRole:   user.AccessRole(),
+			Active:       user.Active(),
+			Anonymous:    user.IsAnonymous(),
+		},
+	})
+}
@@ -0,0 +1,110 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/storage/mem"
+)
+
+func TestDispatcherDelivery(t *testing.T) {
+	const secret = "webhook-secret"
+
+	status := http.StatusOK
+	received := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		expected := signatureHeaderValuePrefix + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		if r.Header.Get(signatureHeaderKey) != expected {
+			t.Errorf("signature = %q, want %q", r.Header.Get(signatureHeaderKey), expected)
+		}
+		if !strings.Contains(string(body), `"type":"user.created"`) {
+			t.Errorf("unexpected payload %s", body)
+		}
+		received++
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	ws, _ := mem.NewWebhookStorage()
+	us, _ := mem.NewUserStorage()
+	webhook, _ := ws.AddWebhook(model.Webhook{URL: srv.URL, Secret: secret, Active: true})
+	user, _ := us.AddUserByNameAndPassword("john", "Password1!", "user", false)
+
+	d := NewDispatcher(ws, us)
+
+	// Failed attempt is rescheduled with back-off.
+	status = http.StatusInternalServerError
+	d.Notify(model.WebhookEventUserCreated, "", user)
+	d.deliverPending()
+
+	deliveries, total, _ := ws.FetchWebhookDeliveries(model.WebhookDeliveryFilter{WebhookID: webhook.ID}, 0, 0)
+	if total != 1 {
+		t.Fatalf("deliveries = %d, want 1", total)
+	}
+	failed := deliveries[0]
+	if failed.Status != model.WebhookDeliveryPending || failed.Attempts != 1 || failed.LastStatusCode != status {
+		t.Fatalf("unexpected failed delivery %+v", failed)
+	}
+	if failed.NextAttemptAt < time.Now().Add(initialBackoff).Unix()-1 {
+		t.Errorf("next attempt at %d is not backed off", failed.NextAttemptAt)
+	}
+
+	// Replay makes it due immediately.
+	status = http.StatusOK
+	if _, err := d.Replay(failed.ID); err != nil {
+		t.Fatal(err)
+	}
+	d.deliverPending()
+
+	delivered, _ := ws.WebhookDeliveryByID(failed.ID)
+	if delivered.Status != model.WebhookDeliveryDelivered || delivered.DeliveredAt == 0 {
+		t.Fatalf("unexpected delivery %+v", delivered)
+	}
+	if received != 2 {
+		t.Errorf("received = %d, want 2", received)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, initialBackoff},
+		{2, 2 * initialBackoff},
+		{4, 8 * initialBackoff},
+		{20, maxBackoff},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// TestSign checks webhook signatures against the vectors signed API requests are validated with,
+// see web/api/appsecret_test.go, so receivers can verify both the same way.
+func TestSign(t *testing.T) {
+	tests := []struct {
+		body, secret, want string
+	}{
+		{"test", "secret", "Aymga2LNFrM+tnkr6MYLFY2Jou46h2/Omogeu0iMCRQ="},
+		{"test2", "secret", "9TpPNnsorxe8U99HeujuJZCxhfQ51Yz9oD7PBWs/Yjs="},
+	}
+	for _, tt := range tests {
+		if got := sign([]byte(tt.body), tt.secret); got != tt.want {
+			t.Errorf("sign(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
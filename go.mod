@@ -21,7 +21,7 @@ require (
 	github.com/mailgun/mailgun-go v1.1.1
 	github.com/njern/gonexmo v2.0.0+incompatible
 	github.com/pallinder/go-randomdata v1.2.0
-	github.com/qiangmzsx/string-adapter v0.0.0-20180323073508-38f25303bb0c
+	github.com/prometheus/client_golang v1.7.0
 	github.com/rs/cors v1.6.0
 	github.com/rs/xid v1.2.1
 	github.com/satori/go.uuid v1.2.0 // indirect
@@ -30,13 +30,15 @@ require (
 	github.com/xlzd/gotp v0.0.0-20181030022105-c8557ba2c119
 	go.etcd.io/etcd v3.3.13+incompatible
 	go.mongodb.org/mongo-driver v1.3.0
+	go.opentelemetry.io/otel v0.20.0
+	go.opentelemetry.io/otel/sdk v0.20.0
+	go.opentelemetry.io/otel/trace v0.20.0
 	golang.org/x/crypto v0.0.0-20190701094942-4def268fd1a4
 	golang.org/x/net v0.0.0-20190724013045-ca1201d0de80 // indirect
-	golang.org/x/sys v0.0.0-20190712062909-fae7ac547cb7 // indirect
 	google.golang.org/appengine v1.4.0 // indirect
 	google.golang.org/genproto v0.0.0-20190716160619-c506a9f90610 // indirect
 	google.golang.org/grpc v1.22.0 // indirect
 	gopkg.in/go-playground/validator.v9 v9.29.1
 	gopkg.in/njern/gonexmo.v2 v2.0.0 // indirect
-	gopkg.in/yaml.v2 v2.2.2
+	gopkg.in/yaml.v2 v2.2.5
 )
@@ -0,0 +1,233 @@
+// Package health checks the dependencies of the server and serves liveness and readiness endpoints.
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+)
+
+const (
+	// DefaultTimeout is a timeout of each check, when it is not set.
+	DefaultTimeout = 2 * time.Second
+	// DefaultCacheDuration is how long check results are reused, when it is not set.
+	DefaultCacheDuration = 5 * time.Second
+)
+
+const (
+	// LivenessPath is a path of the liveness endpoint.
+	LivenessPath = "/healthz"
+	// ReadinessPath is a path of the readiness endpoint.
+	ReadinessPath = "/readyz"
+)
+
+// Statuses of the components and of the server.
+const (
+	StatusOK       = "ok"
+	StatusFailed   = "failed"
+	StatusDegraded = "degraded"
+)
+
+var (
+	// ErrTimeout means the check did not finish in time.
+	ErrTimeout = errors.New("Check timed out")
+	// ErrCheckRunning means the previous check has not finished yet, so a new one is not started.
+	ErrCheckRunning = errors.New("Previous check is still running")
+)
+
+// Check checks one dependency, returning error if it is not available.
+type Check func() error
+
+// Report is the result of the checks.
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+}
+
+// ComponentStatus is the result of the check of one dependency.
+// Errors are logged, not reported, as the endpoints are public.
+type ComponentStatus struct {
+	Status     string  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// Checker runs the checks in parallel, each with a timeout, and caches their results.
+type Checker struct {
+	timeout       time.Duration
+	cacheDuration time.Duration
+	logger        *logging.Logger
+
+	// mu guards the components and their running flags.
+	mu         sync.Mutex
+	components []*component
+
+	// reportMu serializes the runs and guards the cached report.
+	reportMu sync.Mutex
+	report   *Report
+}
+
+type component struct {
+	name  string
+	check Check
+
+	// running is set while the check runs, so hung checks do not pile up.
+	running bool
+	// failed is the last result, to log changes only. It is guarded by reportMu.
+	failed bool
+}
+
+// NewChecker creates checker with the settings.
+func NewChecker(settings model.HealthSettings, logger *logging.Logger) *Checker {
+	c := &Checker{
+		timeout:       DefaultTimeout,
+		cacheDuration: DefaultCacheDuration,
+		logger:        logger,
+	}
+	if settings.Timeout > 0 {
+		c.timeout = time.Duration(settings.Timeout) * time.Millisecond
+	}
+	if settings.CacheDuration > 0 {
+		c.cacheDuration = time.Duration(settings.CacheDuration) * time.Millisecond
+	}
+	if c.logger == nil {
+		c.logger = logging.Default()
+	}
+	return c
+}
+
+// Add adds the check of the component.
+func (c *Checker) Add(name string, check Check) {
+	c.reportMu.Lock()
+	defer c.reportMu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.components = append(c.components, &component{name: name, check: check})
+	c.report = nil
+}
+
+// AddStorage adds the check of the storage, if it can test its database connection.
+func (c *Checker) AddStorage(name string, storage interface{}) {
+	if tester, ok := storage.(model.ConnectionTester); ok {
+		c.Add(name, tester.TestDatabaseConnection)
+	}
+}
+
+// Report returns results of the checks, running them if the cached ones are stale.
+// Concurrent callers wait for the same run.
+func (c *Checker) Report() Report {
+	c.reportMu.Lock()
+	defer c.reportMu.Unlock()
+
+	if c.report != nil && time.Since(c.report.CheckedAt) < c.cacheDuration {
+		return *c.report
+	}
+
+	c.mu.Lock()
+	components := make([]*component, len(c.components))
+	copy(components, c.components)
+	c.mu.Unlock()
+
+	type result struct {
+		comp     *component
+		err      error
+		duration time.Duration
+	}
+	results := make(chan result, len(components))
+	for _, comp := range components {
+		go func(comp *component) {
+			duration, err := c.run(comp)
+			results <- result{comp: comp, err: err, duration: duration}
+		}(comp)
+	}
+
+	report := &Report{
+		Status:     StatusOK,
+		Components: make(map[string]ComponentStatus, len(components)),
+		CheckedAt:  time.Now(),
+	}
+	for range components {
+		r := <-results
+		status := ComponentStatus{Status: StatusOK, DurationMS: float64(r.duration.Microseconds()) / 1000}
+		if r.err != nil {
+			status.Status = StatusFailed
+			report.Status = StatusFailed
+			if !r.comp.failed {
+				c.logger.Warnf("Health check of %s failed: %s", r.comp.name, r.err)
+			}
+		} else if r.comp.failed {
+			c.logger.Infof("Health check of %s recovered", r.comp.name)
+		}
+		r.comp.failed = r.err != nil
+		report.Components[r.comp.name] = status
+	}
+	c.report = report
+	return *report
+}
+
+// run runs the check of the component with a timeout.
+// The check keeps running after the timeout, and the component is not checked again until it finishes.
+func (c *Checker) run(comp *component) (time.Duration, error) {
+	start := time.Now()
+	c.mu.Lock()
+	running := comp.running
+	comp.running = true
+	c.mu.Unlock()
+	if running {
+		return 0, ErrCheckRunning
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- comp.check()
+		c.mu.Lock()
+		comp.running = false
+		c.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return time.Since(start), err
+	case <-timer.C:
+		return time.Since(start), ErrTimeout
+	}
+}
+
+// LivenessHandler reports the checks, responding with 200 status while the server is serving.
+// Failed dependencies make the status "degraded", as restarting the server does not fix them.
+func (c *Checker) LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Report()
+		if report.Status != StatusOK {
+			report.Status = StatusDegraded
+		}
+		writeReport(w, http.StatusOK, report)
+	}
+}
+
+// ReadinessHandler reports the checks, responding with 503 status if any of them failed,
+// so load balancers do not route traffic to the server.
+func (c *Checker) ReadinessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Report()
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		writeReport(w, status, report)
+	}
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,100 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+)
+
+func TestReadiness(t *testing.T) {
+	checker := NewChecker(model.HealthSettings{Timeout: 50, CacheDuration: 60000}, nil)
+
+	var calls int32
+	checker.Add("appStorage", func() error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+	checker.Add("sessionStorage", func() error {
+		return errors.New("connection refused")
+	})
+	release := make(chan struct{})
+	defer close(release)
+	checker.Add("configurationStorage", func() error {
+		<-release
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	checker.ReadinessHandler()(rec, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Readiness status = %d, expected %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	var report Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Error decoding report: %s", err)
+	}
+	expected := map[string]string{
+		"appStorage":           StatusOK,
+		"sessionStorage":       StatusFailed,
+		"configurationStorage": StatusFailed,
+	}
+	for name, status := range expected {
+		if report.Components[name].Status != status {
+			t.Errorf("Status of %s = %q, expected %q", name, report.Components[name].Status, status)
+		}
+	}
+	if report.Status != StatusFailed {
+		t.Errorf("Report status = %q, expected %q", report.Status, StatusFailed)
+	}
+
+	rec = httptest.NewRecorder()
+	checker.LivenessHandler()(rec, httptest.NewRequest(http.MethodGet, LivenessPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Liveness status = %d, expected %d", rec.Code, http.StatusOK)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Error decoding report: %s", err)
+	}
+	if report.Status != StatusDegraded {
+		t.Errorf("Liveness report status = %q, expected %q", report.Status, StatusDegraded)
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Check ran %d times, expected cached result", n)
+	}
+}
+
+func TestHungCheckIsNotRestarted(t *testing.T) {
+	checker := NewChecker(model.HealthSettings{Timeout: 20, CacheDuration: 1}, nil)
+
+	var calls int32
+	release := make(chan struct{})
+	checker.Add("userStorage", func() error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return nil
+	})
+
+	for i := 0; i < 3; i++ {
+		if report := checker.Report(); report.Status != StatusFailed {
+			t.Errorf("Report status = %q, expected %q", report.Status, StatusFailed)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Hung check started %d times, expected once", n)
+	}
+
+	close(release)
+	time.Sleep(10 * time.Millisecond)
+	if report := checker.Report(); report.Status != StatusOK {
+		t.Errorf("Report status after the check finished = %q, expected %q", report.Status, StatusOK)
+	}
+}
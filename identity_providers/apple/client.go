@@ -29,9 +29,23 @@ func NewClient(authorizationCode string, appleInfo *model.AppleInfo) *Client {
 	return c
 }
 
+// AuthCodeURL returns URL of Sign In with Apple page, which redirects back to redirectURI with authorization code.
+// No scopes are requested, because Apple posts the response as a form when they are.
+func (c *Client) AuthCodeURL(redirectURI, state string) string {
+	v := url.Values{}
+	v.Set("response_type", "code")
+	v.Set("client_id", c.ClientID)
+	v.Set("redirect_uri", redirectURI)
+	v.Set("state", state)
+
+	u := c.BaseURL.ResolveReference(&url.URL{Path: "/auth/authorize", RawQuery: v.Encode()})
+	return u.String()
+}
+
 // Client is a client for making REST API requests to Apple authorization servers.
 type Client struct {
 	AuthorizationCode string
+	RedirectURI       string // Required when the code was obtained via redirect flow.
 	ClientID          string
 	ClientSecret      string
 	BaseURL           *url.URL
@@ -57,6 +71,9 @@ func (c *Client) MyProfile() (User, error) {
 	form.Set("client_secret", c.ClientSecret)
 	form.Set("code", c.AuthorizationCode)
 	form.Set("grant_type", "authorization_code")
+	if c.RedirectURI != "" {
+		form.Set("redirect_uri", c.RedirectURI)
+	}
 
 	var user User
 
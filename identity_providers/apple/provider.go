@@ -0,0 +1,45 @@
+package apple
+
+import (
+	"github.com/madappgang/identifo/model"
+)
+
+// NewProvider creates Sign In with Apple federated identity provider.
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// Provider identifies users by Sign In with Apple authorization code.
+type Provider struct{}
+
+// IsConfigured implements model.FederatedRedirectProvider interface.
+func (p *Provider) IsConfigured(app model.AppData) bool {
+	return app.AppleInfo() != nil
+}
+
+// AuthCodeURL implements model.FederatedRedirectProvider interface.
+func (p *Provider) AuthCodeURL(app model.AppData, redirectURI, state, nonce string) (string, error) {
+	if !p.IsConfigured(app) {
+		return "", model.ErrFederatedProviderNotConfigured
+	}
+	return NewClient("", app.AppleInfo()).AuthCodeURL(redirectURI, state), nil
+}
+
+// Identity implements model.FederatedIdentityVerifier interface.
+func (p *Provider) Identity(app model.AppData, credentials model.FederatedCredentials) (model.FederatedIdentity, error) {
+	if !p.IsConfigured(app) {
+		return model.FederatedIdentity{}, model.ErrFederatedProviderNotConfigured
+	}
+
+	ac := NewClient(credentials.AuthorizationCode, app.AppleInfo())
+	ac.RedirectURI = credentials.RedirectURI
+	appleProfile, err := ac.MyProfile()
+	if err != nil {
+		return model.FederatedIdentity{}, err
+	}
+
+	if len(appleProfile.ID) == 0 {
+		return model.FederatedIdentity{}, model.ErrFederatedEmptyUserID
+	}
+	return model.FederatedIdentity{ID: appleProfile.ID}, nil
+}
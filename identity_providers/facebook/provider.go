@@ -0,0 +1,27 @@
+package facebook
+
+import (
+	"github.com/madappgang/identifo/model"
+)
+
+// NewProvider creates Facebook federated identity provider.
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// Provider identifies users by Facebook access token.
+type Provider struct{}
+
+// Identity implements model.FederatedIdentityVerifier interface.
+func (p *Provider) Identity(app model.AppData, credentials model.FederatedCredentials) (model.FederatedIdentity, error) {
+	fb := NewClient(credentials.AccessToken)
+	fbProfile, err := fb.MyProfile()
+	if err != nil {
+		return model.FederatedIdentity{}, err
+	}
+
+	if len(fbProfile.ID) == 0 {
+		return model.FederatedIdentity{}, model.ErrFederatedEmptyUserID
+	}
+	return model.FederatedIdentity{ID: fbProfile.ID, Name: fbProfile.Name}, nil
+}
@@ -0,0 +1,153 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+)
+
+const (
+	// githubOAuthPath is a base URL for GitHub OAuth web flow.
+	githubOAuthPath = "https://github.com/"
+	// githubAPIPath is a base URL for GitHub REST API.
+	githubAPIPath = "https://api.github.com/"
+)
+
+// NewClient creates new HTTP client for communicating with GitHub.
+func NewClient(githubInfo *model.GitHubInfo) *Client {
+	c := &Client{
+		ClientID:     githubInfo.ClientID,
+		ClientSecret: githubInfo.ClientSecret,
+		HTTPClient:   &http.Client{Timeout: 15 * time.Second},
+	}
+	c.OAuthURL, _ = url.Parse(githubOAuthPath)
+	c.APIURL, _ = url.Parse(githubAPIPath)
+	return c
+}
+
+// Client is a client for making OAuth and REST API requests to GitHub.
+type Client struct {
+	ClientID     string
+	ClientSecret string
+	OAuthURL     *url.URL
+	APIURL       *url.URL
+	HTTPClient   *http.Client
+}
+
+// User is what we can get about the user from GitHub.
+type User struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type accessTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// AuthCodeURL returns URL of GitHub consent page, which redirects back to redirectURI with authorization code.
+func (c *Client) AuthCodeURL(redirectURI, state string) string {
+	v := url.Values{}
+	v.Set("client_id", c.ClientID)
+	v.Set("redirect_uri", redirectURI)
+	v.Set("scope", "read:user user:email")
+	v.Set("state", state)
+
+	u := c.OAuthURL.ResolveReference(&url.URL{Path: "login/oauth/authorize", RawQuery: v.Encode()})
+	return u.String()
+}
+
+// Exchange exchanges OAuth authorization code for the access token.
+func (c *Client) Exchange(code, redirectURI string) (string, error) {
+	form := url.Values{}
+	form.Set("client_id", c.ClientID)
+	form.Set("client_secret", c.ClientSecret)
+	form.Set("code", code)
+	if redirectURI != "" {
+		form.Set("redirect_uri", redirectURI)
+	}
+
+	u := c.OAuthURL.ResolveReference(&url.URL{Path: "login/oauth/access_token"})
+	req, err := http.NewRequest("POST", u.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp accessTokenResponse
+	if err = c.do(req, &resp); err != nil {
+		return "", err
+	}
+	// GitHub reports OAuth errors with 200 status code.
+	if resp.Error != "" {
+		return "", fmt.Errorf("GitHub OAuth error: %s %s", resp.Error, resp.ErrorDescription)
+	}
+	if resp.AccessToken == "" {
+		return "", errors.New("GitHub returned empty access token")
+	}
+	return resp.AccessToken, nil
+}
+
+// MyProfile asks for the access token owner's profile.
+func (c *Client) MyProfile(accessToken string) (User, error) {
+	var user User
+
+	u := c.APIURL.ResolveReference(&url.URL{Path: "user"})
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Set("Authorization", "token "+accessToken)
+
+	err = c.do(req, &user)
+	return user, err
+}
+
+// CheckToken makes sure the access token was issued for our OAuth app and returns its owner.
+// Without this check, a token issued to any other GitHub app could be used to log in.
+func (c *Client) CheckToken(accessToken string) (User, error) {
+	var check struct {
+		User User `json:"user"`
+	}
+
+	body, err := json.Marshal(map[string]string{"access_token": accessToken})
+	if err != nil {
+		return check.User, err
+	}
+
+	u := c.APIURL.ResolveReference(&url.URL{Path: "applications/" + c.ClientID + "/token"})
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return check.User, err
+	}
+	req.SetBasicAuth(c.ClientID, c.ClientSecret)
+	req.Header.Set("Content-Type", "application/json")
+
+	err = c.do(req, &check)
+	return check.User, err
+}
+
+func (c *Client) do(req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("GitHub response error: %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
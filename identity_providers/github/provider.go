@@ -0,0 +1,81 @@
+package github
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/madappgang/identifo/model"
+)
+
+// NewProvider creates GitHub federated identity provider.
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// Provider authenticates users with GitHub OAuth.
+// Clients send either the authorization code, which is exchanged server side,
+// or the access token issued for the app's GitHub OAuth client.
+type Provider struct {
+	// OAuthURL and APIURL override GitHub endpoints, used in tests.
+	OAuthURL *url.URL
+	APIURL   *url.URL
+}
+
+// IsConfigured implements model.FederatedRedirectProvider interface.
+func (p *Provider) IsConfigured(app model.AppData) bool {
+	info := app.GitHubInfo()
+	return info != nil && info.ClientID != ""
+}
+
+// AuthCodeURL implements model.FederatedRedirectProvider interface.
+// GitHub OAuth does not support nonce, state protects the flow.
+func (p *Provider) AuthCodeURL(app model.AppData, redirectURI, state, nonce string) (string, error) {
+	if !p.IsConfigured(app) {
+		return "", model.ErrFederatedProviderNotConfigured
+	}
+	return p.client(app).AuthCodeURL(redirectURI, state), nil
+}
+
+// Identity implements model.FederatedIdentityVerifier interface.
+func (p *Provider) Identity(app model.AppData, credentials model.FederatedCredentials) (model.FederatedIdentity, error) {
+	if !p.IsConfigured(app) {
+		return model.FederatedIdentity{}, model.ErrFederatedProviderNotConfigured
+	}
+	c := p.client(app)
+
+	var user User
+	var err error
+
+	if credentials.AuthorizationCode != "" {
+		var accessToken string
+		if accessToken, err = c.Exchange(credentials.AuthorizationCode, credentials.RedirectURI); err != nil {
+			return model.FederatedIdentity{}, err
+		}
+		user, err = c.MyProfile(accessToken)
+	} else {
+		user, err = c.CheckToken(credentials.AccessToken)
+	}
+	if err != nil {
+		return model.FederatedIdentity{}, err
+	}
+
+	if user.ID == 0 {
+		return model.FederatedIdentity{}, model.ErrFederatedEmptyUserID
+	}
+	return model.FederatedIdentity{
+		ID:    strconv.FormatInt(user.ID, 10),
+		Email: user.Email,
+		Name:  user.Name,
+	}, nil
+}
+
+func (p *Provider) client(app model.AppData) *Client {
+	c := NewClient(app.GitHubInfo())
+	if p.OAuthURL != nil {
+		c.OAuthURL = p.OAuthURL
+	}
+	if p.APIURL != nil {
+		c.APIURL = p.APIURL
+	}
+	return c
+}
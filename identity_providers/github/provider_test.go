@@ -0,0 +1,91 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/madappgang/identifo/model"
+)
+
+const (
+	testClientID     = "test-client"
+	testClientSecret = "test-secret"
+	testCode         = "test-code"
+	testAccessToken  = "test-token"
+)
+
+type testApp struct {
+	model.AppData
+	info *model.GitHubInfo
+}
+
+func (a testApp) GitHubInfo() *model.GitHubInfo { return a.info }
+
+// newFakeGitHub serves both OAuth and REST API endpoints GitHub provider uses.
+func newFakeGitHub() *httptest.Server {
+	user := User{ID: 42, Login: "octocat", Email: "octocat@example.com"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("code") != testCode || r.PostFormValue("client_secret") != testClientSecret {
+			json.NewEncoder(w).Encode(accessTokenResponse{Error: "bad_verification_code"})
+			return
+		}
+		json.NewEncoder(w).Encode(accessTokenResponse{AccessToken: testAccessToken})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token "+testAccessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(user)
+	})
+	mux.HandleFunc("/applications/"+testClientID+"/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if id != testClientID || secret != testClientSecret || body["access_token"] != testAccessToken {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"user": user})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestProvider_Identity(t *testing.T) {
+	gh := newFakeGitHub()
+	defer gh.Close()
+
+	u, _ := url.Parse(gh.URL + "/")
+	p := &Provider{OAuthURL: u, APIURL: u}
+	app := testApp{info: &model.GitHubInfo{ClientID: testClientID, ClientSecret: testClientSecret}}
+
+	tests := []struct {
+		name        string
+		app         model.AppData
+		credentials model.FederatedCredentials
+		wantID      string
+		wantErr     bool
+	}{
+		{"authorization code", app, model.FederatedCredentials{AuthorizationCode: testCode}, "42", false},
+		{"access token", app, model.FederatedCredentials{AccessToken: testAccessToken}, "42", false},
+		{"wrong authorization code", app, model.FederatedCredentials{AuthorizationCode: "wrong"}, "", true},
+		{"foreign access token", app, model.FederatedCredentials{AccessToken: "foreign"}, "", true},
+		{"app without github info", testApp{}, model.FederatedCredentials{AuthorizationCode: testCode}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Identity(tt.app, tt.credentials)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Identity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("Identity() ID = %v, want %v", got.ID, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package google
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/madappgang/identifo/identity_providers/oidc"
+	"github.com/madappgang/identifo/model"
+)
+
+// googleIssuer is the issuer of Google ID tokens. Tokens might also have it without the scheme.
+const googleIssuer = "https://accounts.google.com"
+
+// Discovery is a well-known Google OpenID Connect configuration.
+// It is hardcoded to save the discovery round trip.
+var Discovery = oidc.Discovery{
+	Issuer:                googleIssuer,
+	AuthorizationEndpoint: "https://accounts.google.com/o/oauth2/v2/auth",
+	TokenEndpoint:         "https://oauth2.googleapis.com/token",
+	UserinfoEndpoint:      "https://openidconnect.googleapis.com/v1/userinfo",
+	JWKSURI:               "https://www.googleapis.com/oauth2/v3/certs",
+}
+
+// NewProvider creates Google federated identity provider.
+func NewProvider() *Provider {
+	return &Provider{
+		Discovery:  Discovery,
+		HTTPClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Provider verifies Google ID tokens against Google's JWKS.
+// Authorization code is exchanged for the ID token when the client does not send it.
+type Provider struct {
+	Discovery  oidc.Discovery
+	HTTPClient *http.Client
+
+	keySetOnce sync.Once
+	keySet     *oidc.KeySet
+}
+
+// IsConfigured implements model.FederatedRedirectProvider interface.
+func (p *Provider) IsConfigured(app model.AppData) bool {
+	info := app.GoogleInfo()
+	return info != nil && info.ClientID != ""
+}
+
+// AuthCodeURL implements model.FederatedRedirectProvider interface.
+func (p *Provider) AuthCodeURL(app model.AppData, redirectURI, state, nonce string) (string, error) {
+	c, err := p.Client(app)
+	if err != nil {
+		return "", err
+	}
+	return c.AuthCodeURL(redirectURI, state, nonce, nil), nil
+}
+
+// Identity implements model.FederatedIdentityVerifier interface.
+func (p *Provider) Identity(app model.AppData, credentials model.FederatedCredentials) (model.FederatedIdentity, error) {
+	c, err := p.Client(app)
+	if err != nil {
+		return model.FederatedIdentity{}, err
+	}
+
+	claims, err := c.Claims(credentials.IDToken, credentials.AuthorizationCode, credentials.RedirectURI, credentials.Nonce)
+	if err != nil {
+		return model.FederatedIdentity{}, err
+	}
+	return model.FederatedIdentity{ID: claims.Subject, Email: claims.Email, Name: claims.Name}, nil
+}
+
+// Client returns OpenID Connect client configured with the app's Google credentials.
+func (p *Provider) Client(app model.AppData) (*oidc.Client, error) {
+	if !p.IsConfigured(app) {
+		return nil, model.ErrFederatedProviderNotConfigured
+	}
+	info := app.GoogleInfo()
+
+	p.keySetOnce.Do(func() {
+		p.keySet = oidc.NewKeySet(p.Discovery.JWKSURI, p.HTTPClient)
+	})
+
+	c := oidc.NewClient(p.Discovery, info.ClientID, info.ClientSecret, p.HTTPClient, "accounts.google.com")
+	c.Verifier.KeySet = p.keySet
+	return c, nil
+}
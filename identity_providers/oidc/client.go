@@ -0,0 +1,158 @@
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrEmptyCredentials is when neither ID token nor authorization code is provided.
+var ErrEmptyCredentials = errors.New("ID token or authorization code is required")
+
+// Discovery is the part of OpenID Provider Metadata Identifo needs.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint,omitempty"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Discover fetches provider metadata from the issuer's well-known discovery endpoint.
+func Discover(issuer string, httpClient *http.Client) (Discovery, error) {
+	var d Discovery
+	wellKnown := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+
+	resp, err := httpClient.Get(wellKnown)
+	if err != nil {
+		return d, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return d, fmt.Errorf("OIDC discovery error, status: %d", resp.StatusCode)
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&d); err != nil {
+		return d, err
+	}
+	if d.Issuer != issuer {
+		return d, fmt.Errorf("OIDC discovery issuer mismatch: expected %s, got %s", issuer, d.Issuer)
+	}
+	if d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return d, errors.New("OIDC discovery document misses token endpoint or JWKS URI")
+	}
+	return d, nil
+}
+
+// NewClient creates new client for the provider described by the discovery document.
+func NewClient(d Discovery, clientID, clientSecret string, httpClient *http.Client, extraIssuers ...string) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	issuers := append([]string{d.Issuer}, extraIssuers...)
+	return &Client{
+		Discovery:    d,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		HTTPClient:   httpClient,
+		Verifier:     NewVerifier(NewKeySet(d.JWKSURI, httpClient), issuers...),
+	}
+}
+
+// Client is a client for an OpenID Connect provider.
+type Client struct {
+	Discovery    Discovery
+	ClientID     string
+	ClientSecret string
+	HTTPClient   *http.Client
+	Verifier     *Verifier
+}
+
+// DefaultScopes are requested when the app does not specify its own scopes.
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// AuthCodeURL returns URL of the provider's consent page, which redirects back to redirectURI with authorization code.
+func (c *Client) AuthCodeURL(redirectURI, state, nonce string, scopes []string) string {
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+
+	v := url.Values{}
+	v.Set("response_type", "code")
+	v.Set("client_id", c.ClientID)
+	v.Set("redirect_uri", redirectURI)
+	v.Set("scope", strings.Join(scopes, " "))
+	v.Set("state", state)
+	if nonce != "" {
+		v.Set("nonce", nonce)
+	}
+
+	sep := "?"
+	if strings.Contains(c.Discovery.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return c.Discovery.AuthorizationEndpoint + sep + v.Encode()
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange exchanges authorization code for the ID token.
+func (c *Client) Exchange(code, redirectURI string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("client_id", c.ClientID)
+	form.Set("client_secret", c.ClientSecret)
+	if redirectURI != "" {
+		form.Set("redirect_uri", redirectURI)
+	}
+
+	req, err := http.NewRequest("POST", c.Discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var tr tokenResponse
+	if err = json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return "", err
+	}
+	if resp.StatusCode >= 400 || tr.Error != "" {
+		return "", fmt.Errorf("OIDC token endpoint error: %s %s, status: %d", tr.Error, tr.ErrorDescription, resp.StatusCode)
+	}
+	if tr.IDToken == "" {
+		return "", errors.New("OIDC token endpoint returned no ID token")
+	}
+	return tr.IDToken, nil
+}
+
+// Claims verifies ID token and returns its claims.
+// If ID token is empty, authorization code is exchanged for it first.
+func (c *Client) Claims(idToken, code, redirectURI, nonce string) (*Claims, error) {
+	if idToken == "" {
+		if code == "" {
+			return nil, ErrEmptyCredentials
+		}
+		var err error
+		if idToken, err = c.Exchange(code, redirectURI); err != nil {
+			return nil, err
+		}
+	}
+	return c.Verifier.Verify(idToken, c.ClientID, nonce)
+}
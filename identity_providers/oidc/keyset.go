@@ -0,0 +1,175 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultKeySetLifespan is how long fetched keys are considered fresh.
+	defaultKeySetLifespan = time.Hour
+	// defaultKeySetMinRefreshInterval limits how often keys are refetched,
+	// so tokens with unknown key IDs cannot make every request hit the provider.
+	defaultKeySetMinRefreshInterval = time.Minute
+)
+
+// jwk is a single JSON Web Key as published by identity providers.
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// KeySet fetches and caches the provider's JSON Web Key Set.
+type KeySet struct {
+	URL        string
+	HTTPClient *http.Client
+	Lifespan   time.Duration
+	// MinRefreshInterval is the minimum time between two fetches of the key set.
+	MinRefreshInterval time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+
+	refreshMu   sync.Mutex
+	attemptedAt time.Time
+	refreshErr  error
+}
+
+// NewKeySet creates new key set that loads keys from the URL.
+func NewKeySet(url string, httpClient *http.Client) *KeySet {
+	return &KeySet{
+		URL:                url,
+		HTTPClient:         httpClient,
+		Lifespan:           defaultKeySetLifespan,
+		MinRefreshInterval: defaultKeySetMinRefreshInterval,
+	}
+}
+
+// Key returns the public key with the specified key ID.
+// Keys are refetched when they are stale or the key ID is unknown, so key rotation is handled transparently.
+// Refetches are made at most once per MinRefreshInterval.
+func (ks *KeySet) Key(kid string) (interface{}, error) {
+	ks.mu.RLock()
+	key, ok := ks.keys[kid]
+	fresh := time.Since(ks.fetchedAt) < ks.Lifespan
+	ks.mu.RUnlock()
+	if ok && fresh {
+		return key, nil
+	}
+
+	if err := ks.refresh(); err != nil {
+		return nil, err
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if key, ok = ks.keys[kid]; !ok {
+		return nil, fmt.Errorf("Unknown key id: %s", kid)
+	}
+	return key, nil
+}
+
+// refresh fetches the key set, unless it has been attempted less than MinRefreshInterval ago.
+// In that case the result of the last attempt is returned.
+func (ks *KeySet) refresh() error {
+	ks.refreshMu.Lock()
+	defer ks.refreshMu.Unlock()
+
+	if time.Since(ks.attemptedAt) < ks.MinRefreshInterval {
+		return ks.refreshErr
+	}
+	ks.attemptedAt = time.Now()
+	ks.refreshErr = ks.fetch()
+	return ks.refreshErr
+}
+
+func (ks *KeySet) fetch() error {
+	resp, err := ks.HTTPClient.Get(ks.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("Cannot fetch key set, status: %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pk, err := k.publicKey()
+		if err != nil {
+			// Providers may publish keys we cannot use (other curves, OKP), skip them instead of failing the whole set.
+			continue
+		}
+		keys[k.Kid] = pk
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.fetchedAt = time.Now()
+	ks.mu.Unlock()
+	return nil
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("Unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("Unsupported key type: %s", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,235 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/madappgang/identifo/model"
+)
+
+const (
+	testKeyID    = "test-key"
+	testClientID = "test-client"
+	testCode     = "test-code"
+)
+
+// fakeIdP is a local OpenID Connect provider, that issues ID tokens for the test code.
+type fakeIdP struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+
+	discoveries int32
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &fakeIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&idp.discoveries, 1)
+		json.NewEncoder(w).Encode(Discovery{
+			Issuer:                idp.URL,
+			AuthorizationEndpoint: idp.URL + "/authorize",
+			TokenEndpoint:         idp.URL + "/token",
+			JWKSURI:               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []jwk{{
+			Kid: testKeyID,
+			Kty: "RSA",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("code") != testCode || r.PostFormValue("client_id") != testClientID {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(tokenResponse{IDToken: idp.sign(t, idp.claims)})
+	})
+	idp.Server = httptest.NewServer(mux)
+	return idp
+}
+
+func (idp *fakeIdP) sign(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	s, err := token.SignedString(idp.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+type testApp struct {
+	model.AppData
+	info *model.OIDCInfo
+}
+
+func (a testApp) OIDCInfo() *model.OIDCInfo { return a.info }
+
+func TestProvider_Identity(t *testing.T) {
+	idp := newFakeIdP(t)
+	defer idp.Close()
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   idp.URL,
+			"sub":   "user-1",
+			"aud":   []string{testClientID},
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"email": "user@example.com",
+		}
+	}
+	with := func(key string, value interface{}) jwt.MapClaims {
+		c := validClaims()
+		c[key] = value
+		return c
+	}
+	app := testApp{info: &model.OIDCInfo{Issuer: idp.URL, ClientID: testClientID}}
+
+	tests := []struct {
+		name        string
+		app         model.AppData
+		credentials func() model.FederatedCredentials
+		codeClaims  jwt.MapClaims
+		wantID      string
+		wantErr     bool
+	}{
+		{"valid id token", app, func() model.FederatedCredentials {
+			return model.FederatedCredentials{IDToken: idp.sign(t, validClaims())}
+		}, nil, idp.URL + "|user-1", false},
+		{"valid authorization code", app, func() model.FederatedCredentials {
+			return model.FederatedCredentials{AuthorizationCode: testCode}
+		}, validClaims(), idp.URL + "|user-1", false},
+		{"string audience", app, func() model.FederatedCredentials {
+			return model.FederatedCredentials{IDToken: idp.sign(t, with("aud", testClientID))}
+		}, nil, idp.URL + "|user-1", false},
+		{"wrong authorization code", app, func() model.FederatedCredentials {
+			return model.FederatedCredentials{AuthorizationCode: "wrong"}
+		}, validClaims(), "", true},
+		{"expired token", app, func() model.FederatedCredentials {
+			return model.FederatedCredentials{IDToken: idp.sign(t, with("exp", time.Now().Add(-time.Hour).Unix()))}
+		}, nil, "", true},
+		{"wrong audience", app, func() model.FederatedCredentials {
+			return model.FederatedCredentials{IDToken: idp.sign(t, with("aud", "another-client"))}
+		}, nil, "", true},
+		{"wrong issuer", app, func() model.FederatedCredentials {
+			return model.FederatedCredentials{IDToken: idp.sign(t, with("iss", "https://evil.example.com"))}
+		}, nil, "", true},
+		{"wrong nonce", app, func() model.FederatedCredentials {
+			return model.FederatedCredentials{IDToken: idp.sign(t, with("nonce", "a")), Nonce: "b"}
+		}, nil, "", true},
+		{"empty credentials", app, func() model.FederatedCredentials {
+			return model.FederatedCredentials{}
+		}, nil, "", true},
+		{"app without oidc info", testApp{}, func() model.FederatedCredentials {
+			return model.FederatedCredentials{IDToken: idp.sign(t, validClaims())}
+		}, nil, "", true},
+	}
+
+	p := NewProvider()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp.claims = tt.codeClaims
+			got, err := p.Identity(tt.app, tt.credentials())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Identity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("Identity() ID = %v, want %v", got.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestProvider_DiscoveryLifespan(t *testing.T) {
+	idp := newFakeIdP(t)
+	defer idp.Close()
+	info := &model.OIDCInfo{Issuer: idp.URL, ClientID: testClientID}
+
+	p := NewProvider()
+	first, err := p.Client(info)
+	if err != nil {
+		t.Fatalf("Error creating client: %s", err)
+	}
+	if _, err = p.Client(info); err != nil {
+		t.Fatalf("Error creating client: %s", err)
+	}
+	if n := atomic.LoadInt32(&idp.discoveries); n != 1 {
+		t.Errorf("Discovery fetched %d times, expected cached document", n)
+	}
+
+	p.DiscoveryLifespan = 0
+	second, err := p.Client(info)
+	if err != nil {
+		t.Fatalf("Error creating client: %s", err)
+	}
+	if n := atomic.LoadInt32(&idp.discoveries); n != 2 {
+		t.Errorf("Discovery fetched %d times, expected refetch of the expired document", n)
+	}
+	if first.Verifier.KeySet != second.Verifier.KeySet {
+		t.Error("Key set is not kept when the JWKS URI has not changed")
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []jwk{
+			{Kid: "p384", Kty: "EC", Crv: "P-384", X: "AQ", Y: "AQ"},
+			{Kid: "ed25519", Kty: "OKP", Crv: "Ed25519", X: "AQ"},
+			{
+				Kid: testKeyID,
+				Kty: "RSA",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			},
+		}})
+	}))
+	defer server.Close()
+
+	ks := NewKeySet(server.URL, server.Client())
+	if _, err := ks.Key(testKeyID); err != nil {
+		t.Errorf("Supported key is not loaded along with unsupported ones: %s", err)
+	}
+	for _, kid := range []string{"p384", "unknown", "unknown"} {
+		if _, err := ks.Key(kid); err == nil {
+			t.Errorf("Key(%q) returned no error", kid)
+		}
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("Key set fetched %d times, expected once within the minimum refresh interval", n)
+	}
+
+	ks.MinRefreshInterval = 0
+	if _, err := ks.Key("unknown"); err == nil {
+		t.Error("Unknown key returned no error")
+	}
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("Key set fetched %d times, expected refetch after the interval", n)
+	}
+}
@@ -0,0 +1,117 @@
+package oidc
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+)
+
+// defaultDiscoveryLifespan is how long discovery documents are cached.
+const defaultDiscoveryLifespan = 24 * time.Hour
+
+// NewProvider creates generic OpenID Connect provider.
+// Every app brings its own issuer in model.OIDCInfo, discovery documents are cached per issuer.
+func NewProvider() *Provider {
+	return &Provider{
+		HTTPClient:        &http.Client{Timeout: 15 * time.Second},
+		DiscoveryLifespan: defaultDiscoveryLifespan,
+		issuers:           make(map[string]issuer),
+	}
+}
+
+// Provider is a generic OpenID Connect federated identity provider.
+type Provider struct {
+	HTTPClient        *http.Client
+	DiscoveryLifespan time.Duration
+
+	mu      sync.Mutex
+	issuers map[string]issuer
+}
+
+// issuer is the cached discovery document of the issuer with its key set.
+type issuer struct {
+	discovery Discovery
+	keySet    *KeySet
+	fetchedAt time.Time
+}
+
+// IsConfigured implements model.FederatedRedirectProvider interface.
+func (p *Provider) IsConfigured(app model.AppData) bool {
+	info := app.OIDCInfo()
+	return info != nil && info.Issuer != "" && info.ClientID != ""
+}
+
+// AuthCodeURL implements model.FederatedRedirectProvider interface.
+func (p *Provider) AuthCodeURL(app model.AppData, redirectURI, state, nonce string) (string, error) {
+	if !p.IsConfigured(app) {
+		return "", model.ErrFederatedProviderNotConfigured
+	}
+
+	c, err := p.Client(app.OIDCInfo())
+	if err != nil {
+		return "", err
+	}
+	return c.AuthCodeURL(redirectURI, state, nonce, app.OIDCInfo().Scopes), nil
+}
+
+// Identity implements model.FederatedIdentityVerifier interface.
+// Identity ID is prefixed with the issuer, because subjects are unique only within the issuer.
+func (p *Provider) Identity(app model.AppData, credentials model.FederatedCredentials) (model.FederatedIdentity, error) {
+	if !p.IsConfigured(app) {
+		return model.FederatedIdentity{}, model.ErrFederatedProviderNotConfigured
+	}
+
+	c, err := p.Client(app.OIDCInfo())
+	if err != nil {
+		return model.FederatedIdentity{}, err
+	}
+
+	claims, err := c.Claims(credentials.IDToken, credentials.AuthorizationCode, credentials.RedirectURI, credentials.Nonce)
+	if err != nil {
+		return model.FederatedIdentity{}, err
+	}
+	return model.FederatedIdentity{
+		ID:    claims.Issuer + "|" + claims.Subject,
+		Email: claims.Email,
+		Name:  claims.Name,
+	}, nil
+}
+
+// Client returns client for the app's provider settings.
+func (p *Provider) Client(info *model.OIDCInfo) (*Client, error) {
+	p.mu.Lock()
+	iss, ok := p.issuers[info.Issuer]
+	p.mu.Unlock()
+
+	if !ok || time.Since(iss.fetchedAt) >= p.DiscoveryLifespan {
+		// Discovery is fetched without holding the lock, so a slow issuer does not block logins with other issuers.
+		d, err := Discover(info.Issuer, p.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		iss = p.storeIssuer(info.Issuer, d)
+	}
+
+	c := NewClient(iss.discovery, info.ClientID, info.ClientSecret, p.HTTPClient)
+	// Share the key set between apps of the same issuer, so keys are not refetched on every login.
+	c.Verifier.KeySet = iss.keySet
+	return c, nil
+}
+
+// storeIssuer caches the discovery document.
+// Key set is kept when the JWKS URI has not changed, so cached keys survive discovery refetches.
+func (p *Provider) storeIssuer(name string, d Discovery) issuer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	iss := issuer{discovery: d, fetchedAt: time.Now()}
+	if cached, ok := p.issuers[name]; ok && cached.discovery.JWKSURI == d.JWKSURI {
+		iss.keySet = cached.keySet
+	} else {
+		iss.keySet = NewKeySet(d.JWKSURI, p.HTTPClient)
+	}
+	p.issuers[name] = iss
+	return iss
+}
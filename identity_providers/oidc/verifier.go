@@ -0,0 +1,118 @@
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+var (
+	// ErrInvalidIssuer is when ID token was issued by unexpected party.
+	ErrInvalidIssuer = errors.New("ID token has invalid issuer")
+	// ErrInvalidAudience is when ID token was issued for another client.
+	ErrInvalidAudience = errors.New("ID token has invalid audience")
+	// ErrInvalidNonce is when ID token nonce does not match the expected one.
+	ErrInvalidNonce = errors.New("ID token has invalid nonce")
+	// ErrTokenExpired is when ID token has expired.
+	ErrTokenExpired = errors.New("ID token is expired")
+)
+
+// audience is the aud claim, which can be either a string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+	var arr []string
+	if err := json.Unmarshal(b, &arr); err != nil {
+		return err
+	}
+	*a = audience(arr)
+	return nil
+}
+
+func (a audience) contains(clientID string) bool {
+	for _, aud := range a {
+		if aud == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims are ID token claims we are interested in.
+type Claims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	IssuedAt  int64    `json:"iat"`
+	Nonce     string   `json:"nonce,omitempty"`
+	Email     string   `json:"email,omitempty"`
+	Name      string   `json:"name,omitempty"`
+}
+
+// Valid implements jwt.Claims interface.
+func (c *Claims) Valid() error {
+	if c.ExpiresAt == 0 || time.Now().Unix() > c.ExpiresAt {
+		return ErrTokenExpired
+	}
+	if c.Subject == "" {
+		return errors.New("ID token has empty subject")
+	}
+	return nil
+}
+
+// Verifier verifies ID tokens signed by the provider.
+type Verifier struct {
+	KeySet  *KeySet
+	Issuers []string
+}
+
+// NewVerifier creates new ID token verifier. Token issuer must match one of the issuers.
+func NewVerifier(keySet *KeySet, issuers ...string) *Verifier {
+	return &Verifier{KeySet: keySet, Issuers: issuers}
+}
+
+// Verify checks ID token signature and claims. Nonce is checked only when it is not empty.
+func (v *Verifier) Verify(rawIDToken, clientID, nonce string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("Unexpected signing method: %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		return v.KeySet.Key(kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.validIssuer(claims.Issuer) {
+		return nil, ErrInvalidIssuer
+	}
+	if !claims.Audience.contains(clientID) {
+		return nil, ErrInvalidAudience
+	}
+	if nonce != "" && claims.Nonce != nonce {
+		return nil, ErrInvalidNonce
+	}
+	return claims, nil
+}
+
+func (v *Verifier) validIssuer(iss string) bool {
+	for _, i := range v.Issuers {
+		if i == iss {
+			return true
+		}
+	}
+	return false
+}
@@ -11,6 +11,7 @@ import (
 	jwt "github.com/dgrijalva/jwt-go"
 	ijwt "github.com/madappgang/identifo/jwt"
 	jwtValidator "github.com/madappgang/identifo/jwt/validator"
+	"github.com/madappgang/identifo/metrics"
 	"github.com/madappgang/identifo/model"
 )
 
@@ -25,6 +26,10 @@ var (
 	ErrInvalidOfflineScope = errors.New("Requested scope don't have offline value")
 	// ErrInvalidUser is when the user cannot obtain the new token.
 	ErrInvalidUser = errors.New("The user cannot obtain the new token")
+	// ErrTokenRevoked is when the token is issued before the user tokens were revoked.
+	ErrTokenRevoked = errors.New("Token has been revoked")
+	// ErrNotOrganizationMember is when the user requests the token for the organization they are not a member of.
+	ErrNotOrganizationMember = errors.New("The user is not a member of the organization")
 
 	// TokenLifespan is a token expiration time, one week.
 	TokenLifespan = int64(604800) // int64(1*7*24*60*60)
@@ -37,6 +42,26 @@ var (
 const (
 	// PayloadName is a JWT token payload "name".
 	PayloadName = "name"
+	// PayloadEmail is a JWT token payload "email".
+	PayloadEmail = "email"
+	// PayloadPhone is a JWT token payload "phone".
+	PayloadPhone = "phone"
+	// PayloadRole is a JWT token payload "role".
+	PayloadRole = "role"
+	// PayloadScopes is a JWT token payload "scopes", the list of granted scopes.
+	PayloadScopes = "scopes"
+	// PayloadUserMetadata is a JWT token payload "user_metadata", or the prefix of its field path.
+	PayloadUserMetadata = "user_metadata"
+	// PayloadAppMetadata is a JWT token payload "app_metadata", or the prefix of its field path.
+	PayloadAppMetadata = "app_metadata"
+	// PayloadOrgID is a JWT token payload "org_id", the organization the token is issued for.
+	PayloadOrgID = "org_id"
+	// PayloadOrgRole is a JWT token payload "org_role", the role of the user in the organization.
+	PayloadOrgRole = "org_role"
+	// PayloadRoles is a JWT token payload "roles", the roles of the user in the app, including inherited ones.
+	PayloadRoles = "roles"
+	// PayloadPermissions is a JWT token payload "permissions", the permissions of all the user roles in the app.
+	PayloadPermissions = "permissions"
 	// PayloadTFAuthorized is a JWT token payload "tfa_authorized".
 	PayloadTFAuthorized = "tfa_authorized"
 )
@@ -77,6 +102,42 @@ func NewJWTokenService(keys *model.JWTKeys, issuer string, tokenStorage model.To
 	return t, nil
 }
 
+// AuthHooksOption sets the service called before access tokens are issued.
+func AuthHooksOption(authHooks model.AuthHookService) func(TokenService) error {
+	return func(ts TokenService) error {
+		jts, ok := ts.(*JWTokenService)
+		if !ok {
+			return fmt.Errorf("Auth hooks are not supported by %T", ts)
+		}
+		jts.authHooks = authHooks
+		return nil
+	}
+}
+
+// OrganizationsOption sets the storage used to check the membership when tokens are issued for the organization.
+func OrganizationsOption(orgStorage model.OrganizationStorage) func(TokenService) error {
+	return func(ts TokenService) error {
+		jts, ok := ts.(*JWTokenService)
+		if !ok {
+			return fmt.Errorf("Organizations are not supported by %T", ts)
+		}
+		jts.orgStorage = orgStorage
+		return nil
+	}
+}
+
+// RolesOption sets the storage of the app roles, used to add the roles and permissions claims to access tokens.
+func RolesOption(roleStorage model.RoleStorage) func(TokenService) error {
+	return func(ts TokenService) error {
+		jts, ok := ts.(*JWTokenService)
+		if !ok {
+			return fmt.Errorf("Roles are not supported by %T", ts)
+		}
+		jts.roleStorage = roleStorage
+		return nil
+	}
+}
+
 // JWTokenService is a JWT token service.
 type JWTokenService struct {
 	privateKey             interface{} // *ecdsa.PrivateKey, or *rsa.PrivateKey
@@ -84,6 +145,9 @@ type JWTokenService struct {
 	tokenStorage           model.TokenStorage
 	appStorage             model.AppStorage
 	userStorage            model.UserStorage
+	authHooks              model.AuthHookService
+	orgStorage             model.OrganizationStorage
+	roleStorage            model.RoleStorage
 	algorithm              ijwt.TokenSignatureAlgorithm
 	issuer                 string
 	resetTokenLifespan     int64
@@ -162,6 +226,19 @@ func (ts *JWTokenService) ValidateTokenString(tstr string, v jwtValidator.Valida
 
 // NewAccessToken creates new access token for user.
 func (ts *JWTokenService) NewAccessToken(u model.User, scopes []string, app model.AppData, requireTFA bool) (ijwt.Token, error) {
+	return ts.newAccessToken(u, scopes, app, requireTFA, nil)
+}
+
+// NewOrgAccessToken creates new access token for the user acting on behalf of the organization.
+func (ts *JWTokenService) NewOrgAccessToken(u model.User, scopes []string, app model.AppData, orgID string) (ijwt.Token, error) {
+	member, err := ts.orgMember(u, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return ts.newAccessToken(u, scopes, app, false, &member)
+}
+
+func (ts *JWTokenService) newAccessToken(u model.User, scopes []string, app model.AppData, requireTFA bool, member *model.OrganizationMember) (ijwt.Token, error) {
 	if !app.Active() {
 		return nil, ErrInvalidApp
 	}
@@ -170,12 +247,28 @@ func (ts *JWTokenService) NewAccessToken(u model.User, scopes []string, app mode
 		return nil, ErrInvalidUser
 	}
 
-	payload := make(map[string]string)
-	if contains(app.TokenPayload(), PayloadName) {
-		payload[PayloadName] = u.Username()
+	payload := tokenPayload(u, scopes, app)
+	if member != nil {
+		payload[PayloadOrgID] = member.OrgID
+		payload[PayloadOrgRole] = member.Role
+	}
+	if ts.roleStorage != nil {
+		roles, permissions, err := model.EffectiveRoles(ts.roleStorage, app.ID(), u.ID(), u.AccessRole())
+		if err != nil {
+			return nil, err
+		}
+		if len(roles) > 0 {
+			payload[PayloadRoles] = roles
+		}
+		if len(permissions) > 0 {
+			payload[PayloadPermissions] = permissions
+		}
 	}
 	if requireTFA {
+		// Token is not usable until TFA is passed, pre-token hook is called for the final one.
 		payload[PayloadTFAuthorized] = "false"
+	} else if err := ts.callPreTokenHook(u, scopes, app, member, payload); err != nil {
+		return nil, err
 	}
 
 	now := ijwt.TimeFunc().Unix()
@@ -212,11 +305,71 @@ func (ts *JWTokenService) NewAccessToken(u model.User, scopes []string, app mode
 	if token == nil {
 		return nil, ErrCreatingToken
 	}
+	metrics.TokenIssued(AccessTokenType)
 	return &ijwt.JWToken{JWT: token, New: true}, nil
 }
 
+// callPreTokenHook lets the external service deny the token or add claims to its payload.
+// Claims do not override the payload set by the service.
+func (ts *JWTokenService) callPreTokenHook(u model.User, scopes []string, app model.AppData, member *model.OrganizationMember, payload map[string]interface{}) error {
+	if ts.authHooks == nil {
+		return nil
+	}
+
+	req := model.AuthHookRequest{
+		Hook:     model.AuthHookPreToken,
+		AppID:    app.ID(),
+		UserID:   u.ID(),
+		Username: u.Username(),
+		Email:    u.Email(),
+		Phone:    u.Phone(),
+		Role:     u.AccessRole(),
+		Scopes:   scopes,
+	}
+	if member != nil {
+		req.OrgID = member.OrgID
+		req.OrgRole = member.Role
+	}
+
+	resp, err := ts.authHooks.Call(req)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range resp.Claims {
+		if _, ok := payload[k]; !ok {
+			payload[k] = v
+		}
+	}
+	return nil
+}
+
+// orgMember returns the membership of the user in the organization.
+func (ts *JWTokenService) orgMember(u model.User, orgID string) (model.OrganizationMember, error) {
+	if ts.orgStorage == nil {
+		return model.OrganizationMember{}, ErrNotOrganizationMember
+	}
+	member, err := ts.orgStorage.Member(orgID, u.ID())
+	if err == model.ErrOrganizationMemberNotFound {
+		return member, ErrNotOrganizationMember
+	}
+	return member, err
+}
+
 // NewRefreshToken creates new refresh token.
 func (ts *JWTokenService) NewRefreshToken(u model.User, scopes []string, app model.AppData) (ijwt.Token, error) {
+	return ts.newRefreshToken(u, scopes, app, "")
+}
+
+// NewOrgRefreshToken creates new refresh token, which issues access tokens for the organization while the user is its member.
+func (ts *JWTokenService) NewOrgRefreshToken(u model.User, scopes []string, app model.AppData, orgID string) (ijwt.Token, error) {
+	if _, err := ts.orgMember(u, orgID); err != nil {
+		return nil, err
+	}
+	return ts.newRefreshToken(u, scopes, app, orgID)
+}
+
+func (ts *JWTokenService) newRefreshToken(u model.User, scopes []string, app model.AppData, orgID string) (ijwt.Token, error) {
 	if !app.Active() || !app.Offline() {
 		return nil, ErrInvalidApp
 
@@ -230,10 +383,13 @@ func (ts *JWTokenService) NewRefreshToken(u model.User, scopes []string, app mod
 		return nil, ErrInvalidUser
 	}
 
-	payload := make(map[string]string)
+	payload := make(map[string]interface{})
 	if contains(app.TokenPayload(), PayloadName) {
 		payload[PayloadName] = u.Username()
 	}
+	if orgID != "" {
+		payload[PayloadOrgID] = orgID
+	}
 	now := ijwt.TimeFunc().Unix()
 
 	lifespan := app.RefreshTokenLifespan()
@@ -278,6 +434,7 @@ func (ts *JWTokenService) NewRefreshToken(u model.User, scopes []string, app mod
 	if err := ts.tokenStorage.SaveToken(tokenString); err != nil {
 		return nil, ErrSavingToken
 	}
+	metrics.TokenIssued(RefrestTokenType)
 	return t, nil
 }
 
@@ -307,7 +464,17 @@ func (ts *JWTokenService) RefreshAccessToken(refreshToken ijwt.Token) (ijwt.Toke
 		return nil, ErrInvalidUser
 	}
 
-	token, err := ts.NewAccessToken(user, strings.Split(claims.Scopes, " "), app, false)
+	if claims.IssuedAt < user.TokensValidAfter() {
+		return nil, ErrTokenRevoked
+	}
+
+	// Organization tokens are refreshed only while the user is a member, with the current role.
+	var token ijwt.Token
+	if orgID, _ := claims.Payload[PayloadOrgID].(string); orgID != "" {
+		token, err = ts.NewOrgAccessToken(user, strings.Split(claims.Scopes, " "), app, orgID)
+	} else {
+		token, err = ts.NewAccessToken(user, strings.Split(claims.Scopes, " "), app, false)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -323,24 +490,27 @@ func (ts *JWTokenService) RefreshAccessToken(refreshToken ijwt.Token) (ijwt.Toke
 	return token, nil
 }
 
-// NewInviteToken creates new invite token.
-func (ts *JWTokenService) NewInviteToken() (ijwt.Token, error) {
-	payload := make(map[string]string)
-	// add payload data here
+// NewInviteToken creates new invite token for the stored invite.
+// Token ID refers to the invite, so the token can be used only while the invite is valid.
+func (ts *JWTokenService) NewInviteToken(invite model.Invite) (ijwt.Token, error) {
+	payload := map[string]interface{}{"email": invite.Email}
 
 	now := ijwt.TimeFunc().Unix()
 
-	lifespan := InviteTokenLifespan
+	expiresAt := invite.ExpiresAt
+	if expiresAt == 0 {
+		expiresAt = now + InviteTokenLifespan
+	}
 
 	claims := ijwt.Claims{
 		Payload: payload,
 		Type:    InviteTokenType,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: now + lifespan,
+			Id:        invite.ID,
+			ExpiresAt: expiresAt,
 			Issuer:    ts.issuer,
-			// Subject:   u.ID(),
-			Audience: "identifo",
-			IssuedAt: now,
+			Audience:  invite.AppID,
+			IssuedAt:  now,
 		},
 	}
 
@@ -358,6 +528,7 @@ func (ts *JWTokenService) NewInviteToken() (ijwt.Token, error) {
 	if token == nil {
 		return nil, ErrCreatingToken
 	}
+	metrics.TokenIssued(InviteTokenType)
 	return &ijwt.JWToken{JWT: token, New: true}, nil
 }
 
@@ -393,6 +564,7 @@ func (ts *JWTokenService) NewResetToken(userID string) (ijwt.Token, error) {
 		return nil, ErrCreatingToken
 	}
 
+	metrics.TokenIssued(ResetTokenType)
 	return &ijwt.JWToken{JWT: token, New: true}, nil
 }
 
@@ -430,6 +602,7 @@ func (ts *JWTokenService) NewWebCookieToken(u model.User) (ijwt.Token, error) {
 		return nil, ErrCreatingToken
 	}
 
+	metrics.TokenIssued(WebCookieTokenType)
 	return &ijwt.JWToken{JWT: token, New: true}, nil
 }
 
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ijwt "github.com/madappgang/identifo/jwt"
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/storage/boltdb"
+	"github.com/madappgang/identifo/storage/mem"
+)
+
+func TestRefreshAccessTokenRevocation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "identifo-jwt")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := boltdb.InitDB(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatalf("Error opening database: %s", err)
+	}
+	defer db.Close()
+
+	us, _ := boltdb.NewUserStorage(db)
+	as, _ := mem.NewAppStorage()
+	tstor, _ := mem.NewTokenStorage()
+	app := mem.MakeAppData("123456", "1", true, "test", "", []string{"offline"}, true, []string{}, 0, 0, 0, nil, true, true, model.TFAStatusDisabled, "", model.NoAuthz, "", "", []string{}, []string{}, "user")
+	as.CreateApp(&app)
+
+	private, _ := ijwt.LoadPrivateKeyFromPEM("../private.pem", ijwt.TokenSignatureAlgorithmES256)
+	public, _ := ijwt.LoadPublicKeyFromPEM("../public.pem", ijwt.TokenSignatureAlgorithmES256)
+	ts, err := NewJWTokenService(&model.JWTKeys{Private: private, Public: public, Algorithm: ijwt.TokenSignatureAlgorithmES256}, "identifo.test", tstor, as, us)
+	if err != nil {
+		t.Fatalf("Error creating token service: %s", err)
+	}
+
+	user, _ := us.AddUserByNameAndPassword("john", "Password1!", "user", false)
+	refreshToken := func() ijwt.Token {
+		token, err := ts.NewRefreshToken(user, []string{"offline"}, &app)
+		if err != nil {
+			t.Fatalf("Error creating refresh token: %s", err)
+		}
+		tokenString, _ := ts.String(token)
+		if token, err = ts.Parse(tokenString); err != nil {
+			t.Fatalf("Error parsing refresh token: %s", err)
+		}
+		return token
+	}
+
+	token := refreshToken()
+	if _, err = ts.RefreshAccessToken(token); err != nil {
+		t.Fatalf("Error refreshing access token: %s", err)
+	}
+
+	// Revocation sets the next second, so tokens issued in the second of the revocation are revoked too.
+	us.SetTokensValidAfter(user.ID(), token.IssuedAt()+1)
+	if _, err = ts.RefreshAccessToken(token); err != ErrTokenRevoked {
+		t.Errorf("RefreshAccessToken() with revoked token error = %v, expected %v", err, ErrTokenRevoked)
+	}
+
+	us.SetTokensValidAfter(user.ID(), token.IssuedAt())
+	token = refreshToken()
+	user.SetActive(false)
+	us.UpdateUser(user.ID(), user)
+	if _, err = ts.RefreshAccessToken(token); err != ErrInvalidUser {
+		t.Errorf("RefreshAccessToken() of deactivated user error = %v, expected %v", err, ErrInvalidUser)
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/madappgang/identifo/model"
+)
+
+// tokenPayload maps the app token payload fields to the access token claims.
+// Field is either a source, or "claim=source". Metadata sources keep the JSON type of the value.
+// Fields with unknown sources or missing values are skipped.
+func tokenPayload(u model.User, scopes []string, app model.AppData) map[string]interface{} {
+	payload := make(map[string]interface{})
+	for _, field := range app.TokenPayload() {
+		claim, source := parsePayloadField(field)
+		if claim == "" {
+			continue
+		}
+		if value, ok := payloadValue(u, scopes, source); ok {
+			payload[claim] = value
+		}
+	}
+	return payload
+}
+
+// parsePayloadField splits the field into claim name and source.
+// Without explicit claim name, metadata fields are named by the last path element.
+func parsePayloadField(field string) (claim, source string) {
+	field = strings.TrimSpace(field)
+	if i := strings.Index(field, "="); i >= 0 {
+		return strings.TrimSpace(field[:i]), strings.TrimSpace(field[i+1:])
+	}
+	path := strings.Split(field, ".")
+	return path[len(path)-1], field
+}
+
+func payloadValue(u model.User, scopes []string, source string) (interface{}, bool) {
+	switch source {
+	case PayloadName:
+		return u.Username(), true
+	case PayloadEmail:
+		return u.Email(), u.Email() != ""
+	case PayloadPhone:
+		return u.Phone(), u.Phone() != ""
+	case PayloadRole:
+		return u.AccessRole(), u.AccessRole() != ""
+	case PayloadScopes:
+		return scopes, len(scopes) > 0
+	}
+
+	path := strings.Split(source, ".")
+	var metadata map[string]interface{}
+	switch path[0] {
+	case PayloadUserMetadata:
+		metadata = u.UserMetadata()
+	case PayloadAppMetadata:
+		metadata = u.AppMetadata()
+	default:
+		return nil, false
+	}
+	if metadata == nil {
+		return nil, false
+	}
+	if len(path) == 1 {
+		return metadata, true
+	}
+	return metadataValue(metadata, path[1:])
+}
+
+// metadataValue returns the value at the path of nested metadata objects.
+func metadataValue(metadata map[string]interface{}, path []string) (interface{}, bool) {
+	value, ok := metadata[path[0]]
+	if !ok || len(path) == 1 {
+		return value, ok
+	}
+	nested, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return metadataValue(nested, path[1:])
+}
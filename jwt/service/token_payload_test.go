@@ -0,0 +1,40 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/storage/mem"
+)
+
+func TestTokenPayload(t *testing.T) {
+	us, _ := mem.NewUserStorage()
+	user, _ := us.AddUserByNameAndPassword("john", "Password1!", "user", false)
+	user.SetUsername("john")
+	user.SetUserMetadata(map[string]interface{}{"locale": "en"})
+
+	// Metadata is decoded from JSON, as it is stored.
+	appMetadata := map[string]interface{}{}
+	json.Unmarshal([]byte(`{"billing":{"plan":"pro","seats":5},"groups":["a","b"]}`), &appMetadata)
+	user.SetAppMetadata(appMetadata)
+
+	scopes := []string{"read", "write"}
+	fields := []string{"name", "scopes", "user_metadata.locale", "plan=app_metadata.billing.plan",
+		"app_metadata.billing.seats", "app_metadata.groups", "billing=app_metadata.billing", "app_metadata.missing", "unknown"}
+	app := mem.MakeAppData("123456", "1", true, "test", "", scopes, true, []string{}, 0, 0, 0, fields, true, true, model.TFAStatusDisabled, "", model.NoAuthz, "", "", []string{}, []string{}, "user")
+
+	want := map[string]interface{}{
+		"name":    "john",
+		"scopes":  scopes,
+		"locale":  "en",
+		"plan":    "pro",
+		"seats":   float64(5),
+		"groups":  []interface{}{"a", "b"},
+		"billing": map[string]interface{}{"plan": "pro", "seats": float64(5)},
+	}
+	if got := tokenPayload(user, scopes, &app); !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenPayload() = %+v, want %+v", got, want)
+	}
+}
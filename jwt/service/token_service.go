@@ -24,8 +24,11 @@ const (
 type TokenService interface {
 	NewAccessToken(u model.User, scopes []string, app model.AppData, requireTFA bool) (ijwt.Token, error)
 	NewRefreshToken(u model.User, scopes []string, app model.AppData) (ijwt.Token, error)
+	// NewOrgAccessToken and NewOrgRefreshToken issue tokens for the user acting on behalf of the organization.
+	NewOrgAccessToken(u model.User, scopes []string, app model.AppData, orgID string) (ijwt.Token, error)
+	NewOrgRefreshToken(u model.User, scopes []string, app model.AppData, orgID string) (ijwt.Token, error)
 	RefreshAccessToken(token ijwt.Token) (ijwt.Token, error)
-	NewInviteToken() (ijwt.Token, error)
+	NewInviteToken(invite model.Invite) (ijwt.Token, error)
 	NewResetToken(userID string) (ijwt.Token, error)
 	NewWebCookieToken(u model.User) (ijwt.Token, error)
 	Parse(string) (ijwt.Token, error)
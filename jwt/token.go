@@ -20,9 +20,11 @@ const (
 // Token is an abstract application token.
 type Token interface {
 	Validate() error
+	ID() string
 	UserID() string
 	Type() string
-	Payload() map[string]string
+	IssuedAt() int64
+	Payload() map[string]interface{}
 }
 
 // NewTokenWithClaims generates new JWT token with claims and keyID.
@@ -55,6 +57,15 @@ func (t *JWToken) Validate() error {
 	return nil
 }
 
+// ID returns token ID.
+func (t *JWToken) ID() string {
+	claims, ok := t.JWT.Claims.(*Claims)
+	if !ok {
+		return ""
+	}
+	return claims.Id
+}
+
 // UserID returns user ID.
 func (t *JWToken) UserID() string {
 	claims, ok := t.JWT.Claims.(*Claims)
@@ -65,10 +76,10 @@ func (t *JWToken) UserID() string {
 }
 
 // Payload returns token payload.
-func (t *JWToken) Payload() map[string]string {
+func (t *JWToken) Payload() map[string]interface{} {
 	claims, ok := t.JWT.Claims.(*Claims)
 	if !ok {
-		return make(map[string]string)
+		return make(map[string]interface{})
 	}
 	return claims.Payload
 }
@@ -82,12 +93,21 @@ func (t *JWToken) Type() string {
 	return claims.Type
 }
 
+// IssuedAt returns token issue time.
+func (t *JWToken) IssuedAt() int64 {
+	claims, ok := t.JWT.Claims.(*Claims)
+	if !ok {
+		return 0
+	}
+	return claims.IssuedAt
+}
+
 // Claims is an extended claims structure.
 type Claims struct {
-	Payload map[string]string `json:"payload,omitempty"`
-	Scopes  string            `json:"scopes,omitempty"`
-	Type    string            `json:"type,omitempty"`
-	KeyID   string            `json:"kid,omitempty"` // optional keyID
+	Payload map[string]interface{} `json:"payload,omitempty"`
+	Scopes  string                 `json:"scopes,omitempty"`
+	Type    string                 `json:"type,omitempty"`
+	KeyID   string                 `json:"kid,omitempty"` // optional keyID
 	jwt.StandardClaims
 }
 
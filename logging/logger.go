@@ -0,0 +1,283 @@
+// Package logging writes structured JSON logs with levels, request ID correlation and redaction of secrets.
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is a logging level.
+type Level int8
+
+// Logging levels.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// String implements fmt.Stringer interface.
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel parses level name. Empty name is info level.
+func ParseLevel(name string) (Level, error) {
+	if len(name) == 0 {
+		return LevelInfo, nil
+	}
+	for level, n := range levelNames {
+		if strings.EqualFold(n, name) {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("Unknown logging level %s", name)
+}
+
+// StatusLevel returns level of the entry about the response with the HTTP status code.
+// Server errors are errors, client errors are expected in normal operation.
+func StatusLevel(status int) Level {
+	if status >= 500 {
+		return LevelError
+	}
+	return LevelInfo
+}
+
+// Logger writes log entries as JSON objects, one per line.
+// Loggers derived with With and WithRequest share the output.
+type Logger struct {
+	out    *output
+	level  Level
+	fields []field
+	// sampleRate is a share of requests logged at debug level.
+	sampleRate float64
+	// debug is false when the request of the logger is not sampled.
+	debug bool
+}
+
+type output struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+type field struct {
+	key   string
+	value interface{}
+}
+
+// New creates logger writing entries of the level and above.
+// At debug level only the share of requests set by debugSampleRate is logged in detail, all requests if it is not in (0, 1).
+func New(w io.Writer, level Level, debugSampleRate float64) *Logger {
+	if debugSampleRate <= 0 || debugSampleRate > 1 {
+		debugSampleRate = 1
+	}
+	return &Logger{
+		out:        &output{w: w},
+		level:      level,
+		sampleRate: debugSampleRate,
+		debug:      true,
+	}
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultLogger = New(os.Stdout, LevelInfo, 1)
+)
+
+// Default returns the logger used by storages and services, which do not get the logger explicitly.
+func Default() *Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+// SetDefault replaces the default logger.
+func SetDefault(l *Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = l
+}
+
+// With returns logger adding the field to every entry. Values of sensitive fields are redacted.
+func (l *Logger) With(key string, value interface{}) *Logger {
+	if IsSensitive(key) {
+		value = Redacted
+	}
+	c := *l
+	c.fields = append(append(make([]field, 0, len(l.fields)+1), l.fields...), field{key: key, value: value})
+	return &c
+}
+
+// Level returns the logger level.
+func (l *Logger) Level() Level {
+	return l.level
+}
+
+// Enabled checks if entries of the level are written.
+func (l *Logger) Enabled(level Level) bool {
+	if level == LevelDebug && !l.debug {
+		return false
+	}
+	return level >= l.level
+}
+
+// Logf writes entry of the level.
+func (l *Logger) Logf(level Level, format string, args ...interface{}) { l.logf(level, format, args...) }
+
+// Debugf writes debug entry.
+func (l *Logger) Debugf(format string, args ...interface{}) { l.logf(LevelDebug, format, args...) }
+
+// Infof writes info entry.
+func (l *Logger) Infof(format string, args ...interface{}) { l.logf(LevelInfo, format, args...) }
+
+// Warnf writes warning entry.
+func (l *Logger) Warnf(format string, args ...interface{}) { l.logf(LevelWarn, format, args...) }
+
+// Errorf writes error entry.
+func (l *Logger) Errorf(format string, args ...interface{}) { l.logf(LevelError, format, args...) }
+
+// Debug writes debug entry, formatting the arguments like fmt.Sprintln.
+func (l *Logger) Debug(args ...interface{}) { l.log(LevelDebug, args...) }
+
+// Info writes info entry, formatting the arguments like fmt.Sprintln.
+func (l *Logger) Info(args ...interface{}) { l.log(LevelInfo, args...) }
+
+// Warn writes warning entry, formatting the arguments like fmt.Sprintln.
+func (l *Logger) Warn(args ...interface{}) { l.log(LevelWarn, args...) }
+
+// Error writes error entry, formatting the arguments like fmt.Sprintln.
+func (l *Logger) Error(args ...interface{}) { l.log(LevelError, args...) }
+
+// Fatal writes error entry and exits.
+func (l *Logger) Fatal(args ...interface{}) {
+	l.log(LevelError, args...)
+	os.Exit(1)
+}
+
+// Fatalf writes error entry and exits.
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.logf(LevelError, format, args...)
+	os.Exit(1)
+}
+
+// Printf writes info entry. It makes the logger usable in place of log.Logger.
+func (l *Logger) Printf(format string, args ...interface{}) { l.logf(LevelInfo, format, args...) }
+
+// Println writes info entry. It makes the logger usable in place of log.Logger.
+func (l *Logger) Println(args ...interface{}) { l.log(LevelInfo, args...) }
+
+// StdLogger returns standard logger writing entries of the level, for libraries which need one.
+func (l *Logger) StdLogger(level Level) *log.Logger {
+	return log.New(levelWriter{logger: l, level: level}, "", 0)
+}
+
+type levelWriter struct {
+	logger *Logger
+	level  Level
+}
+
+func (w levelWriter) Write(p []byte) (int, error) {
+	w.logger.write(w.level, string(p), nil)
+	return len(p), nil
+}
+
+func (l *Logger) logf(level Level, format string, args ...interface{}) {
+	if l.Enabled(level) {
+		l.write(level, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+func (l *Logger) log(level Level, args ...interface{}) {
+	if l.Enabled(level) {
+		l.write(level, fmt.Sprintln(args...), nil)
+	}
+}
+
+// Entry writes entry with extra fields. Values of sensitive fields are redacted.
+func (l *Logger) Entry(level Level, message string, fields map[string]interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	extra := make([]field, 0, len(fields))
+	for _, key := range sortedKeys(fields) {
+		value := fields[key]
+		if IsSensitive(key) {
+			value = Redacted
+		}
+		extra = append(extra, field{key: key, value: value})
+	}
+	l.write(level, message, extra)
+}
+
+func (l *Logger) write(level Level, message string, extra []field) {
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"time":`)
+	writeValue(buf, time.Now().UTC().Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeValue(buf, level.String())
+	buf.WriteString(`,"msg":`)
+	writeValue(buf, strings.TrimSpace(message))
+	for _, fields := range [][]field{l.fields, extra} {
+		for _, f := range fields {
+			buf.WriteByte(',')
+			writeValue(buf, f.key)
+			buf.WriteByte(':')
+			writeValue(buf, f.value)
+		}
+	}
+	buf.WriteString("}\n")
+
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	l.out.w.Write(buf.Bytes())
+}
+
+func writeValue(buf *bytes.Buffer, value interface{}) {
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(data)
+}
+
+// Debugf writes debug entry with the default logger.
+func Debugf(format string, args ...interface{}) { Default().logf(LevelDebug, format, args...) }
+
+// Infof writes info entry with the default logger.
+func Infof(format string, args ...interface{}) { Default().logf(LevelInfo, format, args...) }
+
+// Warnf writes warning entry with the default logger.
+func Warnf(format string, args ...interface{}) { Default().logf(LevelWarn, format, args...) }
+
+// Errorf writes error entry with the default logger.
+func Errorf(format string, args ...interface{}) { Default().logf(LevelError, format, args...) }
+
+// Debug writes debug entry with the default logger.
+func Debug(args ...interface{}) { Default().log(LevelDebug, args...) }
+
+// Info writes info entry with the default logger.
+func Info(args ...interface{}) { Default().log(LevelInfo, args...) }
+
+// Warn writes warning entry with the default logger.
+func Warn(args ...interface{}) { Default().log(LevelWarn, args...) }
+
+// Error writes error entry with the default logger.
+func Error(args ...interface{}) { Default().log(LevelError, args...) }
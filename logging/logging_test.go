@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/urfave/negroni"
+)
+
+func entries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Entry %s is not JSON: %v", line, err)
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+func TestLoggerLevels(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(buf, LevelInfo, 1).With("router", "api").With("client_secret", "s3cr3t")
+
+	logger.Debugf("hidden %d", 1)
+	logger.Infof("User %s updated\n", "42")
+	logger.Error("Error putting item:", "timeout")
+
+	e := entries(t, buf)
+	if len(e) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(e))
+	}
+	if e[0]["level"] != "info" || e[0]["msg"] != "User 42 updated" || e[0]["router"] != "api" {
+		t.Errorf("Unexpected info entry %v", e[0])
+	}
+	if e[0]["client_secret"] != Redacted {
+		t.Errorf("Secret field is not redacted: %v", e[0])
+	}
+	if e[1]["level"] != "error" || e[1]["msg"] != "Error putting item: timeout" {
+		t.Errorf("Unexpected error entry %v", e[1])
+	}
+}
+
+func TestIsSensitive(t *testing.T) {
+	for _, name := range []string{"password", "new_password", "refresh_token", "tfa_code", "recovery_codes", "client_secret", "X-Auth-Token", "apiKey", "otp"} {
+		if !IsSensitive(name) {
+			t.Errorf("%s must be sensitive", name)
+		}
+	}
+	for _, name := range []string{"username", "scopes", "Accept-Encoding", "Content-Type", "password_env_name", "app_id"} {
+		if IsSensitive(name) {
+			t.Errorf("%s must not be sensitive", name)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(buf, LevelDebug, 1)
+
+	var handlerBody string
+	n := negroni.New(Middleware(logger))
+	n.UseHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		handlerBody = string(body)
+		logger.WithRequest(r).Debug("handled")
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	body := `{"username":"john","password":"qwerty","device":{"token":"abc"}}`
+	req := httptest.NewRequest(http.MethodPost, "/auth/login?token=xyz&app=1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Digest", "SHA-256=abc")
+	req.Header.Set(RequestIDHeader, "req-1")
+	rw := httptest.NewRecorder()
+	n.ServeHTTP(rw, req)
+
+	if handlerBody != body {
+		t.Errorf("Handler got body %s, expected %s", handlerBody, body)
+	}
+	if rw.Header().Get(RequestIDHeader) != "req-1" {
+		t.Errorf("Request ID is not sent back, got %s", rw.Header().Get(RequestIDHeader))
+	}
+	for _, secret := range []string{"qwerty", "xyz", "SHA-256=abc", `"abc"`} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("Log contains secret %s: %s", secret, buf.String())
+		}
+	}
+
+	e := entries(t, buf)
+	if len(e) != 3 {
+		t.Fatalf("Expected request, handler and completion entries, got %d", len(e))
+	}
+	for _, entry := range e {
+		if entry["request_id"] != "req-1" {
+			t.Errorf("Entry has no request ID: %v", entry)
+		}
+	}
+	if e[2]["status"] != float64(http.StatusCreated) {
+		t.Errorf("Unexpected completion entry %v", e[2])
+	}
+}
+
+func TestMiddlewareSampling(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(buf, LevelDebug, 1)
+	logger.sampleRate = 0
+
+	n := negroni.New(Middleware(logger))
+	n.UseHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.WithRequest(r).Debug("handled")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	e := entries(t, buf)
+	if len(e) != 1 || e[0]["level"] != "info" {
+		t.Fatalf("Expected only completion entry of not sampled request, got %v", e)
+	}
+	if id, _ := e[0]["request_id"].(string); len(id) != 32 {
+		t.Errorf("Invalid request ID must be replaced, got %q", id)
+	}
+}
@@ -0,0 +1,140 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	mrand "math/rand"
+	"net/http"
+	"time"
+
+	"github.com/urfave/negroni"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the header carrying request ID, it is taken from the request or generated.
+const RequestIDHeader = "X-Request-ID"
+
+// maxDumpedBody is the size of the request body part logged at debug level.
+const maxDumpedBody = 16 * 1024
+
+// maxRequestIDLength limits length of request ID taken from the request.
+const maxRequestIDLength = 64
+
+type contextKey int
+
+const requestContextKey contextKey = iota
+
+type requestInfo struct {
+	id      string
+	sampled bool
+}
+
+// Middleware returns negroni middleware which sets request ID, logs requests and, for sampled requests at debug level, their redacted contents.
+func Middleware(logger *Logger) negroni.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		start := time.Now()
+
+		info := requestInfo{
+			id:      requestID(r.Header.Get(RequestIDHeader)),
+			sampled: logger.sampleRate >= 1 || mrand.Float64() < logger.sampleRate,
+		}
+		rw.Header().Set(RequestIDHeader, info.id)
+		r = r.WithContext(context.WithValue(r.Context(), requestContextKey, info))
+
+		l := logger.WithRequest(r)
+		if l.Enabled(LevelDebug) {
+			l.Entry(LevelDebug, "Request", map[string]interface{}{
+				"method":  r.Method,
+				"path":    r.URL.Path,
+				"query":   RedactQuery(r.URL.Query()),
+				"headers": RedactHeaders(r.Header),
+				"body":    RedactBody(r.Header.Get("Content-Type"), peekBody(r)),
+			})
+		}
+
+		next(rw, r)
+
+		status := http.StatusOK
+		if nrw, ok := rw.(negroni.ResponseWriter); ok && nrw.Status() != 0 {
+			status = nrw.Status()
+		}
+		l.Entry(LevelInfo, "Request completed", map[string]interface{}{
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"status":      status,
+			"duration_ms": float64(time.Since(start).Microseconds()) / 1000,
+		})
+	}
+}
+
+// WithRequest returns logger adding ID of the request, and ID of the trace if the request is traced, to every entry.
+// Debug entries are written only if the request is sampled.
+func (l *Logger) WithRequest(r *http.Request) *Logger {
+	info, ok := r.Context().Value(requestContextKey).(requestInfo)
+	if !ok {
+		return l
+	}
+	c := l.With("request_id", info.id)
+	if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() && sc.IsSampled() {
+		c = c.With("trace_id", sc.TraceID().String())
+	}
+	c.debug = info.sampled
+	return c
+}
+
+// RequestID returns ID of the request, set by the middleware.
+func RequestID(r *http.Request) string {
+	info, _ := r.Context().Value(requestContextKey).(requestInfo)
+	return info.id
+}
+
+// Recovery returns negroni middleware which recovers from panics and logs them with the logger.
+// Unlike negroni.Classic one, it does not send the stack trace to the client.
+func Recovery(logger *Logger) *negroni.Recovery {
+	recovery := negroni.NewRecovery()
+	recovery.Logger = logger.StdLogger(LevelError)
+	recovery.PrintStack = false
+	return recovery
+}
+
+// requestID returns the ID if it is safe to log and to send back, new ID otherwise.
+func requestID(id string) string {
+	if len(id) > 0 && len(id) <= maxRequestIDLength {
+		valid := true
+		for _, c := range id {
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-' || c == '_' || c == '.') {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			return id
+		}
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// peekBody reads the beginning of the request body, leaving the body intact for the handlers.
+func peekBody(r *http.Request) []byte {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	head, err := ioutil.ReadAll(io.LimitReader(r.Body, maxDumpedBody))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	if err != nil {
+		return nil
+	}
+	return head
+}
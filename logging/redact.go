@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces values of sensitive fields and headers.
+const Redacted = "[REDACTED]"
+
+// sensitiveHeaders are headers, values of which are never logged.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"Digest":              true,
+}
+
+// sensitiveParts are parts of field names, values of which are never logged.
+// The list is wider than the audit log one, as requests carry one-time codes and tokens.
+var sensitiveParts = []string{"password", "pswd", "secret", "private_key", "privatekey", "api_key", "apikey", "token", "signature"}
+
+// sensitiveWords are words of field names, values of which are never logged. They are too short to be matched as parts.
+var sensitiveWords = map[string]bool{"code": true, "codes": true, "otp": true, "totp": true, "key": true, "digest": true}
+
+// IsSensitive checks if the value of the field, query parameter or header must not be logged.
+func IsSensitive(name string) bool {
+	name = strings.ToLower(strings.Replace(name, "-", "_", -1))
+	if strings.HasSuffix(name, "_env_name") {
+		return false
+	}
+	for _, part := range sensitiveParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	for _, word := range strings.Split(name, "_") {
+		if sensitiveWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHeaders returns the headers with sensitive values redacted.
+func RedactHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for name, values := range h {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] || IsSensitive(name) {
+			headers[name] = Redacted
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+	return headers
+}
+
+// RedactQuery returns the query parameters with sensitive values redacted.
+func RedactQuery(q url.Values) map[string]string {
+	params := make(map[string]string, len(q))
+	for name, values := range q {
+		if IsSensitive(name) {
+			params[name] = Redacted
+			continue
+		}
+		params[name] = strings.Join(values, ", ")
+	}
+	return params
+}
+
+// RedactBody returns loggable representation of the request body with sensitive values redacted.
+// JSON and form bodies are logged as objects, others are logged by size only.
+func RedactBody(contentType string, body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err == nil {
+			return redactJSON(v)
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			return RedactQuery(form)
+		}
+	}
+	return fmt.Sprintf("[%d bytes]", len(body))
+}
+
+func redactJSON(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			if IsSensitive(key) {
+				value[key] = Redacted
+			} else {
+				value[key] = redactJSON(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactJSON(item)
+		}
+	}
+	return v
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
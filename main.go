@@ -9,6 +9,7 @@ import (
 	configStoreEtcd "github.com/madappgang/identifo/configuration/storage/etcd"
 	configWatcherEtcd "github.com/madappgang/identifo/configuration/watcher/etcd"
 	configWatcherGeneric "github.com/madappgang/identifo/configuration/watcher/generic"
+	"github.com/madappgang/identifo/metrics"
 	"github.com/madappgang/identifo/model"
 	"github.com/madappgang/identifo/server"
 	"github.com/madappgang/identifo/server/boltdb"
@@ -56,24 +57,7 @@ func initServer(configStorage model.ConfigurationStorage) model.Server {
 		log.Panicln("Cannot load server settings: ", err)
 	}
 
-	dbTypes := make(map[model.DatabaseType]bool)
-	var partialComposers []server.PartialDatabaseComposer
-
-	dbTypes[server.ServerSettings.Storage.AppStorage.Type] = true
-	dbTypes[server.ServerSettings.Storage.UserStorage.Type] = true
-	dbTypes[server.ServerSettings.Storage.TokenStorage.Type] = true
-	dbTypes[server.ServerSettings.Storage.TokenBlacklist.Type] = true
-	dbTypes[server.ServerSettings.Storage.VerificationCodeStorage.Type] = true
-
-	for dbType := range dbTypes {
-		pc, err := initPartialComposer(dbType, server.ServerSettings.Storage)
-		if err != nil {
-			log.Panicf("Cannot init partial composer for db type %s: %s\n", dbType, err)
-		}
-		partialComposers = append(partialComposers, pc)
-	}
-
-	dbComposer, err := server.NewComposer(server.ServerSettings, partialComposers)
+	dbComposer, err := initDatabaseComposer(server.ServerSettings)
 	if err != nil {
 		log.Panicln("Cannot init database composer:", err)
 	}
@@ -93,7 +77,58 @@ func initServer(configStorage model.ConfigurationStorage) model.Server {
 		}
 	}
 
-	return srv
+	if len(server.ServerSettings.Tenants) == 0 {
+		return srv
+	}
+
+	tenantServers := make(map[string]model.Server)
+	for _, tenant := range server.ServerSettings.Tenants {
+		tenantSettings := server.ServerSettings.TenantServerSettings(tenant)
+
+		tenantDBComposer, err := initDatabaseComposer(tenantSettings)
+		if err != nil {
+			log.Panicf("Cannot init database composer for tenant %s: %s\n", tenant.ID, err)
+		}
+
+		tenantServers[tenant.ID], err = server.NewTenantServer(tenantSettings, tenant, tenantDBComposer, configStorage, nil)
+		if err != nil {
+			log.Panicf("Cannot init server for tenant %s: %s\n", tenant.ID, err)
+		}
+		log.Printf("Tenant %s initialized\n", tenant.ID)
+	}
+
+	return server.NewMultiTenantServer(srv, server.ServerSettings.Tenants, tenantServers)
+}
+
+func initDatabaseComposer(settings model.ServerSettings) (server.DatabaseComposer, error) {
+	dbTypes := make(map[model.DatabaseType]bool)
+	var partialComposers []server.PartialDatabaseComposer
+
+	dbTypes[settings.Storage.AppStorage.Type] = true
+	dbTypes[settings.Storage.UserStorage.Type] = true
+	dbTypes[settings.Storage.TokenStorage.Type] = true
+	dbTypes[settings.Storage.TokenBlacklist.Type] = true
+	dbTypes[settings.Storage.VerificationCodeStorage.Type] = true
+	dbTypes[settings.Storage.InviteStorage.Type] = true
+	dbTypes[settings.Storage.UserSessionStorage.Type] = true
+	dbTypes[settings.Storage.AdminStorage.Type] = true
+	dbTypes[settings.Storage.AuditStorage.Type] = true
+	dbTypes[settings.Storage.AuthEventStorage.Type] = true
+	dbTypes[settings.Storage.WebhookStorage.Type] = true
+	dbTypes[settings.Storage.OrganizationStorage.Type] = true
+	dbTypes[settings.Storage.SCIMTokenStorage.Type] = true
+	dbTypes[settings.Storage.PolicyStorage.Type] = true
+	dbTypes[settings.Storage.RoleStorage.Type] = true
+
+	for dbType := range dbTypes {
+		pc, err := initPartialComposer(dbType, settings.Storage)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot init partial composer for db type %s: %s", dbType, err)
+		}
+		partialComposers = append(partialComposers, pc)
+	}
+
+	return server.NewComposer(settings, partialComposers)
 }
 
 func initWatcher(httpSrv *http.Server, srv model.Server) model.ConfigurationWatcher {
@@ -126,6 +161,7 @@ func initWatcher(httpSrv *http.Server, srv model.Server) model.ConfigurationWatc
 	go func() {
 		for event := range cw.WatchChan() {
 			log.Printf("New event from watcher: %+v\n", event)
+			metrics.ConfigReloaded()
 			if err := configStorage.LoadServerSettings(&server.ServerSettings); err != nil {
 				log.Panicln("Cannot reload server configuration: ", err)
 			}
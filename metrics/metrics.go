@@ -0,0 +1,141 @@
+// Package metrics collects Prometheus metrics of the server.
+// Metrics are always collected, and exposed only when enabled in the server settings.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "identifo"
+
+// Result label values.
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+var (
+	// registry is not the default one, so metrics survive the server re-creation on config reload.
+	registry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "logins_total",
+		Help:      "Number of login attempts by method, app and result.",
+	}, []string{"method", "app_id", "result"})
+
+	tokensIssued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "tokens_issued_total",
+		Help:      "Number of issued tokens by type.",
+	}, []string{"type"})
+
+	smsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sms",
+		Name:      "sent_total",
+		Help:      "Number of SMS sending attempts by provider and result.",
+	}, []string{"provider", "result"})
+
+	emailsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "email",
+		Name:      "sent_total",
+		Help:      "Number of email sending attempts by provider and result.",
+	}, []string{"provider", "result"})
+
+	storageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "operation_duration_seconds",
+		Help:      "Storage call latency by backend, operation and result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"backend", "operation", "result"})
+
+	configReloads = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "config",
+		Name:      "reloads_total",
+		Help:      "Number of server configuration reloads.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		logins,
+		tokensIssued,
+		smsSent,
+		emailsSent,
+		storageDuration,
+		configReloads,
+	)
+}
+
+// Handler returns the handler exposing the metrics.
+// Requests must have bearer token in Authorization header, if the token is set.
+func Handler(token string) http.Handler {
+	h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	if token == "" {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// TokenIssued counts the issued token of the type.
+func TokenIssued(tokenType string) {
+	tokensIssued.WithLabelValues(tokenType).Inc()
+}
+
+// ConfigReloaded counts the server configuration reload.
+func ConfigReloaded() {
+	configReloads.Inc()
+}
+
+// ObserveStorage records the storage call latency.
+// Backends report it from their driver hooks, BoltDB is embedded and is not observed.
+func ObserveStorage(backend, operation string, duration time.Duration, err error) {
+	storageDuration.WithLabelValues(backend, operation, result(err == nil)).Observe(duration.Seconds())
+}
+
+func result(success bool) string {
+	if success {
+		return resultSuccess
+	}
+	return resultFailure
+}
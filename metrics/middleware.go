@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/urfave/negroni"
+)
+
+// unmatchedRoute is the route label of requests not matched by any route, so random paths do not create new series.
+const unmatchedRoute = "unmatched"
+
+// Middleware returns negroni middleware that counts requests and measures their latency.
+func Middleware() negroni.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		start := time.Now()
+		r = TrackRoute(r)
+		next(rw, r)
+
+		status := http.StatusOK
+		if nrw, ok := rw.(negroni.ResponseWriter); ok && nrw.Status() != 0 {
+			status = nrw.Status()
+		}
+		labels := []string{MatchedRoute(r), r.Method, strconv.Itoa(status)}
+		httpRequests.WithLabelValues(labels...).Inc()
+		httpDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	}
+}
+
+// routeKey is the context key of the route matched by the routers serving the request.
+type routeKey struct{}
+
+// matchedRoute is the route matched by the routers serving the request.
+type matchedRoute struct {
+	// path is the request path before any prefix is stripped.
+	path     string
+	template string
+}
+
+// routeVariable matches route variables with their patterns, like "{id:[a-zA-Z0-9]+}".
+var routeVariable = regexp.MustCompile(`\{([^:{}]+):([^{}]*)\}`)
+
+// TrackRoute returns the request carrying the holder of the route matched by the routers serving it.
+// The request is returned as is, if it already carries one.
+func TrackRoute(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(routeKey{}).(*matchedRoute); ok {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, &matchedRoute{path: r.URL.Path}))
+}
+
+// RouteMiddleware records the path template of the route matched by gorilla/mux router, adding the prefix stripped before the router.
+// Routers add it with Use, so the routes of nested routers replace the routes of the outer ones.
+func RouteMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if matched, ok := r.Context().Value(routeKey{}).(*matchedRoute); ok {
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					matched.template = strings.TrimSuffix(matched.path, r.URL.Path) + template
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MatchedRoute returns the route label of the request served with TrackRoute, or "unmatched", if no route matched it.
+// Patterns of the route variables are omitted.
+func MatchedRoute(r *http.Request) string {
+	matched, ok := r.Context().Value(routeKey{}).(*matchedRoute)
+	if !ok || matched.template == "" {
+		return unmatchedRoute
+	}
+	return routeVariable.ReplaceAllStringFunc(matched.template, func(v string) string {
+		m := routeVariable.FindStringSubmatch(v)
+		// Routes with optional trailing slash, like "{login:login/?}", match only the name.
+		if strings.TrimSuffix(m[2], "/?") == m[1] {
+			return m[1]
+		}
+		return "{" + m[1] + "}"
+	})
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/urfave/negroni"
+)
+
+func TestMatchedRoute(t *testing.T) {
+	noop := func(w http.ResponseWriter, r *http.Request) {}
+
+	// API router with the nested router behind the middleware rejecting requests before routing.
+	api := mux.NewRouter()
+	api.Use(RouteMiddleware)
+	auth := mux.NewRouter().PathPrefix("/auth").Subrouter()
+	auth.Use(RouteMiddleware)
+	api.PathPrefix("/auth").Handler(negroni.New(
+		negroni.HandlerFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			if r.Header.Get("X-Identifo-Clientid") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			next(w, r)
+		}),
+		negroni.Wrap(auth),
+	))
+	auth.Path(`/{login:login/?}`).HandlerFunc(noop)
+
+	// Admin router mounted with the prefix stripped.
+	admin := mux.NewRouter()
+	admin.Use(RouteMiddleware)
+	admin.Path("/users/{id:[a-zA-Z0-9]+}").HandlerFunc(noop)
+
+	root := http.NewServeMux()
+	root.Handle("/", api)
+	root.Handle("/admin/", http.StripPrefix("/admin", admin))
+
+	tests := []struct {
+		path     string
+		clientID string
+		expected string
+	}{
+		{"/auth/login", "app", "/auth/login"},
+		{"/auth/login/", "app", "/auth/login"},
+		{"/auth/unknown-42", "app", "/auth"},
+		{"/auth/unknown-42", "", "/auth"},
+		{"/admin/users/5e5f1b8c0d2f4a0001a1b2c3", "", "/admin/users/{id}"},
+		{"/admin/unknown/42", "", unmatchedRoute},
+		{"/random/path", "", unmatchedRoute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := TrackRoute(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			req.Header.Set("X-Identifo-Clientid", tt.clientID)
+			root.ServeHTTP(httptest.NewRecorder(), req)
+
+			if route := MatchedRoute(req); route != tt.expected {
+				t.Errorf("MatchedRoute() = %q, expected %q", route, tt.expected)
+			}
+		})
+	}
+}
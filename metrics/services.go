@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"html/template"
+
+	"github.com/madappgang/identifo/model"
+)
+
+// AuthEventSink returns the sink counting login events, to be passed to the auth event recorder.
+func AuthEventSink() model.AuthEventSink {
+	return authEventSink{}
+}
+
+type authEventSink struct{}
+
+// SendAuthEvent implements model.AuthEventSink interface.
+func (authEventSink) SendAuthEvent(event model.AuthEvent) error {
+	if event.Type == model.AuthEventLogin {
+		logins.WithLabelValues(string(event.Method), event.AppID, result(event.Success)).Inc()
+	}
+	return nil
+}
+
+// InstrumentSMSService counts SMS sent with the service of the provider.
+func InstrumentSMSService(sms model.SMSService, provider string) model.SMSService {
+	if sms == nil {
+		return nil
+	}
+	return &smsService{SMSService: sms, provider: provider}
+}
+
+type smsService struct {
+	model.SMSService
+	provider string
+}
+
+// SendSMS implements model.SMSService interface.
+func (s *smsService) SendSMS(recipient, message string) error {
+	err := s.SMSService.SendSMS(recipient, message)
+	smsSent.WithLabelValues(s.provider, result(err == nil)).Inc()
+	return err
+}
+
+// InstrumentEmailService counts emails sent with the service of the provider.
+func InstrumentEmailService(es model.EmailService, provider string) model.EmailService {
+	if es == nil {
+		return nil
+	}
+	return &emailService{EmailService: es, provider: provider}
+}
+
+type emailService struct {
+	model.EmailService
+	provider string
+}
+
+func (s *emailService) count(err error) error {
+	emailsSent.WithLabelValues(s.provider, result(err == nil)).Inc()
+	return err
+}
+
+// SendMessage implements model.EmailService interface.
+func (s *emailService) SendMessage(subject, body, recipient string) error {
+	return s.count(s.EmailService.SendMessage(subject, body, recipient))
+}
+
+// SendHTML implements model.EmailService interface.
+func (s *emailService) SendHTML(subject, html, recipient string) error {
+	return s.count(s.EmailService.SendHTML(subject, html, recipient))
+}
+
+// SendTemplateEmail implements model.EmailService interface.
+func (s *emailService) SendTemplateEmail(subject, recipient string, template *template.Template, data interface{}) error {
+	return s.count(s.EmailService.SendTemplateEmail(subject, recipient, template, data))
+}
+
+// SendResetEmail implements model.EmailService interface.
+func (s *emailService) SendResetEmail(subject, recipient string, data interface{}) error {
+	return s.count(s.EmailService.SendResetEmail(subject, recipient, data))
+}
+
+// SendInviteEmail implements model.EmailService interface.
+func (s *emailService) SendInviteEmail(subject, recipient string, data interface{}) error {
+	return s.count(s.EmailService.SendInviteEmail(subject, recipient, data))
+}
+
+// SendWelcomeEmail implements model.EmailService interface.
+func (s *emailService) SendWelcomeEmail(subject, recipient string, data interface{}) error {
+	return s.count(s.EmailService.SendWelcomeEmail(subject, recipient, data))
+}
+
+// SendVerifyEmail implements model.EmailService interface.
+func (s *emailService) SendVerifyEmail(subject, recipient string, data interface{}) error {
+	return s.count(s.EmailService.SendVerifyEmail(subject, recipient, data))
+}
+
+// SendTFAEmail implements model.EmailService interface.
+func (s *emailService) SendTFAEmail(subject, recipient string, data interface{}) error {
+	return s.count(s.EmailService.SendTFAEmail(subject, recipient, data))
+}
@@ -0,0 +1,166 @@
+package model
+
+import (
+	"errors"
+
+	"github.com/xlzd/gotp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// tfaCodePeriod is a lifetime of TOTP codes in seconds, the default one of gotp and authenticator apps.
+const tfaCodePeriod = 30
+
+var (
+	// ErrAdminNotFound is when admin account not found.
+	ErrAdminNotFound = errors.New("Admin not found")
+	// ErrAdminExists is when admin account with the same email already exists.
+	ErrAdminExists = errors.New("Admin with this email already exists")
+)
+
+// AdminRole is a role of the admin account, which defines what the admin can do in the admin panel.
+type AdminRole string
+
+const (
+	// AdminRoleOwner can do everything, including server settings and admin accounts management.
+	AdminRoleOwner AdminRole = "owner"
+	// AdminRoleAppManager can manage apps and invites.
+	AdminRoleAppManager AdminRole = "app-manager"
+	// AdminRoleUserSupport can manage users, their sessions and two-factor authentication.
+	AdminRoleUserSupport AdminRole = "user-support"
+	// AdminRoleReadOnly can only view apps, users and invites.
+	AdminRoleReadOnly AdminRole = "read-only"
+)
+
+// IsValid checks if the role is one of the known admin roles.
+func (r AdminRole) IsValid() bool {
+	switch r {
+	case AdminRoleOwner, AdminRoleAppManager, AdminRoleUserSupport, AdminRoleReadOnly:
+		return true
+	}
+	return false
+}
+
+// AdminStorage stores admin panel accounts.
+type AdminStorage interface {
+	// AddAdmin saves new admin and returns it with generated ID. Emails are unique.
+	AddAdmin(admin Admin) (Admin, error)
+	AdminByID(id string) (Admin, error)
+	AdminByEmail(email string) (Admin, error)
+	FetchAdmins() ([]Admin, error)
+	// UpdateAdmin replaces stored admin with the given one.
+	UpdateAdmin(admin Admin) (Admin, error)
+	DeleteAdmin(id string) error
+	Close()
+}
+
+// Admin is an admin panel account.
+// Invited admin has no password until the invite is accepted.
+// TFASecret is set on enrolment, but TOTP codes are required on login only after TFAEnabled is set.
+// TFALastStep is the time step of the last accepted TOTP code, which cannot be used again.
+type Admin struct {
+	ID                 string    `json:"id" bson:"_id"`
+	Email              string    `json:"email" bson:"email"`
+	PasswordHash       string    `json:"password_hash,omitempty" bson:"password_hash,omitempty"`
+	Role               AdminRole `json:"role" bson:"role"`
+	Disabled           bool      `json:"disabled,omitempty" bson:"disabled,omitempty"`
+	InviteHash         string    `json:"invite_hash,omitempty" bson:"invite_hash,omitempty"`
+	InviteExpiresAt    int64     `json:"invite_expires_at,omitempty" bson:"invite_expires_at,omitempty"`
+	InvitedBy          string    `json:"invited_by,omitempty" bson:"invited_by,omitempty"`
+	CreatedAt          int64     `json:"created_at" bson:"created_at"`
+	TFASecret          string    `json:"tfa_secret,omitempty" bson:"tfa_secret,omitempty"`
+	TFAEnabled         bool      `json:"tfa_enabled,omitempty" bson:"tfa_enabled,omitempty"`
+	TFALastStep        int64     `json:"tfa_last_step,omitempty" bson:"tfa_last_step,omitempty"`
+	RecoveryCodeHashes []string  `json:"recovery_code_hashes,omitempty" bson:"recovery_code_hashes,omitempty"`
+}
+
+// SetPassword hashes and sets admin password. Pending invite is dropped.
+func (a *Admin) SetPassword(password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	a.PasswordHash = string(hash)
+	a.InviteHash = ""
+	a.InviteExpiresAt = 0
+	return nil
+}
+
+// CheckPassword checks if the password matches the admin one.
+func (a Admin) CheckPassword(password string) bool {
+	if a.PasswordHash == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(a.PasswordHash), []byte(password)) == nil
+}
+
+// SetInvite hashes and sets the token admin has to present to accept the invite.
+func (a *Admin) SetInvite(token string, expiresAt int64) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(token), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	a.InviteHash = string(hash)
+	a.InviteExpiresAt = expiresAt
+	return nil
+}
+
+// CheckInvite checks if the invite token is valid at the moment.
+func (a Admin) CheckInvite(token string, now int64) bool {
+	if a.InviteHash == "" || now >= a.InviteExpiresAt {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(a.InviteHash), []byte(token)) == nil
+}
+
+// IsPending checks if the admin has not accepted the invite yet.
+func (a Admin) IsPending() bool {
+	return a.PasswordHash == ""
+}
+
+// SetRecoveryCodes hashes and sets one-time codes to log in when TOTP device is lost.
+func (a *Admin) SetRecoveryCodes(codes []string) error {
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		hashes[i] = string(hash)
+	}
+	a.RecoveryCodeHashes = hashes
+	return nil
+}
+
+// UseTFACode checks TOTP code at the time and remembers its time step, so the code cannot be replayed.
+// Admin has to be saved after the code is used.
+func (a *Admin) UseTFACode(code string, now int64) bool {
+	step := now / tfaCodePeriod
+	if a.TFASecret == "" || step <= a.TFALastStep {
+		return false
+	}
+	if !gotp.NewDefaultTOTP(a.TFASecret).Verify(code, int(now)) {
+		return false
+	}
+	a.TFALastStep = step
+	return true
+}
+
+// UseRecoveryCode checks the recovery code and removes it, so it cannot be used again.
+// Admin has to be saved after the code is used.
+func (a *Admin) UseRecoveryCode(code string) bool {
+	for i, hash := range a.RecoveryCodeHashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(code)) == nil {
+			a.RecoveryCodeHashes = append(a.RecoveryCodeHashes[:i:i], a.RecoveryCodeHashes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ResetTFA disables two-factor authentication and drops recovery codes.
+func (a *Admin) ResetTFA() {
+	a.TFASecret = ""
+	a.TFAEnabled = false
+	a.TFALastStep = 0
+	a.RecoveryCodeHashes = nil
+}
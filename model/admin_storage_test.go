@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/xlzd/gotp"
+)
+
+func TestAdminUseTFACode(t *testing.T) {
+	admin := Admin{TFASecret: gotp.RandomSecret(16)}
+	totp := gotp.NewDefaultTOTP(admin.TFASecret)
+	now := int64(1600000000)
+
+	wrong := "000000"
+	if totp.At(int(now)) == wrong {
+		wrong = "111111"
+	}
+	if admin.UseTFACode(wrong, now) {
+		t.Errorf("UseTFACode() accepted wrong code")
+	}
+	if !admin.UseTFACode(totp.At(int(now)), now) {
+		t.Fatalf("UseTFACode() rejected valid code")
+	}
+	if admin.UseTFACode(totp.At(int(now)), now+1) {
+		t.Errorf("UseTFACode() accepted replayed code")
+	}
+	if admin.UseTFACode(totp.At(int(now-tfaCodePeriod)), now) {
+		t.Errorf("UseTFACode() accepted code of the previous period")
+	}
+	if !admin.UseTFACode(totp.At(int(now+tfaCodePeriod)), now+tfaCodePeriod) {
+		t.Errorf("UseTFACode() rejected code of the next period")
+	}
+
+	admin.ResetTFA()
+	if admin.UseTFACode(totp.At(int(now+2*tfaCodePeriod)), now+2*tfaCodePeriod) {
+		t.Errorf("UseTFACode() accepted code after TFA reset")
+	}
+}
+
+func TestAdminUseRecoveryCode(t *testing.T) {
+	admin := Admin{}
+	if err := admin.SetRecoveryCodes([]string{"first", "second"}); err != nil {
+		t.Fatalf("Error setting recovery codes: %s", err)
+	}
+
+	if admin.UseRecoveryCode("unknown") {
+		t.Errorf("UseRecoveryCode() accepted unknown code")
+	}
+	if !admin.UseRecoveryCode("second") {
+		t.Fatalf("UseRecoveryCode() rejected valid code")
+	}
+	if admin.UseRecoveryCode("second") {
+		t.Errorf("UseRecoveryCode() accepted used code")
+	}
+	if len(admin.RecoveryCodeHashes) != 1 || !admin.UseRecoveryCode("first") {
+		t.Errorf("Recovery codes left = %d, expected the first one to be valid", len(admin.RecoveryCodeHashes))
+	}
+}
@@ -15,6 +15,12 @@ type AppStorage interface {
 	Close()
 }
 
+// ConnectionTester is implemented by storages which can test connection to their database.
+// Health checks test every storage implementing it.
+type ConnectionTester interface {
+	TestDatabaseConnection() error
+}
+
 // AppData represents Application data information.
 type AppData interface {
 	ID() string
@@ -38,6 +44,8 @@ type AppData interface {
 	// RefreshTokenLifespan is a refreshToken lifespan in seconds, if 0 - default one is used.
 	RefreshTokenLifespan() int64
 	// Payload is a list of fields that are included in token. If it's empty, there are no fields in payload.
+	// Field is either a source, or "claim=source" to rename the claim. Sources are name, email, phone, role, scopes,
+	// and user_metadata or app_metadata optionally followed by the dotted path, like "app_metadata.billing.plan".
 	TokenPayload() []string
 	Sanitize()
 	TFAStatus() TFAStatus
@@ -51,6 +59,9 @@ type AppData interface {
 	RolesBlacklist() []string
 	NewUserDefaultRole() string
 	AppleInfo() *AppleInfo
+	GoogleInfo() *GoogleInfo
+	GitHubInfo() *GitHubInfo
+	OIDCInfo() *OIDCInfo
 	SetSecret(secret string)
 }
 
@@ -0,0 +1,55 @@
+package model
+
+import "encoding/json"
+
+// AuditStorage is an append-only storage of admin actions.
+type AuditStorage interface {
+	// AddAuditEvent saves new event and returns it with generated ID.
+	AddAuditEvent(event AuditEvent) (AuditEvent, error)
+	// FetchAuditEvents returns events matching the filter, newest first, and total number of matching events.
+	FetchAuditEvents(filter AuditEventFilter, skip, limit int) ([]AuditEvent, int, error)
+	Close()
+}
+
+// AuditEvent is a record of the change made through admin panel.
+type AuditEvent struct {
+	ID         string        `json:"id" bson:"_id"`
+	AdminID    string        `json:"admin_id" bson:"admin_id"`
+	AdminEmail string        `json:"admin_email" bson:"admin_email"`
+	SessionID  string        `json:"session_id,omitempty" bson:"session_id,omitempty"`
+	Action     string        `json:"action" bson:"action"`
+	TargetType string        `json:"target_type" bson:"target_type"`
+	TargetID   string        `json:"target_id,omitempty" bson:"target_id,omitempty"`
+	Changes    []AuditChange `json:"changes,omitempty" bson:"changes,omitempty"`
+	IP         string        `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent  string        `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	CreatedAt  int64         `json:"created_at" bson:"created_at"`
+}
+
+// AuditChange is a change of a single field, with JSON encoded values.
+// Field is a dot-separated path, like "tfa_info.is_enabled".
+type AuditChange struct {
+	Field  string          `json:"field" bson:"field"`
+	Before json.RawMessage `json:"before,omitempty" bson:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+// AuditEventFilter filters audit events. Empty fields match all events.
+type AuditEventFilter struct {
+	AdminID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       int64
+	To         int64
+}
+
+// Matches checks if the event matches the filter.
+func (f AuditEventFilter) Matches(e AuditEvent) bool {
+	return (f.AdminID == "" || e.AdminID == f.AdminID) &&
+		(f.Action == "" || e.Action == f.Action) &&
+		(f.TargetType == "" || e.TargetType == f.TargetType) &&
+		(f.TargetID == "" || e.TargetID == f.TargetID) &&
+		(f.From == 0 || e.CreatedAt >= f.From) &&
+		(f.To == 0 || e.CreatedAt <= f.To)
+}
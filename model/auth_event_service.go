@@ -0,0 +1,105 @@
+package model
+
+import (
+	"sync"
+	"time"
+
+	"github.com/madappgang/identifo/logging"
+)
+
+// AuthEventSink receives authentication events in addition to the storage, like log collectors.
+type AuthEventSink interface {
+	SendAuthEvent(event AuthEvent) error
+}
+
+// authEventQueueSize is a number of events waiting to be sent to the sinks, newer events are dropped when it is full.
+const authEventQueueSize = 1024
+
+// AuthEventService records authentication events.
+// Recording never fails the authentication, errors are only logged.
+type AuthEventService interface {
+	Record(event AuthEvent)
+	// Close sends queued events to the sinks and stops sending.
+	Close()
+}
+
+// AuthEventRecorder is a default authentication event service.
+// It saves events to the storage and passes them to the sinks, if any.
+// Sinks are called one by one by the single background worker, so slow sinks cannot pile up goroutines.
+type AuthEventRecorder struct {
+	authEventStorage AuthEventStorage
+	sinks            []AuthEventSink
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan AuthEvent
+	done   chan struct{}
+}
+
+// NewAuthEventRecorder creates new authentication event recorder. Sinks are optional, nil ones are skipped.
+func NewAuthEventRecorder(aes AuthEventStorage, sinks ...AuthEventSink) AuthEventService {
+	er := &AuthEventRecorder{
+		authEventStorage: aes,
+		queue:            make(chan AuthEvent, authEventQueueSize),
+		done:             make(chan struct{}),
+	}
+	for _, sink := range sinks {
+		if sink != nil {
+			er.sinks = append(er.sinks, sink)
+		}
+	}
+	go er.run()
+	return er
+}
+
+// Record saves the event and queues it for the sinks.
+// When the queue is full, the event is not sent to the sinks, so recording never blocks.
+func (er *AuthEventRecorder) Record(event AuthEvent) {
+	if event.CreatedAt == 0 {
+		event.CreatedAt = time.Now().Unix()
+	}
+
+	if saved, err := er.authEventStorage.AddAuthEvent(event); err != nil {
+		logging.Errorf("Cannot save auth event %s: %s\n", event.Type, err)
+	} else {
+		event = saved
+	}
+
+	if len(er.sinks) == 0 {
+		return
+	}
+
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+	if er.closed {
+		return
+	}
+	select {
+	case er.queue <- event:
+	default:
+		logging.Errorf("Auth event queue is full, event %s is not sent to sinks\n", event.Type)
+	}
+}
+
+// Close sends queued events to the sinks and stops the worker. Events recorded after Close are only saved.
+func (er *AuthEventRecorder) Close() {
+	er.mu.Lock()
+	if !er.closed {
+		er.closed = true
+		close(er.queue)
+	}
+	er.mu.Unlock()
+	<-er.done
+}
+
+func (er *AuthEventRecorder) run() {
+	defer close(er.done)
+
+	for event := range er.queue {
+		for _, sink := range er.sinks {
+			if err := sink.SendAuthEvent(event); err != nil {
+				logging.Errorf("Cannot send auth event %s to sink: %s\n", event.Type, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testAuthEventStorage keeps events in a slice.
+type testAuthEventStorage struct {
+	mu     sync.Mutex
+	events []AuthEvent
+}
+
+func (s *testAuthEventStorage) AddAuthEvent(event AuthEvent) (AuthEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event.ID = "saved"
+	s.events = append(s.events, event)
+	return event, nil
+}
+
+func (s *testAuthEventStorage) FetchAuthEvents(filter AuthEventFilter, skip, limit int) ([]AuthEvent, int, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (s *testAuthEventStorage) Close() {}
+
+// testAuthEventSink records events and the number of concurrent calls. Calls wait for release, if set.
+type testAuthEventSink struct {
+	mu       sync.Mutex
+	events   []AuthEvent
+	calls    int32
+	maxCalls int32
+	release  chan struct{}
+}
+
+func (s *testAuthEventSink) SendAuthEvent(event AuthEvent) error {
+	calls := atomic.AddInt32(&s.calls, 1)
+	defer atomic.AddInt32(&s.calls, -1)
+
+	s.mu.Lock()
+	if calls > s.maxCalls {
+		s.maxCalls = calls
+	}
+	s.events = append(s.events, event)
+	s.mu.Unlock()
+
+	if s.release != nil {
+		<-s.release
+	}
+	return nil
+}
+
+func TestAuthEventRecorder(t *testing.T) {
+	storage := &testAuthEventStorage{}
+	first, second := &testAuthEventSink{}, &testAuthEventSink{}
+	er := NewAuthEventRecorder(storage, first, nil, second)
+
+	for i := 0; i < 100; i++ {
+		er.Record(AuthEvent{Type: AuthEventLogin, UserID: "user", Success: true})
+	}
+	er.Close()
+
+	if len(storage.events) != 100 {
+		t.Errorf("Saved events = %d, expected 100", len(storage.events))
+	}
+	for _, sink := range []*testAuthEventSink{first, second} {
+		if len(sink.events) != 100 {
+			t.Errorf("Sent events = %d, expected 100", len(sink.events))
+		}
+		if sink.maxCalls != 1 {
+			t.Errorf("Concurrent sink calls = %d, expected 1", sink.maxCalls)
+		}
+		if sink.events[0].ID != "saved" || sink.events[0].CreatedAt == 0 {
+			t.Errorf("Sent event = %+v, expected the saved one", sink.events[0])
+		}
+	}
+
+	// Events recorded after Close are only saved.
+	er.Record(AuthEvent{Type: AuthEventLogout})
+	if len(storage.events) != 101 || len(first.events) != 100 {
+		t.Errorf("Event recorded after Close: saved %d, sent %d", len(storage.events), len(first.events))
+	}
+}
+
+func TestAuthEventRecorderQueueFull(t *testing.T) {
+	storage := &testAuthEventStorage{}
+	sink := &testAuthEventSink{release: make(chan struct{})}
+	er := NewAuthEventRecorder(storage, sink)
+
+	// The sink is stuck, so the queue fills up, but recording does not block.
+	recorded := make(chan struct{})
+	go func() {
+		for i := 0; i < 2*authEventQueueSize; i++ {
+			er.Record(AuthEvent{Type: AuthEventLogin})
+		}
+		close(recorded)
+	}()
+	select {
+	case <-recorded:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Record() blocks when the queue is full")
+	}
+
+	close(sink.release)
+	er.Close()
+
+	if len(storage.events) != 2*authEventQueueSize {
+		t.Errorf("Saved events = %d, expected %d", len(storage.events), 2*authEventQueueSize)
+	}
+	// The event taken by the stuck sink is not in the queue anymore.
+	if len(sink.events) > authEventQueueSize+1 {
+		t.Errorf("Sent events = %d, expected at most %d", len(sink.events), authEventQueueSize+1)
+	}
+}
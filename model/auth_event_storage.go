@@ -0,0 +1,128 @@
+package model
+
+// AuthEventStorage is an append-only storage of user authentication events.
+type AuthEventStorage interface {
+	// AddAuthEvent saves new event and returns it with generated ID.
+	AddAuthEvent(event AuthEvent) (AuthEvent, error)
+	// FetchAuthEvents returns events matching the filter, newest first, and total number of matching events.
+	FetchAuthEvents(filter AuthEventFilter, skip, limit int) ([]AuthEvent, int, error)
+	Close()
+}
+
+// AuthEventType is a type of authentication event.
+type AuthEventType string
+
+const (
+	// AuthEventLogin is a login attempt.
+	AuthEventLogin AuthEventType = "login"
+	// AuthEventTFAChallenge is sent when login needs to be finalized with two-factor authentication code.
+	AuthEventTFAChallenge AuthEventType = "tfa_challenge"
+	// AuthEventTFAVerification is a check of two-factor authentication code.
+	AuthEventTFAVerification AuthEventType = "tfa_verification"
+	// AuthEventTokenRefresh is an exchange of refresh token for new tokens.
+	AuthEventTokenRefresh AuthEventType = "token_refresh"
+	// AuthEventLogout is a logout.
+	AuthEventLogout AuthEventType = "logout"
+	// AuthEventPasswordResetRequest is a request of password reset email.
+	AuthEventPasswordResetRequest AuthEventType = "password_reset_request"
+	// AuthEventPasswordReset is a password change with reset token.
+	AuthEventPasswordReset AuthEventType = "password_reset"
+	// AuthEventRegistration is a registration of the new user.
+	AuthEventRegistration AuthEventType = "registration"
+)
+
+// AuthMethod is a way the user has authenticated with.
+type AuthMethod string
+
+const (
+	// AuthMethodPassword is authentication with username and password.
+	AuthMethodPassword AuthMethod = "password"
+	// AuthMethodPhone is authentication with phone verification code.
+	AuthMethodPhone AuthMethod = "phone"
+	// AuthMethodFederated is authentication with federated identity provider.
+	AuthMethodFederated AuthMethod = "federated"
+	// AuthMethodRefreshToken is authentication with refresh token.
+	AuthMethodRefreshToken AuthMethod = "refresh_token"
+	// AuthMethodTFAApp is two-factor authentication with authenticator app.
+	AuthMethodTFAApp AuthMethod = "tfa_app"
+	// AuthMethodTFASMS is two-factor authentication with code sent in SMS.
+	AuthMethodTFASMS AuthMethod = "tfa_sms"
+	// AuthMethodTFAEmail is two-factor authentication with code sent on email.
+	AuthMethodTFAEmail AuthMethod = "tfa_email"
+)
+
+// AuthFailureReason is a reason of failed authentication step.
+type AuthFailureReason string
+
+const (
+	// AuthFailureInvalidCredentials is a wrong username or password.
+	AuthFailureInvalidCredentials AuthFailureReason = "invalid_credentials"
+	// AuthFailureInvalidCode is a wrong verification or two-factor authentication code.
+	AuthFailureInvalidCode AuthFailureReason = "invalid_code"
+	// AuthFailureUserNotFound is when there is no such user.
+	AuthFailureUserNotFound AuthFailureReason = "user_not_found"
+	// AuthFailureUserExists is when the user is already registered.
+	AuthFailureUserExists AuthFailureReason = "user_exists"
+	// AuthFailureWeakPassword is when the password is not strong enough.
+	AuthFailureWeakPassword AuthFailureReason = "weak_password"
+	// AuthFailureAccessDenied is when the user is not authorized to use the app.
+	AuthFailureAccessDenied AuthFailureReason = "access_denied"
+	// AuthFailureScopesForbidden is when requested scopes are not allowed for the user.
+	AuthFailureScopesForbidden AuthFailureReason = "scopes_forbidden"
+	// AuthFailureTFAPolicy is when user two-factor authentication settings do not match app requirements.
+	AuthFailureTFAPolicy AuthFailureReason = "tfa_policy"
+	// AuthFailureProviderError is when federated identity provider has not confirmed the identity.
+	AuthFailureProviderError AuthFailureReason = "provider_error"
+	// AuthFailureTokenNotIssued is when tokens cannot be issued.
+	AuthFailureTokenNotIssued AuthFailureReason = "token_not_issued"
+	// AuthFailureHookDenied is when the auth hook endpoint has denied the step.
+	AuthFailureHookDenied AuthFailureReason = "hook_denied"
+)
+
+// TFAAuthMethod returns authentication method for two-factor authentication type.
+func TFAAuthMethod(tfaType TFAType) AuthMethod {
+	switch tfaType {
+	case TFATypeSMS:
+		return AuthMethodTFASMS
+	case TFATypeEmail:
+		return AuthMethodTFAEmail
+	}
+	return AuthMethodTFAApp
+}
+
+// AuthEvent is a record of user authentication step.
+// UserID is empty when the user is not known, like on login with unknown username.
+type AuthEvent struct {
+	ID            string            `json:"id" bson:"_id"`
+	Type          AuthEventType     `json:"type" bson:"type"`
+	Method        AuthMethod        `json:"method,omitempty" bson:"method,omitempty"`
+	Provider      string            `json:"provider,omitempty" bson:"provider,omitempty"`
+	Success       bool              `json:"success" bson:"success"`
+	FailureReason AuthFailureReason `json:"failure_reason,omitempty" bson:"failure_reason,omitempty"`
+	UserID        string            `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	AppID         string            `json:"app_id,omitempty" bson:"app_id,omitempty"`
+	IP            string            `json:"ip,omitempty" bson:"ip,omitempty"`
+	UserAgent     string            `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	CreatedAt     int64             `json:"created_at" bson:"created_at"`
+}
+
+// AuthEventFilter filters authentication events. Empty fields match all events.
+type AuthEventFilter struct {
+	UserID string
+	AppID  string
+	Type   AuthEventType
+	// Success filters events by result, when set.
+	Success *bool
+	From    int64
+	To      int64
+}
+
+// Matches checks if the event matches the filter.
+func (f AuthEventFilter) Matches(e AuthEvent) bool {
+	return (f.UserID == "" || e.UserID == f.UserID) &&
+		(f.AppID == "" || e.AppID == f.AppID) &&
+		(f.Type == "" || e.Type == f.Type) &&
+		(f.Success == nil || e.Success == *f.Success) &&
+		(f.From == 0 || e.CreatedAt >= f.From) &&
+		(f.To == 0 || e.CreatedAt <= f.To)
+}
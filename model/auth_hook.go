@@ -0,0 +1,68 @@
+package model
+
+import "errors"
+
+// ErrAuthHookDenied is when the external service denies the authentication step.
+var ErrAuthHookDenied = errors.New("Denied by auth hook")
+
+// AuthHookType is a point of authentication flow where the hook is called.
+type AuthHookType string
+
+const (
+	// AuthHookPreRegistration is called before the new user is created.
+	AuthHookPreRegistration AuthHookType = "pre_registration"
+	// AuthHookPreLogin is called after the user is authenticated, before the login is finished.
+	AuthHookPreLogin AuthHookType = "pre_login"
+	// AuthHookPreToken is called before the access token is issued and can add claims to its payload.
+	AuthHookPreToken AuthHookType = "pre_token"
+	// AuthHookAuthorization is called for apps with external authorization.
+	AuthHookAuthorization AuthHookType = "authorization"
+)
+
+// IsValid checks if the hook type is known.
+func (t AuthHookType) IsValid() bool {
+	switch t {
+	case AuthHookPreRegistration, AuthHookPreLogin, AuthHookPreToken, AuthHookAuthorization:
+		return true
+	}
+	return false
+}
+
+// AuthHookRequest is sent to the hook endpoint. Fields not known at the hook point are empty.
+type AuthHookRequest struct {
+	Hook     AuthHookType              `json:"hook"`
+	AppID    string                    `json:"app_id,omitempty"`
+	UserID   string                    `json:"user_id,omitempty"`
+	Username string                    `json:"username,omitempty"`
+	Email    string                    `json:"email,omitempty"`
+	Phone    string                    `json:"phone,omitempty"`
+	Role     string                    `json:"role,omitempty"`
+	Method   AuthMethod                `json:"method,omitempty"`
+	Provider FederatedIdentityProvider `json:"provider,omitempty"`
+	Scopes   []string                  `json:"scopes,omitempty"`
+	// OrgID and OrgRole are set when the user acts on behalf of the organization.
+	OrgID   string `json:"org_id,omitempty"`
+	OrgRole string `json:"org_role,omitempty"`
+	// ResourceURI and HTTPMethod are the request being authorized.
+	ResourceURI string `json:"resource_uri,omitempty"`
+	HTTPMethod  string `json:"http_method,omitempty"`
+	IP          string `json:"ip,omitempty"`
+	UserAgent   string `json:"user_agent,omitempty"`
+}
+
+// AuthHookResponse is returned by the hook endpoint.
+type AuthHookResponse struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"`
+	// Claims are added to the access token payload, only for pre-token hook.
+	Claims map[string]interface{} `json:"claims,omitempty"`
+}
+
+// AuthHookService calls the external service at the hook points of authentication flow.
+type AuthHookService interface {
+	// Enabled checks if the hook is configured.
+	Enabled(hook AuthHookType) bool
+	// Call calls the hook. It returns ErrAuthHookDenied if the service denies the step.
+	// Hooks which are not enabled always allow.
+	Call(req AuthHookRequest) (AuthHookResponse, error)
+}
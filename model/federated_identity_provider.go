@@ -1,5 +1,11 @@
 package model
 
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
 // FederatedIdentityProvider is an external federated identity provider type.
 // If you are missing the provider you need, please feel free to add it here.
 type FederatedIdentityProvider string
@@ -9,16 +15,21 @@ var (
 	FacebookIDProvider FederatedIdentityProvider = "FACEBOOK"
 	// GoogleIDProvider is a Google ID provider.
 	GoogleIDProvider FederatedIdentityProvider = "GOOGLE"
-	// TwitterIDProvider is a Twitter ID provider.
+	// TwitterIDProvider is a Twitter ID provider. It is not implemented yet, so it is not valid.
 	TwitterIDProvider FederatedIdentityProvider = "TWITTER"
 	// AppleIDProvider is an Apple ID provider.
 	AppleIDProvider FederatedIdentityProvider = "APPLE"
+	// GitHubIDProvider is a GitHub ID provider.
+	GitHubIDProvider FederatedIdentityProvider = "GITHUB"
+	// OIDCIDProvider is a generic OpenID Connect provider configured per app.
+	OIDCIDProvider FederatedIdentityProvider = "OIDC"
 )
 
 // IsValid has to be called everywhere input happens, otherwise you risk to operate on bad data - no guarantees.
+// Only providers which are implemented are valid.
 func (fid FederatedIdentityProvider) IsValid() bool {
 	switch fid {
-	case FacebookIDProvider, GoogleIDProvider, TwitterIDProvider, AppleIDProvider:
+	case FacebookIDProvider, GoogleIDProvider, AppleIDProvider, GitHubIDProvider, OIDCIDProvider:
 		return true
 	}
 	return false
@@ -29,3 +40,112 @@ type AppleInfo struct {
 	ClientID     string `json:"client_id,omitempty" bson:"client_id,omitempty"`
 	ClientSecret string `json:"client_secret,omitempty" bson:"client_secret,omitempty"`
 }
+
+// GoogleInfo represents the information needed for Sign In with Google.
+type GoogleInfo struct {
+	ClientID     string `json:"client_id,omitempty" bson:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty" bson:"client_secret,omitempty"`
+}
+
+// GitHubInfo represents the information needed for Sign In with GitHub.
+type GitHubInfo struct {
+	ClientID     string `json:"client_id,omitempty" bson:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty" bson:"client_secret,omitempty"`
+}
+
+// OIDCInfo represents the information needed for signing in with a generic OpenID Connect provider.
+// Provider endpoints are obtained from the issuer's discovery document.
+type OIDCInfo struct {
+	Issuer       string   `json:"issuer,omitempty" bson:"issuer,omitempty"`
+	ClientID     string   `json:"client_id,omitempty" bson:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty" bson:"client_secret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty" bson:"scopes,omitempty"`
+}
+
+var (
+	// ErrFederatedProviderNotConfigured is when the app has no settings for the requested provider.
+	ErrFederatedProviderNotConfigured = errors.New("App is not configured for this federated identity provider")
+	// ErrFederatedProviderNotSupported is when there is no such provider in the registry.
+	ErrFederatedProviderNotSupported = errors.New("Federated identity provider is not supported")
+	// ErrFederatedEmptyUserID is when the provider did not return user ID.
+	ErrFederatedEmptyUserID = errors.New("Federated user id is not accessible")
+)
+
+// FederatedCredentials are the credentials the client obtained from the federated identity provider.
+// Every provider uses only the fields it needs.
+type FederatedCredentials struct {
+	AccessToken       string
+	AuthorizationCode string
+	IDToken           string
+	RedirectURI       string
+	Nonce             string
+}
+
+// FederatedIdentity is the user identity confirmed by the federated identity provider.
+type FederatedIdentity struct {
+	ID    string
+	Email string
+	Name  string
+}
+
+// FederatedIdentityVerifier verifies credentials with the federated identity provider and returns user identity.
+type FederatedIdentityVerifier interface {
+	Identity(app AppData, credentials FederatedCredentials) (FederatedIdentity, error)
+}
+
+// FederatedRedirectProvider is a provider that supports browser redirect flow.
+// User is redirected to the provider's authorization URL, and then back to the redirect URI with authorization code,
+// which is passed to Identity along with the same redirect URI and nonce.
+type FederatedRedirectProvider interface {
+	FederatedIdentityVerifier
+	IsConfigured(app AppData) bool
+	AuthCodeURL(app AppData, redirectURI, state, nonce string) (string, error)
+}
+
+// FederatedProviderRegistry keeps federated identity providers by name.
+type FederatedProviderRegistry struct {
+	mu        sync.RWMutex
+	providers map[FederatedIdentityProvider]FederatedIdentityVerifier
+}
+
+// NewFederatedProviderRegistry creates empty federated provider registry.
+func NewFederatedProviderRegistry() *FederatedProviderRegistry {
+	return &FederatedProviderRegistry{providers: make(map[FederatedIdentityProvider]FederatedIdentityVerifier)}
+}
+
+// Register adds provider to the registry, replacing the existing one with the same name.
+func (r *FederatedProviderRegistry) Register(name FederatedIdentityProvider, provider FederatedIdentityVerifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[FederatedIdentityProvider(strings.ToUpper(string(name)))] = provider
+}
+
+// Provider returns provider by name. Name is case insensitive.
+func (r *FederatedProviderRegistry) Provider(name string) (FederatedIdentityProvider, FederatedIdentityVerifier, error) {
+	if r == nil {
+		return "", nil, ErrFederatedProviderNotSupported
+	}
+	fid := FederatedIdentityProvider(strings.ToUpper(name))
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[fid]
+	if !ok {
+		return fid, nil, ErrFederatedProviderNotSupported
+	}
+	return fid, p, nil
+}
+
+// Names returns names of all registered providers.
+func (r *FederatedProviderRegistry) Names() []FederatedIdentityProvider {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]FederatedIdentityProvider, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	return names
+}
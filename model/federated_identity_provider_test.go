@@ -0,0 +1,24 @@
+package model
+
+import "testing"
+
+func TestFederatedIdentityProviderIsValid(t *testing.T) {
+	tests := []struct {
+		fid   FederatedIdentityProvider
+		valid bool
+	}{
+		{FacebookIDProvider, true},
+		{GoogleIDProvider, true},
+		{AppleIDProvider, true},
+		{GitHubIDProvider, true},
+		{OIDCIDProvider, true},
+		{TwitterIDProvider, false},
+		{"google", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if valid := tt.fid.IsValid(); valid != tt.valid {
+			t.Errorf("%q.IsValid() = %v, expected %v", tt.fid, valid, tt.valid)
+		}
+	}
+}
@@ -10,4 +10,8 @@ const (
 	TokenContextKey
 	//TokenRawContextKey bearer token context key in raw format
 	TokenRawContextKey
+	//AdminContextKey context key to keep logged in admin account
+	AdminContextKey
+	//ClientIPContextKey context key to keep client IP address resolved from trusted proxy headers
+	ClientIPContextKey
 )
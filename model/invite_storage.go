@@ -0,0 +1,47 @@
+package model
+
+import "errors"
+
+var (
+	// ErrInviteNotFound is when invite not found.
+	ErrInviteNotFound = errors.New("Invite not found")
+	// ErrInviteNotValid is when invite is already used, revoked or expired.
+	ErrInviteNotValid = errors.New("Invite is used, revoked or expired")
+)
+
+// InviteStorage stores invitations to register in the app.
+type InviteStorage interface {
+	// AddInvite saves new invite and returns it with generated ID.
+	AddInvite(invite Invite) (Invite, error)
+	InviteByID(id string) (Invite, error)
+	FetchInvites(appID, email string, skip, limit int) ([]Invite, int, error)
+	RevokeInvite(id string) error
+	// ConsumeInvite marks valid invite as used by the user. Only one call per invite succeeds, others get ErrInviteNotValid.
+	ConsumeInvite(id, userID string) error
+	Close()
+}
+
+// Invite is an invitation for the particular email to register in the app.
+type Invite struct {
+	ID        string   `json:"id" bson:"_id"`
+	AppID     string   `json:"app_id" bson:"app_id"`
+	Email     string   `json:"email" bson:"email"`
+	Role      string   `json:"role,omitempty" bson:"role,omitempty"`
+	Scopes    []string `json:"scopes,omitempty" bson:"scopes,omitempty"`
+	InvitedBy string   `json:"invited_by,omitempty" bson:"invited_by,omitempty"`
+	CreatedAt int64    `json:"created_at" bson:"created_at"`
+	ExpiresAt int64    `json:"expires_at" bson:"expires_at"`
+	UsedBy    string   `json:"used_by,omitempty" bson:"used_by,omitempty"`
+	UsedAt    int64    `json:"used_at,omitempty" bson:"used_at,omitempty"`
+	Revoked   bool     `json:"revoked,omitempty" bson:"revoked,omitempty"`
+	// ByAdmin is set for invites created in the admin panel. Only they allow registration in apps where it is forbidden.
+	ByAdmin bool `json:"by_admin,omitempty" bson:"by_admin,omitempty"`
+	// OrgID and OrgRole make the invited user a member of the organization.
+	OrgID   string `json:"org_id,omitempty" bson:"org_id,omitempty"`
+	OrgRole string `json:"org_role,omitempty" bson:"org_role,omitempty"`
+}
+
+// IsValid checks that invite can be used at the moment.
+func (i Invite) IsValid(now int64) bool {
+	return !i.Revoked && i.UsedBy == "" && i.UsedAt == 0 && now < i.ExpiresAt
+}
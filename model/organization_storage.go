@@ -0,0 +1,62 @@
+package model
+
+import "errors"
+
+var (
+	// ErrOrganizationNotFound is when organization not found.
+	ErrOrganizationNotFound = errors.New("Organization not found")
+	// ErrOrganizationMemberNotFound is when the user is not a member of the organization.
+	ErrOrganizationMemberNotFound = errors.New("User is not a member of the organization")
+)
+
+// OrganizationStorage stores organizations and their members.
+type OrganizationStorage interface {
+	// AddOrganization saves new organization and returns it with generated ID.
+	AddOrganization(org Organization) (Organization, error)
+	OrganizationByID(id string) (Organization, error)
+	// FetchOrganizations returns organizations which name contains search string, and total number of them.
+	FetchOrganizations(search string, skip, limit int) ([]Organization, int, error)
+	UpdateOrganization(org Organization) (Organization, error)
+	// DeleteOrganization deletes the organization with all its memberships.
+	DeleteOrganization(id string) error
+
+	// SetMember adds the user to the organization or changes the role of the member.
+	SetMember(member OrganizationMember) (OrganizationMember, error)
+	Member(orgID, userID string) (OrganizationMember, error)
+	FetchMembers(orgID string) ([]OrganizationMember, error)
+	// UserMemberships returns all memberships of the user.
+	UserMemberships(userID string) ([]OrganizationMember, error)
+	RemoveMember(orgID, userID string) error
+	Close()
+}
+
+// Organization is a customer's tenant, which groups member users.
+type Organization struct {
+	ID        string `json:"id" bson:"_id"`
+	Name      string `json:"name" bson:"name"`
+	CreatedBy string `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	CreatedAt int64  `json:"created_at" bson:"created_at"`
+}
+
+// Organization member roles with special meaning. Other roles are app-defined.
+const (
+	// OrganizationRoleOwner can do everything with the organization, including managing other owners.
+	OrganizationRoleOwner = "owner"
+	// OrganizationRoleAdmin can invite and manage members, except owners.
+	OrganizationRoleAdmin = "admin"
+	// OrganizationRoleMember is a default role of the invited member.
+	OrganizationRoleMember = "member"
+)
+
+// OrganizationMember is a membership of the user in the organization.
+type OrganizationMember struct {
+	OrgID    string `json:"org_id" bson:"org_id"`
+	UserID   string `json:"user_id" bson:"user_id"`
+	Role     string `json:"role" bson:"role"`
+	JoinedAt int64  `json:"joined_at" bson:"joined_at"`
+}
+
+// CanManageMembers checks if the member can invite and manage other members.
+func (m OrganizationMember) CanManageMembers() bool {
+	return m.Role == OrganizationRoleOwner || m.Role == OrganizationRoleAdmin
+}
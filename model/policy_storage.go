@@ -0,0 +1,55 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrPolicyRuleNotFound is when policy rule not found.
+var ErrPolicyRuleNotFound = errors.New("Policy rule not found")
+
+// PolicyStorage stores Casbin policy rules of the apps with internal authorization.
+type PolicyStorage interface {
+	// AddPolicyRule saves new rule and returns it with generated ID.
+	AddPolicyRule(rule PolicyRule) (PolicyRule, error)
+	PolicyRuleByID(id string) (PolicyRule, error)
+	// FetchPolicyRules returns all rules of the app, in the order they were added.
+	FetchPolicyRules(appID string) ([]PolicyRule, error)
+	UpdatePolicyRule(rule PolicyRule) (PolicyRule, error)
+	DeletePolicyRule(id string) error
+	Close()
+}
+
+// PolicyRule is a single Casbin policy line, like "p, admin, /users, GET" or "g, alice, admin".
+type PolicyRule struct {
+	ID    string `json:"id" bson:"_id"`
+	AppID string `json:"app_id" bson:"app_id"`
+	// PType is a policy type from the app authz model, like "p" or "g".
+	PType  string   `json:"ptype" bson:"ptype"`
+	Values []string `json:"values" bson:"values"`
+}
+
+var policyTypeRegexp = regexp.MustCompile(`^[pg][0-9]*$`)
+
+// Validate checks that the rule is well-formed.
+func (pr PolicyRule) Validate() error {
+	if !policyTypeRegexp.MatchString(pr.PType) {
+		return fmt.Errorf("Invalid policy type %q", pr.PType)
+	}
+	if len(pr.Values) == 0 {
+		return fmt.Errorf("Policy rule values are empty")
+	}
+	for _, v := range pr.Values {
+		if strings.TrimSpace(v) == "" || strings.Contains(v, ",") {
+			return fmt.Errorf("Invalid policy rule value %q", v)
+		}
+	}
+	return nil
+}
+
+// Line returns the rule in Casbin policy file format.
+func (pr PolicyRule) Line() string {
+	return strings.Join(append([]string{pr.PType}, pr.Values...), ", ")
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// ErrRoleNotFound is when role not found.
+var ErrRoleNotFound = errors.New("Role not found")
+
+// RoleStorage stores the role catalogue of the apps and the roles assigned to the users.
+type RoleStorage interface {
+	// AddRole saves new role and returns it with generated ID.
+	AddRole(role Role) (Role, error)
+	RoleByID(id string) (Role, error)
+	// FetchRoles returns all roles of the app, sorted by name.
+	FetchRoles(appID string) ([]Role, error)
+	UpdateRole(role Role) (Role, error)
+	// DeleteRole deletes the role and unassigns it from the users.
+	DeleteRole(id string) error
+	// UserRoles returns names of the roles assigned to the user in the app.
+	UserRoles(appID, userID string) ([]string, error)
+	// SetUserRoles replaces the roles assigned to the user in the app. Empty list unassigns all of them.
+	SetUserRoles(appID, userID string, roles []string) error
+	Close()
+}
+
+// Role is a named set of permissions in the app. Role has permissions of the roles it inherits.
+type Role struct {
+	ID          string   `json:"id" bson:"_id"`
+	AppID       string   `json:"app_id" bson:"app_id"`
+	Name        string   `json:"name" bson:"name"`
+	Description string   `json:"description,omitempty" bson:"description,omitempty"`
+	Inherits    []string `json:"inherits,omitempty" bson:"inherits,omitempty"`
+	Permissions []string `json:"permissions,omitempty" bson:"permissions,omitempty"`
+}
+
+var roleNameRegexp = regexp.MustCompile(`^[\w.:-]+$`)
+
+// Validate checks the role against the other roles of the app.
+// Inherited roles must exist, and inheritance must not be cyclic.
+func (r Role) Validate(catalogue []Role) error {
+	if !roleNameRegexp.MatchString(r.Name) {
+		return fmt.Errorf("Invalid role name %q", r.Name)
+	}
+
+	byName := make(map[string]Role, len(catalogue)+1)
+	for _, role := range catalogue {
+		if role.Name == r.Name && role.ID != r.ID {
+			return fmt.Errorf("Role %s already exists", r.Name)
+		}
+		byName[role.Name] = role
+	}
+	byName[r.Name] = r
+
+	for _, name := range r.Inherits {
+		if _, ok := byName[name]; !ok || name == r.Name {
+			return fmt.Errorf("Invalid inherited role %q", name)
+		}
+	}
+
+	// Walk the inheritance from the role, it is cyclic if it gets back to the role.
+	seen := map[string]bool{}
+	queue := append([]string{}, r.Inherits...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if name == r.Name {
+			return fmt.Errorf("Role %s inherits itself", r.Name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		queue = append(queue, byName[name].Inherits...)
+	}
+	return nil
+}
+
+// ExpandRoles returns the given roles with all the roles they inherit, and the permissions of them all.
+// Roles missing from the catalogue are kept, as apps may use free-text roles.
+func ExpandRoles(catalogue []Role, names []string) (roles, permissions []string) {
+	byName := make(map[string]Role, len(catalogue))
+	for _, role := range catalogue {
+		byName[role.Name] = role
+	}
+
+	seenRoles, seenPermissions := map[string]bool{}, map[string]bool{}
+	queue := append([]string{}, names...)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if name == "" || seenRoles[name] {
+			continue
+		}
+		seenRoles[name] = true
+		roles = append(roles, name)
+
+		role := byName[name]
+		for _, p := range role.Permissions {
+			if !seenPermissions[p] {
+				seenPermissions[p] = true
+				permissions = append(permissions, p)
+			}
+		}
+		queue = append(queue, role.Inherits...)
+	}
+	sort.Strings(permissions)
+	return roles, permissions
+}
+
+// EffectiveRoles returns the access role and the roles assigned to the user in the app,
+// with all the roles they inherit and the permissions of them all.
+// User ID may be empty when the user is not created yet, then only the access role is expanded.
+func EffectiveRoles(rs RoleStorage, appID, userID, accessRole string) (roles, permissions []string, err error) {
+	names := []string{accessRole}
+	if userID != "" {
+		assigned, err := rs.UserRoles(appID, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		names = append(names, assigned...)
+	}
+
+	catalogue, err := rs.FetchRoles(appID)
+	if err != nil {
+		return nil, nil, err
+	}
+	roles, permissions = ExpandRoles(catalogue, names)
+	return roles, permissions, nil
+}
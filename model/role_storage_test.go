@@ -0,0 +1,51 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoleValidate(t *testing.T) {
+	catalogue := []Role{
+		{ID: "1", Name: "viewer"},
+		{ID: "2", Name: "editor", Inherits: []string{"viewer"}},
+		{ID: "3", Name: "admin", Inherits: []string{"editor"}},
+	}
+
+	tests := []struct {
+		name    string
+		role    Role
+		wantErr bool
+	}{
+		{"new role", Role{Name: "auditor", Inherits: []string{"viewer"}}, false},
+		{"invalid name", Role{Name: "a b"}, true},
+		{"duplicate name", Role{Name: "editor"}, true},
+		{"missing inherited role", Role{Name: "auditor", Inherits: []string{"owner"}}, true},
+		{"inherits itself", Role{ID: "2", Name: "editor", Inherits: []string{"editor"}}, true},
+		{"cycle", Role{ID: "1", Name: "viewer", Inherits: []string{"admin"}}, true},
+		{"update", Role{ID: "2", Name: "editor", Description: "Edits", Inherits: []string{"viewer"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.role.Validate(catalogue); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpandRoles(t *testing.T) {
+	catalogue := []Role{
+		{Name: "viewer", Permissions: []string{"article:read"}},
+		{Name: "editor", Inherits: []string{"viewer"}, Permissions: []string{"article:write", "article:read"}},
+		{Name: "billing", Permissions: []string{"invoice:read"}},
+	}
+
+	roles, permissions := ExpandRoles(catalogue, []string{"editor", "legacy", "", "billing", "viewer"})
+	if want := []string{"editor", "legacy", "billing", "viewer"}; !reflect.DeepEqual(roles, want) {
+		t.Errorf("ExpandRoles() roles = %v, want %v", roles, want)
+	}
+	if want := []string{"article:read", "article:write", "invoice:read"}; !reflect.DeepEqual(permissions, want) {
+		t.Errorf("ExpandRoles() permissions = %v, want %v", permissions, want)
+	}
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrSCIMTokenNotFound is when SCIM token not found.
+var ErrSCIMTokenNotFound = errors.New("SCIM token not found")
+
+// SCIMTokenStorage stores bearer tokens of SCIM provisioning clients, like Okta or Azure AD.
+// Only token hashes are stored, tokens themselves are shown to the admin once.
+type SCIMTokenStorage interface {
+	// AddSCIMToken saves new token and returns it with generated ID.
+	AddSCIMToken(token SCIMToken) (SCIMToken, error)
+	SCIMTokenByHash(hash string) (SCIMToken, error)
+	FetchSCIMTokens() ([]SCIMToken, error)
+	DeleteSCIMToken(id string) error
+	Close()
+}
+
+// SCIMToken is an admin-issued bearer token for SCIM API.
+type SCIMToken struct {
+	ID   string `json:"id" bson:"_id"`
+	Name string `json:"name" bson:"name"`
+	// Hash is a SHA-256 hash of the token, see HashSCIMToken.
+	Hash      string `json:"hash,omitempty" bson:"hash"`
+	CreatedBy string `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	CreatedAt int64  `json:"created_at" bson:"created_at"`
+}
+
+// HashSCIMToken returns the hash the token is stored by.
+func HashSCIMToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
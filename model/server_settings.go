@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"net"
 	"net/url"
 	"strings"
@@ -16,6 +17,13 @@ type ServerSettings struct {
 	StaticFilesStorage   StaticFilesStorageSettings   `yaml:"staticFilesStorage,omitempty" json:"static_files_storage,omitempty"`
 	ExternalServices     ExternalServicesSettings     `yaml:"externalServices,omitempty" json:"external_services,omitempty"`
 	Login                LoginSettings                `yaml:"login,omitempty" json:"login,omitempty"`
+	UserAttributes       UserAttributeSchema          `yaml:"userAttributes,omitempty" json:"user_attributes,omitempty"`
+	Metrics              MetricsSettings              `yaml:"metrics,omitempty" json:"metrics,omitempty"`
+	Logger               LoggerSettings               `yaml:"logger,omitempty" json:"logger,omitempty"`
+	Tracing              TracingSettings              `yaml:"tracing,omitempty" json:"tracing,omitempty"`
+	Health               HealthSettings               `yaml:"health,omitempty" json:"health,omitempty"`
+	// Tenants are served next to the default tenant, each with its own storages, keys and issuer.
+	Tenants []TenantSettings `yaml:"tenants,omitempty" json:"tenants,omitempty"`
 }
 
 // GeneralServerSettings are general server settings.
@@ -23,12 +31,97 @@ type GeneralServerSettings struct {
 	Host      string `yaml:"host,omitempty" json:"host,omitempty"`
 	Issuer    string `yaml:"issuer,omitempty" json:"issuer,omitempty"`
 	Algorithm string `yaml:"algorithm,omitempty" json:"algorithm,omitempty"`
+	// TrustedProxies are IP addresses and CIDR networks of the reverse proxies in front of the server.
+	// X-Forwarded-For and X-Real-Ip headers are honoured only in requests from them.
+	TrustedProxies []string `yaml:"trustedProxies,omitempty" json:"trusted_proxies,omitempty"`
+}
+
+// TrustedProxyNetworks parses trusted proxies. Single addresses become networks of one address.
+func (gss GeneralServerSettings) TrustedProxyNetworks() ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(gss.TrustedProxies))
+	for _, proxy := range gss.TrustedProxies {
+		if ip := net.ParseIP(proxy); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid trusted proxy %q, expected IP address or CIDR", proxy)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// MetricsSettings are settings of the Prometheus metrics endpoint.
+type MetricsSettings struct {
+	Enabled bool   `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	Path    string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Token is a bearer token the scraper must send, so metrics are not public.
+	Token string `yaml:"token,omitempty" json:"token,omitempty"`
+}
+
+// DefaultMetricsPath is a path of the metrics endpoint, when it is not set.
+const DefaultMetricsPath = "/metrics"
+
+// LoggerSettings are settings of the server log.
+type LoggerSettings struct {
+	// Level is one of "debug", "info", "warn" and "error". Defaults to "info".
+	Level string `yaml:"level,omitempty" json:"level,omitempty"`
+	// DebugSampleRate is a share of requests logged in detail at debug level, from 0 to 1. All requests are logged if it is not set.
+	DebugSampleRate float64 `yaml:"debugSampleRate,omitempty" json:"debug_sample_rate,omitempty"`
+}
+
+// TracingSettings are settings of OpenTelemetry tracing.
+type TracingSettings struct {
+	// Exporter is where spans are sent. Tracing is disabled if it is not set.
+	Exporter TracingExporterType `yaml:"exporter,omitempty" json:"exporter,omitempty"`
+	// Endpoint is OTLP/HTTP traces endpoint of the collector. Defaults to DefaultOTLPEndpoint.
+	Endpoint    string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	ServiceName string `yaml:"serviceName,omitempty" json:"service_name,omitempty"`
+	// SampleRatio is a share of traces started by the server that are recorded, from 0 to 1. All traces are recorded if it is not set.
+	// Traces continued from the incoming requests follow the sampling decision of the caller.
+	SampleRatio float64 `yaml:"sampleRatio,omitempty" json:"sample_ratio,omitempty"`
+}
+
+// TracingExporterType is a type of the span exporter.
+type TracingExporterType string
+
+const (
+	// TracingExporterNone disables tracing.
+	TracingExporterNone TracingExporterType = ""
+	// TracingExporterOTLP sends spans to OpenTelemetry collector over OTLP/HTTP.
+	TracingExporterOTLP TracingExporterType = "otlp"
+	// TracingExporterStdout writes spans to stdout.
+	TracingExporterStdout TracingExporterType = "stdout"
+)
+
+const (
+	// DefaultOTLPEndpoint is OTLP/HTTP traces endpoint of the local collector.
+	DefaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+	// DefaultTracingServiceName is the service name of the spans, when it is not set.
+	DefaultTracingServiceName = "identifo"
+)
+
+// HealthSettings are settings of the health and readiness endpoints.
+type HealthSettings struct {
+	// Timeout is a timeout of each dependency check in milliseconds, 2 seconds if 0.
+	Timeout int `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// CacheDuration is how long check results are reused in milliseconds, 5 seconds if 0.
+	// Frequent probes do not load the databases.
+	CacheDuration int `yaml:"cacheDuration,omitempty" json:"cache_duration,omitempty"`
 }
 
 // AdminAccountSettings are names of environment variables that store admin credentials.
 type AdminAccountSettings struct {
 	LoginEnvName    string `yaml:"loginEnvName" json:"login_env_name,omitempty"`
 	PasswordEnvName string `yaml:"passwordEnvName" json:"password_env_name,omitempty"`
+	// RequireTFA makes admins without two-factor authentication enrol it before using admin panel.
+	RequireTFA bool `yaml:"requireTFA,omitempty" json:"require_tfa,omitempty"`
 }
 
 // StorageSettings holds together storage settings for different services.
@@ -38,6 +131,16 @@ type StorageSettings struct {
 	TokenStorage            DatabaseSettings `yaml:"tokenStorage,omitempty" json:"token_storage,omitempty"`
 	TokenBlacklist          DatabaseSettings `yaml:"tokenBlacklist,omitempty" json:"token_blacklist,omitempty"`
 	VerificationCodeStorage DatabaseSettings `yaml:"verificationCodeStorage,omitempty" json:"verification_code_storage,omitempty"`
+	InviteStorage           DatabaseSettings `yaml:"inviteStorage,omitempty" json:"invite_storage,omitempty"`
+	UserSessionStorage      DatabaseSettings `yaml:"userSessionStorage,omitempty" json:"user_session_storage,omitempty"`
+	AdminStorage            DatabaseSettings `yaml:"adminStorage,omitempty" json:"admin_storage,omitempty"`
+	AuditStorage            DatabaseSettings `yaml:"auditStorage,omitempty" json:"audit_storage,omitempty"`
+	AuthEventStorage        DatabaseSettings `yaml:"authEventStorage,omitempty" json:"auth_event_storage,omitempty"`
+	WebhookStorage          DatabaseSettings `yaml:"webhookStorage,omitempty" json:"webhook_storage,omitempty"`
+	OrganizationStorage     DatabaseSettings `yaml:"organizationStorage,omitempty" json:"organization_storage,omitempty"`
+	SCIMTokenStorage        DatabaseSettings `yaml:"scimTokenStorage,omitempty" json:"scim_token_storage,omitempty"`
+	PolicyStorage           DatabaseSettings `yaml:"policyStorage,omitempty" json:"policy_storage,omitempty"`
+	RoleStorage             DatabaseSettings `yaml:"roleStorage,omitempty" json:"role_storage,omitempty"`
 }
 
 // DatabaseSettings holds together all settings applicable to a particular database.
@@ -47,6 +150,8 @@ type DatabaseSettings struct {
 	Endpoint string       `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
 	Region   string       `yaml:"region,omitempty" json:"region,omitempty"`
 	Path     string       `yaml:"path,omitempty" json:"path,omitempty"`
+	// TablePrefix is prepended to DynamoDB table names.
+	TablePrefix string `yaml:"tablePrefix,omitempty" json:"table_prefix,omitempty"`
 }
 
 // DatabaseType is a type of database.
@@ -151,8 +256,10 @@ const (
 
 // ExternalServicesSettings are settings for external services.
 type ExternalServicesSettings struct {
-	EmailService EmailServiceSettings `yaml:"emailService,omitempty" json:"email_service,omitempty"`
-	SMSService   SMSServiceSettings   `yaml:"smsService,omitempty" json:"sms_service,omitempty"`
+	EmailService  EmailServiceSettings  `yaml:"emailService,omitempty" json:"email_service,omitempty"`
+	SMSService    SMSServiceSettings    `yaml:"smsService,omitempty" json:"sms_service,omitempty"`
+	AuthEventSink AuthEventSinkSettings `yaml:"authEventSink,omitempty" json:"auth_event_sink,omitempty"`
+	AuthHooks     AuthHooksSettings     `yaml:"authHooks,omitempty" json:"auth_hooks,omitempty"`
 }
 
 // EmailServiceType - how to send email to clients.
@@ -208,6 +315,39 @@ const (
 	SMSServiceMock SMSServiceType = "mock"
 )
 
+// AuthEventSinkSettings are settings of the sink which receives authentication events.
+type AuthEventSinkSettings struct {
+	Type AuthEventSinkType `yaml:"type,omitempty" json:"type,omitempty"`
+	// URL is an endpoint where HTTP sink posts events.
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+}
+
+// AuthEventSinkType is a type of authentication event sink.
+type AuthEventSinkType string
+
+const (
+	// AuthEventSinkNone means events are only kept in the storage.
+	AuthEventSinkNone AuthEventSinkType = "none"
+	// AuthEventSinkLog writes events as JSON lines to stdout.
+	AuthEventSinkLog AuthEventSinkType = "log"
+	// AuthEventSinkHTTP posts events as JSON to the URL.
+	AuthEventSinkHTTP AuthEventSinkType = "http"
+)
+
+// AuthHooksSettings are settings of the external service called at the hook points of authentication flow.
+type AuthHooksSettings struct {
+	// URL is an endpoint where hook requests are posted.
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+	// Secret signs hook requests together with their timestamp, see package external_services/signature.
+	Secret string `yaml:"secret,omitempty" json:"secret,omitempty"`
+	// Timeout is a hook request timeout in milliseconds, 5 seconds if 0.
+	Timeout int `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// Hooks is a list of enabled hooks. Authorization hook is called for apps with external authorization regardless of it.
+	Hooks []AuthHookType `yaml:"hooks,omitempty" json:"hooks,omitempty"`
+	// AllowOnError lets the flow continue when the hook endpoint fails, otherwise the step is denied.
+	AllowOnError bool `yaml:"allowOnError,omitempty" json:"allow_on_error,omitempty"`
+}
+
 // LoginSettings are settings of login.
 type LoginSettings struct {
 	LoginWith LoginWith `yaml:"loginWith,omitempty" json:"login_with,omitempty"`
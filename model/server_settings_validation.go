@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"regexp"
+	"strings"
+
+	"github.com/madappgang/identifo/logging"
 )
 
 // Validate makes sure that all crucial fields are set.
@@ -33,6 +37,24 @@ func (ss *ServerSettings) Validate() error {
 	if err := ss.ExternalServices.Validate(); err != nil {
 		return err
 	}
+	if err := ss.UserAttributes.Validate(); err != nil {
+		return err
+	}
+	if err := ss.Metrics.Validate(); err != nil {
+		return err
+	}
+	if err := ss.Logger.Validate(); err != nil {
+		return err
+	}
+	if err := ss.Tracing.Validate(); err != nil {
+		return err
+	}
+	if err := ss.Health.Validate(); err != nil {
+		return err
+	}
+	if err := ValidateTenants(ss.Tenants); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -52,6 +74,9 @@ func (gss *GeneralServerSettings) Validate() error {
 	if len(gss.Issuer) == 0 {
 		return fmt.Errorf("%s. Issuer is not set", subject)
 	}
+	if _, err := gss.TrustedProxyNetworks(); err != nil {
+		return fmt.Errorf("%s. %s", subject, err)
+	}
 	return nil
 }
 
@@ -75,6 +100,36 @@ func (ss *StorageSettings) Validate() error {
 	if err := ss.VerificationCodeStorage.Validate(); err != nil {
 		return fmt.Errorf("VerificationCodeStorage: %s", err)
 	}
+	if err := ss.InviteStorage.Validate(); err != nil {
+		return fmt.Errorf("InviteStorage: %s", err)
+	}
+	if err := ss.UserSessionStorage.Validate(); err != nil {
+		return fmt.Errorf("UserSessionStorage: %s", err)
+	}
+	if err := ss.AdminStorage.Validate(); err != nil {
+		return fmt.Errorf("AdminStorage: %s", err)
+	}
+	if err := ss.AuditStorage.Validate(); err != nil {
+		return fmt.Errorf("AuditStorage: %s", err)
+	}
+	if err := ss.AuthEventStorage.Validate(); err != nil {
+		return fmt.Errorf("AuthEventStorage: %s", err)
+	}
+	if err := ss.WebhookStorage.Validate(); err != nil {
+		return fmt.Errorf("WebhookStorage: %s", err)
+	}
+	if err := ss.OrganizationStorage.Validate(); err != nil {
+		return fmt.Errorf("OrganizationStorage: %s", err)
+	}
+	if err := ss.SCIMTokenStorage.Validate(); err != nil {
+		return fmt.Errorf("SCIMTokenStorage: %s", err)
+	}
+	if err := ss.PolicyStorage.Validate(); err != nil {
+		return fmt.Errorf("PolicyStorage: %s", err)
+	}
+	if err := ss.RoleStorage.Validate(); err != nil {
+		return fmt.Errorf("RoleStorage: %s", err)
+	}
 	return nil
 }
 
@@ -255,6 +310,43 @@ func (kss *KeyStorageSettings) Validate() error {
 	return nil
 }
 
+// Validate validates custom user attributes definitions.
+func (s UserAttributeSchema) Validate() error {
+	subject := "UserAttributes"
+
+	names := make(map[string]bool)
+	for _, a := range s {
+		if !userAttributeNameRegexp.MatchString(a.Name) {
+			return fmt.Errorf("%s. Invalid name '%s'", subject, a.Name)
+		}
+		if names[a.Name] {
+			return fmt.Errorf("%s. Duplicated name '%s'", subject, a.Name)
+		}
+		names[a.Name] = true
+
+		switch a.Type {
+		case UserAttributeTypeString, UserAttributeTypeNumber, UserAttributeTypeBoolean, UserAttributeTypeDate, UserAttributeTypeURL:
+		default:
+			return fmt.Errorf("%s. Unknown type '%s' of '%s'", subject, a.Type, a.Name)
+		}
+		if a.Pattern != "" {
+			if _, err := regexp.Compile(a.Pattern); err != nil {
+				return fmt.Errorf("%s. Invalid pattern of '%s'. %s", subject, a.Name, err)
+			}
+		}
+		if a.MinLength < 0 || (a.MaxLength > 0 && a.MaxLength < a.MinLength) {
+			return fmt.Errorf("%s. Invalid length limits of '%s'", subject, a.Name)
+		}
+		if a.Min != nil && a.Max != nil && *a.Max < *a.Min {
+			return fmt.Errorf("%s. Invalid range of '%s'", subject, a.Name)
+		}
+		if (a.Required || a.Registration) && a.AdminOnly {
+			return fmt.Errorf("%s. Required or registration attribute '%s' cannot be admin only", subject, a.Name)
+		}
+	}
+	return nil
+}
+
 // Validate validates external services settings.
 func (ess *ExternalServicesSettings) Validate() error {
 	subject := "ExternalServicesSettings"
@@ -268,6 +360,138 @@ func (ess *ExternalServicesSettings) Validate() error {
 	if err := ess.SMSService.Validate(); err != nil {
 		return fmt.Errorf("%s. %s", subject, err)
 	}
+	if err := ess.AuthEventSink.Validate(); err != nil {
+		return fmt.Errorf("%s. %s", subject, err)
+	}
+	if err := ess.AuthHooks.Validate(); err != nil {
+		return fmt.Errorf("%s. %s", subject, err)
+	}
+	return nil
+}
+
+// Validate validates auth hooks settings.
+func (ahs *AuthHooksSettings) Validate() error {
+	subject := "AuthHooksSettings"
+	if ahs == nil {
+		return fmt.Errorf("Nil %s", subject)
+	}
+
+	for _, hook := range ahs.Hooks {
+		if !hook.IsValid() {
+			return fmt.Errorf("%s. Unknown hook '%s'", subject, hook)
+		}
+	}
+	if ahs.URL == "" {
+		if len(ahs.Hooks) > 0 {
+			return fmt.Errorf("%s. Empty URL", subject)
+		}
+		return nil
+	}
+
+	if _, err := url.ParseRequestURI(ahs.URL); err != nil {
+		return fmt.Errorf("%s. Invalid URL '%s'", subject, ahs.URL)
+	}
+	if ahs.Secret == "" {
+		return fmt.Errorf("%s. Empty secret", subject)
+	}
+	if ahs.Timeout < 0 {
+		return fmt.Errorf("%s. Negative timeout", subject)
+	}
+	return nil
+}
+
+// Validate validates metrics settings.
+func (ms *MetricsSettings) Validate() error {
+	subject := "MetricsSettings"
+	if ms == nil {
+		return fmt.Errorf("Nil %s", subject)
+	}
+	if !ms.Enabled {
+		return nil
+	}
+
+	if len(ms.Path) > 0 && !strings.HasPrefix(ms.Path, "/") {
+		return fmt.Errorf("%s. Path must start with '/'", subject)
+	}
+	if len(ms.Token) == 0 {
+		return fmt.Errorf("%s. Token is required when metrics are enabled", subject)
+	}
+	return nil
+}
+
+// Validate validates logger settings.
+func (ls *LoggerSettings) Validate() error {
+	subject := "LoggerSettings"
+	if ls == nil {
+		return fmt.Errorf("Nil %s", subject)
+	}
+
+	if _, err := logging.ParseLevel(ls.Level); err != nil {
+		return fmt.Errorf("%s. %s", subject, err)
+	}
+	if ls.DebugSampleRate < 0 || ls.DebugSampleRate > 1 {
+		return fmt.Errorf("%s. Debug sample rate must be from 0 to 1", subject)
+	}
+	return nil
+}
+
+// Validate validates tracing settings.
+func (ts *TracingSettings) Validate() error {
+	subject := "TracingSettings"
+	if ts == nil {
+		return fmt.Errorf("Nil %s", subject)
+	}
+
+	switch ts.Exporter {
+	case TracingExporterNone, TracingExporterStdout:
+	case TracingExporterOTLP:
+		if len(ts.Endpoint) > 0 {
+			if u, err := url.Parse(ts.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("%s. Invalid endpoint %s", subject, ts.Endpoint)
+			}
+		}
+	default:
+		return fmt.Errorf("%s. Unknown exporter %s", subject, ts.Exporter)
+	}
+	if ts.SampleRatio < 0 || ts.SampleRatio > 1 {
+		return fmt.Errorf("%s. Sample ratio must be from 0 to 1", subject)
+	}
+	return nil
+}
+
+// Validate validates health endpoints settings.
+func (hs *HealthSettings) Validate() error {
+	subject := "HealthSettings"
+	if hs == nil {
+		return fmt.Errorf("Nil %s", subject)
+	}
+
+	if hs.Timeout < 0 {
+		return fmt.Errorf("%s. Timeout must not be negative", subject)
+	}
+	if hs.CacheDuration < 0 {
+		return fmt.Errorf("%s. Cache duration must not be negative", subject)
+	}
+	return nil
+}
+
+// Validate validates authentication event sink settings.
+func (aess *AuthEventSinkSettings) Validate() error {
+	subject := "AuthEventSinkSettings"
+	if aess == nil {
+		return fmt.Errorf("Nil %s", subject)
+	}
+
+	switch aess.Type {
+	case "", AuthEventSinkNone, AuthEventSinkLog:
+		return nil
+	case AuthEventSinkHTTP:
+		if _, err := url.ParseRequestURI(aess.URL); err != nil {
+			return fmt.Errorf("%s. Invalid URL '%s'", subject, aess.URL)
+		}
+	default:
+		return fmt.Errorf("%s. Unknown type '%s'", subject, aess.Type)
+	}
 	return nil
 }
 
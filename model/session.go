@@ -12,6 +12,7 @@ var ErrSessionNotFound = errors.New("Session not found. ")
 // Session is a session.
 type Session struct {
 	ID             string `json:"id"`
+	AdminID        string `json:"admin_id,omitempty"`
 	ExpirationTime int64  `json:"expiration_time"`
 }
 
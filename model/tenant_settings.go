@@ -0,0 +1,145 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TenantSettings describe a tenant with its own isolated user pool.
+// Tenant storages are derived from the default ones: MongoDB database names and BoltDB file names
+// get the tenant ID suffix, DynamoDB table names get the tenant ID prefix.
+type TenantSettings struct {
+	ID string `yaml:"id" json:"id"`
+	// Hosts are host names served by the tenant.
+	Hosts []string `yaml:"hosts,omitempty" json:"hosts,omitempty"`
+	// PathPrefix routes requests with the path prefix to the tenant, e.g. "/brand".
+	PathPrefix string `yaml:"pathPrefix,omitempty" json:"path_prefix,omitempty"`
+	// Host is the public URL of the tenant. Defaults to the general host with the first of Hosts and PathPrefix.
+	Host string `yaml:"host,omitempty" json:"host,omitempty"`
+	// Issuer defaults to the tenant host.
+	Issuer     string             `yaml:"issuer,omitempty" json:"issuer,omitempty"`
+	KeyStorage KeyStorageSettings `yaml:"keyStorage,omitempty" json:"key_storage,omitempty"`
+	// Login overrides default login settings when set.
+	Login *LoginSettings `yaml:"login,omitempty" json:"login,omitempty"`
+}
+
+var tenantIDRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Validate validates tenant settings.
+func (ts *TenantSettings) Validate() error {
+	subject := "TenantSettings"
+	if !tenantIDRegexp.MatchString(ts.ID) {
+		return fmt.Errorf("%s. Invalid tenant ID '%s'", subject, ts.ID)
+	}
+	if len(ts.Hosts) == 0 && len(ts.PathPrefix) == 0 {
+		return fmt.Errorf("%s. Tenant %s has neither hosts nor path prefix", subject, ts.ID)
+	}
+	if len(ts.PathPrefix) > 0 && (!strings.HasPrefix(ts.PathPrefix, "/") || strings.HasSuffix(ts.PathPrefix, "/")) {
+		return fmt.Errorf("%s. Path prefix of tenant %s must start and must not end with '/'", subject, ts.ID)
+	}
+	if len(ts.Host) > 0 {
+		if _, err := url.ParseRequestURI(ts.Host); err != nil {
+			return fmt.Errorf("%s. Host of tenant %s is invalid. %s", subject, ts.ID, err)
+		}
+	}
+	if err := ts.KeyStorage.Validate(); err != nil {
+		return fmt.Errorf("Tenant %s: %s", ts.ID, err)
+	}
+	return nil
+}
+
+// ValidateTenants validates tenants and makes sure that they can be told apart.
+func ValidateTenants(tenants []TenantSettings) error {
+	ids := make(map[string]bool)
+	routes := make(map[string]string)
+	for i := range tenants {
+		t := &tenants[i]
+		if err := t.Validate(); err != nil {
+			return err
+		}
+		if ids[t.ID] {
+			return fmt.Errorf("Duplicate tenant ID %s", t.ID)
+		}
+		ids[t.ID] = true
+
+		for _, h := range t.Hosts {
+			route := strings.ToLower(h) + t.PathPrefix
+			if id, ok := routes[route]; ok {
+				return fmt.Errorf("Tenants %s and %s share route %s", id, t.ID, route)
+			}
+			routes[route] = t.ID
+		}
+		if len(t.Hosts) == 0 {
+			if id, ok := routes[t.PathPrefix]; ok {
+				return fmt.Errorf("Tenants %s and %s share route %s", id, t.ID, t.PathPrefix)
+			}
+			routes[t.PathPrefix] = t.ID
+		}
+	}
+	return nil
+}
+
+// TenantServerSettings derives settings of the tenant server from the default settings.
+func (ss ServerSettings) TenantServerSettings(ts TenantSettings) ServerSettings {
+	tss := ss
+	tss.Tenants = nil
+
+	tss.General.Host = ts.Host
+	if len(tss.General.Host) == 0 {
+		tss.General.Host = ss.General.Host
+		if len(ts.Hosts) > 0 {
+			if u, err := url.Parse(ss.General.Host); err == nil {
+				u.Host = ts.Hosts[0]
+				tss.General.Host = u.String()
+			}
+		}
+		tss.General.Host = strings.TrimSuffix(tss.General.Host, "/") + ts.PathPrefix
+	}
+
+	tss.General.Issuer = ts.Issuer
+	if len(tss.General.Issuer) == 0 {
+		tss.General.Issuer = tss.General.Host
+	}
+
+	tss.ConfigurationStorage.KeyStorage = ts.KeyStorage
+	if ts.Login != nil {
+		tss.Login = *ts.Login
+	}
+
+	tss.Storage = StorageSettings{
+		AppStorage:              ss.Storage.AppStorage.forTenant(ts.ID),
+		UserStorage:             ss.Storage.UserStorage.forTenant(ts.ID),
+		TokenStorage:            ss.Storage.TokenStorage.forTenant(ts.ID),
+		TokenBlacklist:          ss.Storage.TokenBlacklist.forTenant(ts.ID),
+		VerificationCodeStorage: ss.Storage.VerificationCodeStorage.forTenant(ts.ID),
+		InviteStorage:           ss.Storage.InviteStorage.forTenant(ts.ID),
+		UserSessionStorage:      ss.Storage.UserSessionStorage.forTenant(ts.ID),
+		AdminStorage:            ss.Storage.AdminStorage.forTenant(ts.ID),
+		AuditStorage:            ss.Storage.AuditStorage.forTenant(ts.ID),
+		AuthEventStorage:        ss.Storage.AuthEventStorage.forTenant(ts.ID),
+		WebhookStorage:          ss.Storage.WebhookStorage.forTenant(ts.ID),
+		OrganizationStorage:     ss.Storage.OrganizationStorage.forTenant(ts.ID),
+		SCIMTokenStorage:        ss.Storage.SCIMTokenStorage.forTenant(ts.ID),
+		PolicyStorage:           ss.Storage.PolicyStorage.forTenant(ts.ID),
+		RoleStorage:             ss.Storage.RoleStorage.forTenant(ts.ID),
+	}
+	return tss
+}
+
+// forTenant returns settings of the tenant's own database, collection set or table set.
+func (dbs DatabaseSettings) forTenant(tenantID string) DatabaseSettings {
+	switch dbs.Type {
+	case DBTypeMongoDB:
+		dbs.Name = dbs.Name + "_" + tenantID
+	case DBTypeBoltDB:
+		ext := filepath.Ext(dbs.Path)
+		dbs.Path = strings.TrimSuffix(dbs.Path, ext) + "_" + tenantID + ext
+	case DBTypeDynamoDB:
+		dbs.TablePrefix = dbs.TablePrefix + tenantID + "_"
+	}
+	// Fake storages are in-memory, so every tenant has its own anyway.
+	return dbs
+}
@@ -0,0 +1,51 @@
+package model
+
+import "testing"
+
+func TestTenantServerSettings(t *testing.T) {
+	ss := ServerSettings{
+		General: GeneralServerSettings{Host: "https://auth.example.com", Issuer: "https://auth.example.com"},
+		Storage: StorageSettings{
+			AppStorage:   DatabaseSettings{Type: DBTypeMongoDB, Name: "identifo"},
+			UserStorage:  DatabaseSettings{Type: DBTypeBoltDB, Path: "./db.db"},
+			TokenStorage: DatabaseSettings{Type: DBTypeDynamoDB, TablePrefix: "prod_"},
+		},
+	}
+	tenant := TenantSettings{ID: "brand", Hosts: []string{"auth.brand.com"}, PathPrefix: "/id"}
+
+	tss := ss.TenantServerSettings(tenant)
+	if tss.General.Host != "https://auth.brand.com/id" || tss.General.Issuer != tss.General.Host {
+		t.Errorf("Tenant host %s and issuer %s", tss.General.Host, tss.General.Issuer)
+	}
+	if tss.Storage.AppStorage.Name != "identifo_brand" {
+		t.Errorf("Tenant MongoDB name %s", tss.Storage.AppStorage.Name)
+	}
+	if tss.Storage.UserStorage.Path != "./db_brand.db" {
+		t.Errorf("Tenant BoltDB path %s", tss.Storage.UserStorage.Path)
+	}
+	if tss.Storage.TokenStorage.TablePrefix != "prod_brand_" {
+		t.Errorf("Tenant DynamoDB table prefix %s", tss.Storage.TokenStorage.TablePrefix)
+	}
+	if ss.Storage.AppStorage.Name != "identifo" {
+		t.Errorf("Default settings changed")
+	}
+
+	keys := KeyStorageSettings{Type: KeyStorageTypeLocal}
+	tests := []struct {
+		tenants []TenantSettings
+		valid   bool
+	}{
+		{[]TenantSettings{{ID: "a", Hosts: []string{"a.com"}, KeyStorage: keys}, {ID: "b", PathPrefix: "/b", KeyStorage: keys}}, true},
+		{[]TenantSettings{{ID: "a", Hosts: []string{"a.com"}, KeyStorage: keys}, {ID: "a", Hosts: []string{"b.com"}, KeyStorage: keys}}, false},
+		{[]TenantSettings{{ID: "a", Hosts: []string{"a.com"}, KeyStorage: keys}, {ID: "b", Hosts: []string{"A.com"}, KeyStorage: keys}}, false},
+		{[]TenantSettings{{ID: "a", KeyStorage: keys}}, false},
+		{[]TenantSettings{{ID: "a", PathPrefix: "/a/", KeyStorage: keys}}, false},
+		{[]TenantSettings{{ID: "a.b", PathPrefix: "/a", KeyStorage: keys}}, false},
+		{[]TenantSettings{{ID: "a", PathPrefix: "/a"}}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateTenants(tt.tenants); (err == nil) != tt.valid {
+			t.Errorf("ValidateTenants(%+v) = %v, want valid %v", tt.tenants, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UserAttributeType is a type of custom user profile attribute.
+type UserAttributeType string
+
+const (
+	// UserAttributeTypeString is a free text attribute.
+	UserAttributeTypeString UserAttributeType = "string"
+	// UserAttributeTypeNumber is a number attribute.
+	UserAttributeTypeNumber UserAttributeType = "number"
+	// UserAttributeTypeBoolean is a boolean attribute, like marketing consent.
+	UserAttributeTypeBoolean UserAttributeType = "boolean"
+	// UserAttributeTypeDate is a date attribute in YYYY-MM-DD format, like date of birth.
+	UserAttributeTypeDate UserAttributeType = "date"
+	// UserAttributeTypeURL is an absolute http or https URL attribute, like avatar URL.
+	UserAttributeTypeURL UserAttributeType = "url"
+)
+
+// UserAttributeDateLayout is a format of date attributes.
+const UserAttributeDateLayout = "2006-01-02"
+
+var userAttributeNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// UserAttribute is a definition of custom user profile attribute.
+type UserAttribute struct {
+	Name  string            `yaml:"name" json:"name"`
+	Type  UserAttributeType `yaml:"type" json:"type"`
+	Label string            `yaml:"label,omitempty" json:"label,omitempty"`
+	// Required attributes must be set on registration and cannot be removed.
+	Required bool `yaml:"required,omitempty" json:"required,omitempty"`
+	// Registration shows the attribute on the registration form.
+	Registration bool `yaml:"registration,omitempty" json:"registration,omitempty"`
+	// AdminOnly attributes cannot be changed by the user.
+	AdminOnly bool `yaml:"adminOnly,omitempty" json:"admin_only,omitempty"`
+	// MinLength, MaxLength, Pattern and Enum are the rules of string values.
+	MinLength int      `yaml:"minLength,omitempty" json:"min_length,omitempty"`
+	MaxLength int      `yaml:"maxLength,omitempty" json:"max_length,omitempty"`
+	Pattern   string   `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	Enum      []string `yaml:"enum,omitempty" json:"enum,omitempty"`
+	// Min and Max are the rules of number values.
+	Min *float64 `yaml:"min,omitempty" json:"min,omitempty"`
+	Max *float64 `yaml:"max,omitempty" json:"max,omitempty"`
+}
+
+// UserAttributeSchema is a list of custom user profile attributes.
+type UserAttributeSchema []UserAttribute
+
+// Attribute returns the attribute definition by name.
+func (s UserAttributeSchema) Attribute(name string) (UserAttribute, bool) {
+	for _, a := range s {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return UserAttribute{}, false
+}
+
+// RegistrationAttributes returns the attributes shown on the registration form.
+func (s UserAttributeSchema) RegistrationAttributes() []UserAttribute {
+	attributes := []UserAttribute{}
+	for _, a := range s {
+		if a.Registration || a.Required {
+			attributes = append(attributes, a)
+		}
+	}
+	return attributes
+}
+
+// ValidateAttributes checks that all attributes are defined and valid, and that required ones are set.
+// Values are expected as decoded from JSON, so numbers are float64.
+func (s UserAttributeSchema) ValidateAttributes(attributes map[string]interface{}) error {
+	for name, value := range attributes {
+		a, ok := s.Attribute(name)
+		if !ok {
+			return fmt.Errorf("Unknown user attribute %s", name)
+		}
+		if err := a.ValidateValue(value); err != nil {
+			return err
+		}
+	}
+	for _, a := range s {
+		if _, ok := attributes[a.Name]; a.Required && !ok {
+			return fmt.Errorf("User attribute %s is required", a.Name)
+		}
+	}
+	return nil
+}
+
+// MergeUserAttributes applies the changes made by the user to the current attributes.
+// Nil value removes the attribute. Admin only attributes cannot be changed.
+func (s UserAttributeSchema) MergeUserAttributes(current, changes map[string]interface{}) (map[string]interface{}, error) {
+	merged := make(map[string]interface{}, len(current))
+	for name, value := range current {
+		merged[name] = value
+	}
+	for name, value := range changes {
+		if a, ok := s.Attribute(name); ok && a.AdminOnly {
+			return nil, fmt.Errorf("User attribute %s is not editable", name)
+		}
+		if value == nil {
+			delete(merged, name)
+		} else {
+			merged[name] = value
+		}
+	}
+	return merged, s.ValidateAttributes(merged)
+}
+
+// ParseValue converts the string value, like form or query value, to the attribute type.
+func (s UserAttributeSchema) ParseValue(name, value string) (interface{}, error) {
+	a, ok := s.Attribute(name)
+	if !ok {
+		return nil, fmt.Errorf("Unknown user attribute %s", name)
+	}
+
+	var v interface{} = value
+	switch a.Type {
+	case UserAttributeTypeNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("User attribute %s should be a number", name)
+		}
+		v = n
+	case UserAttributeTypeBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("User attribute %s should be a boolean", name)
+		}
+		v = b
+	}
+	return v, a.ValidateValue(v)
+}
+
+// ValidateValue checks the value type and rules.
+func (a UserAttribute) ValidateValue(value interface{}) error {
+	switch a.Type {
+	case UserAttributeTypeNumber:
+		n, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("User attribute %s should be a number", a.Name)
+		}
+		if (a.Min != nil && n < *a.Min) || (a.Max != nil && n > *a.Max) {
+			return fmt.Errorf("User attribute %s is out of range", a.Name)
+		}
+		return nil
+	case UserAttributeTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("User attribute %s should be a boolean", a.Name)
+		}
+		return nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("User attribute %s should be a string", a.Name)
+	}
+
+	switch a.Type {
+	case UserAttributeTypeDate:
+		if _, err := time.Parse(UserAttributeDateLayout, s); err != nil {
+			return fmt.Errorf("User attribute %s should be a date in YYYY-MM-DD format", a.Name)
+		}
+	case UserAttributeTypeURL:
+		if u, err := url.Parse(s); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("User attribute %s should be an absolute http or https URL", a.Name)
+		}
+	}
+
+	if a.MinLength > 0 && len(s) < a.MinLength {
+		return fmt.Errorf("User attribute %s should be at least %d characters long", a.Name, a.MinLength)
+	}
+	if a.MaxLength > 0 && len(s) > a.MaxLength {
+		return fmt.Errorf("User attribute %s should be at most %d characters long", a.Name, a.MaxLength)
+	}
+	if a.Pattern != "" {
+		if re, err := regexp.Compile(a.Pattern); err != nil || !re.MatchString(s) {
+			return fmt.Errorf("User attribute %s has invalid format", a.Name)
+		}
+	}
+	if len(a.Enum) > 0 && !contains(a.Enum, s) {
+		return fmt.Errorf("User attribute %s should be one of %s", a.Name, strings.Join(a.Enum, ", "))
+	}
+	return nil
+}
+
+// AttributesMatch checks that the attributes have all the filter values.
+func AttributesMatch(attributes, filter map[string]interface{}) bool {
+	for name, value := range filter {
+		if v, ok := attributes[name]; !ok || !reflect.DeepEqual(v, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package model
+
+import "testing"
+
+func TestUserAttributeSchema(t *testing.T) {
+	min, max := 13.0, 120.0
+	schema := UserAttributeSchema{
+		{Name: "first_name", Type: UserAttributeTypeString, Required: true, MaxLength: 10},
+		{Name: "locale", Type: UserAttributeTypeString, Enum: []string{"en", "de"}},
+		{Name: "age", Type: UserAttributeTypeNumber, Min: &min, Max: &max},
+		{Name: "birthday", Type: UserAttributeTypeDate},
+		{Name: "avatar", Type: UserAttributeTypeURL},
+		{Name: "tier", Type: UserAttributeTypeString, AdminOnly: true},
+	}
+	if err := schema.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		attributes map[string]interface{}
+		valid      bool
+	}{
+		{map[string]interface{}{"first_name": "John", "locale": "en", "age": 30.0, "birthday": "1990-01-31", "avatar": "https://example.com/a.png"}, true},
+		{map[string]interface{}{"locale": "en"}, false},
+		{map[string]interface{}{"first_name": "Johnathan Smith"}, false},
+		{map[string]interface{}{"first_name": "John", "locale": "fr"}, false},
+		{map[string]interface{}{"first_name": "John", "age": 5.0}, false},
+		{map[string]interface{}{"first_name": "John", "age": "30"}, false},
+		{map[string]interface{}{"first_name": "John", "birthday": "31.01.1990"}, false},
+		{map[string]interface{}{"first_name": "John", "avatar": "/a.png"}, false},
+		{map[string]interface{}{"first_name": "John", "unknown": "x"}, false},
+	}
+	for _, tt := range tests {
+		if err := schema.ValidateAttributes(tt.attributes); (err == nil) != tt.valid {
+			t.Errorf("ValidateAttributes(%v) = %v, want valid %v", tt.attributes, err, tt.valid)
+		}
+	}
+
+	current := map[string]interface{}{"first_name": "John", "locale": "en", "tier": "gold"}
+	merged, err := schema.MergeUserAttributes(current, map[string]interface{}{"locale": nil, "age": 30.0})
+	if err != nil || merged["locale"] != nil || merged["age"] != 30.0 || merged["tier"] != "gold" {
+		t.Errorf("MergeUserAttributes() = %v, %v", merged, err)
+	}
+	if _, err = schema.MergeUserAttributes(current, map[string]interface{}{"tier": "platinum"}); err == nil {
+		t.Error("MergeUserAttributes() changed admin only attribute")
+	}
+
+	if v, err := schema.ParseValue("age", "42"); err != nil || v != 42.0 {
+		t.Errorf("ParseValue() = %v, %v", v, err)
+	}
+}
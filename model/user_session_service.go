@@ -0,0 +1,136 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// UserSessionService creates user sessions and revokes their tokens.
+type UserSessionService interface {
+	StartSession(userID, appID, userAgent, ip string, tokens ...string) (UserSession, error)
+	// AddSessionTokens adds tokens to the session which has issued the token.
+	AddSessionTokens(token string, tokens ...string) error
+	// ReplaceSessionToken replaces the token with new ones in the session which has issued it.
+	ReplaceSessionToken(oldToken string, tokens ...string) error
+	RevokeSession(session UserSession) error
+	RevokeSessionByToken(token string) error
+}
+
+// UserSessionManager is a default user session service.
+type UserSessionManager struct {
+	userSessionStorage UserSessionStorage
+	tokenStorage       TokenStorage
+	tokenBlacklist     TokenBlacklist
+}
+
+// NewUserSessionManager creates new user session manager and returns it.
+func NewUserSessionManager(uss UserSessionStorage, ts TokenStorage, tb TokenBlacklist) UserSessionService {
+	return &UserSessionManager{
+		userSessionStorage: uss,
+		tokenStorage:       ts,
+		tokenBlacklist:     tb,
+	}
+}
+
+// StartSession creates new session with the tokens issued on login.
+func (sm *UserSessionManager) StartSession(userID, appID, userAgent, ip string, tokens ...string) (UserSession, error) {
+	now := time.Now().Unix()
+	return sm.userSessionStorage.AddUserSession(UserSession{
+		UserID:     userID,
+		AppID:      appID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		Tokens:     nonEmpty(tokens),
+	})
+}
+
+// AddSessionTokens adds tokens to the session which has issued the token.
+func (sm *UserSessionManager) AddSessionTokens(token string, tokens ...string) error {
+	return sm.updateSessionTokens(token, false, tokens)
+}
+
+// ReplaceSessionToken replaces the token with new ones in the session which has issued it.
+func (sm *UserSessionManager) ReplaceSessionToken(oldToken string, tokens ...string) error {
+	return sm.updateSessionTokens(oldToken, true, tokens)
+}
+
+// RevokeSession invalidates all session tokens and deletes the session.
+func (sm *UserSessionManager) RevokeSession(session UserSession) error {
+	for _, token := range session.Tokens {
+		// Only refresh tokens are kept in token storage.
+		if sm.tokenStorage.HasToken(token) {
+			if err := sm.tokenStorage.DeleteToken(token); err != nil {
+				return err
+			}
+		}
+		if err := sm.tokenBlacklist.Add(token); err != nil {
+			return err
+		}
+	}
+	return sm.userSessionStorage.DeleteUserSession(session.ID)
+}
+
+// RevokeSessionByToken revokes the session which has issued the token.
+func (sm *UserSessionManager) RevokeSessionByToken(token string) error {
+	session, err := sm.userSessionStorage.UserSessionByToken(token)
+	if err != nil {
+		return err
+	}
+	return sm.RevokeSession(session)
+}
+
+// updateSessionTokens adds new tokens to the session and drops the expired ones,
+// so the session keeps only tokens which have to be blacklisted on revocation.
+func (sm *UserSessionManager) updateSessionTokens(token string, dropToken bool, tokens []string) error {
+	session, err := sm.userSessionStorage.UserSessionByToken(token)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	updated := []string{}
+	for _, t := range session.Tokens {
+		if (dropToken && t == token) || tokenExpired(t, now) {
+			continue
+		}
+		updated = append(updated, t)
+	}
+	updated = append(updated, nonEmpty(tokens)...)
+
+	return sm.userSessionStorage.UpdateUserSessionTokens(session.ID, updated, now)
+}
+
+// tokenExpired checks JWT expiration time without verifying the signature.
+// Tokens which cannot be decoded are considered alive.
+func tokenExpired(token string, now int64) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+
+	claims := struct {
+		ExpiresAt int64 `json:"exp"`
+	}{}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return false
+	}
+	return claims.ExpiresAt != 0 && claims.ExpiresAt < now
+}
+
+func nonEmpty(values []string) []string {
+	result := []string{}
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
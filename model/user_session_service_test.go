@@ -0,0 +1,104 @@
+package model_test
+
+import (
+	"encoding/base64"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/storage/mem"
+)
+
+// testToken returns unsigned JWT with the expiration time, enough for the session manager.
+func testToken(name string, expiresAt int64) string {
+	payload := `{"jti":"` + name + `","exp":` + strconv.FormatInt(expiresAt, 10) + `}`
+	return "e30." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+}
+
+func newTestSessionManager() (model.UserSessionService, model.UserSessionStorage, model.TokenStorage, model.TokenBlacklist) {
+	sessionStorage, _ := mem.NewUserSessionStorage()
+	tokenStorage, _ := mem.NewTokenStorage()
+	tokenBlacklist, _ := mem.NewTokenBlacklist()
+	return model.NewUserSessionManager(sessionStorage, tokenStorage, tokenBlacklist), sessionStorage, tokenStorage, tokenBlacklist
+}
+
+func TestUserSessionManagerTokens(t *testing.T) {
+	sm, storage, _, _ := newTestSessionManager()
+	future := time.Now().Add(time.Hour).Unix()
+	access, refresh := testToken("access", future), testToken("refresh", future)
+	expired := testToken("expired", time.Now().Add(-time.Hour).Unix())
+
+	session, err := sm.StartSession("user", "app", "agent", "203.0.113.7", access, "", refresh, expired)
+	if err != nil {
+		t.Fatalf("Error starting session: %s", err)
+	}
+	if len(session.Tokens) != 3 {
+		t.Errorf("Session tokens = %v, expected empty token to be dropped", session.Tokens)
+	}
+
+	// Refresh replaces the refresh token, new access token is added and the expired one is dropped.
+	newAccess, newRefresh := testToken("access2", future), testToken("refresh2", future)
+	if err = sm.ReplaceSessionToken(refresh, newAccess, newRefresh); err != nil {
+		t.Fatalf("Error replacing token: %s", err)
+	}
+	session, _ = storage.UserSessionByID(session.ID)
+	expected := []string{access, newAccess, newRefresh}
+	if len(session.Tokens) != len(expected) {
+		t.Fatalf("Session tokens = %v, expected %v", session.Tokens, expected)
+	}
+	for i := range expected {
+		if session.Tokens[i] != expected[i] {
+			t.Errorf("Session token %d = %q, expected %q", i, session.Tokens[i], expected[i])
+		}
+	}
+
+	if err = sm.AddSessionTokens(newAccess, "tfa"); err != nil {
+		t.Fatalf("Error adding token: %s", err)
+	}
+	if session, _ = storage.UserSessionByID(session.ID); !session.HasToken("tfa") {
+		t.Errorf("Added token is not in the session: %v", session.Tokens)
+	}
+
+	if err = sm.ReplaceSessionToken(refresh, newAccess); err != model.ErrUserSessionNotFound {
+		t.Errorf("Replacing dropped token error = %v, expected %v", err, model.ErrUserSessionNotFound)
+	}
+}
+
+func TestUserSessionManagerRevoke(t *testing.T) {
+	sm, storage, tokenStorage, tokenBlacklist := newTestSessionManager()
+	future := time.Now().Add(time.Hour).Unix()
+	access, refresh := testToken("access", future), testToken("refresh", future)
+	other := testToken("other", future)
+
+	tokenStorage.SaveToken(refresh)
+	tokenStorage.SaveToken(other)
+	session, _ := sm.StartSession("user", "app", "", "", access, refresh)
+	otherSession, _ := sm.StartSession("user", "app", "", "", other)
+
+	if err := sm.RevokeSessionByToken(access); err != nil {
+		t.Fatalf("Error revoking session: %s", err)
+	}
+	if _, err := storage.UserSessionByID(session.ID); err != model.ErrUserSessionNotFound {
+		t.Errorf("Revoked session is not deleted: %v", err)
+	}
+	if tokenStorage.HasToken(refresh) {
+		t.Error("Refresh token of the revoked session is kept in the token storage")
+	}
+	for _, token := range []string{access, refresh} {
+		if !tokenBlacklist.IsBlacklisted(token) {
+			t.Errorf("Token %q of the revoked session is not blacklisted", token)
+		}
+	}
+
+	if _, err := storage.UserSessionByID(otherSession.ID); err != nil {
+		t.Errorf("Another session is revoked: %v", err)
+	}
+	if !tokenStorage.HasToken(other) || tokenBlacklist.IsBlacklisted(other) {
+		t.Error("Token of another session is revoked")
+	}
+
+	if err := sm.RevokeSessionByToken(access); err != model.ErrUserSessionNotFound {
+		t.Errorf("Revoking unknown session error = %v, expected %v", err, model.ErrUserSessionNotFound)
+	}
+}
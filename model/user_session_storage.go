@@ -0,0 +1,43 @@
+package model
+
+import "errors"
+
+// ErrUserSessionNotFound is when user session not found.
+var ErrUserSessionNotFound = errors.New("User session not found")
+
+// UserSessionStorage stores sessions created by user logins.
+type UserSessionStorage interface {
+	// AddUserSession saves new session and returns it with generated ID.
+	AddUserSession(session UserSession) (UserSession, error)
+	UserSessionByID(id string) (UserSession, error)
+	// UserSessionByToken returns the session which has issued the token.
+	UserSessionByToken(token string) (UserSession, error)
+	FetchUserSessions(userID string) ([]UserSession, error)
+	// UpdateUserSessionTokens replaces session tokens and sets last used time.
+	UpdateUserSessionTokens(id string, tokens []string, lastUsedAt int64) error
+	DeleteUserSession(id string) error
+	Close()
+}
+
+// UserSession is a user login on the particular device.
+// Session keeps the tokens it has issued, so they can be revoked with the session.
+type UserSession struct {
+	ID         string   `json:"id" bson:"_id"`
+	UserID     string   `json:"user_id" bson:"user_id"`
+	AppID      string   `json:"app_id" bson:"app_id"`
+	UserAgent  string   `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	IP         string   `json:"ip,omitempty" bson:"ip,omitempty"`
+	CreatedAt  int64    `json:"created_at" bson:"created_at"`
+	LastUsedAt int64    `json:"last_used_at" bson:"last_used_at"`
+	Tokens     []string `json:"tokens,omitempty" bson:"tokens,omitempty"`
+}
+
+// HasToken checks if the token is issued by the session.
+func (s UserSession) HasToken(token string) bool {
+	for _, t := range s.Tokens {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
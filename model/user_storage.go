@@ -3,6 +3,7 @@ package model
 import (
 	"errors"
 	"regexp"
+	"strings"
 )
 
 // ErrUserNotFound is when user not found.
@@ -21,6 +22,8 @@ type UserStorage interface {
 	AddUserByPhone(phone, role string) (User, error)
 	UserByID(id string) (User, error)
 	UserByEmail(email string) (User, error)
+	// UserByUsername returns user with the username, including inactive users. Backends ignore case, if they store usernames so.
+	UserByUsername(username string) (User, error)
 	IDByName(name string) (string, error)
 	AttachDeviceToken(id, token string) error
 	DetachDeviceToken(token string) error
@@ -30,9 +33,26 @@ type UserStorage interface {
 	UserByFederatedID(provider FederatedIdentityProvider, id string) (User, error)
 	AddUserWithFederatedID(provider FederatedIdentityProvider, id, role string) (User, error)
 	UpdateUser(userID string, newUser User) (User, error)
+	// LinkFederatedID attaches federated identity to the existing user.
+	// It returns ErrorUserExists if the identity belongs to another user.
+	LinkFederatedID(userID string, provider FederatedIdentityProvider, id string) error
+	UnlinkFederatedID(userID string, provider FederatedIdentityProvider, id string) error
+	// LinkPhone attaches phone number to the existing user, ErrorUserExists if it is taken.
+	LinkPhone(userID, phone string) error
+	UnlinkPhone(userID string) error
+	// LinkPassword sets username and password for the existing user, ErrorUserExists if username is taken.
+	// Empty username keeps the current one.
+	LinkPassword(userID, username, password string) error
+	UnlinkPassword(userID string) error
+	// DeanonimizeUser turns anonymous user into the regular one.
+	DeanonimizeUser(userID string) error
+	// SetTokensValidAfter invalidates all user tokens issued before the timestamp.
+	// Tokens are issued with second precision, so to revoke tokens issued in the current second the next one is set.
+	SetTokensValidAfter(userID string, timestamp int64) error
 	ResetPassword(id, password string) error
 	DeleteUser(id string) error
-	FetchUsers(search string, skip, limit int) ([]User, int, error)
+	// FetchUsers returns users which username contains search string and which custom attributes equal the given ones.
+	FetchUsers(search string, attributes map[string]interface{}, skip, limit int) ([]User, int, error)
 	NewUser() User
 
 	RequestScopes(userID string, scopes []string) ([]string, error)
@@ -51,13 +71,42 @@ type User interface {
 	Email() string
 	SetEmail(string)
 	Phone() string
+	// FederatedIDs are the linked federated identities in "PROVIDER:id" format.
+	FederatedIDs() []string
 	TFAInfo() TFAInfo
 	SetTFAInfo(TFAInfo)
 	PasswordHash() string
 	Active() bool
+	SetActive(bool)
 	AccessRole() string
 	Sanitize()
+	IsAnonymous() bool
 	Deanonimize()
+	// TokensValidAfter is a time before which all user tokens are revoked. Tokens issued in this second are valid.
+	TokensValidAfter() int64
+	// UserMetadata is arbitrary data editable by the user.
+	UserMetadata() map[string]interface{}
+	SetUserMetadata(map[string]interface{})
+	// AppMetadata is arbitrary data editable only by admins.
+	AppMetadata() map[string]interface{}
+	SetAppMetadata(map[string]interface{})
+	// Attributes are custom profile attributes defined by UserAttributeSchema.
+	Attributes() map[string]interface{}
+	SetAttributes(map[string]interface{})
+}
+
+// FederatedIDKey is how federated identity is stored with the user.
+func FederatedIDKey(provider FederatedIdentityProvider, id string) string {
+	return string(provider) + ":" + id
+}
+
+// ParseFederatedIDKey splits stored federated identity into provider and provider's user ID.
+func ParseFederatedIDKey(key string) (FederatedIdentityProvider, string) {
+	parts := strings.SplitN(key, ":", 2)
+	if len(parts) != 2 {
+		return "", key
+	}
+	return FederatedIdentityProvider(parts[0]), parts[1]
 }
 
 // TFAInfo encapsulates two-factor authentication user info.
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var (
+	// ErrWebhookNotFound is when webhook not found.
+	ErrWebhookNotFound = errors.New("Webhook not found")
+	// ErrWebhookDeliveryNotFound is when webhook delivery not found.
+	ErrWebhookDeliveryNotFound = errors.New("Webhook delivery not found")
+)
+
+// WebhookStorage stores webhooks and their deliveries.
+// Deliveries are persisted before they are sent, so pending ones survive server restart.
+type WebhookStorage interface {
+	// AddWebhook saves new webhook and returns it with generated ID.
+	AddWebhook(webhook Webhook) (Webhook, error)
+	WebhookByID(id string) (Webhook, error)
+	FetchWebhooks() ([]Webhook, error)
+	UpdateWebhook(webhook Webhook) (Webhook, error)
+	DeleteWebhook(id string) error
+
+	// AddWebhookDelivery saves new delivery and returns it with generated ID.
+	AddWebhookDelivery(delivery WebhookDelivery) (WebhookDelivery, error)
+	WebhookDeliveryByID(id string) (WebhookDelivery, error)
+	UpdateWebhookDelivery(delivery WebhookDelivery) error
+	// FetchWebhookDeliveries returns deliveries matching the filter, newest first, and total number of matching deliveries.
+	FetchWebhookDeliveries(filter WebhookDeliveryFilter, skip, limit int) ([]WebhookDelivery, int, error)
+	// PendingWebhookDeliveries returns pending deliveries due to be attempted at the time, oldest first.
+	PendingWebhookDeliveries(now int64, limit int) ([]WebhookDelivery, error)
+	Close()
+}
+
+// WebhookEventType is a type of user lifecycle event sent to webhooks.
+type WebhookEventType string
+
+const (
+	// WebhookEventUserCreated is sent when the user registers or is created by admin.
+	WebhookEventUserCreated WebhookEventType = "user.created"
+	// WebhookEventUserUpdated is sent when the user data is changed.
+	WebhookEventUserUpdated WebhookEventType = "user.updated"
+	// WebhookEventUserDeleted is sent when the user is deleted.
+	WebhookEventUserDeleted WebhookEventType = "user.deleted"
+	// WebhookEventUserLogin is sent when the user logs in.
+	WebhookEventUserLogin WebhookEventType = "user.login"
+	// WebhookEventUserDeactivated is sent when the user gets deactivated.
+	WebhookEventUserDeactivated WebhookEventType = "user.deactivated"
+)
+
+// IsValid checks if the event type is known.
+func (t WebhookEventType) IsValid() bool {
+	switch t {
+	case WebhookEventUserCreated, WebhookEventUserUpdated, WebhookEventUserDeleted, WebhookEventUserLogin, WebhookEventUserDeactivated:
+		return true
+	}
+	return false
+}
+
+// Webhook is an endpoint notified about user lifecycle events.
+// Webhook with empty AppID is global and receives events of all apps, including the ones made by admins.
+type Webhook struct {
+	ID     string `json:"id" bson:"_id"`
+	AppID  string `json:"app_id,omitempty" bson:"app_id,omitempty"`
+	URL    string `json:"url" bson:"url"`
+	Secret string `json:"secret" bson:"secret"`
+	// Events the webhook is subscribed to, all events if empty.
+	Events    []WebhookEventType `json:"events,omitempty" bson:"events,omitempty"`
+	Active    bool               `json:"active" bson:"active"`
+	CreatedAt int64              `json:"created_at" bson:"created_at"`
+}
+
+// Subscribed checks if the webhook receives the event of the app.
+func (w Webhook) Subscribed(eventType WebhookEventType, appID string) bool {
+	if !w.Active || (w.AppID != "" && w.AppID != appID) {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus is a status of webhook delivery.
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending is waiting for the next attempt.
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryDelivered is accepted by the webhook.
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryDead has run out of attempts and is kept for replay.
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery is a single event sent to a single webhook.
+type WebhookDelivery struct {
+	ID        string           `json:"id" bson:"_id"`
+	WebhookID string           `json:"webhook_id" bson:"webhook_id"`
+	EventType WebhookEventType `json:"event_type" bson:"event_type"`
+	// Payload is a signed request body.
+	Payload        json.RawMessage       `json:"payload" bson:"payload"`
+	Status         WebhookDeliveryStatus `json:"status" bson:"status"`
+	Attempts       int                   `json:"attempts" bson:"attempts"`
+	LastStatusCode int                   `json:"last_status_code,omitempty" bson:"last_status_code,omitempty"`
+	LastError      string                `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	NextAttemptAt  int64                 `json:"next_attempt_at,omitempty" bson:"next_attempt_at,omitempty"`
+	CreatedAt      int64                 `json:"created_at" bson:"created_at"`
+	DeliveredAt    int64                 `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+}
+
+// WebhookDeliveryFilter filters webhook deliveries. Empty fields match all deliveries.
+type WebhookDeliveryFilter struct {
+	WebhookID string
+	Status    WebhookDeliveryStatus
+}
+
+// Matches checks if the delivery matches the filter.
+func (f WebhookDeliveryFilter) Matches(d WebhookDelivery) bool {
+	return (f.WebhookID == "" || d.WebhookID == f.WebhookID) &&
+		(f.Status == "" || d.Status == f.Status)
+}
+
+// WebhookService delivers user lifecycle events to webhooks.
+type WebhookService interface {
+	// Notify queues the event for delivery to subscribed webhooks. App ID is empty for events made by admins.
+	Notify(eventType WebhookEventType, appID string, user User)
+	// Replay queues the delivery to be sent again, like dead ones after the receiver is fixed.
+	Replay(deliveryID string) (WebhookDelivery, error)
+	Start()
+	Stop()
+}
@@ -15,6 +15,16 @@ func NewComposer(settings model.ServerSettings) (*DatabaseComposer, error) {
 		newTokenStorage:            boltdb.NewTokenStorage,
 		newTokenBlacklist:          boltdb.NewTokenBlacklist,
 		newVerificationCodeStorage: boltdb.NewVerificationCodeStorage,
+		newInviteStorage:           boltdb.NewInviteStorage,
+		newUserSessionStorage:      boltdb.NewUserSessionStorage,
+		newAdminStorage:            boltdb.NewAdminStorage,
+		newAuditStorage:            boltdb.NewAuditStorage,
+		newAuthEventStorage:        boltdb.NewAuthEventStorage,
+		newWebhookStorage:          boltdb.NewWebhookStorage,
+		newOrganizationStorage:     boltdb.NewOrganizationStorage,
+		newSCIMTokenStorage:        boltdb.NewSCIMTokenStorage,
+		newPolicyStorage:           boltdb.NewPolicyStorage,
+		newRoleStorage:             boltdb.NewRoleStorage,
 	}
 	return &c, nil
 }
@@ -27,6 +37,16 @@ type DatabaseComposer struct {
 	newTokenStorage            func(*bolt.DB) (model.TokenStorage, error)
 	newTokenBlacklist          func(*bolt.DB) (model.TokenBlacklist, error)
 	newVerificationCodeStorage func(*bolt.DB) (model.VerificationCodeStorage, error)
+	newInviteStorage           func(*bolt.DB) (model.InviteStorage, error)
+	newUserSessionStorage      func(*bolt.DB) (model.UserSessionStorage, error)
+	newAdminStorage            func(*bolt.DB) (model.AdminStorage, error)
+	newAuditStorage            func(*bolt.DB) (model.AuditStorage, error)
+	newAuthEventStorage        func(*bolt.DB) (model.AuthEventStorage, error)
+	newWebhookStorage          func(*bolt.DB) (model.WebhookStorage, error)
+	newOrganizationStorage     func(*bolt.DB) (model.OrganizationStorage, error)
+	newSCIMTokenStorage        func(*bolt.DB) (model.SCIMTokenStorage, error)
+	newPolicyStorage           func(*bolt.DB) (model.PolicyStorage, error)
+	newRoleStorage             func(*bolt.DB) (model.RoleStorage, error)
 }
 
 // Compose composes all services with BoltDB support.
@@ -36,40 +56,100 @@ func (dc *DatabaseComposer) Compose() (
 	model.TokenStorage,
 	model.TokenBlacklist,
 	model.VerificationCodeStorage,
+	model.InviteStorage,
+	model.UserSessionStorage,
+	model.AdminStorage,
+	model.AuditStorage,
+	model.AuthEventStorage,
+	model.WebhookStorage,
+	model.OrganizationStorage,
+	model.SCIMTokenStorage,
+	model.PolicyStorage,
+	model.RoleStorage,
 	error,
 ) {
 	// We assume that all BoltDB-backed storages share the same filepath, so we can pick any of them.
 	db, err := boltdb.InitDB(dc.settings.Storage.AppStorage.Path)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	appStorage, err := dc.newAppStorage(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	userStorage, err := dc.newUserStorage(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	tokenStorage, err := dc.newTokenStorage(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	tokenBlacklist, err := dc.newTokenBlacklist(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	verificationCodeStorage, err := dc.newVerificationCodeStorage(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
-	return appStorage, userStorage, tokenStorage, tokenBlacklist, verificationCodeStorage, nil
+	inviteStorage, err := dc.newInviteStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	userSessionStorage, err := dc.newUserSessionStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	adminStorage, err := dc.newAdminStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	auditStorage, err := dc.newAuditStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	authEventStorage, err := dc.newAuthEventStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	webhookStorage, err := dc.newWebhookStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	organizationStorage, err := dc.newOrganizationStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	scimTokenStorage, err := dc.newSCIMTokenStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	policyStorage, err := dc.newPolicyStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	roleStorage, err := dc.newRoleStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	return appStorage, userStorage, tokenStorage, tokenBlacklist, verificationCodeStorage, inviteStorage, userSessionStorage, adminStorage, auditStorage, authEventStorage, webhookStorage, organizationStorage, scimTokenStorage, policyStorage, roleStorage, nil
 }
 
 // NewPartialComposer returns new partial composer with BoltDB support.
@@ -103,6 +183,56 @@ func NewPartialComposer(settings model.StorageSettings, options ...func(*Partial
 		dbPath = settings.VerificationCodeStorage.Path
 	}
 
+	if settings.InviteStorage.Type == model.DBTypeBoltDB {
+		pc.newInviteStorage = boltdb.NewInviteStorage
+		dbPath = settings.InviteStorage.Path
+	}
+
+	if settings.UserSessionStorage.Type == model.DBTypeBoltDB {
+		pc.newUserSessionStorage = boltdb.NewUserSessionStorage
+		dbPath = settings.UserSessionStorage.Path
+	}
+
+	if settings.AdminStorage.Type == model.DBTypeBoltDB {
+		pc.newAdminStorage = boltdb.NewAdminStorage
+		dbPath = settings.AdminStorage.Path
+	}
+
+	if settings.AuditStorage.Type == model.DBTypeBoltDB {
+		pc.newAuditStorage = boltdb.NewAuditStorage
+		dbPath = settings.AuditStorage.Path
+	}
+
+	if settings.AuthEventStorage.Type == model.DBTypeBoltDB {
+		pc.newAuthEventStorage = boltdb.NewAuthEventStorage
+		dbPath = settings.AuthEventStorage.Path
+	}
+
+	if settings.WebhookStorage.Type == model.DBTypeBoltDB {
+		pc.newWebhookStorage = boltdb.NewWebhookStorage
+		dbPath = settings.WebhookStorage.Path
+	}
+
+	if settings.OrganizationStorage.Type == model.DBTypeBoltDB {
+		pc.newOrganizationStorage = boltdb.NewOrganizationStorage
+		dbPath = settings.OrganizationStorage.Path
+	}
+
+	if settings.SCIMTokenStorage.Type == model.DBTypeBoltDB {
+		pc.newSCIMTokenStorage = boltdb.NewSCIMTokenStorage
+		dbPath = settings.SCIMTokenStorage.Path
+	}
+
+	if settings.PolicyStorage.Type == model.DBTypeBoltDB {
+		pc.newPolicyStorage = boltdb.NewPolicyStorage
+		dbPath = settings.PolicyStorage.Path
+	}
+
+	if settings.RoleStorage.Type == model.DBTypeBoltDB {
+		pc.newRoleStorage = boltdb.NewRoleStorage
+		dbPath = settings.RoleStorage.Path
+	}
+
 	db, err := boltdb.InitDB(dbPath)
 	if err != nil {
 		return nil, err
@@ -125,6 +255,16 @@ type PartialDatabaseComposer struct {
 	newTokenStorage            func(*bolt.DB) (model.TokenStorage, error)
 	newTokenBlacklist          func(*bolt.DB) (model.TokenBlacklist, error)
 	newVerificationCodeStorage func(*bolt.DB) (model.VerificationCodeStorage, error)
+	newInviteStorage           func(*bolt.DB) (model.InviteStorage, error)
+	newUserSessionStorage      func(*bolt.DB) (model.UserSessionStorage, error)
+	newAdminStorage            func(*bolt.DB) (model.AdminStorage, error)
+	newAuditStorage            func(*bolt.DB) (model.AuditStorage, error)
+	newAuthEventStorage        func(*bolt.DB) (model.AuthEventStorage, error)
+	newWebhookStorage          func(*bolt.DB) (model.WebhookStorage, error)
+	newOrganizationStorage     func(*bolt.DB) (model.OrganizationStorage, error)
+	newSCIMTokenStorage        func(*bolt.DB) (model.SCIMTokenStorage, error)
+	newPolicyStorage           func(*bolt.DB) (model.PolicyStorage, error)
+	newRoleStorage             func(*bolt.DB) (model.RoleStorage, error)
 }
 
 // AppStorageComposer returns app storage composer.
@@ -176,3 +316,103 @@ func (pc *PartialDatabaseComposer) VerificationCodeStorageComposer() func() (mod
 	}
 	return nil
 }
+
+// InviteStorageComposer returns invite storage composer.
+func (pc *PartialDatabaseComposer) InviteStorageComposer() func() (model.InviteStorage, error) {
+	if pc.newInviteStorage != nil {
+		return func() (model.InviteStorage, error) {
+			return pc.newInviteStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// UserSessionStorageComposer returns user session storage composer.
+func (pc *PartialDatabaseComposer) UserSessionStorageComposer() func() (model.UserSessionStorage, error) {
+	if pc.newUserSessionStorage != nil {
+		return func() (model.UserSessionStorage, error) {
+			return pc.newUserSessionStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// AdminStorageComposer returns admin storage composer.
+func (pc *PartialDatabaseComposer) AdminStorageComposer() func() (model.AdminStorage, error) {
+	if pc.newAdminStorage != nil {
+		return func() (model.AdminStorage, error) {
+			return pc.newAdminStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// AuditStorageComposer returns audit storage composer.
+func (pc *PartialDatabaseComposer) AuditStorageComposer() func() (model.AuditStorage, error) {
+	if pc.newAuditStorage != nil {
+		return func() (model.AuditStorage, error) {
+			return pc.newAuditStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// AuthEventStorageComposer returns auth event storage composer.
+func (pc *PartialDatabaseComposer) AuthEventStorageComposer() func() (model.AuthEventStorage, error) {
+	if pc.newAuthEventStorage != nil {
+		return func() (model.AuthEventStorage, error) {
+			return pc.newAuthEventStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// WebhookStorageComposer returns webhook storage composer.
+func (pc *PartialDatabaseComposer) WebhookStorageComposer() func() (model.WebhookStorage, error) {
+	if pc.newWebhookStorage != nil {
+		return func() (model.WebhookStorage, error) {
+			return pc.newWebhookStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// OrganizationStorageComposer returns organization storage composer.
+func (pc *PartialDatabaseComposer) OrganizationStorageComposer() func() (model.OrganizationStorage, error) {
+	if pc.newOrganizationStorage != nil {
+		return func() (model.OrganizationStorage, error) {
+			return pc.newOrganizationStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// SCIMTokenStorageComposer returns SCIM token storage composer.
+func (pc *PartialDatabaseComposer) SCIMTokenStorageComposer() func() (model.SCIMTokenStorage, error) {
+	if pc.newSCIMTokenStorage != nil {
+		return func() (model.SCIMTokenStorage, error) {
+			return pc.newSCIMTokenStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// PolicyStorageComposer returns policy storage composer.
+func (pc *PartialDatabaseComposer) PolicyStorageComposer() func() (model.PolicyStorage, error) {
+	if pc.newPolicyStorage != nil {
+		return func() (model.PolicyStorage, error) {
+			return pc.newPolicyStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// RoleStorageComposer returns role storage composer.
+func (pc *PartialDatabaseComposer) RoleStorageComposer() func() (model.RoleStorage, error) {
+	if pc.newRoleStorage != nil {
+		return func() (model.RoleStorage, error) {
+			return pc.newRoleStorage(pc.db)
+		}
+	}
+	return nil
+}
@@ -12,6 +12,16 @@ type DatabaseComposer interface {
 		model.TokenStorage,
 		model.TokenBlacklist,
 		model.VerificationCodeStorage,
+		model.InviteStorage,
+		model.UserSessionStorage,
+		model.AdminStorage,
+		model.AuditStorage,
+		model.AuthEventStorage,
+		model.WebhookStorage,
+		model.OrganizationStorage,
+		model.SCIMTokenStorage,
+		model.PolicyStorage,
+		model.RoleStorage,
 		error,
 	)
 }
@@ -23,6 +33,16 @@ type PartialDatabaseComposer interface {
 	TokenStorageComposer() func() (model.TokenStorage, error)
 	TokenBlacklistComposer() func() (model.TokenBlacklist, error)
 	VerificationCodeStorageComposer() func() (model.VerificationCodeStorage, error)
+	InviteStorageComposer() func() (model.InviteStorage, error)
+	UserSessionStorageComposer() func() (model.UserSessionStorage, error)
+	AdminStorageComposer() func() (model.AdminStorage, error)
+	AuditStorageComposer() func() (model.AuditStorage, error)
+	AuthEventStorageComposer() func() (model.AuthEventStorage, error)
+	WebhookStorageComposer() func() (model.WebhookStorage, error)
+	OrganizationStorageComposer() func() (model.OrganizationStorage, error)
+	SCIMTokenStorageComposer() func() (model.SCIMTokenStorage, error)
+	PolicyStorageComposer() func() (model.PolicyStorage, error)
+	RoleStorageComposer() func() (model.RoleStorage, error)
 }
 
 // Composer is a service composer which is agnostic to particular database implementations.
@@ -33,6 +53,16 @@ type Composer struct {
 	newTokenStorage            func() (model.TokenStorage, error)
 	newTokenBlacklist          func() (model.TokenBlacklist, error)
 	newVerificationCodeStorage func() (model.VerificationCodeStorage, error)
+	newInviteStorage           func() (model.InviteStorage, error)
+	newUserSessionStorage      func() (model.UserSessionStorage, error)
+	newAdminStorage            func() (model.AdminStorage, error)
+	newAuditStorage            func() (model.AuditStorage, error)
+	newAuthEventStorage        func() (model.AuthEventStorage, error)
+	newWebhookStorage          func() (model.WebhookStorage, error)
+	newOrganizationStorage     func() (model.OrganizationStorage, error)
+	newSCIMTokenStorage        func() (model.SCIMTokenStorage, error)
+	newPolicyStorage           func() (model.PolicyStorage, error)
+	newRoleStorage             func() (model.RoleStorage, error)
 }
 
 // Compose composes all services.
@@ -42,34 +72,94 @@ func (c *Composer) Compose() (
 	model.TokenStorage,
 	model.TokenBlacklist,
 	model.VerificationCodeStorage,
+	model.InviteStorage,
+	model.UserSessionStorage,
+	model.AdminStorage,
+	model.AuditStorage,
+	model.AuthEventStorage,
+	model.WebhookStorage,
+	model.OrganizationStorage,
+	model.SCIMTokenStorage,
+	model.PolicyStorage,
+	model.RoleStorage,
 	error,
 ) {
 	appStorage, err := c.newAppStorage()
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	userStorage, err := c.newUserStorage()
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	tokenStorage, err := c.newTokenStorage()
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	tokenBlacklist, err := c.newTokenBlacklist()
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	verificationCodeStorage, err := c.newVerificationCodeStorage()
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
-	return appStorage, userStorage, tokenStorage, tokenBlacklist, verificationCodeStorage, nil
+	inviteStorage, err := c.newInviteStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	userSessionStorage, err := c.newUserSessionStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	adminStorage, err := c.newAdminStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	auditStorage, err := c.newAuditStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	authEventStorage, err := c.newAuthEventStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	webhookStorage, err := c.newWebhookStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	organizationStorage, err := c.newOrganizationStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	scimTokenStorage, err := c.newSCIMTokenStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	policyStorage, err := c.newPolicyStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	roleStorage, err := c.newRoleStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	return appStorage, userStorage, tokenStorage, tokenBlacklist, verificationCodeStorage, inviteStorage, userSessionStorage, adminStorage, auditStorage, authEventStorage, webhookStorage, organizationStorage, scimTokenStorage, policyStorage, roleStorage, nil
 }
 
 // NewComposer returns new database composer based on passed server settings.
@@ -92,6 +182,36 @@ func NewComposer(settings model.ServerSettings, partialComposers []PartialDataba
 		if pc.VerificationCodeStorageComposer() != nil {
 			c.newVerificationCodeStorage = pc.VerificationCodeStorageComposer()
 		}
+		if pc.InviteStorageComposer() != nil {
+			c.newInviteStorage = pc.InviteStorageComposer()
+		}
+		if pc.UserSessionStorageComposer() != nil {
+			c.newUserSessionStorage = pc.UserSessionStorageComposer()
+		}
+		if pc.AdminStorageComposer() != nil {
+			c.newAdminStorage = pc.AdminStorageComposer()
+		}
+		if pc.AuditStorageComposer() != nil {
+			c.newAuditStorage = pc.AuditStorageComposer()
+		}
+		if pc.AuthEventStorageComposer() != nil {
+			c.newAuthEventStorage = pc.AuthEventStorageComposer()
+		}
+		if pc.WebhookStorageComposer() != nil {
+			c.newWebhookStorage = pc.WebhookStorageComposer()
+		}
+		if pc.OrganizationStorageComposer() != nil {
+			c.newOrganizationStorage = pc.OrganizationStorageComposer()
+		}
+		if pc.SCIMTokenStorageComposer() != nil {
+			c.newSCIMTokenStorage = pc.SCIMTokenStorageComposer()
+		}
+		if pc.PolicyStorageComposer() != nil {
+			c.newPolicyStorage = pc.PolicyStorageComposer()
+		}
+		if pc.RoleStorageComposer() != nil {
+			c.newRoleStorage = pc.RoleStorageComposer()
+		}
 	}
 
 	for _, option := range options {
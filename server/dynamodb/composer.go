@@ -14,6 +14,16 @@ func NewComposer(settings model.ServerSettings) (*DatabaseComposer, error) {
 		newTokenStorage:            dynamodb.NewTokenStorage,
 		newTokenBlacklist:          dynamodb.NewTokenBlacklist,
 		newVerificationCodeStorage: dynamodb.NewVerificationCodeStorage,
+		newInviteStorage:           dynamodb.NewInviteStorage,
+		newUserSessionStorage:      dynamodb.NewUserSessionStorage,
+		newAdminStorage:            dynamodb.NewAdminStorage,
+		newAuditStorage:            dynamodb.NewAuditStorage,
+		newAuthEventStorage:        dynamodb.NewAuthEventStorage,
+		newWebhookStorage:          dynamodb.NewWebhookStorage,
+		newOrganizationStorage:     dynamodb.NewOrganizationStorage,
+		newSCIMTokenStorage:        dynamodb.NewSCIMTokenStorage,
+		newPolicyStorage:           dynamodb.NewPolicyStorage,
+		newRoleStorage:             dynamodb.NewRoleStorage,
 	}
 	return &c, nil
 }
@@ -26,6 +36,16 @@ type DatabaseComposer struct {
 	newTokenStorage            func(*dynamodb.DB) (model.TokenStorage, error)
 	newTokenBlacklist          func(*dynamodb.DB) (model.TokenBlacklist, error)
 	newVerificationCodeStorage func(*dynamodb.DB) (model.VerificationCodeStorage, error)
+	newInviteStorage           func(*dynamodb.DB) (model.InviteStorage, error)
+	newUserSessionStorage      func(*dynamodb.DB) (model.UserSessionStorage, error)
+	newAdminStorage            func(*dynamodb.DB) (model.AdminStorage, error)
+	newAuditStorage            func(*dynamodb.DB) (model.AuditStorage, error)
+	newAuthEventStorage        func(*dynamodb.DB) (model.AuthEventStorage, error)
+	newWebhookStorage          func(*dynamodb.DB) (model.WebhookStorage, error)
+	newOrganizationStorage     func(*dynamodb.DB) (model.OrganizationStorage, error)
+	newSCIMTokenStorage        func(*dynamodb.DB) (model.SCIMTokenStorage, error)
+	newPolicyStorage           func(*dynamodb.DB) (model.PolicyStorage, error)
+	newRoleStorage             func(*dynamodb.DB) (model.RoleStorage, error)
 }
 
 // Compose composes all services with DynamoDB support.
@@ -35,82 +55,219 @@ func (dc *DatabaseComposer) Compose() (
 	model.TokenStorage,
 	model.TokenBlacklist,
 	model.VerificationCodeStorage,
+	model.InviteStorage,
+	model.UserSessionStorage,
+	model.AdminStorage,
+	model.AuditStorage,
+	model.AuthEventStorage,
+	model.WebhookStorage,
+	model.OrganizationStorage,
+	model.SCIMTokenStorage,
+	model.PolicyStorage,
+	model.RoleStorage,
 	error,
 ) {
-	// We assume that all DynamoDB-backed storages share the same endpoint and region, so we can pick any of them.
+	// We assume that all DynamoDB-backed storages share the same endpoint, region and table prefix, so we can pick any of them.
 	db, err := dynamodb.NewDB(dc.settings.Storage.AppStorage.Endpoint, dc.settings.Storage.AppStorage.Region)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
+	db.UseTablePrefix(dc.settings.Storage.AppStorage.TablePrefix)
 
 	appStorage, err := dc.newAppStorage(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	userStorage, err := dc.newUserStorage(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	tokenStorage, err := dc.newTokenStorage(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	tokenBlacklist, err := dc.newTokenBlacklist(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	verificationCodeStorage, err := dc.newVerificationCodeStorage(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
-	return appStorage, userStorage, tokenStorage, tokenBlacklist, verificationCodeStorage, nil
+	inviteStorage, err := dc.newInviteStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	userSessionStorage, err := dc.newUserSessionStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	adminStorage, err := dc.newAdminStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	auditStorage, err := dc.newAuditStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	authEventStorage, err := dc.newAuthEventStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	webhookStorage, err := dc.newWebhookStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	organizationStorage, err := dc.newOrganizationStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	scimTokenStorage, err := dc.newSCIMTokenStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	policyStorage, err := dc.newPolicyStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	roleStorage, err := dc.newRoleStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	return appStorage, userStorage, tokenStorage, tokenBlacklist, verificationCodeStorage, inviteStorage, userSessionStorage, adminStorage, auditStorage, authEventStorage, webhookStorage, organizationStorage, scimTokenStorage, policyStorage, roleStorage, nil
 }
 
 // NewPartialComposer returns new partial composer with DynamoDB support.
 func NewPartialComposer(settings model.StorageSettings, options ...func(*PartialDatabaseComposer) error) (*PartialDatabaseComposer, error) {
 	pc := &PartialDatabaseComposer{}
-	// We assume that all DynamoDB-backed storages share the same endpoint and region, so we can pick any of them.
-	var dbEndpoint, dbRegion string
+	// We assume that all DynamoDB-backed storages share the same endpoint, region and table prefix, so we can pick any of them.
+	var dbEndpoint, dbRegion, dbTablePrefix string
 
 	if settings.AppStorage.Type == model.DBTypeDynamoDB {
 		pc.newAppStorage = dynamodb.NewAppStorage
 		dbEndpoint = settings.AppStorage.Endpoint
 		dbRegion = settings.AppStorage.Region
+		dbTablePrefix = settings.AppStorage.TablePrefix
 	}
 
 	if settings.UserStorage.Type == model.DBTypeDynamoDB {
 		pc.newUserStorage = dynamodb.NewUserStorage
 		dbEndpoint = settings.UserStorage.Endpoint
 		dbRegion = settings.UserStorage.Region
+		dbTablePrefix = settings.UserStorage.TablePrefix
 	}
 
 	if settings.TokenStorage.Type == model.DBTypeDynamoDB {
 		pc.newTokenStorage = dynamodb.NewTokenStorage
 		dbEndpoint = settings.TokenStorage.Endpoint
 		dbRegion = settings.TokenStorage.Region
+		dbTablePrefix = settings.TokenStorage.TablePrefix
 	}
 
 	if settings.TokenBlacklist.Type == model.DBTypeDynamoDB {
 		pc.newTokenBlacklist = dynamodb.NewTokenBlacklist
 		dbEndpoint = settings.TokenBlacklist.Endpoint
 		dbRegion = settings.TokenBlacklist.Region
+		dbTablePrefix = settings.TokenBlacklist.TablePrefix
 	}
 
 	if settings.VerificationCodeStorage.Type == model.DBTypeDynamoDB {
 		pc.newVerificationCodeStorage = dynamodb.NewVerificationCodeStorage
 		dbEndpoint = settings.VerificationCodeStorage.Endpoint
 		dbRegion = settings.VerificationCodeStorage.Region
+		dbTablePrefix = settings.VerificationCodeStorage.TablePrefix
+	}
+
+	if settings.InviteStorage.Type == model.DBTypeDynamoDB {
+		pc.newInviteStorage = dynamodb.NewInviteStorage
+		dbEndpoint = settings.InviteStorage.Endpoint
+		dbRegion = settings.InviteStorage.Region
+		dbTablePrefix = settings.InviteStorage.TablePrefix
+	}
+
+	if settings.UserSessionStorage.Type == model.DBTypeDynamoDB {
+		pc.newUserSessionStorage = dynamodb.NewUserSessionStorage
+		dbEndpoint = settings.UserSessionStorage.Endpoint
+		dbRegion = settings.UserSessionStorage.Region
+		dbTablePrefix = settings.UserSessionStorage.TablePrefix
+	}
+
+	if settings.AdminStorage.Type == model.DBTypeDynamoDB {
+		pc.newAdminStorage = dynamodb.NewAdminStorage
+		dbEndpoint = settings.AdminStorage.Endpoint
+		dbRegion = settings.AdminStorage.Region
+		dbTablePrefix = settings.AdminStorage.TablePrefix
+	}
+
+	if settings.AuditStorage.Type == model.DBTypeDynamoDB {
+		pc.newAuditStorage = dynamodb.NewAuditStorage
+		dbEndpoint = settings.AuditStorage.Endpoint
+		dbRegion = settings.AuditStorage.Region
+		dbTablePrefix = settings.AuditStorage.TablePrefix
+	}
+
+	if settings.AuthEventStorage.Type == model.DBTypeDynamoDB {
+		pc.newAuthEventStorage = dynamodb.NewAuthEventStorage
+		dbEndpoint = settings.AuthEventStorage.Endpoint
+		dbRegion = settings.AuthEventStorage.Region
+		dbTablePrefix = settings.AuthEventStorage.TablePrefix
+	}
+
+	if settings.WebhookStorage.Type == model.DBTypeDynamoDB {
+		pc.newWebhookStorage = dynamodb.NewWebhookStorage
+		dbEndpoint = settings.WebhookStorage.Endpoint
+		dbRegion = settings.WebhookStorage.Region
+		dbTablePrefix = settings.WebhookStorage.TablePrefix
+	}
+
+	if settings.OrganizationStorage.Type == model.DBTypeDynamoDB {
+		pc.newOrganizationStorage = dynamodb.NewOrganizationStorage
+		dbEndpoint = settings.OrganizationStorage.Endpoint
+		dbRegion = settings.OrganizationStorage.Region
+		dbTablePrefix = settings.OrganizationStorage.TablePrefix
+	}
+
+	if settings.SCIMTokenStorage.Type == model.DBTypeDynamoDB {
+		pc.newSCIMTokenStorage = dynamodb.NewSCIMTokenStorage
+		dbEndpoint = settings.SCIMTokenStorage.Endpoint
+		dbRegion = settings.SCIMTokenStorage.Region
+		dbTablePrefix = settings.SCIMTokenStorage.TablePrefix
+	}
+
+	if settings.PolicyStorage.Type == model.DBTypeDynamoDB {
+		pc.newPolicyStorage = dynamodb.NewPolicyStorage
+		dbEndpoint = settings.PolicyStorage.Endpoint
+		dbRegion = settings.PolicyStorage.Region
+		dbTablePrefix = settings.PolicyStorage.TablePrefix
+	}
+
+	if settings.RoleStorage.Type == model.DBTypeDynamoDB {
+		pc.newRoleStorage = dynamodb.NewRoleStorage
+		dbEndpoint = settings.RoleStorage.Endpoint
+		dbRegion = settings.RoleStorage.Region
+		dbTablePrefix = settings.RoleStorage.TablePrefix
 	}
 
 	db, err := dynamodb.NewDB(dbEndpoint, dbRegion)
 	if err != nil {
 		return nil, err
 	}
+	db.UseTablePrefix(dbTablePrefix)
 	pc.db = db
 
 	for _, option := range options {
@@ -129,6 +286,16 @@ type PartialDatabaseComposer struct {
 	newTokenStorage            func(*dynamodb.DB) (model.TokenStorage, error)
 	newTokenBlacklist          func(*dynamodb.DB) (model.TokenBlacklist, error)
 	newVerificationCodeStorage func(*dynamodb.DB) (model.VerificationCodeStorage, error)
+	newInviteStorage           func(*dynamodb.DB) (model.InviteStorage, error)
+	newUserSessionStorage      func(*dynamodb.DB) (model.UserSessionStorage, error)
+	newAdminStorage            func(*dynamodb.DB) (model.AdminStorage, error)
+	newAuditStorage            func(*dynamodb.DB) (model.AuditStorage, error)
+	newAuthEventStorage        func(*dynamodb.DB) (model.AuthEventStorage, error)
+	newWebhookStorage          func(*dynamodb.DB) (model.WebhookStorage, error)
+	newOrganizationStorage     func(*dynamodb.DB) (model.OrganizationStorage, error)
+	newSCIMTokenStorage        func(*dynamodb.DB) (model.SCIMTokenStorage, error)
+	newPolicyStorage           func(*dynamodb.DB) (model.PolicyStorage, error)
+	newRoleStorage             func(*dynamodb.DB) (model.RoleStorage, error)
 }
 
 // AppStorageComposer returns app storage composer.
@@ -180,3 +347,103 @@ func (pc *PartialDatabaseComposer) VerificationCodeStorageComposer() func() (mod
 	}
 	return nil
 }
+
+// InviteStorageComposer returns invite storage composer.
+func (pc *PartialDatabaseComposer) InviteStorageComposer() func() (model.InviteStorage, error) {
+	if pc.newInviteStorage != nil {
+		return func() (model.InviteStorage, error) {
+			return pc.newInviteStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// UserSessionStorageComposer returns user session storage composer.
+func (pc *PartialDatabaseComposer) UserSessionStorageComposer() func() (model.UserSessionStorage, error) {
+	if pc.newUserSessionStorage != nil {
+		return func() (model.UserSessionStorage, error) {
+			return pc.newUserSessionStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// AdminStorageComposer returns admin storage composer.
+func (pc *PartialDatabaseComposer) AdminStorageComposer() func() (model.AdminStorage, error) {
+	if pc.newAdminStorage != nil {
+		return func() (model.AdminStorage, error) {
+			return pc.newAdminStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// AuditStorageComposer returns audit storage composer.
+func (pc *PartialDatabaseComposer) AuditStorageComposer() func() (model.AuditStorage, error) {
+	if pc.newAuditStorage != nil {
+		return func() (model.AuditStorage, error) {
+			return pc.newAuditStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// AuthEventStorageComposer returns auth event storage composer.
+func (pc *PartialDatabaseComposer) AuthEventStorageComposer() func() (model.AuthEventStorage, error) {
+	if pc.newAuthEventStorage != nil {
+		return func() (model.AuthEventStorage, error) {
+			return pc.newAuthEventStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// WebhookStorageComposer returns webhook storage composer.
+func (pc *PartialDatabaseComposer) WebhookStorageComposer() func() (model.WebhookStorage, error) {
+	if pc.newWebhookStorage != nil {
+		return func() (model.WebhookStorage, error) {
+			return pc.newWebhookStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// OrganizationStorageComposer returns organization storage composer.
+func (pc *PartialDatabaseComposer) OrganizationStorageComposer() func() (model.OrganizationStorage, error) {
+	if pc.newOrganizationStorage != nil {
+		return func() (model.OrganizationStorage, error) {
+			return pc.newOrganizationStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// SCIMTokenStorageComposer returns SCIM token storage composer.
+func (pc *PartialDatabaseComposer) SCIMTokenStorageComposer() func() (model.SCIMTokenStorage, error) {
+	if pc.newSCIMTokenStorage != nil {
+		return func() (model.SCIMTokenStorage, error) {
+			return pc.newSCIMTokenStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// PolicyStorageComposer returns policy storage composer.
+func (pc *PartialDatabaseComposer) PolicyStorageComposer() func() (model.PolicyStorage, error) {
+	if pc.newPolicyStorage != nil {
+		return func() (model.PolicyStorage, error) {
+			return pc.newPolicyStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// RoleStorageComposer returns role storage composer.
+func (pc *PartialDatabaseComposer) RoleStorageComposer() func() (model.RoleStorage, error) {
+	if pc.newRoleStorage != nil {
+		return func() (model.RoleStorage, error) {
+			return pc.newRoleStorage(pc.db)
+		}
+	}
+	return nil
+}
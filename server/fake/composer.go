@@ -14,6 +14,16 @@ func NewComposer(settings model.ServerSettings) (*DatabaseComposer, error) {
 		newTokenStorage:            mem.NewTokenStorage,
 		newTokenBlacklist:          mem.NewTokenBlacklist,
 		newVerificationCodeStorage: mem.NewVerificationCodeStorage,
+		newInviteStorage:           mem.NewInviteStorage,
+		newUserSessionStorage:      mem.NewUserSessionStorage,
+		newAdminStorage:            mem.NewAdminStorage,
+		newAuditStorage:            mem.NewAuditStorage,
+		newAuthEventStorage:        mem.NewAuthEventStorage,
+		newWebhookStorage:          mem.NewWebhookStorage,
+		newOrganizationStorage:     mem.NewOrganizationStorage,
+		newSCIMTokenStorage:        mem.NewSCIMTokenStorage,
+		newPolicyStorage:           mem.NewPolicyStorage,
+		newRoleStorage:             mem.NewRoleStorage,
 	}
 	return &c, nil
 }
@@ -26,6 +36,16 @@ type DatabaseComposer struct {
 	newTokenStorage            func() (model.TokenStorage, error)
 	newTokenBlacklist          func() (model.TokenBlacklist, error)
 	newVerificationCodeStorage func() (model.VerificationCodeStorage, error)
+	newInviteStorage           func() (model.InviteStorage, error)
+	newUserSessionStorage      func() (model.UserSessionStorage, error)
+	newAdminStorage            func() (model.AdminStorage, error)
+	newAuditStorage            func() (model.AuditStorage, error)
+	newAuthEventStorage        func() (model.AuthEventStorage, error)
+	newWebhookStorage          func() (model.WebhookStorage, error)
+	newOrganizationStorage     func() (model.OrganizationStorage, error)
+	newSCIMTokenStorage        func() (model.SCIMTokenStorage, error)
+	newPolicyStorage           func() (model.PolicyStorage, error)
+	newRoleStorage             func() (model.RoleStorage, error)
 }
 
 // Compose composes all services with in-memory storage support.
@@ -35,34 +55,94 @@ func (dc *DatabaseComposer) Compose() (
 	model.TokenStorage,
 	model.TokenBlacklist,
 	model.VerificationCodeStorage,
+	model.InviteStorage,
+	model.UserSessionStorage,
+	model.AdminStorage,
+	model.AuditStorage,
+	model.AuthEventStorage,
+	model.WebhookStorage,
+	model.OrganizationStorage,
+	model.SCIMTokenStorage,
+	model.PolicyStorage,
+	model.RoleStorage,
 	error,
 ) {
 	appStorage, err := dc.newAppStorage()
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	userStorage, err := dc.newUserStorage()
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	tokenStorage, err := dc.newTokenStorage()
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	tokenBlacklist, err := dc.newTokenBlacklist()
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	verificationCodeStorage, err := dc.newVerificationCodeStorage()
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
-	return appStorage, userStorage, tokenStorage, tokenBlacklist, verificationCodeStorage, nil
+	inviteStorage, err := dc.newInviteStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	userSessionStorage, err := dc.newUserSessionStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	adminStorage, err := dc.newAdminStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	auditStorage, err := dc.newAuditStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	authEventStorage, err := dc.newAuthEventStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	webhookStorage, err := dc.newWebhookStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	organizationStorage, err := dc.newOrganizationStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	scimTokenStorage, err := dc.newSCIMTokenStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	policyStorage, err := dc.newPolicyStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	roleStorage, err := dc.newRoleStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	return appStorage, userStorage, tokenStorage, tokenBlacklist, verificationCodeStorage, inviteStorage, userSessionStorage, adminStorage, auditStorage, authEventStorage, webhookStorage, organizationStorage, scimTokenStorage, policyStorage, roleStorage, nil
 }
 
 // NewPartialComposer returns new partial composer with in-memory storage support.
@@ -89,6 +169,46 @@ func NewPartialComposer(settings model.StorageSettings, options ...func(*Partial
 		pc.newVerificationCodeStorage = mem.NewVerificationCodeStorage
 	}
 
+	if settings.InviteStorage.Type == model.DBTypeFake {
+		pc.newInviteStorage = mem.NewInviteStorage
+	}
+
+	if settings.UserSessionStorage.Type == model.DBTypeFake {
+		pc.newUserSessionStorage = mem.NewUserSessionStorage
+	}
+
+	if settings.AdminStorage.Type == model.DBTypeFake {
+		pc.newAdminStorage = mem.NewAdminStorage
+	}
+
+	if settings.AuditStorage.Type == model.DBTypeFake {
+		pc.newAuditStorage = mem.NewAuditStorage
+	}
+
+	if settings.AuthEventStorage.Type == model.DBTypeFake {
+		pc.newAuthEventStorage = mem.NewAuthEventStorage
+	}
+
+	if settings.WebhookStorage.Type == model.DBTypeFake {
+		pc.newWebhookStorage = mem.NewWebhookStorage
+	}
+
+	if settings.OrganizationStorage.Type == model.DBTypeFake {
+		pc.newOrganizationStorage = mem.NewOrganizationStorage
+	}
+
+	if settings.SCIMTokenStorage.Type == model.DBTypeFake {
+		pc.newSCIMTokenStorage = mem.NewSCIMTokenStorage
+	}
+
+	if settings.PolicyStorage.Type == model.DBTypeFake {
+		pc.newPolicyStorage = mem.NewPolicyStorage
+	}
+
+	if settings.RoleStorage.Type == model.DBTypeFake {
+		pc.newRoleStorage = mem.NewRoleStorage
+	}
+
 	for _, option := range options {
 		if err := option(pc); err != nil {
 			return nil, err
@@ -104,6 +224,16 @@ type PartialDatabaseComposer struct {
 	newTokenStorage            func() (model.TokenStorage, error)
 	newTokenBlacklist          func() (model.TokenBlacklist, error)
 	newVerificationCodeStorage func() (model.VerificationCodeStorage, error)
+	newInviteStorage           func() (model.InviteStorage, error)
+	newUserSessionStorage      func() (model.UserSessionStorage, error)
+	newAdminStorage            func() (model.AdminStorage, error)
+	newAuditStorage            func() (model.AuditStorage, error)
+	newAuthEventStorage        func() (model.AuthEventStorage, error)
+	newWebhookStorage          func() (model.WebhookStorage, error)
+	newOrganizationStorage     func() (model.OrganizationStorage, error)
+	newSCIMTokenStorage        func() (model.SCIMTokenStorage, error)
+	newPolicyStorage           func() (model.PolicyStorage, error)
+	newRoleStorage             func() (model.RoleStorage, error)
 }
 
 // AppStorageComposer returns app storage composer.
@@ -155,3 +285,103 @@ func (pc *PartialDatabaseComposer) VerificationCodeStorageComposer() func() (mod
 	}
 	return nil
 }
+
+// InviteStorageComposer returns invite storage composer.
+func (pc *PartialDatabaseComposer) InviteStorageComposer() func() (model.InviteStorage, error) {
+	if pc.newInviteStorage != nil {
+		return func() (model.InviteStorage, error) {
+			return pc.newInviteStorage()
+		}
+	}
+	return nil
+}
+
+// UserSessionStorageComposer returns user session storage composer.
+func (pc *PartialDatabaseComposer) UserSessionStorageComposer() func() (model.UserSessionStorage, error) {
+	if pc.newUserSessionStorage != nil {
+		return func() (model.UserSessionStorage, error) {
+			return pc.newUserSessionStorage()
+		}
+	}
+	return nil
+}
+
+// AdminStorageComposer returns admin storage composer.
+func (pc *PartialDatabaseComposer) AdminStorageComposer() func() (model.AdminStorage, error) {
+	if pc.newAdminStorage != nil {
+		return func() (model.AdminStorage, error) {
+			return pc.newAdminStorage()
+		}
+	}
+	return nil
+}
+
+// AuditStorageComposer returns audit storage composer.
+func (pc *PartialDatabaseComposer) AuditStorageComposer() func() (model.AuditStorage, error) {
+	if pc.newAuditStorage != nil {
+		return func() (model.AuditStorage, error) {
+			return pc.newAuditStorage()
+		}
+	}
+	return nil
+}
+
+// AuthEventStorageComposer returns auth event storage composer.
+func (pc *PartialDatabaseComposer) AuthEventStorageComposer() func() (model.AuthEventStorage, error) {
+	if pc.newAuthEventStorage != nil {
+		return func() (model.AuthEventStorage, error) {
+			return pc.newAuthEventStorage()
+		}
+	}
+	return nil
+}
+
+// WebhookStorageComposer returns webhook storage composer.
+func (pc *PartialDatabaseComposer) WebhookStorageComposer() func() (model.WebhookStorage, error) {
+	if pc.newWebhookStorage != nil {
+		return func() (model.WebhookStorage, error) {
+			return pc.newWebhookStorage()
+		}
+	}
+	return nil
+}
+
+// OrganizationStorageComposer returns organization storage composer.
+func (pc *PartialDatabaseComposer) OrganizationStorageComposer() func() (model.OrganizationStorage, error) {
+	if pc.newOrganizationStorage != nil {
+		return func() (model.OrganizationStorage, error) {
+			return pc.newOrganizationStorage()
+		}
+	}
+	return nil
+}
+
+// SCIMTokenStorageComposer returns SCIM token storage composer.
+func (pc *PartialDatabaseComposer) SCIMTokenStorageComposer() func() (model.SCIMTokenStorage, error) {
+	if pc.newSCIMTokenStorage != nil {
+		return func() (model.SCIMTokenStorage, error) {
+			return pc.newSCIMTokenStorage()
+		}
+	}
+	return nil
+}
+
+// PolicyStorageComposer returns policy storage composer.
+func (pc *PartialDatabaseComposer) PolicyStorageComposer() func() (model.PolicyStorage, error) {
+	if pc.newPolicyStorage != nil {
+		return func() (model.PolicyStorage, error) {
+			return pc.newPolicyStorage()
+		}
+	}
+	return nil
+}
+
+// RoleStorageComposer returns role storage composer.
+func (pc *PartialDatabaseComposer) RoleStorageComposer() func() (model.RoleStorage, error) {
+	if pc.newRoleStorage != nil {
+		return func() (model.RoleStorage, error) {
+			return pc.newRoleStorage()
+		}
+	}
+	return nil
+}
@@ -14,6 +14,16 @@ func NewComposer(settings model.ServerSettings) (*DatabaseComposer, error) {
 		newTokenStorage:            mongo.NewTokenStorage,
 		newTokenBlacklist:          mongo.NewTokenBlacklist,
 		newVerificationCodeStorage: mongo.NewVerificationCodeStorage,
+		newInviteStorage:           mongo.NewInviteStorage,
+		newUserSessionStorage:      mongo.NewUserSessionStorage,
+		newAdminStorage:            mongo.NewAdminStorage,
+		newAuditStorage:            mongo.NewAuditStorage,
+		newAuthEventStorage:        mongo.NewAuthEventStorage,
+		newWebhookStorage:          mongo.NewWebhookStorage,
+		newOrganizationStorage:     mongo.NewOrganizationStorage,
+		newSCIMTokenStorage:        mongo.NewSCIMTokenStorage,
+		newPolicyStorage:           mongo.NewPolicyStorage,
+		newRoleStorage:             mongo.NewRoleStorage,
 	}
 	return &c, nil
 }
@@ -26,6 +36,16 @@ type DatabaseComposer struct {
 	newTokenStorage            func(*mongo.DB) (model.TokenStorage, error)
 	newTokenBlacklist          func(*mongo.DB) (model.TokenBlacklist, error)
 	newVerificationCodeStorage func(*mongo.DB) (model.VerificationCodeStorage, error)
+	newInviteStorage           func(*mongo.DB) (model.InviteStorage, error)
+	newUserSessionStorage      func(*mongo.DB) (model.UserSessionStorage, error)
+	newAdminStorage            func(*mongo.DB) (model.AdminStorage, error)
+	newAuditStorage            func(*mongo.DB) (model.AuditStorage, error)
+	newAuthEventStorage        func(*mongo.DB) (model.AuthEventStorage, error)
+	newWebhookStorage          func(*mongo.DB) (model.WebhookStorage, error)
+	newOrganizationStorage     func(*mongo.DB) (model.OrganizationStorage, error)
+	newSCIMTokenStorage        func(*mongo.DB) (model.SCIMTokenStorage, error)
+	newPolicyStorage           func(*mongo.DB) (model.PolicyStorage, error)
+	newRoleStorage             func(*mongo.DB) (model.RoleStorage, error)
 }
 
 // Compose composes all services with MongoDB support.
@@ -35,40 +55,100 @@ func (dc *DatabaseComposer) Compose() (
 	model.TokenStorage,
 	model.TokenBlacklist,
 	model.VerificationCodeStorage,
+	model.InviteStorage,
+	model.UserSessionStorage,
+	model.AdminStorage,
+	model.AuditStorage,
+	model.AuthEventStorage,
+	model.WebhookStorage,
+	model.OrganizationStorage,
+	model.SCIMTokenStorage,
+	model.PolicyStorage,
+	model.RoleStorage,
 	error,
 ) {
 	// We assume that all MongoDB-backed storages share the same database name and connection string, so we can pick any of them.
 	db, err := mongo.NewDB(dc.settings.Storage.AppStorage.Endpoint, dc.settings.Storage.AppStorage.Name)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	appStorage, err := dc.newAppStorage(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	userStorage, err := dc.newUserStorage(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	tokenStorage, err := dc.newTokenStorage(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	tokenBlacklist, err := dc.newTokenBlacklist(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	verificationCodeStorage, err := dc.newVerificationCodeStorage(db)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
-	return appStorage, userStorage, tokenStorage, tokenBlacklist, verificationCodeStorage, nil
+	inviteStorage, err := dc.newInviteStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	userSessionStorage, err := dc.newUserSessionStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	adminStorage, err := dc.newAdminStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	auditStorage, err := dc.newAuditStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	authEventStorage, err := dc.newAuthEventStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	webhookStorage, err := dc.newWebhookStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	organizationStorage, err := dc.newOrganizationStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	scimTokenStorage, err := dc.newSCIMTokenStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	policyStorage, err := dc.newPolicyStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	roleStorage, err := dc.newRoleStorage(db)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	return appStorage, userStorage, tokenStorage, tokenBlacklist, verificationCodeStorage, inviteStorage, userSessionStorage, adminStorage, auditStorage, authEventStorage, webhookStorage, organizationStorage, scimTokenStorage, policyStorage, roleStorage, nil
 }
 
 // NewPartialComposer returns new partial composer with MongoDB support.
@@ -107,6 +187,66 @@ func NewPartialComposer(settings model.StorageSettings, options ...func(*Partial
 		dbName = settings.VerificationCodeStorage.Name
 	}
 
+	if settings.InviteStorage.Type == model.DBTypeMongoDB {
+		pc.newInviteStorage = mongo.NewInviteStorage
+		dbEndpoint = settings.InviteStorage.Endpoint
+		dbName = settings.InviteStorage.Name
+	}
+
+	if settings.UserSessionStorage.Type == model.DBTypeMongoDB {
+		pc.newUserSessionStorage = mongo.NewUserSessionStorage
+		dbEndpoint = settings.UserSessionStorage.Endpoint
+		dbName = settings.UserSessionStorage.Name
+	}
+
+	if settings.AdminStorage.Type == model.DBTypeMongoDB {
+		pc.newAdminStorage = mongo.NewAdminStorage
+		dbEndpoint = settings.AdminStorage.Endpoint
+		dbName = settings.AdminStorage.Name
+	}
+
+	if settings.AuditStorage.Type == model.DBTypeMongoDB {
+		pc.newAuditStorage = mongo.NewAuditStorage
+		dbEndpoint = settings.AuditStorage.Endpoint
+		dbName = settings.AuditStorage.Name
+	}
+
+	if settings.AuthEventStorage.Type == model.DBTypeMongoDB {
+		pc.newAuthEventStorage = mongo.NewAuthEventStorage
+		dbEndpoint = settings.AuthEventStorage.Endpoint
+		dbName = settings.AuthEventStorage.Name
+	}
+
+	if settings.WebhookStorage.Type == model.DBTypeMongoDB {
+		pc.newWebhookStorage = mongo.NewWebhookStorage
+		dbEndpoint = settings.WebhookStorage.Endpoint
+		dbName = settings.WebhookStorage.Name
+	}
+
+	if settings.OrganizationStorage.Type == model.DBTypeMongoDB {
+		pc.newOrganizationStorage = mongo.NewOrganizationStorage
+		dbEndpoint = settings.OrganizationStorage.Endpoint
+		dbName = settings.OrganizationStorage.Name
+	}
+
+	if settings.SCIMTokenStorage.Type == model.DBTypeMongoDB {
+		pc.newSCIMTokenStorage = mongo.NewSCIMTokenStorage
+		dbEndpoint = settings.SCIMTokenStorage.Endpoint
+		dbName = settings.SCIMTokenStorage.Name
+	}
+
+	if settings.PolicyStorage.Type == model.DBTypeMongoDB {
+		pc.newPolicyStorage = mongo.NewPolicyStorage
+		dbEndpoint = settings.PolicyStorage.Endpoint
+		dbName = settings.PolicyStorage.Name
+	}
+
+	if settings.RoleStorage.Type == model.DBTypeMongoDB {
+		pc.newRoleStorage = mongo.NewRoleStorage
+		dbEndpoint = settings.RoleStorage.Endpoint
+		dbName = settings.RoleStorage.Name
+	}
+
 	db, err := mongo.NewDB(dbEndpoint, dbName)
 	if err != nil {
 		return nil, err
@@ -129,6 +269,16 @@ type PartialDatabaseComposer struct {
 	newTokenStorage            func(*mongo.DB) (model.TokenStorage, error)
 	newTokenBlacklist          func(*mongo.DB) (model.TokenBlacklist, error)
 	newVerificationCodeStorage func(*mongo.DB) (model.VerificationCodeStorage, error)
+	newInviteStorage           func(*mongo.DB) (model.InviteStorage, error)
+	newUserSessionStorage      func(*mongo.DB) (model.UserSessionStorage, error)
+	newAdminStorage            func(*mongo.DB) (model.AdminStorage, error)
+	newAuditStorage            func(*mongo.DB) (model.AuditStorage, error)
+	newAuthEventStorage        func(*mongo.DB) (model.AuthEventStorage, error)
+	newWebhookStorage          func(*mongo.DB) (model.WebhookStorage, error)
+	newOrganizationStorage     func(*mongo.DB) (model.OrganizationStorage, error)
+	newSCIMTokenStorage        func(*mongo.DB) (model.SCIMTokenStorage, error)
+	newPolicyStorage           func(*mongo.DB) (model.PolicyStorage, error)
+	newRoleStorage             func(*mongo.DB) (model.RoleStorage, error)
 }
 
 // AppStorageComposer returns app storage composer.
@@ -180,3 +330,103 @@ func (pc *PartialDatabaseComposer) VerificationCodeStorageComposer() func() (mod
 	}
 	return nil
 }
+
+// InviteStorageComposer returns invite storage composer.
+func (pc *PartialDatabaseComposer) InviteStorageComposer() func() (model.InviteStorage, error) {
+	if pc.newInviteStorage != nil {
+		return func() (model.InviteStorage, error) {
+			return pc.newInviteStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// UserSessionStorageComposer returns user session storage composer.
+func (pc *PartialDatabaseComposer) UserSessionStorageComposer() func() (model.UserSessionStorage, error) {
+	if pc.newUserSessionStorage != nil {
+		return func() (model.UserSessionStorage, error) {
+			return pc.newUserSessionStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// AdminStorageComposer returns admin storage composer.
+func (pc *PartialDatabaseComposer) AdminStorageComposer() func() (model.AdminStorage, error) {
+	if pc.newAdminStorage != nil {
+		return func() (model.AdminStorage, error) {
+			return pc.newAdminStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// AuditStorageComposer returns audit storage composer.
+func (pc *PartialDatabaseComposer) AuditStorageComposer() func() (model.AuditStorage, error) {
+	if pc.newAuditStorage != nil {
+		return func() (model.AuditStorage, error) {
+			return pc.newAuditStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// AuthEventStorageComposer returns auth event storage composer.
+func (pc *PartialDatabaseComposer) AuthEventStorageComposer() func() (model.AuthEventStorage, error) {
+	if pc.newAuthEventStorage != nil {
+		return func() (model.AuthEventStorage, error) {
+			return pc.newAuthEventStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// WebhookStorageComposer returns webhook storage composer.
+func (pc *PartialDatabaseComposer) WebhookStorageComposer() func() (model.WebhookStorage, error) {
+	if pc.newWebhookStorage != nil {
+		return func() (model.WebhookStorage, error) {
+			return pc.newWebhookStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// OrganizationStorageComposer returns organization storage composer.
+func (pc *PartialDatabaseComposer) OrganizationStorageComposer() func() (model.OrganizationStorage, error) {
+	if pc.newOrganizationStorage != nil {
+		return func() (model.OrganizationStorage, error) {
+			return pc.newOrganizationStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// SCIMTokenStorageComposer returns SCIM token storage composer.
+func (pc *PartialDatabaseComposer) SCIMTokenStorageComposer() func() (model.SCIMTokenStorage, error) {
+	if pc.newSCIMTokenStorage != nil {
+		return func() (model.SCIMTokenStorage, error) {
+			return pc.newSCIMTokenStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// PolicyStorageComposer returns policy storage composer.
+func (pc *PartialDatabaseComposer) PolicyStorageComposer() func() (model.PolicyStorage, error) {
+	if pc.newPolicyStorage != nil {
+		return func() (model.PolicyStorage, error) {
+			return pc.newPolicyStorage(pc.db)
+		}
+	}
+	return nil
+}
+
+// RoleStorageComposer returns role storage composer.
+func (pc *PartialDatabaseComposer) RoleStorageComposer() func() (model.RoleStorage, error) {
+	if pc.newRoleStorage != nil {
+		return func() (model.RoleStorage, error) {
+			return pc.newRoleStorage(pc.db)
+		}
+	}
+	return nil
+}
@@ -19,16 +19,24 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
-const (
-	defaultAdminLogin    = "admin@admin.com"
-	defaultAdminPassword = "password"
-)
-
 const warningMsg = "WARNING! Config file could not be read, so the default server-config.yaml will be used for the server configuration. Note that when using Docker container, changes made to this file won't survive the container restart."
 
 func init() {
-	configFlag := flag.String("config", "", "Path to the file that describes the location of a server configuration file")
-	flag.Parse()
+	// Own flag set keeps the flags of test binaries from failing the init.
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	configFlag := flags.String("config", "", "Path to the file that describes the location of a server configuration file")
+	err := flags.Parse(os.Args[1:])
+	if err == flag.ErrHelp {
+		flags.SetOutput(os.Stderr)
+		flags.PrintDefaults()
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Println("Cannot parse flags: ", err, warningMsg)
+		loadDefaultServerConfiguration(&ServerSettings)
+		return
+	}
 
 	if configFlag == nil || len(*configFlag) == 0 {
 		log.Println("Config file path not specified.")
@@ -93,7 +101,7 @@ func loadConfigFromFile(ic *initialConfig, out *model.ServerSettings) {
 		log.Fatalln("Invalid settings.", err)
 	}
 
-	loadAdminEnvVars(out.AdminAccount)
+	checkAdminEnvVars(out.AdminAccount)
 
 	log.Println("Server configuration loaded from the file.")
 }
@@ -231,21 +239,14 @@ func loadDefaultServerConfiguration(out *model.ServerSettings) {
 	if err := out.Validate(); err != nil {
 		log.Fatalln(err)
 	}
-	loadAdminEnvVars(out.AdminAccount)
+	checkAdminEnvVars(out.AdminAccount)
 	log.Println("Default server configuration loaded.")
 }
 
-func loadAdminEnvVars(vars model.AdminAccountSettings) {
-	if len(os.Getenv(vars.LoginEnvName)) == 0 {
-		if err := os.Setenv(vars.LoginEnvName, defaultAdminLogin); err != nil {
-			log.Fatalf("Could not set default %s: %s\n", vars.LoginEnvName, err)
-		}
-		log.Printf("WARNING! %s not set. Default value will be used.\n", vars.LoginEnvName)
-	}
-	if len(os.Getenv(vars.PasswordEnvName)) == 0 {
-		if err := os.Setenv(vars.PasswordEnvName, defaultAdminPassword); err != nil {
-			log.Fatalf("Could not set default %s: %s\n", vars.PasswordEnvName, err)
-		}
-		log.Printf("WARNING! %s not set. Default value will be used.\n", vars.PasswordEnvName)
+// checkAdminEnvVars warns if admin credentials are not set, as the owner account cannot be created without them.
+// There are no default credentials, so a fresh install is not open to anyone knowing them.
+func checkAdminEnvVars(vars model.AdminAccountSettings) {
+	if len(os.Getenv(vars.LoginEnvName)) == 0 || len(os.Getenv(vars.PasswordEnvName)) == 0 {
+		log.Printf("WARNING! %s and %s must be set to create the owner admin account.\n", vars.LoginEnvName, vars.PasswordEnvName)
 	}
 }
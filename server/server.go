@@ -10,6 +10,9 @@ import (
 	configStoreEtcd "github.com/madappgang/identifo/configuration/storage/etcd"
 	configStoreFile "github.com/madappgang/identifo/configuration/storage/file"
 	configStoreS3 "github.com/madappgang/identifo/configuration/storage/s3"
+	authEventsHTTP "github.com/madappgang/identifo/external_services/auth_events/httpsink"
+	authEventsStdout "github.com/madappgang/identifo/external_services/auth_events/stdout"
+	authhooks "github.com/madappgang/identifo/external_services/auth_hooks"
 	"github.com/madappgang/identifo/external_services/mail/mailgun"
 	emailMock "github.com/madappgang/identifo/external_services/mail/mock"
 	"github.com/madappgang/identifo/external_services/mail/ses"
@@ -17,8 +20,17 @@ import (
 	"github.com/madappgang/identifo/external_services/sms/nexmo"
 	"github.com/madappgang/identifo/external_services/sms/routemobile"
 	"github.com/madappgang/identifo/external_services/sms/twilio"
+	"github.com/madappgang/identifo/external_services/webhooks"
+	"github.com/madappgang/identifo/health"
+	"github.com/madappgang/identifo/identity_providers/apple"
+	"github.com/madappgang/identifo/identity_providers/facebook"
+	"github.com/madappgang/identifo/identity_providers/github"
+	"github.com/madappgang/identifo/identity_providers/google"
+	"github.com/madappgang/identifo/identity_providers/oidc"
 	ijwt "github.com/madappgang/identifo/jwt"
 	jwtService "github.com/madappgang/identifo/jwt/service"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/metrics"
 	"github.com/madappgang/identifo/model"
 	"github.com/madappgang/identifo/server/utils/originchecker"
 	dynamodb "github.com/madappgang/identifo/sessions/dynamodb"
@@ -27,10 +39,12 @@ import (
 	staticStoreDynamo "github.com/madappgang/identifo/static/storage/dynamodb"
 	staticStoreLocal "github.com/madappgang/identifo/static/storage/local"
 	staticStoreS3 "github.com/madappgang/identifo/static/storage/s3"
+	"github.com/madappgang/identifo/tracing"
 	"github.com/madappgang/identifo/web"
 	"github.com/madappgang/identifo/web/admin"
 	"github.com/madappgang/identifo/web/api"
 	"github.com/madappgang/identifo/web/html"
+	"github.com/madappgang/identifo/web/scim"
 )
 
 // ServerSettings are server settings.
@@ -38,7 +52,23 @@ var ServerSettings model.ServerSettings
 
 // NewServer creates backend service.
 func NewServer(settings model.ServerSettings, db DatabaseComposer, configurationStorage model.ConfigurationStorage, cors *model.CorsOptions, options ...func(*Server) error) (model.Server, error) {
-	var err error
+	return newServer(settings, nil, db, configurationStorage, cors, options...)
+}
+
+// newServer creates backend service of the tenant, or of the default tenant if tenant is nil.
+func newServer(settings model.ServerSettings, tenant *model.TenantSettings, db DatabaseComposer, configurationStorage model.ConfigurationStorage, cors *model.CorsOptions, options ...func(*Server) error) (*Server, error) {
+	logger, err := initLogger(settings.Logger)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		// Storages and services of all tenants log with the default logger, and share the tracer.
+		logging.SetDefault(logger)
+		tracing.Setup(settings.Tracing)
+	} else {
+		logger = logger.With("tenant", tenant.ID)
+	}
+
 	if configurationStorage == nil {
 		configurationStorage, err = InitConfigurationStorage(settings.ConfigurationStorage, settings.StaticFilesStorage.ServerConfigPath)
 		if err != nil {
@@ -46,12 +76,14 @@ func NewServer(settings model.ServerSettings, db DatabaseComposer, configuration
 		}
 	}
 
-	appStorage, userStorage, tokenStorage, tokenBlacklist, verificationCodeStorage, err := db.Compose()
+	appStorage, userStorage, tokenStorage, tokenBlacklist, verificationCodeStorage, inviteStorage, userSessionStorage, adminStorage, auditStorage, authEventStorage, webhookStorage, organizationStorage, scimTokenStorage, policyStorage, roleStorage, err := db.Compose()
 	if err != nil {
 		return nil, err
 	}
 
-	tokenService, err := initTokenService(settings.General, configurationStorage, tokenStorage, appStorage, userStorage)
+	authHookService := authhooks.NewAuthHookService(settings.ExternalServices.AuthHooks)
+
+	tokenService, err := initTokenService(settings.General, configurationStorage, tokenStorage, appStorage, userStorage, organizationStorage, roleStorage, authHookService)
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +99,16 @@ func NewServer(settings model.ServerSettings, db DatabaseComposer, configuration
 		tokenStorage:            tokenStorage,
 		tokenBlacklist:          tokenBlacklist,
 		verificationCodeStorage: verificationCodeStorage,
+		inviteStorage:           inviteStorage,
+		userSessionStorage:      userSessionStorage,
+		adminStorage:            adminStorage,
+		auditStorage:            auditStorage,
+		authEventStorage:        authEventStorage,
+		webhookStorage:          webhookStorage,
+		organizationStorage:     organizationStorage,
+		scimTokenStorage:        scimTokenStorage,
+		policyStorage:           policyStorage,
+		roleStorage:             roleStorage,
 		configurationStorage:    configurationStorage,
 		staticFilesStorage:      staticFilesStorage,
 	}
@@ -76,20 +118,36 @@ func NewServer(settings model.ServerSettings, db DatabaseComposer, configuration
 		return nil, err
 	}
 	sessionService := model.NewSessionManager(settings.SessionStorage.SessionDuration, sessionStorage)
+	userSessionService := model.NewUserSessionManager(userSessionStorage, tokenStorage, tokenBlacklist)
+
+	authEventSink, err := initAuthEventSink(settings.ExternalServices.AuthEventSink)
+	if err != nil {
+		return nil, err
+	}
+	webhookDispatcher := webhooks.NewDispatcher(webhookStorage, userStorage)
+	s.webhookService = webhookDispatcher
+	authEventService := model.NewAuthEventRecorder(authEventStorage, authEventSink, webhookDispatcher, metrics.AuthEventSink())
+	s.authEventService = authEventService
 
 	ms, err := initEmailService(settings.ExternalServices.EmailService, staticFilesStorage)
 	if err != nil {
 		return nil, err
 	}
+	ms = metrics.InstrumentEmailService(ms, string(settings.ExternalServices.EmailService.Type))
+	ms = tracing.InstrumentEmailService(ms, string(settings.ExternalServices.EmailService.Type))
 
 	sms, err := initSMSService(settings.ExternalServices.SMSService)
 	if err != nil {
 		return nil, err
 	}
+	sms = metrics.InstrumentSMSService(sms, string(settings.ExternalServices.SMSService.Type))
+	sms = tracing.InstrumentSMSService(sms, string(settings.ExternalServices.SMSService.Type))
 
-	// env variable can rewrite host option
+	federatedProviders := initFederatedProviders()
+
+	// env variable can rewrite host option of the default tenant
 	hostName := os.Getenv("HOST_NAME")
-	if len(hostName) == 0 {
+	if len(hostName) == 0 || tenant != nil {
 		hostName = settings.General.Host
 	}
 
@@ -104,11 +162,49 @@ func NewServer(settings model.ServerSettings, db DatabaseComposer, configuration
 		originChecker.AddRawURLs(a.RedirectURLs())
 	}
 
+	webRouterSettings := []func(*html.Router) error{
+		html.HostOption(hostName),
+		html.CorsOption(cors),
+		html.SupportedLoginWaysOption(settings.Login.LoginWith),
+		html.FederatedProvidersOption(federatedProviders),
+		html.UserAttributesOption(settings.UserAttributes),
+	}
+	if tenant != nil && len(tenant.PathPrefix) > 0 {
+		webRouterSettings = append(webRouterSettings, html.PathPrefixOptions(tenant.PathPrefix+"/web"))
+	}
+
+	scimRouterSettings := []func(*scim.Router) error{
+		scim.HostOption(hostName),
+	}
+	if tenant != nil && len(tenant.PathPrefix) > 0 {
+		scimRouterSettings = append(scimRouterSettings, scim.PathPrefixOptions(tenant.PathPrefix+"/scim/v2"))
+	}
+
+	trustedProxies, err := settings.General.TrustedProxyNetworks()
+	if err != nil {
+		return nil, err
+	}
+
 	routerSettings := web.RouterSetting{
+		Logger:                  logger,
 		AppStorage:              appStorage,
 		UserStorage:             userStorage,
 		TokenStorage:            tokenStorage,
 		VerificationCodeStorage: verificationCodeStorage,
+		InviteStorage:           inviteStorage,
+		UserSessionStorage:      userSessionStorage,
+		AdminStorage:            adminStorage,
+		AuditStorage:            auditStorage,
+		AuthEventStorage:        authEventStorage,
+		WebhookStorage:          webhookStorage,
+		OrganizationStorage:     organizationStorage,
+		SCIMTokenStorage:        scimTokenStorage,
+		PolicyStorage:           policyStorage,
+		RoleStorage:             roleStorage,
+		UserSessionService:      userSessionService,
+		AuthEventService:        authEventService,
+		WebhookService:          webhookDispatcher,
+		AuthHookService:         authHookService,
 		TokenService:            tokenService,
 		TokenBlacklist:          tokenBlacklist,
 		SessionService:          sessionService,
@@ -116,16 +212,19 @@ func NewServer(settings model.ServerSettings, db DatabaseComposer, configuration
 		ConfigurationStorage:    configurationStorage,
 		StaticFilesStorage:      staticFilesStorage,
 		ServeAdminPanel:         settings.StaticFilesStorage.ServeAdminPanel,
+		Metrics:                 settings.Metrics,
+		TrustedProxies:          trustedProxies,
+		HealthChecker:           initHealthChecker(settings, logger, &s, sessionStorage),
 		SMSService:              sms,
 		EmailService:            ms,
-		WebRouterSettings: []func(*html.Router) error{
-			html.HostOption(hostName),
-			html.CorsOption(cors),
-		},
+		WebRouterSettings:       webRouterSettings,
+		SCIMRouterSettings:      scimRouterSettings,
 		APIRouterSettings: []func(*api.Router) error{
 			api.HostOption(hostName),
 			api.SupportedLoginWaysOption(settings.Login.LoginWith),
 			api.TFATypeOption(settings.Login.TFAType),
+			api.FederatedProvidersOption(federatedProviders),
+			api.UserAttributesOption(settings.UserAttributes),
 			api.CorsOption(cors, originChecker),
 		},
 		AdminRouterSettings: []func(*admin.Router) error{
@@ -133,6 +232,7 @@ func NewServer(settings model.ServerSettings, db DatabaseComposer, configuration
 			admin.ServerConfigPathOption(settings.StaticFilesStorage.ServerConfigPath),
 			admin.ServerSettingsOption(&settings),
 			admin.CorsOption(cors, originChecker),
+			admin.UserAttributesOption(settings.UserAttributes),
 		},
 	}
 
@@ -147,6 +247,8 @@ func NewServer(settings model.ServerSettings, db DatabaseComposer, configuration
 			return nil, err
 		}
 	}
+
+	s.webhookService.Start()
 	return &s, nil
 }
 
@@ -160,6 +262,18 @@ type Server struct {
 	tokenBlacklist          model.TokenBlacklist
 	staticFilesStorage      model.StaticFilesStorage
 	verificationCodeStorage model.VerificationCodeStorage
+	inviteStorage           model.InviteStorage
+	userSessionStorage      model.UserSessionStorage
+	adminStorage            model.AdminStorage
+	auditStorage            model.AuditStorage
+	authEventStorage        model.AuthEventStorage
+	webhookStorage          model.WebhookStorage
+	organizationStorage     model.OrganizationStorage
+	scimTokenStorage        model.SCIMTokenStorage
+	policyStorage           model.PolicyStorage
+	roleStorage             model.RoleStorage
+	webhookService          model.WebhookService
+	authEventService        model.AuthEventService
 }
 
 // Router returns server's main router.
@@ -196,6 +310,56 @@ func (s *Server) VerificationCodeStorage() model.VerificationCodeStorage {
 	return s.verificationCodeStorage
 }
 
+// InviteStorage returns server's invite storage.
+func (s *Server) InviteStorage() model.InviteStorage {
+	return s.inviteStorage
+}
+
+// UserSessionStorage returns server's user session storage.
+func (s *Server) UserSessionStorage() model.UserSessionStorage {
+	return s.userSessionStorage
+}
+
+// AdminStorage returns server's admin storage.
+func (s *Server) AdminStorage() model.AdminStorage {
+	return s.adminStorage
+}
+
+// AuditStorage returns server's audit storage.
+func (s *Server) AuditStorage() model.AuditStorage {
+	return s.auditStorage
+}
+
+// AuthEventStorage returns server's auth event storage.
+func (s *Server) AuthEventStorage() model.AuthEventStorage {
+	return s.authEventStorage
+}
+
+// WebhookStorage returns server's webhook storage.
+func (s *Server) WebhookStorage() model.WebhookStorage {
+	return s.webhookStorage
+}
+
+// OrganizationStorage returns server's organization storage.
+func (s *Server) OrganizationStorage() model.OrganizationStorage {
+	return s.organizationStorage
+}
+
+// SCIMTokenStorage returns server's SCIM token storage.
+func (s *Server) SCIMTokenStorage() model.SCIMTokenStorage {
+	return s.scimTokenStorage
+}
+
+// PolicyStorage returns server's policy storage.
+func (s *Server) PolicyStorage() model.PolicyStorage {
+	return s.policyStorage
+}
+
+// RoleStorage returns server's role storage.
+func (s *Server) RoleStorage() model.RoleStorage {
+	return s.roleStorage
+}
+
 // ConfigurationStorage returns server's configuration storage.
 func (s *Server) ConfigurationStorage() model.ConfigurationStorage {
 	return s.configurationStorage
@@ -206,13 +370,25 @@ func (s *Server) StaticFilesStorage() model.StaticFilesStorage {
 	return s.staticFilesStorage
 }
 
-// Close closes all database connections.
+// Close sends queued auth events, stops webhook delivery and closes all database connections.
 func (s *Server) Close() {
+	s.authEventService.Close()
+	s.webhookService.Stop()
 	s.AppStorage().Close()
 	s.UserStorage().Close()
 	s.TokenStorage().Close()
 	s.TokenBlacklist().Close()
 	s.VerificationCodeStorage().Close()
+	s.InviteStorage().Close()
+	s.UserSessionStorage().Close()
+	s.AdminStorage().Close()
+	s.AuditStorage().Close()
+	s.AuthEventStorage().Close()
+	s.WebhookStorage().Close()
+	s.OrganizationStorage().Close()
+	s.SCIMTokenStorage().Close()
+	s.PolicyStorage().Close()
+	s.RoleStorage().Close()
 	s.StaticFilesStorage().Close()
 }
 
@@ -229,7 +405,7 @@ func InitConfigurationStorage(settings model.ConfigurationStorageSettings, serve
 	return nil, fmt.Errorf("Configuration storage of type '%s' is not supported", settings.Type)
 }
 
-func initTokenService(generalSettings model.GeneralServerSettings, configStorage model.ConfigurationStorage, tokenStorage model.TokenStorage, appStorage model.AppStorage, userStorage model.UserStorage) (jwtService.TokenService, error) {
+func initTokenService(generalSettings model.GeneralServerSettings, configStorage model.ConfigurationStorage, tokenStorage model.TokenStorage, appStorage model.AppStorage, userStorage model.UserStorage, orgStorage model.OrganizationStorage, roleStorage model.RoleStorage, authHooks model.AuthHookService) (jwtService.TokenService, error) {
 	tokenServiceAlg, ok := ijwt.StrToTokenSignAlg[generalSettings.Algorithm]
 	if !ok {
 		return nil, fmt.Errorf("Unknown token service algorithm %s", generalSettings.Algorithm)
@@ -246,6 +422,9 @@ func initTokenService(generalSettings model.GeneralServerSettings, configStorage
 		tokenStorage,
 		appStorage,
 		userStorage,
+		jwtService.AuthHooksOption(authHooks),
+		jwtService.OrganizationsOption(orgStorage),
+		jwtService.RolesOption(roleStorage),
 	)
 	return tokenService, err
 }
@@ -292,6 +471,83 @@ func initSMSService(settings model.SMSServiceSettings) (model.SMSService, error)
 	return nil, fmt.Errorf("SMS service of type '%s' is not supported", settings.Type)
 }
 
+// initHealthChecker adds checks of the storages, session storage, configuration storage and keys of the server.
+func initHealthChecker(settings model.ServerSettings, logger *logging.Logger, s *Server, sessionStorage model.SessionStorage) *health.Checker {
+	checker := health.NewChecker(settings.Health, logger)
+	checker.AddStorage("appStorage", s.appStorage)
+	checker.AddStorage("userStorage", s.userStorage)
+	checker.AddStorage("tokenStorage", s.tokenStorage)
+	checker.AddStorage("tokenBlacklist", s.tokenBlacklist)
+	checker.AddStorage("verificationCodeStorage", s.verificationCodeStorage)
+	checker.AddStorage("inviteStorage", s.inviteStorage)
+	checker.AddStorage("userSessionStorage", s.userSessionStorage)
+	checker.AddStorage("adminStorage", s.adminStorage)
+	checker.AddStorage("auditStorage", s.auditStorage)
+	checker.AddStorage("authEventStorage", s.authEventStorage)
+	checker.AddStorage("webhookStorage", s.webhookStorage)
+	checker.AddStorage("organizationStorage", s.organizationStorage)
+	checker.AddStorage("scimTokenStorage", s.scimTokenStorage)
+	checker.AddStorage("policyStorage", s.policyStorage)
+	checker.AddStorage("roleStorage", s.roleStorage)
+	checker.AddStorage("sessionStorage", sessionStorage)
+
+	configurationStorage := s.configurationStorage
+	checker.Add("configurationStorage", func() error {
+		// Settings are loaded into a copy, the running server keeps its own.
+		loaded := model.ServerSettings{ConfigurationStorage: settings.ConfigurationStorage}
+		return configurationStorage.LoadServerSettings(&loaded)
+	})
+	checker.Add("keys", func() error {
+		alg, ok := ijwt.StrToTokenSignAlg[settings.General.Algorithm]
+		if !ok {
+			return fmt.Errorf("Unknown token service algorithm %s", settings.General.Algorithm)
+		}
+		keys, err := configurationStorage.LoadKeys(alg)
+		if err != nil {
+			return err
+		}
+		if keys == nil || keys.Public == nil || keys.Private == nil {
+			return errors.New("Keys are missing")
+		}
+		return nil
+	})
+	return checker
+}
+
+func initLogger(settings model.LoggerSettings) (*logging.Logger, error) {
+	level, err := logging.ParseLevel(settings.Level)
+	if err != nil {
+		return nil, err
+	}
+	return logging.New(os.Stdout, level, settings.DebugSampleRate), nil
+}
+
+// initAuthEventSink returns nil sink when events are only kept in the storage.
+func initAuthEventSink(settings model.AuthEventSinkSettings) (model.AuthEventSink, error) {
+	switch settings.Type {
+	case "", model.AuthEventSinkNone:
+		return nil, nil
+	case model.AuthEventSinkLog:
+		return authEventsStdout.NewAuthEventSink(), nil
+	case model.AuthEventSinkHTTP:
+		return authEventsHTTP.NewAuthEventSink(settings), nil
+	}
+	return nil, fmt.Errorf("Auth event sink of type '%s' is not supported", settings.Type)
+}
+
+func initFederatedProviders() *model.FederatedProviderRegistry {
+	registry := model.NewFederatedProviderRegistry()
+	register := func(name model.FederatedIdentityProvider, provider model.FederatedIdentityVerifier) {
+		registry.Register(name, tracing.InstrumentFederatedProvider(provider, name))
+	}
+	register(model.FacebookIDProvider, facebook.NewProvider())
+	register(model.AppleIDProvider, apple.NewProvider())
+	register(model.GoogleIDProvider, google.NewProvider())
+	register(model.GitHubIDProvider, github.NewProvider())
+	register(model.OIDCIDProvider, oidc.NewProvider())
+	return registry
+}
+
 func initEmailService(ess model.EmailServiceSettings, sfs model.StaticFilesStorage) (model.EmailService, error) {
 	tpltr, err := model.NewEmailTemplater(sfs)
 	if err != nil {
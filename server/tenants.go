@@ -0,0 +1,107 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	configStoreTenant "github.com/madappgang/identifo/configuration/storage/tenant"
+	"github.com/madappgang/identifo/model"
+)
+
+// NewTenantServer creates backend service of the tenant.
+// Settings are expected to be derived from the default ones with ServerSettings.TenantServerSettings.
+func NewTenantServer(settings model.ServerSettings, tenant model.TenantSettings, db DatabaseComposer, configurationStorage model.ConfigurationStorage, cors *model.CorsOptions, options ...func(*Server) error) (model.Server, error) {
+	tenantConfigurationStorage, err := configStoreTenant.NewConfigurationStorage(configurationStorage, tenant.KeyStorage)
+	if err != nil {
+		return nil, err
+	}
+	return newServer(settings, &tenant, db, tenantConfigurationStorage, cors, options...)
+}
+
+// MultiTenantServer routes requests to tenant servers by host name and path prefix.
+// Requests which match no tenant are served by the default tenant server.
+type MultiTenantServer struct {
+	model.Server
+	tenants []tenantServer
+}
+
+type tenantServer struct {
+	settings model.TenantSettings
+	server   model.Server
+	handler  http.Handler
+}
+
+// NewMultiTenantServer creates server which serves the tenants next to the default one.
+// Servers are keyed by tenant ID.
+func NewMultiTenantServer(defaultServer model.Server, tenants []model.TenantSettings, servers map[string]model.Server) *MultiTenantServer {
+	s := &MultiTenantServer{Server: defaultServer}
+	for _, t := range tenants {
+		srv, ok := servers[t.ID]
+		if !ok {
+			continue
+		}
+
+		var handler http.Handler = srv.Router()
+		if len(t.PathPrefix) > 0 {
+			handler = http.StripPrefix(t.PathPrefix, handler)
+		}
+		s.tenants = append(s.tenants, tenantServer{settings: t, server: srv, handler: handler})
+	}
+
+	// Longer path prefixes are more specific, so they go first.
+	sort.SliceStable(s.tenants, func(i, j int) bool {
+		return len(s.tenants[i].settings.PathPrefix) > len(s.tenants[j].settings.PathPrefix)
+	})
+	return s
+}
+
+// Router returns the server itself, as it routes requests to the tenants.
+func (s *MultiTenantServer) Router() model.Router {
+	return s
+}
+
+// ServeHTTP passes the request to the server of the matching tenant.
+func (s *MultiTenantServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if t, ok := s.tenant(r); ok {
+		t.handler.ServeHTTP(w, r)
+		return
+	}
+	s.Server.Router().ServeHTTP(w, r)
+}
+
+// Close closes all tenant servers and the default one.
+func (s *MultiTenantServer) Close() {
+	for _, t := range s.tenants {
+		t.server.Close()
+	}
+	s.Server.Close()
+}
+
+func (s *MultiTenantServer) tenant(r *http.Request) (tenantServer, bool) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	for _, t := range s.tenants {
+		if len(t.settings.Hosts) > 0 && !containsHost(t.settings.Hosts, host) {
+			continue
+		}
+		if len(t.settings.PathPrefix) > 0 && !strings.HasPrefix(r.URL.Path, t.settings.PathPrefix+"/") {
+			continue
+		}
+		return t, true
+	}
+	return tenantServer{}, false
+}
+
+func containsHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/madappgang/identifo/model"
+)
+
+// namedServer answers every request with its name and the path it got.
+type namedServer struct {
+	model.Server
+	name   string
+	closed bool
+}
+
+func (s *namedServer) Router() model.Router {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(s.name + " " + r.URL.Path))
+	})
+}
+
+func (s *namedServer) Close() {
+	s.closed = true
+}
+
+func TestMultiTenantServerServeHTTP(t *testing.T) {
+	tenants := []model.TenantSettings{
+		{ID: "host", Hosts: []string{"brand.example.com"}},
+		{ID: "prefix", PathPrefix: "/brand"},
+		{ID: "prefix-long", PathPrefix: "/brand/eu"},
+		{ID: "host-prefix", Hosts: []string{"other.example.com"}, PathPrefix: "/app"},
+		{ID: "missing", Hosts: []string{"missing.example.com"}},
+	}
+	servers := map[string]model.Server{
+		"host":        &namedServer{name: "host"},
+		"prefix":      &namedServer{name: "prefix"},
+		"prefix-long": &namedServer{name: "prefix-long"},
+		"host-prefix": &namedServer{name: "host-prefix"},
+	}
+	s := NewMultiTenantServer(&namedServer{name: "default"}, tenants, servers)
+
+	tests := []struct {
+		name   string
+		host   string
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"host match", "brand.example.com", "/auth/login", "host /auth/login", true},
+		{"host match is case insensitive", "Brand.Example.COM", "/auth/login", "host /auth/login", true},
+		{"host with port", "brand.example.com:8081", "/auth/login", "host /auth/login", true},
+		{"path prefix", "identifo.example.com", "/brand/auth/login", "prefix /auth/login", true},
+		{"longer path prefix goes first", "identifo.example.com", "/brand/eu/auth/login", "prefix-long /auth/login", true},
+		{"path prefix matches whole segment", "identifo.example.com", "/brandnew/auth/login", "default /brandnew/auth/login", false},
+		{"host and path prefix", "other.example.com", "/app/auth/login", "host-prefix /auth/login", true},
+		{"host without path prefix", "other.example.com", "/auth/login", "default /auth/login", false},
+		{"tenant without server", "missing.example.com", "/auth/login", "default /auth/login", false},
+		{"default fallback", "identifo.example.com", "/auth/login", "default /auth/login", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "http://"+tt.host+tt.path, nil)
+
+			if _, ok := s.tenant(r); ok != tt.wantOK {
+				t.Errorf("tenant() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			rr := httptest.NewRecorder()
+			s.ServeHTTP(rr, r)
+			if got := rr.Body.String(); got != tt.want {
+				t.Errorf("ServeHTTP() served %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiTenantServerClose(t *testing.T) {
+	defaultServer := &namedServer{name: "default"}
+	tenantServer := &namedServer{name: "tenant"}
+	s := NewMultiTenantServer(defaultServer, []model.TenantSettings{{ID: "tenant", PathPrefix: "/tenant"}}, map[string]model.Server{"tenant": tenantServer})
+
+	s.Close()
+	if !defaultServer.closed || !tenantServer.closed {
+		t.Errorf("closed default = %v, tenant = %v, want both closed", defaultServer.closed, tenantServer.closed)
+	}
+}
+
+func TestContainsHost(t *testing.T) {
+	hosts := []string{"a.example.com", "B.example.com"}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"a.example.com", true},
+		{"b.example.com", true},
+		{"c.example.com", false},
+		{"example.com", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := containsHost(hosts, tt.host); got != tt.want {
+			t.Errorf("containsHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package sessions
 import (
 	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -11,6 +10,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 )
 
@@ -35,7 +35,7 @@ func NewSessionStorage(settings model.SessionStorageSettings) (model.SessionStor
 
 	sess, err := session.NewSession(config)
 	if err != nil {
-		log.Println(err)
+		logging.Error(err)
 		return nil, err
 	}
 
@@ -122,11 +122,19 @@ func (dss *DynamoDBSessionStorage) ProlongSession(id string, newDuration model.S
 	return dss.InsertSession(session)
 }
 
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (dss *DynamoDBSessionStorage) TestDatabaseConnection() error {
+	_, err := dss.db.DescribeTable(&dynamodb.DescribeTableInput{
+		TableName: aws.String(adminSessionsTableName),
+	})
+	return err
+}
+
 // ensureTable ensures that admin sessions table exists in database.
 func (dss *DynamoDBSessionStorage) ensureTable() error {
 	exists, err := dss.isTableExists(adminSessionsTableName)
 	if err != nil {
-		log.Println("Error checking admins sessions table existence:", err)
+		logging.Error("Error checking admins sessions table existence:", err)
 		return err
 	}
 	if exists {
@@ -169,9 +177,9 @@ func (dss *DynamoDBSessionStorage) ensureTable() error {
 			// Then table must be in creating status. Let's give it some time.
 			for i := 0; i < 5; i++ {
 				time.Sleep(5 * time.Second)
-				log.Println("Retry setting expiration time...")
+				logging.Info("Retry setting expiration time...")
 				if _, err = dss.db.UpdateTimeToLive(ttlInput); err == nil {
-					log.Println("Expiration time successfully set")
+					logging.Info("Expiration time successfully set")
 					break
 				}
 			}
@@ -192,7 +200,7 @@ func (dss *DynamoDBSessionStorage) isTableExists(table string) (bool, error) {
 		return false, nil
 	}
 	if err != nil {
-		log.Println(err)
+		logging.Error(err)
 		return false, err
 	}
 
@@ -1,12 +1,15 @@
 package sessions
 
 import (
+	"context"
 	"encoding/json"
-	"log"
 	"time"
 
 	"github.com/go-redis/redis"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/metrics"
 	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/tracing"
 )
 
 const (
@@ -40,6 +43,7 @@ func NewSessionStorage(settings model.SessionStorageSettings) (model.SessionStor
 		Password: password,
 		DB:       db,
 	})
+	client.WrapProcess(observeCommand)
 
 	if _, err := client.Ping().Result(); err != nil {
 		return nil, err
@@ -48,6 +52,22 @@ func NewSessionStorage(settings model.SessionStorageSettings) (model.SessionStor
 	return &RedisSessionStorage{client: client}, nil
 }
 
+// observeCommand reports latency of the commands to metrics, and records their spans. Missing key is not a failure.
+func observeCommand(process func(redis.Cmder) error) func(redis.Cmder) error {
+	return func(cmd redis.Cmder) error {
+		span := tracing.StartStorageSpan(context.Background(), "redis", cmd.Name())
+		start := time.Now()
+		err := process(cmd)
+		failure := err
+		if err == redis.Nil {
+			failure = nil
+		}
+		metrics.ObserveStorage("redis", cmd.Name(), time.Since(start), failure)
+		tracing.End(span, failure)
+		return err
+	}
+}
+
 // GetSession fetches session by ID.
 func (r *RedisSessionStorage) GetSession(id string) (model.Session, error) {
 	var session model.Session
@@ -76,7 +96,7 @@ func (r *RedisSessionStorage) InsertSession(session model.Session) error {
 func (r *RedisSessionStorage) DeleteSession(id string) error {
 	count, err := r.client.Del(id).Result()
 	if count == 0 {
-		log.Println("Tried to delete nonexistent session:", id)
+		logging.Warn("Tried to delete nonexistent session:", id)
 	}
 
 	return err
@@ -99,3 +119,8 @@ func (r *RedisSessionStorage) ProlongSession(id string, newDuration model.Sessio
 	err = r.client.SetXX(session.ID, bs, newDuration.Duration).Err()
 	return err
 }
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (r *RedisSessionStorage) TestDatabaseConnection() error {
+	return r.client.Ping().Err()
+}
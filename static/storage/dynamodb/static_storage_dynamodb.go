@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"html/template"
 	"io"
-	"log"
 	"mime"
 	"net/http"
 	"path"
@@ -13,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	staticStoreLocal "github.com/madappgang/identifo/static/storage/local"
 	idynamodb "github.com/madappgang/identifo/storage/dynamodb"
@@ -51,7 +51,7 @@ func (sfs *StaticFilesStorage) GetFile(name string) ([]byte, error) {
 		return file, nil
 	}
 
-	log.Printf("Error getting %s from DynamoDB: %s. Using local storage.\n", name, err)
+	logging.Errorf("Error getting %s from DynamoDB: %s. Using local storage.\n", name, err)
 	return sfs.localStorage.GetFile(name)
 }
 
@@ -139,7 +139,7 @@ func (sfs *StaticFilesStorage) GetAppleFile(name string) ([]byte, error) {
 		return nil, nil
 	}
 
-	log.Printf("Error getting %s from DynamoDB: %s. Using local storage.\n", name, err)
+	logging.Errorf("Error getting %s from DynamoDB: %s. Using local storage.\n", name, err)
 	return sfs.localStorage.GetAppleFile(name)
 }
 
@@ -161,7 +161,7 @@ func (sfs *StaticFilesStorage) AssetHandlers() *model.AssetHandlers {
 		if err == nil {
 			w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(name)))
 			if _, err = w.Write(file); err != nil {
-				log.Printf("Error writing body to the response: %s\n", err)
+				logging.Errorf("Error writing body to the response: %s\n", err)
 			}
 			return
 		}
@@ -220,7 +220,7 @@ func (sfs *StaticFilesStorage) ensureTable() error {
 
 // writeError writes an error message to the response and logger.
 func writeError(w http.ResponseWriter, err error, code int, userInfo string) {
-	log.Printf("http error: %s (code=%d)\n", err, code)
+	logging.Default().Logf(logging.StatusLevel(code), "http error: %s (code=%d)", err, code)
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	responseString := `
@@ -241,6 +241,6 @@ func writeError(w http.ResponseWriter, err error, code int, userInfo string) {
 	`
 	w.WriteHeader(code)
 	if _, wrErr := io.WriteString(w, responseString); wrErr != nil {
-		log.Println("Error writing response string:", wrErr)
+		logging.Error("Error writing response string:", wrErr)
 	}
 }
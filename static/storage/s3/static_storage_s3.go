@@ -6,7 +6,6 @@ import (
 	"html/template"
 	"io"
 	"io/ioutil"
-	"log"
 	"mime"
 	"net/http"
 	"path"
@@ -15,6 +14,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	s3Storage "github.com/madappgang/identifo/external_services/storage/s3"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	staticStoreLocal "github.com/madappgang/identifo/static/storage/local"
 )
@@ -51,7 +51,7 @@ func (sfs *StaticFilesStorage) GetFile(name string) ([]byte, error) {
 		return file, nil
 	}
 
-	log.Printf("Error getting %s from S3: %s. Using local storage.\n", name, err)
+	logging.Errorf("Error getting %s from S3: %s. Using local storage.\n", name, err)
 	return sfs.localStorage.GetFile(name)
 }
 
@@ -97,7 +97,7 @@ func (sfs *StaticFilesStorage) UploadFile(name string, contents []byte) error {
 		ContentType:  aws.String(mime.TypeByExtension(path.Ext(name))),
 	})
 	if err == nil {
-		log.Printf("Successfully put %s to S3\n", filepath)
+		logging.Infof("Successfully put %s to S3\n", filepath)
 	}
 	return nil
 }
@@ -130,7 +130,7 @@ func (sfs *StaticFilesStorage) GetAppleFile(filename string) ([]byte, error) {
 		return nil, nil
 	}
 
-	log.Printf("Error getting %s from S3: %s. Using local storage.\n", filename, err)
+	logging.Errorf("Error getting %s from S3: %s. Using local storage.\n", filename, err)
 	return sfs.localStorage.GetAppleFile(filename)
 }
 
@@ -159,7 +159,7 @@ func (sfs *StaticFilesStorage) AssetHandlers() *model.AssetHandlers {
 
 		w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(name)))
 		if _, err = w.Write(file); err != nil {
-			log.Printf("Error writing body to the response: %s\n", err)
+			logging.Errorf("Error writing body to the response: %s\n", err)
 			return
 		}
 	})
@@ -183,7 +183,7 @@ func (sfs *StaticFilesStorage) Close() {}
 
 // writeError writes an error message to the response and logger.
 func writeError(w http.ResponseWriter, err error, code int, userInfo string) {
-	log.Printf("http error: %s (code=%d)\n", err, code)
+	logging.Default().Logf(logging.StatusLevel(code), "http error: %s (code=%d)", err, code)
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	responseString := `
@@ -204,6 +204,6 @@ func writeError(w http.ResponseWriter, err error, code int, userInfo string) {
 	`
 	w.WriteHeader(code)
 	if _, wrErr := io.WriteString(w, responseString); wrErr != nil {
-		log.Println("Error writing response string:", wrErr)
+		logging.Error("Error writing response string:", wrErr)
 	}
 }
@@ -0,0 +1,160 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+const (
+	// AdminBucket is a name for bucket with admin accounts.
+	AdminBucket = "Admins"
+)
+
+// NewAdminStorage creates and inits BoltDB admin storage.
+func NewAdminStorage(db *bolt.DB) (model.AdminStorage, error) {
+	as := &AdminStorage{db: db}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(AdminBucket)); err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return as, nil
+}
+
+// AdminStorage implements admin storage interface.
+type AdminStorage struct {
+	db *bolt.DB
+}
+
+// AddAdmin saves new admin.
+func (as *AdminStorage) AddAdmin(admin model.Admin) (model.Admin, error) {
+	admin.ID = xid.New().String()
+
+	err := update(as.db, func(tx *bolt.Tx) error {
+		if _, err := adminByEmailInTx(tx, admin.Email); err == nil {
+			return model.ErrAdminExists
+		} else if err != model.ErrAdminNotFound {
+			return err
+		}
+		return putAdminInTx(tx, admin)
+	})
+	if err != nil {
+		return model.Admin{}, err
+	}
+	return admin, nil
+}
+
+// AdminByID returns admin by ID.
+func (as *AdminStorage) AdminByID(id string) (model.Admin, error) {
+	var admin model.Admin
+	err := view(as.db, func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(AdminBucket)).Get([]byte(id))
+		if data == nil {
+			return model.ErrAdminNotFound
+		}
+		return json.Unmarshal(data, &admin)
+	})
+	return admin, err
+}
+
+// AdminByEmail returns admin by email.
+func (as *AdminStorage) AdminByEmail(email string) (model.Admin, error) {
+	var admin model.Admin
+	err := view(as.db, func(tx *bolt.Tx) error {
+		var err error
+		admin, err = adminByEmailInTx(tx, email)
+		return err
+	})
+	return admin, err
+}
+
+// FetchAdmins returns all admins, oldest first.
+func (as *AdminStorage) FetchAdmins() ([]model.Admin, error) {
+	admins := []model.Admin{}
+
+	err := view(as.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(AdminBucket)).ForEach(func(k, v []byte) error {
+			var admin model.Admin
+			if err := json.Unmarshal(v, &admin); err != nil {
+				return err
+			}
+			admins = append(admins, admin)
+			return nil
+		})
+	})
+	if err != nil {
+		return []model.Admin{}, err
+	}
+
+	sort.Slice(admins, func(i, j int) bool { return admins[i].CreatedAt < admins[j].CreatedAt })
+	return admins, nil
+}
+
+// UpdateAdmin replaces stored admin.
+func (as *AdminStorage) UpdateAdmin(admin model.Admin) (model.Admin, error) {
+	err := update(as.db, func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(AdminBucket)).Get([]byte(admin.ID)) == nil {
+			return model.ErrAdminNotFound
+		}
+		return putAdminInTx(tx, admin)
+	})
+	if err != nil {
+		return model.Admin{}, err
+	}
+	return admin, nil
+}
+
+// DeleteAdmin deletes admin.
+func (as *AdminStorage) DeleteAdmin(id string) error {
+	return update(as.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(AdminBucket)).Delete([]byte(id))
+	})
+}
+
+// Close closes underlying database.
+func (as *AdminStorage) Close() {
+	if err := as.db.Close(); err != nil {
+		logging.Errorf("Error closing admin storage: %s\n", err)
+	}
+}
+
+func adminByEmailInTx(tx *bolt.Tx, email string) (model.Admin, error) {
+	var admin model.Admin
+	found := false
+
+	err := tx.Bucket([]byte(AdminBucket)).ForEach(func(k, v []byte) error {
+		if err := json.Unmarshal(v, &admin); err != nil {
+			return err
+		}
+		if admin.Email == email {
+			found = true
+			return errStopIteration
+		}
+		return nil
+	})
+	if err != nil && err != errStopIteration {
+		return model.Admin{}, err
+	}
+	if !found {
+		return model.Admin{}, model.ErrAdminNotFound
+	}
+	return admin, nil
+}
+
+func putAdminInTx(tx *bolt.Tx, admin model.Admin) error {
+	data, err := json.Marshal(admin)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket([]byte(AdminBucket)).Put([]byte(admin.ID), data)
+}
@@ -3,10 +3,10 @@ package boltdb
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"strings"
 
 	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	"github.com/rs/xid"
 )
@@ -44,7 +44,7 @@ func (as *AppStorage) NewAppData() model.AppData {
 // AppByID returns app from memory by ID.
 func (as *AppStorage) AppByID(id string) (model.AppData, error) {
 	res := new(AppData)
-	if err := as.db.View(func(tx *bolt.Tx) error {
+	if err := view(as.db, func(tx *bolt.Tx) error {
 		ab := tx.Bucket([]byte(AppBucket))
 		app := ab.Get([]byte(id))
 		if app == nil {
@@ -98,7 +98,7 @@ func (as *AppStorage) addNewApp(app model.AppData) (model.AppData, error) {
 	if len(res.ID()) == 0 {
 		res.appData.ID = xid.New().String()
 	}
-	return res, as.db.Update(func(tx *bolt.Tx) error {
+	return res, update(as.db, func(tx *bolt.Tx) error {
 		data, err := res.Marshal()
 		if err != nil {
 			return err
@@ -132,7 +132,7 @@ func (as *AppStorage) UpdateApp(appID string, newApp model.AppData) (model.AppDa
 		res.appData.ID = appID
 	}
 
-	err := as.db.Update(func(tx *bolt.Tx) error {
+	err := update(as.db, func(tx *bolt.Tx) error {
 		data, err := res.Marshal()
 		if err != nil {
 			return err
@@ -160,7 +160,7 @@ func (as *AppStorage) FetchApps(filterString string, skip, limit int) ([]model.A
 	apps := []model.AppData{}
 	var total int
 
-	err := as.db.View(func(tx *bolt.Tx) error {
+	err := view(as.db, func(tx *bolt.Tx) error {
 		ab := tx.Bucket([]byte(AppBucket))
 
 		if iterErr := ab.ForEach(func(k, v []byte) error {
@@ -191,7 +191,7 @@ func (as *AppStorage) FetchApps(filterString string, skip, limit int) ([]model.A
 
 // DeleteApp deletes app by ID.
 func (as *AppStorage) DeleteApp(id string) error {
-	err := as.db.Update(func(tx *bolt.Tx) error {
+	err := update(as.db, func(tx *bolt.Tx) error {
 		ab := tx.Bucket([]byte(AppBucket))
 		return ab.Delete([]byte(id))
 	})
@@ -200,7 +200,7 @@ func (as *AppStorage) DeleteApp(id string) error {
 
 // TestDatabaseConnection checks whether we can fetch the first document in the applications bucket.
 func (as *AppStorage) TestDatabaseConnection() error {
-	err := as.db.View(func(tx *bolt.Tx) error {
+	err := view(as.db, func(tx *bolt.Tx) error {
 		ab := tx.Bucket([]byte(AppBucket))
 		return ab.ForEach(func(k, v []byte) error {
 			_, err := AppDataFromJSON(v)
@@ -214,7 +214,7 @@ func (as *AppStorage) TestDatabaseConnection() error {
 func (as *AppStorage) ImportJSON(data []byte) error {
 	apd := []appData{}
 	if err := json.Unmarshal(data, &apd); err != nil {
-		log.Println(err)
+		logging.Error(err)
 		return err
 	}
 	for _, a := range apd {
@@ -228,6 +228,6 @@ func (as *AppStorage) ImportJSON(data []byte) error {
 // Close closes underlying database.
 func (as *AppStorage) Close() {
 	if err := as.db.Close(); err != nil {
-		log.Printf("Error closing app storage: %s\n", err)
+		logging.Errorf("Error closing app storage: %s\n", err)
 	}
 }
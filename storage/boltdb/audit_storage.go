@@ -0,0 +1,91 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+const (
+	// AuditBucket is a name for bucket with audit events.
+	AuditBucket = "AuditEvents"
+)
+
+// NewAuditStorage creates and inits BoltDB audit storage.
+func NewAuditStorage(db *bolt.DB) (model.AuditStorage, error) {
+	as := &AuditStorage{db: db}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(AuditBucket)); err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return as, nil
+}
+
+// AuditStorage implements audit storage interface.
+// Events are keyed by xid, which is sortable by creation time.
+type AuditStorage struct {
+	db *bolt.DB
+}
+
+// AddAuditEvent saves new event.
+func (as *AuditStorage) AddAuditEvent(event model.AuditEvent) (model.AuditEvent, error) {
+	event.ID = xid.New().String()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return model.AuditEvent{}, err
+	}
+
+	err = update(as.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(AuditBucket)).Put([]byte(event.ID), data)
+	})
+	if err != nil {
+		return model.AuditEvent{}, err
+	}
+	return event, nil
+}
+
+// FetchAuditEvents returns events matching the filter, newest first.
+func (as *AuditStorage) FetchAuditEvents(filter model.AuditEventFilter, skip, limit int) ([]model.AuditEvent, int, error) {
+	events := []model.AuditEvent{}
+	total := 0
+
+	err := view(as.db, func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(AuditBucket)).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var event model.AuditEvent
+			if err := json.Unmarshal(v, &event); err != nil {
+				return err
+			}
+			if !filter.Matches(event) {
+				continue
+			}
+
+			total++
+			if total > skip && (limit == 0 || len(events) < limit) {
+				events = append(events, event)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return []model.AuditEvent{}, 0, err
+	}
+	return events, total, nil
+}
+
+// Close closes underlying database.
+func (as *AuditStorage) Close() {
+	if err := as.db.Close(); err != nil {
+		logging.Errorf("Error closing audit storage: %s\n", err)
+	}
+}
@@ -0,0 +1,91 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+const (
+	// AuthEventBucket is a name for bucket with auth events.
+	AuthEventBucket = "AuthEvents"
+)
+
+// NewAuthEventStorage creates and inits BoltDB auth event storage.
+func NewAuthEventStorage(db *bolt.DB) (model.AuthEventStorage, error) {
+	aes := &AuthEventStorage{db: db}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(AuthEventBucket)); err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return aes, nil
+}
+
+// AuthEventStorage implements auth event storage interface.
+// Events are keyed by xid, which is sortable by creation time.
+type AuthEventStorage struct {
+	db *bolt.DB
+}
+
+// AddAuthEvent saves new event.
+func (aes *AuthEventStorage) AddAuthEvent(event model.AuthEvent) (model.AuthEvent, error) {
+	event.ID = xid.New().String()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return model.AuthEvent{}, err
+	}
+
+	err = update(aes.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(AuthEventBucket)).Put([]byte(event.ID), data)
+	})
+	if err != nil {
+		return model.AuthEvent{}, err
+	}
+	return event, nil
+}
+
+// FetchAuthEvents returns events matching the filter, newest first.
+func (aes *AuthEventStorage) FetchAuthEvents(filter model.AuthEventFilter, skip, limit int) ([]model.AuthEvent, int, error) {
+	events := []model.AuthEvent{}
+	total := 0
+
+	err := view(aes.db, func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(AuthEventBucket)).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var event model.AuthEvent
+			if err := json.Unmarshal(v, &event); err != nil {
+				return err
+			}
+			if !filter.Matches(event) {
+				continue
+			}
+
+			total++
+			if total > skip && (limit == 0 || len(events) < limit) {
+				events = append(events, event)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return []model.AuthEvent{}, 0, err
+	}
+	return events, total, nil
+}
+
+// Close closes underlying database.
+func (aes *AuthEventStorage) Close() {
+	if err := aes.db.Close(); err != nil {
+		logging.Errorf("Error closing auth event storage: %s\n", err)
+	}
+}
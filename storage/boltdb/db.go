@@ -1,6 +1,13 @@
 package boltdb
 
-import "github.com/boltdb/bolt"
+import (
+	"context"
+	"runtime"
+	"strings"
+
+	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/tracing"
+)
 
 // InitDB opens database.
 func InitDB(file string) (*bolt.DB, error) {
@@ -11,3 +18,42 @@ func InitDB(file string) (*bolt.DB, error) {
 func CloseDB(db *bolt.DB) error {
 	return db.Close()
 }
+
+// view executes read-only transaction, recording its span.
+func view(db *bolt.DB, fn func(*bolt.Tx) error) error {
+	if !tracing.Enabled() {
+		return db.View(fn)
+	}
+	span := tracing.StartStorageSpan(context.Background(), "boltdb", callerOperation())
+	err := db.View(fn)
+	tracing.End(span, err)
+	return err
+}
+
+// update executes read-write transaction, recording its span.
+func update(db *bolt.DB, fn func(*bolt.Tx) error) error {
+	if !tracing.Enabled() {
+		return db.Update(fn)
+	}
+	span := tracing.StartStorageSpan(context.Background(), "boltdb", callerOperation())
+	err := db.Update(fn)
+	tracing.End(span, err)
+	return err
+}
+
+// callerOperation returns name of the storage method calling the transaction helper, like "UserByID".
+func callerOperation() string {
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		return "transaction"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "transaction"
+	}
+	name := fn.Name()
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
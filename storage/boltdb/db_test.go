@@ -0,0 +1,27 @@
+package boltdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+// newTestDB opens database in a temporary file, removed when the test ends.
+func newTestDB(t *testing.T) *bolt.DB {
+	dir, err := ioutil.TempDir("", "identifo-boltdb")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %s", err)
+	}
+	db, err := InitDB(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatalf("Error opening database: %s", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(dir)
+	})
+	return db
+}
@@ -0,0 +1,89 @@
+package boltdb
+
+import (
+	"fmt"
+
+	"github.com/boltdb/bolt"
+)
+
+// testBuckets checks that the database is open and the buckets exist.
+func testBuckets(db *bolt.DB, buckets ...string) error {
+	return view(db, func(tx *bolt.Tx) error {
+		for _, bucket := range buckets {
+			if tx.Bucket([]byte(bucket)) == nil {
+				return fmt.Errorf("Bucket %s does not exist", bucket)
+			}
+		}
+		return nil
+	})
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (us *UserStorage) TestDatabaseConnection() error {
+	return testBuckets(us.db, UserBucket, UserBySocialIDBucket, UserByPhoneNumberBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ts *TokenStorage) TestDatabaseConnection() error {
+	return testBuckets(ts.db, TokenBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (tb *TokenBlacklist) TestDatabaseConnection() error {
+	return testBuckets(tb.db, BlacklistedTokenBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (vcs *VerificationCodeStorage) TestDatabaseConnection() error {
+	return testBuckets(vcs.db, VerificationCodesBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (is *InviteStorage) TestDatabaseConnection() error {
+	return testBuckets(is.db, InviteBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ss *UserSessionStorage) TestDatabaseConnection() error {
+	return testBuckets(ss.db, UserSessionBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (as *AdminStorage) TestDatabaseConnection() error {
+	return testBuckets(as.db, AdminBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (as *AuditStorage) TestDatabaseConnection() error {
+	return testBuckets(as.db, AuditBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (aes *AuthEventStorage) TestDatabaseConnection() error {
+	return testBuckets(aes.db, AuthEventBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ws *WebhookStorage) TestDatabaseConnection() error {
+	return testBuckets(ws.db, WebhookBucket, WebhookDeliveryBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (os *OrganizationStorage) TestDatabaseConnection() error {
+	return testBuckets(os.db, OrganizationBucket, OrganizationMemberBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ss *SCIMTokenStorage) TestDatabaseConnection() error {
+	return testBuckets(ss.db, SCIMTokenBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ps *PolicyStorage) TestDatabaseConnection() error {
+	return testBuckets(ps.db, PolicyRuleBucket)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (rs *RoleStorage) TestDatabaseConnection() error {
+	return testBuckets(rs.db, RoleBucket, UserRolesBucket)
+}
@@ -0,0 +1,153 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+const (
+	// InviteBucket is a name for bucket with invites.
+	InviteBucket = "Invites"
+)
+
+// NewInviteStorage creates and inits BoltDB invite storage.
+func NewInviteStorage(db *bolt.DB) (model.InviteStorage, error) {
+	is := &InviteStorage{db: db}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(InviteBucket)); err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return is, nil
+}
+
+// InviteStorage implements invite storage interface.
+type InviteStorage struct {
+	db *bolt.DB
+}
+
+// AddInvite saves new invite.
+func (is *InviteStorage) AddInvite(invite model.Invite) (model.Invite, error) {
+	invite.ID = xid.New().String()
+
+	data, err := json.Marshal(invite)
+	if err != nil {
+		return model.Invite{}, err
+	}
+
+	err = update(is.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(InviteBucket)).Put([]byte(invite.ID), data)
+	})
+	return invite, err
+}
+
+// InviteByID returns invite by its ID.
+func (is *InviteStorage) InviteByID(id string) (model.Invite, error) {
+	var invite model.Invite
+	err := view(is.db, func(tx *bolt.Tx) error {
+		var err error
+		invite, err = inviteByIDInTx(tx, id)
+		return err
+	})
+	return invite, err
+}
+
+// FetchInvites fetches invites, optionally filtered by app ID and email, newest first.
+func (is *InviteStorage) FetchInvites(appID, email string, skip, limit int) ([]model.Invite, int, error) {
+	invites := []model.Invite{}
+
+	err := view(is.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(InviteBucket)).ForEach(func(k, v []byte) error {
+			var invite model.Invite
+			if err := json.Unmarshal(v, &invite); err != nil {
+				return err
+			}
+			if (appID == "" || invite.AppID == appID) && (email == "" || invite.Email == email) {
+				invites = append(invites, invite)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []model.Invite{}, 0, err
+	}
+
+	sort.Slice(invites, func(i, j int) bool { return invites[i].CreatedAt > invites[j].CreatedAt })
+
+	total := len(invites)
+	if skip > total {
+		skip = total
+	}
+	invites = invites[skip:]
+	if limit != 0 && len(invites) > limit {
+		invites = invites[:limit]
+	}
+	return invites, total, nil
+}
+
+// RevokeInvite marks invite as revoked.
+func (is *InviteStorage) RevokeInvite(id string) error {
+	return update(is.db, func(tx *bolt.Tx) error {
+		invite, err := inviteByIDInTx(tx, id)
+		if err != nil {
+			return err
+		}
+		invite.Revoked = true
+		return putInviteInTx(tx, invite)
+	})
+}
+
+// ConsumeInvite marks valid invite as used by the user.
+func (is *InviteStorage) ConsumeInvite(id, userID string) error {
+	return update(is.db, func(tx *bolt.Tx) error {
+		invite, err := inviteByIDInTx(tx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().Unix()
+		if !invite.IsValid(now) {
+			return model.ErrInviteNotValid
+		}
+		invite.UsedBy = userID
+		invite.UsedAt = now
+		return putInviteInTx(tx, invite)
+	})
+}
+
+// Close closes underlying database.
+func (is *InviteStorage) Close() {
+	if err := is.db.Close(); err != nil {
+		logging.Errorf("Error closing invite storage: %s\n", err)
+	}
+}
+
+func inviteByIDInTx(tx *bolt.Tx, id string) (model.Invite, error) {
+	var invite model.Invite
+
+	data := tx.Bucket([]byte(InviteBucket)).Get([]byte(id))
+	if data == nil {
+		return invite, model.ErrInviteNotFound
+	}
+	err := json.Unmarshal(data, &invite)
+	return invite, err
+}
+
+func putInviteInTx(tx *bolt.Tx, invite model.Invite) error {
+	data, err := json.Marshal(invite)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket([]byte(InviteBucket)).Put([]byte(invite.ID), data)
+}
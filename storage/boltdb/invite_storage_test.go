@@ -0,0 +1,75 @@
+package boltdb
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+)
+
+func TestConsumeInvite(t *testing.T) {
+	is, err := NewInviteStorage(newTestDB(t))
+	if err != nil {
+		t.Fatalf("Error creating invite storage: %s", err)
+	}
+	now := time.Now().Unix()
+
+	invite, err := is.AddInvite(model.Invite{AppID: "app", Email: "user@example.com", CreatedAt: now, ExpiresAt: now + 3600})
+	if err != nil {
+		t.Fatalf("Error adding invite: %s", err)
+	}
+
+	// Concurrent registrations with the same invite must not all succeed.
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = is.ConsumeInvite(invite.ID, "user"+strconv.Itoa(i))
+		}(i)
+	}
+	wg.Wait()
+
+	consumedBy := ""
+	for i, err := range errs {
+		switch err {
+		case nil:
+			if consumedBy != "" {
+				t.Fatalf("Invite consumed twice, by %s and user%d", consumedBy, i)
+			}
+			consumedBy = "user" + strconv.Itoa(i)
+		case model.ErrInviteNotValid:
+		default:
+			t.Errorf("ConsumeInvite() error = %v, expected nil or %v", err, model.ErrInviteNotValid)
+		}
+	}
+	if consumedBy == "" {
+		t.Fatalf("Invite has not been consumed")
+	}
+
+	stored, err := is.InviteByID(invite.ID)
+	if err != nil {
+		t.Fatalf("Error getting invite: %s", err)
+	}
+	if stored.UsedBy != consumedBy || stored.UsedAt == 0 || stored.IsValid(now) {
+		t.Errorf("Consumed invite = %+v, expected used by %s", stored, consumedBy)
+	}
+
+	if err = is.ConsumeInvite("unknown", "user"); err != model.ErrInviteNotFound {
+		t.Errorf("ConsumeInvite() of unknown invite error = %v, expected %v", err, model.ErrInviteNotFound)
+	}
+
+	expired, _ := is.AddInvite(model.Invite{AppID: "app", Email: "late@example.com", CreatedAt: now - 7200, ExpiresAt: now - 3600})
+	if err = is.ConsumeInvite(expired.ID, "user"); err != model.ErrInviteNotValid {
+		t.Errorf("ConsumeInvite() of expired invite error = %v, expected %v", err, model.ErrInviteNotValid)
+	}
+
+	revoked, _ := is.AddInvite(model.Invite{AppID: "app", Email: "revoked@example.com", CreatedAt: now, ExpiresAt: now + 3600})
+	is.RevokeInvite(revoked.ID)
+	if err = is.ConsumeInvite(revoked.ID, "user"); err != model.ErrInviteNotValid {
+		t.Errorf("ConsumeInvite() of revoked invite error = %v, expected %v", err, model.ErrInviteNotValid)
+	}
+}
@@ -0,0 +1,256 @@
+package boltdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+const (
+	// OrganizationBucket is a name for bucket with organizations.
+	OrganizationBucket = "Organizations"
+	// OrganizationMemberBucket is a name for bucket with organization members.
+	OrganizationMemberBucket = "OrganizationMembers"
+)
+
+// NewOrganizationStorage creates and inits BoltDB organization storage.
+func NewOrganizationStorage(db *bolt.DB) (model.OrganizationStorage, error) {
+	os := &OrganizationStorage{db: db}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range []string{OrganizationBucket, OrganizationMemberBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return fmt.Errorf("create bucket: %s", err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return os, nil
+}
+
+// OrganizationStorage implements organization storage interface.
+// Members are keyed by "<organization ID>/<user ID>", so members of the organization are stored together.
+type OrganizationStorage struct {
+	db *bolt.DB
+}
+
+func memberKey(orgID, userID string) []byte {
+	return []byte(orgID + "/" + userID)
+}
+
+// AddOrganization saves new organization.
+func (os *OrganizationStorage) AddOrganization(org model.Organization) (model.Organization, error) {
+	org.ID = xid.New().String()
+	data, err := json.Marshal(org)
+	if err != nil {
+		return model.Organization{}, err
+	}
+
+	err = update(os.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(OrganizationBucket)).Put([]byte(org.ID), data)
+	})
+	if err != nil {
+		return model.Organization{}, err
+	}
+	return org, nil
+}
+
+// OrganizationByID returns organization by ID.
+func (os *OrganizationStorage) OrganizationByID(id string) (model.Organization, error) {
+	var org model.Organization
+	err := view(os.db, func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(OrganizationBucket)).Get([]byte(id))
+		if data == nil {
+			return model.ErrOrganizationNotFound
+		}
+		return json.Unmarshal(data, &org)
+	})
+	return org, err
+}
+
+// FetchOrganizations returns organizations which name contains search string, oldest first.
+func (os *OrganizationStorage) FetchOrganizations(search string, skip, limit int) ([]model.Organization, int, error) {
+	orgs := []model.Organization{}
+	total := 0
+
+	err := view(os.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(OrganizationBucket)).ForEach(func(k, v []byte) error {
+			var org model.Organization
+			if err := json.Unmarshal(v, &org); err != nil {
+				return err
+			}
+			if !strings.Contains(strings.ToLower(org.Name), strings.ToLower(search)) {
+				return nil
+			}
+
+			total++
+			if total > skip && (limit == 0 || len(orgs) < limit) {
+				orgs = append(orgs, org)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []model.Organization{}, 0, err
+	}
+	return orgs, total, nil
+}
+
+// UpdateOrganization replaces stored organization.
+func (os *OrganizationStorage) UpdateOrganization(org model.Organization) (model.Organization, error) {
+	data, err := json.Marshal(org)
+	if err != nil {
+		return model.Organization{}, err
+	}
+
+	err = update(os.db, func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(OrganizationBucket))
+		if b.Get([]byte(org.ID)) == nil {
+			return model.ErrOrganizationNotFound
+		}
+		return b.Put([]byte(org.ID), data)
+	})
+	if err != nil {
+		return model.Organization{}, err
+	}
+	return org, nil
+}
+
+// DeleteOrganization deletes the organization with its members.
+func (os *OrganizationStorage) DeleteOrganization(id string) error {
+	return update(os.db, func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(OrganizationBucket))
+		if b.Get([]byte(id)) == nil {
+			return model.ErrOrganizationNotFound
+		}
+		if err := b.Delete([]byte(id)); err != nil {
+			return err
+		}
+
+		mb := tx.Bucket([]byte(OrganizationMemberBucket))
+		prefix := memberKey(id, "")
+		keys := [][]byte{}
+		c := mb.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, k)
+		}
+		for _, k := range keys {
+			if err := mb.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetMember adds the member or changes its role.
+func (os *OrganizationStorage) SetMember(member model.OrganizationMember) (model.OrganizationMember, error) {
+	err := update(os.db, func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(OrganizationBucket)).Get([]byte(member.OrgID)) == nil {
+			return model.ErrOrganizationNotFound
+		}
+
+		mb := tx.Bucket([]byte(OrganizationMemberBucket))
+		key := memberKey(member.OrgID, member.UserID)
+		if data := mb.Get(key); data != nil {
+			var existing model.OrganizationMember
+			if err := json.Unmarshal(data, &existing); err != nil {
+				return err
+			}
+			member.JoinedAt = existing.JoinedAt
+		}
+
+		data, err := json.Marshal(member)
+		if err != nil {
+			return err
+		}
+		return mb.Put(key, data)
+	})
+	if err != nil {
+		return model.OrganizationMember{}, err
+	}
+	return member, nil
+}
+
+// Member returns the membership of the user in the organization.
+func (os *OrganizationStorage) Member(orgID, userID string) (model.OrganizationMember, error) {
+	var member model.OrganizationMember
+	err := view(os.db, func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(OrganizationMemberBucket)).Get(memberKey(orgID, userID))
+		if data == nil {
+			return model.ErrOrganizationMemberNotFound
+		}
+		return json.Unmarshal(data, &member)
+	})
+	return member, err
+}
+
+// FetchMembers returns organization members.
+func (os *OrganizationStorage) FetchMembers(orgID string) ([]model.OrganizationMember, error) {
+	members := []model.OrganizationMember{}
+
+	err := view(os.db, func(tx *bolt.Tx) error {
+		prefix := memberKey(orgID, "")
+		c := tx.Bucket([]byte(OrganizationMemberBucket)).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var member model.OrganizationMember
+			if err := json.Unmarshal(v, &member); err != nil {
+				return err
+			}
+			members = append(members, member)
+		}
+		return nil
+	})
+	if err != nil {
+		return []model.OrganizationMember{}, err
+	}
+	return members, nil
+}
+
+// UserMemberships returns all memberships of the user.
+func (os *OrganizationStorage) UserMemberships(userID string) ([]model.OrganizationMember, error) {
+	memberships := []model.OrganizationMember{}
+
+	err := view(os.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(OrganizationMemberBucket)).ForEach(func(k, v []byte) error {
+			var member model.OrganizationMember
+			if err := json.Unmarshal(v, &member); err != nil {
+				return err
+			}
+			if member.UserID == userID {
+				memberships = append(memberships, member)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []model.OrganizationMember{}, err
+	}
+	return memberships, nil
+}
+
+// RemoveMember removes the user from the organization.
+func (os *OrganizationStorage) RemoveMember(orgID, userID string) error {
+	return update(os.db, func(tx *bolt.Tx) error {
+		mb := tx.Bucket([]byte(OrganizationMemberBucket))
+		if mb.Get(memberKey(orgID, userID)) == nil {
+			return model.ErrOrganizationMemberNotFound
+		}
+		return mb.Delete(memberKey(orgID, userID))
+	})
+}
+
+// Close closes underlying database.
+func (os *OrganizationStorage) Close() {
+	if err := os.db.Close(); err != nil {
+		logging.Errorf("Error closing organization storage: %s\n", err)
+	}
+}
@@ -0,0 +1,62 @@
+package boltdb
+
+import (
+	"testing"
+
+	"github.com/madappgang/identifo/model"
+)
+
+func TestOrganizationStorage(t *testing.T) {
+	os, err := NewOrganizationStorage(newTestDB(t))
+	if err != nil {
+		t.Fatalf("Error creating storage: %s", err)
+	}
+
+	acme, err := os.AddOrganization(model.Organization{Name: "Acme"})
+	if err != nil || acme.ID == "" {
+		t.Fatalf("Error adding organization: %v, %+v", err, acme)
+	}
+	globex, _ := os.AddOrganization(model.Organization{Name: "Globex"})
+
+	if orgs, total, _ := os.FetchOrganizations("acm", 0, 10); total != 1 || len(orgs) != 1 || orgs[0].ID != acme.ID {
+		t.Errorf("FetchOrganizations() = %+v, %d, expected Acme only", orgs, total)
+	}
+	if _, err = os.SetMember(model.OrganizationMember{OrgID: "unknown", UserID: "u1"}); err != model.ErrOrganizationNotFound {
+		t.Errorf("SetMember() in unknown organization error = %v, expected %v", err, model.ErrOrganizationNotFound)
+	}
+
+	os.SetMember(model.OrganizationMember{OrgID: acme.ID, UserID: "u1", Role: model.OrganizationRoleOwner, JoinedAt: 1})
+	os.SetMember(model.OrganizationMember{OrgID: acme.ID, UserID: "u2", Role: model.OrganizationRoleMember, JoinedAt: 2})
+	os.SetMember(model.OrganizationMember{OrgID: globex.ID, UserID: "u2", Role: model.OrganizationRoleAdmin, JoinedAt: 3})
+
+	member, err := os.SetMember(model.OrganizationMember{OrgID: acme.ID, UserID: "u2", Role: model.OrganizationRoleAdmin, JoinedAt: 4})
+	if err != nil || member.Role != model.OrganizationRoleAdmin || member.JoinedAt != 2 {
+		t.Errorf("SetMember() changing role = %+v, %v, expected admin joined at 2", member, err)
+	}
+	if members, _ := os.FetchMembers(acme.ID); len(members) != 2 || members[0].UserID != "u1" || members[1].UserID != "u2" {
+		t.Errorf("FetchMembers() = %+v, expected u1 and u2", members)
+	}
+	if memberships, _ := os.UserMemberships("u2"); len(memberships) != 2 {
+		t.Errorf("UserMemberships() = %+v, expected 2", memberships)
+	}
+
+	if err = os.RemoveMember(acme.ID, "u2"); err != nil {
+		t.Errorf("Error removing member: %s", err)
+	}
+	if _, err = os.Member(acme.ID, "u2"); err != model.ErrOrganizationMemberNotFound {
+		t.Errorf("Member() after removal error = %v, expected %v", err, model.ErrOrganizationMemberNotFound)
+	}
+	if err = os.RemoveMember(acme.ID, "u2"); err != model.ErrOrganizationMemberNotFound {
+		t.Errorf("RemoveMember() twice error = %v, expected %v", err, model.ErrOrganizationMemberNotFound)
+	}
+
+	if err = os.DeleteOrganization(globex.ID); err != nil {
+		t.Errorf("Error deleting organization: %s", err)
+	}
+	if _, err = os.OrganizationByID(globex.ID); err != model.ErrOrganizationNotFound {
+		t.Errorf("OrganizationByID() after deletion error = %v, expected %v", err, model.ErrOrganizationNotFound)
+	}
+	if memberships, _ := os.UserMemberships("u2"); len(memberships) != 0 {
+		t.Errorf("Memberships in deleted organization are kept: %+v", memberships)
+	}
+}
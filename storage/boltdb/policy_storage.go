@@ -0,0 +1,117 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// PolicyRuleBucket is a name for bucket with policy rules.
+const PolicyRuleBucket = "PolicyRules"
+
+// NewPolicyStorage creates and inits BoltDB policy storage.
+func NewPolicyStorage(db *bolt.DB) (model.PolicyStorage, error) {
+	ps := &PolicyStorage{db: db}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(PolicyRuleBucket)); err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+// PolicyStorage implements policy storage interface.
+// Rules are loaded once per app enforcer, so they are fetched with the bucket scan.
+type PolicyStorage struct {
+	db *bolt.DB
+}
+
+// AddPolicyRule saves new rule.
+func (ps *PolicyStorage) AddPolicyRule(rule model.PolicyRule) (model.PolicyRule, error) {
+	rule.ID = xid.New().String()
+	if err := ps.put(rule); err != nil {
+		return model.PolicyRule{}, err
+	}
+	return rule, nil
+}
+
+// PolicyRuleByID returns rule by its ID.
+func (ps *PolicyStorage) PolicyRuleByID(id string) (model.PolicyRule, error) {
+	var rule model.PolicyRule
+	err := view(ps.db, func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(PolicyRuleBucket)).Get([]byte(id))
+		if data == nil {
+			return model.ErrPolicyRuleNotFound
+		}
+		return json.Unmarshal(data, &rule)
+	})
+	return rule, err
+}
+
+// FetchPolicyRules returns all rules of the app, oldest first.
+func (ps *PolicyStorage) FetchPolicyRules(appID string) ([]model.PolicyRule, error) {
+	rules := []model.PolicyRule{}
+	err := view(ps.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(PolicyRuleBucket)).ForEach(func(k, v []byte) error {
+			var rule model.PolicyRule
+			if err := json.Unmarshal(v, &rule); err != nil {
+				return err
+			}
+			if rule.AppID == appID {
+				rules = append(rules, rule)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []model.PolicyRule{}, err
+	}
+	return rules, nil
+}
+
+// UpdatePolicyRule updates the rule.
+func (ps *PolicyStorage) UpdatePolicyRule(rule model.PolicyRule) (model.PolicyRule, error) {
+	if _, err := ps.PolicyRuleByID(rule.ID); err != nil {
+		return model.PolicyRule{}, err
+	}
+	if err := ps.put(rule); err != nil {
+		return model.PolicyRule{}, err
+	}
+	return rule, nil
+}
+
+func (ps *PolicyStorage) put(rule model.PolicyRule) error {
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	return update(ps.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(PolicyRuleBucket)).Put([]byte(rule.ID), data)
+	})
+}
+
+// DeletePolicyRule deletes the rule.
+func (ps *PolicyStorage) DeletePolicyRule(id string) error {
+	return update(ps.db, func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(PolicyRuleBucket))
+		if b.Get([]byte(id)) == nil {
+			return model.ErrPolicyRuleNotFound
+		}
+		return b.Delete([]byte(id))
+	})
+}
+
+// Close closes underlying database.
+func (ps *PolicyStorage) Close() {
+	if err := ps.db.Close(); err != nil {
+		logging.Errorf("Error closing policy storage: %s\n", err)
+	}
+}
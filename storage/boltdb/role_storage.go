@@ -0,0 +1,193 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+const (
+	// RoleBucket is a name for bucket with roles.
+	RoleBucket = "Roles"
+	// UserRolesBucket is a name for bucket with roles assigned to the users, keyed by app ID and user ID.
+	UserRolesBucket = "UserRoles"
+)
+
+// NewRoleStorage creates and inits BoltDB role storage.
+func NewRoleStorage(db *bolt.DB) (model.RoleStorage, error) {
+	rs := &RoleStorage{db: db}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range []string{RoleBucket, UserRolesBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return fmt.Errorf("create bucket: %s", err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// RoleStorage implements role storage interface.
+type RoleStorage struct {
+	db *bolt.DB
+}
+
+func userRolesKey(appID, userID string) []byte {
+	return []byte(appID + "/" + userID)
+}
+
+// AddRole saves new role.
+func (rs *RoleStorage) AddRole(role model.Role) (model.Role, error) {
+	role.ID = xid.New().String()
+	if err := rs.put(role); err != nil {
+		return model.Role{}, err
+	}
+	return role, nil
+}
+
+// RoleByID returns role by its ID.
+func (rs *RoleStorage) RoleByID(id string) (model.Role, error) {
+	var role model.Role
+	err := view(rs.db, func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(RoleBucket)).Get([]byte(id))
+		if data == nil {
+			return model.ErrRoleNotFound
+		}
+		return json.Unmarshal(data, &role)
+	})
+	return role, err
+}
+
+// FetchRoles returns all roles of the app, sorted by name.
+func (rs *RoleStorage) FetchRoles(appID string) ([]model.Role, error) {
+	roles := []model.Role{}
+	err := view(rs.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(RoleBucket)).ForEach(func(k, v []byte) error {
+			var role model.Role
+			if err := json.Unmarshal(v, &role); err != nil {
+				return err
+			}
+			if role.AppID == appID {
+				roles = append(roles, role)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []model.Role{}, err
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+// UpdateRole updates the role.
+func (rs *RoleStorage) UpdateRole(role model.Role) (model.Role, error) {
+	if _, err := rs.RoleByID(role.ID); err != nil {
+		return model.Role{}, err
+	}
+	if err := rs.put(role); err != nil {
+		return model.Role{}, err
+	}
+	return role, nil
+}
+
+func (rs *RoleStorage) put(role model.Role) error {
+	data, err := json.Marshal(role)
+	if err != nil {
+		return err
+	}
+	return update(rs.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(RoleBucket)).Put([]byte(role.ID), data)
+	})
+}
+
+// DeleteRole deletes the role and unassigns it from the users.
+func (rs *RoleStorage) DeleteRole(id string) error {
+	return update(rs.db, func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(RoleBucket))
+		data := b.Get([]byte(id))
+		if data == nil {
+			return model.ErrRoleNotFound
+		}
+		var role model.Role
+		if err := json.Unmarshal(data, &role); err != nil {
+			return err
+		}
+		if err := b.Delete([]byte(id)); err != nil {
+			return err
+		}
+
+		ub := tx.Bucket([]byte(UserRolesBucket))
+		updated := map[string][]string{}
+		c := ub.Cursor()
+		prefix := userRolesKey(role.AppID, "")
+		for k, v := c.Seek(prefix); k != nil && len(k) >= len(prefix) && string(k[:len(prefix)]) == string(prefix); k, v = c.Next() {
+			var names []string
+			if err := json.Unmarshal(v, &names); err != nil {
+				return err
+			}
+			kept := []string{}
+			for _, name := range names {
+				if name != role.Name {
+					kept = append(kept, name)
+				}
+			}
+			if len(kept) != len(names) {
+				updated[string(k)] = kept
+			}
+		}
+		for k, names := range updated {
+			v, err := json.Marshal(names)
+			if err != nil {
+				return err
+			}
+			if err = ub.Put([]byte(k), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UserRoles returns names of the roles assigned to the user in the app.
+func (rs *RoleStorage) UserRoles(appID, userID string) ([]string, error) {
+	names := []string{}
+	err := view(rs.db, func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(UserRolesBucket)).Get(userRolesKey(appID, userID))
+		if data == nil {
+			return nil
+		}
+		return json.Unmarshal(data, &names)
+	})
+	return names, err
+}
+
+// SetUserRoles replaces the roles assigned to the user in the app.
+func (rs *RoleStorage) SetUserRoles(appID, userID string, roles []string) error {
+	return update(rs.db, func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(UserRolesBucket))
+		if len(roles) == 0 {
+			return b.Delete(userRolesKey(appID, userID))
+		}
+		data, err := json.Marshal(roles)
+		if err != nil {
+			return err
+		}
+		return b.Put(userRolesKey(appID, userID), data)
+	})
+}
+
+// Close closes underlying database.
+func (rs *RoleStorage) Close() {
+	if err := rs.db.Close(); err != nil {
+		logging.Errorf("Error closing role storage: %s\n", err)
+	}
+}
@@ -0,0 +1,103 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// SCIMTokenBucket is a name for bucket with SCIM tokens.
+const SCIMTokenBucket = "SCIMTokens"
+
+// NewSCIMTokenStorage creates and inits BoltDB SCIM token storage.
+func NewSCIMTokenStorage(db *bolt.DB) (model.SCIMTokenStorage, error) {
+	ss := &SCIMTokenStorage{db: db}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(SCIMTokenBucket)); err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+// SCIMTokenStorage implements SCIM token storage interface.
+// There are few tokens, so they are looked up by hash with the bucket scan.
+type SCIMTokenStorage struct {
+	db *bolt.DB
+}
+
+// AddSCIMToken saves new token.
+func (ss *SCIMTokenStorage) AddSCIMToken(token model.SCIMToken) (model.SCIMToken, error) {
+	token.ID = xid.New().String()
+	data, err := json.Marshal(token)
+	if err != nil {
+		return model.SCIMToken{}, err
+	}
+
+	err = update(ss.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(SCIMTokenBucket)).Put([]byte(token.ID), data)
+	})
+	if err != nil {
+		return model.SCIMToken{}, err
+	}
+	return token, nil
+}
+
+// SCIMTokenByHash returns token by its hash.
+func (ss *SCIMTokenStorage) SCIMTokenByHash(hash string) (model.SCIMToken, error) {
+	tokens, err := ss.FetchSCIMTokens()
+	if err != nil {
+		return model.SCIMToken{}, err
+	}
+	for _, token := range tokens {
+		if token.Hash == hash {
+			return token, nil
+		}
+	}
+	return model.SCIMToken{}, model.ErrSCIMTokenNotFound
+}
+
+// FetchSCIMTokens returns all tokens, oldest first.
+func (ss *SCIMTokenStorage) FetchSCIMTokens() ([]model.SCIMToken, error) {
+	tokens := []model.SCIMToken{}
+	err := view(ss.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(SCIMTokenBucket)).ForEach(func(k, v []byte) error {
+			var token model.SCIMToken
+			if err := json.Unmarshal(v, &token); err != nil {
+				return err
+			}
+			tokens = append(tokens, token)
+			return nil
+		})
+	})
+	if err != nil {
+		return []model.SCIMToken{}, err
+	}
+	return tokens, nil
+}
+
+// DeleteSCIMToken deletes the token.
+func (ss *SCIMTokenStorage) DeleteSCIMToken(id string) error {
+	return update(ss.db, func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(SCIMTokenBucket))
+		if b.Get([]byte(id)) == nil {
+			return model.ErrSCIMTokenNotFound
+		}
+		return b.Delete([]byte(id))
+	})
+}
+
+// Close closes underlying database.
+func (ss *SCIMTokenStorage) Close() {
+	if err := ss.db.Close(); err != nil {
+		logging.Errorf("Error closing SCIM token storage: %s\n", err)
+	}
+}
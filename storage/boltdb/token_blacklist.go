@@ -2,9 +2,9 @@ package boltdb
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 )
 
@@ -16,7 +16,7 @@ const (
 // NewTokenBlacklist creates a token blacklist in BoltDB.
 func NewTokenBlacklist(db *bolt.DB) (model.TokenBlacklist, error) {
 	tb := &TokenBlacklist{db: db}
-	if err := tb.db.Update(func(tx *bolt.Tx) error {
+	if err := update(tb.db, func(tx *bolt.Tx) error {
 		if _, err := tx.CreateBucketIfNotExists([]byte(BlacklistedTokenBucket)); err != nil {
 			return fmt.Errorf("create bucket: %s", err)
 		}
@@ -34,7 +34,7 @@ type TokenBlacklist struct {
 
 // Add adds token in the blacklist.
 func (tb *TokenBlacklist) Add(token string) error {
-	return tb.db.Update(func(tx *bolt.Tx) error {
+	return update(tb.db, func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(BlacklistedTokenBucket))
 		// We use token as key and value.
 		return b.Put([]byte(token), []byte(token))
@@ -44,7 +44,7 @@ func (tb *TokenBlacklist) Add(token string) error {
 // IsBlacklisted returns true if the token is blacklisted.
 func (tb *TokenBlacklist) IsBlacklisted(token string) bool {
 	var res bool
-	if err := tb.db.View(func(tx *bolt.Tx) error {
+	if err := view(tb.db, func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(BlacklistedTokenBucket))
 		// We use token as key and value.
 		res = b.Get([]byte(token)) != nil
@@ -58,6 +58,6 @@ func (tb *TokenBlacklist) IsBlacklisted(token string) bool {
 // Close closes underlying database.
 func (tb *TokenBlacklist) Close() {
 	if err := tb.db.Close(); err != nil {
-		log.Printf("Error closing token blacklist storage: %s\n", err)
+		logging.Errorf("Error closing token blacklist storage: %s\n", err)
 	}
 }
@@ -2,9 +2,9 @@ package boltdb
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 )
 
@@ -35,7 +35,7 @@ type TokenStorage struct {
 
 // SaveToken saves token in the storage.
 func (ts *TokenStorage) SaveToken(token string) error {
-	return ts.db.Update(func(tx *bolt.Tx) error {
+	return update(ts.db, func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(TokenBucket))
 		// We use token as key and value.
 		return b.Put([]byte(token), []byte(token))
@@ -45,7 +45,7 @@ func (ts *TokenStorage) SaveToken(token string) error {
 // HasToken returns true if the token is present in the storage.
 func (ts *TokenStorage) HasToken(token string) bool {
 	var res bool
-	if err := ts.db.View(func(tx *bolt.Tx) error {
+	if err := view(ts.db, func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(TokenBucket))
 		// We use token as key and value.
 		res = b.Get([]byte(token)) != nil
@@ -59,7 +59,7 @@ func (ts *TokenStorage) HasToken(token string) bool {
 
 // DeleteToken removes token from the storage.
 func (ts *TokenStorage) DeleteToken(token string) error {
-	return ts.db.Update(func(tx *bolt.Tx) error {
+	return update(ts.db, func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(TokenBucket))
 		// We use token as key and value.
 		return b.Delete([]byte(token))
@@ -69,6 +69,6 @@ func (ts *TokenStorage) DeleteToken(token string) error {
 // Close closes underlying database.
 func (ts *TokenStorage) Close() {
 	if err := ts.db.Close(); err != nil {
-		log.Printf("Error closing token storage: %s\n", err)
+		logging.Errorf("Error closing token storage: %s\n", err)
 	}
 }
@@ -13,17 +13,22 @@ type User struct {
 
 // User data implementation.
 type userData struct {
-	ID              string        `json:"id,omitempty"`
-	Username        string        `json:"username,omitempty"`
-	Email           string        `json:"email,omitempty"`
-	Phone           string        `json:"phone,omitempty"`
-	Pswd            string        `json:"pswd,omitempty"`
-	Active          bool          `json:"active,omitempty"`
-	TFAInfo         model.TFAInfo `json:"tfa_info"`
-	NumOfLogins     int           `json:"num_of_logins,omitempty"`
-	LatestLoginTime int64         `json:"latest_login_time,omitempty"`
-	AccessRole      string        `json:"access_role,omitempty"`
-	Anonymous       bool          `json:"anonymous,omitempty"`
+	ID               string                 `json:"id,omitempty"`
+	Username         string                 `json:"username,omitempty"`
+	Email            string                 `json:"email,omitempty"`
+	Phone            string                 `json:"phone,omitempty"`
+	Pswd             string                 `json:"pswd,omitempty"`
+	Active           bool                   `json:"active,omitempty"`
+	TFAInfo          model.TFAInfo          `json:"tfa_info"`
+	FederatedIDs     []string               `json:"federated_ids,omitempty"`
+	NumOfLogins      int                    `json:"num_of_logins,omitempty"`
+	LatestLoginTime  int64                  `json:"latest_login_time,omitempty"`
+	AccessRole       string                 `json:"access_role,omitempty"`
+	Anonymous        bool                   `json:"anonymous,omitempty"`
+	TokensValidAfter int64                  `json:"tokens_valid_after,omitempty"`
+	UserMetadata     map[string]interface{} `json:"user_metadata,omitempty"`
+	AppMetadata      map[string]interface{} `json:"app_metadata,omitempty"`
+	Attributes       map[string]interface{} `json:"attributes,omitempty"`
 }
 
 // Marshal serializes data to byte array.
@@ -55,6 +60,9 @@ func (u *User) SetEmail(email string) { u.userData.Email = email }
 // Phone implements model.User interface.
 func (u *User) Phone() string { return u.userData.Phone }
 
+// FederatedIDs implements model.User interface.
+func (u *User) FederatedIDs() []string { return u.userData.FederatedIDs }
+
 // TFAInfo implements model.User interface.
 func (u *User) TFAInfo() model.TFAInfo { return u.userData.TFAInfo }
 
@@ -67,6 +75,9 @@ func (u *User) PasswordHash() string { return u.userData.Pswd }
 // Active implements model.User interface.
 func (u *User) Active() bool { return u.userData.Active }
 
+// SetActive implements model.User interface.
+func (u *User) SetActive(active bool) { u.userData.Active = active }
+
 // AccessRole implements model.User interface.
 func (u *User) AccessRole() string { return u.userData.AccessRole }
 
@@ -76,6 +87,27 @@ func (u *User) IsAnonymous() bool { return u.userData.Anonymous }
 // Deanonimize implements model.User interface.
 func (u *User) Deanonimize() { u.userData.Anonymous = false }
 
+// TokensValidAfter implements model.User interface.
+func (u *User) TokensValidAfter() int64 { return u.userData.TokensValidAfter }
+
+// UserMetadata implements model.User interface.
+func (u *User) UserMetadata() map[string]interface{} { return u.userData.UserMetadata }
+
+// SetUserMetadata implements model.User interface.
+func (u *User) SetUserMetadata(metadata map[string]interface{}) { u.userData.UserMetadata = metadata }
+
+// AppMetadata implements model.User interface.
+func (u *User) AppMetadata() map[string]interface{} { return u.userData.AppMetadata }
+
+// SetAppMetadata implements model.User interface.
+func (u *User) SetAppMetadata(metadata map[string]interface{}) { u.userData.AppMetadata = metadata }
+
+// Attributes implements model.User interface.
+func (u *User) Attributes() map[string]interface{} { return u.userData.Attributes }
+
+// SetAttributes implements model.User interface.
+func (u *User) SetAttributes(attributes map[string]interface{}) { u.userData.Attributes = attributes }
+
 // UserFromJSON deserializes user data from JSON.
 func UserFromJSON(d []byte) (*User, error) {
 	user := userData{}
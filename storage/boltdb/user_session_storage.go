@@ -0,0 +1,158 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+const (
+	// UserSessionBucket is a name for bucket with user sessions.
+	UserSessionBucket = "UserSessions"
+)
+
+// errStopIteration stops bucket iteration when the value is found.
+var errStopIteration = errors.New("stop iteration")
+
+// NewUserSessionStorage creates and inits BoltDB user session storage.
+func NewUserSessionStorage(db *bolt.DB) (model.UserSessionStorage, error) {
+	ss := &UserSessionStorage{db: db}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(UserSessionBucket)); err != nil {
+			return fmt.Errorf("create bucket: %s", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+// UserSessionStorage implements user session storage interface.
+type UserSessionStorage struct {
+	db *bolt.DB
+}
+
+// AddUserSession saves new session.
+func (ss *UserSessionStorage) AddUserSession(session model.UserSession) (model.UserSession, error) {
+	session.ID = xid.New().String()
+
+	err := update(ss.db, func(tx *bolt.Tx) error {
+		return putUserSessionInTx(tx, session)
+	})
+	return session, err
+}
+
+// UserSessionByID returns session by its ID.
+func (ss *UserSessionStorage) UserSessionByID(id string) (model.UserSession, error) {
+	var session model.UserSession
+	err := view(ss.db, func(tx *bolt.Tx) error {
+		var err error
+		session, err = userSessionByIDInTx(tx, id)
+		return err
+	})
+	return session, err
+}
+
+// UserSessionByToken returns session which has issued the token.
+func (ss *UserSessionStorage) UserSessionByToken(token string) (model.UserSession, error) {
+	var session model.UserSession
+	found := false
+
+	err := view(ss.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(UserSessionBucket)).ForEach(func(k, v []byte) error {
+			if err := json.Unmarshal(v, &session); err != nil {
+				return err
+			}
+			if session.HasToken(token) {
+				found = true
+				return errStopIteration
+			}
+			return nil
+		})
+	})
+	if err != nil && err != errStopIteration {
+		return model.UserSession{}, err
+	}
+	if !found {
+		return model.UserSession{}, model.ErrUserSessionNotFound
+	}
+	return session, nil
+}
+
+// FetchUserSessions returns all sessions of the user, most recently used first.
+func (ss *UserSessionStorage) FetchUserSessions(userID string) ([]model.UserSession, error) {
+	sessions := []model.UserSession{}
+
+	err := view(ss.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(UserSessionBucket)).ForEach(func(k, v []byte) error {
+			var session model.UserSession
+			if err := json.Unmarshal(v, &session); err != nil {
+				return err
+			}
+			if session.UserID == userID {
+				sessions = append(sessions, session)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []model.UserSession{}, err
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt > sessions[j].LastUsedAt })
+	return sessions, nil
+}
+
+// UpdateUserSessionTokens replaces session tokens and sets last used time.
+func (ss *UserSessionStorage) UpdateUserSessionTokens(id string, tokens []string, lastUsedAt int64) error {
+	return update(ss.db, func(tx *bolt.Tx) error {
+		session, err := userSessionByIDInTx(tx, id)
+		if err != nil {
+			return err
+		}
+		session.Tokens = tokens
+		session.LastUsedAt = lastUsedAt
+		return putUserSessionInTx(tx, session)
+	})
+}
+
+// DeleteUserSession deletes session.
+func (ss *UserSessionStorage) DeleteUserSession(id string) error {
+	return update(ss.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(UserSessionBucket)).Delete([]byte(id))
+	})
+}
+
+// Close closes underlying database.
+func (ss *UserSessionStorage) Close() {
+	if err := ss.db.Close(); err != nil {
+		logging.Errorf("Error closing user session storage: %s\n", err)
+	}
+}
+
+func userSessionByIDInTx(tx *bolt.Tx, id string) (model.UserSession, error) {
+	var session model.UserSession
+
+	data := tx.Bucket([]byte(UserSessionBucket)).Get([]byte(id))
+	if data == nil {
+		return session, model.ErrUserSessionNotFound
+	}
+	err := json.Unmarshal(data, &session)
+	return session, err
+}
+
+func putUserSessionInTx(tx *bolt.Tx, session model.UserSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket([]byte(UserSessionBucket)).Put([]byte(session.ID), data)
+}
@@ -4,11 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	"github.com/rs/xid"
 	"golang.org/x/crypto/bcrypt"
@@ -63,7 +63,7 @@ func (us *UserStorage) NewUser() model.User {
 // UserByID returns user by ID.
 func (us *UserStorage) UserByID(id string) (model.User, error) {
 	var res *User
-	err := us.db.View(func(tx *bolt.Tx) error {
+	err := view(us.db, func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(UserBucket))
 		u := b.Get([]byte(id))
 		if u == nil {
@@ -88,28 +88,28 @@ func (us *UserStorage) UserByEmail(email string) (model.User, error) {
 
 // DeleteUser deletes user by ID.
 func (us *UserStorage) DeleteUser(id string) error {
-	if err := us.db.Update(func(tx *bolt.Tx) error {
+	if err := update(us.db, func(tx *bolt.Tx) error {
 		ub := tx.Bucket([]byte(UserBucket))
 		return ub.Delete([]byte(id))
 	}); err != nil {
 		return err
 	}
 
-	if err := us.db.Update(func(tx *bolt.Tx) error {
+	if err := update(us.db, func(tx *bolt.Tx) error {
 		unpb := tx.Bucket([]byte(UserByNameAndPassword))
 		return unpb.Delete([]byte(id))
 	}); err != nil {
 		return err
 	}
 
-	if err := us.db.Update(func(tx *bolt.Tx) error {
+	if err := update(us.db, func(tx *bolt.Tx) error {
 		usib := tx.Bucket([]byte(UserBySocialIDBucket))
 		return usib.Delete([]byte(id))
 	}); err != nil {
 		return err
 	}
 
-	err := us.db.Update(func(tx *bolt.Tx) error {
+	err := update(us.db, func(tx *bolt.Tx) error {
 		upnb := tx.Bucket([]byte(UserByPhoneNumberBucket))
 		return upnb.Delete([]byte(id))
 	})
@@ -121,7 +121,7 @@ func (us *UserStorage) UserByFederatedID(provider model.FederatedIdentityProvide
 	var res *User
 	sid := string(provider) + ":" + id
 
-	err := us.db.View(func(tx *bolt.Tx) error {
+	err := view(us.db, func(tx *bolt.Tx) error {
 		usib := tx.Bucket([]byte(UserBySocialIDBucket))
 		// get userID from index.
 		userID := usib.Get([]byte(sid))
@@ -148,7 +148,7 @@ func (us *UserStorage) UserByFederatedID(provider model.FederatedIdentityProvide
 
 // UserExists checks if user with provided name exists.
 func (us *UserStorage) UserExists(name string) bool {
-	err := us.db.View(func(tx *bolt.Tx) error {
+	err := view(us.db, func(tx *bolt.Tx) error {
 		unpb := tx.Bucket([]byte(UserByNameAndPassword))
 		userID := unpb.Get([]byte(name))
 
@@ -192,7 +192,7 @@ func (us *UserStorage) Scopes() []string {
 // UserByPhone fetches user by phone number.
 func (us *UserStorage) UserByPhone(phone string) (model.User, error) {
 	var res *User
-	err := us.db.View(func(tx *bolt.Tx) error {
+	err := view(us.db, func(tx *bolt.Tx) error {
 		upnb := tx.Bucket([]byte(UserByPhoneNumberBucket))
 		// We use phone number as a key.
 		// Get user ID.
@@ -224,7 +224,7 @@ func (us *UserStorage) UserByPhone(phone string) (model.User, error) {
 // UserByNamePassword returns user by name and password.
 func (us *UserStorage) UserByNamePassword(name, password string) (model.User, error) {
 	var res *User
-	err := us.db.View(func(tx *bolt.Tx) error {
+	err := view(us.db, func(tx *bolt.Tx) error {
 		unpb := tx.Bucket([]byte(UserByNameAndPassword))
 		// we use username and password hash as a key
 		key := name
@@ -267,7 +267,7 @@ func (us *UserStorage) AddNewUser(usr model.User, password string) (model.User,
 	u.userData.Pswd = PasswordHash(password)
 	u.userData.NumOfLogins = 0
 
-	err := us.db.Update(func(tx *bolt.Tx) error {
+	err := update(us.db, func(tx *bolt.Tx) error {
 		data, err := u.Marshal()
 		if err != nil {
 			return err
@@ -302,7 +302,7 @@ func (us *UserStorage) AddUserByPhone(phone, role string) (model.User, error) {
 		},
 	}
 
-	err := us.db.Update(func(tx *bolt.Tx) error {
+	err := update(us.db, func(tx *bolt.Tx) error {
 		data, err := u.Marshal()
 		if err != nil {
 			return err
@@ -332,11 +332,11 @@ func (us *UserStorage) AddUserWithFederatedID(provider model.FederatedIdentityPr
 		return nil, model.ErrorUserExists
 	}
 
-	u := userData{Active: true, Username: sid, AccessRole: role, NumOfLogins: 0}
+	u := userData{Active: true, Username: sid, AccessRole: role, NumOfLogins: 0, FederatedIDs: []string{sid}}
 	u.ID = sid // not sure it's a good idea
 	user := &User{userData: u}
 
-	err := us.db.Update(func(tx *bolt.Tx) error {
+	err := update(us.db, func(tx *bolt.Tx) error {
 		data, err := user.Marshal()
 		if err != nil {
 			return err
@@ -392,7 +392,7 @@ func (us *UserStorage) UpdateUser(userID string, newUser model.User) (model.User
 		res.userData.ID = userID
 	}
 
-	err := us.db.Update(func(tx *bolt.Tx) error {
+	err := update(us.db, func(tx *bolt.Tx) error {
 		ub := tx.Bucket([]byte(UserBucket))
 		oldBytes := ub.Get([]byte(userID))
 
@@ -407,6 +407,9 @@ func (us *UserStorage) UpdateUser(userID string, newUser model.User) (model.User
 			if res.userData.TFAInfo.Secret == "" {
 				res.userData.TFAInfo.Secret = oldUser.userData.TFAInfo.Secret
 			}
+			if res.userData.TokensValidAfter == 0 {
+				res.userData.TokensValidAfter = oldUser.userData.TokensValidAfter
+			}
 		}
 
 		data, err := res.Marshal()
@@ -429,9 +432,179 @@ func (us *UserStorage) UpdateUser(userID string, newUser model.User) (model.User
 	return updatedUser, err
 }
 
+// LinkFederatedID attaches federated identity to the existing user.
+func (us *UserStorage) LinkFederatedID(userID string, provider model.FederatedIdentityProvider, id string) error {
+	sid := model.FederatedIDKey(provider, id)
+
+	return update(us.db, func(tx *bolt.Tx) error {
+		usib := tx.Bucket([]byte(UserBySocialIDBucket))
+		if owner := usib.Get([]byte(sid)); owner != nil {
+			if string(owner) == userID {
+				return nil
+			}
+			return model.ErrorUserExists
+		}
+
+		if err := updateUserInTx(tx, userID, func(u *User) {
+			u.userData.FederatedIDs = append(u.userData.FederatedIDs, sid)
+		}); err != nil {
+			return err
+		}
+		return usib.Put([]byte(sid), []byte(userID))
+	})
+}
+
+// UnlinkFederatedID detaches federated identity from the user.
+func (us *UserStorage) UnlinkFederatedID(userID string, provider model.FederatedIdentityProvider, id string) error {
+	sid := model.FederatedIDKey(provider, id)
+
+	return update(us.db, func(tx *bolt.Tx) error {
+		if err := updateUserInTx(tx, userID, func(u *User) {
+			fids := []string{}
+			for _, fid := range u.userData.FederatedIDs {
+				if fid != sid {
+					fids = append(fids, fid)
+				}
+			}
+			u.userData.FederatedIDs = fids
+		}); err != nil {
+			return err
+		}
+
+		usib := tx.Bucket([]byte(UserBySocialIDBucket))
+		if owner := usib.Get([]byte(sid)); string(owner) != userID {
+			return nil
+		}
+		return usib.Delete([]byte(sid))
+	})
+}
+
+// LinkPhone attaches phone number to the existing user.
+func (us *UserStorage) LinkPhone(userID, phone string) error {
+	return update(us.db, func(tx *bolt.Tx) error {
+		upnb := tx.Bucket([]byte(UserByPhoneNumberBucket))
+		if owner := upnb.Get([]byte(phone)); owner != nil && string(owner) != userID {
+			return model.ErrorUserExists
+		}
+
+		var oldPhone string
+		if err := updateUserInTx(tx, userID, func(u *User) {
+			oldPhone = u.userData.Phone
+			u.userData.Phone = phone
+		}); err != nil {
+			return err
+		}
+
+		if oldPhone != "" && oldPhone != phone {
+			if err := upnb.Delete([]byte(oldPhone)); err != nil {
+				return err
+			}
+		}
+		return upnb.Put([]byte(phone), []byte(userID))
+	})
+}
+
+// UnlinkPhone removes phone number from the user.
+func (us *UserStorage) UnlinkPhone(userID string) error {
+	return update(us.db, func(tx *bolt.Tx) error {
+		var oldPhone string
+		if err := updateUserInTx(tx, userID, func(u *User) {
+			oldPhone = u.userData.Phone
+			u.userData.Phone = ""
+		}); err != nil {
+			return err
+		}
+
+		if oldPhone == "" {
+			return nil
+		}
+		return tx.Bucket([]byte(UserByPhoneNumberBucket)).Delete([]byte(oldPhone))
+	})
+}
+
+// LinkPassword sets username and password for the existing user.
+func (us *UserStorage) LinkPassword(userID, username, password string) error {
+	return update(us.db, func(tx *bolt.Tx) error {
+		unpb := tx.Bucket([]byte(UserByNameAndPassword))
+		if username != "" {
+			if owner := unpb.Get([]byte(username)); owner != nil && string(owner) != userID {
+				return model.ErrorUserExists
+			}
+		}
+
+		var oldUsername string
+		if err := updateUserInTx(tx, userID, func(u *User) {
+			oldUsername = u.userData.Username
+			if username != "" {
+				u.userData.Username = username
+			}
+			u.userData.Pswd = PasswordHash(password)
+		}); err != nil {
+			return err
+		}
+
+		if username == "" || username == oldUsername {
+			return nil
+		}
+		if oldUsername != "" {
+			if err := unpb.Delete([]byte(oldUsername)); err != nil {
+				return err
+			}
+		}
+		return unpb.Put([]byte(username), []byte(userID))
+	})
+}
+
+// UnlinkPassword removes password from the user.
+func (us *UserStorage) UnlinkPassword(userID string) error {
+	return update(us.db, func(tx *bolt.Tx) error {
+		return updateUserInTx(tx, userID, func(u *User) {
+			u.userData.Pswd = ""
+		})
+	})
+}
+
+// DeanonimizeUser turns anonymous user into the regular one.
+func (us *UserStorage) DeanonimizeUser(userID string) error {
+	return update(us.db, func(tx *bolt.Tx) error {
+		return updateUserInTx(tx, userID, func(u *User) {
+			u.Deanonimize()
+		})
+	})
+}
+
+// SetTokensValidAfter invalidates all user tokens issued before the timestamp.
+func (us *UserStorage) SetTokensValidAfter(userID string, timestamp int64) error {
+	return update(us.db, func(tx *bolt.Tx) error {
+		return updateUserInTx(tx, userID, func(u *User) {
+			u.userData.TokensValidAfter = timestamp
+		})
+	})
+}
+
+// updateUserInTx loads the user, applies changes and saves it back within the transaction.
+func updateUserInTx(tx *bolt.Tx, userID string, update func(*User)) error {
+	ub := tx.Bucket([]byte(UserBucket))
+	data := ub.Get([]byte(userID))
+	if data == nil {
+		return model.ErrUserNotFound
+	}
+
+	user, err := UserFromJSON(data)
+	if err != nil {
+		return err
+	}
+	update(user)
+
+	if data, err = user.Marshal(); err != nil {
+		return err
+	}
+	return ub.Put([]byte(userID), data)
+}
+
 // ResetPassword sets new user password.
 func (us *UserStorage) ResetPassword(id, password string) error {
-	return us.db.Update(func(tx *bolt.Tx) error {
+	return update(us.db, func(tx *bolt.Tx) error {
 		ub := tx.Bucket([]byte(UserBucket))
 		u := ub.Get([]byte(id))
 		if u == nil {
@@ -459,10 +632,33 @@ func (us *UserStorage) ResetUsername(id, username string) error {
 	return errors.New("ResetUsername is not implemented. ")
 }
 
+// UserByUsername returns user by username.
+func (us *UserStorage) UserByUsername(username string) (model.User, error) {
+	var res *User
+	err := view(us.db, func(tx *bolt.Tx) error {
+		userID := tx.Bucket([]byte(UserByNameAndPassword)).Get([]byte(username))
+		if userID == nil {
+			return model.ErrUserNotFound
+		}
+		u := tx.Bucket([]byte(UserBucket)).Get(userID)
+		if u == nil {
+			return model.ErrUserNotFound
+		}
+
+		var err error
+		res, err = UserFromJSON(u)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 // IDByName returns userID by name.
 func (us *UserStorage) IDByName(name string) (string, error) {
 	var id string
-	err := us.db.View(func(tx *bolt.Tx) error {
+	err := view(us.db, func(tx *bolt.Tx) error {
 		unpb := tx.Bucket([]byte(UserByNameAndPassword))
 		userID := unpb.Get([]byte(name))
 		if userID == nil {
@@ -494,28 +690,40 @@ func (us *UserStorage) IDByName(name string) (string, error) {
 	return id, nil
 }
 
-// FetchUsers fetches users which name satisfies provided filterString.
+// FetchUsers fetches users which name satisfies provided filterString and which attributes match.
 // Supports pagination.
-func (us *UserStorage) FetchUsers(filterString string, skip, limit int) ([]model.User, int, error) {
+func (us *UserStorage) FetchUsers(filterString string, attributes map[string]interface{}, skip, limit int) ([]model.User, int, error) {
 	users := []model.User{}
 	var total int
 
-	err := us.db.View(func(tx *bolt.Tx) error {
+	err := view(us.db, func(tx *bolt.Tx) error {
 		ubnp := tx.Bucket([]byte(UserByNameAndPassword))
+		ub := tx.Bucket([]byte(UserBucket))
 		var userIDs [][]byte
 
 		if iterErr := ubnp.ForEach(func(k, v []byte) error {
-			if filterString == "" {
-				userIDs = append(userIDs, v)
-			} else if strings.Contains(strings.ToLower(string(k)), strings.ToLower(filterString)) {
-				userIDs = append(userIDs, v)
+			if filterString != "" && !strings.Contains(strings.ToLower(string(k)), strings.ToLower(filterString)) {
+				return nil
+			}
+			if len(attributes) > 0 {
+				u := ub.Get(v)
+				if u == nil {
+					return nil
+				}
+				user, err := UserFromJSON(u)
+				if err != nil {
+					return err
+				}
+				if !model.AttributesMatch(user.Attributes(), attributes) {
+					return nil
+				}
 			}
+			userIDs = append(userIDs, v)
 			return nil
 		}); iterErr != nil {
 			return iterErr
 		}
 
-		ub := tx.Bucket([]byte(UserBucket))
 		total = len(userIDs)
 
 		for i, uid := range userIDs {
@@ -528,7 +736,7 @@ func (us *UserStorage) FetchUsers(filterString string, skip, limit int) ([]model
 
 			u := ub.Get(uid)
 			if u == nil {
-				log.Printf("User %s does not exist in %s, but does exist in %s", uid, UserBucket, UserByNameAndPassword)
+				logging.Warnf("User %s does not exist in %s, but does exist in %s", uid, UserBucket, UserByNameAndPassword)
 				continue
 			}
 
@@ -567,26 +775,26 @@ func (us *UserStorage) ImportJSON(data []byte) error {
 func (us *UserStorage) UpdateLoginMetadata(userID string) {
 	user, err := us.UserByID(userID)
 	if err != nil {
-		log.Printf("Cannot get user by ID %s: %s\n", userID, err)
+		logging.Errorf("Cannot get user by ID %s: %s\n", userID, err)
 	}
 
 	u, ok := user.(*User)
 	if !ok || u == nil {
-		log.Printf("Cannot update login metadata of user %s: %s\n", userID, err)
+		logging.Errorf("Cannot update login metadata of user %s: %s\n", userID, err)
 	}
 
 	u.userData.NumOfLogins++
 	u.userData.LatestLoginTime = time.Now().Unix()
 
 	if _, err := us.UpdateUser(UserBucket, u); err != nil {
-		log.Println("Cannot update user login info: ", err)
+		logging.Error("Cannot update user login info: ", err)
 	}
 }
 
 // Close closes underlying database.
 func (us *UserStorage) Close() {
 	if err := us.db.Close(); err != nil {
-		log.Printf("Error closing user storage: %s\n", err)
+		logging.Errorf("Error closing user storage: %s\n", err)
 	}
 }
 
@@ -2,9 +2,9 @@ package boltdb
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 )
 
@@ -36,7 +36,7 @@ type VerificationCodeStorage struct {
 
 // IsVerificationCodeFound checks whether verification code can be found.
 func (vcs *VerificationCodeStorage) IsVerificationCodeFound(phone, code string) (bool, error) {
-	err := vcs.db.View(func(tx *bolt.Tx) error {
+	err := view(vcs.db, func(tx *bolt.Tx) error {
 		vcb := tx.Bucket([]byte(VerificationCodesBucket))
 		code := vcb.Get([]byte(phone))
 		if code == nil {
@@ -49,7 +49,7 @@ func (vcs *VerificationCodeStorage) IsVerificationCodeFound(phone, code string)
 
 // CreateVerificationCode inserts new verification code to the database.
 func (vcs *VerificationCodeStorage) CreateVerificationCode(phone, code string) error {
-	err := vcs.db.Update(func(tx *bolt.Tx) error {
+	err := update(vcs.db, func(tx *bolt.Tx) error {
 		vcb := tx.Bucket([]byte(VerificationCodesBucket))
 		if err := vcb.Delete([]byte(phone)); err != nil {
 			return err
@@ -63,6 +63,6 @@ func (vcs *VerificationCodeStorage) CreateVerificationCode(phone, code string) e
 // Close closes underlying database.
 func (vcs *VerificationCodeStorage) Close() {
 	if err := vcs.db.Close(); err != nil {
-		log.Printf("Error closing verification code storage: %s\n", err)
+		logging.Errorf("Error closing verification code storage: %s\n", err)
 	}
 }
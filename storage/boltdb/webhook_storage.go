@@ -0,0 +1,205 @@
+package boltdb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+const (
+	// WebhookBucket is a name for bucket with webhooks.
+	WebhookBucket = "Webhooks"
+	// WebhookDeliveryBucket is a name for bucket with webhook deliveries.
+	WebhookDeliveryBucket = "WebhookDeliveries"
+)
+
+// NewWebhookStorage creates and inits BoltDB webhook storage.
+func NewWebhookStorage(db *bolt.DB) (model.WebhookStorage, error) {
+	ws := &WebhookStorage{db: db}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range []string{WebhookBucket, WebhookDeliveryBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return fmt.Errorf("create bucket: %s", err)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// WebhookStorage implements webhook storage interface.
+// Webhooks and deliveries are keyed by xid, which is sortable by creation time.
+type WebhookStorage struct {
+	db *bolt.DB
+}
+
+// AddWebhook saves new webhook.
+func (ws *WebhookStorage) AddWebhook(webhook model.Webhook) (model.Webhook, error) {
+	webhook.ID = xid.New().String()
+	if err := ws.put(WebhookBucket, webhook.ID, webhook, nil); err != nil {
+		return model.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// WebhookByID returns webhook by ID.
+func (ws *WebhookStorage) WebhookByID(id string) (model.Webhook, error) {
+	var webhook model.Webhook
+	err := view(ws.db, func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(WebhookBucket)).Get([]byte(id))
+		if data == nil {
+			return model.ErrWebhookNotFound
+		}
+		return json.Unmarshal(data, &webhook)
+	})
+	return webhook, err
+}
+
+// FetchWebhooks returns all webhooks, oldest first.
+func (ws *WebhookStorage) FetchWebhooks() ([]model.Webhook, error) {
+	webhooks := []model.Webhook{}
+
+	err := view(ws.db, func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(WebhookBucket)).ForEach(func(k, v []byte) error {
+			var webhook model.Webhook
+			if err := json.Unmarshal(v, &webhook); err != nil {
+				return err
+			}
+			webhooks = append(webhooks, webhook)
+			return nil
+		})
+	})
+	if err != nil {
+		return []model.Webhook{}, err
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook replaces stored webhook.
+func (ws *WebhookStorage) UpdateWebhook(webhook model.Webhook) (model.Webhook, error) {
+	if err := ws.put(WebhookBucket, webhook.ID, webhook, model.ErrWebhookNotFound); err != nil {
+		return model.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook deletes webhook.
+func (ws *WebhookStorage) DeleteWebhook(id string) error {
+	return update(ws.db, func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(WebhookBucket))
+		if b.Get([]byte(id)) == nil {
+			return model.ErrWebhookNotFound
+		}
+		return b.Delete([]byte(id))
+	})
+}
+
+// AddWebhookDelivery saves new delivery.
+func (ws *WebhookStorage) AddWebhookDelivery(delivery model.WebhookDelivery) (model.WebhookDelivery, error) {
+	delivery.ID = xid.New().String()
+	if err := ws.put(WebhookDeliveryBucket, delivery.ID, delivery, nil); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// WebhookDeliveryByID returns delivery by ID.
+func (ws *WebhookStorage) WebhookDeliveryByID(id string) (model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := view(ws.db, func(tx *bolt.Tx) error {
+		data := tx.Bucket([]byte(WebhookDeliveryBucket)).Get([]byte(id))
+		if data == nil {
+			return model.ErrWebhookDeliveryNotFound
+		}
+		return json.Unmarshal(data, &delivery)
+	})
+	return delivery, err
+}
+
+// UpdateWebhookDelivery replaces stored delivery.
+func (ws *WebhookStorage) UpdateWebhookDelivery(delivery model.WebhookDelivery) error {
+	return ws.put(WebhookDeliveryBucket, delivery.ID, delivery, model.ErrWebhookDeliveryNotFound)
+}
+
+// FetchWebhookDeliveries returns deliveries matching the filter, newest first.
+func (ws *WebhookStorage) FetchWebhookDeliveries(filter model.WebhookDeliveryFilter, skip, limit int) ([]model.WebhookDelivery, int, error) {
+	deliveries := []model.WebhookDelivery{}
+	total := 0
+
+	err := view(ws.db, func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(WebhookDeliveryBucket)).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var delivery model.WebhookDelivery
+			if err := json.Unmarshal(v, &delivery); err != nil {
+				return err
+			}
+			if !filter.Matches(delivery) {
+				continue
+			}
+
+			total++
+			if total > skip && (limit == 0 || len(deliveries) < limit) {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return []model.WebhookDelivery{}, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// PendingWebhookDeliveries returns pending deliveries due at the time, oldest first.
+func (ws *WebhookStorage) PendingWebhookDeliveries(now int64, limit int) ([]model.WebhookDelivery, error) {
+	deliveries := []model.WebhookDelivery{}
+
+	err := view(ws.db, func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(WebhookDeliveryBucket)).Cursor()
+		for k, v := c.First(); k != nil && (limit == 0 || len(deliveries) < limit); k, v = c.Next() {
+			var delivery model.WebhookDelivery
+			if err := json.Unmarshal(v, &delivery); err != nil {
+				return err
+			}
+			if delivery.Status == model.WebhookDeliveryPending && delivery.NextAttemptAt <= now {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return []model.WebhookDelivery{}, err
+	}
+	return deliveries, nil
+}
+
+// Close closes underlying database.
+func (ws *WebhookStorage) Close() {
+	if err := ws.db.Close(); err != nil {
+		logging.Errorf("Error closing webhook storage: %s\n", err)
+	}
+}
+
+// put saves JSON encoded value. If errNotFound is set, only existing keys are replaced
+// and errNotFound is returned for missing ones.
+func (ws *WebhookStorage) put(bucket, key string, value interface{}, errNotFound error) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return update(ws.db, func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if errNotFound != nil && b.Get([]byte(key)) == nil {
+			return errNotFound
+		}
+		return b.Put([]byte(key), data)
+	})
+}
@@ -0,0 +1,200 @@
+package dynamodb
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// adminsTableName is a table name for admin accounts.
+const adminsTableName = "Admins"
+
+// NewAdminStorage creates and provisions new DynamoDB admin storage.
+func NewAdminStorage(db *DB) (model.AdminStorage, error) {
+	as := &AdminStorage{db: db}
+	err := as.ensureTable()
+	return as, err
+}
+
+// AdminStorage implements admin storage interface.
+type AdminStorage struct {
+	db *DB
+}
+
+// AddAdmin saves new admin.
+// There are only a few admins, so email uniqueness is checked with scan.
+func (as *AdminStorage) AddAdmin(admin model.Admin) (model.Admin, error) {
+	if _, err := as.AdminByEmail(admin.Email); err == nil {
+		return model.Admin{}, model.ErrAdminExists
+	} else if err != model.ErrAdminNotFound {
+		return model.Admin{}, err
+	}
+
+	admin.ID = xid.New().String()
+	if err := as.put(admin, "attribute_not_exists(id)"); err != nil {
+		return model.Admin{}, err
+	}
+	return admin, nil
+}
+
+// AdminByID returns admin by ID.
+func (as *AdminStorage) AdminByID(id string) (model.Admin, error) {
+	var admin model.Admin
+
+	result, err := as.db.C.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(adminsTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+	})
+	if err != nil {
+		logging.Error("Error getting admin from DynamoDB:", err)
+		return admin, ErrorInternalError
+	}
+	if result.Item == nil {
+		return admin, model.ErrAdminNotFound
+	}
+
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &admin); err != nil {
+		logging.Error("Error unmarshalling admin:", err)
+		return admin, ErrorInternalError
+	}
+	return admin, nil
+}
+
+// AdminByEmail returns admin by email.
+func (as *AdminStorage) AdminByEmail(email string) (model.Admin, error) {
+	admins, err := as.scan(&dynamodb.ScanInput{
+		TableName:        aws.String(adminsTableName),
+		FilterExpression: aws.String("email = :email"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":email": {S: aws.String(email)},
+		},
+	})
+	if err != nil {
+		return model.Admin{}, err
+	}
+	if len(admins) == 0 {
+		return model.Admin{}, model.ErrAdminNotFound
+	}
+	return admins[0], nil
+}
+
+// FetchAdmins returns all admins, oldest first.
+func (as *AdminStorage) FetchAdmins() ([]model.Admin, error) {
+	admins, err := as.scan(&dynamodb.ScanInput{TableName: aws.String(adminsTableName)})
+	if err != nil {
+		return []model.Admin{}, err
+	}
+
+	sort.Slice(admins, func(i, j int) bool { return admins[i].CreatedAt < admins[j].CreatedAt })
+	return admins, nil
+}
+
+// UpdateAdmin replaces stored admin.
+func (as *AdminStorage) UpdateAdmin(admin model.Admin) (model.Admin, error) {
+	if err := as.put(admin, "attribute_exists(id)"); err != nil {
+		return model.Admin{}, err
+	}
+	return admin, nil
+}
+
+// DeleteAdmin deletes admin.
+func (as *AdminStorage) DeleteAdmin(id string) error {
+	if _, err := as.db.C.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(adminsTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+	}); err != nil {
+		logging.Error("Error deleting admin:", err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+// Close does nothing here.
+func (as *AdminStorage) Close() {}
+
+func (as *AdminStorage) put(admin model.Admin, condition string) error {
+	item, err := dynamodbattribute.MarshalMap(admin)
+	if err != nil {
+		logging.Error("Error marshalling admin:", err)
+		return ErrorInternalError
+	}
+
+	_, err = as.db.C.PutItem(&dynamodb.PutItemInput{
+		Item:                item,
+		TableName:           aws.String(adminsTableName),
+		ConditionExpression: aws.String(condition),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		if condition == "attribute_exists(id)" {
+			return model.ErrAdminNotFound
+		}
+		return model.ErrAdminExists
+	}
+	if err != nil {
+		logging.Error("Error putting admin to database:", err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+func (as *AdminStorage) scan(scanInput *dynamodb.ScanInput) ([]model.Admin, error) {
+	admins := []model.Admin{}
+	if err := as.db.C.ScanPages(scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageAdmins := []model.Admin{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageAdmins); err != nil {
+			logging.Error("Error unmarshalling admins:", err)
+			return false
+		}
+		admins = append(admins, pageAdmins...)
+		return true
+	}); err != nil {
+		logging.Error("Error querying for admins:", err)
+		return nil, ErrorInternalError
+	}
+	return admins, nil
+}
+
+// ensureTable ensures that admin storage table exists in the database.
+func (as *AdminStorage) ensureTable() error {
+	exists, err := as.db.IsTableExists(adminsTableName)
+	if err != nil {
+		logging.Error("Error checking for admins table existence:", err)
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	createTableInput := &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		BillingMode: aws.String("PAY_PER_REQUEST"),
+		TableName:   aws.String(adminsTableName),
+	}
+
+	if _, err = as.db.C.CreateTable(createTableInput); err != nil {
+		logging.Error("Error creating admins table:", err)
+		return err
+	}
+	return nil
+}
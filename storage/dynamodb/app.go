@@ -2,8 +2,8 @@ package dynamodb
 
 import (
 	"encoding/json"
-	"log"
 
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	"github.com/rs/xid"
 )
@@ -38,12 +38,15 @@ type appData struct {
 	RolesBlacklist               []string               `json:"roles_blacklist,omitempty"`
 	NewUserDefaultRole           string                 `json:"new_user_default_role,omitempty"`
 	AppleInfo                    *model.AppleInfo       `json:"apple_info,omitempty"`
+	GoogleInfo                   *model.GoogleInfo      `json:"google_info,omitempty"`
+	GitHubInfo                   *model.GitHubInfo      `json:"github_info,omitempty"`
+	OIDCInfo                     *model.OIDCInfo        `json:"oidc_info,omitempty"`
 }
 
 // NewAppData instantiates DynamoDB app data model from the general one.
 func NewAppData(data model.AppData) (AppData, error) {
 	if _, err := xid.FromString(data.ID()); err != nil {
-		log.Println("Incorrect AppID: ", data.ID())
+		logging.Error("Incorrect AppID: ", data.ID())
 		return AppData{}, model.ErrorWrongDataFormat
 	}
 	return AppData{appData: appData{
@@ -61,6 +64,10 @@ func NewAppData(data model.AppData) (AppData, error) {
 		TokenPayload:                 data.TokenPayload(),
 		RegistrationForbidden:        data.RegistrationForbidden(),
 		AnonymousRegistrationAllowed: data.AnonymousRegistrationAllowed(),
+		AppleInfo:                    data.AppleInfo(),
+		GoogleInfo:                   data.GoogleInfo(),
+		GitHubInfo:                   data.GitHubInfo(),
+		OIDCInfo:                     data.OIDCInfo(),
 	}}, nil
 }
 
@@ -68,7 +75,7 @@ func NewAppData(data model.AppData) (AppData, error) {
 func AppDataFromJSON(d []byte) (AppData, error) {
 	apd := appData{}
 	if err := json.Unmarshal(d, &apd); err != nil {
-		log.Println(err)
+		logging.Error(err)
 		return AppData{}, err
 	}
 	return AppData{appData: apd}, nil
@@ -79,7 +86,7 @@ func MakeAppData(id, secret string, active bool, name, description string, scope
 	refreshTokenLifespan, inviteTokenLifespan, tokenLifespan int64, tokenPayload []string, registrationForbidden bool, anonymousRegistrationAllowed bool,
 	tfaStatus model.TFAStatus, debugTFACode string, authzWay model.AuthorizationWay, authzModel, authzPolicy string, rolesWhitelist, rolesBlacklist []string, newUserDefaultRole string) (AppData, error) {
 	if _, err := xid.FromString(id); err != nil {
-		log.Println("Cannot create ID from the string representation:", err)
+		logging.Error("Cannot create ID from the string representation:", err)
 		return AppData{}, model.ErrorWrongDataFormat
 	}
 	return AppData{appData: appData{
@@ -185,6 +192,15 @@ func (ad *AppData) NewUserDefaultRole() string { return ad.appData.NewUserDefaul
 // AppleInfo implements model.AppData interface.
 func (ad *AppData) AppleInfo() *model.AppleInfo { return ad.appData.AppleInfo }
 
+// GoogleInfo implements model.AppData interface.
+func (ad *AppData) GoogleInfo() *model.GoogleInfo { return ad.appData.GoogleInfo }
+
+// GitHubInfo implements model.AppData interface.
+func (ad *AppData) GitHubInfo() *model.GitHubInfo { return ad.appData.GitHubInfo }
+
+// OIDCInfo implements model.AppData interface.
+func (ad *AppData) OIDCInfo() *model.OIDCInfo { return ad.appData.OIDCInfo }
+
 // SetSecret implements model.AppData interface.
 func (ad *AppData) SetSecret(secret string) {
 	if ad == nil {
@@ -202,6 +218,15 @@ func (ad *AppData) Sanitize() {
 	if ad.appData.AppleInfo != nil {
 		ad.appData.AppleInfo.ClientSecret = ""
 	}
+	if ad.appData.GoogleInfo != nil {
+		ad.appData.GoogleInfo.ClientSecret = ""
+	}
+	if ad.appData.GitHubInfo != nil {
+		ad.appData.GitHubInfo.ClientSecret = ""
+	}
+	if ad.appData.OIDCInfo != nil {
+		ad.appData.OIDCInfo.ClientSecret = ""
+	}
 
 	ad.appData.AuthorizationWay = ""
 	ad.appData.AuthorizationModel = ""
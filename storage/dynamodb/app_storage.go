@@ -2,11 +2,11 @@ package dynamodb
 
 import (
 	"encoding/json"
-	"log"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	"github.com/rs/xid"
 )
@@ -34,7 +34,7 @@ func (as *AppStorage) NewAppData() model.AppData {
 func (as *AppStorage) ensureTable() error {
 	exists, err := as.db.IsTableExists(appsTableName)
 	if err != nil {
-		log.Println("Error checking Applications table existence:", err)
+		logging.Error("Error checking Applications table existence:", err)
 		return err
 	}
 	if exists {
@@ -77,7 +77,7 @@ func (as *AppStorage) AppByID(id string) (model.AppData, error) {
 		},
 	})
 	if err != nil {
-		log.Println("Error getting application:", err)
+		logging.Error("Error getting application:", err)
 		return nil, ErrorInternalError
 	}
 
@@ -87,7 +87,7 @@ func (as *AppStorage) AppByID(id string) (model.AppData, error) {
 
 	appdata := appData{}
 	if err = dynamodbattribute.UnmarshalMap(result.Item, &appdata); err != nil {
-		log.Println("Error unmarshalling app data:", err)
+		logging.Error("Error unmarshalling app data:", err)
 		return nil, ErrorInternalError
 	}
 	return &AppData{appData: appdata}, nil
@@ -134,7 +134,7 @@ func (as *AppStorage) addNewApp(app model.AppData) (model.AppData, error) {
 
 	av, err := dynamodbattribute.MarshalMap(a)
 	if err != nil {
-		log.Println("Error marshalling app:", err)
+		logging.Error("Error marshalling app:", err)
 		return nil, ErrorInternalError
 	}
 
@@ -144,7 +144,7 @@ func (as *AppStorage) addNewApp(app model.AppData) (model.AppData, error) {
 	}
 
 	if _, err = as.db.C.PutItem(input); err != nil {
-		log.Println("Error putting app to storage:", err)
+		logging.Error("Error putting app to storage:", err)
 		return nil, ErrorInternalError
 	}
 	return a, nil
@@ -153,7 +153,7 @@ func (as *AppStorage) addNewApp(app model.AppData) (model.AppData, error) {
 // DisableApp disables app in DynamoDB storage.
 func (as *AppStorage) DisableApp(app model.AppData) error {
 	if _, err := xid.FromString(app.ID()); err != nil {
-		log.Println("Incorrect AppID: ", app.ID())
+		logging.Error("Incorrect AppID: ", app.ID())
 		return model.ErrorWrongDataFormat
 	}
 	input := &dynamodb.UpdateItemInput{
@@ -184,7 +184,7 @@ func (as *AppStorage) DisableApp(app model.AppData) error {
 	// _, err = as.db.C.DeleteItem(input)
 
 	if _, err := as.db.C.UpdateItem(input); err != nil {
-		log.Println("Error updating app:", err)
+		logging.Error("Error updating app:", err)
 		return ErrorInternalError
 	}
 	return nil
@@ -193,7 +193,7 @@ func (as *AppStorage) DisableApp(app model.AppData) error {
 // UpdateApp updates app in DynamoDB storage.
 func (as *AppStorage) UpdateApp(appID string, newApp model.AppData) (model.AppData, error) {
 	if _, err := xid.FromString(appID); err != nil {
-		log.Println("Incorrect appID: ", appID)
+		logging.Error("Incorrect appID: ", appID)
 		return nil, model.ErrorWrongDataFormat
 	}
 
@@ -209,7 +209,7 @@ func (as *AppStorage) UpdateApp(appID string, newApp model.AppData) (model.AppDa
 
 	oldAppData := &AppData{appData: appData{ID: appID}}
 	if err := as.DisableApp(oldAppData); err != nil {
-		log.Println("Error disabling old app:", err)
+		logging.Error("Error disabling old app:", err)
 		return nil, err
 	}
 
@@ -243,7 +243,7 @@ func (as *AppStorage) FetchApps(filterString string, skip, limit int) ([]model.A
 
 	result, err := as.db.C.Scan(scanInput)
 	if err != nil {
-		log.Println("Error querying for apps:", err)
+		logging.Error("Error querying for apps:", err)
 		return []model.AppData{}, 0, ErrorInternalError
 	}
 
@@ -254,7 +254,7 @@ func (as *AppStorage) FetchApps(filterString string, skip, limit int) ([]model.A
 		}
 		appData := appData{}
 		if err = dynamodbattribute.UnmarshalMap(result.Items[i], &appData); err != nil {
-			log.Println("Error unmarshalling app:", err)
+			logging.Error("Error unmarshalling app:", err)
 			return []model.AppData{}, 0, ErrorInternalError
 		}
 		apps[i] = &AppData{appData: appData}
@@ -287,7 +287,7 @@ func (as *AppStorage) TestDatabaseConnection() error {
 func (as *AppStorage) ImportJSON(data []byte) error {
 	apd := []appData{}
 	if err := json.Unmarshal(data, &apd); err != nil {
-		log.Println("Error unmarshalling app data:", err)
+		logging.Error("Error unmarshalling app data:", err)
 		return err
 	}
 	for _, a := range apd {
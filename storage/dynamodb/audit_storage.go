@@ -0,0 +1,161 @@
+package dynamodb
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// auditEventsTableName is a table name for audit events.
+const auditEventsTableName = "AuditEvents"
+
+// NewAuditStorage creates and provisions new DynamoDB audit storage.
+func NewAuditStorage(db *DB) (model.AuditStorage, error) {
+	as := &AuditStorage{db: db}
+	err := as.ensureTable()
+	return as, err
+}
+
+// AuditStorage implements audit storage interface.
+type AuditStorage struct {
+	db *DB
+}
+
+// AddAuditEvent saves new event.
+func (as *AuditStorage) AddAuditEvent(event model.AuditEvent) (model.AuditEvent, error) {
+	event.ID = xid.New().String()
+
+	item, err := dynamodbattribute.MarshalMap(event)
+	if err != nil {
+		logging.Error("Error marshalling audit event:", err)
+		return model.AuditEvent{}, ErrorInternalError
+	}
+
+	if _, err = as.db.C.PutItem(&dynamodb.PutItemInput{
+		Item:                item,
+		TableName:           aws.String(auditEventsTableName),
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	}); err != nil {
+		logging.Error("Error putting audit event to database:", err)
+		return model.AuditEvent{}, ErrorInternalError
+	}
+	return event, nil
+}
+
+// FetchAuditEvents returns events matching the filter, newest first.
+func (as *AuditStorage) FetchAuditEvents(filter model.AuditEventFilter, skip, limit int) ([]model.AuditEvent, int, error) {
+	scanInput := &dynamodb.ScanInput{
+		TableName: aws.String(auditEventsTableName),
+	}
+
+	conditions := []string{}
+	values := map[string]*dynamodb.AttributeValue{}
+	addCondition := func(condition, name string, value *dynamodb.AttributeValue) {
+		conditions = append(conditions, condition)
+		values[name] = value
+	}
+	if filter.AdminID != "" {
+		addCondition("admin_id = :admin_id", ":admin_id", &dynamodb.AttributeValue{S: aws.String(filter.AdminID)})
+	}
+	if filter.Action != "" {
+		// Action is a reserved word in DynamoDB.
+		addCondition("#action = :action", ":action", &dynamodb.AttributeValue{S: aws.String(filter.Action)})
+		scanInput.ExpressionAttributeNames = map[string]*string{"#action": aws.String("action")}
+	}
+	if filter.TargetType != "" {
+		addCondition("target_type = :target_type", ":target_type", &dynamodb.AttributeValue{S: aws.String(filter.TargetType)})
+	}
+	if filter.TargetID != "" {
+		addCondition("target_id = :target_id", ":target_id", &dynamodb.AttributeValue{S: aws.String(filter.TargetID)})
+	}
+	if filter.From != 0 {
+		addCondition("created_at >= :from", ":from", &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(filter.From, 10))})
+	}
+	if filter.To != 0 {
+		addCondition("created_at <= :to", ":to", &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(filter.To, 10))})
+	}
+	if len(conditions) > 0 {
+		filterExpression := conditions[0]
+		for _, c := range conditions[1:] {
+			filterExpression += " AND " + c
+		}
+		scanInput.FilterExpression = aws.String(filterExpression)
+		scanInput.ExpressionAttributeValues = values
+	}
+
+	events := []model.AuditEvent{}
+	if err := as.db.C.ScanPages(scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageEvents := []model.AuditEvent{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageEvents); err != nil {
+			logging.Error("Error unmarshalling audit events:", err)
+			return false
+		}
+		events = append(events, pageEvents...)
+		return true
+	}); err != nil {
+		logging.Error("Error querying for audit events:", err)
+		return []model.AuditEvent{}, 0, ErrorInternalError
+	}
+
+	// IDs are xids, which order events created within the same second.
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].CreatedAt != events[j].CreatedAt {
+			return events[i].CreatedAt > events[j].CreatedAt
+		}
+		return events[i].ID > events[j].ID
+	})
+
+	total := len(events)
+	if skip > total {
+		skip = total
+	}
+	events = events[skip:]
+	if limit != 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events, total, nil
+}
+
+// Close does nothing here.
+func (as *AuditStorage) Close() {}
+
+// ensureTable ensures that audit storage table exists in the database.
+func (as *AuditStorage) ensureTable() error {
+	exists, err := as.db.IsTableExists(auditEventsTableName)
+	if err != nil {
+		logging.Error("Error checking for audit events table existence:", err)
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	createTableInput := &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		BillingMode: aws.String("PAY_PER_REQUEST"),
+		TableName:   aws.String(auditEventsTableName),
+	}
+
+	if _, err = as.db.C.CreateTable(createTableInput); err != nil {
+		logging.Error("Error creating audit events table:", err)
+		return err
+	}
+	return nil
+}
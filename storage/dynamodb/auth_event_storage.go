@@ -0,0 +1,161 @@
+package dynamodb
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// authEventsTableName is a table name for auth events.
+const authEventsTableName = "AuthEvents"
+
+// NewAuthEventStorage creates and provisions new DynamoDB auth event storage.
+func NewAuthEventStorage(db *DB) (model.AuthEventStorage, error) {
+	aes := &AuthEventStorage{db: db}
+	err := aes.ensureTable()
+	return aes, err
+}
+
+// AuthEventStorage implements auth event storage interface.
+type AuthEventStorage struct {
+	db *DB
+}
+
+// AddAuthEvent saves new event.
+func (aes *AuthEventStorage) AddAuthEvent(event model.AuthEvent) (model.AuthEvent, error) {
+	event.ID = xid.New().String()
+
+	item, err := dynamodbattribute.MarshalMap(event)
+	if err != nil {
+		logging.Error("Error marshalling auth event:", err)
+		return model.AuthEvent{}, ErrorInternalError
+	}
+
+	if _, err = aes.db.C.PutItem(&dynamodb.PutItemInput{
+		Item:                item,
+		TableName:           aws.String(authEventsTableName),
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	}); err != nil {
+		logging.Error("Error putting auth event to database:", err)
+		return model.AuthEvent{}, ErrorInternalError
+	}
+	return event, nil
+}
+
+// FetchAuthEvents returns events matching the filter, newest first.
+func (aes *AuthEventStorage) FetchAuthEvents(filter model.AuthEventFilter, skip, limit int) ([]model.AuthEvent, int, error) {
+	scanInput := &dynamodb.ScanInput{
+		TableName: aws.String(authEventsTableName),
+	}
+
+	conditions := []string{}
+	values := map[string]*dynamodb.AttributeValue{}
+	addCondition := func(condition, name string, value *dynamodb.AttributeValue) {
+		conditions = append(conditions, condition)
+		values[name] = value
+	}
+	if filter.UserID != "" {
+		addCondition("user_id = :user_id", ":user_id", &dynamodb.AttributeValue{S: aws.String(filter.UserID)})
+	}
+	if filter.AppID != "" {
+		addCondition("app_id = :app_id", ":app_id", &dynamodb.AttributeValue{S: aws.String(filter.AppID)})
+	}
+	if filter.Type != "" {
+		// Type is a reserved word in DynamoDB.
+		addCondition("#type = :type", ":type", &dynamodb.AttributeValue{S: aws.String(string(filter.Type))})
+		scanInput.ExpressionAttributeNames = map[string]*string{"#type": aws.String("type")}
+	}
+	if filter.Success != nil {
+		addCondition("success = :success", ":success", &dynamodb.AttributeValue{BOOL: aws.Bool(*filter.Success)})
+	}
+	if filter.From != 0 {
+		addCondition("created_at >= :from", ":from", &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(filter.From, 10))})
+	}
+	if filter.To != 0 {
+		addCondition("created_at <= :to", ":to", &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(filter.To, 10))})
+	}
+	if len(conditions) > 0 {
+		filterExpression := conditions[0]
+		for _, c := range conditions[1:] {
+			filterExpression += " AND " + c
+		}
+		scanInput.FilterExpression = aws.String(filterExpression)
+		scanInput.ExpressionAttributeValues = values
+	}
+
+	events := []model.AuthEvent{}
+	if err := aes.db.C.ScanPages(scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageEvents := []model.AuthEvent{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageEvents); err != nil {
+			logging.Error("Error unmarshalling auth events:", err)
+			return false
+		}
+		events = append(events, pageEvents...)
+		return true
+	}); err != nil {
+		logging.Error("Error querying for auth events:", err)
+		return []model.AuthEvent{}, 0, ErrorInternalError
+	}
+
+	// IDs are xids, which order events created within the same second.
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].CreatedAt != events[j].CreatedAt {
+			return events[i].CreatedAt > events[j].CreatedAt
+		}
+		return events[i].ID > events[j].ID
+	})
+
+	total := len(events)
+	if skip > total {
+		skip = total
+	}
+	events = events[skip:]
+	if limit != 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events, total, nil
+}
+
+// Close does nothing here.
+func (aes *AuthEventStorage) Close() {}
+
+// ensureTable ensures that auth event storage table exists in the database.
+func (aes *AuthEventStorage) ensureTable() error {
+	exists, err := aes.db.IsTableExists(authEventsTableName)
+	if err != nil {
+		logging.Error("Error checking for auth events table existence:", err)
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	createTableInput := &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		BillingMode: aws.String("PAY_PER_REQUEST"),
+		TableName:   aws.String(authEventsTableName),
+	}
+
+	if _, err = aes.db.C.CreateTable(createTableInput); err != nil {
+		logging.Error("Error creating auth events table:", err)
+		return err
+	}
+	return nil
+}
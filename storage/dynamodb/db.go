@@ -2,13 +2,18 @@ package dynamodb
 
 import (
 	"context"
-	"log"
+	"reflect"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/metrics"
+	"github.com/madappgang/identifo/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // NewDB creates new database connection.
@@ -19,11 +24,30 @@ func NewDB(endpoint string, region string) (*DB, error) {
 	}
 	sess, err := session.NewSession(config)
 	if err != nil {
-		log.Println(err)
+		logging.Error(err)
 		return nil, err
 	}
 
-	return &DB{C: dynamodb.New(sess)}, nil
+	c := dynamodb.New(sess)
+	c.Handlers.Build.PushFront(startRequestSpan)
+	c.Handlers.Complete.PushBack(observeRequest)
+	return &DB{C: c}, nil
+}
+
+// startRequestSpan starts span of the request, keeping it in the request context.
+func startRequestSpan(r *request.Request) {
+	if tracing.Enabled() {
+		span := tracing.StartStorageSpan(r.Context(), "dynamodb", r.Operation.Name)
+		r.SetContext(trace.ContextWithSpan(r.Context(), span))
+	}
+}
+
+// observeRequest reports latency of the completed request to metrics, and ends its span.
+func observeRequest(r *request.Request) {
+	metrics.ObserveStorage("dynamodb", r.Operation.Name, time.Since(r.Time), r.Error)
+	if span := trace.SpanFromContext(r.Context()); span.SpanContext().IsValid() {
+		tracing.End(span, r.Error)
+	}
 }
 
 // DB represents connection to AWS DynamoDB service or local instance.
@@ -31,6 +55,32 @@ type DB struct {
 	C *dynamodb.DynamoDB
 }
 
+// UseTablePrefix makes every request go to the table with prefixed name,
+// so storages of different tenants can share one database.
+func (db *DB) UseTablePrefix(prefix string) {
+	if len(prefix) == 0 {
+		return
+	}
+	stringPtrType := reflect.TypeOf((*string)(nil))
+
+	db.C.Handlers.Build.PushFront(func(r *request.Request) {
+		params := reflect.ValueOf(r.Params)
+		if params.Kind() != reflect.Ptr || params.IsNil() || params.Elem().Kind() != reflect.Struct {
+			return
+		}
+		tableName := params.Elem().FieldByName("TableName")
+		if !tableName.IsValid() || tableName.Type() != stringPtrType || tableName.IsNil() {
+			return
+		}
+
+		// Input is copied, as callers (and paginators) reuse it for the following requests.
+		input := reflect.New(params.Elem().Type())
+		input.Elem().Set(params.Elem())
+		input.Elem().FieldByName("TableName").Set(reflect.ValueOf(aws.String(prefix + tableName.Elem().String())))
+		r.Params = input.Interface()
+	})
+}
+
 // IsTableExists checks if table exists.
 func (db *DB) IsTableExists(table string) (bool, error) {
 	input := &dynamodb.DescribeTableInput{
@@ -46,7 +96,7 @@ func (db *DB) IsTableExists(table string) (bool, error) {
 		//if table not exists - create table
 	}
 	if err != nil {
-		log.Println(err)
+		logging.Error(err)
 		return false, err
 	}
 
@@ -0,0 +1,210 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/madappgang/identifo/model"
+)
+
+// fakeDynamoDB serves the subset of DynamoDB API the storages need for the tests:
+// tables are created on demand, items are kept by their hash and range keys,
+// and queries support a single equality key condition.
+type fakeDynamoDB struct {
+	mu     sync.Mutex
+	keys   map[string][]string
+	items  map[string]map[string]map[string]interface{}
+	tables []string
+}
+
+func newFakeDynamoDB(t *testing.T) (*fakeDynamoDB, string) {
+	f := &fakeDynamoDB{
+		keys:  make(map[string][]string),
+		items: make(map[string]map[string]map[string]interface{}),
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv.URL
+}
+
+// requestedTables returns names of all tables requested so far.
+func (f *fakeDynamoDB) requestedTables() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.tables...)
+}
+
+func (f *fakeDynamoDB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		TableName                 string
+		KeySchema                 []struct{ AttributeName string }
+		Key                       map[string]interface{}
+		Item                      map[string]interface{}
+		KeyConditionExpression    string
+		ExpressionAttributeValues map[string]interface{}
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(in.TableName) > 0 {
+		f.tables = append(f.tables, in.TableName)
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+
+	items, exists := f.items[in.TableName]
+	operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+	if !exists && operation != "CreateTable" && len(in.TableName) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"__type":  "com.amazonaws.dynamodb.v20120810#ResourceNotFoundException",
+			"message": "Requested resource not found",
+		})
+		return
+	}
+
+	out := map[string]interface{}{}
+	switch operation {
+	case "CreateTable":
+		for _, k := range in.KeySchema {
+			f.keys[in.TableName] = append(f.keys[in.TableName], k.AttributeName)
+		}
+		f.items[in.TableName] = make(map[string]map[string]interface{})
+	case "DescribeTable":
+		out["Table"] = map[string]string{"TableName": in.TableName, "TableStatus": "ACTIVE"}
+	case "PutItem":
+		items[f.itemKey(in.TableName, in.Item)] = in.Item
+	case "GetItem":
+		if item, ok := items[f.itemKey(in.TableName, in.Key)]; ok {
+			out["Item"] = item
+		}
+	case "DeleteItem":
+		delete(items, f.itemKey(in.TableName, in.Key))
+	case "Query":
+		condition := strings.SplitN(in.KeyConditionExpression, " = ", 2)
+		found := []interface{}{}
+		for _, item := range items {
+			if len(condition) == 2 && attributeEqual(item[condition[0]], in.ExpressionAttributeValues[condition[1]]) {
+				found = append(found, item)
+			}
+		}
+		out["Items"] = found
+		out["Count"] = len(found)
+	}
+	json.NewEncoder(w).Encode(out)
+}
+
+func (f *fakeDynamoDB) itemKey(table string, item map[string]interface{}) string {
+	key := map[string]interface{}{}
+	for _, k := range f.keys[table] {
+		key[k] = item[k]
+	}
+	b, _ := json.Marshal(key)
+	return string(b)
+}
+
+func attributeEqual(a, b interface{}) bool {
+	ab, _ := json.Marshal(a)
+	bb, _ := json.Marshal(b)
+	return a != nil && string(ab) == string(bb)
+}
+
+func newTestDB(t *testing.T, endpoint, prefix string) *DB {
+	db, err := NewDB(endpoint, "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.C.Config.Credentials = credentials.NewStaticCredentials("id", "secret", "")
+	db.UseTablePrefix(prefix)
+	return db
+}
+
+func TestUseTablePrefix(t *testing.T) {
+	const prefix = "tenant_"
+	f, endpoint := newFakeDynamoDB(t)
+	db := newTestDB(t, endpoint, prefix)
+
+	storages := map[string]func(*DB) error{
+		"app":               func(db *DB) error { _, err := NewAppStorage(db); return err },
+		"user":              func(db *DB) error { _, err := NewUserStorage(db); return err },
+		"token":             func(db *DB) error { _, err := NewTokenStorage(db); return err },
+		"token blacklist":   func(db *DB) error { _, err := NewTokenBlacklist(db); return err },
+		"verification code": func(db *DB) error { _, err := NewVerificationCodeStorage(db); return err },
+		"invite":            func(db *DB) error { _, err := NewInviteStorage(db); return err },
+		"user session":      func(db *DB) error { _, err := NewUserSessionStorage(db); return err },
+		"admin":             func(db *DB) error { _, err := NewAdminStorage(db); return err },
+		"audit":             func(db *DB) error { _, err := NewAuditStorage(db); return err },
+		"auth event":        func(db *DB) error { _, err := NewAuthEventStorage(db); return err },
+		"webhook":           func(db *DB) error { _, err := NewWebhookStorage(db); return err },
+		"organization":      func(db *DB) error { _, err := NewOrganizationStorage(db); return err },
+		"scim token":        func(db *DB) error { _, err := NewSCIMTokenStorage(db); return err },
+		"policy":            func(db *DB) error { _, err := NewPolicyStorage(db); return err },
+		"role":              func(db *DB) error { _, err := NewRoleStorage(db); return err },
+	}
+	for name, newStorage := range storages {
+		if err := newStorage(db); err != nil {
+			t.Errorf("new %s storage err = %v, want nil", name, err)
+		}
+	}
+
+	tables := f.requestedTables()
+	if len(tables) == 0 {
+		t.Fatal("no tables requested")
+	}
+	for _, table := range tables {
+		if !strings.HasPrefix(table, prefix) {
+			t.Errorf("table %q is requested without prefix %q", table, prefix)
+		}
+		if strings.HasPrefix(table, prefix+prefix) {
+			t.Errorf("table %q is prefixed twice", table)
+		}
+	}
+}
+
+func TestUseTablePrefixIsolatesTenants(t *testing.T) {
+	const username = "john@example.com"
+	_, endpoint := newFakeDynamoDB(t)
+
+	first, err := NewUserStorage(newTestDB(t, endpoint, "first_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewUserStorage(newTestDB(t, endpoint, "second_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	firstUser, err := first.AddUserByNameAndPassword(username, "FirstPassword1!", "user", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondUser, err := second.AddUserByNameAndPassword(username, "SecondPassword1!", "user", false)
+	if err != nil {
+		t.Fatalf("second tenant user err = %v, want nil", err)
+	}
+	if firstUser.ID() == secondUser.ID() {
+		t.Fatalf("tenants share user %s", firstUser.ID())
+	}
+
+	if u, err := first.UserByNamePassword(username, "FirstPassword1!"); err != nil || u.ID() != firstUser.ID() {
+		t.Errorf("first tenant user = %v, %v, want %s", u, err, firstUser.ID())
+	}
+	if u, err := second.UserByNamePassword(username, "SecondPassword1!"); err != nil || u.ID() != secondUser.ID() {
+		t.Errorf("second tenant user = %v, %v, want %s", u, err, secondUser.ID())
+	}
+	if _, err := second.UserByNamePassword(username, "FirstPassword1!"); err != model.ErrUserNotFound {
+		t.Errorf("second tenant login with first tenant password err = %v, want %v", err, model.ErrUserNotFound)
+	}
+	if _, err := first.UserByID(secondUser.ID()); err == nil {
+		t.Errorf("first tenant finds user %s of the second tenant", secondUser.ID())
+	}
+}
@@ -0,0 +1,95 @@
+package dynamodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// testTablesTimeout limits the check of the tables.
+const testTablesTimeout = 5 * time.Second
+
+// testTables checks that the tables exist and can be described.
+func (db *DB) testTables(tables ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), testTablesTimeout)
+	defer cancel()
+
+	for _, table := range tables {
+		if _, err := db.C.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (us *UserStorage) TestDatabaseConnection() error {
+	return us.db.testTables(usersTableName, usersFederatedIDTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ts *TokenStorage) TestDatabaseConnection() error {
+	return ts.db.testTables(tokensTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (tb *TokenBlacklist) TestDatabaseConnection() error {
+	return tb.db.testTables(blacklistedTokensTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (vcs *VerificationCodeStorage) TestDatabaseConnection() error {
+	return vcs.db.testTables(verificationCodesTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (is *InviteStorage) TestDatabaseConnection() error {
+	return is.db.testTables(invitesTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ss *UserSessionStorage) TestDatabaseConnection() error {
+	return ss.db.testTables(userSessionsTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (as *AdminStorage) TestDatabaseConnection() error {
+	return as.db.testTables(adminsTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (as *AuditStorage) TestDatabaseConnection() error {
+	return as.db.testTables(auditEventsTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (aes *AuthEventStorage) TestDatabaseConnection() error {
+	return aes.db.testTables(authEventsTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ws *WebhookStorage) TestDatabaseConnection() error {
+	return ws.db.testTables(webhooksTableName, webhookDeliveriesTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (os *OrganizationStorage) TestDatabaseConnection() error {
+	return os.db.testTables(organizationsTableName, organizationMembersTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ss *SCIMTokenStorage) TestDatabaseConnection() error {
+	return ss.db.testTables(scimTokensTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ps *PolicyStorage) TestDatabaseConnection() error {
+	return ps.db.testTables(policyRulesTableName)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (rs *RoleStorage) TestDatabaseConnection() error {
+	return rs.db.testTables(rolesTableName, userRolesTableName)
+}
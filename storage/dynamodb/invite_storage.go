@@ -0,0 +1,210 @@
+package dynamodb
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// invitesTableName is a table name for invites.
+const invitesTableName = "Invites"
+
+// NewInviteStorage creates and provisions new DynamoDB invite storage.
+func NewInviteStorage(db *DB) (model.InviteStorage, error) {
+	is := &InviteStorage{db: db}
+	err := is.ensureTable()
+	return is, err
+}
+
+// InviteStorage implements invite storage interface.
+type InviteStorage struct {
+	db *DB
+}
+
+// AddInvite saves new invite.
+func (is *InviteStorage) AddInvite(invite model.Invite) (model.Invite, error) {
+	invite.ID = xid.New().String()
+
+	item, err := dynamodbattribute.MarshalMap(invite)
+	if err != nil {
+		logging.Error("Error marshalling invite:", err)
+		return model.Invite{}, ErrorInternalError
+	}
+
+	if _, err = is.db.C.PutItem(&dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(invitesTableName),
+	}); err != nil {
+		logging.Error("Error putting invite to database:", err)
+		return model.Invite{}, ErrorInternalError
+	}
+	return invite, nil
+}
+
+// InviteByID returns invite by its ID.
+func (is *InviteStorage) InviteByID(id string) (model.Invite, error) {
+	var invite model.Invite
+
+	result, err := is.db.C.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(invitesTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+	})
+	if err != nil {
+		logging.Error("Error getting invite from DynamoDB:", err)
+		return invite, ErrorInternalError
+	}
+	if result.Item == nil {
+		return invite, model.ErrInviteNotFound
+	}
+
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &invite); err != nil {
+		logging.Error("Error unmarshalling invite:", err)
+		return invite, ErrorInternalError
+	}
+	return invite, nil
+}
+
+// FetchInvites fetches invites, optionally filtered by app ID and email, newest first.
+func (is *InviteStorage) FetchInvites(appID, email string, skip, limit int) ([]model.Invite, int, error) {
+	scanInput := &dynamodb.ScanInput{
+		TableName: aws.String(invitesTableName),
+	}
+
+	filter := ""
+	values := map[string]*dynamodb.AttributeValue{}
+	if appID != "" {
+		filter = "app_id = :app_id"
+		values[":app_id"] = &dynamodb.AttributeValue{S: aws.String(appID)}
+	}
+	if email != "" {
+		if filter != "" {
+			filter += " AND "
+		}
+		filter += "email = :email"
+		values[":email"] = &dynamodb.AttributeValue{S: aws.String(email)}
+	}
+	if filter != "" {
+		scanInput.FilterExpression = aws.String(filter)
+		scanInput.ExpressionAttributeValues = values
+	}
+
+	invites := []model.Invite{}
+	if err := is.db.C.ScanPages(scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageInvites := []model.Invite{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageInvites); err != nil {
+			logging.Error("Error unmarshalling invites:", err)
+			return false
+		}
+		invites = append(invites, pageInvites...)
+		return true
+	}); err != nil {
+		logging.Error("Error querying for invites:", err)
+		return []model.Invite{}, 0, ErrorInternalError
+	}
+
+	sort.Slice(invites, func(i, j int) bool { return invites[i].CreatedAt > invites[j].CreatedAt })
+
+	total := len(invites)
+	if skip > total {
+		skip = total
+	}
+	invites = invites[skip:]
+	if limit != 0 && len(invites) > limit {
+		invites = invites[:limit]
+	}
+	return invites, total, nil
+}
+
+// RevokeInvite marks invite as revoked.
+func (is *InviteStorage) RevokeInvite(id string) error {
+	_, err := is.db.C.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(invitesTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+		ConditionExpression: aws.String("attribute_exists(id)"),
+		UpdateExpression:    aws.String("set revoked = :t"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":t": {BOOL: aws.Bool(true)},
+		},
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return model.ErrInviteNotFound
+	}
+	return err
+}
+
+// ConsumeInvite marks valid invite as used by the user.
+// Conditional update guarantees that the invite cannot be used twice.
+func (is *InviteStorage) ConsumeInvite(id, userID string) error {
+	now := time.Now().Unix()
+
+	_, err := is.db.C.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(invitesTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+		ConditionExpression: aws.String("attribute_exists(id) AND attribute_not_exists(used_at) AND (attribute_not_exists(revoked) OR revoked = :f) AND expires_at > :now"),
+		UpdateExpression:    aws.String("set used_by = :u, used_at = :now"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":f":   {BOOL: aws.Bool(false)},
+			":u":   {S: aws.String(userID)},
+			":now": {N: aws.String(strconv.FormatInt(now, 10))},
+		},
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		if _, err := is.InviteByID(id); err != nil {
+			return err
+		}
+		return model.ErrInviteNotValid
+	}
+	return err
+}
+
+// ensureTable ensures that invite storage table exists in the database.
+func (is *InviteStorage) ensureTable() error {
+	exists, err := is.db.IsTableExists(invitesTableName)
+	if err != nil {
+		logging.Error("Error checking for invites table existence:", err)
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	createTableInput := &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		BillingMode: aws.String("PAY_PER_REQUEST"),
+		TableName:   aws.String(invitesTableName),
+	}
+
+	if _, err = is.db.C.CreateTable(createTableInput); err != nil {
+		logging.Error("Error creating invites table:", err)
+		return err
+	}
+	return nil
+}
+
+// Close does nothing here.
+func (is *InviteStorage) Close() {}
@@ -0,0 +1,320 @@
+package dynamodb
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+const (
+	// organizationsTableName is a table name for organizations.
+	organizationsTableName = "Organizations"
+	// organizationMembersTableName is a table name for organization members.
+	organizationMembersTableName = "OrganizationMembers"
+)
+
+// NewOrganizationStorage creates and provisions new DynamoDB organization storage.
+func NewOrganizationStorage(db *DB) (model.OrganizationStorage, error) {
+	os := &OrganizationStorage{db: db}
+	for _, table := range []string{organizationsTableName, organizationMembersTableName} {
+		if err := os.ensureTable(table); err != nil {
+			return os, err
+		}
+	}
+	return os, nil
+}
+
+// OrganizationStorage implements organization storage interface.
+type OrganizationStorage struct {
+	db *DB
+}
+
+// organizationMemberData is a member item, keyed by "<organization ID>/<user ID>".
+type organizationMemberData struct {
+	ID       string `json:"id"`
+	OrgID    string `json:"org_id"`
+	UserID   string `json:"user_id"`
+	Role     string `json:"role"`
+	JoinedAt int64  `json:"joined_at"`
+}
+
+func (md organizationMemberData) member() model.OrganizationMember {
+	return model.OrganizationMember{OrgID: md.OrgID, UserID: md.UserID, Role: md.Role, JoinedAt: md.JoinedAt}
+}
+
+func memberID(orgID, userID string) string {
+	return orgID + "/" + userID
+}
+
+// AddOrganization saves new organization.
+func (os *OrganizationStorage) AddOrganization(org model.Organization) (model.Organization, error) {
+	org.ID = xid.New().String()
+	if err := os.put(organizationsTableName, org, "attribute_not_exists(id)", nil); err != nil {
+		return model.Organization{}, err
+	}
+	return org, nil
+}
+
+// OrganizationByID returns organization by ID.
+func (os *OrganizationStorage) OrganizationByID(id string) (model.Organization, error) {
+	var org model.Organization
+	err := os.get(organizationsTableName, id, &org, model.ErrOrganizationNotFound)
+	return org, err
+}
+
+// FetchOrganizations returns organizations which name contains search string, oldest first.
+func (os *OrganizationStorage) FetchOrganizations(search string, skip, limit int) ([]model.Organization, int, error) {
+	orgs := []model.Organization{}
+	if err := os.db.C.ScanPages(&dynamodb.ScanInput{
+		TableName: aws.String(organizationsTableName),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageOrgs := []model.Organization{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageOrgs); err != nil {
+			logging.Error("Error unmarshalling organizations:", err)
+			return false
+		}
+		for _, org := range pageOrgs {
+			if strings.Contains(strings.ToLower(org.Name), strings.ToLower(search)) {
+				orgs = append(orgs, org)
+			}
+		}
+		return true
+	}); err != nil {
+		logging.Error("Error querying for organizations:", err)
+		return []model.Organization{}, 0, ErrorInternalError
+	}
+
+	// IDs are xids, which are sortable by creation time.
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].ID < orgs[j].ID })
+
+	total := len(orgs)
+	if skip > total {
+		skip = total
+	}
+	orgs = orgs[skip:]
+	if limit != 0 && len(orgs) > limit {
+		orgs = orgs[:limit]
+	}
+	return orgs, total, nil
+}
+
+// UpdateOrganization replaces stored organization.
+func (os *OrganizationStorage) UpdateOrganization(org model.Organization) (model.Organization, error) {
+	if err := os.put(organizationsTableName, org, "attribute_exists(id)", model.ErrOrganizationNotFound); err != nil {
+		return model.Organization{}, err
+	}
+	return org, nil
+}
+
+// DeleteOrganization deletes the organization with its members.
+func (os *OrganizationStorage) DeleteOrganization(id string) error {
+	if err := os.delete(organizationsTableName, id, model.ErrOrganizationNotFound); err != nil {
+		return err
+	}
+
+	members, err := os.FetchMembers(id)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if err := os.delete(organizationMembersTableName, memberID(m.OrgID, m.UserID), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetMember adds the member or changes its role.
+func (os *OrganizationStorage) SetMember(member model.OrganizationMember) (model.OrganizationMember, error) {
+	if _, err := os.OrganizationByID(member.OrgID); err != nil {
+		return model.OrganizationMember{}, err
+	}
+
+	existing, err := os.Member(member.OrgID, member.UserID)
+	if err == nil {
+		member.JoinedAt = existing.JoinedAt
+	} else if err != model.ErrOrganizationMemberNotFound {
+		return model.OrganizationMember{}, err
+	}
+
+	md := organizationMemberData{
+		ID:       memberID(member.OrgID, member.UserID),
+		OrgID:    member.OrgID,
+		UserID:   member.UserID,
+		Role:     member.Role,
+		JoinedAt: member.JoinedAt,
+	}
+	if err := os.put(organizationMembersTableName, md, "", nil); err != nil {
+		return model.OrganizationMember{}, err
+	}
+	return member, nil
+}
+
+// Member returns the membership of the user in the organization.
+func (os *OrganizationStorage) Member(orgID, userID string) (model.OrganizationMember, error) {
+	var md organizationMemberData
+	if err := os.get(organizationMembersTableName, memberID(orgID, userID), &md, model.ErrOrganizationMemberNotFound); err != nil {
+		return model.OrganizationMember{}, err
+	}
+	return md.member(), nil
+}
+
+// FetchMembers returns organization members, earliest joined first.
+func (os *OrganizationStorage) FetchMembers(orgID string) ([]model.OrganizationMember, error) {
+	return os.scanMembers("org_id", orgID)
+}
+
+// UserMemberships returns all memberships of the user.
+func (os *OrganizationStorage) UserMemberships(userID string) ([]model.OrganizationMember, error) {
+	return os.scanMembers("user_id", userID)
+}
+
+// RemoveMember removes the user from the organization.
+func (os *OrganizationStorage) RemoveMember(orgID, userID string) error {
+	return os.delete(organizationMembersTableName, memberID(orgID, userID), model.ErrOrganizationMemberNotFound)
+}
+
+// Close does nothing here.
+func (os *OrganizationStorage) Close() {}
+
+func (os *OrganizationStorage) scanMembers(field, value string) ([]model.OrganizationMember, error) {
+	members := []model.OrganizationMember{}
+	if err := os.db.C.ScanPages(&dynamodb.ScanInput{
+		TableName:        aws.String(organizationMembersTableName),
+		FilterExpression: aws.String(field + " = :value"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":value": {S: aws.String(value)},
+		},
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageMembers := []organizationMemberData{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageMembers); err != nil {
+			logging.Error("Error unmarshalling organization members:", err)
+			return false
+		}
+		for _, md := range pageMembers {
+			members = append(members, md.member())
+		}
+		return true
+	}); err != nil {
+		logging.Error("Error querying for organization members:", err)
+		return []model.OrganizationMember{}, ErrorInternalError
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].JoinedAt < members[j].JoinedAt })
+	return members, nil
+}
+
+// get loads the item by ID into value, returning errNotFound for missing items.
+func (os *OrganizationStorage) get(table, id string, value interface{}, errNotFound error) error {
+	result, err := os.db.C.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+	})
+	if err != nil {
+		logging.Errorf("Error getting item from %s: %s\n", table, err)
+		return ErrorInternalError
+	}
+	if result.Item == nil {
+		return errNotFound
+	}
+
+	if err = dynamodbattribute.UnmarshalMap(result.Item, value); err != nil {
+		logging.Errorf("Error unmarshalling item from %s: %s\n", table, err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+// put saves the item, returning errNotFound if the condition fails. Empty condition overwrites the item.
+func (os *OrganizationStorage) put(table string, value interface{}, condition string, errNotFound error) error {
+	item, err := dynamodbattribute.MarshalMap(value)
+	if err != nil {
+		logging.Errorf("Error marshalling item for %s: %s\n", table, err)
+		return ErrorInternalError
+	}
+
+	input := &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(table),
+	}
+	if condition != "" {
+		input.ConditionExpression = aws.String(condition)
+	}
+
+	_, err = os.db.C.PutItem(input)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException && errNotFound != nil {
+		return errNotFound
+	}
+	if err != nil {
+		logging.Errorf("Error putting item to %s: %s\n", table, err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+// delete deletes the item by ID, returning errNotFound for missing items if it is not nil.
+func (os *OrganizationStorage) delete(table, id string, errNotFound error) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+	}
+	if errNotFound != nil {
+		input.ConditionExpression = aws.String("attribute_exists(id)")
+	}
+
+	_, err := os.db.C.DeleteItem(input)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return errNotFound
+	}
+	if err != nil {
+		logging.Errorf("Error deleting item from %s: %s\n", table, err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+// ensureTable ensures that the organization storage table exists in the database.
+func (os *OrganizationStorage) ensureTable(table string) error {
+	exists, err := os.db.IsTableExists(table)
+	if err != nil {
+		logging.Errorf("Error checking for %s table existence: %s\n", table, err)
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	createTableInput := &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		BillingMode: aws.String("PAY_PER_REQUEST"),
+		TableName:   aws.String(table),
+	}
+
+	if _, err = os.db.C.CreateTable(createTableInput); err != nil {
+		logging.Errorf("Error creating %s table: %s\n", table, err)
+		return err
+	}
+	return nil
+}
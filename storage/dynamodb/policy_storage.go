@@ -0,0 +1,176 @@
+package dynamodb
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// policyRulesTableName is a table name for policy rules.
+const policyRulesTableName = "PolicyRules"
+
+// NewPolicyStorage creates and provisions new DynamoDB policy storage.
+func NewPolicyStorage(db *DB) (model.PolicyStorage, error) {
+	ps := &PolicyStorage{db: db}
+	err := ps.ensureTable()
+	return ps, err
+}
+
+// PolicyStorage implements policy storage interface.
+// Rules are loaded once per app enforcer, so they are fetched with the table scan.
+type PolicyStorage struct {
+	db *DB
+}
+
+// AddPolicyRule saves new rule.
+func (ps *PolicyStorage) AddPolicyRule(rule model.PolicyRule) (model.PolicyRule, error) {
+	rule.ID = xid.New().String()
+	if err := ps.put(rule, "attribute_not_exists(id)"); err != nil {
+		return model.PolicyRule{}, err
+	}
+	return rule, nil
+}
+
+// PolicyRuleByID returns rule by its ID.
+func (ps *PolicyStorage) PolicyRuleByID(id string) (model.PolicyRule, error) {
+	result, err := ps.db.C.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(policyRulesTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+	})
+	if err != nil {
+		logging.Error("Error getting policy rule:", err)
+		return model.PolicyRule{}, ErrorInternalError
+	}
+	if result.Item == nil {
+		return model.PolicyRule{}, model.ErrPolicyRuleNotFound
+	}
+
+	var rule model.PolicyRule
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &rule); err != nil {
+		logging.Error("Error unmarshalling policy rule:", err)
+		return model.PolicyRule{}, ErrorInternalError
+	}
+	return rule, nil
+}
+
+// FetchPolicyRules returns all rules of the app, oldest first.
+func (ps *PolicyStorage) FetchPolicyRules(appID string) ([]model.PolicyRule, error) {
+	rules := []model.PolicyRule{}
+	if err := ps.db.C.ScanPages(&dynamodb.ScanInput{
+		TableName:        aws.String(policyRulesTableName),
+		FilterExpression: aws.String("app_id = :app_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":app_id": {S: aws.String(appID)},
+		},
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageRules := []model.PolicyRule{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageRules); err != nil {
+			logging.Error("Error unmarshalling policy rules:", err)
+			return false
+		}
+		rules = append(rules, pageRules...)
+		return true
+	}); err != nil {
+		logging.Error("Error querying for policy rules:", err)
+		return []model.PolicyRule{}, ErrorInternalError
+	}
+
+	// IDs are xids, which are sortable by creation time.
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules, nil
+}
+
+// UpdatePolicyRule updates the rule.
+func (ps *PolicyStorage) UpdatePolicyRule(rule model.PolicyRule) (model.PolicyRule, error) {
+	if err := ps.put(rule, "attribute_exists(id)"); err != nil {
+		return model.PolicyRule{}, err
+	}
+	return rule, nil
+}
+
+func (ps *PolicyStorage) put(rule model.PolicyRule, condition string) error {
+	item, err := dynamodbattribute.MarshalMap(rule)
+	if err != nil {
+		logging.Error("Error marshalling policy rule:", err)
+		return ErrorInternalError
+	}
+
+	_, err = ps.db.C.PutItem(&dynamodb.PutItemInput{
+		Item:                item,
+		TableName:           aws.String(policyRulesTableName),
+		ConditionExpression: aws.String(condition),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return model.ErrPolicyRuleNotFound
+	}
+	if err != nil {
+		logging.Error("Error putting policy rule:", err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+// DeletePolicyRule deletes the rule.
+func (ps *PolicyStorage) DeletePolicyRule(id string) error {
+	_, err := ps.db.C.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(policyRulesTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+		ConditionExpression: aws.String("attribute_exists(id)"),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return model.ErrPolicyRuleNotFound
+	}
+	if err != nil {
+		logging.Error("Error deleting policy rule:", err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+// Close does nothing here.
+func (ps *PolicyStorage) Close() {}
+
+// ensureTable ensures that the policy rules table exists in the database.
+func (ps *PolicyStorage) ensureTable() error {
+	exists, err := ps.db.IsTableExists(policyRulesTableName)
+	if err != nil {
+		logging.Error("Error checking for policy rules table existence:", err)
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	createTableInput := &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		BillingMode: aws.String("PAY_PER_REQUEST"),
+		TableName:   aws.String(policyRulesTableName),
+	}
+
+	if _, err = ps.db.C.CreateTable(createTableInput); err != nil {
+		logging.Error("Error creating policy rules table:", err)
+		return err
+	}
+	return nil
+}
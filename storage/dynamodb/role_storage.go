@@ -0,0 +1,283 @@
+package dynamodb
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+const (
+	// rolesTableName is a table name for roles.
+	rolesTableName = "Roles"
+	// userRolesTableName is a table name for roles assigned to the users.
+	userRolesTableName = "UserRoles"
+)
+
+// NewRoleStorage creates and provisions new DynamoDB role storage.
+func NewRoleStorage(db *DB) (model.RoleStorage, error) {
+	rs := &RoleStorage{db: db}
+	for _, table := range []string{rolesTableName, userRolesTableName} {
+		if err := rs.ensureTable(table); err != nil {
+			return rs, err
+		}
+	}
+	return rs, nil
+}
+
+// RoleStorage implements role storage interface.
+// Roles are fetched with the table scan, as apps have few of them.
+type RoleStorage struct {
+	db *DB
+}
+
+// userRolesData is an item with the roles assigned to the user, keyed by "<app ID>/<user ID>".
+type userRolesData struct {
+	ID     string   `json:"id"`
+	AppID  string   `json:"app_id"`
+	UserID string   `json:"user_id"`
+	Roles  []string `json:"roles"`
+}
+
+func userRolesID(appID, userID string) string {
+	return appID + "/" + userID
+}
+
+// AddRole saves new role.
+func (rs *RoleStorage) AddRole(role model.Role) (model.Role, error) {
+	role.ID = xid.New().String()
+	if err := rs.put(rolesTableName, role, "attribute_not_exists(id)"); err != nil {
+		return model.Role{}, err
+	}
+	return role, nil
+}
+
+// RoleByID returns role by its ID.
+func (rs *RoleStorage) RoleByID(id string) (model.Role, error) {
+	var role model.Role
+	result, err := rs.db.C.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(rolesTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+	})
+	if err != nil {
+		logging.Error("Error getting role:", err)
+		return role, ErrorInternalError
+	}
+	if result.Item == nil {
+		return role, model.ErrRoleNotFound
+	}
+
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &role); err != nil {
+		logging.Error("Error unmarshalling role:", err)
+		return role, ErrorInternalError
+	}
+	return role, nil
+}
+
+// FetchRoles returns all roles of the app, sorted by name.
+func (rs *RoleStorage) FetchRoles(appID string) ([]model.Role, error) {
+	roles := []model.Role{}
+	if err := rs.db.C.ScanPages(&dynamodb.ScanInput{
+		TableName:        aws.String(rolesTableName),
+		FilterExpression: aws.String("app_id = :app_id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":app_id": {S: aws.String(appID)},
+		},
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageRoles := []model.Role{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageRoles); err != nil {
+			logging.Error("Error unmarshalling roles:", err)
+			return false
+		}
+		roles = append(roles, pageRoles...)
+		return true
+	}); err != nil {
+		logging.Error("Error querying for roles:", err)
+		return []model.Role{}, ErrorInternalError
+	}
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+// UpdateRole updates the role.
+func (rs *RoleStorage) UpdateRole(role model.Role) (model.Role, error) {
+	if err := rs.put(rolesTableName, role, "attribute_exists(id)"); err != nil {
+		return model.Role{}, err
+	}
+	return role, nil
+}
+
+// DeleteRole deletes the role and unassigns it from the users.
+func (rs *RoleStorage) DeleteRole(id string) error {
+	role, err := rs.RoleByID(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = rs.db.C.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(rolesTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+		ConditionExpression: aws.String("attribute_exists(id)"),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return model.ErrRoleNotFound
+	}
+	if err != nil {
+		logging.Error("Error deleting role:", err)
+		return ErrorInternalError
+	}
+
+	assigned := []userRolesData{}
+	if err = rs.db.C.ScanPages(&dynamodb.ScanInput{
+		TableName:        aws.String(userRolesTableName),
+		FilterExpression: aws.String("app_id = :app_id AND contains(#roles, :role)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#roles": aws.String("roles"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":app_id": {S: aws.String(role.AppID)},
+			":role":   {S: aws.String(role.Name)},
+		},
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageItems := []userRolesData{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageItems); err != nil {
+			logging.Error("Error unmarshalling user roles:", err)
+			return false
+		}
+		assigned = append(assigned, pageItems...)
+		return true
+	}); err != nil {
+		logging.Error("Error querying for user roles:", err)
+		return ErrorInternalError
+	}
+
+	for _, ur := range assigned {
+		kept := []string{}
+		for _, name := range ur.Roles {
+			if name != role.Name {
+				kept = append(kept, name)
+			}
+		}
+		if err = rs.SetUserRoles(ur.AppID, ur.UserID, kept); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UserRoles returns names of the roles assigned to the user in the app.
+func (rs *RoleStorage) UserRoles(appID, userID string) ([]string, error) {
+	result, err := rs.db.C.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(userRolesTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(userRolesID(appID, userID))},
+		},
+	})
+	if err != nil {
+		logging.Error("Error getting user roles:", err)
+		return nil, ErrorInternalError
+	}
+	if result.Item == nil {
+		return []string{}, nil
+	}
+
+	var ur userRolesData
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &ur); err != nil {
+		logging.Error("Error unmarshalling user roles:", err)
+		return nil, ErrorInternalError
+	}
+	return ur.Roles, nil
+}
+
+// SetUserRoles replaces the roles assigned to the user in the app.
+func (rs *RoleStorage) SetUserRoles(appID, userID string, roles []string) error {
+	if len(roles) > 0 {
+		return rs.put(userRolesTableName, userRolesData{ID: userRolesID(appID, userID), AppID: appID, UserID: userID, Roles: roles}, "")
+	}
+
+	if _, err := rs.db.C.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(userRolesTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(userRolesID(appID, userID))},
+		},
+	}); err != nil {
+		logging.Error("Error deleting user roles:", err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+// Close does nothing here.
+func (rs *RoleStorage) Close() {}
+
+// put saves the item, returning ErrRoleNotFound if the condition fails. Empty condition overwrites the item.
+func (rs *RoleStorage) put(table string, value interface{}, condition string) error {
+	item, err := dynamodbattribute.MarshalMap(value)
+	if err != nil {
+		logging.Errorf("Error marshalling item for %s: %s\n", table, err)
+		return ErrorInternalError
+	}
+
+	input := &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(table),
+	}
+	if condition != "" {
+		input.ConditionExpression = aws.String(condition)
+	}
+
+	_, err = rs.db.C.PutItem(input)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return model.ErrRoleNotFound
+	}
+	if err != nil {
+		logging.Errorf("Error putting item to %s: %s\n", table, err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+// ensureTable ensures that the role storage table exists in the database.
+func (rs *RoleStorage) ensureTable(table string) error {
+	exists, err := rs.db.IsTableExists(table)
+	if err != nil {
+		logging.Errorf("Error checking for %s table existence: %s\n", table, err)
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	createTableInput := &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		BillingMode: aws.String("PAY_PER_REQUEST"),
+		TableName:   aws.String(table),
+	}
+
+	if _, err = rs.db.C.CreateTable(createTableInput); err != nil {
+		logging.Errorf("Error creating %s table: %s\n", table, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package dynamodb
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// scimTokensTableName is a table name for SCIM tokens.
+const scimTokensTableName = "SCIMTokens"
+
+// NewSCIMTokenStorage creates and provisions new DynamoDB SCIM token storage.
+func NewSCIMTokenStorage(db *DB) (model.SCIMTokenStorage, error) {
+	ss := &SCIMTokenStorage{db: db}
+	err := ss.ensureTable()
+	return ss, err
+}
+
+// SCIMTokenStorage implements SCIM token storage interface.
+// There are few tokens, so they are looked up by hash with the table scan.
+type SCIMTokenStorage struct {
+	db *DB
+}
+
+// AddSCIMToken saves new token.
+func (ss *SCIMTokenStorage) AddSCIMToken(token model.SCIMToken) (model.SCIMToken, error) {
+	token.ID = xid.New().String()
+
+	item, err := dynamodbattribute.MarshalMap(token)
+	if err != nil {
+		logging.Error("Error marshalling SCIM token:", err)
+		return model.SCIMToken{}, ErrorInternalError
+	}
+
+	if _, err = ss.db.C.PutItem(&dynamodb.PutItemInput{
+		Item:                item,
+		TableName:           aws.String(scimTokensTableName),
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	}); err != nil {
+		logging.Error("Error putting SCIM token:", err)
+		return model.SCIMToken{}, ErrorInternalError
+	}
+	return token, nil
+}
+
+// SCIMTokenByHash returns token by its hash.
+func (ss *SCIMTokenStorage) SCIMTokenByHash(hash string) (model.SCIMToken, error) {
+	tokens, err := ss.scanTokens(&dynamodb.ScanInput{
+		TableName:        aws.String(scimTokensTableName),
+		FilterExpression: aws.String("#hash = :hash"),
+		// hash is a DynamoDB reserved word.
+		ExpressionAttributeNames: map[string]*string{"#hash": aws.String("hash")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":hash": {S: aws.String(hash)},
+		},
+	})
+	if err != nil {
+		return model.SCIMToken{}, err
+	}
+	if len(tokens) == 0 {
+		return model.SCIMToken{}, model.ErrSCIMTokenNotFound
+	}
+	return tokens[0], nil
+}
+
+// FetchSCIMTokens returns all tokens, oldest first.
+func (ss *SCIMTokenStorage) FetchSCIMTokens() ([]model.SCIMToken, error) {
+	tokens, err := ss.scanTokens(&dynamodb.ScanInput{TableName: aws.String(scimTokensTableName)})
+	if err != nil {
+		return []model.SCIMToken{}, err
+	}
+
+	// IDs are xids, which are sortable by creation time.
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+	return tokens, nil
+}
+
+// DeleteSCIMToken deletes the token.
+func (ss *SCIMTokenStorage) DeleteSCIMToken(id string) error {
+	_, err := ss.db.C.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(scimTokensTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+		ConditionExpression: aws.String("attribute_exists(id)"),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return model.ErrSCIMTokenNotFound
+	}
+	if err != nil {
+		logging.Error("Error deleting SCIM token:", err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+// Close does nothing here.
+func (ss *SCIMTokenStorage) Close() {}
+
+func (ss *SCIMTokenStorage) scanTokens(input *dynamodb.ScanInput) ([]model.SCIMToken, error) {
+	tokens := []model.SCIMToken{}
+	if err := ss.db.C.ScanPages(input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageTokens := []model.SCIMToken{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageTokens); err != nil {
+			logging.Error("Error unmarshalling SCIM tokens:", err)
+			return false
+		}
+		tokens = append(tokens, pageTokens...)
+		return true
+	}); err != nil {
+		logging.Error("Error querying for SCIM tokens:", err)
+		return nil, ErrorInternalError
+	}
+	return tokens, nil
+}
+
+// ensureTable ensures that the SCIM token table exists in the database.
+func (ss *SCIMTokenStorage) ensureTable() error {
+	exists, err := ss.db.IsTableExists(scimTokensTableName)
+	if err != nil {
+		logging.Error("Error checking for SCIM tokens table existence:", err)
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	createTableInput := &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		BillingMode: aws.String("PAY_PER_REQUEST"),
+		TableName:   aws.String(scimTokensTableName),
+	}
+
+	if _, err = ss.db.C.CreateTable(createTableInput); err != nil {
+		logging.Error("Error creating SCIM tokens table:", err)
+		return err
+	}
+	return nil
+}
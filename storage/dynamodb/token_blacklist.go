@@ -1,11 +1,10 @@
 package dynamodb
 
 import (
-	"log"
-
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 )
 
@@ -27,7 +26,7 @@ type TokenBlacklist struct {
 func (tb *TokenBlacklist) ensureTable() error {
 	exists, err := tb.db.IsTableExists(blacklistedTokensTableName)
 	if err != nil {
-		log.Printf("Error while checking if %s exists: %v", blacklistedTokensTableName, err)
+		logging.Errorf("Error while checking if %s exists: %v", blacklistedTokensTableName, err)
 		return err
 	}
 	if exists {
@@ -52,7 +51,7 @@ func (tb *TokenBlacklist) ensureTable() error {
 	}
 
 	if _, err = tb.db.C.CreateTable(input); err != nil {
-		log.Printf("Error while creating %s table: %v", blacklistedTokensTableName, err)
+		logging.Errorf("Error while creating %s table: %v", blacklistedTokensTableName, err)
 		return err
 	}
 	return nil
@@ -69,7 +68,7 @@ func (tb *TokenBlacklist) Add(token string) error {
 
 	t, err := dynamodbattribute.MarshalMap(Token{Token: token})
 	if err != nil {
-		log.Println(err)
+		logging.Error(err)
 		return ErrorInternalError
 	}
 
@@ -79,7 +78,7 @@ func (tb *TokenBlacklist) Add(token string) error {
 	}
 
 	if _, err = tb.db.C.PutItem(input); err != nil {
-		log.Println("Error while putting token to blacklist:", err)
+		logging.Error("Error while putting token to blacklist:", err)
 		return ErrorInternalError
 	}
 	return nil
@@ -100,7 +99,7 @@ func (tb *TokenBlacklist) IsBlacklisted(token string) bool {
 		},
 	})
 	if err != nil {
-		log.Println("Error while fetching token from db:", err)
+		logging.Error("Error while fetching token from db:", err)
 		return false
 	}
 
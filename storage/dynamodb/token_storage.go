@@ -1,11 +1,10 @@
 package dynamodb
 
 import (
-	"log"
-
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 )
 
@@ -27,7 +26,7 @@ type TokenStorage struct {
 func (ts *TokenStorage) ensureTable() error {
 	exists, err := ts.db.IsTableExists(tokensTableName)
 	if err != nil {
-		log.Printf("Error while checking if %s exists: %v", tokensTableName, err)
+		logging.Errorf("Error while checking if %s exists: %v", tokensTableName, err)
 		return err
 	}
 	if exists {
@@ -52,7 +51,7 @@ func (ts *TokenStorage) ensureTable() error {
 	}
 
 	if _, err = ts.db.C.CreateTable(input); err != nil {
-		log.Printf("Error while creating %s table: %v", tokensTableName, err)
+		logging.Errorf("Error while creating %s table: %v", tokensTableName, err)
 		return err
 	}
 	return nil
@@ -69,7 +68,7 @@ func (ts *TokenStorage) SaveToken(token string) error {
 
 	t, err := dynamodbattribute.MarshalMap(Token{Token: token})
 	if err != nil {
-		log.Println(err)
+		logging.Error(err)
 		return ErrorInternalError
 	}
 
@@ -79,7 +78,7 @@ func (ts *TokenStorage) SaveToken(token string) error {
 	}
 
 	if _, err = ts.db.C.PutItem(input); err != nil {
-		log.Println("Error while putting token to db:", err)
+		logging.Error("Error while putting token to db:", err)
 		return ErrorInternalError
 	}
 	return nil
@@ -100,7 +99,7 @@ func (ts *TokenStorage) HasToken(token string) bool {
 		},
 	})
 	if err != nil {
-		log.Println("Error while fetching token from db:", err)
+		logging.Error("Error while fetching token from db:", err)
 		return false
 	}
 	//empty result
@@ -123,7 +122,7 @@ func (ts *TokenStorage) DeleteToken(token string) error {
 			},
 		},
 	}); err != nil {
-		log.Println("Error while deleting token from db:", err)
+		logging.Error("Error while deleting token from db:", err)
 		return ErrorInternalError
 	}
 	return nil
@@ -0,0 +1,202 @@
+package dynamodb
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// userSessionsTableName is a table name for user sessions.
+const userSessionsTableName = "UserSessions"
+
+// NewUserSessionStorage creates and provisions new DynamoDB user session storage.
+func NewUserSessionStorage(db *DB) (model.UserSessionStorage, error) {
+	ss := &UserSessionStorage{db: db}
+	err := ss.ensureTable()
+	return ss, err
+}
+
+// UserSessionStorage implements user session storage interface.
+type UserSessionStorage struct {
+	db *DB
+}
+
+// AddUserSession saves new session.
+func (ss *UserSessionStorage) AddUserSession(session model.UserSession) (model.UserSession, error) {
+	session.ID = xid.New().String()
+
+	item, err := dynamodbattribute.MarshalMap(session)
+	if err != nil {
+		logging.Error("Error marshalling user session:", err)
+		return model.UserSession{}, ErrorInternalError
+	}
+
+	if _, err = ss.db.C.PutItem(&dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(userSessionsTableName),
+	}); err != nil {
+		logging.Error("Error putting user session to database:", err)
+		return model.UserSession{}, ErrorInternalError
+	}
+	return session, nil
+}
+
+// UserSessionByID returns session by its ID.
+func (ss *UserSessionStorage) UserSessionByID(id string) (model.UserSession, error) {
+	var session model.UserSession
+
+	result, err := ss.db.C.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(userSessionsTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+	})
+	if err != nil {
+		logging.Error("Error getting user session from DynamoDB:", err)
+		return session, ErrorInternalError
+	}
+	if result.Item == nil {
+		return session, model.ErrUserSessionNotFound
+	}
+
+	if err = dynamodbattribute.UnmarshalMap(result.Item, &session); err != nil {
+		logging.Error("Error unmarshalling user session:", err)
+		return session, ErrorInternalError
+	}
+	return session, nil
+}
+
+// UserSessionByToken returns session which has issued the token.
+func (ss *UserSessionStorage) UserSessionByToken(token string) (model.UserSession, error) {
+	sessions, err := ss.scan("contains(tokens, :token)", map[string]*dynamodb.AttributeValue{
+		":token": {S: aws.String(token)},
+	})
+	if err != nil {
+		return model.UserSession{}, err
+	}
+	if len(sessions) == 0 {
+		return model.UserSession{}, model.ErrUserSessionNotFound
+	}
+	return sessions[0], nil
+}
+
+// FetchUserSessions returns all sessions of the user, most recently used first.
+func (ss *UserSessionStorage) FetchUserSessions(userID string) ([]model.UserSession, error) {
+	sessions, err := ss.scan("user_id = :user_id", map[string]*dynamodb.AttributeValue{
+		":user_id": {S: aws.String(userID)},
+	})
+	if err != nil {
+		return []model.UserSession{}, err
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt > sessions[j].LastUsedAt })
+	return sessions, nil
+}
+
+// UpdateUserSessionTokens replaces session tokens and sets last used time.
+func (ss *UserSessionStorage) UpdateUserSessionTokens(id string, tokens []string, lastUsedAt int64) error {
+	tokensValue, err := dynamodbattribute.Marshal(tokens)
+	if err != nil {
+		logging.Error("Error marshalling user session tokens:", err)
+		return ErrorInternalError
+	}
+
+	_, err = ss.db.C.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(userSessionsTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+		ConditionExpression: aws.String("attribute_exists(id)"),
+		UpdateExpression:    aws.String("set tokens = :tokens, last_used_at = :last_used_at"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":tokens":       tokensValue,
+			":last_used_at": {N: aws.String(strconv.FormatInt(lastUsedAt, 10))},
+		},
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return model.ErrUserSessionNotFound
+	}
+	return err
+}
+
+// DeleteUserSession deletes session.
+func (ss *UserSessionStorage) DeleteUserSession(id string) error {
+	if _, err := ss.db.C.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(userSessionsTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+	}); err != nil {
+		logging.Error("Error deleting user session:", err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+// Close does nothing here.
+func (ss *UserSessionStorage) Close() {}
+
+func (ss *UserSessionStorage) scan(filter string, values map[string]*dynamodb.AttributeValue) ([]model.UserSession, error) {
+	scanInput := &dynamodb.ScanInput{
+		TableName:                 aws.String(userSessionsTableName),
+		FilterExpression:          aws.String(filter),
+		ExpressionAttributeValues: values,
+	}
+
+	sessions := []model.UserSession{}
+	if err := ss.db.C.ScanPages(scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageSessions := []model.UserSession{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageSessions); err != nil {
+			logging.Error("Error unmarshalling user sessions:", err)
+			return false
+		}
+		sessions = append(sessions, pageSessions...)
+		return true
+	}); err != nil {
+		logging.Error("Error querying for user sessions:", err)
+		return nil, ErrorInternalError
+	}
+	return sessions, nil
+}
+
+// ensureTable ensures that user session storage table exists in the database.
+func (ss *UserSessionStorage) ensureTable() error {
+	exists, err := ss.db.IsTableExists(userSessionsTableName)
+	if err != nil {
+		logging.Error("Error checking for user sessions table existence:", err)
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	createTableInput := &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		BillingMode: aws.String("PAY_PER_REQUEST"),
+		TableName:   aws.String(userSessionsTableName),
+	}
+
+	if _, err = ss.db.C.CreateTable(createTableInput); err != nil {
+		logging.Error("Error creating user sessions table:", err)
+		return err
+	}
+	return nil
+}
@@ -3,14 +3,16 @@ package dynamodb
 import (
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	"github.com/rs/xid"
 	"golang.org/x/crypto/bcrypt"
@@ -48,7 +50,7 @@ func (us *UserStorage) NewUser() model.User {
 func (us *UserStorage) UserByID(id string) (model.User, error) {
 	idx, err := xid.FromString(id)
 	if err != nil {
-		log.Println("Incorrect user ID: ", id)
+		logging.Error("Incorrect user ID: ", id)
 		return nil, model.ErrorWrongDataFormat
 	}
 
@@ -61,7 +63,7 @@ func (us *UserStorage) UserByID(id string) (model.User, error) {
 		},
 	})
 	if err != nil {
-		log.Println("Error getting item from DynamoDB:", err)
+		logging.Error("Error getting item from DynamoDB:", err)
 		return nil, ErrorInternalError
 	}
 	if result.Item == nil {
@@ -70,7 +72,7 @@ func (us *UserStorage) UserByID(id string) (model.User, error) {
 
 	userdata := userData{}
 	if err = dynamodbattribute.UnmarshalMap(result.Item, &userdata); err != nil {
-		log.Println("Error unmarshalling item:", err)
+		logging.Error("Error unmarshalling item:", err)
 		return nil, ErrorInternalError
 	}
 	return &User{userData: userdata}, nil
@@ -93,7 +95,7 @@ func (us *UserStorage) userIDByFederatedID(provider model.FederatedIdentityProvi
 		},
 	})
 	if err != nil {
-		log.Println("Error getting item from DynamoDB:", err)
+		logging.Error("Error getting item from DynamoDB:", err)
 		return "", ErrorInternalError
 	}
 	if result.Item == nil {
@@ -102,7 +104,7 @@ func (us *UserStorage) userIDByFederatedID(provider model.FederatedIdentityProvi
 
 	fedData := federatedUserID{}
 	if err = dynamodbattribute.UnmarshalMap(result.Item, &fedData); err != nil || len(fedData.UserID) == 0 {
-		log.Println("Error unmarshalling item:", err)
+		logging.Error("Error unmarshalling item:", err)
 		return "", ErrorInternalError
 	}
 	return fedData.UserID, nil
@@ -164,7 +166,7 @@ func (us *UserStorage) userIdxByName(name string) (*userIndexByNameData, error)
 		Select: aws.String("ALL_PROJECTED_ATTRIBUTES"), // retrieve all attributes, because we need to make local check.
 	})
 	if err != nil {
-		log.Println("Error querying for items:", err)
+		logging.Error("Error querying for items:", err)
 		return nil, ErrorInternalError
 	}
 	if len(result.Items) == 0 {
@@ -174,7 +176,7 @@ func (us *UserStorage) userIdxByName(name string) (*userIndexByNameData, error)
 	item := result.Items[0]
 	userdata := new(userIndexByNameData)
 	if err = dynamodbattribute.UnmarshalMap(item, userdata); err != nil {
-		log.Println("Error unmarshalling item:", err)
+		logging.Error("Error unmarshalling item:", err)
 		return nil, ErrorInternalError
 	}
 	return userdata, nil
@@ -192,7 +194,7 @@ func (us *UserStorage) userIdxByPhone(phone string) (*userIndexByPhoneData, erro
 		Select: aws.String("ALL_PROJECTED_ATTRIBUTES"),
 	})
 	if err != nil {
-		log.Println("Error querying for user by phone number:", err)
+		logging.Error("Error querying for user by phone number:", err)
 		return nil, ErrorInternalError
 	}
 	if len(result.Items) == 0 {
@@ -202,7 +204,7 @@ func (us *UserStorage) userIdxByPhone(phone string) (*userIndexByPhoneData, erro
 	item := result.Items[0]
 	userdata := new(userIndexByPhoneData)
 	if err = dynamodbattribute.UnmarshalMap(item, userdata); err != nil {
-		log.Println("Error unmarshalling user:", err)
+		logging.Error("Error unmarshalling user:", err)
 		return nil, ErrorInternalError
 	}
 	return userdata, nil
@@ -213,7 +215,7 @@ func (us *UserStorage) UserByNamePassword(name, password string) (model.User, er
 	name = strings.ToLower(name)
 	userIdx, err := us.userIdxByName(name)
 	if err != nil {
-		log.Println("Error getting user by name:", err)
+		logging.Error("Error getting user by name:", err)
 		return nil, err
 	}
 	// if password is incorrect, return 'not found' error for security reasons.
@@ -223,7 +225,7 @@ func (us *UserStorage) UserByNamePassword(name, password string) (model.User, er
 
 	user, err := us.UserByID(userIdx.ID)
 	if err != nil {
-		log.Println("Error querying user by id:", err)
+		logging.Error("Error querying user by id:", err)
 		return nil, ErrorInternalError
 	}
 	return user, nil
@@ -233,13 +235,13 @@ func (us *UserStorage) UserByNamePassword(name, password string) (model.User, er
 func (us *UserStorage) UserByPhone(phone string) (model.User, error) {
 	userIdx, err := us.userIdxByPhone(phone)
 	if err != nil {
-		log.Println("Error getting user by phone:", err)
+		logging.Error("Error getting user by phone:", err)
 		return nil, err
 	}
 
 	user, err := us.UserByID(userIdx.ID)
 	if err != nil {
-		log.Println("Error querying user by id:", err)
+		logging.Error("Error querying user by id:", err)
 		return nil, ErrorInternalError
 	}
 
@@ -280,7 +282,7 @@ func (us *UserStorage) addNewUser(u *User) (*User, error) {
 	u.userData.NumOfLogins = 0
 	uv, err := dynamodbattribute.MarshalMap(u)
 	if err != nil {
-		log.Println("Error marshalling user:", err)
+		logging.Error("Error marshalling user:", err)
 		return nil, ErrorInternalError
 	}
 
@@ -289,7 +291,7 @@ func (us *UserStorage) addNewUser(u *User) (*User, error) {
 		TableName: aws.String(usersTableName),
 	}
 	if _, err = us.db.C.PutItem(input); err != nil {
-		log.Println("Error putting item:", err)
+		logging.Error("Error putting item:", err)
 		return nil, ErrorInternalError
 	}
 	return u, err
@@ -312,7 +314,7 @@ func (us *UserStorage) AddUserByNameAndPassword(username, password, role string,
 	username = strings.ToLower(username)
 	_, err := us.userIdxByName(username)
 	if err != nil && err != model.ErrUserNotFound {
-		log.Println(err)
+		logging.Error(err)
 		return nil, err
 	} else if err == nil {
 		return nil, model.ErrorUserExists
@@ -338,7 +340,7 @@ func (us *UserStorage) AddUserByNameAndPassword(username, password, role string,
 func (us *UserStorage) AddUserWithFederatedID(provider model.FederatedIdentityProvider, federatedID, role string) (model.User, error) {
 	_, err := us.userIDByFederatedID(provider, federatedID)
 	if err != nil && err != model.ErrUserNotFound {
-		log.Println("Error getting user by name:", err)
+		logging.Error("Error getting user by name:", err)
 		return nil, err
 	} else if err == nil {
 		return nil, model.ErrorUserExists
@@ -348,14 +350,14 @@ func (us *UserStorage) AddUserWithFederatedID(provider model.FederatedIdentityPr
 
 	user, err := us.userIdxByName(fid)
 	if err != nil && err != model.ErrUserNotFound {
-		log.Println("Error getting user by name:", err)
+		logging.Error("Error getting user by name:", err)
 		return nil, err
 	} else if err == model.ErrUserNotFound {
 		// no such user, let's create it
-		uData := userData{Username: fid, AccessRole: role, Active: true}
+		uData := userData{Username: fid, AccessRole: role, Active: true, FederatedIDs: []string{fid}}
 		u, creationErr := us.AddNewUser(&User{userData: uData}, "")
 		if creationErr != nil {
-			log.Println("Error adding new user:", creationErr)
+			logging.Error("Error adding new user:", creationErr)
 			return nil, creationErr
 		}
 		user = &userIndexByNameData{ID: u.ID(), Username: u.Username()}
@@ -368,7 +370,7 @@ func (us *UserStorage) AddUserWithFederatedID(provider model.FederatedIdentityPr
 	fedData := federatedUserID{FederatedID: fid, UserID: user.ID}
 	fedInputData, err := dynamodbattribute.MarshalMap(fedData)
 	if err != nil {
-		log.Println("Error marshalling federated data:", err)
+		logging.Error("Error marshalling federated data:", err)
 		return nil, ErrorInternalError
 	}
 
@@ -377,7 +379,7 @@ func (us *UserStorage) AddUserWithFederatedID(provider model.FederatedIdentityPr
 		TableName: aws.String(usersFederatedIDTableName),
 	}
 	if _, err = us.db.C.PutItem(input); err != nil {
-		log.Println("Error putting item:", err)
+		logging.Error("Error putting item:", err)
 		return nil, ErrorInternalError
 	}
 	// just in case
@@ -393,7 +395,7 @@ func (us *UserStorage) AddUserWithFederatedID(provider model.FederatedIdentityPr
 func (us *UserStorage) AddUserByPhone(phone, role string) (model.User, error) {
 	_, err := us.userIdxByPhone(phone)
 	if err != nil && err != model.ErrUserNotFound {
-		log.Println(err)
+		logging.Error(err)
 		return nil, err
 	} else if err == nil {
 		return nil, model.ErrorUserExists
@@ -413,7 +415,7 @@ func (us *UserStorage) AddUserByPhone(phone, role string) (model.User, error) {
 // UpdateUser updates user in DynamoDB storage.
 func (us *UserStorage) UpdateUser(userID string, newUser model.User) (model.User, error) {
 	if _, err := xid.FromString(userID); err != nil {
-		log.Println("Incorrect userID: ", userID)
+		logging.Error("Incorrect userID: ", userID)
 		return nil, model.ErrorWrongDataFormat
 	}
 
@@ -427,8 +429,13 @@ func (us *UserStorage) UpdateUser(userID string, newUser model.User) (model.User
 		res.userData.ID = userID
 	}
 
+	// Tokens revocation cannot be undone by the update.
+	if oldUser, err := us.UserByID(userID); err == nil && res.userData.TokensValidAfter == 0 {
+		res.userData.TokensValidAfter = oldUser.TokensValidAfter()
+	}
+
 	if err := us.DeleteUser(userID); err != nil {
-		log.Println("Error deleting old user:", err)
+		logging.Error("Error deleting old user:", err)
 		return nil, err
 	}
 
@@ -441,11 +448,173 @@ func (us *UserStorage) UpdateUser(userID string, newUser model.User) (model.User
 	return updatedUser, err
 }
 
+// LinkFederatedID attaches federated identity to the existing user.
+func (us *UserStorage) LinkFederatedID(userID string, provider model.FederatedIdentityProvider, id string) error {
+	owner, err := us.userIDByFederatedID(provider, id)
+	if err != nil && err != model.ErrUserNotFound {
+		return err
+	} else if err == nil {
+		if owner == userID {
+			return nil
+		}
+		return model.ErrorUserExists
+	}
+
+	fid := model.FederatedIDKey(provider, id)
+	fedInputData, err := dynamodbattribute.MarshalMap(federatedUserID{FederatedID: fid, UserID: userID})
+	if err != nil {
+		logging.Error("Error marshalling federated data:", err)
+		return ErrorInternalError
+	}
+
+	if _, err = us.db.C.PutItem(&dynamodb.PutItemInput{
+		Item:                fedInputData,
+		TableName:           aws.String(usersFederatedIDTableName),
+		ConditionExpression: aws.String("attribute_not_exists(federated_id)"),
+	}); err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return model.ErrorUserExists
+		}
+		logging.Error("Error putting item:", err)
+		return ErrorInternalError
+	}
+
+	return us.updateUserFields(userID, "set federated_ids = list_append(if_not_exists(federated_ids, :empty), :f)", map[string]*dynamodb.AttributeValue{
+		":empty": {L: []*dynamodb.AttributeValue{}},
+		":f":     {L: []*dynamodb.AttributeValue{{S: aws.String(fid)}}},
+	})
+}
+
+// UnlinkFederatedID detaches federated identity from the user.
+func (us *UserStorage) UnlinkFederatedID(userID string, provider model.FederatedIdentityProvider, id string) error {
+	user, err := us.UserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	fid := model.FederatedIDKey(provider, id)
+	fids := []*dynamodb.AttributeValue{}
+	for _, f := range user.FederatedIDs() {
+		if f != fid {
+			fids = append(fids, &dynamodb.AttributeValue{S: aws.String(f)})
+		}
+	}
+
+	if len(fids) == 0 {
+		err = us.updateUserFields(userID, "remove federated_ids", nil)
+	} else {
+		err = us.updateUserFields(userID, "set federated_ids = :f", map[string]*dynamodb.AttributeValue{":f": {L: fids}})
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = us.db.C.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(usersFederatedIDTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"federated_id": {S: aws.String(fid)},
+		},
+		ConditionExpression: aws.String("user_id = :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u": {S: aws.String(userID)},
+		},
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return nil
+	}
+	return err
+}
+
+// LinkPhone attaches phone number to the existing user.
+func (us *UserStorage) LinkPhone(userID, phone string) error {
+	idx, err := us.userIdxByPhone(phone)
+	if err != nil && err != model.ErrUserNotFound {
+		return err
+	} else if err == nil && idx.ID != userID {
+		return model.ErrorUserExists
+	}
+
+	return us.updateUserFields(userID, "set phone = :p", map[string]*dynamodb.AttributeValue{
+		":p": {S: aws.String(phone)},
+	})
+}
+
+// UnlinkPhone removes phone number from the user.
+// Attribute is removed, because empty strings are not allowed as index keys.
+func (us *UserStorage) UnlinkPhone(userID string) error {
+	return us.updateUserFields(userID, "remove phone", nil)
+}
+
+// LinkPassword sets username and password for the existing user.
+func (us *UserStorage) LinkPassword(userID, username, password string) error {
+	values := map[string]*dynamodb.AttributeValue{
+		":p": {S: aws.String(PasswordHash(password))},
+	}
+	expression := "set pswd = :p"
+
+	if username != "" {
+		username = strings.ToLower(username)
+		idx, err := us.userIdxByName(username)
+		if err != nil && err != model.ErrUserNotFound {
+			return err
+		} else if err == nil && idx.ID != userID {
+			return model.ErrorUserExists
+		}
+		values[":u"] = &dynamodb.AttributeValue{S: aws.String(username)}
+		expression += ", username = :u"
+	}
+	return us.updateUserFields(userID, expression, values)
+}
+
+// UnlinkPassword removes password from the user.
+func (us *UserStorage) UnlinkPassword(userID string) error {
+	return us.updateUserFields(userID, "remove pswd", nil)
+}
+
+// DeanonimizeUser turns anonymous user into the regular one.
+func (us *UserStorage) DeanonimizeUser(userID string) error {
+	return us.updateUserFields(userID, "remove anonymous", nil)
+}
+
+// SetTokensValidAfter invalidates all user tokens issued before the timestamp.
+func (us *UserStorage) SetTokensValidAfter(userID string, timestamp int64) error {
+	return us.updateUserFields(userID, "set tokens_valid_after = :t", map[string]*dynamodb.AttributeValue{
+		":t": {N: aws.String(strconv.FormatInt(timestamp, 10))},
+	})
+}
+
+// updateUserFields applies update expression to the existing user.
+func (us *UserStorage) updateUserFields(userID, expression string, values map[string]*dynamodb.AttributeValue) error {
+	idx, err := xid.FromString(userID)
+	if err != nil {
+		logging.Error("Incorrect user ID: ", userID)
+		return model.ErrorWrongDataFormat
+	}
+
+	if _, err = us.db.C.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(usersTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(idx.String())},
+		},
+		ConditionExpression:       aws.String("attribute_exists(id)"),
+		ExpressionAttributeValues: values,
+		UpdateExpression:          aws.String(expression),
+		ReturnValues:              aws.String("NONE"),
+	}); err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return model.ErrUserNotFound
+		}
+		logging.Error("Error updating user:", err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
 // ResetPassword sets new user password.
 func (us *UserStorage) ResetPassword(id, password string) error {
 	idx, err := xid.FromString(id)
 	if err != nil {
-		log.Println("Incorrect user ID: ", id)
+		logging.Error("Incorrect user ID: ", id)
 		return model.ErrorWrongDataFormat
 	}
 
@@ -469,7 +638,7 @@ func (us *UserStorage) ResetPassword(id, password string) error {
 func (us *UserStorage) ResetUsername(id, username string) error {
 	idx, err := xid.FromString(id)
 	if err != nil {
-		log.Println("Incorrect user ID: ", id)
+		logging.Error("Incorrect user ID: ", id)
 		return model.ErrorWrongDataFormat
 	}
 
@@ -489,6 +658,15 @@ func (us *UserStorage) ResetUsername(id, username string) error {
 	return err
 }
 
+// UserByUsername returns user by username, ignoring case, as usernames are stored in lower case.
+func (us *UserStorage) UserByUsername(username string) (model.User, error) {
+	userIndex, err := us.userIdxByName(username)
+	if err != nil {
+		return nil, err
+	}
+	return us.UserByID(userIndex.ID)
+}
+
 // IDByName returns userID by name.
 func (us *UserStorage) IDByName(name string) (string, error) {
 	userIndex, err := us.userIdxByName(name)
@@ -508,24 +686,44 @@ func (us *UserStorage) IDByName(name string) (string, error) {
 	return user.ID(), nil
 }
 
-// FetchUsers fetches users which name satisfies provided filterString.
+// FetchUsers fetches users which name satisfies provided filterString and which attributes match.
 // Supports pagination. Search is case-senstive for now.
-func (us *UserStorage) FetchUsers(filterString string, skip, limit int) ([]model.User, int, error) {
+func (us *UserStorage) FetchUsers(filterString string, attributes map[string]interface{}, skip, limit int) ([]model.User, int, error) {
 	scanInput := &dynamodb.ScanInput{
 		TableName: aws.String(usersTableName),
 		Limit:     aws.Int64(int64(limit)),
 	}
 
+	conditions := []string{}
+	values := map[string]*dynamodb.AttributeValue{}
 	if len(filterString) != 0 {
-		scanInput.FilterExpression = aws.String("contains(username, :filterStr)")
-		scanInput.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
-			":filterStr": {S: aws.String(filterString)},
+		conditions = append(conditions, "contains(username, :filterStr)")
+		values[":filterStr"] = &dynamodb.AttributeValue{S: aws.String(filterString)}
+	}
+	if len(attributes) > 0 {
+		names := map[string]*string{"#attributes": aws.String("attributes")}
+		i := 0
+		for name, value := range attributes {
+			av, err := dynamodbattribute.Marshal(value)
+			if err != nil {
+				return []model.User{}, 0, err
+			}
+			nameKey, valueKey := fmt.Sprintf("#a%d", i), fmt.Sprintf(":a%d", i)
+			conditions = append(conditions, fmt.Sprintf("#attributes.%s = %s", nameKey, valueKey))
+			names[nameKey] = aws.String(name)
+			values[valueKey] = av
+			i++
 		}
+		scanInput.ExpressionAttributeNames = names
+	}
+	if len(conditions) > 0 {
+		scanInput.FilterExpression = aws.String(strings.Join(conditions, " AND "))
+		scanInput.ExpressionAttributeValues = values
 	}
 
 	result, err := us.db.C.Scan(scanInput)
 	if err != nil {
-		log.Println("Error querying for users:", err)
+		logging.Error("Error querying for users:", err)
 		return []model.User{}, 0, ErrorInternalError
 	}
 
@@ -536,7 +734,7 @@ func (us *UserStorage) FetchUsers(filterString string, skip, limit int) ([]model
 		}
 		user := new(User)
 		if err = dynamodbattribute.UnmarshalMap(result.Items[i], user); err != nil {
-			log.Println("Error unmarshalling user:", err)
+			logging.Error("Error unmarshalling user:", err)
 			return []model.User{}, 0, ErrorInternalError
 		}
 		users[i] = user
@@ -563,12 +761,12 @@ func (us *UserStorage) ImportJSON(data []byte) error {
 // UpdateLoginMetadata updates user's login metadata.
 func (us *UserStorage) UpdateLoginMetadata(userID string) {
 	if _, err := xid.FromString(userID); err != nil {
-		log.Println("Incorrect userID: ", userID)
+		logging.Error("Incorrect userID: ", userID)
 		return
 	}
 
 	if _, err := us.UserByID(userID); err != nil {
-		log.Println("Cannot get user by ID: ", userID)
+		logging.Error("Cannot get user by ID: ", userID)
 		return
 	}
 
@@ -585,7 +783,7 @@ func (us *UserStorage) UpdateLoginMetadata(userID string) {
 		ReturnValues:     aws.String("NONE"),
 	})
 	if err != nil {
-		log.Printf("Cannot update login metadata of user %s: %s\n", userID, err)
+		logging.Errorf("Cannot update login metadata of user %s: %s\n", userID, err)
 		return
 	}
 }
@@ -595,7 +793,7 @@ func (us *UserStorage) UpdateLoginMetadata(userID string) {
 func (us *UserStorage) ensureTable() error {
 	exists, err := us.db.IsTableExists(usersTableName)
 	if err != nil {
-		log.Println("Error checking for table existence:", err)
+		logging.Error("Error checking for table existence:", err)
 		return err
 	}
 	if !exists {
@@ -657,7 +855,7 @@ func (us *UserStorage) ensureTable() error {
 			TableName:   aws.String(usersTableName),
 		}
 		if _, err = us.db.C.CreateTable(input); err != nil {
-			log.Println("Error creating table:", err)
+			logging.Error("Error creating table:", err)
 			return err
 		}
 	}
@@ -665,7 +863,7 @@ func (us *UserStorage) ensureTable() error {
 	// create table to handle federated ID's
 	exists, err = us.db.IsTableExists(usersFederatedIDTableName)
 	if err != nil {
-		log.Println("Error checking for table existence:", err)
+		logging.Error("Error checking for table existence:", err)
 		return err
 	}
 	if !exists {
@@ -687,7 +885,7 @@ func (us *UserStorage) ensureTable() error {
 			TableName:   aws.String(usersFederatedIDTableName),
 		}
 		if _, err = us.db.C.CreateTable(input); err != nil {
-			log.Println("Error creating table:", err)
+			logging.Error("Error creating table:", err)
 			return err
 		}
 	}
@@ -1,12 +1,12 @@
 package dynamodb
 
 import (
-	"log"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 )
 
@@ -45,7 +45,7 @@ func (vcs *VerificationCodeStorage) IsVerificationCodeFound(phone, code string)
 	})
 
 	if err != nil {
-		log.Println("Error querying for verification code:", err)
+		logging.Error("Error querying for verification code:", err)
 		return false, ErrorInternalError
 	}
 	if len(result.Items) == 0 {
@@ -65,7 +65,7 @@ func (vcs *VerificationCodeStorage) CreateVerificationCode(phone, code string) e
 	}
 
 	if _, err := vcs.db.C.DeleteItem(delInput); err != nil {
-		log.Println("Error deleting old verification code: ", err)
+		logging.Error("Error deleting old verification code: ", err)
 		return ErrorInternalError
 	}
 
@@ -76,7 +76,7 @@ func (vcs *VerificationCodeStorage) CreateVerificationCode(phone, code string) e
 		expiresAtField: time.Now().Add(verificationCodesExpirationTime),
 	})
 	if err != nil {
-		log.Println("Error marshalling verification code:", err)
+		logging.Error("Error marshalling verification code:", err)
 		return ErrorInternalError
 	}
 
@@ -86,7 +86,7 @@ func (vcs *VerificationCodeStorage) CreateVerificationCode(phone, code string) e
 	}
 
 	if _, err := vcs.db.C.PutItem(putInput); err != nil {
-		log.Println("Error putting verification code to database:", err)
+		logging.Error("Error putting verification code to database:", err)
 		return ErrorInternalError
 	}
 	return err
@@ -96,7 +96,7 @@ func (vcs *VerificationCodeStorage) CreateVerificationCode(phone, code string) e
 func (vcs *VerificationCodeStorage) ensureTable() error {
 	exists, err := vcs.db.IsTableExists(verificationCodesTableName)
 	if err != nil {
-		log.Println("Error checking for verification codes table existence:", err)
+		logging.Error("Error checking for verification codes table existence:", err)
 		return err
 	}
 	if exists {
@@ -121,7 +121,7 @@ func (vcs *VerificationCodeStorage) ensureTable() error {
 	}
 
 	if _, err = vcs.db.C.CreateTable(createTableInput); err != nil {
-		log.Println("Error creating table:", err)
+		logging.Error("Error creating table:", err)
 		return err
 	}
 
@@ -139,9 +139,9 @@ func (vcs *VerificationCodeStorage) ensureTable() error {
 			// Then Verification Codes table must be in creating status. Let's give it some time.
 			for i := 0; i < 5; i++ {
 				time.Sleep(5 * time.Second)
-				log.Println("Retry setting expiration time...")
+				logging.Info("Retry setting expiration time...")
 				if _, err = vcs.db.C.UpdateTimeToLive(ttlInput); err == nil {
-					log.Println("Expiration time successfully set")
+					logging.Info("Expiration time successfully set")
 					break
 				}
 			}
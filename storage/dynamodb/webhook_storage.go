@@ -0,0 +1,294 @@
+package dynamodb
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+const (
+	// webhooksTableName is a table name for webhooks.
+	webhooksTableName = "Webhooks"
+	// webhookDeliveriesTableName is a table name for webhook deliveries.
+	webhookDeliveriesTableName = "WebhookDeliveries"
+)
+
+// NewWebhookStorage creates and provisions new DynamoDB webhook storage.
+func NewWebhookStorage(db *DB) (model.WebhookStorage, error) {
+	ws := &WebhookStorage{db: db}
+	for _, table := range []string{webhooksTableName, webhookDeliveriesTableName} {
+		if err := ws.ensureTable(table); err != nil {
+			return ws, err
+		}
+	}
+	return ws, nil
+}
+
+// WebhookStorage implements webhook storage interface.
+type WebhookStorage struct {
+	db *DB
+}
+
+// AddWebhook saves new webhook.
+func (ws *WebhookStorage) AddWebhook(webhook model.Webhook) (model.Webhook, error) {
+	webhook.ID = xid.New().String()
+	if err := ws.put(webhooksTableName, webhook, "attribute_not_exists(id)", nil); err != nil {
+		return model.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// WebhookByID returns webhook by ID.
+func (ws *WebhookStorage) WebhookByID(id string) (model.Webhook, error) {
+	var webhook model.Webhook
+	err := ws.get(webhooksTableName, id, &webhook, model.ErrWebhookNotFound)
+	return webhook, err
+}
+
+// FetchWebhooks returns all webhooks, oldest first.
+func (ws *WebhookStorage) FetchWebhooks() ([]model.Webhook, error) {
+	webhooks := []model.Webhook{}
+	if err := ws.db.C.ScanPages(&dynamodb.ScanInput{
+		TableName: aws.String(webhooksTableName),
+	}, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageWebhooks := []model.Webhook{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageWebhooks); err != nil {
+			logging.Error("Error unmarshalling webhooks:", err)
+			return false
+		}
+		webhooks = append(webhooks, pageWebhooks...)
+		return true
+	}); err != nil {
+		logging.Error("Error querying for webhooks:", err)
+		return []model.Webhook{}, ErrorInternalError
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+// UpdateWebhook replaces stored webhook.
+func (ws *WebhookStorage) UpdateWebhook(webhook model.Webhook) (model.Webhook, error) {
+	if err := ws.put(webhooksTableName, webhook, "attribute_exists(id)", model.ErrWebhookNotFound); err != nil {
+		return model.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook deletes webhook.
+func (ws *WebhookStorage) DeleteWebhook(id string) error {
+	_, err := ws.db.C.DeleteItem(&dynamodb.DeleteItemInput{
+		TableName: aws.String(webhooksTableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+		ConditionExpression: aws.String("attribute_exists(id)"),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return model.ErrWebhookNotFound
+	}
+	if err != nil {
+		logging.Error("Error deleting webhook:", err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+// AddWebhookDelivery saves new delivery.
+func (ws *WebhookStorage) AddWebhookDelivery(delivery model.WebhookDelivery) (model.WebhookDelivery, error) {
+	delivery.ID = xid.New().String()
+	if err := ws.put(webhookDeliveriesTableName, delivery, "attribute_not_exists(id)", nil); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// WebhookDeliveryByID returns delivery by ID.
+func (ws *WebhookStorage) WebhookDeliveryByID(id string) (model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := ws.get(webhookDeliveriesTableName, id, &delivery, model.ErrWebhookDeliveryNotFound)
+	return delivery, err
+}
+
+// UpdateWebhookDelivery replaces stored delivery.
+func (ws *WebhookStorage) UpdateWebhookDelivery(delivery model.WebhookDelivery) error {
+	return ws.put(webhookDeliveriesTableName, delivery, "attribute_exists(id)", model.ErrWebhookDeliveryNotFound)
+}
+
+// FetchWebhookDeliveries returns deliveries matching the filter, newest first.
+func (ws *WebhookStorage) FetchWebhookDeliveries(filter model.WebhookDeliveryFilter, skip, limit int) ([]model.WebhookDelivery, int, error) {
+	scanInput := &dynamodb.ScanInput{
+		TableName: aws.String(webhookDeliveriesTableName),
+	}
+
+	conditions := []string{}
+	values := map[string]*dynamodb.AttributeValue{}
+	if filter.WebhookID != "" {
+		conditions = append(conditions, "webhook_id = :webhook_id")
+		values[":webhook_id"] = &dynamodb.AttributeValue{S: aws.String(filter.WebhookID)}
+	}
+	if filter.Status != "" {
+		// Status is a reserved word in DynamoDB.
+		conditions = append(conditions, "#status = :status")
+		values[":status"] = &dynamodb.AttributeValue{S: aws.String(string(filter.Status))}
+		scanInput.ExpressionAttributeNames = map[string]*string{"#status": aws.String("status")}
+	}
+	if len(conditions) > 0 {
+		filterExpression := conditions[0]
+		for _, c := range conditions[1:] {
+			filterExpression += " AND " + c
+		}
+		scanInput.FilterExpression = aws.String(filterExpression)
+		scanInput.ExpressionAttributeValues = values
+	}
+
+	deliveries, err := ws.scanDeliveries(scanInput)
+	if err != nil {
+		return []model.WebhookDelivery{}, 0, err
+	}
+
+	// IDs are xids, which are sortable by creation time.
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+
+	total := len(deliveries)
+	if skip > total {
+		skip = total
+	}
+	deliveries = deliveries[skip:]
+	if limit != 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, total, nil
+}
+
+// PendingWebhookDeliveries returns pending deliveries due at the time, oldest first.
+func (ws *WebhookStorage) PendingWebhookDeliveries(now int64, limit int) ([]model.WebhookDelivery, error) {
+	deliveries, err := ws.scanDeliveries(&dynamodb.ScanInput{
+		TableName:        aws.String(webhookDeliveriesTableName),
+		FilterExpression: aws.String("#status = :status AND (attribute_not_exists(next_attempt_at) OR next_attempt_at <= :now)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#status": aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":status": {S: aws.String(string(model.WebhookDeliveryPending))},
+			":now":    {N: aws.String(strconv.FormatInt(now, 10))},
+		},
+	})
+	if err != nil {
+		return []model.WebhookDelivery{}, err
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	if limit != 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// Close does nothing here.
+func (ws *WebhookStorage) Close() {}
+
+// get loads the item by ID into value, returning errNotFound for missing items.
+func (ws *WebhookStorage) get(table, id string, value interface{}, errNotFound error) error {
+	result, err := ws.db.C.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(id)},
+		},
+	})
+	if err != nil {
+		logging.Errorf("Error getting item from %s: %s\n", table, err)
+		return ErrorInternalError
+	}
+	if result.Item == nil {
+		return errNotFound
+	}
+
+	if err = dynamodbattribute.UnmarshalMap(result.Item, value); err != nil {
+		logging.Errorf("Error unmarshalling item from %s: %s\n", table, err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+// put saves the item, returning errNotFound if the condition fails.
+func (ws *WebhookStorage) put(table string, value interface{}, condition string, errNotFound error) error {
+	item, err := dynamodbattribute.MarshalMap(value)
+	if err != nil {
+		logging.Errorf("Error marshalling item for %s: %s\n", table, err)
+		return ErrorInternalError
+	}
+
+	_, err = ws.db.C.PutItem(&dynamodb.PutItemInput{
+		Item:                item,
+		TableName:           aws.String(table),
+		ConditionExpression: aws.String(condition),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException && errNotFound != nil {
+		return errNotFound
+	}
+	if err != nil {
+		logging.Errorf("Error putting item to %s: %s\n", table, err)
+		return ErrorInternalError
+	}
+	return nil
+}
+
+func (ws *WebhookStorage) scanDeliveries(scanInput *dynamodb.ScanInput) ([]model.WebhookDelivery, error) {
+	deliveries := []model.WebhookDelivery{}
+	if err := ws.db.C.ScanPages(scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		pageDeliveries := []model.WebhookDelivery{}
+		if err := dynamodbattribute.UnmarshalListOfMaps(page.Items, &pageDeliveries); err != nil {
+			logging.Error("Error unmarshalling webhook deliveries:", err)
+			return false
+		}
+		deliveries = append(deliveries, pageDeliveries...)
+		return true
+	}); err != nil {
+		logging.Error("Error querying for webhook deliveries:", err)
+		return nil, ErrorInternalError
+	}
+	return deliveries, nil
+}
+
+// ensureTable ensures that the webhook storage table exists in the database.
+func (ws *WebhookStorage) ensureTable(table string) error {
+	exists, err := ws.db.IsTableExists(table)
+	if err != nil {
+		logging.Errorf("Error checking for %s table existence: %s\n", table, err)
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	createTableInput := &dynamodb.CreateTableInput{
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		BillingMode: aws.String("PAY_PER_REQUEST"),
+		TableName:   aws.String(table),
+	}
+
+	if _, err = ws.db.C.CreateTable(createTableInput); err != nil {
+		logging.Errorf("Error creating %s table: %s\n", table, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package mem
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// NewAdminStorage creates and inits in-memory admin storage.
+func NewAdminStorage() (model.AdminStorage, error) {
+	return &AdminStorage{admins: make(map[string]model.Admin)}, nil
+}
+
+// AdminStorage is an in-memory admin storage.
+// It keeps data, so admins created on first login can log in again.
+type AdminStorage struct {
+	sync.RWMutex
+	admins map[string]model.Admin
+}
+
+// AddAdmin saves new admin.
+func (as *AdminStorage) AddAdmin(admin model.Admin) (model.Admin, error) {
+	as.Lock()
+	defer as.Unlock()
+
+	for _, a := range as.admins {
+		if a.Email == admin.Email {
+			return model.Admin{}, model.ErrAdminExists
+		}
+	}
+
+	admin.ID = xid.New().String()
+	as.admins[admin.ID] = admin
+	return admin, nil
+}
+
+// AdminByID returns admin by ID.
+func (as *AdminStorage) AdminByID(id string) (model.Admin, error) {
+	as.RLock()
+	defer as.RUnlock()
+
+	admin, ok := as.admins[id]
+	if !ok {
+		return admin, model.ErrAdminNotFound
+	}
+	return admin, nil
+}
+
+// AdminByEmail returns admin by email.
+func (as *AdminStorage) AdminByEmail(email string) (model.Admin, error) {
+	as.RLock()
+	defer as.RUnlock()
+
+	for _, admin := range as.admins {
+		if admin.Email == email {
+			return admin, nil
+		}
+	}
+	return model.Admin{}, model.ErrAdminNotFound
+}
+
+// FetchAdmins returns all admins, oldest first.
+func (as *AdminStorage) FetchAdmins() ([]model.Admin, error) {
+	as.RLock()
+	admins := make([]model.Admin, 0, len(as.admins))
+	for _, admin := range as.admins {
+		admins = append(admins, admin)
+	}
+	as.RUnlock()
+
+	sort.Slice(admins, func(i, j int) bool { return admins[i].CreatedAt < admins[j].CreatedAt })
+	return admins, nil
+}
+
+// UpdateAdmin replaces stored admin.
+func (as *AdminStorage) UpdateAdmin(admin model.Admin) (model.Admin, error) {
+	as.Lock()
+	defer as.Unlock()
+
+	if _, ok := as.admins[admin.ID]; !ok {
+		return model.Admin{}, model.ErrAdminNotFound
+	}
+	as.admins[admin.ID] = admin
+	return admin, nil
+}
+
+// DeleteAdmin deletes admin.
+func (as *AdminStorage) DeleteAdmin(id string) error {
+	as.Lock()
+	defer as.Unlock()
+
+	delete(as.admins, id)
+	return nil
+}
+
+// Close does nothing here.
+func (as *AdminStorage) Close() {}
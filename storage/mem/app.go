@@ -34,6 +34,9 @@ type appData struct {
 	RolesBlacklist               []string               `json:"roles_blacklist,omitempty"`
 	NewUserDefaultRole           string                 `json:"new_user_default_role,omitempty"`
 	AppleInfo                    *model.AppleInfo       `json:"apple_info,omitempty"`
+	GoogleInfo                   *model.GoogleInfo      `json:"google_info,omitempty"`
+	GitHubInfo                   *model.GitHubInfo      `json:"github_info,omitempty"`
+	OIDCInfo                     *model.OIDCInfo        `json:"oidc_info,omitempty"`
 }
 
 // NewAppData instantiates app data in-memory model from the general one.
@@ -53,6 +56,10 @@ func NewAppData(data model.AppData) AppData {
 		TokenPayload:                 data.TokenPayload(),
 		RegistrationForbidden:        data.RegistrationForbidden(),
 		AnonymousRegistrationAllowed: data.AnonymousRegistrationAllowed(),
+		AppleInfo:                    data.AppleInfo(),
+		GoogleInfo:                   data.GoogleInfo(),
+		GitHubInfo:                   data.GitHubInfo(),
+		OIDCInfo:                     data.OIDCInfo(),
 	}}
 }
 
@@ -159,6 +166,15 @@ func (ad *AppData) NewUserDefaultRole() string { return ad.appData.NewUserDefaul
 // AppleInfo implements model.AppData interface.
 func (ad *AppData) AppleInfo() *model.AppleInfo { return ad.appData.AppleInfo }
 
+// GoogleInfo implements model.AppData interface.
+func (ad *AppData) GoogleInfo() *model.GoogleInfo { return ad.appData.GoogleInfo }
+
+// GitHubInfo implements model.AppData interface.
+func (ad *AppData) GitHubInfo() *model.GitHubInfo { return ad.appData.GitHubInfo }
+
+// OIDCInfo implements model.AppData interface.
+func (ad *AppData) OIDCInfo() *model.OIDCInfo { return ad.appData.OIDCInfo }
+
 // SetSecret implements model.AppData interface.
 func (ad *AppData) SetSecret(secret string) {
 	if ad == nil {
@@ -176,6 +192,15 @@ func (ad *AppData) Sanitize() {
 	if ad.appData.AppleInfo != nil {
 		ad.appData.AppleInfo.ClientSecret = ""
 	}
+	if ad.appData.GoogleInfo != nil {
+		ad.appData.GoogleInfo.ClientSecret = ""
+	}
+	if ad.appData.GitHubInfo != nil {
+		ad.appData.GitHubInfo.ClientSecret = ""
+	}
+	if ad.appData.OIDCInfo != nil {
+		ad.appData.OIDCInfo.ClientSecret = ""
+	}
 
 	ad.appData.AuthorizationWay = ""
 	ad.appData.AuthorizationModel = ""
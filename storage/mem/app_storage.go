@@ -2,9 +2,9 @@ package mem
 
 import (
 	"encoding/json"
-	"log"
 	"strings"
 
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	"github.com/rs/xid"
 )
@@ -125,7 +125,7 @@ func (as *AppStorage) TestDatabaseConnection() error {
 func (as *AppStorage) ImportJSON(data []byte) error {
 	apd := []appData{}
 	if err := json.Unmarshal(data, &apd); err != nil {
-		log.Println("Error unmarshalling app data:", err)
+		logging.Error("Error unmarshalling app data:", err)
 		return err
 	}
 	for _, a := range apd {
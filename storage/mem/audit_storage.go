@@ -0,0 +1,59 @@
+package mem
+
+import (
+	"sync"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// NewAuditStorage creates and inits in-memory audit storage.
+func NewAuditStorage() (model.AuditStorage, error) {
+	return &AuditStorage{}, nil
+}
+
+// AuditStorage is an in-memory audit storage.
+type AuditStorage struct {
+	sync.RWMutex
+	events []model.AuditEvent
+}
+
+// AddAuditEvent saves new event.
+func (as *AuditStorage) AddAuditEvent(event model.AuditEvent) (model.AuditEvent, error) {
+	as.Lock()
+	defer as.Unlock()
+
+	event.ID = xid.New().String()
+	as.events = append(as.events, event)
+	return event, nil
+}
+
+// FetchAuditEvents returns events matching the filter, newest first.
+func (as *AuditStorage) FetchAuditEvents(filter model.AuditEventFilter, skip, limit int) ([]model.AuditEvent, int, error) {
+	as.RLock()
+	events := []model.AuditEvent{}
+	for _, event := range as.events {
+		if filter.Matches(event) {
+			events = append(events, event)
+		}
+	}
+	as.RUnlock()
+
+	// Events are appended in creation order.
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+
+	total := len(events)
+	if skip > total {
+		skip = total
+	}
+	events = events[skip:]
+	if limit != 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events, total, nil
+}
+
+// Close does nothing here.
+func (as *AuditStorage) Close() {}
@@ -0,0 +1,59 @@
+package mem
+
+import (
+	"sync"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// NewAuthEventStorage creates and inits in-memory auth event storage.
+func NewAuthEventStorage() (model.AuthEventStorage, error) {
+	return &AuthEventStorage{}, nil
+}
+
+// AuthEventStorage is an in-memory auth event storage.
+type AuthEventStorage struct {
+	sync.RWMutex
+	events []model.AuthEvent
+}
+
+// AddAuthEvent saves new event.
+func (aes *AuthEventStorage) AddAuthEvent(event model.AuthEvent) (model.AuthEvent, error) {
+	aes.Lock()
+	defer aes.Unlock()
+
+	event.ID = xid.New().String()
+	aes.events = append(aes.events, event)
+	return event, nil
+}
+
+// FetchAuthEvents returns events matching the filter, newest first.
+func (aes *AuthEventStorage) FetchAuthEvents(filter model.AuthEventFilter, skip, limit int) ([]model.AuthEvent, int, error) {
+	aes.RLock()
+	events := []model.AuthEvent{}
+	for _, event := range aes.events {
+		if filter.Matches(event) {
+			events = append(events, event)
+		}
+	}
+	aes.RUnlock()
+
+	// Events are appended in creation order.
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+
+	total := len(events)
+	if skip > total {
+		skip = total
+	}
+	events = events[skip:]
+	if limit != 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events, total, nil
+}
+
+// Close does nothing here.
+func (aes *AuthEventStorage) Close() {}
@@ -0,0 +1,105 @@
+package mem
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// NewInviteStorage creates and inits in-memory invite storage.
+func NewInviteStorage() (model.InviteStorage, error) {
+	return &InviteStorage{invites: make(map[string]model.Invite)}, nil
+}
+
+// InviteStorage is an in-memory invite storage.
+// Unlike other in-memory storages it keeps data, so invites can be tested end-to-end.
+type InviteStorage struct {
+	sync.RWMutex
+	invites map[string]model.Invite
+}
+
+// AddInvite saves new invite.
+func (is *InviteStorage) AddInvite(invite model.Invite) (model.Invite, error) {
+	is.Lock()
+	defer is.Unlock()
+
+	invite.ID = xid.New().String()
+	is.invites[invite.ID] = invite
+	return invite, nil
+}
+
+// InviteByID returns invite by its ID.
+func (is *InviteStorage) InviteByID(id string) (model.Invite, error) {
+	is.RLock()
+	defer is.RUnlock()
+
+	invite, ok := is.invites[id]
+	if !ok {
+		return invite, model.ErrInviteNotFound
+	}
+	return invite, nil
+}
+
+// FetchInvites fetches invites, optionally filtered by app ID and email, newest first.
+func (is *InviteStorage) FetchInvites(appID, email string, skip, limit int) ([]model.Invite, int, error) {
+	is.RLock()
+	invites := []model.Invite{}
+	for _, invite := range is.invites {
+		if (appID == "" || invite.AppID == appID) && (email == "" || invite.Email == email) {
+			invites = append(invites, invite)
+		}
+	}
+	is.RUnlock()
+
+	sort.Slice(invites, func(i, j int) bool { return invites[i].CreatedAt > invites[j].CreatedAt })
+
+	total := len(invites)
+	if skip > total {
+		skip = total
+	}
+	invites = invites[skip:]
+	if limit != 0 && len(invites) > limit {
+		invites = invites[:limit]
+	}
+	return invites, total, nil
+}
+
+// RevokeInvite marks invite as revoked.
+func (is *InviteStorage) RevokeInvite(id string) error {
+	is.Lock()
+	defer is.Unlock()
+
+	invite, ok := is.invites[id]
+	if !ok {
+		return model.ErrInviteNotFound
+	}
+	invite.Revoked = true
+	is.invites[id] = invite
+	return nil
+}
+
+// ConsumeInvite marks valid invite as used by the user.
+func (is *InviteStorage) ConsumeInvite(id, userID string) error {
+	is.Lock()
+	defer is.Unlock()
+
+	invite, ok := is.invites[id]
+	if !ok {
+		return model.ErrInviteNotFound
+	}
+
+	now := time.Now().Unix()
+	if !invite.IsValid(now) {
+		return model.ErrInviteNotValid
+	}
+	invite.UsedBy = userID
+	invite.UsedAt = now
+	is.invites[id] = invite
+	return nil
+}
+
+// Close does nothing here.
+func (is *InviteStorage) Close() {}
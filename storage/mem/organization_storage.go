@@ -0,0 +1,170 @@
+package mem
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// NewOrganizationStorage creates and inits in-memory organization storage.
+func NewOrganizationStorage() (model.OrganizationStorage, error) {
+	return &OrganizationStorage{
+		organizations: make(map[string]model.Organization),
+		members:       make(map[string]map[string]model.OrganizationMember),
+	}, nil
+}
+
+// OrganizationStorage is an in-memory organization storage.
+type OrganizationStorage struct {
+	sync.RWMutex
+	organizations map[string]model.Organization
+	// members are keyed by organization ID and user ID.
+	members map[string]map[string]model.OrganizationMember
+}
+
+// AddOrganization saves new organization.
+func (os *OrganizationStorage) AddOrganization(org model.Organization) (model.Organization, error) {
+	os.Lock()
+	defer os.Unlock()
+
+	org.ID = xid.New().String()
+	os.organizations[org.ID] = org
+	return org, nil
+}
+
+// OrganizationByID returns organization by ID.
+func (os *OrganizationStorage) OrganizationByID(id string) (model.Organization, error) {
+	os.RLock()
+	defer os.RUnlock()
+
+	org, ok := os.organizations[id]
+	if !ok {
+		return org, model.ErrOrganizationNotFound
+	}
+	return org, nil
+}
+
+// FetchOrganizations returns organizations which name contains search string, oldest first.
+func (os *OrganizationStorage) FetchOrganizations(search string, skip, limit int) ([]model.Organization, int, error) {
+	os.RLock()
+	defer os.RUnlock()
+
+	orgs := []model.Organization{}
+	for _, org := range os.organizations {
+		if strings.Contains(strings.ToLower(org.Name), strings.ToLower(search)) {
+			orgs = append(orgs, org)
+		}
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].ID < orgs[j].ID })
+
+	total := len(orgs)
+	if skip > total {
+		skip = total
+	}
+	orgs = orgs[skip:]
+	if limit > 0 && len(orgs) > limit {
+		orgs = orgs[:limit]
+	}
+	return orgs, total, nil
+}
+
+// UpdateOrganization replaces the organization.
+func (os *OrganizationStorage) UpdateOrganization(org model.Organization) (model.Organization, error) {
+	os.Lock()
+	defer os.Unlock()
+
+	if _, ok := os.organizations[org.ID]; !ok {
+		return model.Organization{}, model.ErrOrganizationNotFound
+	}
+	os.organizations[org.ID] = org
+	return org, nil
+}
+
+// DeleteOrganization deletes the organization with its members.
+func (os *OrganizationStorage) DeleteOrganization(id string) error {
+	os.Lock()
+	defer os.Unlock()
+
+	if _, ok := os.organizations[id]; !ok {
+		return model.ErrOrganizationNotFound
+	}
+	delete(os.organizations, id)
+	delete(os.members, id)
+	return nil
+}
+
+// SetMember adds the member or changes its role.
+func (os *OrganizationStorage) SetMember(member model.OrganizationMember) (model.OrganizationMember, error) {
+	os.Lock()
+	defer os.Unlock()
+
+	if _, ok := os.organizations[member.OrgID]; !ok {
+		return model.OrganizationMember{}, model.ErrOrganizationNotFound
+	}
+	if os.members[member.OrgID] == nil {
+		os.members[member.OrgID] = make(map[string]model.OrganizationMember)
+	}
+	if existing, ok := os.members[member.OrgID][member.UserID]; ok {
+		member.JoinedAt = existing.JoinedAt
+	}
+	os.members[member.OrgID][member.UserID] = member
+	return member, nil
+}
+
+// Member returns the membership of the user in the organization.
+func (os *OrganizationStorage) Member(orgID, userID string) (model.OrganizationMember, error) {
+	os.RLock()
+	defer os.RUnlock()
+
+	member, ok := os.members[orgID][userID]
+	if !ok {
+		return member, model.ErrOrganizationMemberNotFound
+	}
+	return member, nil
+}
+
+// FetchMembers returns organization members, earliest joined first.
+func (os *OrganizationStorage) FetchMembers(orgID string) ([]model.OrganizationMember, error) {
+	os.RLock()
+	defer os.RUnlock()
+
+	members := []model.OrganizationMember{}
+	for _, member := range os.members[orgID] {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].JoinedAt < members[j].JoinedAt })
+	return members, nil
+}
+
+// UserMemberships returns all memberships of the user.
+func (os *OrganizationStorage) UserMemberships(userID string) ([]model.OrganizationMember, error) {
+	os.RLock()
+	defer os.RUnlock()
+
+	memberships := []model.OrganizationMember{}
+	for _, members := range os.members {
+		if member, ok := members[userID]; ok {
+			memberships = append(memberships, member)
+		}
+	}
+	sort.Slice(memberships, func(i, j int) bool { return memberships[i].JoinedAt < memberships[j].JoinedAt })
+	return memberships, nil
+}
+
+// RemoveMember removes the user from the organization.
+func (os *OrganizationStorage) RemoveMember(orgID, userID string) error {
+	os.Lock()
+	defer os.Unlock()
+
+	if _, ok := os.members[orgID][userID]; !ok {
+		return model.ErrOrganizationMemberNotFound
+	}
+	delete(os.members[orgID], userID)
+	return nil
+}
+
+// Close does nothing here.
+func (os *OrganizationStorage) Close() {}
@@ -0,0 +1,84 @@
+package mem
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// NewPolicyStorage creates and inits in-memory policy storage.
+func NewPolicyStorage() (model.PolicyStorage, error) {
+	return &PolicyStorage{rules: make(map[string]model.PolicyRule)}, nil
+}
+
+// PolicyStorage is an in-memory policy storage.
+type PolicyStorage struct {
+	sync.RWMutex
+	rules map[string]model.PolicyRule
+}
+
+// AddPolicyRule saves new rule.
+func (ps *PolicyStorage) AddPolicyRule(rule model.PolicyRule) (model.PolicyRule, error) {
+	ps.Lock()
+	defer ps.Unlock()
+
+	rule.ID = xid.New().String()
+	ps.rules[rule.ID] = rule
+	return rule, nil
+}
+
+// PolicyRuleByID returns rule by its ID.
+func (ps *PolicyStorage) PolicyRuleByID(id string) (model.PolicyRule, error) {
+	ps.RLock()
+	defer ps.RUnlock()
+
+	rule, ok := ps.rules[id]
+	if !ok {
+		return model.PolicyRule{}, model.ErrPolicyRuleNotFound
+	}
+	return rule, nil
+}
+
+// FetchPolicyRules returns all rules of the app, oldest first.
+func (ps *PolicyStorage) FetchPolicyRules(appID string) ([]model.PolicyRule, error) {
+	ps.RLock()
+	defer ps.RUnlock()
+
+	rules := []model.PolicyRule{}
+	for _, rule := range ps.rules {
+		if rule.AppID == appID {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules, nil
+}
+
+// UpdatePolicyRule updates the rule.
+func (ps *PolicyStorage) UpdatePolicyRule(rule model.PolicyRule) (model.PolicyRule, error) {
+	ps.Lock()
+	defer ps.Unlock()
+
+	if _, ok := ps.rules[rule.ID]; !ok {
+		return model.PolicyRule{}, model.ErrPolicyRuleNotFound
+	}
+	ps.rules[rule.ID] = rule
+	return rule, nil
+}
+
+// DeletePolicyRule deletes the rule.
+func (ps *PolicyStorage) DeletePolicyRule(id string) error {
+	ps.Lock()
+	defer ps.Unlock()
+
+	if _, ok := ps.rules[id]; !ok {
+		return model.ErrPolicyRuleNotFound
+	}
+	delete(ps.rules, id)
+	return nil
+}
+
+// Close does nothing here.
+func (ps *PolicyStorage) Close() {}
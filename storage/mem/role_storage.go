@@ -0,0 +1,129 @@
+package mem
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// NewRoleStorage creates and inits in-memory role storage.
+func NewRoleStorage() (model.RoleStorage, error) {
+	return &RoleStorage{
+		roles:     make(map[string]model.Role),
+		userRoles: make(map[string][]string),
+	}, nil
+}
+
+// RoleStorage is an in-memory role storage.
+type RoleStorage struct {
+	sync.RWMutex
+	roles map[string]model.Role
+	// userRoles are keyed by app ID and user ID.
+	userRoles map[string][]string
+}
+
+func userRolesKey(appID, userID string) string {
+	return appID + "/" + userID
+}
+
+// AddRole saves new role.
+func (rs *RoleStorage) AddRole(role model.Role) (model.Role, error) {
+	rs.Lock()
+	defer rs.Unlock()
+
+	role.ID = xid.New().String()
+	rs.roles[role.ID] = role
+	return role, nil
+}
+
+// RoleByID returns role by its ID.
+func (rs *RoleStorage) RoleByID(id string) (model.Role, error) {
+	rs.RLock()
+	defer rs.RUnlock()
+
+	role, ok := rs.roles[id]
+	if !ok {
+		return model.Role{}, model.ErrRoleNotFound
+	}
+	return role, nil
+}
+
+// FetchRoles returns all roles of the app, sorted by name.
+func (rs *RoleStorage) FetchRoles(appID string) ([]model.Role, error) {
+	rs.RLock()
+	defer rs.RUnlock()
+
+	roles := []model.Role{}
+	for _, role := range rs.roles {
+		if role.AppID == appID {
+			roles = append(roles, role)
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+// UpdateRole updates the role.
+func (rs *RoleStorage) UpdateRole(role model.Role) (model.Role, error) {
+	rs.Lock()
+	defer rs.Unlock()
+
+	if _, ok := rs.roles[role.ID]; !ok {
+		return model.Role{}, model.ErrRoleNotFound
+	}
+	rs.roles[role.ID] = role
+	return role, nil
+}
+
+// DeleteRole deletes the role and unassigns it from the users.
+func (rs *RoleStorage) DeleteRole(id string) error {
+	rs.Lock()
+	defer rs.Unlock()
+
+	role, ok := rs.roles[id]
+	if !ok {
+		return model.ErrRoleNotFound
+	}
+	delete(rs.roles, id)
+
+	prefix := userRolesKey(role.AppID, "")
+	for key, names := range rs.userRoles {
+		if len(key) < len(prefix) || key[:len(prefix)] != prefix {
+			continue
+		}
+		kept := []string{}
+		for _, name := range names {
+			if name != role.Name {
+				kept = append(kept, name)
+			}
+		}
+		rs.userRoles[key] = kept
+	}
+	return nil
+}
+
+// UserRoles returns names of the roles assigned to the user in the app.
+func (rs *RoleStorage) UserRoles(appID, userID string) ([]string, error) {
+	rs.RLock()
+	defer rs.RUnlock()
+
+	return append([]string{}, rs.userRoles[userRolesKey(appID, userID)]...), nil
+}
+
+// SetUserRoles replaces the roles assigned to the user in the app.
+func (rs *RoleStorage) SetUserRoles(appID, userID string, roles []string) error {
+	rs.Lock()
+	defer rs.Unlock()
+
+	if len(roles) == 0 {
+		delete(rs.userRoles, userRolesKey(appID, userID))
+		return nil
+	}
+	rs.userRoles[userRolesKey(appID, userID)] = append([]string{}, roles...)
+	return nil
+}
+
+// Close does nothing here.
+func (rs *RoleStorage) Close() {}
@@ -0,0 +1,71 @@
+package mem
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// NewSCIMTokenStorage creates and inits in-memory SCIM token storage.
+func NewSCIMTokenStorage() (model.SCIMTokenStorage, error) {
+	return &SCIMTokenStorage{tokens: make(map[string]model.SCIMToken)}, nil
+}
+
+// SCIMTokenStorage is an in-memory SCIM token storage.
+type SCIMTokenStorage struct {
+	sync.RWMutex
+	tokens map[string]model.SCIMToken
+}
+
+// AddSCIMToken saves new token.
+func (ss *SCIMTokenStorage) AddSCIMToken(token model.SCIMToken) (model.SCIMToken, error) {
+	ss.Lock()
+	defer ss.Unlock()
+
+	token.ID = xid.New().String()
+	ss.tokens[token.ID] = token
+	return token, nil
+}
+
+// SCIMTokenByHash returns token by its hash.
+func (ss *SCIMTokenStorage) SCIMTokenByHash(hash string) (model.SCIMToken, error) {
+	ss.RLock()
+	defer ss.RUnlock()
+
+	for _, token := range ss.tokens {
+		if token.Hash == hash {
+			return token, nil
+		}
+	}
+	return model.SCIMToken{}, model.ErrSCIMTokenNotFound
+}
+
+// FetchSCIMTokens returns all tokens, oldest first.
+func (ss *SCIMTokenStorage) FetchSCIMTokens() ([]model.SCIMToken, error) {
+	ss.RLock()
+	defer ss.RUnlock()
+
+	tokens := make([]model.SCIMToken, 0, len(ss.tokens))
+	for _, token := range ss.tokens {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+	return tokens, nil
+}
+
+// DeleteSCIMToken deletes the token.
+func (ss *SCIMTokenStorage) DeleteSCIMToken(id string) error {
+	ss.Lock()
+	defer ss.Unlock()
+
+	if _, ok := ss.tokens[id]; !ok {
+		return model.ErrSCIMTokenNotFound
+	}
+	delete(ss.tokens, id)
+	return nil
+}
+
+// Close does nothing here.
+func (ss *SCIMTokenStorage) Close() {}
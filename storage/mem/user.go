@@ -7,15 +7,20 @@ import (
 
 // User data implementation.
 type userData struct {
-	ID         string        `json:"id,omitempty"`
-	Username   string        `json:"username,omitempty"`
-	Email      string        `json:"email,omitempty"`
-	Phone      string        `json:"phone,omitempty"`
-	Pswd       string        `json:"pswd,omitempty"`
-	Active     bool          `json:"active,omitempty"`
-	TFAInfo    model.TFAInfo `json:"tfa_info"`
-	AccessRole string        `json:"access_role,omitempty"`
-	Anonymous  bool          `json:"anonymous,omitempty"`
+	ID               string                 `json:"id,omitempty"`
+	Username         string                 `json:"username,omitempty"`
+	Email            string                 `json:"email,omitempty"`
+	Phone            string                 `json:"phone,omitempty"`
+	FederatedIDs     []string               `json:"federated_ids,omitempty"`
+	Pswd             string                 `json:"pswd,omitempty"`
+	Active           bool                   `json:"active,omitempty"`
+	TFAInfo          model.TFAInfo          `json:"tfa_info"`
+	AccessRole       string                 `json:"access_role,omitempty"`
+	Anonymous        bool                   `json:"anonymous,omitempty"`
+	TokensValidAfter int64                  `json:"tokens_valid_after,omitempty"`
+	UserMetadata     map[string]interface{} `json:"user_metadata,omitempty"`
+	AppMetadata      map[string]interface{} `json:"app_metadata,omitempty"`
+	Attributes       map[string]interface{} `json:"attributes,omitempty"`
 }
 
 type user struct {
@@ -45,6 +50,9 @@ func (u *user) SetEmail(email string) { u.userData.Email = email }
 // Phone implements model.User interface.
 func (u *user) Phone() string { return u.userData.Phone }
 
+// FederatedIDs implements model.User interface.
+func (u *user) FederatedIDs() []string { return u.userData.FederatedIDs }
+
 // TFAInfo implements model.User interface.
 func (u *user) TFAInfo() model.TFAInfo { return u.userData.TFAInfo }
 
@@ -57,6 +65,9 @@ func (u *user) PasswordHash() string { return u.userData.Pswd }
 // Active implements model.User interface.
 func (u *user) Active() bool { return u.userData.Active }
 
+// SetActive implements model.User interface.
+func (u *user) SetActive(active bool) { u.userData.Active = active }
+
 // AccessRole implements model.User interface.
 func (u *user) AccessRole() string { return u.userData.AccessRole }
 
@@ -66,6 +77,28 @@ func (u *user) IsAnonymous() bool { return u.userData.Anonymous }
 // Deanonimize implements model.User interface.
 func (u *user) Deanonimize() { u.userData.Anonymous = false }
 
+// TokensValidAfter implements model.User interface.
+func (u *user) TokensValidAfter() int64 { return u.userData.TokensValidAfter }
+
+// UserMetadata implements model.User interface.
+func (u *user) UserMetadata() map[string]interface{} { return u.userData.UserMetadata }
+
+// SetUserMetadata implements model.User interface.
+func (u *user) SetUserMetadata(metadata map[string]interface{}) { u.userData.UserMetadata = metadata }
+
+// AppMetadata implements model.User interface.
+func (u *user) AppMetadata() map[string]interface{} { return u.userData.AppMetadata }
+
+// SetAppMetadata implements model.User interface.
+func (u *user) SetAppMetadata(metadata map[string]interface{}) { u.userData.AppMetadata = metadata }
+
+// Attributes implements model.User interface.
+func (u *user) Attributes() map[string]interface{} { return u.userData.Attributes }
+
+// SetAttributes implements model.User interface.
+func (u *user) SetAttributes(attributes map[string]interface{}) { u.userData.Attributes = attributes }
+
+// randUser returns random active user, so its tokens are accepted.
 func randUser() *user {
 	return &user{
 		userData: userData{
@@ -73,7 +106,7 @@ func randUser() *user {
 			Username: randomdata.SillyName(),
 			Email:    randomdata.Email(),
 			Pswd:     randomdata.StringNumber(2, "-"),
-			Active:   randomdata.Boolean(),
+			Active:   true,
 		},
 	}
 }
@@ -0,0 +1,97 @@
+package mem
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// NewUserSessionStorage creates and inits in-memory user session storage.
+func NewUserSessionStorage() (model.UserSessionStorage, error) {
+	return &UserSessionStorage{sessions: make(map[string]model.UserSession)}, nil
+}
+
+// UserSessionStorage is an in-memory user session storage.
+type UserSessionStorage struct {
+	sync.RWMutex
+	sessions map[string]model.UserSession
+}
+
+// AddUserSession saves new session.
+func (ss *UserSessionStorage) AddUserSession(session model.UserSession) (model.UserSession, error) {
+	ss.Lock()
+	defer ss.Unlock()
+
+	session.ID = xid.New().String()
+	ss.sessions[session.ID] = session
+	return session, nil
+}
+
+// UserSessionByID returns session by its ID.
+func (ss *UserSessionStorage) UserSessionByID(id string) (model.UserSession, error) {
+	ss.RLock()
+	defer ss.RUnlock()
+
+	session, ok := ss.sessions[id]
+	if !ok {
+		return session, model.ErrUserSessionNotFound
+	}
+	return session, nil
+}
+
+// UserSessionByToken returns session which has issued the token.
+func (ss *UserSessionStorage) UserSessionByToken(token string) (model.UserSession, error) {
+	ss.RLock()
+	defer ss.RUnlock()
+
+	for _, session := range ss.sessions {
+		if session.HasToken(token) {
+			return session, nil
+		}
+	}
+	return model.UserSession{}, model.ErrUserSessionNotFound
+}
+
+// FetchUserSessions returns all sessions of the user, most recently used first.
+func (ss *UserSessionStorage) FetchUserSessions(userID string) ([]model.UserSession, error) {
+	ss.RLock()
+	sessions := []model.UserSession{}
+	for _, session := range ss.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	ss.RUnlock()
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt > sessions[j].LastUsedAt })
+	return sessions, nil
+}
+
+// UpdateUserSessionTokens replaces session tokens and sets last used time.
+func (ss *UserSessionStorage) UpdateUserSessionTokens(id string, tokens []string, lastUsedAt int64) error {
+	ss.Lock()
+	defer ss.Unlock()
+
+	session, ok := ss.sessions[id]
+	if !ok {
+		return model.ErrUserSessionNotFound
+	}
+	session.Tokens = tokens
+	session.LastUsedAt = lastUsedAt
+	ss.sessions[id] = session
+	return nil
+}
+
+// DeleteUserSession deletes session.
+func (ss *UserSessionStorage) DeleteUserSession(id string) error {
+	ss.Lock()
+	defer ss.Unlock()
+
+	delete(ss.sessions, id)
+	return nil
+}
+
+// Close does nothing here.
+func (ss *UserSessionStorage) Close() {}
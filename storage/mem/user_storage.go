@@ -1,6 +1,9 @@
 package mem
 
 import (
+	"sort"
+	"sync"
+
 	"github.com/madappgang/identifo/model"
 	"github.com/pallinder/go-randomdata"
 )
@@ -8,20 +11,25 @@ import (
 // NewUserStorage creates and inits in-memory user storage.
 // Use it only for test purposes and in CI, all data is wiped on exit.
 func NewUserStorage() (model.UserStorage, error) {
-	return &UserStorage{}, nil
+	return &UserStorage{federatedIDs: make(map[string]string)}, nil
 }
 
 // UserStorage is an in-memory user storage .
-type UserStorage struct{}
+// Users are randomly generated, only linked federated identities are kept, so account linking can be tried out.
+type UserStorage struct {
+	mu sync.RWMutex
+	// federatedIDs are user IDs by federated identity keys.
+	federatedIDs map[string]string
+}
 
 // NewUser returns pointer to newly created user.
 func (us *UserStorage) NewUser() model.User {
 	return &user{}
 }
 
-// UserByID returns randomly generated user.
+// UserByID returns randomly generated user with the ID and its linked federated identities.
 func (us *UserStorage) UserByID(id string) (model.User, error) {
-	return randUser(), nil
+	return us.linkedUser(id), nil
 }
 
 // UserByEmail returns randomly generated user.
@@ -29,6 +37,11 @@ func (us *UserStorage) UserByEmail(email string) (model.User, error) {
 	return randUser(), nil
 }
 
+// UserByUsername returns randomly generated user.
+func (us *UserStorage) UserByUsername(username string) (model.User, error) {
+	return randUser(), nil
+}
+
 // UserBySocialID returns randomly generated user.
 func (us *UserStorage) UserBySocialID(id string) (model.User, error) {
 	return randUser(), nil
@@ -80,14 +93,26 @@ func (us *UserStorage) AddUserByPhone(phone, role string) (model.User, error) {
 	return randUser(), nil
 }
 
-// UserByFederatedID returns randomly generated user.
+// UserByFederatedID returns the user the identity is linked to, or randomly generated user.
 func (us *UserStorage) UserByFederatedID(provider model.FederatedIdentityProvider, id string) (model.User, error) {
-	return randUser(), nil
+	us.mu.RLock()
+	userID, ok := us.federatedIDs[model.FederatedIDKey(provider, id)]
+	us.mu.RUnlock()
+	if !ok {
+		return randUser(), nil
+	}
+	return us.linkedUser(userID), nil
 }
 
-// AddUserWithFederatedID returns randomly generated user.
+// AddUserWithFederatedID returns randomly generated user with the identity linked.
 func (us *UserStorage) AddUserWithFederatedID(provider model.FederatedIdentityProvider, id, role string) (model.User, error) {
-	return randUser(), nil
+	u := randUser()
+	u.userData.AccessRole = role
+	if err := us.LinkFederatedID(u.ID(), provider, id); err != nil {
+		return nil, err
+	}
+	u.userData.FederatedIDs = []string{model.FederatedIDKey(provider, id)}
+	return u, nil
 }
 
 // UpdateUser returns what it receives.
@@ -95,6 +120,80 @@ func (us *UserStorage) UpdateUser(userID string, newUser model.User) (model.User
 	return newUser, nil
 }
 
+// LinkFederatedID attaches federated identity to the user.
+func (us *UserStorage) LinkFederatedID(userID string, provider model.FederatedIdentityProvider, id string) error {
+	sid := model.FederatedIDKey(provider, id)
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	if owner, ok := us.federatedIDs[sid]; ok && owner != userID {
+		return model.ErrorUserExists
+	}
+	if us.federatedIDs == nil {
+		us.federatedIDs = make(map[string]string)
+	}
+	us.federatedIDs[sid] = userID
+	return nil
+}
+
+// UnlinkFederatedID detaches federated identity from the user.
+func (us *UserStorage) UnlinkFederatedID(userID string, provider model.FederatedIdentityProvider, id string) error {
+	sid := model.FederatedIDKey(provider, id)
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	if us.federatedIDs[sid] == userID {
+		delete(us.federatedIDs, sid)
+	}
+	return nil
+}
+
+// linkedUser returns randomly generated user with the ID and its linked federated identities.
+func (us *UserStorage) linkedUser(userID string) *user {
+	u := randUser()
+	u.userData.ID = userID
+
+	us.mu.RLock()
+	defer us.mu.RUnlock()
+	for sid, owner := range us.federatedIDs {
+		if owner == userID {
+			u.userData.FederatedIDs = append(u.userData.FederatedIDs, sid)
+		}
+	}
+	sort.Strings(u.userData.FederatedIDs)
+	return u
+}
+
+// LinkPhone does nothing here.
+func (us *UserStorage) LinkPhone(userID, phone string) error {
+	return nil
+}
+
+// UnlinkPhone does nothing here.
+func (us *UserStorage) UnlinkPhone(userID string) error {
+	return nil
+}
+
+// LinkPassword does nothing here.
+func (us *UserStorage) LinkPassword(userID, username, password string) error {
+	return nil
+}
+
+// UnlinkPassword does nothing here.
+func (us *UserStorage) UnlinkPassword(userID string) error {
+	return nil
+}
+
+// DeanonimizeUser does nothing here.
+func (us *UserStorage) DeanonimizeUser(userID string) error {
+	return nil
+}
+
+// SetTokensValidAfter does nothing here.
+func (us *UserStorage) SetTokensValidAfter(userID string, timestamp int64) error {
+	return nil
+}
+
 // ResetPassword does nothing here.
 func (us *UserStorage) ResetPassword(id, password string) error {
 	return nil
@@ -105,8 +204,15 @@ func (us *UserStorage) IDByName(name string) (string, error) {
 	return randomdata.StringNumber(2, "-"), nil
 }
 
-// DeleteUser does nothing here.
+// DeleteUser drops federated identities linked to the user.
 func (us *UserStorage) DeleteUser(id string) error {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	for sid, owner := range us.federatedIDs {
+		if owner == id {
+			delete(us.federatedIDs, sid)
+		}
+	}
 	return nil
 }
 
@@ -114,7 +220,7 @@ func (us *UserStorage) DeleteUser(id string) error {
 func (us *UserStorage) UpdateLoginMetadata(userID string) {}
 
 // FetchUsers returns randomly generated user enclosed in slice.
-func (us *UserStorage) FetchUsers(filterString string, skip, limit int) ([]model.User, int, error) {
+func (us *UserStorage) FetchUsers(filterString string, attributes map[string]interface{}, skip, limit int) ([]model.User, int, error) {
 	return []model.User{randUser()}, 1, nil
 }
 
@@ -0,0 +1,63 @@
+package mem
+
+import (
+	"testing"
+
+	"github.com/madappgang/identifo/model"
+)
+
+func TestFederatedIDLinking(t *testing.T) {
+	us, _ := NewUserStorage()
+	john, _ := us.UserByID("john")
+	jane, _ := us.UserByID("jane")
+
+	if err := us.LinkFederatedID(john.ID(), "GOOGLE", "g1"); err != nil {
+		t.Fatalf("Error linking identity: %s", err)
+	}
+	if err := us.LinkFederatedID(john.ID(), "GOOGLE", "g1"); err != nil {
+		t.Errorf("Linking the same identity again error = %v, expected nil", err)
+	}
+	if err := us.LinkFederatedID(jane.ID(), "GOOGLE", "g1"); err != model.ErrorUserExists {
+		t.Errorf("Linking identity of another user error = %v, expected %v", err, model.ErrorUserExists)
+	}
+	us.LinkFederatedID(john.ID(), "GITHUB", "h1")
+
+	if user, err := us.UserByFederatedID("GOOGLE", "g1"); err != nil || user.ID() != john.ID() {
+		t.Errorf("UserByFederatedID() = %v, %v, expected the linked user", user, err)
+	}
+	user, _ := us.UserByID(john.ID())
+	if ids := user.FederatedIDs(); len(ids) != 2 || !containsString(ids, "GOOGLE:g1") || !containsString(ids, "GITHUB:h1") {
+		t.Errorf("FederatedIDs() = %v, expected both linked identities", ids)
+	}
+
+	if err := us.UnlinkFederatedID(jane.ID(), "GOOGLE", "g1"); err != nil {
+		t.Fatalf("Error unlinking identity of another user: %s", err)
+	}
+	if user, err := us.UserByFederatedID("GOOGLE", "g1"); err != nil || user.ID() != john.ID() {
+		t.Errorf("Identity is unlinked by another user: %v, %v", user, err)
+	}
+
+	if err := us.UnlinkFederatedID(john.ID(), "GOOGLE", "g1"); err != nil {
+		t.Fatalf("Error unlinking identity: %s", err)
+	}
+	user, _ = us.UserByID(john.ID())
+	if ids := user.FederatedIDs(); len(ids) != 1 || ids[0] != "GITHUB:h1" {
+		t.Errorf("FederatedIDs() after unlink = %v, expected only GITHUB:h1", ids)
+	}
+	if user, _ := us.UserByFederatedID("GOOGLE", "g1"); user.ID() == john.ID() {
+		t.Error("UserByFederatedID() returns the user the identity is unlinked from")
+	}
+
+	if err := us.LinkFederatedID(jane.ID(), "GOOGLE", "g1"); err != nil {
+		t.Errorf("Linking unlinked identity to another user error = %v, expected nil", err)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
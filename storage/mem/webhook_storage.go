@@ -0,0 +1,163 @@
+package mem
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+)
+
+// NewWebhookStorage creates and inits in-memory webhook storage.
+func NewWebhookStorage() (model.WebhookStorage, error) {
+	return &WebhookStorage{
+		webhooks:   make(map[string]model.Webhook),
+		deliveries: make(map[string]model.WebhookDelivery),
+	}, nil
+}
+
+// WebhookStorage is an in-memory webhook storage.
+type WebhookStorage struct {
+	sync.RWMutex
+	webhooks   map[string]model.Webhook
+	deliveries map[string]model.WebhookDelivery
+}
+
+// AddWebhook saves new webhook.
+func (ws *WebhookStorage) AddWebhook(webhook model.Webhook) (model.Webhook, error) {
+	ws.Lock()
+	defer ws.Unlock()
+
+	webhook.ID = xid.New().String()
+	ws.webhooks[webhook.ID] = webhook
+	return webhook, nil
+}
+
+// WebhookByID returns webhook by ID.
+func (ws *WebhookStorage) WebhookByID(id string) (model.Webhook, error) {
+	ws.RLock()
+	defer ws.RUnlock()
+
+	webhook, ok := ws.webhooks[id]
+	if !ok {
+		return webhook, model.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// FetchWebhooks returns all webhooks, oldest first.
+func (ws *WebhookStorage) FetchWebhooks() ([]model.Webhook, error) {
+	ws.RLock()
+	defer ws.RUnlock()
+
+	webhooks := make([]model.Webhook, 0, len(ws.webhooks))
+	for _, webhook := range ws.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+// UpdateWebhook replaces the webhook.
+func (ws *WebhookStorage) UpdateWebhook(webhook model.Webhook) (model.Webhook, error) {
+	ws.Lock()
+	defer ws.Unlock()
+
+	if _, ok := ws.webhooks[webhook.ID]; !ok {
+		return model.Webhook{}, model.ErrWebhookNotFound
+	}
+	ws.webhooks[webhook.ID] = webhook
+	return webhook, nil
+}
+
+// DeleteWebhook deletes the webhook.
+func (ws *WebhookStorage) DeleteWebhook(id string) error {
+	ws.Lock()
+	defer ws.Unlock()
+
+	if _, ok := ws.webhooks[id]; !ok {
+		return model.ErrWebhookNotFound
+	}
+	delete(ws.webhooks, id)
+	return nil
+}
+
+// AddWebhookDelivery saves new delivery.
+func (ws *WebhookStorage) AddWebhookDelivery(delivery model.WebhookDelivery) (model.WebhookDelivery, error) {
+	ws.Lock()
+	defer ws.Unlock()
+
+	delivery.ID = xid.New().String()
+	ws.deliveries[delivery.ID] = delivery
+	return delivery, nil
+}
+
+// WebhookDeliveryByID returns delivery by ID.
+func (ws *WebhookStorage) WebhookDeliveryByID(id string) (model.WebhookDelivery, error) {
+	ws.RLock()
+	defer ws.RUnlock()
+
+	delivery, ok := ws.deliveries[id]
+	if !ok {
+		return delivery, model.ErrWebhookDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// UpdateWebhookDelivery replaces the delivery.
+func (ws *WebhookStorage) UpdateWebhookDelivery(delivery model.WebhookDelivery) error {
+	ws.Lock()
+	defer ws.Unlock()
+
+	if _, ok := ws.deliveries[delivery.ID]; !ok {
+		return model.ErrWebhookDeliveryNotFound
+	}
+	ws.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+// FetchWebhookDeliveries returns deliveries matching the filter, newest first.
+func (ws *WebhookStorage) FetchWebhookDeliveries(filter model.WebhookDeliveryFilter, skip, limit int) ([]model.WebhookDelivery, int, error) {
+	ws.RLock()
+	deliveries := []model.WebhookDelivery{}
+	for _, delivery := range ws.deliveries {
+		if filter.Matches(delivery) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	ws.RUnlock()
+
+	// IDs are xids, which are sortable by creation time.
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+
+	total := len(deliveries)
+	if skip > total {
+		skip = total
+	}
+	deliveries = deliveries[skip:]
+	if limit != 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, total, nil
+}
+
+// PendingWebhookDeliveries returns pending deliveries due at the time, oldest first.
+func (ws *WebhookStorage) PendingWebhookDeliveries(now int64, limit int) ([]model.WebhookDelivery, error) {
+	ws.RLock()
+	deliveries := []model.WebhookDelivery{}
+	for _, delivery := range ws.deliveries {
+		if delivery.Status == model.WebhookDeliveryPending && delivery.NextAttemptAt <= now {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	ws.RUnlock()
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	if limit != 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// Close does nothing here.
+func (ws *WebhookStorage) Close() {}
@@ -0,0 +1,129 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const adminsCollectionName = "Admins"
+
+// NewAdminStorage creates and inits MongoDB admin storage.
+func NewAdminStorage(db *DB) (model.AdminStorage, error) {
+	coll := db.Database.Collection(adminsCollectionName)
+	as := &AdminStorage{coll: coll, timeout: 30 * time.Second}
+
+	emailIndexOptions := &options.IndexOptions{}
+	emailIndexOptions.SetUnique(true)
+
+	emailIndex := &mongo.IndexModel{
+		Keys:    bsonx.Doc{{Key: "email", Value: bsonx.Int32(int32(1))}},
+		Options: emailIndexOptions,
+	}
+
+	err := db.EnsureCollectionIndices(adminsCollectionName, []mongo.IndexModel{*emailIndex})
+	return as, err
+}
+
+// AdminStorage implements admin storage interface.
+type AdminStorage struct {
+	coll    *mongo.Collection
+	timeout time.Duration
+}
+
+// AddAdmin saves new admin.
+func (as *AdminStorage) AddAdmin(admin model.Admin) (model.Admin, error) {
+	admin.ID = xid.New().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), as.timeout)
+	defer cancel()
+
+	if _, err := as.coll.InsertOne(ctx, admin); err != nil {
+		if isErrDuplication(err) {
+			return model.Admin{}, model.ErrAdminExists
+		}
+		return model.Admin{}, err
+	}
+	return admin, nil
+}
+
+// AdminByID returns admin by ID.
+func (as *AdminStorage) AdminByID(id string) (model.Admin, error) {
+	return as.findOne(bson.M{"_id": id})
+}
+
+// AdminByEmail returns admin by email.
+func (as *AdminStorage) AdminByEmail(email string) (model.Admin, error) {
+	return as.findOne(bson.M{"email": email})
+}
+
+// FetchAdmins returns all admins, oldest first.
+func (as *AdminStorage) FetchAdmins() ([]model.Admin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), as.timeout)
+	defer cancel()
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{primitive.E{Key: "created_at", Value: 1}})
+
+	curr, err := as.coll.Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return []model.Admin{}, err
+	}
+
+	admins := []model.Admin{}
+	if err = curr.All(ctx, &admins); err != nil {
+		return []model.Admin{}, err
+	}
+	return admins, nil
+}
+
+// UpdateAdmin replaces stored admin.
+func (as *AdminStorage) UpdateAdmin(admin model.Admin) (model.Admin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), as.timeout)
+	defer cancel()
+
+	res, err := as.coll.ReplaceOne(ctx, bson.M{"_id": admin.ID}, admin)
+	if err != nil {
+		if isErrDuplication(err) {
+			return model.Admin{}, model.ErrAdminExists
+		}
+		return model.Admin{}, err
+	}
+	if res.MatchedCount == 0 {
+		return model.Admin{}, model.ErrAdminNotFound
+	}
+	return admin, nil
+}
+
+// DeleteAdmin deletes admin.
+func (as *AdminStorage) DeleteAdmin(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), as.timeout)
+	defer cancel()
+
+	_, err := as.coll.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// Close is a no-op here.
+func (as *AdminStorage) Close() {}
+
+func (as *AdminStorage) findOne(q bson.M) (model.Admin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), as.timeout)
+	defer cancel()
+
+	var admin model.Admin
+	if err := as.coll.FindOne(ctx, q).Decode(&admin); err != nil {
+		if isErrNotFound(err) {
+			return admin, model.ErrAdminNotFound
+		}
+		return admin, err
+	}
+	return admin, nil
+}
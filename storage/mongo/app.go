@@ -37,6 +37,9 @@ type appData struct {
 	RolesBlacklist               []string               `bson:"roles_blacklist,omitempty" json:"roles_blacklist,omitempty"`
 	NewUserDefaultRole           string                 `bson:"new_user_default_role,omitempty" json:"new_user_default_role,omitempty"`
 	AppleInfo                    *model.AppleInfo       `bson:"apple_info,omitempty" json:"apple_info,omitempty"`
+	GoogleInfo                   *model.GoogleInfo      `bson:"google_info,omitempty" json:"google_info,omitempty"`
+	GitHubInfo                   *model.GitHubInfo      `bson:"github_info,omitempty" json:"github_info,omitempty"`
+	OIDCInfo                     *model.OIDCInfo        `bson:"oidc_info,omitempty" json:"oidc_info,omitempty"`
 }
 
 // NewAppData instantiates MongoDB app data model from the general one.
@@ -67,6 +70,10 @@ func NewAppData(data model.AppData) (AppData, error) {
 		RolesWhitelist:               data.RolesWhitelist(),
 		RolesBlacklist:               data.RolesBlacklist(),
 		NewUserDefaultRole:           data.NewUserDefaultRole(),
+		AppleInfo:                    data.AppleInfo(),
+		GoogleInfo:                   data.GoogleInfo(),
+		GitHubInfo:                   data.GitHubInfo(),
+		OIDCInfo:                     data.OIDCInfo(),
 	}}, nil
 }
 
@@ -191,6 +198,15 @@ func (ad *AppData) NewUserDefaultRole() string { return ad.appData.NewUserDefaul
 // AppleInfo implements model.AppData interface.
 func (ad *AppData) AppleInfo() *model.AppleInfo { return ad.appData.AppleInfo }
 
+// GoogleInfo implements model.AppData interface.
+func (ad *AppData) GoogleInfo() *model.GoogleInfo { return ad.appData.GoogleInfo }
+
+// GitHubInfo implements model.AppData interface.
+func (ad *AppData) GitHubInfo() *model.GitHubInfo { return ad.appData.GitHubInfo }
+
+// OIDCInfo implements model.AppData interface.
+func (ad *AppData) OIDCInfo() *model.OIDCInfo { return ad.appData.OIDCInfo }
+
 // SetSecret implements model.AppData interface.
 func (ad *AppData) SetSecret(secret string) {
 	if ad == nil {
@@ -208,6 +224,15 @@ func (ad *AppData) Sanitize() {
 	if ad.appData.AppleInfo != nil {
 		ad.appData.AppleInfo.ClientSecret = ""
 	}
+	if ad.appData.GoogleInfo != nil {
+		ad.appData.GoogleInfo.ClientSecret = ""
+	}
+	if ad.appData.GitHubInfo != nil {
+		ad.appData.GitHubInfo.ClientSecret = ""
+	}
+	if ad.appData.OIDCInfo != nil {
+		ad.appData.OIDCInfo.ClientSecret = ""
+	}
 
 	ad.appData.AuthorizationWay = ""
 	ad.appData.AuthorizationModel = ""
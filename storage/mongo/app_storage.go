@@ -3,9 +3,9 @@ package mongo
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"time"
 
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -216,7 +216,7 @@ func (as *AppStorage) TestDatabaseConnection() error {
 func (as *AppStorage) ImportJSON(data []byte) error {
 	apd := []appData{}
 	if err := json.Unmarshal(data, &apd); err != nil {
-		log.Println(err)
+		logging.Error(err)
 		return err
 	}
 	for _, a := range apd {
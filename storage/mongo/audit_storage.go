@@ -0,0 +1,111 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const auditEventsCollectionName = "AuditEvents"
+
+// NewAuditStorage creates and inits MongoDB audit storage.
+func NewAuditStorage(db *DB) (model.AuditStorage, error) {
+	coll := db.Database.Collection(auditEventsCollectionName)
+	as := &AuditStorage{coll: coll, timeout: 30 * time.Second}
+
+	createdAtIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{{Key: "created_at", Value: bsonx.Int32(int32(-1))}},
+	}
+	adminIDIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{{Key: "admin_id", Value: bsonx.Int32(int32(1))}},
+	}
+	targetIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "target_type", Value: bsonx.Int32(int32(1))},
+			{Key: "target_id", Value: bsonx.Int32(int32(1))},
+		},
+	}
+
+	err := db.EnsureCollectionIndices(auditEventsCollectionName, []mongo.IndexModel{*createdAtIndex, *adminIDIndex, *targetIndex})
+	return as, err
+}
+
+// AuditStorage implements audit storage interface.
+type AuditStorage struct {
+	coll    *mongo.Collection
+	timeout time.Duration
+}
+
+// AddAuditEvent saves new event.
+func (as *AuditStorage) AddAuditEvent(event model.AuditEvent) (model.AuditEvent, error) {
+	event.ID = xid.New().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), as.timeout)
+	defer cancel()
+
+	if _, err := as.coll.InsertOne(ctx, event); err != nil {
+		return model.AuditEvent{}, err
+	}
+	return event, nil
+}
+
+// FetchAuditEvents returns events matching the filter, newest first.
+func (as *AuditStorage) FetchAuditEvents(filter model.AuditEventFilter, skip, limit int) ([]model.AuditEvent, int, error) {
+	q := bson.M{}
+	if filter.AdminID != "" {
+		q["admin_id"] = filter.AdminID
+	}
+	if filter.Action != "" {
+		q["action"] = filter.Action
+	}
+	if filter.TargetType != "" {
+		q["target_type"] = filter.TargetType
+	}
+	if filter.TargetID != "" {
+		q["target_id"] = filter.TargetID
+	}
+	if filter.From != 0 || filter.To != 0 {
+		createdAt := bson.M{}
+		if filter.From != 0 {
+			createdAt["$gte"] = filter.From
+		}
+		if filter.To != 0 {
+			createdAt["$lte"] = filter.To
+		}
+		q["created_at"] = createdAt
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*as.timeout)
+	defer cancel()
+
+	total, err := as.coll.CountDocuments(ctx, q)
+	if err != nil {
+		return []model.AuditEvent{}, 0, err
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{primitive.E{Key: "created_at", Value: -1}, primitive.E{Key: "_id", Value: -1}})
+	findOptions.SetLimit(int64(limit))
+	findOptions.SetSkip(int64(skip))
+
+	curr, err := as.coll.Find(ctx, q, findOptions)
+	if err != nil {
+		return []model.AuditEvent{}, 0, err
+	}
+
+	events := []model.AuditEvent{}
+	if err = curr.All(ctx, &events); err != nil {
+		return []model.AuditEvent{}, 0, err
+	}
+	return events, int(total), nil
+}
+
+// Close is a no-op here.
+func (as *AuditStorage) Close() {}
@@ -0,0 +1,108 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const authEventsCollectionName = "AuthEvents"
+
+// NewAuthEventStorage creates and inits MongoDB auth event storage.
+func NewAuthEventStorage(db *DB) (model.AuthEventStorage, error) {
+	coll := db.Database.Collection(authEventsCollectionName)
+	aes := &AuthEventStorage{coll: coll, timeout: 30 * time.Second}
+
+	createdAtIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{{Key: "created_at", Value: bsonx.Int32(int32(-1))}},
+	}
+	userIDIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "user_id", Value: bsonx.Int32(int32(1))},
+			{Key: "created_at", Value: bsonx.Int32(int32(-1))},
+		},
+	}
+
+	err := db.EnsureCollectionIndices(authEventsCollectionName, []mongo.IndexModel{*createdAtIndex, *userIDIndex})
+	return aes, err
+}
+
+// AuthEventStorage implements auth event storage interface.
+type AuthEventStorage struct {
+	coll    *mongo.Collection
+	timeout time.Duration
+}
+
+// AddAuthEvent saves new event.
+func (aes *AuthEventStorage) AddAuthEvent(event model.AuthEvent) (model.AuthEvent, error) {
+	event.ID = xid.New().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), aes.timeout)
+	defer cancel()
+
+	if _, err := aes.coll.InsertOne(ctx, event); err != nil {
+		return model.AuthEvent{}, err
+	}
+	return event, nil
+}
+
+// FetchAuthEvents returns events matching the filter, newest first.
+func (aes *AuthEventStorage) FetchAuthEvents(filter model.AuthEventFilter, skip, limit int) ([]model.AuthEvent, int, error) {
+	q := bson.M{}
+	if filter.UserID != "" {
+		q["user_id"] = filter.UserID
+	}
+	if filter.AppID != "" {
+		q["app_id"] = filter.AppID
+	}
+	if filter.Type != "" {
+		q["type"] = filter.Type
+	}
+	if filter.Success != nil {
+		q["success"] = *filter.Success
+	}
+	if filter.From != 0 || filter.To != 0 {
+		createdAt := bson.M{}
+		if filter.From != 0 {
+			createdAt["$gte"] = filter.From
+		}
+		if filter.To != 0 {
+			createdAt["$lte"] = filter.To
+		}
+		q["created_at"] = createdAt
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*aes.timeout)
+	defer cancel()
+
+	total, err := aes.coll.CountDocuments(ctx, q)
+	if err != nil {
+		return []model.AuthEvent{}, 0, err
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{primitive.E{Key: "created_at", Value: -1}, primitive.E{Key: "_id", Value: -1}})
+	findOptions.SetLimit(int64(limit))
+	findOptions.SetSkip(int64(skip))
+
+	curr, err := aes.coll.Find(ctx, q, findOptions)
+	if err != nil {
+		return []model.AuthEvent{}, 0, err
+	}
+
+	events := []model.AuthEvent{}
+	if err = curr.All(ctx, &events); err != nil {
+		return []model.AuthEvent{}, 0, err
+	}
+	return events, int(total), nil
+}
+
+// Close is a no-op here.
+func (aes *AuthEventStorage) Close() {}
@@ -0,0 +1,85 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// pingCollection checks that the server of the collection responds.
+func pingCollection(coll *mongo.Collection, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return coll.Database().Client().Ping(ctx, nil)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (us *UserStorage) TestDatabaseConnection() error {
+	return pingCollection(us.coll, us.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ts *TokenStorage) TestDatabaseConnection() error {
+	return pingCollection(ts.coll, ts.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (tb *TokenBlacklist) TestDatabaseConnection() error {
+	return pingCollection(tb.coll, tb.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (vcs *VerificationCodeStorage) TestDatabaseConnection() error {
+	return pingCollection(vcs.coll, vcs.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (is *InviteStorage) TestDatabaseConnection() error {
+	return pingCollection(is.coll, is.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ss *UserSessionStorage) TestDatabaseConnection() error {
+	return pingCollection(ss.coll, ss.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (as *AdminStorage) TestDatabaseConnection() error {
+	return pingCollection(as.coll, as.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (as *AuditStorage) TestDatabaseConnection() error {
+	return pingCollection(as.coll, as.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (aes *AuthEventStorage) TestDatabaseConnection() error {
+	return pingCollection(aes.coll, aes.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ws *WebhookStorage) TestDatabaseConnection() error {
+	return pingCollection(ws.webhooks, ws.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (os *OrganizationStorage) TestDatabaseConnection() error {
+	return pingCollection(os.organizations, os.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ss *SCIMTokenStorage) TestDatabaseConnection() error {
+	return pingCollection(ss.coll, ss.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (ps *PolicyStorage) TestDatabaseConnection() error {
+	return pingCollection(ps.coll, ps.timeout)
+}
+
+// TestDatabaseConnection implements model.ConnectionTester interface.
+func (rs *RoleStorage) TestDatabaseConnection() error {
+	return pingCollection(rs.coll, rs.timeout)
+}
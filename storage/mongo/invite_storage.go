@@ -0,0 +1,147 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const invitesCollectionName = "Invites"
+
+// NewInviteStorage creates and inits MongoDB invite storage.
+func NewInviteStorage(db *DB) (model.InviteStorage, error) {
+	coll := db.Database.Collection(invitesCollectionName)
+	is := &InviteStorage{coll: coll, timeout: 30 * time.Second}
+
+	appEmailIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "app_id", Value: bsonx.Int32(int32(1))},
+			{Key: "email", Value: bsonx.Int32(int32(1))},
+		},
+	}
+
+	err := db.EnsureCollectionIndices(invitesCollectionName, []mongo.IndexModel{*appEmailIndex})
+	return is, err
+}
+
+// InviteStorage implements invite storage interface.
+type InviteStorage struct {
+	coll    *mongo.Collection
+	timeout time.Duration
+}
+
+// AddInvite saves new invite.
+func (is *InviteStorage) AddInvite(invite model.Invite) (model.Invite, error) {
+	invite.ID = xid.New().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), is.timeout)
+	defer cancel()
+
+	if _, err := is.coll.InsertOne(ctx, invite); err != nil {
+		return model.Invite{}, err
+	}
+	return invite, nil
+}
+
+// InviteByID returns invite by its ID.
+func (is *InviteStorage) InviteByID(id string) (model.Invite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), is.timeout)
+	defer cancel()
+
+	var invite model.Invite
+	if err := is.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&invite); err != nil {
+		if isErrNotFound(err) {
+			return invite, model.ErrInviteNotFound
+		}
+		return invite, err
+	}
+	return invite, nil
+}
+
+// FetchInvites fetches invites, optionally filtered by app ID and email, newest first.
+func (is *InviteStorage) FetchInvites(appID, email string, skip, limit int) ([]model.Invite, int, error) {
+	q := bson.M{}
+	if appID != "" {
+		q["app_id"] = appID
+	}
+	if email != "" {
+		q["email"] = email
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*is.timeout)
+	defer cancel()
+
+	total, err := is.coll.CountDocuments(ctx, q)
+	if err != nil {
+		return []model.Invite{}, 0, err
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{primitive.E{Key: "created_at", Value: -1}})
+	findOptions.SetLimit(int64(limit))
+	findOptions.SetSkip(int64(skip))
+
+	curr, err := is.coll.Find(ctx, q, findOptions)
+	if err != nil {
+		return []model.Invite{}, 0, err
+	}
+
+	invites := []model.Invite{}
+	if err = curr.All(ctx, &invites); err != nil {
+		return []model.Invite{}, 0, err
+	}
+	return invites, int(total), nil
+}
+
+// RevokeInvite marks invite as revoked.
+func (is *InviteStorage) RevokeInvite(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), is.timeout)
+	defer cancel()
+
+	res, err := is.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"revoked": true}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return model.ErrInviteNotFound
+	}
+	return nil
+}
+
+// ConsumeInvite marks valid invite as used by the user.
+// The validity check and the update are done in one query, so the invite cannot be used twice.
+func (is *InviteStorage) ConsumeInvite(id, userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), is.timeout)
+	defer cancel()
+
+	now := time.Now().Unix()
+	q := bson.M{
+		"_id":        id,
+		"revoked":    bson.M{"$ne": true},
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"used_by": userID, "used_at": now}}
+
+	res, err := is.coll.UpdateOne(ctx, q, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		if _, err := is.InviteByID(id); err != nil {
+			return err
+		}
+		return model.ErrInviteNotValid
+	}
+	return nil
+}
+
+// Close is a no-op here.
+func (is *InviteStorage) Close() {}
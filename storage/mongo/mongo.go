@@ -3,19 +3,27 @@ package mongo
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/madappgang/identifo/metrics"
+	"github.com/madappgang/identifo/tracing"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/x/bsonx"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // NewDB creates new database connection.
 func NewDB(conn string, dbName string) (*DB, error) {
-	client, err := mongo.NewClient(options.Client().ApplyURI(conn))
+	client, err := mongo.NewClient(options.Client().ApplyURI(conn).SetMonitor(commandMonitor))
 	if err != nil {
 		return nil, err
 	}
@@ -36,6 +44,34 @@ func NewDB(conn string, dbName string) (*DB, error) {
 	return db, nil
 }
 
+// commandSpans are spans of the running commands by request ID.
+var commandSpans sync.Map
+
+// commandMonitor reports latency of the database commands to metrics, and records their spans.
+var commandMonitor = &event.CommandMonitor{
+	Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+		if tracing.Enabled() {
+			commandSpans.Store(e.RequestID, tracing.StartStorageSpan(ctx, "mongodb", e.CommandName))
+		}
+	},
+	Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+		metrics.ObserveStorage("mongodb", e.CommandName, time.Duration(e.DurationNanos), nil)
+		endCommandSpan(e.RequestID, nil)
+	},
+	Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+		err := errors.New(e.Failure)
+		metrics.ObserveStorage("mongodb", e.CommandName, time.Duration(e.DurationNanos), err)
+		endCommandSpan(e.RequestID, err)
+	},
+}
+
+func endCommandSpan(requestID int64, err error) {
+	if span, ok := commandSpans.Load(requestID); ok {
+		commandSpans.Delete(requestID)
+		tracing.End(span.(trace.Span), err)
+	}
+}
+
 // DB is database connection structure.
 type DB struct {
 	Database *mongo.Database
@@ -118,6 +154,11 @@ func generateIndexName(index mongo.IndexModel) (string, error) {
 	return name.String(), nil
 }
 
+// metadataRegistry decodes embedded documents of untyped fields as plain maps, so they are marshaled to JSON as objects.
+var metadataRegistry = bson.NewRegistryBuilder().
+	RegisterTypeMapEntry(bsontype.EmbeddedDocument, reflect.TypeOf(map[string]interface{}{})).
+	Build()
+
 func isErrNotFound(err error) bool {
 	return strings.Contains(err.Error(), "no documents in result")
 }
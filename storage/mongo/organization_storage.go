@@ -0,0 +1,222 @@
+package mongo
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const (
+	organizationsCollectionName       = "Organizations"
+	organizationMembersCollectionName = "OrganizationMembers"
+)
+
+// NewOrganizationStorage creates and inits MongoDB organization storage.
+func NewOrganizationStorage(db *DB) (model.OrganizationStorage, error) {
+	os := &OrganizationStorage{
+		organizations: db.Database.Collection(organizationsCollectionName),
+		members:       db.Database.Collection(organizationMembersCollectionName),
+		timeout:       30 * time.Second,
+	}
+
+	memberIndexOptions := &options.IndexOptions{}
+	memberIndexOptions.SetUnique(true)
+
+	memberIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "org_id", Value: bsonx.Int32(int32(1))},
+			{Key: "user_id", Value: bsonx.Int32(int32(1))},
+		},
+		Options: memberIndexOptions,
+	}
+	userIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{{Key: "user_id", Value: bsonx.Int32(int32(1))}},
+	}
+
+	err := db.EnsureCollectionIndices(organizationMembersCollectionName, []mongo.IndexModel{*memberIndex, *userIndex})
+	return os, err
+}
+
+// OrganizationStorage implements organization storage interface.
+type OrganizationStorage struct {
+	organizations *mongo.Collection
+	members       *mongo.Collection
+	timeout       time.Duration
+}
+
+// AddOrganization saves new organization.
+func (os *OrganizationStorage) AddOrganization(org model.Organization) (model.Organization, error) {
+	org.ID = xid.New().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), os.timeout)
+	defer cancel()
+
+	if _, err := os.organizations.InsertOne(ctx, org); err != nil {
+		return model.Organization{}, err
+	}
+	return org, nil
+}
+
+// OrganizationByID returns organization by ID.
+func (os *OrganizationStorage) OrganizationByID(id string) (model.Organization, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), os.timeout)
+	defer cancel()
+
+	var org model.Organization
+	if err := os.organizations.FindOne(ctx, bson.M{"_id": id}).Decode(&org); err != nil {
+		if isErrNotFound(err) {
+			return org, model.ErrOrganizationNotFound
+		}
+		return org, err
+	}
+	return org, nil
+}
+
+// FetchOrganizations returns organizations which name contains search string, oldest first.
+func (os *OrganizationStorage) FetchOrganizations(search string, skip, limit int) ([]model.Organization, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*os.timeout)
+	defer cancel()
+
+	q := bson.M{"name": primitive.Regex{Pattern: regexp.QuoteMeta(search), Options: "i"}}
+
+	total, err := os.organizations.CountDocuments(ctx, q)
+	if err != nil {
+		return []model.Organization{}, 0, err
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{primitive.E{Key: "_id", Value: 1}})
+	findOptions.SetLimit(int64(limit))
+	findOptions.SetSkip(int64(skip))
+
+	curr, err := os.organizations.Find(ctx, q, findOptions)
+	if err != nil {
+		return []model.Organization{}, 0, err
+	}
+
+	orgs := []model.Organization{}
+	if err = curr.All(ctx, &orgs); err != nil {
+		return []model.Organization{}, 0, err
+	}
+	return orgs, int(total), nil
+}
+
+// UpdateOrganization replaces stored organization.
+func (os *OrganizationStorage) UpdateOrganization(org model.Organization) (model.Organization, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), os.timeout)
+	defer cancel()
+
+	res, err := os.organizations.ReplaceOne(ctx, bson.M{"_id": org.ID}, org)
+	if err != nil {
+		return model.Organization{}, err
+	}
+	if res.MatchedCount == 0 {
+		return model.Organization{}, model.ErrOrganizationNotFound
+	}
+	return org, nil
+}
+
+// DeleteOrganization deletes the organization with its members.
+func (os *OrganizationStorage) DeleteOrganization(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), os.timeout)
+	defer cancel()
+
+	res, err := os.organizations.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return model.ErrOrganizationNotFound
+	}
+
+	_, err = os.members.DeleteMany(ctx, bson.M{"org_id": id})
+	return err
+}
+
+// SetMember adds the member or changes its role.
+func (os *OrganizationStorage) SetMember(member model.OrganizationMember) (model.OrganizationMember, error) {
+	if _, err := os.OrganizationByID(member.OrgID); err != nil {
+		return model.OrganizationMember{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), os.timeout)
+	defer cancel()
+
+	q := bson.M{"org_id": member.OrgID, "user_id": member.UserID}
+	update := bson.M{
+		"$set":         bson.M{"role": member.Role},
+		"$setOnInsert": bson.M{"joined_at": member.JoinedAt},
+	}
+	if _, err := os.members.UpdateOne(ctx, q, update, options.Update().SetUpsert(true)); err != nil {
+		return model.OrganizationMember{}, err
+	}
+	return os.Member(member.OrgID, member.UserID)
+}
+
+// Member returns the membership of the user in the organization.
+func (os *OrganizationStorage) Member(orgID, userID string) (model.OrganizationMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), os.timeout)
+	defer cancel()
+
+	var member model.OrganizationMember
+	if err := os.members.FindOne(ctx, bson.M{"org_id": orgID, "user_id": userID}).Decode(&member); err != nil {
+		if isErrNotFound(err) {
+			return member, model.ErrOrganizationMemberNotFound
+		}
+		return member, err
+	}
+	return member, nil
+}
+
+// FetchMembers returns organization members, earliest joined first.
+func (os *OrganizationStorage) FetchMembers(orgID string) ([]model.OrganizationMember, error) {
+	return os.findMembers(bson.M{"org_id": orgID})
+}
+
+// UserMemberships returns all memberships of the user.
+func (os *OrganizationStorage) UserMemberships(userID string) ([]model.OrganizationMember, error) {
+	return os.findMembers(bson.M{"user_id": userID})
+}
+
+func (os *OrganizationStorage) findMembers(q bson.M) ([]model.OrganizationMember, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), os.timeout)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{primitive.E{Key: "joined_at", Value: 1}})
+	curr, err := os.members.Find(ctx, q, findOptions)
+	if err != nil {
+		return []model.OrganizationMember{}, err
+	}
+
+	members := []model.OrganizationMember{}
+	if err = curr.All(ctx, &members); err != nil {
+		return []model.OrganizationMember{}, err
+	}
+	return members, nil
+}
+
+// RemoveMember removes the user from the organization.
+func (os *OrganizationStorage) RemoveMember(orgID, userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), os.timeout)
+	defer cancel()
+
+	res, err := os.members.DeleteOne(ctx, bson.M{"org_id": orgID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return model.ErrOrganizationMemberNotFound
+	}
+	return nil
+}
+
+// Close is a no-op here.
+func (os *OrganizationStorage) Close() {}
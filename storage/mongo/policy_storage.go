@@ -0,0 +1,113 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const policyRulesCollectionName = "PolicyRules"
+
+// NewPolicyStorage creates and inits MongoDB policy storage.
+func NewPolicyStorage(db *DB) (model.PolicyStorage, error) {
+	ps := &PolicyStorage{coll: db.Database.Collection(policyRulesCollectionName), timeout: 30 * time.Second}
+
+	appIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{{Key: "app_id", Value: bsonx.Int32(int32(1))}},
+	}
+
+	err := db.EnsureCollectionIndices(policyRulesCollectionName, []mongo.IndexModel{*appIndex})
+	return ps, err
+}
+
+// PolicyStorage implements policy storage interface.
+type PolicyStorage struct {
+	coll    *mongo.Collection
+	timeout time.Duration
+}
+
+// AddPolicyRule saves new rule.
+func (ps *PolicyStorage) AddPolicyRule(rule model.PolicyRule) (model.PolicyRule, error) {
+	rule.ID = xid.New().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ps.timeout)
+	defer cancel()
+
+	if _, err := ps.coll.InsertOne(ctx, rule); err != nil {
+		return model.PolicyRule{}, err
+	}
+	return rule, nil
+}
+
+// PolicyRuleByID returns rule by its ID.
+func (ps *PolicyStorage) PolicyRuleByID(id string) (model.PolicyRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ps.timeout)
+	defer cancel()
+
+	var rule model.PolicyRule
+	if err := ps.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&rule); err != nil {
+		if isErrNotFound(err) {
+			return rule, model.ErrPolicyRuleNotFound
+		}
+		return rule, err
+	}
+	return rule, nil
+}
+
+// FetchPolicyRules returns all rules of the app, oldest first.
+func (ps *PolicyStorage) FetchPolicyRules(appID string) ([]model.PolicyRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ps.timeout)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{primitive.E{Key: "_id", Value: 1}})
+	curr, err := ps.coll.Find(ctx, bson.M{"app_id": appID}, findOptions)
+	if err != nil {
+		return []model.PolicyRule{}, err
+	}
+
+	rules := []model.PolicyRule{}
+	if err = curr.All(ctx, &rules); err != nil {
+		return []model.PolicyRule{}, err
+	}
+	return rules, nil
+}
+
+// UpdatePolicyRule updates the rule.
+func (ps *PolicyStorage) UpdatePolicyRule(rule model.PolicyRule) (model.PolicyRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ps.timeout)
+	defer cancel()
+
+	res, err := ps.coll.ReplaceOne(ctx, bson.M{"_id": rule.ID}, rule)
+	if err != nil {
+		return model.PolicyRule{}, err
+	}
+	if res.MatchedCount == 0 {
+		return model.PolicyRule{}, model.ErrPolicyRuleNotFound
+	}
+	return rule, nil
+}
+
+// DeletePolicyRule deletes the rule.
+func (ps *PolicyStorage) DeletePolicyRule(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ps.timeout)
+	defer cancel()
+
+	res, err := ps.coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return model.ErrPolicyRuleNotFound
+	}
+	return nil
+}
+
+// Close is a no-op here.
+func (ps *PolicyStorage) Close() {}
@@ -0,0 +1,181 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const (
+	rolesCollectionName     = "Roles"
+	userRolesCollectionName = "UserRoles"
+)
+
+// NewRoleStorage creates and inits MongoDB role storage.
+func NewRoleStorage(db *DB) (model.RoleStorage, error) {
+	rs := &RoleStorage{
+		coll:          db.Database.Collection(rolesCollectionName),
+		userRolesColl: db.Database.Collection(userRolesCollectionName),
+		timeout:       30 * time.Second,
+	}
+
+	roleIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "app_id", Value: bsonx.Int32(int32(1))},
+			{Key: "name", Value: bsonx.Int32(int32(1))},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	if err := db.EnsureCollectionIndices(rolesCollectionName, []mongo.IndexModel{*roleIndex}); err != nil {
+		return nil, err
+	}
+
+	userRolesIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "app_id", Value: bsonx.Int32(int32(1))},
+			{Key: "user_id", Value: bsonx.Int32(int32(1))},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	err := db.EnsureCollectionIndices(userRolesCollectionName, []mongo.IndexModel{*userRolesIndex})
+	return rs, err
+}
+
+// RoleStorage implements role storage interface.
+type RoleStorage struct {
+	coll          *mongo.Collection
+	userRolesColl *mongo.Collection
+	timeout       time.Duration
+}
+
+// userRoles are the roles assigned to the user in the app.
+type userRoles struct {
+	AppID  string   `bson:"app_id"`
+	UserID string   `bson:"user_id"`
+	Roles  []string `bson:"roles"`
+}
+
+// AddRole saves new role.
+func (rs *RoleStorage) AddRole(role model.Role) (model.Role, error) {
+	role.ID = xid.New().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), rs.timeout)
+	defer cancel()
+
+	if _, err := rs.coll.InsertOne(ctx, role); err != nil {
+		return model.Role{}, err
+	}
+	return role, nil
+}
+
+// RoleByID returns role by its ID.
+func (rs *RoleStorage) RoleByID(id string) (model.Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rs.timeout)
+	defer cancel()
+
+	var role model.Role
+	if err := rs.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&role); err != nil {
+		if isErrNotFound(err) {
+			return role, model.ErrRoleNotFound
+		}
+		return role, err
+	}
+	return role, nil
+}
+
+// FetchRoles returns all roles of the app, sorted by name.
+func (rs *RoleStorage) FetchRoles(appID string) ([]model.Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rs.timeout)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{primitive.E{Key: "name", Value: 1}})
+	curr, err := rs.coll.Find(ctx, bson.M{"app_id": appID}, findOptions)
+	if err != nil {
+		return []model.Role{}, err
+	}
+
+	roles := []model.Role{}
+	if err = curr.All(ctx, &roles); err != nil {
+		return []model.Role{}, err
+	}
+	return roles, nil
+}
+
+// UpdateRole updates the role.
+func (rs *RoleStorage) UpdateRole(role model.Role) (model.Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rs.timeout)
+	defer cancel()
+
+	res, err := rs.coll.ReplaceOne(ctx, bson.M{"_id": role.ID}, role)
+	if err != nil {
+		return model.Role{}, err
+	}
+	if res.MatchedCount == 0 {
+		return model.Role{}, model.ErrRoleNotFound
+	}
+	return role, nil
+}
+
+// DeleteRole deletes the role and unassigns it from the users.
+func (rs *RoleStorage) DeleteRole(id string) error {
+	role, err := rs.RoleByID(id)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rs.timeout)
+	defer cancel()
+
+	res, err := rs.coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return model.ErrRoleNotFound
+	}
+
+	_, err = rs.userRolesColl.UpdateMany(ctx,
+		bson.M{"app_id": role.AppID, "roles": role.Name},
+		bson.M{"$pull": bson.M{"roles": role.Name}},
+	)
+	return err
+}
+
+// UserRoles returns names of the roles assigned to the user in the app.
+func (rs *RoleStorage) UserRoles(appID, userID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rs.timeout)
+	defer cancel()
+
+	var ur userRoles
+	if err := rs.userRolesColl.FindOne(ctx, bson.M{"app_id": appID, "user_id": userID}).Decode(&ur); err != nil {
+		if isErrNotFound(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	return ur.Roles, nil
+}
+
+// SetUserRoles replaces the roles assigned to the user in the app.
+func (rs *RoleStorage) SetUserRoles(appID, userID string, roles []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), rs.timeout)
+	defer cancel()
+
+	filter := bson.M{"app_id": appID, "user_id": userID}
+	if len(roles) == 0 {
+		_, err := rs.userRolesColl.DeleteOne(ctx, filter)
+		return err
+	}
+	_, err := rs.userRolesColl.ReplaceOne(ctx, filter, userRoles{AppID: appID, UserID: userID, Roles: roles}, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Close is a no-op here.
+func (rs *RoleStorage) Close() {}
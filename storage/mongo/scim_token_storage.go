@@ -0,0 +1,102 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const scimTokensCollectionName = "SCIMTokens"
+
+// NewSCIMTokenStorage creates and inits MongoDB SCIM token storage.
+func NewSCIMTokenStorage(db *DB) (model.SCIMTokenStorage, error) {
+	ss := &SCIMTokenStorage{coll: db.Database.Collection(scimTokensCollectionName), timeout: 30 * time.Second}
+
+	hashIndexOptions := &options.IndexOptions{}
+	hashIndexOptions.SetUnique(true)
+
+	hashIndex := &mongo.IndexModel{
+		Keys:    bsonx.Doc{{Key: "hash", Value: bsonx.Int32(int32(1))}},
+		Options: hashIndexOptions,
+	}
+
+	err := db.EnsureCollectionIndices(scimTokensCollectionName, []mongo.IndexModel{*hashIndex})
+	return ss, err
+}
+
+// SCIMTokenStorage implements SCIM token storage interface.
+type SCIMTokenStorage struct {
+	coll    *mongo.Collection
+	timeout time.Duration
+}
+
+// AddSCIMToken saves new token.
+func (ss *SCIMTokenStorage) AddSCIMToken(token model.SCIMToken) (model.SCIMToken, error) {
+	token.ID = xid.New().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ss.timeout)
+	defer cancel()
+
+	if _, err := ss.coll.InsertOne(ctx, token); err != nil {
+		return model.SCIMToken{}, err
+	}
+	return token, nil
+}
+
+// SCIMTokenByHash returns token by its hash.
+func (ss *SCIMTokenStorage) SCIMTokenByHash(hash string) (model.SCIMToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ss.timeout)
+	defer cancel()
+
+	var token model.SCIMToken
+	if err := ss.coll.FindOne(ctx, bson.M{"hash": hash}).Decode(&token); err != nil {
+		if isErrNotFound(err) {
+			return token, model.ErrSCIMTokenNotFound
+		}
+		return token, err
+	}
+	return token, nil
+}
+
+// FetchSCIMTokens returns all tokens, oldest first.
+func (ss *SCIMTokenStorage) FetchSCIMTokens() ([]model.SCIMToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ss.timeout)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{primitive.E{Key: "_id", Value: 1}})
+	curr, err := ss.coll.Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return []model.SCIMToken{}, err
+	}
+
+	tokens := []model.SCIMToken{}
+	if err = curr.All(ctx, &tokens); err != nil {
+		return []model.SCIMToken{}, err
+	}
+	return tokens, nil
+}
+
+// DeleteSCIMToken deletes the token.
+func (ss *SCIMTokenStorage) DeleteSCIMToken(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ss.timeout)
+	defer cancel()
+
+	res, err := ss.coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return model.ErrSCIMTokenNotFound
+	}
+	return nil
+}
+
+// Close is a no-op here.
+func (ss *SCIMTokenStorage) Close() {}
@@ -14,18 +14,22 @@ type User struct {
 
 // User data implementation.
 type userData struct {
-	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Username        string             `bson:"username,omitempty" json:"username,omitempty"`
-	Email           string             `bson:"email,omitempty" json:"email,omitempty"`
-	Phone           string             `bson:"phone,omitempty" json:"phone,omitempty"`
-	Pswd            string             `bson:"pswd,omitempty" json:"pswd,omitempty"`
-	Active          bool               `bson:"active,omitempty" json:"active,omitempty"`
-	TFAInfo         model.TFAInfo      `bson:"tfa_info" json:"tfa_info"`
-	FederatedIDs    []string           `bson:"federated_ids,omitempty" json:"federated_ids,omitempty"`
-	NumOfLogins     int                `bson:"num_of_logins" json:"num_of_logins,omitempty"`
-	LatestLoginTime int64              `bson:"latest_login_time,omitempty" json:"latest_login_time,omitempty"`
-	AccessRole      string             `bson:"access_role,omitempty" json:"access_role,omitempty"`
-	Anonymous       bool               `json:"anonymous,omitempty"`
+	ID               primitive.ObjectID     `bson:"_id,omitempty" json:"id,omitempty"`
+	Username         string                 `bson:"username,omitempty" json:"username,omitempty"`
+	Email            string                 `bson:"email,omitempty" json:"email,omitempty"`
+	Phone            string                 `bson:"phone,omitempty" json:"phone,omitempty"`
+	Pswd             string                 `bson:"pswd,omitempty" json:"pswd,omitempty"`
+	Active           bool                   `bson:"active" json:"active,omitempty"`
+	TFAInfo          model.TFAInfo          `bson:"tfa_info" json:"tfa_info"`
+	FederatedIDs     []string               `bson:"federated_ids,omitempty" json:"federated_ids,omitempty"`
+	NumOfLogins      int                    `bson:"num_of_logins" json:"num_of_logins,omitempty"`
+	LatestLoginTime  int64                  `bson:"latest_login_time,omitempty" json:"latest_login_time,omitempty"`
+	AccessRole       string                 `bson:"access_role,omitempty" json:"access_role,omitempty"`
+	Anonymous        bool                   `json:"anonymous,omitempty"`
+	TokensValidAfter int64                  `bson:"tokens_valid_after,omitempty" json:"tokens_valid_after,omitempty"`
+	UserMetadata     map[string]interface{} `bson:"user_metadata,omitempty" json:"user_metadata,omitempty"`
+	AppMetadata      map[string]interface{} `bson:"app_metadata,omitempty" json:"app_metadata,omitempty"`
+	Attributes       map[string]interface{} `bson:"attributes,omitempty" json:"attributes,omitempty"`
 }
 
 // Sanitize removes sensitive data.
@@ -61,6 +65,9 @@ func (u *User) SetEmail(email string) { u.userData.Email = email }
 // Phone implements model.User interface.
 func (u *User) Phone() string { return u.userData.Phone }
 
+// FederatedIDs implements model.User interface.
+func (u *User) FederatedIDs() []string { return u.userData.FederatedIDs }
+
 // TFAInfo implements model.User interface.
 func (u *User) TFAInfo() model.TFAInfo { return u.userData.TFAInfo }
 
@@ -73,6 +80,9 @@ func (u *User) PasswordHash() string { return u.userData.Pswd }
 // Active implements model.User interface.
 func (u *User) Active() bool { return u.userData.Active }
 
+// SetActive implements model.User interface.
+func (u *User) SetActive(active bool) { u.userData.Active = active }
+
 // AccessRole implements model.User interface.
 func (u *User) AccessRole() string { return u.userData.AccessRole }
 
@@ -81,3 +91,24 @@ func (u *User) IsAnonymous() bool { return u.userData.Anonymous }
 
 // Deanonimize implements model.User interface.
 func (u *User) Deanonimize() { u.userData.Anonymous = false }
+
+// TokensValidAfter implements model.User interface.
+func (u *User) TokensValidAfter() int64 { return u.userData.TokensValidAfter }
+
+// UserMetadata implements model.User interface.
+func (u *User) UserMetadata() map[string]interface{} { return u.userData.UserMetadata }
+
+// SetUserMetadata implements model.User interface.
+func (u *User) SetUserMetadata(metadata map[string]interface{}) { u.userData.UserMetadata = metadata }
+
+// AppMetadata implements model.User interface.
+func (u *User) AppMetadata() map[string]interface{} { return u.userData.AppMetadata }
+
+// SetAppMetadata implements model.User interface.
+func (u *User) SetAppMetadata(metadata map[string]interface{}) { u.userData.AppMetadata = metadata }
+
+// Attributes implements model.User interface.
+func (u *User) Attributes() map[string]interface{} { return u.userData.Attributes }
+
+// SetAttributes implements model.User interface.
+func (u *User) SetAttributes(attributes map[string]interface{}) { u.userData.Attributes = attributes }
@@ -0,0 +1,123 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const userSessionsCollectionName = "UserSessions"
+
+// NewUserSessionStorage creates and inits MongoDB user session storage.
+func NewUserSessionStorage(db *DB) (model.UserSessionStorage, error) {
+	coll := db.Database.Collection(userSessionsCollectionName)
+	ss := &UserSessionStorage{coll: coll, timeout: 30 * time.Second}
+
+	userIDIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{{Key: "user_id", Value: bsonx.Int32(int32(1))}},
+	}
+	tokensIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{{Key: "tokens", Value: bsonx.Int32(int32(1))}},
+	}
+
+	err := db.EnsureCollectionIndices(userSessionsCollectionName, []mongo.IndexModel{*userIDIndex, *tokensIndex})
+	return ss, err
+}
+
+// UserSessionStorage implements user session storage interface.
+type UserSessionStorage struct {
+	coll    *mongo.Collection
+	timeout time.Duration
+}
+
+// AddUserSession saves new session.
+func (ss *UserSessionStorage) AddUserSession(session model.UserSession) (model.UserSession, error) {
+	session.ID = xid.New().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ss.timeout)
+	defer cancel()
+
+	if _, err := ss.coll.InsertOne(ctx, session); err != nil {
+		return model.UserSession{}, err
+	}
+	return session, nil
+}
+
+// UserSessionByID returns session by its ID.
+func (ss *UserSessionStorage) UserSessionByID(id string) (model.UserSession, error) {
+	return ss.findOne(bson.M{"_id": id})
+}
+
+// UserSessionByToken returns session which has issued the token.
+func (ss *UserSessionStorage) UserSessionByToken(token string) (model.UserSession, error) {
+	return ss.findOne(bson.M{"tokens": token})
+}
+
+// FetchUserSessions returns all sessions of the user, most recently used first.
+func (ss *UserSessionStorage) FetchUserSessions(userID string) ([]model.UserSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ss.timeout)
+	defer cancel()
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{primitive.E{Key: "last_used_at", Value: -1}})
+
+	curr, err := ss.coll.Find(ctx, bson.M{"user_id": userID}, findOptions)
+	if err != nil {
+		return []model.UserSession{}, err
+	}
+
+	sessions := []model.UserSession{}
+	if err = curr.All(ctx, &sessions); err != nil {
+		return []model.UserSession{}, err
+	}
+	return sessions, nil
+}
+
+// UpdateUserSessionTokens replaces session tokens and sets last used time.
+func (ss *UserSessionStorage) UpdateUserSessionTokens(id string, tokens []string, lastUsedAt int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ss.timeout)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"tokens": tokens, "last_used_at": lastUsedAt}}
+	res, err := ss.coll.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return model.ErrUserSessionNotFound
+	}
+	return nil
+}
+
+// DeleteUserSession deletes session.
+func (ss *UserSessionStorage) DeleteUserSession(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ss.timeout)
+	defer cancel()
+
+	_, err := ss.coll.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// Close is a no-op here.
+func (ss *UserSessionStorage) Close() {}
+
+func (ss *UserSessionStorage) findOne(q bson.M) (model.UserSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ss.timeout)
+	defer cancel()
+
+	var session model.UserSession
+	if err := ss.coll.FindOne(ctx, q).Decode(&session); err != nil {
+		if isErrNotFound(err) {
+			return session, model.ErrUserSessionNotFound
+		}
+		return session, err
+	}
+	return session, nil
+}
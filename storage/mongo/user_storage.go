@@ -3,10 +3,10 @@ package mongo
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"strings"
 	"time"
 
+	"github.com/madappgang/identifo/logging"
 	"github.com/madappgang/identifo/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -18,15 +18,19 @@ import (
 
 const usersCollectionName = "Users"
 
+// usernameCollation makes usernames unique ignoring case. Queries by username use it to use the index.
+var usernameCollation = &options.Collation{Locale: "en", Strength: 1}
+
 // NewUserStorage creates and inits MongoDB user storage.
 func NewUserStorage(db *DB) (model.UserStorage, error) {
-	coll := db.Database.Collection(usersCollectionName)
+	// Nested user metadata is decoded into maps, not into ordered documents.
+	coll := db.Database.Collection(usersCollectionName, options.Collection().SetRegistry(metadataRegistry))
 	us := &UserStorage{coll: coll, timeout: 30 * time.Second}
 
 	userNameIndexOptions := &options.IndexOptions{}
 	userNameIndexOptions.SetUnique(true)
 	userNameIndexOptions.SetSparse(true)
-	userNameIndexOptions.SetCollation(&options.Collation{Locale: "en", Strength: 1})
+	userNameIndexOptions.SetCollation(usernameCollation)
 
 	userNameIndex := &mongo.IndexModel{
 		Keys:    bsonx.Doc{{Key: "username", Value: bsonx.Int32(int32(1))}},
@@ -51,7 +55,19 @@ func NewUserStorage(db *DB) (model.UserStorage, error) {
 		Options: phoneIndexOptions,
 	}
 
-	err := db.EnsureCollectionIndices(usersCollectionName, []mongo.IndexModel{*userNameIndex, *emailIndex, *phoneIndex})
+	// Federated identity can be linked to one user only, the index stops concurrent links of the same identity.
+	// Users without federated identities may keep an empty array, which sparse index would count as a duplicate,
+	// so only users with at least one identity are indexed.
+	federatedIDsIndexOptions := &options.IndexOptions{}
+	federatedIDsIndexOptions.SetUnique(true)
+	federatedIDsIndexOptions.SetPartialFilterExpression(bson.M{"federated_ids": bson.M{"$type": "string"}})
+
+	federatedIDsIndex := &mongo.IndexModel{
+		Keys:    bsonx.Doc{{Key: "federated_ids", Value: bsonx.Int32(int32(1))}},
+		Options: federatedIDsIndexOptions,
+	}
+
+	err := db.EnsureCollectionIndices(usersCollectionName, []mongo.IndexModel{*userNameIndex, *emailIndex, *phoneIndex, *federatedIDsIndex})
 	return us, err
 }
 
@@ -100,6 +116,22 @@ func (us *UserStorage) UserByEmail(email string) (model.User, error) {
 	return &User{userData: u}, nil
 }
 
+// UserByUsername returns user by username, ignoring case.
+func (us *UserStorage) UserByUsername(username string) (model.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), us.timeout)
+	defer cancel()
+
+	var u userData
+	err := us.coll.FindOne(ctx, bson.M{"username": username}, options.FindOne().SetCollation(usernameCollation)).Decode(&u)
+	if err == mongo.ErrNoDocuments {
+		return nil, model.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &User{userData: u}, nil
+}
+
 // UserByFederatedID returns user by federated ID.
 func (us *UserStorage) UserByFederatedID(provider model.FederatedIdentityProvider, id string) (model.User, error) {
 	sid := string(provider) + ":" + id
@@ -303,6 +335,130 @@ func (us *UserStorage) UpdateUser(userID string, newUser model.User) (model.User
 	return &User{userData: ud}, nil
 }
 
+// LinkFederatedID attaches federated identity to the existing user.
+func (us *UserStorage) LinkFederatedID(userID string, provider model.FederatedIdentityProvider, id string) error {
+	hexID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	if u, err := us.UserByFederatedID(provider, id); err == nil {
+		if u.ID() == userID {
+			return nil
+		}
+		return model.ErrorUserExists
+	}
+
+	// Concurrent link of the same identity to another user fails on the unique index with ErrorUserExists.
+	update := bson.M{"$addToSet": bson.M{"federated_ids": model.FederatedIDKey(provider, id)}}
+	return us.updateUserFields(hexID, update)
+}
+
+// UnlinkFederatedID detaches federated identity from the user.
+func (us *UserStorage) UnlinkFederatedID(userID string, provider model.FederatedIdentityProvider, id string) error {
+	hexID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$pull": bson.M{"federated_ids": model.FederatedIDKey(provider, id)}}
+	return us.updateUserFields(hexID, update)
+}
+
+// LinkPhone attaches phone number to the existing user.
+func (us *UserStorage) LinkPhone(userID, phone string) error {
+	hexID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	if u, err := us.UserByPhone(phone); err == nil && u.ID() != userID {
+		return model.ErrorUserExists
+	}
+
+	update := bson.M{"$set": bson.M{"phone": phone}}
+	return us.updateUserFields(hexID, update)
+}
+
+// UnlinkPhone removes phone number from the user.
+func (us *UserStorage) UnlinkPhone(userID string) error {
+	hexID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	// Unset instead of setting empty value, otherwise the unique sparse index is violated.
+	update := bson.M{"$unset": bson.M{"phone": ""}}
+	return us.updateUserFields(hexID, update)
+}
+
+// LinkPassword sets username and password for the existing user.
+func (us *UserStorage) LinkPassword(userID, username, password string) error {
+	hexID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	fields := bson.M{"pswd": PasswordHash(password)}
+	if username != "" {
+		if id, err := us.IDByName(username); err == nil && id != userID {
+			return model.ErrorUserExists
+		}
+		fields["username"] = username
+	}
+	return us.updateUserFields(hexID, bson.M{"$set": fields})
+}
+
+// UnlinkPassword removes password from the user.
+func (us *UserStorage) UnlinkPassword(userID string) error {
+	hexID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$unset": bson.M{"pswd": ""}}
+	return us.updateUserFields(hexID, update)
+}
+
+// DeanonimizeUser turns anonymous user into the regular one.
+func (us *UserStorage) DeanonimizeUser(userID string) error {
+	hexID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"anonymous": false}}
+	return us.updateUserFields(hexID, update)
+}
+
+// SetTokensValidAfter invalidates all user tokens issued before the timestamp.
+func (us *UserStorage) SetTokensValidAfter(userID string, timestamp int64) error {
+	hexID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"tokens_valid_after": timestamp}}
+	return us.updateUserFields(hexID, update)
+}
+
+func (us *UserStorage) updateUserFields(id primitive.ObjectID, update bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), us.timeout)
+	defer cancel()
+
+	res, err := us.coll.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		if isErrDuplication(err) {
+			return model.ErrorUserExists
+		}
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return model.ErrUserNotFound
+	}
+	return nil
+}
+
 // ResetPassword sets new user's password.
 func (us *UserStorage) ResetPassword(id, password string) error {
 	hexID, err := primitive.ObjectIDFromHex(id)
@@ -373,10 +529,13 @@ func (us *UserStorage) DeleteUser(id string) error {
 	return err
 }
 
-// FetchUsers fetches users which name satisfies provided filterString.
+// FetchUsers fetches users which name satisfies provided filterString and which attributes match.
 // Supports pagination.
-func (us *UserStorage) FetchUsers(filterString string, skip, limit int) ([]model.User, int, error) {
+func (us *UserStorage) FetchUsers(filterString string, attributes map[string]interface{}, skip, limit int) ([]model.User, int, error) {
 	q := bson.D{primitive.E{Key: "username", Value: primitive.Regex{Pattern: filterString, Options: "i"}}}
+	for name, value := range attributes {
+		q = append(q, primitive.E{Key: "attributes." + name, Value: value})
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*us.timeout)
 	defer cancel()
@@ -428,7 +587,7 @@ func (us *UserStorage) ImportJSON(data []byte) error {
 func (us *UserStorage) UpdateLoginMetadata(userID string) {
 	hexID, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
-		log.Printf("Cannot update login metadata of user %s: %s\n", userID, err)
+		logging.Errorf("Cannot update login metadata of user %s: %s\n", userID, err)
 		return
 	}
 
@@ -442,7 +601,7 @@ func (us *UserStorage) UpdateLoginMetadata(userID string) {
 
 	var ud userData
 	if err := us.coll.FindOneAndUpdate(ctx, bson.M{"_id": hexID}, update).Decode(&ud); err != nil {
-		log.Printf("Cannot update login metadata of user %s: %s\n", userID, err)
+		logging.Errorf("Cannot update login metadata of user %s: %s\n", userID, err)
 	}
 }
 
@@ -0,0 +1,234 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/rs/xid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+const (
+	webhooksCollectionName          = "Webhooks"
+	webhookDeliveriesCollectionName = "WebhookDeliveries"
+)
+
+// NewWebhookStorage creates and inits MongoDB webhook storage.
+func NewWebhookStorage(db *DB) (model.WebhookStorage, error) {
+	ws := &WebhookStorage{
+		webhooks:   db.Database.Collection(webhooksCollectionName),
+		deliveries: db.Database.Collection(webhookDeliveriesCollectionName),
+		timeout:    30 * time.Second,
+	}
+
+	pendingIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{
+			{Key: "status", Value: bsonx.Int32(int32(1))},
+			{Key: "next_attempt_at", Value: bsonx.Int32(int32(1))},
+		},
+	}
+	webhookIDIndex := &mongo.IndexModel{
+		Keys: bsonx.Doc{{Key: "webhook_id", Value: bsonx.Int32(int32(1))}},
+	}
+
+	err := db.EnsureCollectionIndices(webhookDeliveriesCollectionName, []mongo.IndexModel{*pendingIndex, *webhookIDIndex})
+	return ws, err
+}
+
+// WebhookStorage implements webhook storage interface.
+type WebhookStorage struct {
+	webhooks   *mongo.Collection
+	deliveries *mongo.Collection
+	timeout    time.Duration
+}
+
+// AddWebhook saves new webhook.
+func (ws *WebhookStorage) AddWebhook(webhook model.Webhook) (model.Webhook, error) {
+	webhook.ID = xid.New().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ws.timeout)
+	defer cancel()
+
+	if _, err := ws.webhooks.InsertOne(ctx, webhook); err != nil {
+		return model.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// WebhookByID returns webhook by ID.
+func (ws *WebhookStorage) WebhookByID(id string) (model.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ws.timeout)
+	defer cancel()
+
+	var webhook model.Webhook
+	if err := ws.webhooks.FindOne(ctx, bson.M{"_id": id}).Decode(&webhook); err != nil {
+		if isErrNotFound(err) {
+			return webhook, model.ErrWebhookNotFound
+		}
+		return webhook, err
+	}
+	return webhook, nil
+}
+
+// FetchWebhooks returns all webhooks, oldest first.
+func (ws *WebhookStorage) FetchWebhooks() ([]model.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ws.timeout)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{primitive.E{Key: "created_at", Value: 1}})
+	curr, err := ws.webhooks.Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return []model.Webhook{}, err
+	}
+
+	webhooks := []model.Webhook{}
+	if err = curr.All(ctx, &webhooks); err != nil {
+		return []model.Webhook{}, err
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook replaces stored webhook.
+func (ws *WebhookStorage) UpdateWebhook(webhook model.Webhook) (model.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ws.timeout)
+	defer cancel()
+
+	res, err := ws.webhooks.ReplaceOne(ctx, bson.M{"_id": webhook.ID}, webhook)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	if res.MatchedCount == 0 {
+		return model.Webhook{}, model.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook deletes webhook.
+func (ws *WebhookStorage) DeleteWebhook(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ws.timeout)
+	defer cancel()
+
+	res, err := ws.webhooks.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return model.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// AddWebhookDelivery saves new delivery.
+func (ws *WebhookStorage) AddWebhookDelivery(delivery model.WebhookDelivery) (model.WebhookDelivery, error) {
+	delivery.ID = xid.New().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ws.timeout)
+	defer cancel()
+
+	if _, err := ws.deliveries.InsertOne(ctx, delivery); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// WebhookDeliveryByID returns delivery by ID.
+func (ws *WebhookStorage) WebhookDeliveryByID(id string) (model.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ws.timeout)
+	defer cancel()
+
+	var delivery model.WebhookDelivery
+	if err := ws.deliveries.FindOne(ctx, bson.M{"_id": id}).Decode(&delivery); err != nil {
+		if isErrNotFound(err) {
+			return delivery, model.ErrWebhookDeliveryNotFound
+		}
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+// UpdateWebhookDelivery replaces stored delivery.
+func (ws *WebhookStorage) UpdateWebhookDelivery(delivery model.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ws.timeout)
+	defer cancel()
+
+	res, err := ws.deliveries.ReplaceOne(ctx, bson.M{"_id": delivery.ID}, delivery)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return model.ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
+
+// FetchWebhookDeliveries returns deliveries matching the filter, newest first.
+func (ws *WebhookStorage) FetchWebhookDeliveries(filter model.WebhookDeliveryFilter, skip, limit int) ([]model.WebhookDelivery, int, error) {
+	q := bson.M{}
+	if filter.WebhookID != "" {
+		q["webhook_id"] = filter.WebhookID
+	}
+	if filter.Status != "" {
+		q["status"] = filter.Status
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*ws.timeout)
+	defer cancel()
+
+	total, err := ws.deliveries.CountDocuments(ctx, q)
+	if err != nil {
+		return []model.WebhookDelivery{}, 0, err
+	}
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{primitive.E{Key: "_id", Value: -1}})
+	findOptions.SetLimit(int64(limit))
+	findOptions.SetSkip(int64(skip))
+
+	curr, err := ws.deliveries.Find(ctx, q, findOptions)
+	if err != nil {
+		return []model.WebhookDelivery{}, 0, err
+	}
+
+	deliveries := []model.WebhookDelivery{}
+	if err = curr.All(ctx, &deliveries); err != nil {
+		return []model.WebhookDelivery{}, 0, err
+	}
+	return deliveries, int(total), nil
+}
+
+// PendingWebhookDeliveries returns pending deliveries due at the time, oldest first.
+func (ws *WebhookStorage) PendingWebhookDeliveries(now int64, limit int) ([]model.WebhookDelivery, error) {
+	q := bson.M{
+		"status": model.WebhookDeliveryPending,
+		"$or": bson.A{
+			bson.M{"next_attempt_at": bson.M{"$lte": now}},
+			bson.M{"next_attempt_at": bson.M{"$exists": false}},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ws.timeout)
+	defer cancel()
+
+	findOptions := options.Find()
+	findOptions.SetSort(bson.D{primitive.E{Key: "_id", Value: 1}})
+	findOptions.SetLimit(int64(limit))
+
+	curr, err := ws.deliveries.Find(ctx, q, findOptions)
+	if err != nil {
+		return []model.WebhookDelivery{}, err
+	}
+
+	deliveries := []model.WebhookDelivery{}
+	if err = curr.All(ctx, &deliveries); err != nil {
+		return []model.WebhookDelivery{}, err
+	}
+	return deliveries, nil
+}
+
+// Close is a no-op here.
+func (ws *WebhookStorage) Close() {}
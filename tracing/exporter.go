@@ -0,0 +1,239 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// exporter encodes spans with OTLP JSON encoding, and sends them to OTLP/HTTP endpoint or writes them to the writer, one batch per line.
+// OTLP gRPC and protobuf exporters are not used, as they need newer gRPC than etcd client supports.
+type exporter struct {
+	endpoint string
+	client   *http.Client
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newExporter(w io.Writer, endpoint string) *exporter {
+	return &exporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		w:        w,
+	}
+}
+
+// ExportSpans implements sdktrace.SpanExporter interface.
+func (e *exporter) ExportSpans(ctx context.Context, spans []*sdktrace.SpanSnapshot) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(encodeSpans(spans))
+	if err != nil {
+		return err
+	}
+
+	if e.w != nil {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		_, err = e.w.Write(append(data, '\n'))
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Collector responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Shutdown implements sdktrace.SpanExporter interface.
+func (e *exporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// The types below follow OTLP JSON encoding of ExportTraceServiceRequest.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// OTLP status codes differ from the API ones.
+const (
+	otlpStatusOk    = 1
+	otlpStatusError = 2
+)
+
+// encodeSpans groups the spans by resource and instrumentation library.
+func encodeSpans(spans []*sdktrace.SpanSnapshot) otlpRequest {
+	req := otlpRequest{}
+	resources := map[string]int{}
+	scopes := map[string]int{}
+
+	for _, s := range spans {
+		var resourceAttrs []attribute.KeyValue
+		resourceKey := ""
+		if s.Resource != nil {
+			resourceAttrs = s.Resource.Attributes()
+			resourceKey = s.Resource.Encoded(attribute.DefaultEncoder())
+		}
+		ri, ok := resources[resourceKey]
+		if !ok {
+			ri = len(req.ResourceSpans)
+			resources[resourceKey] = ri
+			req.ResourceSpans = append(req.ResourceSpans, otlpResourceSpans{Resource: otlpResource{Attributes: encodeAttributes(resourceAttrs)}})
+		}
+
+		scopeKey := resourceKey + "|" + s.InstrumentationLibrary.Name + "|" + s.InstrumentationLibrary.Version
+		si, ok := scopes[scopeKey]
+		if !ok {
+			si = len(req.ResourceSpans[ri].ScopeSpans)
+			scopes[scopeKey] = si
+			req.ResourceSpans[ri].ScopeSpans = append(req.ResourceSpans[ri].ScopeSpans, otlpScopeSpans{
+				Scope: otlpScope{Name: s.InstrumentationLibrary.Name, Version: s.InstrumentationLibrary.Version},
+			})
+		}
+
+		scope := &req.ResourceSpans[ri].ScopeSpans[si]
+		scope.Spans = append(scope.Spans, encodeSpan(s))
+	}
+	return req
+}
+
+func encodeSpan(s *sdktrace.SpanSnapshot) otlpSpan {
+	span := otlpSpan{
+		TraceID:           s.SpanContext.TraceID().String(),
+		SpanID:            s.SpanContext.SpanID().String(),
+		Name:              s.Name,
+		Kind:              int(s.SpanKind),
+		StartTimeUnixNano: unixNano(s.StartTime),
+		EndTimeUnixNano:   unixNano(s.EndTime),
+		Attributes:        encodeAttributes(s.Attributes),
+	}
+	if s.Parent.HasSpanID() {
+		span.ParentSpanID = s.Parent.SpanID().String()
+	}
+	for _, e := range s.MessageEvents {
+		span.Events = append(span.Events, otlpEvent{
+			TimeUnixNano: unixNano(e.Time),
+			Name:         e.Name,
+			Attributes:   encodeAttributes(e.Attributes),
+		})
+	}
+	switch s.StatusCode {
+	case codes.Ok:
+		span.Status.Code = otlpStatusOk
+	case codes.Error:
+		span.Status = otlpStatus{Code: otlpStatusError, Message: s.StatusMessage}
+	}
+	return span
+}
+
+func encodeAttributes(attrs []attribute.KeyValue) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	result := make([]otlpKeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		value := otlpValue{}
+		switch kv.Value.Type() {
+		case attribute.BOOL:
+			b := kv.Value.AsBool()
+			value.BoolValue = &b
+		case attribute.INT64:
+			i := strconv.FormatInt(kv.Value.AsInt64(), 10)
+			value.IntValue = &i
+		case attribute.FLOAT64:
+			f := kv.Value.AsFloat64()
+			value.DoubleValue = &f
+		default:
+			s := kv.Value.Emit()
+			value.StringValue = &s
+		}
+		result = append(result, otlpKeyValue{Key: string(kv.Key), Value: value})
+	}
+	return result
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/madappgang/identifo/metrics"
+	"github.com/urfave/negroni"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware returns negroni middleware which records span of the request, continuing the trace of the caller.
+// URLs are not recorded in full, as query strings of some routes carry tokens.
+func Middleware() negroni.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		if !Enabled() {
+			next(rw, r)
+			return
+		}
+
+		// Span is named after the route, once the routers matched it.
+		r = metrics.TrackRoute(r)
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := Start(ctx, r.Method, trace.SpanKindServer,
+			semconv.HTTPMethodKey.String(r.Method),
+			semconv.HTTPHostKey.String(r.Host),
+		)
+		defer span.End()
+
+		r = r.WithContext(ctx)
+		next(rw, r)
+
+		route := metrics.MatchedRoute(r)
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRouteKey.String(route))
+
+		status := http.StatusOK
+		if nrw, ok := rw.(negroni.ResponseWriter); ok && nrw.Status() != 0 {
+			status = nrw.Status()
+		}
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+		// Client errors are not errors of the server.
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// Transport returns HTTP transport which records spans of the outgoing requests and propagates trace context to the called services.
+// The span in the context of the request is the parent.
+// The base transport is http.DefaultTransport if it is nil.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper interface.
+func (t transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if !Enabled() {
+		return t.base.RoundTrip(r)
+	}
+
+	ctx, span := Start(r.Context(), "HTTP "+r.Method+" "+r.URL.Host, trace.SpanKindClient,
+		semconv.HTTPMethodKey.String(r.Method),
+		semconv.HTTPHostKey.String(r.URL.Host),
+	)
+	r = r.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		End(span, err)
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)...)
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode))
+	span.End()
+	return resp, nil
+}
@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"context"
+	"html/template"
+
+	"github.com/madappgang/identifo/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartStorageSpan starts span of the storage operation. Backends start it from their driver hooks,
+// with the context the driver got, if any.
+func StartStorageSpan(ctx context.Context, backend, operation string) trace.Span {
+	return StartSpan(ctx, backend+" "+operation, trace.SpanKindClient,
+		semconv.DBSystemKey.String(backend),
+		semconv.DBOperationKey.String(operation),
+	)
+}
+
+// InstrumentSMSService records spans of SMS sending with the service of the provider.
+func InstrumentSMSService(sms model.SMSService, provider string) model.SMSService {
+	if sms == nil {
+		return nil
+	}
+	return &smsService{SMSService: sms, provider: provider}
+}
+
+type smsService struct {
+	model.SMSService
+	provider string
+}
+
+// SendSMS implements model.SMSService interface.
+func (s *smsService) SendSMS(recipient, message string) error {
+	span := StartSpan(context.Background(), "SMS send", trace.SpanKindClient, attribute.String("sms.provider", s.provider))
+	err := s.SMSService.SendSMS(recipient, message)
+	End(span, err)
+	return err
+}
+
+// InstrumentEmailService records spans of email sending with the service of the provider.
+func InstrumentEmailService(es model.EmailService, provider string) model.EmailService {
+	if es == nil {
+		return nil
+	}
+	return &emailService{EmailService: es, provider: provider}
+}
+
+type emailService struct {
+	model.EmailService
+	provider string
+}
+
+func (s *emailService) trace(kind string, send func() error) error {
+	span := StartSpan(context.Background(), "Email send "+kind, trace.SpanKindClient, attribute.String("email.provider", s.provider))
+	err := send()
+	End(span, err)
+	return err
+}
+
+// SendMessage implements model.EmailService interface.
+func (s *emailService) SendMessage(subject, body, recipient string) error {
+	return s.trace("message", func() error { return s.EmailService.SendMessage(subject, body, recipient) })
+}
+
+// SendHTML implements model.EmailService interface.
+func (s *emailService) SendHTML(subject, html, recipient string) error {
+	return s.trace("html", func() error { return s.EmailService.SendHTML(subject, html, recipient) })
+}
+
+// SendTemplateEmail implements model.EmailService interface.
+func (s *emailService) SendTemplateEmail(subject, recipient string, template *template.Template, data interface{}) error {
+	return s.trace("template", func() error { return s.EmailService.SendTemplateEmail(subject, recipient, template, data) })
+}
+
+// SendResetEmail implements model.EmailService interface.
+func (s *emailService) SendResetEmail(subject, recipient string, data interface{}) error {
+	return s.trace("reset", func() error { return s.EmailService.SendResetEmail(subject, recipient, data) })
+}
+
+// SendInviteEmail implements model.EmailService interface.
+func (s *emailService) SendInviteEmail(subject, recipient string, data interface{}) error {
+	return s.trace("invite", func() error { return s.EmailService.SendInviteEmail(subject, recipient, data) })
+}
+
+// SendWelcomeEmail implements model.EmailService interface.
+func (s *emailService) SendWelcomeEmail(subject, recipient string, data interface{}) error {
+	return s.trace("welcome", func() error { return s.EmailService.SendWelcomeEmail(subject, recipient, data) })
+}
+
+// SendVerifyEmail implements model.EmailService interface.
+func (s *emailService) SendVerifyEmail(subject, recipient string, data interface{}) error {
+	return s.trace("verify", func() error { return s.EmailService.SendVerifyEmail(subject, recipient, data) })
+}
+
+// SendTFAEmail implements model.EmailService interface.
+func (s *emailService) SendTFAEmail(subject, recipient string, data interface{}) error {
+	return s.trace("tfa", func() error { return s.EmailService.SendTFAEmail(subject, recipient, data) })
+}
+
+// InstrumentFederatedProvider records spans of identity verification with the federated provider.
+// Providers supporting redirect flow keep supporting it.
+func InstrumentFederatedProvider(provider model.FederatedIdentityVerifier, name model.FederatedIdentityProvider) model.FederatedIdentityVerifier {
+	verifier := &federatedVerifier{verifier: provider, name: string(name)}
+	if redirect, ok := provider.(model.FederatedRedirectProvider); ok {
+		return &federatedRedirectProvider{federatedVerifier: verifier, redirect: redirect}
+	}
+	return verifier
+}
+
+type federatedVerifier struct {
+	verifier model.FederatedIdentityVerifier
+	name     string
+}
+
+// Identity implements model.FederatedIdentityVerifier interface.
+func (v *federatedVerifier) Identity(app model.AppData, credentials model.FederatedCredentials) (model.FederatedIdentity, error) {
+	span := StartSpan(context.Background(), "Federated identity "+v.name, trace.SpanKindClient,
+		attribute.String("federated.provider", v.name),
+		attribute.String("app.id", app.ID()),
+	)
+	identity, err := v.verifier.Identity(app, credentials)
+	End(span, err)
+	return identity, err
+}
+
+type federatedRedirectProvider struct {
+	*federatedVerifier
+	redirect model.FederatedRedirectProvider
+}
+
+// IsConfigured implements model.FederatedRedirectProvider interface.
+func (p *federatedRedirectProvider) IsConfigured(app model.AppData) bool {
+	return p.redirect.IsConfigured(app)
+}
+
+// AuthCodeURL implements model.FederatedRedirectProvider interface.
+func (p *federatedRedirectProvider) AuthCodeURL(app model.AppData, redirectURI, state, nonce string) (string, error) {
+	return p.redirect.AuthCodeURL(app, redirectURI, state, nonce)
+}
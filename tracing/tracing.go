@@ -0,0 +1,141 @@
+// Package tracing records OpenTelemetry spans of HTTP requests, storage operations and external service calls.
+// Spans are exported to OpenTelemetry collector over OTLP/HTTP, or written to stdout.
+package tracing
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer.
+const instrumentationName = "github.com/madappgang/identifo"
+
+// shutdownTimeout limits flushing of the spans on shutdown.
+const shutdownTimeout = 5 * time.Second
+
+var (
+	// enabled is 1 when tracing is set up, so disabled tracing costs nothing in storage hooks.
+	enabled int32
+
+	mu       sync.Mutex
+	provider *sdktrace.TracerProvider
+)
+
+func init() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Setup sets up tracing, replacing the previous setup. Tracing is disabled if exporter is not set.
+// Settings are expected to be validated.
+func Setup(settings model.TracingSettings) {
+	var exporter sdktrace.SpanExporter
+	switch settings.Exporter {
+	case model.TracingExporterNone:
+	case model.TracingExporterStdout:
+		exporter = newExporter(os.Stdout, "")
+	case model.TracingExporterOTLP:
+		endpoint := settings.Endpoint
+		if len(endpoint) == 0 {
+			endpoint = model.DefaultOTLPEndpoint
+		}
+		exporter = newExporter(nil, endpoint)
+	}
+
+	var tp *sdktrace.TracerProvider
+	if exporter != nil {
+		serviceName := settings.ServiceName
+		if len(serviceName) == 0 {
+			serviceName = model.DefaultTracingServiceName
+		}
+		ratio := settings.SampleRatio
+		if ratio <= 0 {
+			ratio = 1
+		}
+		tp = sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(sdkresource.NewWithAttributes(semconv.ServiceNameKey.String(serviceName))),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		)
+	}
+	install(tp)
+}
+
+// Shutdown exports the remaining spans and disables tracing.
+func Shutdown() {
+	install(nil)
+}
+
+// install makes the provider global, shutting down the previous one. Tracing is disabled if the provider is nil.
+func install(tp *sdktrace.TracerProvider) {
+	mu.Lock()
+	previous := provider
+	provider = tp
+	if tp != nil {
+		otel.SetTracerProvider(tp)
+		atomic.StoreInt32(&enabled, 1)
+	} else {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		atomic.StoreInt32(&enabled, 0)
+	}
+	mu.Unlock()
+
+	if previous != nil {
+		shutdown(previous)
+	}
+}
+
+func shutdown(tp *sdktrace.TracerProvider) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := tp.Shutdown(ctx); err != nil {
+		logging.Errorf("Error shutting down tracer provider: %s", err)
+	}
+}
+
+// Enabled checks if tracing is set up.
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts span with the context as a parent.
+func Start(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// StartSpan starts span of the operation, like storage and service calls, with the span in the context as a parent.
+// Storage and service interfaces do not take context, so their calls usually start their own traces.
+// The span is not recording if tracing is disabled.
+func StartSpan(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) trace.Span {
+	if !Enabled() {
+		return trace.SpanFromContext(context.Background())
+	}
+	_, span := Start(ctx, name, kind, attrs...)
+	return span
+}
+
+// End records the error, if any, and ends the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
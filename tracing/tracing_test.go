@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/madappgang/identifo/metrics"
+	"github.com/urfave/negroni"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestMiddlewareContinuesTrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	install(sdktrace.NewTracerProvider(sdktrace.WithSyncer(newExporter(buf, ""))))
+	defer Shutdown()
+
+	router := mux.NewRouter()
+	router.Use(metrics.RouteMiddleware)
+	n := negroni.New(Middleware())
+	n.UseHandler(router)
+	router.Path("/admin/users/{id:[0-9]+}").HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		// Drivers pass the context they got, so the caller passing the request context gets child spans.
+		span := StartStorageSpan(r.Context(), "mongodb", "find")
+		End(span, errors.New("connection reset"))
+		rw.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := map[string]otlpSpan{}
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var exported otlpRequest
+		if err := json.Unmarshal(line, &exported); err != nil {
+			t.Fatalf("Error decoding exported spans: %s", err)
+		}
+		for _, rs := range exported.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					spans[s.Name] = s
+				}
+			}
+		}
+	}
+
+	server, ok := spans["GET /admin/users/{id}"]
+	if !ok {
+		t.Fatalf("Request span is not exported, got %v", spans)
+	}
+	if server.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || server.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("Request span does not continue the trace of the caller: %+v", server)
+	}
+	if server.Status.Code != otlpStatusError {
+		t.Errorf("Request span status = %d, expected error", server.Status.Code)
+	}
+
+	storage, ok := spans["mongodb find"]
+	if !ok {
+		t.Fatalf("Storage span is not exported, got %v", spans)
+	}
+	if storage.TraceID != server.TraceID || storage.ParentSpanID != server.SpanID {
+		t.Errorf("Storage span is not a child of the request span: %+v", storage)
+	}
+	if storage.Status.Code != otlpStatusError || storage.Status.Message != "connection reset" {
+		t.Errorf("Storage span status = %+v, expected error", storage.Status)
+	}
+}
@@ -0,0 +1,276 @@
+package admin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+)
+
+// adminInviteLifespan is how long the invited admin can accept the invite.
+const adminInviteLifespan = 7 * 24 * time.Hour
+
+// adminView is an admin account representation without password and invite hashes.
+type adminView struct {
+	ID         string          `json:"id"`
+	Email      string          `json:"email"`
+	Role       model.AdminRole `json:"role"`
+	Disabled   bool            `json:"disabled"`
+	Pending    bool            `json:"pending"`
+	TFAEnabled bool            `json:"tfa_enabled"`
+	InvitedBy  string          `json:"invited_by,omitempty"`
+	CreatedAt  int64           `json:"created_at"`
+}
+
+func newAdminView(a model.Admin) adminView {
+	return adminView{
+		ID:         a.ID,
+		Email:      a.Email,
+		Role:       a.Role,
+		Disabled:   a.Disabled,
+		Pending:    a.IsPending(),
+		TFAEnabled: a.TFAEnabled,
+		InvitedBy:  a.InvitedBy,
+		CreatedAt:  a.CreatedAt,
+	}
+}
+
+type adminInviteData struct {
+	Email string          `json:"email" validate:"required,email"`
+	Role  model.AdminRole `json:"role" validate:"required"`
+}
+
+type acceptAdminInviteData struct {
+	Email    string `json:"email" validate:"required"`
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type adminUpdateData struct {
+	Role     *model.AdminRole `json:"role,omitempty"`
+	Disabled *bool            `json:"disabled,omitempty"`
+}
+
+// FetchAdmins returns all admin accounts.
+func (ar *Router) FetchAdmins() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		admins, err := ar.adminStorage.FetchAdmins()
+		if err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		result := make([]adminView, len(admins))
+		for i, a := range admins {
+			result[i] = newAdminView(a)
+		}
+		ar.ServeJSON(w, http.StatusOK, result)
+	}
+}
+
+// InviteAdmin creates pending admin account and emails the invite link to it.
+// The link is returned as well, so it can be passed to the admin if email is not delivered.
+func (ar *Router) InviteAdmin() http.HandlerFunc {
+	type invitedAdmin struct {
+		adminView
+		Link string `json:"link"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := adminInviteData{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+		if !d.Role.IsValid() {
+			err := fmt.Errorf("Unknown admin role %s", d.Role)
+			ar.Error(w, err, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		token, err := randomAdminInviteToken()
+		if err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "Creating invite token")
+			return
+		}
+
+		now := time.Now()
+		admin := model.Admin{
+			Email:     strings.ToLower(strings.TrimSpace(d.Email)),
+			Role:      d.Role,
+			InvitedBy: adminFromContext(r.Context()).ID,
+			CreatedAt: now.Unix(),
+		}
+		if err = admin.SetInvite(token, now.Add(adminInviteLifespan).Unix()); err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "Creating invite token")
+			return
+		}
+
+		admin, err = ar.adminStorage.AddAdmin(admin)
+		if err == model.ErrAdminExists {
+			ar.Error(w, err, http.StatusConflict, "")
+			return
+		}
+		if err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "Creating admin")
+			return
+		}
+
+		link := ar.adminInviteLink(admin.Email, token)
+		if err = ar.emailService.SendInviteEmail("Admin panel invitation", admin.Email, link); err != nil {
+			ar.logger.WithRequest(r).Errorf("Cannot send admin invite email to %s: %s", admin.Email, err)
+		}
+
+		ar.logger.WithRequest(r).Infof("Admin %s invited with %s role", admin.ID, admin.Role)
+		ar.audit(r, "admin.invite", "admin", admin.ID, nil, newAdminView(admin))
+		ar.ServeJSON(w, http.StatusOK, invitedAdmin{adminView: newAdminView(admin), Link: link})
+	}
+}
+
+// AcceptAdminInvite sets password of the invited admin, so the admin can log in.
+func (ar *Router) AcceptAdminInvite() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := acceptAdminInviteData{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+		if ar.validateAdminPassword(d.Password, w) != nil {
+			return
+		}
+
+		admin, err := ar.adminStorage.AdminByEmail(strings.ToLower(strings.TrimSpace(d.Email)))
+		if err != nil && err != model.ErrAdminNotFound {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+		if err == model.ErrAdminNotFound || !admin.CheckInvite(d.Token, time.Now().Unix()) {
+			ar.Error(w, ErrorAdminInviteInvalid, http.StatusBadRequest, "")
+			return
+		}
+
+		if err = admin.SetPassword(d.Password); err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+		if _, err = ar.adminStorage.UpdateAdmin(admin); err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Admin %s accepted invite", admin.ID)
+		ar.auditAs(r, admin, "", "admin.accept_invite", "admin", admin.ID, nil, nil)
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// UpdateAdmin changes admin role or disables admin account.
+// Admins cannot change their own accounts, so there is always an active owner.
+func (ar *Router) UpdateAdmin() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID := getRouteVar("id", r)
+		if ar.rejectOwnAccount(w, r, adminID) {
+			return
+		}
+
+		d := adminUpdateData{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+
+		admin, err := ar.adminStorage.AdminByID(adminID)
+		if err != nil {
+			if err == model.ErrAdminNotFound {
+				ar.Error(w, err, http.StatusNotFound, "")
+			} else {
+				ar.Error(w, err, http.StatusInternalServerError, "")
+			}
+			return
+		}
+
+		before := newAdminView(admin)
+		if d.Role != nil {
+			if !d.Role.IsValid() {
+				err = fmt.Errorf("Unknown admin role %s", *d.Role)
+				ar.Error(w, err, http.StatusBadRequest, err.Error())
+				return
+			}
+			admin.Role = *d.Role
+		}
+		if d.Disabled != nil {
+			admin.Disabled = *d.Disabled
+		}
+
+		if admin, err = ar.adminStorage.UpdateAdmin(admin); err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Admin %s updated", adminID)
+		ar.audit(r, "admin.update", "admin", adminID, before, newAdminView(admin))
+		ar.ServeJSON(w, http.StatusOK, newAdminView(admin))
+	}
+}
+
+// DeleteAdmin deletes admin account.
+func (ar *Router) DeleteAdmin() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID := getRouteVar("id", r)
+		if ar.rejectOwnAccount(w, r, adminID) {
+			return
+		}
+
+		before, err := ar.adminStorage.AdminByID(adminID)
+		if err != nil {
+			if err == model.ErrAdminNotFound {
+				ar.Error(w, err, http.StatusNotFound, "")
+			} else {
+				ar.Error(w, err, http.StatusInternalServerError, "")
+			}
+			return
+		}
+
+		if err = ar.adminStorage.DeleteAdmin(adminID); err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Admin %s deleted", adminID)
+		ar.audit(r, "admin.delete", "admin", adminID, newAdminView(before), nil)
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+func (ar *Router) rejectOwnAccount(w http.ResponseWriter, r *http.Request, adminID string) bool {
+	if adminFromContext(r.Context()).ID != adminID {
+		return false
+	}
+	ar.Error(w, ErrorForbidden, http.StatusForbidden, "Admins cannot change their own accounts")
+	return true
+}
+
+// adminInviteLink returns link to the admin panel page where invited admin sets the password.
+func (ar *Router) adminInviteLink(email, token string) string {
+	adminPanelURL := os.Getenv("ADMIN_PANEL_URL")
+	if len(adminPanelURL) == 0 {
+		adminPanelURL = strings.TrimSuffix(ar.Host, "/") + "/adminpanel"
+	}
+
+	query := url.Values{}
+	query.Set("email", email)
+	query.Set("token", token)
+	return strings.TrimSuffix(adminPanelURL, "/") + "/accept-invite?" + query.Encode()
+}
+
+func randomAdminInviteToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -84,6 +84,8 @@ func (ar *Router) CreateApp() http.HandlerFunc {
 			ar.Error(w, err, http.StatusBadRequest, "")
 			return
 		}
+
+		ar.audit(r, "app.create", "app", app.ID(), nil, app)
 		ar.ServeJSON(w, http.StatusOK, app)
 	}
 }
@@ -109,6 +111,8 @@ func (ar *Router) UpdateApp() http.HandlerFunc {
 			return
 		}
 
+		before, _ := ar.appStorage.AppByID(appID)
+
 		app, err := ar.appStorage.UpdateApp(appID, ad)
 		if err != nil {
 			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
@@ -116,10 +120,12 @@ func (ar *Router) UpdateApp() http.HandlerFunc {
 		}
 
 		if err = ar.updateAllowedOrigins(); err != nil {
-			ar.logger.Printf("Error occurred during updating allowed origins for App %s, error: %v", appID, err)
+			ar.logger.WithRequest(r).Errorf("Error occurred during updating allowed origins for App %s, error: %v", appID, err)
 		}
+		ar.authorizer.InvalidateApp(appID)
 
-		ar.logger.Printf("App %s updated", appID)
+		ar.logger.WithRequest(r).Infof("App %s updated", appID)
+		ar.audit(r, "app.update", "app", appID, before, app)
 
 		ar.ServeJSON(w, http.StatusOK, app)
 	}
@@ -129,12 +135,36 @@ func (ar *Router) UpdateApp() http.HandlerFunc {
 func (ar *Router) DeleteApp() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		appID := getRouteVar("id", r)
+		before, _ := ar.appStorage.AppByID(appID)
+
 		if err := ar.appStorage.DeleteApp(appID); err != nil {
 			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
 			return
 		}
 
-		ar.logger.Printf("App %s deleted", appID)
+		// Policy rules and roles of the deleted app are not needed anymore.
+		rules, err := ar.policyStorage.FetchPolicyRules(appID)
+		if err != nil {
+			ar.logger.WithRequest(r).Errorf("Cannot fetch policy rules of deleted app %s: %s", appID, err)
+		}
+		for _, rule := range rules {
+			if err = ar.policyStorage.DeletePolicyRule(rule.ID); err != nil {
+				ar.logger.WithRequest(r).Errorf("Cannot delete policy rule %s of deleted app %s: %s", rule.ID, appID, err)
+			}
+		}
+		roles, err := ar.roleStorage.FetchRoles(appID)
+		if err != nil {
+			ar.logger.WithRequest(r).Errorf("Cannot fetch roles of deleted app %s: %s", appID, err)
+		}
+		for _, role := range roles {
+			if err = ar.roleStorage.DeleteRole(role.ID); err != nil {
+				ar.logger.WithRequest(r).Errorf("Cannot delete role %s of deleted app %s: %s", role.ID, appID, err)
+			}
+		}
+		ar.authorizer.InvalidateApp(appID)
+
+		ar.logger.WithRequest(r).Infof("App %s deleted", appID)
+		ar.audit(r, "app.delete", "app", appID, before, nil)
 
 		ar.ServeJSON(w, http.StatusOK, nil)
 	}
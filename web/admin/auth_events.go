@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/madappgang/identifo/model"
+)
+
+const (
+	defaultAuthEventsSkip  = 0
+	defaultAuthEventsLimit = 50
+	maxAuthEventsLimit     = 500
+)
+
+// FetchUserAuthEvents returns authentication events of the user, optionally filtered by type, app, result and time range.
+func (ar *Router) FetchUserAuthEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		skip, limit, err := ar.parseSkipAndLimit(r, defaultAuthEventsSkip, defaultAuthEventsLimit, maxAuthEventsLimit)
+		if err != nil {
+			ar.Error(w, ErrorWrongInput, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		filter := model.AuthEventFilter{
+			UserID: getRouteVar("id", r),
+			AppID:  strings.TrimSpace(q.Get("app_id")),
+			Type:   model.AuthEventType(strings.TrimSpace(q.Get("type"))),
+		}
+		if s := q.Get("success"); s != "" {
+			success, err := strconv.ParseBool(s)
+			if err != nil {
+				ar.Error(w, ErrorWrongInput, http.StatusBadRequest, "Success is expected as a boolean")
+				return
+			}
+			filter.Success = &success
+		}
+		for name, value := range map[string]*int64{"from": &filter.From, "to": &filter.To} {
+			if s := q.Get(name); s != "" {
+				if *value, err = strconv.ParseInt(s, 10, 64); err != nil {
+					ar.Error(w, ErrorWrongInput, http.StatusBadRequest, "Time range is expected as Unix timestamps")
+					return
+				}
+			}
+		}
+
+		events, total, err := ar.authEventStorage.FetchAuthEvents(filter, skip, limit)
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		searchResponse := struct {
+			Events []model.AuthEvent `json:"events"`
+			Total  int               `json:"total"`
+		}{
+			Events: events,
+			Total:  total,
+		}
+		ar.ServeJSON(w, http.StatusOK, &searchResponse)
+	}
+}
@@ -17,4 +17,20 @@ const (
 	ErrorIncorrectLogin = Error("Incorrect login information")
 	// ErrorNotAuthorized is for non-authorized access intents.
 	ErrorNotAuthorized = Error("Not authorized")
+	// ErrorForbidden is for actions not allowed for the admin role.
+	ErrorForbidden = Error("Forbidden")
+	// ErrorAdminDisabled is for login intents of disabled admins.
+	ErrorAdminDisabled = Error("Admin account is disabled")
+	// ErrorAdminInviteInvalid is for expired or wrong admin invites.
+	ErrorAdminInviteInvalid = Error("Invite is invalid or expired")
+	// ErrorTFACodeRequired is for login intents without TFA code when TFA is enabled.
+	ErrorTFACodeRequired = Error("TFA code required")
+	// ErrorTFACodeInvalid is for wrong TFA and recovery codes.
+	ErrorTFACodeInvalid = Error("Invalid TFA code")
+	// ErrorTFATooManyAttempts is for TFA checks of admins blocked after too many invalid codes.
+	ErrorTFATooManyAttempts = Error("Too many invalid TFA codes, try again later")
+	// ErrorTFAEnrolmentRequired is for admins without TFA when it is required.
+	ErrorTFAEnrolmentRequired = Error("TFA enrolment required")
+	// ErrorTFAAlreadyEnabled is for enrolment intents when TFA is already enabled.
+	ErrorTFAAlreadyEnabled = Error("TFA already enabled")
 )
@@ -0,0 +1,215 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	jwtService "github.com/madappgang/identifo/jwt/service"
+	"github.com/madappgang/identifo/model"
+)
+
+const (
+	defaultInviteSkip  = 0
+	defaultInviteLimit = 20
+	// maxBulkInvites limits the number of invites created by one request.
+	maxBulkInvites = 100
+)
+
+type inviteData struct {
+	AppID     string   `json:"app_id" validate:"required"`
+	Emails    []string `json:"emails" validate:"required,min=1"`
+	Role      string   `json:"role,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	Lifespan  int64    `json:"lifespan,omitempty"`
+	SendEmail bool     `json:"send_email,omitempty"`
+	// OrgID and OrgRole make invited users members of the organization.
+	OrgID   string `json:"org_id,omitempty"`
+	OrgRole string `json:"org_role,omitempty"`
+}
+
+func (d *inviteData) validate() error {
+	if len(d.Emails) > maxBulkInvites {
+		return fmt.Errorf("Too many emails %d, expected no more than %d", len(d.Emails), maxBulkInvites)
+	}
+	for i, email := range d.Emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if !model.EmailRegexp.MatchString(email) {
+			return fmt.Errorf("Invalid email %s", email)
+		}
+		d.Emails[i] = email
+	}
+	if d.Lifespan < 0 {
+		return fmt.Errorf("Invalid lifespan %d", d.Lifespan)
+	}
+	return nil
+}
+
+type createdInvite struct {
+	model.Invite
+	Link string `json:"link"`
+}
+
+// FetchInvites fetches invites, optionally filtered by app ID and email.
+func (ar *Router) FetchInvites() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		appID := strings.TrimSpace(r.URL.Query().Get("app_id"))
+		email := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("email")))
+
+		skip, limit, err := ar.parseSkipAndLimit(r, defaultInviteSkip, defaultInviteLimit, 0)
+		if err != nil {
+			ar.Error(w, ErrorWrongInput, http.StatusBadRequest, "")
+			return
+		}
+
+		invites, total, err := ar.inviteStorage.FetchInvites(appID, email, skip, limit)
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		searchResponse := struct {
+			Invites []model.Invite `json:"invites"`
+			Total   int            `json:"total"`
+		}{
+			Invites: invites,
+			Total:   total,
+		}
+
+		ar.ServeJSON(w, http.StatusOK, &searchResponse)
+	}
+}
+
+// CreateInvites creates invites for the list of emails and optionally sends them.
+func (ar *Router) CreateInvites() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := inviteData{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+
+		if err := d.validate(); err != nil {
+			ar.Error(w, err, http.StatusBadRequest, "")
+			return
+		}
+
+		app, err := ar.appStorage.AppByID(d.AppID)
+		if err != nil {
+			ar.Error(w, err, http.StatusBadRequest, "")
+			return
+		}
+
+		if d.Role == "" {
+			d.Role = app.NewUserDefaultRole()
+		}
+		if d.Scopes == nil {
+			d.Scopes = app.Scopes()
+		}
+		if d.Lifespan == 0 {
+			d.Lifespan = app.InviteTokenLifespan()
+		}
+		if d.Lifespan == 0 {
+			d.Lifespan = jwtService.InviteTokenLifespan
+		}
+
+		if d.OrgID != "" {
+			if _, err = ar.organizationStorage.OrganizationByID(d.OrgID); err != nil {
+				ar.Error(w, err, http.StatusBadRequest, "")
+				return
+			}
+			if d.OrgRole == "" {
+				d.OrgRole = model.OrganizationRoleMember
+			}
+		}
+
+		now := time.Now().Unix()
+		invites := make([]createdInvite, 0, len(d.Emails))
+
+		for _, email := range d.Emails {
+			invite, err := ar.inviteStorage.AddInvite(model.Invite{
+				AppID:     app.ID(),
+				Email:     email,
+				Role:      d.Role,
+				Scopes:    d.Scopes,
+				InvitedBy: adminFromContext(r.Context()).ID,
+				ByAdmin:   true,
+				CreatedAt: now,
+				ExpiresAt: now + d.Lifespan,
+				OrgID:     d.OrgID,
+				OrgRole:   d.OrgRole,
+			})
+			if err != nil {
+				ar.Error(w, err, http.StatusInternalServerError, "Creating invite")
+				return
+			}
+
+			link, err := ar.inviteLink(invite)
+			if err != nil {
+				ar.Error(w, err, http.StatusInternalServerError, "Creating invite link")
+				return
+			}
+
+			if d.SendEmail {
+				if err = ar.emailService.SendInviteEmail("Invitation", invite.Email, link); err != nil {
+					ar.Error(w, err, http.StatusInternalServerError, "Sending invite email")
+					return
+				}
+			}
+			ar.audit(r, "invite.create", "invite", invite.ID, nil, invite)
+			invites = append(invites, createdInvite{Invite: invite, Link: link})
+		}
+
+		ar.ServeJSON(w, http.StatusOK, invites)
+	}
+}
+
+// RevokeInvite revokes invite, so it cannot be used anymore.
+func (ar *Router) RevokeInvite() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		inviteID := getRouteVar("id", r)
+
+		if err := ar.inviteStorage.RevokeInvite(inviteID); err != nil {
+			if err == model.ErrInviteNotFound {
+				ar.Error(w, err, http.StatusNotFound, "")
+			} else {
+				ar.Error(w, err, http.StatusInternalServerError, "")
+			}
+			return
+		}
+
+		ar.audit(r, "invite.revoke", "invite", inviteID, nil, nil)
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// inviteLink returns link to the registration page with the invite token.
+func (ar *Router) inviteLink(invite model.Invite) (string, error) {
+	token, err := ar.tokenService.NewInviteToken(invite)
+	if err != nil {
+		return "", err
+	}
+
+	tokenString, err := ar.tokenService.String(token)
+	if err != nil {
+		return "", err
+	}
+
+	host, err := url.Parse(ar.Host)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := strings.Replace(fmt.Sprintf("%q", invite.Scopes), " ", ",", -1)
+	query := url.PathEscape(fmt.Sprintf("appId=%s&scopes=%s&token=%s", invite.AppID, scopes, tokenString))
+
+	u := &url.URL{
+		Scheme:   host.Scheme,
+		Host:     host.Host,
+		Path:     path.Join("/web", "register"),
+		RawQuery: query,
+	}
+	return u.String(), nil
+}
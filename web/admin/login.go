@@ -1,10 +1,19 @@
 package admin
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/madappgang/identifo/model"
 )
 
+// insecureAdminPassword is the former default admin password, which is never accepted for the owner account.
+const insecureAdminPassword = "password"
+
 type adminLoginData struct {
 	Login           string `json:"email"`
 	LoginEnvName    string `json:"email_env_name"`
@@ -12,29 +21,52 @@ type adminLoginData struct {
 	PasswordEnvName string `json:"password_env_name"`
 }
 
-// Login logins admin with admin name and password.
+type loginData struct {
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	TFACode      string `json:"tfa_code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// Login logins admin with admin email and password.
+// Admins with two-factor authentication also provide TOTP code or one of the recovery codes.
 func (ar *Router) Login() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		conf := new(adminLoginData)
-		if ar.getAdminAccountSettings(w, conf) != nil {
+		ld := loginData{}
+		if ar.mustParseJSON(w, r, &ld) != nil {
 			return
 		}
+		ld.Email = strings.ToLower(strings.TrimSpace(ld.Email))
 
-		ld := adminLoginData{}
-		if ar.mustParseJSON(w, r, &ld) != nil {
+		admin, err := ar.adminStorage.AdminByEmail(ld.Email)
+		if err == model.ErrAdminNotFound {
+			admin, err = ar.createOwnerFromEnv(w, r, ld)
+			if err != nil {
+				return
+			}
+		} else if err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
 			return
 		}
 
-		if (conf.Login != ld.Login) || (conf.Password != ld.Password) {
+		if !admin.CheckPassword(ld.Password) {
 			ar.Error(w, ErrorIncorrectLogin, http.StatusBadRequest, "")
 			return
 		}
+		if admin.Disabled {
+			ar.Error(w, ErrorAdminDisabled, http.StatusForbidden, "")
+			return
+		}
+		if admin.TFAEnabled && !ar.checkLoginTFA(w, r, &admin, ld) {
+			return
+		}
 
 		session, err := ar.sessionService.NewSession()
 		if err != nil {
 			ar.Error(w, fmt.Errorf("Cannot create session: %s", err), http.StatusInternalServerError, "")
 			return
 		}
+		session.AdminID = admin.ID
 
 		if err = ar.sessionStorage.InsertSession(session); err != nil {
 			ar.Error(w, fmt.Errorf("Cannot insert session: %s", err), http.StatusInternalServerError, "")
@@ -49,6 +81,71 @@ func (ar *Router) Login() http.HandlerFunc {
 			HttpOnly: true,
 		}
 		http.SetCookie(w, c)
-		ar.ServeJSON(w, http.StatusOK, nil)
+
+		ar.auditAs(r, admin, session.ID, "admin.login", "admin", admin.ID, nil, nil)
+		ar.ServeJSON(w, http.StatusOK, newAdminView(admin))
+	}
+}
+
+// createOwnerFromEnv creates the first owner account on login with admin credentials from the environment.
+// Both credentials must be set, and the password must not be the former default one.
+// Once any admin account exists, environment credentials are not accepted anymore.
+func (ar *Router) createOwnerFromEnv(w http.ResponseWriter, r *http.Request, ld loginData) (model.Admin, error) {
+	admins, err := ar.adminStorage.FetchAdmins()
+	if err != nil {
+		ar.Error(w, err, http.StatusInternalServerError, "")
+		return model.Admin{}, err
+	}
+	if len(admins) > 0 {
+		ar.Error(w, ErrorIncorrectLogin, http.StatusBadRequest, "")
+		return model.Admin{}, ErrorIncorrectLogin
+	}
+
+	settings := ar.ServerSettings.AdminAccount
+	login := os.Getenv(settings.LoginEnvName)
+	password := os.Getenv(settings.PasswordEnvName)
+	if len(login) == 0 || len(password) == 0 || password == insecureAdminPassword {
+		ar.logger.WithRequest(r).Warnf("Owner account cannot be created: %s and %s must be set, and the password must not be %q", settings.LoginEnvName, settings.PasswordEnvName, insecureAdminPassword)
+		ar.Error(w, ErrorIncorrectLogin, http.StatusBadRequest, "")
+		return model.Admin{}, ErrorIncorrectLogin
+	}
+	if !strings.EqualFold(login, ld.Email) || subtle.ConstantTimeCompare([]byte(password), []byte(ld.Password)) != 1 {
+		ar.Error(w, ErrorIncorrectLogin, http.StatusBadRequest, "")
+		return model.Admin{}, ErrorIncorrectLogin
+	}
+
+	owner := model.Admin{
+		Email:     ld.Email,
+		Role:      model.AdminRoleOwner,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err = owner.SetPassword(ld.Password); err != nil {
+		ar.Error(w, err, http.StatusInternalServerError, "")
+		return model.Admin{}, err
+	}
+
+	if owner, err = ar.adminStorage.AddAdmin(owner); err != nil {
+		ar.Error(w, err, http.StatusInternalServerError, "Creating owner account")
+		return model.Admin{}, err
+	}
+
+	ar.logger.WithRequest(r).Infof("Owner admin %s created from environment credentials", owner.ID)
+	ar.auditAs(r, owner, "", "admin.create_owner", "admin", owner.ID, nil, newAdminView(owner))
+	return owner, nil
+}
+
+// checkLoginTFA checks TOTP or recovery code provided on login.
+// Used recovery code is removed from the admin account.
+func (ar *Router) checkLoginTFA(w http.ResponseWriter, r *http.Request, admin *model.Admin, ld loginData) bool {
+	if ld.TFACode == "" && ld.RecoveryCode == "" {
+		ar.Error(w, ErrorTFACodeRequired, http.StatusUnauthorized, "")
+		return false
+	}
+	if !ar.checkTFA(w, admin, ld.TFACode, ld.RecoveryCode, http.StatusUnauthorized) {
+		return false
+	}
+	if ld.TFACode == "" {
+		ar.logger.WithRequest(r).Infof("Admin %s logged in with recovery code, %d codes left", admin.ID, len(admin.RecoveryCodeHashes))
 	}
+	return true
 }
@@ -0,0 +1,197 @@
+package admin
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	memSessions "github.com/madappgang/identifo/sessions/mem"
+	"github.com/madappgang/identifo/storage/mem"
+)
+
+const (
+	testLoginEnvName    = "IDENTIFO_TEST_ADMIN_LOGIN"
+	testPasswordEnvName = "IDENTIFO_TEST_ADMIN_PASSWORD"
+)
+
+func newTestRouter(t *testing.T) *Router {
+	adminStorage, _ := mem.NewAdminStorage()
+	auditStorage, _ := mem.NewAuditStorage()
+	sessionStorage, _ := memSessions.NewSessionStorage()
+	settings := &model.ServerSettings{
+		AdminAccount: model.AdminAccountSettings{LoginEnvName: testLoginEnvName, PasswordEnvName: testPasswordEnvName},
+	}
+	return &Router{
+		logger:         logging.New(ioutil.Discard, logging.LevelError, 0),
+		adminStorage:   adminStorage,
+		auditStorage:   auditStorage,
+		sessionStorage: sessionStorage,
+		sessionService: model.NewSessionManager(model.SessionDuration{Duration: time.Hour}, sessionStorage),
+		ServerSettings: settings,
+		newSettings:    settings,
+	}
+}
+
+func setAdminEnv(t *testing.T, login, password string) {
+	os.Setenv(testLoginEnvName, login)
+	os.Setenv(testPasswordEnvName, password)
+	t.Cleanup(func() {
+		os.Unsetenv(testLoginEnvName)
+		os.Unsetenv(testPasswordEnvName)
+	})
+}
+
+func login(ar *Router, email, password string) *httptest.ResponseRecorder {
+	body := `{"email":"` + email + `","password":"` + password + `"}`
+	rec := httptest.NewRecorder()
+	ar.Login()(rec, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body)))
+	return rec
+}
+
+func TestLoginCreatesOwnerFromEnv(t *testing.T) {
+	tests := []struct {
+		name           string
+		login          string
+		password       string
+		loginEmail     string
+		loginPassword  string
+		expectedStatus int
+	}{
+		{"credentials not set", "", "", "admin@admin.com", "password", http.StatusBadRequest},
+		{"password not set", "owner@example.com", "", "owner@example.com", "", http.StatusBadRequest},
+		{"former default password", "owner@example.com", "password", "owner@example.com", "password", http.StatusBadRequest},
+		{"wrong password", "owner@example.com", "s3cret-pass", "owner@example.com", "other-pass", http.StatusBadRequest},
+		{"valid credentials", "Owner@example.com", "s3cret-pass", "owner@example.com", "s3cret-pass", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar := newTestRouter(t)
+			setAdminEnv(t, tt.login, tt.password)
+
+			rec := login(ar, tt.loginEmail, tt.loginPassword)
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Login status = %d, expected %d: %s", rec.Code, tt.expectedStatus, rec.Body.String())
+			}
+
+			admins, _ := ar.adminStorage.FetchAdmins()
+			if tt.expectedStatus != http.StatusOK {
+				if len(admins) != 0 {
+					t.Errorf("Admin created on failed login: %+v", admins)
+				}
+				return
+			}
+			if len(admins) != 1 || admins[0].Role != model.AdminRoleOwner || admins[0].Email != tt.loginEmail {
+				t.Errorf("Owner is not created: %+v", admins)
+			}
+		})
+	}
+}
+
+func TestLoginIgnoresEnvOnceAdminExists(t *testing.T) {
+	ar := newTestRouter(t)
+	setAdminEnv(t, "owner@example.com", "s3cret-pass")
+
+	if rec := login(ar, "owner@example.com", "s3cret-pass"); rec.Code != http.StatusOK {
+		t.Fatalf("First login status = %d, expected %d", rec.Code, http.StatusOK)
+	}
+
+	setAdminEnv(t, "another@example.com", "an0ther-pass")
+	if rec := login(ar, "another@example.com", "an0ther-pass"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Login with new environment credentials status = %d, expected %d", rec.Code, http.StatusBadRequest)
+	}
+	if admins, _ := ar.adminStorage.FetchAdmins(); len(admins) != 1 {
+		t.Errorf("Admins = %d, expected only the owner", len(admins))
+	}
+}
+
+func addSession(t *testing.T, ar *Router, admin model.Admin, expiresIn time.Duration) *http.Cookie {
+	session, err := ar.sessionService.NewSession()
+	if err != nil {
+		t.Fatalf("Error creating session: %s", err)
+	}
+	session.AdminID = admin.ID
+	session.ExpirationTime = time.Now().Add(expiresIn).Unix()
+	if err = ar.sessionStorage.InsertSession(session); err != nil {
+		t.Fatalf("Error inserting session: %s", err)
+	}
+	return &http.Cookie{Name: cookieName, Value: encode(session.ID)}
+}
+
+func TestSession(t *testing.T) {
+	ar := newTestRouter(t)
+	active, _ := ar.adminStorage.AddAdmin(model.Admin{Email: "active@example.com", Role: model.AdminRoleReadOnly})
+	disabled, _ := ar.adminStorage.AddAdmin(model.Admin{Email: "disabled@example.com", Role: model.AdminRoleOwner, Disabled: true})
+
+	tests := []struct {
+		name           string
+		cookie         *http.Cookie
+		expectedStatus int
+	}{
+		{"no cookie", nil, http.StatusUnauthorized},
+		{"unknown session", &http.Cookie{Name: cookieName, Value: encode("unknown")}, http.StatusUnauthorized},
+		{"expired session", addSession(t, ar, active, -time.Minute), http.StatusUnauthorized},
+		{"disabled admin", addSession(t, ar, disabled, time.Hour), http.StatusUnauthorized},
+		{"valid session", addSession(t, ar, active, time.Hour), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var loaded model.Admin
+			next := func(w http.ResponseWriter, r *http.Request) {
+				loaded = adminFromContext(r.Context())
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/apps", nil)
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			rec := httptest.NewRecorder()
+			ar.Session()(rec, req, next)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Status = %d, expected %d", rec.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus == http.StatusOK && loaded.ID != active.ID {
+				t.Errorf("Admin in context = %q, expected %q", loaded.ID, active.ID)
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	ar := newTestRouter(t)
+	tests := []struct {
+		role    model.AdminRole
+		allowed bool
+	}{
+		{model.AdminRoleOwner, true},
+		{model.AdminRoleAppManager, true},
+		{model.AdminRoleUserSupport, false},
+		{model.AdminRoleReadOnly, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			called := false
+			next := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+			req := httptest.NewRequest(http.MethodPost, "/apps", nil)
+			req = req.WithContext(context.WithValue(req.Context(), model.AdminContextKey, model.Admin{Role: tt.role}))
+			rec := httptest.NewRecorder()
+			ar.RequireRole(model.AdminRoleAppManager)(rec, req, next)
+
+			if called != tt.allowed {
+				t.Errorf("Allowed = %v, expected %v", called, tt.allowed)
+			}
+			if !tt.allowed && rec.Code != http.StatusForbidden {
+				t.Errorf("Status = %d, expected %d", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
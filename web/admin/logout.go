@@ -20,7 +20,7 @@ func (ar *Router) Logout() http.HandlerFunc {
 		if err != nil {
 			switch err {
 			case http.ErrNoCookie:
-				ar.logger.Println("No cookie")
+				ar.logger.WithRequest(r).Error("No cookie")
 				ar.ServeJSON(w, http.StatusOK, nil)
 			default:
 				ar.Error(w, err, http.StatusInternalServerError, "")
@@ -33,6 +33,13 @@ func (ar *Router) Logout() http.HandlerFunc {
 			ar.Error(w, ErrorRequestInvalidCookie, http.StatusBadRequest, "")
 			return
 		}
+		session, err := ar.sessionStorage.GetSession(sessionID)
+		if err == nil && session.AdminID != "" {
+			if admin, err := ar.adminStorage.AdminByID(session.AdminID); err == nil {
+				ar.auditAs(r, admin, sessionID, "admin.logout", "admin", admin.ID, nil, nil)
+			}
+		}
+
 		if err := ar.sessionStorage.DeleteSession(sessionID); err != nil {
 			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
 			return
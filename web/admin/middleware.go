@@ -1,62 +1,113 @@
 package admin
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/madappgang/identifo/model"
 	"github.com/urfave/negroni"
 )
 
 // Session is a middleware to check if admin is logged in with valid cookie.
-// If all checks succeeded, prolongs existing session.
+// If all checks succeeded, prolongs existing session and puts admin account to the request context.
 // If not, forces to login.
+// When TFA is required, admins without it are forced to enrol first.
 func (ar *Router) Session() negroni.HandlerFunc {
+	return ar.session(false)
+}
+
+// TFAEnrolmentSession is a Session middleware for TFA enrolment routes,
+// which are allowed for admins without TFA even if TFA is required.
+func (ar *Router) TFAEnrolmentSession() negroni.HandlerFunc {
+	return ar.session(true)
+}
+
+func (ar *Router) session(tfaEnrolment bool) negroni.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-		if ar.isLoggedIn(w, r) {
+		if admin, ok := ar.isLoggedIn(w, r); ok {
+			if !tfaEnrolment && ar.ServerSettings.AdminAccount.RequireTFA && !admin.TFAEnabled {
+				ar.Error(w, ErrorTFAEnrolmentRequired, http.StatusForbidden, "")
+				return
+			}
+
 			sessionID, err := ar.getSessionID(r)
 			if err != nil {
 				ar.Error(w, ErrorNotAuthorized, http.StatusUnauthorized, err.Error())
 				return
 			}
 			ar.prolongSession(w, sessionID)
+
+			ctx := context.WithValue(r.Context(), model.AdminContextKey, admin)
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// RequireRole is a middleware to check if logged in admin has one of the roles.
+// Owner is allowed to do everything. Must be used after Session middleware.
+func (ar *Router) RequireRole(roles ...model.AdminRole) negroni.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		admin := adminFromContext(r.Context())
+		if admin.Role == model.AdminRoleOwner {
 			next(w, r)
+			return
+		}
+		for _, role := range roles {
+			if admin.Role == role {
+				next(w, r)
+				return
+			}
 		}
+		ar.Error(w, ErrorForbidden, http.StatusForbidden, fmt.Sprintf("Not allowed for %s role", admin.Role))
 	}
 }
 
-// IsLoggedIn checks if admin is logged in.
+// IsLoggedIn checks if admin is logged in and returns admin account.
 func (ar *Router) IsLoggedIn() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if ar.isLoggedIn(w, r) {
-			ar.ServeJSON(w, http.StatusOK, nil)
+		if admin, ok := ar.isLoggedIn(w, r); ok {
+			ar.ServeJSON(w, http.StatusOK, newAdminView(admin))
 		}
 	}
 }
 
-func (ar *Router) isLoggedIn(w http.ResponseWriter, r *http.Request) bool {
+func (ar *Router) isLoggedIn(w http.ResponseWriter, r *http.Request) (model.Admin, bool) {
 	sessionID, err := ar.getSessionID(r)
 	if err != nil {
 		ar.Error(w, ErrorNotAuthorized, http.StatusUnauthorized, err.Error())
-		return false
+		return model.Admin{}, false
 	}
 
 	session, err := ar.sessionStorage.GetSession(sessionID)
 	if err != nil {
 		ar.Error(w, err, http.StatusUnauthorized, err.Error())
-		return false
+		return model.Admin{}, false
 	}
 
 	if time.Unix(session.ExpirationTime, 0).Before(time.Now()) {
 		ar.Error(w, ErrorNotAuthorized, http.StatusUnauthorized, "")
-		return false
+		return model.Admin{}, false
 	}
 
-	return true
+	// Admin is loaded on every request, so disabled and deleted admins lose access immediately.
+	admin, err := ar.adminStorage.AdminByID(session.AdminID)
+	if err != nil {
+		ar.Error(w, ErrorNotAuthorized, http.StatusUnauthorized, err.Error())
+		return model.Admin{}, false
+	}
+	if admin.Disabled {
+		ar.Error(w, ErrorAdminDisabled, http.StatusUnauthorized, "")
+		return model.Admin{}, false
+	}
+
+	return admin, true
 }
 
 func (ar *Router) prolongSession(w http.ResponseWriter, sessionID string) {
 	if err := ar.sessionService.ProlongSession(sessionID); err != nil {
-		ar.logger.Println("Error prolonging session:", err)
+		ar.logger.Error("Error prolonging session:", err)
 		return
 	}
 	c := &http.Cookie{
@@ -78,3 +129,8 @@ func (ar *Router) getSessionID(r *http.Request) (string, error) {
 	sessionID, err := decode(cookie.Value)
 	return sessionID, err
 }
+
+func adminFromContext(ctx context.Context) model.Admin {
+	admin, _ := ctx.Value(model.AdminContextKey).(model.Admin)
+	return admin
+}
@@ -0,0 +1,269 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+)
+
+const (
+	defaultOrganizationSkip  = 0
+	defaultOrganizationLimit = 20
+)
+
+type organizationData struct {
+	Name string `json:"name"`
+	// OwnerID is the user who becomes the owner of the new organization.
+	OwnerID string `json:"owner_id,omitempty"`
+}
+
+func (d *organizationData) validate() error {
+	if d.Name = strings.TrimSpace(d.Name); d.Name == "" {
+		return fmt.Errorf("Organization name is empty")
+	}
+	return nil
+}
+
+// FetchOrganizations fetches organizations, optionally filtered by name.
+func (ar *Router) FetchOrganizations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		search := strings.TrimSpace(r.URL.Query().Get("search"))
+
+		skip, limit, err := ar.parseSkipAndLimit(r, defaultOrganizationSkip, defaultOrganizationLimit, 0)
+		if err != nil {
+			ar.Error(w, ErrorWrongInput, http.StatusBadRequest, "")
+			return
+		}
+
+		orgs, total, err := ar.organizationStorage.FetchOrganizations(search, skip, limit)
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		searchResponse := struct {
+			Organizations []model.Organization `json:"organizations"`
+			Total         int                  `json:"total"`
+		}{
+			Organizations: orgs,
+			Total:         total,
+		}
+
+		ar.ServeJSON(w, http.StatusOK, &searchResponse)
+	}
+}
+
+// CreateOrganization adds new organization, optionally with the owner.
+func (ar *Router) CreateOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := organizationData{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+		if err := d.validate(); err != nil {
+			ar.Error(w, err, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if d.OwnerID != "" {
+			if _, err := ar.userStorage.UserByID(d.OwnerID); err != nil {
+				ar.Error(w, err, http.StatusBadRequest, "Owner not found")
+				return
+			}
+		}
+
+		now := time.Now().Unix()
+		org, err := ar.organizationStorage.AddOrganization(model.Organization{Name: d.Name, CreatedAt: now})
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+		ar.logger.WithRequest(r).Infof("Organization %s created", org.ID)
+		ar.audit(r, "organization.create", "organization", org.ID, nil, org)
+
+		if d.OwnerID != "" {
+			member := model.OrganizationMember{OrgID: org.ID, UserID: d.OwnerID, Role: model.OrganizationRoleOwner, JoinedAt: now}
+			if member, err = ar.organizationStorage.SetMember(member); err != nil {
+				ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+				return
+			}
+			ar.audit(r, "organization.member.set", "organization", org.ID, nil, member)
+		}
+
+		ar.ServeJSON(w, http.StatusOK, org)
+	}
+}
+
+// GetOrganization returns organization by ID.
+func (ar *Router) GetOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		org, err := ar.organizationStorage.OrganizationByID(getRouteVar("id", r))
+		if err == model.ErrOrganizationNotFound {
+			ar.Error(w, err, http.StatusNotFound, "")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, org)
+	}
+}
+
+// UpdateOrganization renames the organization.
+func (ar *Router) UpdateOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		before, err := ar.organizationStorage.OrganizationByID(getRouteVar("id", r))
+		if err == model.ErrOrganizationNotFound {
+			ar.Error(w, err, http.StatusNotFound, "")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		d := organizationData{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+		if err = d.validate(); err != nil {
+			ar.Error(w, err, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		org := before
+		org.Name = d.Name
+		if org, err = ar.organizationStorage.UpdateOrganization(org); err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Organization %s updated", org.ID)
+		ar.audit(r, "organization.update", "organization", org.ID, before, org)
+		ar.ServeJSON(w, http.StatusOK, org)
+	}
+}
+
+// DeleteOrganization deletes the organization with all its memberships.
+func (ar *Router) DeleteOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orgID := getRouteVar("id", r)
+		before, _ := ar.organizationStorage.OrganizationByID(orgID)
+
+		err := ar.organizationStorage.DeleteOrganization(orgID)
+		if err == model.ErrOrganizationNotFound {
+			ar.Error(w, err, http.StatusNotFound, "")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Organization %s deleted", orgID)
+		ar.audit(r, "organization.delete", "organization", orgID, before, nil)
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// FetchOrganizationMembers returns members of the organization.
+func (ar *Router) FetchOrganizationMembers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orgID := getRouteVar("id", r)
+		if _, err := ar.organizationStorage.OrganizationByID(orgID); err != nil {
+			if err == model.ErrOrganizationNotFound {
+				ar.Error(w, err, http.StatusNotFound, "")
+			} else {
+				ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			}
+			return
+		}
+
+		members, err := ar.organizationStorage.FetchMembers(orgID)
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, members)
+	}
+}
+
+// SetOrganizationMember adds the user to the organization or changes the role of the member.
+func (ar *Router) SetOrganizationMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := struct {
+			Role string `json:"role"`
+		}{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+		if d.Role = strings.TrimSpace(d.Role); d.Role == "" {
+			d.Role = model.OrganizationRoleMember
+		}
+
+		orgID, userID := getRouteVar("id", r), getRouteVar("user_id", r)
+		if _, err := ar.userStorage.UserByID(userID); err != nil {
+			if err == model.ErrUserNotFound {
+				ar.Error(w, err, http.StatusNotFound, "")
+			} else {
+				ar.Error(w, err, http.StatusInternalServerError, "")
+			}
+			return
+		}
+
+		before, err := ar.organizationStorage.Member(orgID, userID)
+		if err != nil && err != model.ErrOrganizationMemberNotFound {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		member, err := ar.organizationStorage.SetMember(model.OrganizationMember{
+			OrgID:    orgID,
+			UserID:   userID,
+			Role:     d.Role,
+			JoinedAt: time.Now().Unix(),
+		})
+		if err == model.ErrOrganizationNotFound {
+			ar.Error(w, err, http.StatusNotFound, "")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("User %s is %s of organization %s", userID, member.Role, orgID)
+		if before.UserID == "" {
+			ar.audit(r, "organization.member.set", "organization", orgID, nil, member)
+		} else {
+			ar.audit(r, "organization.member.set", "organization", orgID, before, member)
+		}
+		ar.ServeJSON(w, http.StatusOK, member)
+	}
+}
+
+// RemoveOrganizationMember removes the user from the organization.
+func (ar *Router) RemoveOrganizationMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orgID, userID := getRouteVar("id", r), getRouteVar("user_id", r)
+		before, _ := ar.organizationStorage.Member(orgID, userID)
+
+		err := ar.organizationStorage.RemoveMember(orgID, userID)
+		if err == model.ErrOrganizationMemberNotFound {
+			ar.Error(w, err, http.StatusNotFound, "")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("User %s removed from organization %s", userID, orgID)
+		ar.audit(r, "organization.member.remove", "organization", orgID, before, nil)
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
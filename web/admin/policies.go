@@ -0,0 +1,146 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/authorization"
+)
+
+// FetchPolicyRules returns all policy rules of the app.
+func (ar *Router) FetchPolicyRules() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app, err := ar.appForPolicy(w, r)
+		if err != nil {
+			return
+		}
+
+		rules, err := ar.policyStorage.FetchPolicyRules(app.ID())
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, rules)
+	}
+}
+
+// CreatePolicyRule adds new policy rule to the app.
+func (ar *Router) CreatePolicyRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app, err := ar.appForPolicy(w, r)
+		if err != nil {
+			return
+		}
+
+		rule := model.PolicyRule{}
+		if ar.mustParseJSON(w, r, &rule) != nil {
+			return
+		}
+		rule.AppID = app.ID()
+		if err = authorization.ValidatePolicyRule(app, rule); err != nil {
+			ar.Error(w, err, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		rule, err = ar.policyStorage.AddPolicyRule(rule)
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+			return
+		}
+		ar.authorizer.InvalidateApp(app.ID())
+
+		ar.logger.WithRequest(r).Infof("Policy rule %s added to app %s", rule.ID, app.ID())
+		ar.audit(r, "policy.create", "policy", rule.ID, nil, rule)
+		ar.ServeJSON(w, http.StatusOK, rule)
+	}
+}
+
+// UpdatePolicyRule changes the policy rule of the app.
+func (ar *Router) UpdatePolicyRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app, err := ar.appForPolicy(w, r)
+		if err != nil {
+			return
+		}
+		before, err := ar.policyRule(w, r, app.ID())
+		if err != nil {
+			return
+		}
+
+		rule := model.PolicyRule{}
+		if ar.mustParseJSON(w, r, &rule) != nil {
+			return
+		}
+		rule.ID, rule.AppID = before.ID, before.AppID
+		if err = authorization.ValidatePolicyRule(app, rule); err != nil {
+			ar.Error(w, err, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		rule, err = ar.policyStorage.UpdatePolicyRule(rule)
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+			return
+		}
+		ar.authorizer.InvalidateApp(app.ID())
+
+		ar.logger.WithRequest(r).Infof("Policy rule %s of app %s updated", rule.ID, app.ID())
+		ar.audit(r, "policy.update", "policy", rule.ID, before, rule)
+		ar.ServeJSON(w, http.StatusOK, rule)
+	}
+}
+
+// DeletePolicyRule deletes the policy rule of the app.
+func (ar *Router) DeletePolicyRule() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app, err := ar.appForPolicy(w, r)
+		if err != nil {
+			return
+		}
+		before, err := ar.policyRule(w, r, app.ID())
+		if err != nil {
+			return
+		}
+
+		if err = ar.policyStorage.DeletePolicyRule(before.ID); err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+			return
+		}
+		ar.authorizer.InvalidateApp(app.ID())
+
+		ar.logger.WithRequest(r).Infof("Policy rule %s of app %s deleted", before.ID, app.ID())
+		ar.audit(r, "policy.delete", "policy", before.ID, before, nil)
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// appForPolicy fetches the app from route variable, writing error response on failure.
+func (ar *Router) appForPolicy(w http.ResponseWriter, r *http.Request) (model.AppData, error) {
+	app, err := ar.appStorage.AppByID(getRouteVar("id", r))
+	if err == model.ErrorNotFound {
+		ar.Error(w, err, http.StatusNotFound, "")
+		return nil, err
+	}
+	if err != nil {
+		ar.Error(w, err, http.StatusInternalServerError, "")
+		return nil, err
+	}
+	return app, nil
+}
+
+// policyRule fetches the app policy rule from route variable, writing error response on failure.
+func (ar *Router) policyRule(w http.ResponseWriter, r *http.Request, appID string) (model.PolicyRule, error) {
+	rule, err := ar.policyStorage.PolicyRuleByID(getRouteVar("rule_id", r))
+	if err == nil && rule.AppID != appID {
+		err = model.ErrPolicyRuleNotFound
+	}
+	if err == model.ErrPolicyRuleNotFound {
+		ar.Error(w, err, http.StatusNotFound, "")
+		return rule, err
+	}
+	if err != nil {
+		ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+		return rule, err
+	}
+	return rule, nil
+}
@@ -0,0 +1,272 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/madappgang/identifo/model"
+)
+
+// userRolesData is the roles of the user in the app.
+type userRolesData struct {
+	// Roles are assigned to the user explicitly.
+	Roles []string `json:"roles"`
+	// EffectiveRoles include the access role and the inherited roles.
+	EffectiveRoles []string `json:"effective_roles,omitempty"`
+	Permissions    []string `json:"permissions,omitempty"`
+}
+
+// FetchRoles returns all roles of the app.
+func (ar *Router) FetchRoles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app, err := ar.appForPolicy(w, r)
+		if err != nil {
+			return
+		}
+
+		roles, err := ar.roleStorage.FetchRoles(app.ID())
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, roles)
+	}
+}
+
+// CreateRole adds new role to the app.
+func (ar *Router) CreateRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app, err := ar.appForPolicy(w, r)
+		if err != nil {
+			return
+		}
+
+		role := model.Role{}
+		if ar.mustParseJSON(w, r, &role) != nil {
+			return
+		}
+		role.ID, role.AppID = "", app.ID()
+		if ar.validateRole(w, role) != nil {
+			return
+		}
+
+		role, err = ar.roleStorage.AddRole(role)
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Role %s added to app %s", role.Name, app.ID())
+		ar.audit(r, "role.create", "role", role.ID, nil, role)
+		ar.ServeJSON(w, http.StatusOK, role)
+	}
+}
+
+// UpdateRole changes description, inherited roles and permissions of the role. Role name cannot be changed.
+func (ar *Router) UpdateRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app, err := ar.appForPolicy(w, r)
+		if err != nil {
+			return
+		}
+		before, err := ar.role(w, r, app.ID())
+		if err != nil {
+			return
+		}
+
+		role := model.Role{}
+		if ar.mustParseJSON(w, r, &role) != nil {
+			return
+		}
+		if role.Name != "" && role.Name != before.Name {
+			ar.Error(w, ErrorWrongInput, http.StatusBadRequest, "Role name cannot be changed")
+			return
+		}
+		role.ID, role.AppID, role.Name = before.ID, before.AppID, before.Name
+		if ar.validateRole(w, role) != nil {
+			return
+		}
+
+		role, err = ar.roleStorage.UpdateRole(role)
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Role %s of app %s updated", role.Name, app.ID())
+		ar.audit(r, "role.update", "role", role.ID, before, role)
+		ar.ServeJSON(w, http.StatusOK, role)
+	}
+}
+
+// DeleteRole deletes the role of the app and unassigns it from the users.
+// Role inherited by other roles cannot be deleted.
+func (ar *Router) DeleteRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app, err := ar.appForPolicy(w, r)
+		if err != nil {
+			return
+		}
+		before, err := ar.role(w, r, app.ID())
+		if err != nil {
+			return
+		}
+
+		roles, err := ar.roleStorage.FetchRoles(app.ID())
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, role := range roles {
+			if containsRole(role.Inherits, before.Name) {
+				ar.Error(w, ErrorWrongInput, http.StatusConflict, fmt.Sprintf("Role is inherited by role %s", role.Name))
+				return
+			}
+		}
+
+		if err = ar.roleStorage.DeleteRole(before.ID); err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Role %s of app %s deleted", before.Name, app.ID())
+		ar.audit(r, "role.delete", "role", before.ID, before, nil)
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// GetUserRoles returns the roles assigned to the user in the app, with the effective roles and permissions.
+func (ar *Router) GetUserRoles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app, err := ar.appForPolicy(w, r)
+		if err != nil {
+			return
+		}
+		user, err := ar.userForRoles(w, r)
+		if err != nil {
+			return
+		}
+		ar.serveUserRoles(w, app, user)
+	}
+}
+
+// SetUserRoles replaces the roles assigned to the user in the app.
+func (ar *Router) SetUserRoles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app, err := ar.appForPolicy(w, r)
+		if err != nil {
+			return
+		}
+		user, err := ar.userForRoles(w, r)
+		if err != nil {
+			return
+		}
+
+		d := userRolesData{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+
+		catalogue, err := ar.roleStorage.FetchRoles(app.ID())
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+			return
+		}
+		known := make(map[string]bool, len(catalogue))
+		for _, role := range catalogue {
+			known[role.Name] = true
+		}
+		roles := []string{}
+		for _, name := range d.Roles {
+			if !known[name] {
+				ar.Error(w, ErrorWrongInput, http.StatusBadRequest, fmt.Sprintf("Role %s does not exist", name))
+				return
+			}
+			if !containsRole(roles, name) {
+				roles = append(roles, name)
+			}
+		}
+
+		before, err := ar.roleStorage.UserRoles(app.ID(), user.ID())
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if err = ar.roleStorage.SetUserRoles(app.ID(), user.ID(), roles); err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Roles of user %s in app %s updated", user.ID(), app.ID())
+		ar.audit(r, "user.roles.update", "user", user.ID(), userRolesData{Roles: before}, userRolesData{Roles: roles})
+		ar.serveUserRoles(w, app, user)
+	}
+}
+
+func (ar *Router) serveUserRoles(w http.ResponseWriter, app model.AppData, user model.User) {
+	assigned, err := ar.roleStorage.UserRoles(app.ID(), user.ID())
+	if err != nil {
+		ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+		return
+	}
+	effective, permissions, err := model.EffectiveRoles(ar.roleStorage, app.ID(), user.ID(), user.AccessRole())
+	if err != nil {
+		ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ar.ServeJSON(w, http.StatusOK, userRolesData{Roles: assigned, EffectiveRoles: effective, Permissions: permissions})
+}
+
+// validateRole checks the role against the other roles of the app, writing error response on failure.
+func (ar *Router) validateRole(w http.ResponseWriter, role model.Role) error {
+	catalogue, err := ar.roleStorage.FetchRoles(role.AppID)
+	if err != nil {
+		ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+		return err
+	}
+	if err = role.Validate(catalogue); err != nil {
+		ar.Error(w, err, http.StatusBadRequest, err.Error())
+		return err
+	}
+	return nil
+}
+
+// role fetches the app role from route variable, writing error response on failure.
+func (ar *Router) role(w http.ResponseWriter, r *http.Request, appID string) (model.Role, error) {
+	role, err := ar.roleStorage.RoleByID(getRouteVar("role_id", r))
+	if err == nil && role.AppID != appID {
+		err = model.ErrRoleNotFound
+	}
+	if err == model.ErrRoleNotFound {
+		ar.Error(w, err, http.StatusNotFound, "")
+		return role, err
+	}
+	if err != nil {
+		ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+		return role, err
+	}
+	return role, nil
+}
+
+// userForRoles fetches the user from route variable, writing error response on failure.
+func (ar *Router) userForRoles(w http.ResponseWriter, r *http.Request) (model.User, error) {
+	user, err := ar.userStorage.UserByID(getRouteVar("user_id", r))
+	if err == model.ErrUserNotFound {
+		ar.Error(w, err, http.StatusNotFound, "")
+		return nil, err
+	}
+	if err != nil {
+		ar.Error(w, ErrorInternalError, http.StatusInternalServerError, err.Error())
+		return nil, err
+	}
+	return user, nil
+}
+
+func containsRole(roles []string, name string) bool {
+	for _, r := range roles {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
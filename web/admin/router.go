@@ -2,14 +2,17 @@ package admin
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"os"
 	"path"
 
 	"github.com/gorilla/mux"
+	jwtService "github.com/madappgang/identifo/jwt/service"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/metrics"
 	"github.com/madappgang/identifo/model"
 	"github.com/madappgang/identifo/server/utils/originchecker"
+	"github.com/madappgang/identifo/web/authorization"
 	"github.com/rs/cors"
 	"github.com/urfave/negroni"
 )
@@ -19,14 +22,31 @@ type Router struct {
 	middleware           *negroni.Negroni
 	cors                 *cors.Cors
 	originChecker        *originchecker.OriginChecker
-	logger               *log.Logger
+	logger               *logging.Logger
 	router               *mux.Router
 	sessionService       model.SessionService
 	sessionStorage       model.SessionStorage
 	appStorage           model.AppStorage
 	userStorage          model.UserStorage
+	inviteStorage        model.InviteStorage
+	userSessionStorage   model.UserSessionStorage
+	adminStorage         model.AdminStorage
+	auditStorage         model.AuditStorage
+	authEventStorage     model.AuthEventStorage
+	webhookStorage       model.WebhookStorage
+	organizationStorage  model.OrganizationStorage
+	scimTokenStorage     model.SCIMTokenStorage
+	policyStorage        model.PolicyStorage
+	roleStorage          model.RoleStorage
 	configurationStorage model.ConfigurationStorage
 	staticFilesStorage   model.StaticFilesStorage
+	tokenService         jwtService.TokenService
+	emailService         model.EmailService
+	userSessionService   model.UserSessionService
+	webhookService       model.WebhookService
+	authorizer           *authorization.Authorizer
+	userAttributes       model.UserAttributeSchema
+	tfaLimiter           tfaLimiter
 	ServerConfigPath     string
 	ServerSettings       *model.ServerSettings
 	newSettings          *model.ServerSettings
@@ -83,6 +103,14 @@ func ServerSettingsOption(settings *model.ServerSettings) func(*Router) error {
 	}
 }
 
+// UserAttributesOption sets the schema of custom user profile attributes.
+func UserAttributesOption(schema model.UserAttributeSchema) func(*Router) error {
+	return func(r *Router) error {
+		r.userAttributes = schema
+		return nil
+	}
+}
+
 // RedirectURLOption sets redirect url value.
 func RedirectURLOption(redirectURL string) func(*Router) error {
 	return func(r *Router) error {
@@ -100,16 +128,31 @@ func PathPrefixOptions(prefix string) func(r *Router) error {
 }
 
 // NewRouter creates and initializes new admin router.
-func NewRouter(logger *log.Logger, sServ model.SessionService, sStor model.SessionStorage, as model.AppStorage, us model.UserStorage, cs model.ConfigurationStorage, sfs model.StaticFilesStorage, options ...func(*Router) error) (model.Router, error) {
+func NewRouter(logger *logging.Logger, sServ model.SessionService, sStor model.SessionStorage, as model.AppStorage, us model.UserStorage, is model.InviteStorage, uss model.UserSessionStorage, ads model.AdminStorage, aus model.AuditStorage, aes model.AuthEventStorage, ws model.WebhookStorage, ors model.OrganizationStorage, sts model.SCIMTokenStorage, ps model.PolicyStorage, rs model.RoleStorage, cs model.ConfigurationStorage, sfs model.StaticFilesStorage, tServ jwtService.TokenService, emailServ model.EmailService, usServ model.UserSessionService, whServ model.WebhookService, authorizer *authorization.Authorizer, options ...func(*Router) error) (model.Router, error) {
 	ar := Router{
-		middleware:           negroni.Classic(),
+		middleware:           negroni.New(),
 		router:               mux.NewRouter(),
 		sessionService:       sServ,
 		sessionStorage:       sStor,
 		appStorage:           as,
 		userStorage:          us,
+		inviteStorage:        is,
+		userSessionStorage:   uss,
+		adminStorage:         ads,
+		auditStorage:         aus,
+		authEventStorage:     aes,
+		webhookStorage:       ws,
+		organizationStorage:  ors,
+		scimTokenStorage:     sts,
+		policyStorage:        ps,
+		roleStorage:          rs,
 		configurationStorage: cs,
 		staticFilesStorage:   sfs,
+		tokenService:         tServ,
+		emailService:         emailServ,
+		userSessionService:   usServ,
+		webhookService:       whServ,
+		authorizer:           authorizer,
 	}
 
 	for _, option := range append(defaultOptions(), options...) {
@@ -118,9 +161,11 @@ func NewRouter(logger *log.Logger, sServ model.SessionService, sStor model.Sessi
 		}
 	}
 
-	if logger == nil {
-		ar.logger = log.New(os.Stdout, "ADMIN_ROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	ar.logger = logger
+	if ar.logger == nil {
+		ar.logger = logging.Default().With("router", "admin")
 	}
+	ar.middleware.Use(logging.Recovery(ar.logger))
 
 	if ar.cors == nil {
 		ar.cors = ar.defaultCORS()
@@ -128,6 +173,7 @@ func NewRouter(logger *log.Logger, sServ model.SessionService, sStor model.Sessi
 	ar.middleware.Use(ar.cors)
 
 	ar.initRoutes()
+	ar.router.Use(metrics.RouteMiddleware)
 	ar.middleware.UseHandler(ar.router)
 
 	return &ar, nil
@@ -143,7 +189,7 @@ func (ar *Router) Error(w http.ResponseWriter, err error, code int, userInfo str
 	}
 
 	// Log error.
-	ar.logger.Printf("admin error: %v (code=%d)", err, code)
+	ar.logger.Logf(logging.StatusLevel(code), "admin error: %v (code=%d)", err, code)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -153,7 +199,7 @@ func (ar *Router) Error(w http.ResponseWriter, err error, code int, userInfo str
 		Code:  code,
 	})
 	if encodeErr != nil {
-		ar.logger.Printf("error writing http response: %s", err)
+		ar.logger.Errorf("error writing http response: %s", err)
 	}
 }
 
@@ -174,7 +220,7 @@ func (ar *Router) ServeJSON(w http.ResponseWriter, code int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	if _, err = w.Write(data); err != nil {
-		log.Printf("error writing http response: %s", err)
+		logging.Errorf("error writing http response: %s", err)
 	}
 }
 
@@ -2,6 +2,8 @@ package admin
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/madappgang/identifo/metrics"
+	"github.com/madappgang/identifo/model"
 	"github.com/urfave/negroni"
 )
 
@@ -15,6 +17,23 @@ func (ar *Router) initRoutes() {
 		negroni.WrapFunc(ar.IsLoggedIn()),
 	)).Methods("GET")
 
+	ar.router.Path(`/me/tfa`).Handler(negroni.New(
+		ar.TFAEnrolmentSession(),
+		negroni.WrapFunc(ar.EnableTFA()),
+	)).Methods("POST")
+	ar.router.Path(`/me/tfa`).Handler(negroni.New(
+		ar.Session(),
+		negroni.WrapFunc(ar.DisableTFA()),
+	)).Methods("DELETE")
+	ar.router.Path(`/me/tfa/finalize`).Handler(negroni.New(
+		ar.TFAEnrolmentSession(),
+		negroni.WrapFunc(ar.FinalizeTFA()),
+	)).Methods("POST")
+	ar.router.Path(`/me/tfa/recovery_codes`).Handler(negroni.New(
+		ar.Session(),
+		negroni.WrapFunc(ar.RegenerateRecoveryCodes()),
+	)).Methods("POST")
+
 	ar.router.Path(`/{login:login/?}`).Handler(negroni.New(
 		negroni.WrapFunc(ar.Login()),
 	)).Methods("POST")
@@ -23,7 +42,13 @@ func (ar *Router) initRoutes() {
 		negroni.WrapFunc(ar.Logout()),
 	)).Methods("POST")
 
+	ar.router.Path(`/{accept_invite:accept_invite/?}`).Handler(negroni.New(
+		negroni.WrapFunc(ar.AcceptAdminInvite()),
+	)).Methods("POST")
+
 	ar.router.Path(`/{restart:restart/?}`).Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(),
 		negroni.WrapFunc(ar.RestartServer()),
 	)).Methods("POST")
 
@@ -33,17 +58,56 @@ func (ar *Router) initRoutes() {
 	)).Methods("GET")
 	ar.router.Path(`/{apps:apps/?}`).Handler(negroni.New(
 		ar.Session(),
+		ar.RequireRole(model.AdminRoleAppManager),
 		negroni.WrapFunc(ar.CreateApp()),
 	)).Methods("POST")
 
 	apps := mux.NewRouter().PathPrefix("/apps").Subrouter()
+	apps.Use(metrics.RouteMiddleware)
 	ar.router.PathPrefix("/apps").Handler(negroni.New(
 		ar.Session(),
 		negroni.Wrap(apps),
 	))
 	apps.Path("/{id:[a-zA-Z0-9]+}").HandlerFunc(ar.GetApp()).Methods("GET")
-	apps.Path("/{id:[a-zA-Z0-9]+}").HandlerFunc(ar.UpdateApp()).Methods("PUT")
-	apps.Path("/{id:[a-zA-Z0-9]+}").HandlerFunc(ar.DeleteApp()).Methods("DELETE")
+	apps.Path("/{id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.WrapFunc(ar.UpdateApp()),
+	)).Methods("PUT")
+	apps.Path("/{id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.WrapFunc(ar.DeleteApp()),
+	)).Methods("DELETE")
+	apps.Path("/{id:[a-zA-Z0-9]+}/policies").HandlerFunc(ar.FetchPolicyRules()).Methods("GET")
+	apps.Path("/{id:[a-zA-Z0-9]+}/policies").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.WrapFunc(ar.CreatePolicyRule()),
+	)).Methods("POST")
+	apps.Path("/{id:[a-zA-Z0-9]+}/policies/{rule_id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.WrapFunc(ar.UpdatePolicyRule()),
+	)).Methods("PUT")
+	apps.Path("/{id:[a-zA-Z0-9]+}/policies/{rule_id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.WrapFunc(ar.DeletePolicyRule()),
+	)).Methods("DELETE")
+	apps.Path("/{id:[a-zA-Z0-9]+}/roles").HandlerFunc(ar.FetchRoles()).Methods("GET")
+	apps.Path("/{id:[a-zA-Z0-9]+}/roles").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.WrapFunc(ar.CreateRole()),
+	)).Methods("POST")
+	apps.Path("/{id:[a-zA-Z0-9]+}/roles/{role_id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.WrapFunc(ar.UpdateRole()),
+	)).Methods("PUT")
+	apps.Path("/{id:[a-zA-Z0-9]+}/roles/{role_id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.WrapFunc(ar.DeleteRole()),
+	)).Methods("DELETE")
+	apps.Path("/{id:[a-zA-Z0-9]+}/users/{user_id:[a-zA-Z0-9]+}/roles").HandlerFunc(ar.GetUserRoles()).Methods("GET")
+	apps.Path("/{id:[a-zA-Z0-9]+}/users/{user_id:[a-zA-Z0-9]+}/roles").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleUserSupport),
+		negroni.WrapFunc(ar.SetUserRoles()),
+	)).Methods("PUT")
 
 	ar.router.Path(`/{users:users/?}`).Handler(negroni.New(
 		ar.Session(),
@@ -51,26 +115,184 @@ func (ar *Router) initRoutes() {
 	)).Methods("GET")
 	ar.router.Path(`/{users:users/?}`).Handler(negroni.New(
 		ar.Session(),
+		ar.RequireRole(model.AdminRoleUserSupport),
 		negroni.WrapFunc(ar.CreateUser()),
 	)).Methods("POST")
 
 	users := mux.NewRouter().PathPrefix("/users").Subrouter()
+	users.Use(metrics.RouteMiddleware)
 	ar.router.PathPrefix("/users").Handler(negroni.New(
 		ar.Session(),
 		negroni.Wrap(users),
 	))
 	users.Path("/{id:[a-zA-Z0-9]+}").HandlerFunc(ar.GetUser()).Methods("GET")
-	users.Path("/{id:[a-zA-Z0-9]+}").HandlerFunc(ar.UpdateUser()).Methods("PUT")
-	users.Path("/{id:[a-zA-Z0-9]+}").HandlerFunc(ar.DeleteUser()).Methods("DELETE")
+	users.Path("/{id:[a-zA-Z0-9]+}/auth_events").HandlerFunc(ar.FetchUserAuthEvents()).Methods("GET")
+	users.Path("/{id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleUserSupport),
+		negroni.WrapFunc(ar.UpdateUser()),
+	)).Methods("PUT")
+	users.Path("/{id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleUserSupport),
+		negroni.WrapFunc(ar.DeleteUser()),
+	)).Methods("DELETE")
+	users.Path("/{id:[a-zA-Z0-9]+}/revoke_tokens").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleUserSupport),
+		negroni.WrapFunc(ar.RevokeUserTokens()),
+	)).Methods("POST")
+	users.Path("/{id:[a-zA-Z0-9]+}/reset_tfa").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleUserSupport),
+		negroni.WrapFunc(ar.ResetUserTFA()),
+	)).Methods("POST")
+
+	ar.router.Path(`/{invites:invites/?}`).Handler(negroni.New(
+		ar.Session(),
+		negroni.WrapFunc(ar.FetchInvites()),
+	)).Methods("GET")
+	ar.router.Path(`/{invites:invites/?}`).Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(model.AdminRoleAppManager, model.AdminRoleUserSupport),
+		negroni.WrapFunc(ar.CreateInvites()),
+	)).Methods("POST")
+
+	invites := mux.NewRouter().PathPrefix("/invites").Subrouter()
+	invites.Use(metrics.RouteMiddleware)
+	ar.router.PathPrefix("/invites").Handler(negroni.New(
+		ar.Session(),
+		negroni.Wrap(invites),
+	))
+	invites.Path("/{id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleAppManager, model.AdminRoleUserSupport),
+		negroni.WrapFunc(ar.RevokeInvite()),
+	)).Methods("DELETE")
+
+	ar.router.Path(`/{organizations:organizations/?}`).Handler(negroni.New(
+		ar.Session(),
+		negroni.WrapFunc(ar.FetchOrganizations()),
+	)).Methods("GET")
+	ar.router.Path(`/{organizations:organizations/?}`).Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(model.AdminRoleUserSupport),
+		negroni.WrapFunc(ar.CreateOrganization()),
+	)).Methods("POST")
+
+	organizations := mux.NewRouter().PathPrefix("/organizations").Subrouter()
+	organizations.Use(metrics.RouteMiddleware)
+	ar.router.PathPrefix("/organizations").Handler(negroni.New(
+		ar.Session(),
+		negroni.Wrap(organizations),
+	))
+	organizations.Path("/{id:[a-zA-Z0-9]+}").HandlerFunc(ar.GetOrganization()).Methods("GET")
+	organizations.Path("/{id:[a-zA-Z0-9]+}/members").HandlerFunc(ar.FetchOrganizationMembers()).Methods("GET")
+	organizations.Path("/{id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleUserSupport),
+		negroni.WrapFunc(ar.UpdateOrganization()),
+	)).Methods("PUT")
+	organizations.Path("/{id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleUserSupport),
+		negroni.WrapFunc(ar.DeleteOrganization()),
+	)).Methods("DELETE")
+	organizations.Path("/{id:[a-zA-Z0-9]+}/members/{user_id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleUserSupport),
+		negroni.WrapFunc(ar.SetOrganizationMember()),
+	)).Methods("PUT")
+	organizations.Path("/{id:[a-zA-Z0-9]+}/members/{user_id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.RequireRole(model.AdminRoleUserSupport),
+		negroni.WrapFunc(ar.RemoveOrganizationMember()),
+	)).Methods("DELETE")
+
+	ar.router.Path(`/{webhooks:webhooks/?}`).Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.WrapFunc(ar.FetchWebhooks()),
+	)).Methods("GET")
+	ar.router.Path(`/{webhooks:webhooks/?}`).Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.WrapFunc(ar.CreateWebhook()),
+	)).Methods("POST")
+
+	webhooks := mux.NewRouter().PathPrefix("/webhooks").Subrouter()
+	webhooks.Use(metrics.RouteMiddleware)
+	ar.router.PathPrefix("/webhooks").Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.Wrap(webhooks),
+	))
+	webhooks.Path("/{id:[a-zA-Z0-9]+}").HandlerFunc(ar.GetWebhook()).Methods("GET")
+	webhooks.Path("/{id:[a-zA-Z0-9]+}").HandlerFunc(ar.UpdateWebhook()).Methods("PUT")
+	webhooks.Path("/{id:[a-zA-Z0-9]+}").HandlerFunc(ar.DeleteWebhook()).Methods("DELETE")
+
+	ar.router.Path(`/{webhook_deliveries:webhook_deliveries/?}`).Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.WrapFunc(ar.FetchWebhookDeliveries()),
+	)).Methods("GET")
+
+	deliveries := mux.NewRouter().PathPrefix("/webhook_deliveries").Subrouter()
+	deliveries.Use(metrics.RouteMiddleware)
+	ar.router.PathPrefix("/webhook_deliveries").Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(model.AdminRoleAppManager),
+		negroni.Wrap(deliveries),
+	))
+	deliveries.Path("/{id:[a-zA-Z0-9]+}/replay").HandlerFunc(ar.ReplayWebhookDelivery()).Methods("POST")
+
+	// SCIM tokens give full access to users, so only owners manage them.
+	ar.router.Path(`/{scim_tokens:scim_tokens/?}`).Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(),
+		negroni.WrapFunc(ar.FetchSCIMTokens()),
+	)).Methods("GET")
+	ar.router.Path(`/{scim_tokens:scim_tokens/?}`).Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(),
+		negroni.WrapFunc(ar.CreateSCIMToken()),
+	)).Methods("POST")
+	ar.router.Path("/scim_tokens/{id:[a-zA-Z0-9]+}").Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(),
+		negroni.WrapFunc(ar.DeleteSCIMToken()),
+	)).Methods("DELETE")
+
+	ar.router.Path(`/{admins:admins/?}`).Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(),
+		negroni.WrapFunc(ar.FetchAdmins()),
+	)).Methods("GET")
+	ar.router.Path(`/{admins:admins/?}`).Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(),
+		negroni.WrapFunc(ar.InviteAdmin()),
+	)).Methods("POST")
+
+	admins := mux.NewRouter().PathPrefix("/admins").Subrouter()
+	admins.Use(metrics.RouteMiddleware)
+	ar.router.PathPrefix("/admins").Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(),
+		negroni.Wrap(admins),
+	))
+	admins.Path("/{id:[a-zA-Z0-9]+}").HandlerFunc(ar.UpdateAdmin()).Methods("PATCH")
+	admins.Path("/{id:[a-zA-Z0-9]+}").HandlerFunc(ar.DeleteAdmin()).Methods("DELETE")
+	admins.Path("/{id:[a-zA-Z0-9]+}/reset_tfa").HandlerFunc(ar.ResetAdminTFA()).Methods("POST")
+
+	ar.router.Path(`/{audit:audit/?}`).Handler(negroni.New(
+		ar.Session(),
+		ar.RequireRole(),
+		negroni.WrapFunc(ar.FetchAuditEvents()),
+	)).Methods("GET")
 
 	ar.router.Path(`/{settings:settings/?}`).Handler(negroni.New(
 		ar.Session(),
+		ar.RequireRole(),
 		negroni.WrapFunc(ar.FetchServerSettings()),
 	)).Methods("GET")
 
 	settings := mux.NewRouter().PathPrefix("/settings").Subrouter()
+	settings.Use(metrics.RouteMiddleware)
 	ar.router.PathPrefix("/settings").Handler(negroni.New(
 		ar.Session(),
+		ar.RequireRole(),
 		negroni.Wrap(settings),
 	))
 
@@ -79,6 +301,8 @@ func (ar *Router) initRoutes() {
 
 	settings.Path("/account").HandlerFunc(ar.FetchAccountSettings()).Methods("GET")
 	settings.Path("/account").HandlerFunc(ar.UpdateAccountSettings()).Methods("PATCH")
+	settings.Path("/account/tfa").HandlerFunc(ar.FetchTFASettings()).Methods("GET")
+	settings.Path("/account/tfa").HandlerFunc(ar.UpdateTFASettings()).Methods("PUT")
 
 	settings.Path("/storage").HandlerFunc(ar.FetchStorageSettings()).Methods("GET")
 	settings.Path("/storage").HandlerFunc(ar.UpdateStorageSettings()).Methods("PUT")
@@ -100,8 +324,10 @@ func (ar *Router) initRoutes() {
 	settings.Path("/services").HandlerFunc(ar.UpdateExternalServicesSettings()).Methods("PUT")
 
 	static := mux.NewRouter().PathPrefix("/static").Subrouter()
+	static.Use(metrics.RouteMiddleware)
 	ar.router.PathPrefix("/static").Handler(negroni.New(
 		ar.Session(),
+		ar.RequireRole(),
 		negroni.Wrap(static),
 	))
 
@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+)
+
+// FetchSCIMTokens returns all SCIM tokens, without the hashes.
+func (ar *Router) FetchSCIMTokens() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tokens, err := ar.scimTokenStorage.FetchSCIMTokens()
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+		for i := range tokens {
+			tokens[i].Hash = ""
+		}
+		ar.ServeJSON(w, http.StatusOK, tokens)
+	}
+}
+
+// CreateSCIMToken issues new SCIM token. The token is returned only in this response.
+func (ar *Router) CreateSCIMToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := struct {
+			Name string `json:"name"`
+		}{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+		if d.Name = strings.TrimSpace(d.Name); d.Name == "" {
+			err := fmt.Errorf("Token name is empty")
+			ar.Error(w, err, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		b := make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "Cannot generate token")
+			return
+		}
+		secret := base64.RawURLEncoding.EncodeToString(b)
+
+		token, err := ar.scimTokenStorage.AddSCIMToken(model.SCIMToken{
+			Name:      d.Name,
+			Hash:      model.HashSCIMToken(secret),
+			CreatedBy: adminFromContext(r.Context()).ID,
+			CreatedAt: time.Now().Unix(),
+		})
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+		token.Hash = ""
+
+		ar.logger.WithRequest(r).Infof("SCIM token %s created", token.ID)
+		ar.audit(r, "scim_token.create", "scim_token", token.ID, nil, token)
+
+		response := struct {
+			model.SCIMToken
+			Token string `json:"token"`
+		}{
+			SCIMToken: token,
+			Token:     secret,
+		}
+		ar.ServeJSON(w, http.StatusOK, response)
+	}
+}
+
+// DeleteSCIMToken revokes the SCIM token.
+func (ar *Router) DeleteSCIMToken() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := getRouteVar("id", r)
+
+		err := ar.scimTokenStorage.DeleteSCIMToken(id)
+		if err == model.ErrSCIMTokenNotFound {
+			ar.Error(w, err, http.StatusNotFound, "")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("SCIM token %s deleted", id)
+		ar.audit(r, "scim_token.delete", "scim_token", id, nil, nil)
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
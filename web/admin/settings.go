@@ -16,6 +16,7 @@ func (ar *Router) FetchServerSettings() http.HandlerFunc {
 }
 
 // FetchAccountSettings returns admin account settings.
+// These credentials are only used to create the first owner account.
 func (ar *Router) FetchAccountSettings() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		conf := new(adminLoginData)
@@ -54,6 +55,8 @@ func (ar *Router) UpdateAccountSettings() http.HandlerFunc {
 			return
 		}
 
+		adminAccountBefore := *adminData
+
 		if len(adminDataUpdate.Login) > 0 {
 			adminData.Login = adminDataUpdate.Login
 		}
@@ -75,11 +78,54 @@ func (ar *Router) UpdateAccountSettings() http.HandlerFunc {
 		if ar.updateAdminAccountSettings(w, adminData) != nil {
 			return
 		}
+		ar.audit(r, "settings.update", "settings", "admin_account", adminAccountBefore, adminData)
 
 		ar.ServeJSON(w, http.StatusOK, adminData)
 	}
 }
 
+type tfaSettings struct {
+	RequireTFA *bool `json:"require_tfa"`
+}
+
+// FetchTFASettings returns whether admins have to use two-factor authentication.
+func (ar *Router) FetchTFASettings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requireTFA := ar.ServerSettings.AdminAccount.RequireTFA
+		ar.ServeJSON(w, http.StatusOK, tfaSettings{RequireTFA: &requireTFA})
+	}
+}
+
+// UpdateTFASettings sets whether admins have to use two-factor authentication.
+// Unlike other settings, it is saved to the configuration storage and applied at once.
+func (ar *Router) UpdateTFASettings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := tfaSettings{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+		if d.RequireTFA == nil {
+			err := fmt.Errorf("require_tfa is required")
+			ar.Error(w, err, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		before := ar.ServerSettings.AdminAccount.RequireTFA
+		settings := *ar.ServerSettings
+		settings.AdminAccount.RequireTFA = *d.RequireTFA
+		if err := ar.configurationStorage.InsertConfig(ar.ServerSettings.ConfigurationStorage.SettingsKey, &settings); err != nil {
+			ar.logger.WithRequest(r).Error("Cannot save TFA settings to configuration storage:", err)
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+		ar.ServerSettings.AdminAccount.RequireTFA = *d.RequireTFA
+		ar.newSettings.AdminAccount.RequireTFA = *d.RequireTFA
+
+		ar.audit(r, "settings.update", "settings", "admin_account.require_tfa", before, *d.RequireTFA)
+		ar.ServeJSON(w, http.StatusOK, d)
+	}
+}
+
 // FetchGeneralSettings fetches server's general settings.
 func (ar *Router) FetchGeneralSettings() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -100,6 +146,7 @@ func (ar *Router) UpdateGeneralSettings() http.HandlerFunc {
 			return
 		}
 
+		ar.audit(r, "settings.update", "settings", "general", ar.newSettings.General, generalSettingsUpdate)
 		ar.newSettings.General = generalSettingsUpdate
 		ar.ServeJSON(w, http.StatusOK, ar.newSettings.General)
 	}
@@ -125,6 +172,7 @@ func (ar *Router) UpdateStorageSettings() http.HandlerFunc {
 			return
 		}
 
+		ar.audit(r, "settings.update", "settings", "storage", ar.newSettings.Storage, storageSettingsUpdate)
 		ar.newSettings.Storage = storageSettingsUpdate
 		ar.ServeJSON(w, http.StatusOK, ar.newSettings.SessionStorage)
 	}
@@ -150,6 +198,7 @@ func (ar *Router) UpdateSessionStorageSettings() http.HandlerFunc {
 			return
 		}
 
+		ar.audit(r, "settings.update", "settings", "session_storage", ar.newSettings.SessionStorage, sessionStorageSettingsUpdate)
 		ar.newSettings.SessionStorage = sessionStorageSettingsUpdate
 		ar.ServeJSON(w, http.StatusOK, ar.newSettings.SessionStorage)
 	}
@@ -166,10 +215,12 @@ func (ar *Router) FetchConfigurationStorageSettings() http.HandlerFunc {
 func (ar *Router) RestartServer() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if err := ar.configurationStorage.InsertConfig(ar.ServerSettings.ConfigurationStorage.SettingsKey, ar.newSettings); err != nil {
-			ar.logger.Println("Cannot insert new settings into configuartion storage:", err)
+			ar.logger.WithRequest(r).Error("Cannot insert new settings into configuartion storage:", err)
 			ar.Error(w, err, http.StatusInternalServerError, "")
 			return
 		}
+
+		ar.audit(r, "server.restart", "settings", "", nil, nil)
 		ar.ServeJSON(w, http.StatusOK, nil)
 	}
 }
@@ -187,6 +238,7 @@ func (ar *Router) UpdateConfigurationStorageSettings() http.HandlerFunc {
 			return
 		}
 
+		ar.audit(r, "settings.update", "settings", "configuration_storage", ar.newSettings.ConfigurationStorage, configurationStorageSettingsUpdate)
 		ar.newSettings.ConfigurationStorage = configurationStorageSettingsUpdate
 		ar.ServeJSON(w, http.StatusOK, ar.newSettings.ConfigurationStorage)
 	}
@@ -208,6 +260,7 @@ func (ar *Router) UpdateStaticFilesStorageSettings() http.HandlerFunc {
 			return
 		}
 
+		ar.audit(r, "settings.update", "settings", "static_files_storage", ar.newSettings.StaticFilesStorage, staticFilesStorageSettingsUpdate)
 		ar.newSettings.StaticFilesStorage = staticFilesStorageSettingsUpdate
 		ar.ServeJSON(w, http.StatusOK, ar.newSettings.StaticFilesStorage)
 	}
@@ -229,6 +282,7 @@ func (ar *Router) UpdateLoginSettings() http.HandlerFunc {
 			return
 		}
 
+		ar.audit(r, "settings.update", "settings", "login", ar.newSettings.Login, loginSettingsUpdate)
 		ar.newSettings.Login = loginSettingsUpdate
 		ar.ServeJSON(w, http.StatusOK, ar.newSettings.Login)
 	}
@@ -254,6 +308,7 @@ func (ar *Router) UpdateExternalServicesSettings() http.HandlerFunc {
 			return
 		}
 
+		ar.audit(r, "settings.update", "settings", "external_services", ar.newSettings.ExternalServices, servicesSettingsUpdate)
 		ar.newSettings.ExternalServices = servicesSettingsUpdate
 		ar.ServeJSON(w, http.StatusOK, ar.newSettings.ExternalServices)
 	}
@@ -337,5 +392,10 @@ func (ar *Router) validateAdminPassword(pswd string, w http.ResponseWriter) erro
 		ar.Error(w, err, http.StatusBadRequest, err.Error())
 		return err
 	}
+	if pswd == insecureAdminPassword {
+		err := fmt.Errorf("Password %q is not allowed", insecureAdminPassword)
+		ar.Error(w, err, http.StatusBadRequest, err.Error())
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,78 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ijwt "github.com/madappgang/identifo/jwt"
+	"github.com/madappgang/identifo/model"
+)
+
+// testConfigurationStorage keeps the last inserted settings.
+type testConfigurationStorage struct {
+	settings *model.ServerSettings
+	err      error
+}
+
+func (cs *testConfigurationStorage) InsertConfig(key string, value interface{}) error {
+	if cs.err != nil {
+		return cs.err
+	}
+	cs.settings = value.(*model.ServerSettings)
+	return nil
+}
+
+func (cs *testConfigurationStorage) LoadServerSettings(*model.ServerSettings) error { return nil }
+func (cs *testConfigurationStorage) InsertKeys(keys *model.JWTKeys) error           { return nil }
+func (cs *testConfigurationStorage) LoadKeys(ijwt.TokenSignatureAlgorithm) (*model.JWTKeys, error) {
+	return nil, nil
+}
+func (cs *testConfigurationStorage) GetUpdateChan() chan interface{} { return nil }
+func (cs *testConfigurationStorage) CloseUpdateChan()                {}
+
+func updateTFASettings(ar *Router, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	ar.UpdateTFASettings()(rec, httptest.NewRequest(http.MethodPut, "/settings/account/tfa", strings.NewReader(body)))
+	return rec
+}
+
+func TestUpdateTFASettings(t *testing.T) {
+	// Admin credentials are not in the environment once the owner account exists.
+	ar := newTestRouter(t)
+	cs := &testConfigurationStorage{}
+	ar.configurationStorage = cs
+
+	if rec := updateTFASettings(ar, `{"require_tfa":true}`); rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, expected %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if !ar.ServerSettings.AdminAccount.RequireTFA || cs.settings == nil || !cs.settings.AdminAccount.RequireTFA {
+		t.Errorf("TFA requirement is not applied and saved")
+	}
+
+	if rec := updateTFASettings(ar, `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Status without value = %d, expected %d", rec.Code, http.StatusBadRequest)
+	}
+	if !ar.ServerSettings.AdminAccount.RequireTFA {
+		t.Errorf("TFA requirement changed by invalid request")
+	}
+
+	// Nothing is applied when the settings cannot be saved.
+	cs.err = errors.New("read only")
+	if rec := updateTFASettings(ar, `{"require_tfa":false}`); rec.Code != http.StatusInternalServerError {
+		t.Errorf("Status with failing storage = %d, expected %d", rec.Code, http.StatusInternalServerError)
+	}
+	if !ar.ServerSettings.AdminAccount.RequireTFA || !cs.settings.AdminAccount.RequireTFA {
+		t.Errorf("TFA requirement applied although it is not saved")
+	}
+
+	cs.err = nil
+	if rec := updateTFASettings(ar, `{"require_tfa":false}`); rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, expected %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if ar.ServerSettings.AdminAccount.RequireTFA || cs.settings.AdminAccount.RequireTFA {
+		t.Errorf("TFA requirement is not turned off")
+	}
+}
@@ -2,6 +2,8 @@ package admin
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -51,10 +53,14 @@ func (ar *Router) UploadStringifiedFile() http.HandlerFunc {
 			return
 		}
 
+		before, _ := ar.staticFilesStorage.GetFile(filename)
+
 		if err := ar.staticFilesStorage.UploadFile(filename, []byte(f.Contents)); err != nil {
 			ar.Error(w, err, http.StatusInternalServerError, err.Error())
 			return
 		}
+
+		ar.audit(r, "static.template.upload", "static_file", filename, fileDigest(before), fileDigest([]byte(f.Contents)))
 		ar.ServeJSON(w, http.StatusOK, nil)
 	}
 }
@@ -88,6 +94,8 @@ func (ar *Router) UploadADDAFile() http.HandlerFunc {
 			ar.Error(w, err, http.StatusInternalServerError, fmt.Sprintf("Cannot upload file: %s", err.Error()))
 			return
 		}
+
+		ar.audit(r, "static.apple_domain_association.upload", "static_file", model.AppleFilenames.DeveloperDomainAssociation, nil, fileDigest(buf.Bytes()))
 		ar.ServeJSON(w, http.StatusOK, nil)
 	}
 }
@@ -138,6 +146,8 @@ func (ar *Router) UploadJWTKeys() http.HandlerFunc {
 			ar.Error(w, err, http.StatusInternalServerError, "")
 			return
 		}
+
+		ar.audit(r, "static.jwt_keys.upload", "jwt_keys", "", nil, nil)
 		ar.ServeJSON(w, http.StatusOK, nil)
 	}
 }
@@ -156,3 +166,15 @@ func (ar *Router) parseStaticFileFullName(w http.ResponseWriter, r *http.Request
 	}
 	return filename, nil
 }
+
+// fileDigest describes file contents in audit events without writing the contents.
+func fileDigest(contents []byte) interface{} {
+	if contents == nil {
+		return nil
+	}
+	sum := sha256.Sum256(contents)
+	return map[string]interface{}{
+		"sha256": hex.EncodeToString(sum[:]),
+		"size":   len(contents),
+	}
+}
@@ -0,0 +1,281 @@
+package admin
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/xlzd/gotp"
+)
+
+const (
+	// recoveryCodesCount is a number of recovery codes issued on TFA enrolment.
+	recoveryCodesCount = 10
+	// defaultTFAIssuer is shown in authenticator apps when server issuer is not set.
+	defaultTFAIssuer = "Identifo admin panel"
+	// maxTFAFailures is a number of wrong codes after which TFA checks of the admin are blocked for tfaFailuresWindow.
+	maxTFAFailures    = 5
+	tfaFailuresWindow = 15 * time.Minute
+)
+
+// tfaLimiter counts wrong TFA and recovery codes of each admin to stop brute forcing them.
+// It also serializes TFA checks, so concurrent requests cannot use the same TOTP code.
+type tfaLimiter struct {
+	mu       sync.Mutex
+	failures map[string]tfaFailures
+}
+
+type tfaFailures struct {
+	count int
+	since time.Time
+}
+
+func (l *tfaLimiter) blocked(adminID string, now time.Time) bool {
+	f, ok := l.failures[adminID]
+	if ok && now.Sub(f.since) >= tfaFailuresWindow {
+		delete(l.failures, adminID)
+		return false
+	}
+	return ok && f.count >= maxTFAFailures
+}
+
+func (l *tfaLimiter) fail(adminID string, now time.Time) {
+	if l.failures == nil {
+		l.failures = make(map[string]tfaFailures)
+	}
+	f, ok := l.failures[adminID]
+	if !ok {
+		f.since = now
+	}
+	f.count++
+	l.failures[adminID] = f
+}
+
+func (l *tfaLimiter) reset(adminID string) {
+	delete(l.failures, adminID)
+}
+
+type tfaCodeData struct {
+	TFACode string `json:"tfa_code" validate:"required"`
+}
+
+type recoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// EnableTFA starts two-factor authentication enrolment for the logged in admin.
+// It returns TOTP secret to be added to the authenticator app. TFA is enabled by FinalizeTFA.
+func (ar *Router) EnableTFA() http.HandlerFunc {
+	type tfaSecret struct {
+		TFASecret       string `json:"tfa_secret"`
+		ProvisioningURI string `json:"provisioning_uri"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		admin := adminFromContext(r.Context())
+		if admin.TFAEnabled {
+			ar.Error(w, ErrorTFAAlreadyEnabled, http.StatusBadRequest, "")
+			return
+		}
+
+		admin.TFASecret = gotp.RandomSecret(16)
+		if _, err := ar.adminStorage.UpdateAdmin(admin); err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.audit(r, "admin.tfa_enrol", "admin", admin.ID, nil, nil)
+
+		issuer := ar.ServerSettings.General.Issuer
+		if issuer == "" {
+			issuer = defaultTFAIssuer
+		}
+
+		ar.ServeJSON(w, http.StatusOK, tfaSecret{
+			TFASecret:       admin.TFASecret,
+			ProvisioningURI: gotp.NewDefaultTOTP(admin.TFASecret).ProvisioningUri(admin.Email, issuer),
+		})
+	}
+}
+
+// FinalizeTFA enables two-factor authentication once admin confirms the authenticator app works.
+// It returns recovery codes, which are not shown anymore.
+func (ar *Router) FinalizeTFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := tfaCodeData{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+
+		admin := adminFromContext(r.Context())
+		if admin.TFAEnabled {
+			ar.Error(w, ErrorTFAAlreadyEnabled, http.StatusBadRequest, "")
+			return
+		}
+		if !ar.checkTFA(w, &admin, d.TFACode, "", http.StatusBadRequest) {
+			return
+		}
+
+		codes, err := ar.issueRecoveryCodes(&admin)
+		if err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "Creating recovery codes")
+			return
+		}
+		admin.TFAEnabled = true
+
+		if _, err = ar.adminStorage.UpdateAdmin(admin); err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Admin %s enabled TFA", admin.ID)
+		ar.audit(r, "admin.tfa_enable", "admin", admin.ID, nil, nil)
+		ar.ServeJSON(w, http.StatusOK, recoveryCodes{RecoveryCodes: codes})
+	}
+}
+
+// RegenerateRecoveryCodes replaces recovery codes of the logged in admin with new ones.
+func (ar *Router) RegenerateRecoveryCodes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := tfaCodeData{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+
+		admin := adminFromContext(r.Context())
+		if !admin.TFAEnabled {
+			ar.Error(w, ErrorTFACodeInvalid, http.StatusBadRequest, "")
+			return
+		}
+		if !ar.checkTFA(w, &admin, d.TFACode, "", http.StatusBadRequest) {
+			return
+		}
+
+		codes, err := ar.issueRecoveryCodes(&admin)
+		if err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "Creating recovery codes")
+			return
+		}
+		if _, err = ar.adminStorage.UpdateAdmin(admin); err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Admin %s regenerated recovery codes", admin.ID)
+		ar.audit(r, "admin.recovery_codes_regenerate", "admin", admin.ID, nil, nil)
+		ar.ServeJSON(w, http.StatusOK, recoveryCodes{RecoveryCodes: codes})
+	}
+}
+
+// DisableTFA disables two-factor authentication of the logged in admin.
+func (ar *Router) DisableTFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := tfaCodeData{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+
+		admin := adminFromContext(r.Context())
+		if !admin.TFAEnabled {
+			ar.Error(w, ErrorTFACodeInvalid, http.StatusBadRequest, "")
+			return
+		}
+		if !ar.checkTFA(w, &admin, d.TFACode, "", http.StatusBadRequest) {
+			return
+		}
+
+		admin.ResetTFA()
+		if _, err := ar.adminStorage.UpdateAdmin(admin); err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Admin %s disabled TFA", admin.ID)
+		ar.audit(r, "admin.tfa_disable", "admin", admin.ID, nil, nil)
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// ResetAdminTFA disables two-factor authentication of another admin who has lost both device and recovery codes.
+func (ar *Router) ResetAdminTFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminID := getRouteVar("id", r)
+		if ar.rejectOwnAccount(w, r, adminID) {
+			return
+		}
+
+		admin, err := ar.adminStorage.AdminByID(adminID)
+		if err != nil {
+			if err == model.ErrAdminNotFound {
+				ar.Error(w, err, http.StatusNotFound, "")
+			} else {
+				ar.Error(w, err, http.StatusInternalServerError, "")
+			}
+			return
+		}
+
+		admin.ResetTFA()
+		if _, err = ar.adminStorage.UpdateAdmin(admin); err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Admin %s TFA reset by admin %s", adminID, adminFromContext(r.Context()).ID)
+		ar.audit(r, "admin.reset_tfa", "admin", adminID, nil, nil)
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// checkTFA checks TOTP or recovery code of the admin and saves the admin, so the code cannot be used again.
+// Wrong codes are answered with the status, until the admin is blocked after maxTFAFailures of them.
+func (ar *Router) checkTFA(w http.ResponseWriter, admin *model.Admin, tfaCode, recoveryCode string, status int) bool {
+	ar.tfaLimiter.mu.Lock()
+	defer ar.tfaLimiter.mu.Unlock()
+
+	now := time.Now()
+	if ar.tfaLimiter.blocked(admin.ID, now) {
+		ar.Error(w, ErrorTFATooManyAttempts, http.StatusTooManyRequests, "")
+		return false
+	}
+
+	// Admin is read again under the lock to see codes used by concurrent requests.
+	stored, err := ar.adminStorage.AdminByID(admin.ID)
+	if err != nil {
+		ar.Error(w, err, http.StatusInternalServerError, "")
+		return false
+	}
+
+	ok := false
+	if tfaCode != "" {
+		ok = stored.UseTFACode(tfaCode, now.Unix())
+	} else if recoveryCode != "" {
+		ok = stored.UseRecoveryCode(recoveryCode)
+	}
+	if !ok {
+		ar.tfaLimiter.fail(admin.ID, now)
+		ar.Error(w, ErrorTFACodeInvalid, status, "")
+		return false
+	}
+	ar.tfaLimiter.reset(admin.ID)
+
+	if _, err = ar.adminStorage.UpdateAdmin(stored); err != nil {
+		ar.Error(w, err, http.StatusInternalServerError, "")
+		return false
+	}
+	admin.TFALastStep = stored.TFALastStep
+	admin.RecoveryCodeHashes = stored.RecoveryCodeHashes
+	return true
+}
+
+// issueRecoveryCodes generates new recovery codes and sets their hashes to the admin.
+func (ar *Router) issueRecoveryCodes(admin *model.Admin) ([]string, error) {
+	codes := make([]string, recoveryCodesCount)
+	for i := range codes {
+		codes[i] = gotp.RandomSecret(10)
+	}
+	if err := admin.SetRecoveryCodes(codes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
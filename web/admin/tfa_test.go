@@ -0,0 +1,154 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/xlzd/gotp"
+)
+
+const testAdminPassword = "s3cret-pass"
+
+// addTFAAdmin adds admin with TFA enabled and the recovery codes.
+func addTFAAdmin(t *testing.T, ar *Router, recoveryCodes ...string) model.Admin {
+	admin := model.Admin{Email: "tfa@example.com", Role: model.AdminRoleOwner, TFASecret: gotp.RandomSecret(16), TFAEnabled: true}
+	if err := admin.SetPassword(testAdminPassword); err != nil {
+		t.Fatalf("Error setting password: %s", err)
+	}
+	if err := admin.SetRecoveryCodes(recoveryCodes); err != nil {
+		t.Fatalf("Error setting recovery codes: %s", err)
+	}
+	admin, err := ar.adminStorage.AddAdmin(admin)
+	if err != nil {
+		t.Fatalf("Error adding admin: %s", err)
+	}
+	return admin
+}
+
+func loginWithTFA(ar *Router, tfaCode, recoveryCode string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(loginData{Email: "tfa@example.com", Password: testAdminPassword, TFACode: tfaCode, RecoveryCode: recoveryCode})
+	rec := httptest.NewRecorder()
+	ar.Login()(rec, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(string(body))))
+	return rec
+}
+
+// currentTFACode returns TOTP code of the admin, which is valid for at least a couple of seconds.
+func currentTFACode(admin model.Admin) string {
+	code, expiresAt := gotp.NewDefaultTOTP(admin.TFASecret).NowWithExpiration()
+	if left := time.Until(time.Unix(expiresAt, 0)); left < 2*time.Second {
+		time.Sleep(left)
+		code, _ = gotp.NewDefaultTOTP(admin.TFASecret).NowWithExpiration()
+	}
+	return code
+}
+
+func TestLoginTFA(t *testing.T) {
+	ar := newTestRouter(t)
+	admin := addTFAAdmin(t, ar, "recovery-1", "recovery-2")
+
+	if rec := loginWithTFA(ar, "", ""); rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), ErrorTFACodeRequired.Error()) {
+		t.Errorf("Login without code = %d %s, expected TFA code required", rec.Code, rec.Body.String())
+	}
+	code := currentTFACode(admin)
+	if rec := loginWithTFA(ar, code, ""); rec.Code != http.StatusOK {
+		t.Fatalf("Login with TFA code status = %d, expected %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec := loginWithTFA(ar, code, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Login with replayed TFA code status = %d, expected %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	if rec := loginWithTFA(ar, "", "recovery-2"); rec.Code != http.StatusOK {
+		t.Fatalf("Login with recovery code status = %d, expected %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec := loginWithTFA(ar, "", "recovery-2"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Login with used recovery code status = %d, expected %d", rec.Code, http.StatusUnauthorized)
+	}
+	if stored, _ := ar.adminStorage.AdminByID(admin.ID); len(stored.RecoveryCodeHashes) != 1 {
+		t.Errorf("Recovery codes left = %d, expected 1", len(stored.RecoveryCodeHashes))
+	}
+}
+
+func TestLoginTFAConcurrentReplay(t *testing.T) {
+	ar := newTestRouter(t)
+	admin := addTFAAdmin(t, ar)
+	code := currentTFACode(admin)
+
+	var wg sync.WaitGroup
+	statuses := make([]int, 5)
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i] = loginWithTFA(ar, code, "").Code
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, status := range statuses {
+		if status == http.StatusOK {
+			succeeded++
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("Logins with the same TFA code succeeded %d times, expected once: %v", succeeded, statuses)
+	}
+}
+
+func TestLoginTFARateLimit(t *testing.T) {
+	ar := newTestRouter(t)
+	admin := addTFAAdmin(t, ar, "recovery-1")
+
+	for i := 0; i < maxTFAFailures; i++ {
+		if rec := loginWithTFA(ar, "", "wrong"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Login with wrong code status = %d, expected %d", rec.Code, http.StatusUnauthorized)
+		}
+	}
+	if rec := loginWithTFA(ar, "", "recovery-1"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Login of blocked admin status = %d, expected %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if stored, _ := ar.adminStorage.AdminByID(admin.ID); len(stored.RecoveryCodeHashes) != 1 {
+		t.Errorf("Recovery code used while admin is blocked")
+	}
+
+	// The block expires after the window.
+	f := ar.tfaLimiter.failures[admin.ID]
+	f.since = time.Now().Add(-tfaFailuresWindow)
+	ar.tfaLimiter.failures[admin.ID] = f
+	if rec := loginWithTFA(ar, "", "recovery-1"); rec.Code != http.StatusOK {
+		t.Errorf("Login after the block status = %d, expected %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if _, ok := ar.tfaLimiter.failures[admin.ID]; ok {
+		t.Errorf("Failures are kept after successful TFA check")
+	}
+}
+
+func TestTFACodeEndpointsRejectReplay(t *testing.T) {
+	ar := newTestRouter(t)
+	admin := addTFAAdmin(t, ar)
+	code := currentTFACode(admin)
+
+	if rec := loginWithTFA(ar, code, ""); rec.Code != http.StatusOK {
+		t.Fatalf("Login with TFA code status = %d, expected %d", rec.Code, http.StatusOK)
+	}
+
+	// The code used for login cannot disable TFA.
+	stored, _ := ar.adminStorage.AdminByID(admin.ID)
+	req := httptest.NewRequest(http.MethodDelete, "/me/tfa", strings.NewReader(`{"tfa_code":"`+code+`"}`))
+	req = req.WithContext(context.WithValue(req.Context(), model.AdminContextKey, stored))
+	rec := httptest.NewRecorder()
+	ar.DisableTFA()(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("DisableTFA() with replayed code status = %d, expected %d", rec.Code, http.StatusBadRequest)
+	}
+	if stored, _ = ar.adminStorage.AdminByID(admin.ID); !stored.TFAEnabled {
+		t.Errorf("TFA disabled with replayed code")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/madappgang/identifo/model"
 )
@@ -11,14 +12,17 @@ import (
 const (
 	defaultUserSkip  = 0
 	defaultUserLimit = 20
+	// attributeFilterPrefix is a prefix of query parameters which filter users by attributes.
+	attributeFilterPrefix = "attr."
 )
 
 type registrationData struct {
-	Username   string        `json:"username,omitempty"`
-	Password   string        `json:"password,omitempty"`
-	AccessRole string        `json:"access_role,omitempty"`
-	Scope      []string      `json:"scope,omitempty"`
-	TFAInfo    model.TFAInfo `json:"tfa_info,omitempty"`
+	Username   string                 `json:"username,omitempty"`
+	Password   string                 `json:"password,omitempty"`
+	AccessRole string                 `json:"access_role,omitempty"`
+	Scope      []string               `json:"scope,omitempty"`
+	TFAInfo    model.TFAInfo          `json:"tfa_info,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
 }
 
 func (rd *registrationData) validate() error {
@@ -52,6 +56,7 @@ func (ar *Router) GetUser() http.HandlerFunc {
 }
 
 // FetchUsers fetches users from the database.
+// Users can be filtered by custom attributes with "attr.<name>=<value>" query parameters.
 func (ar *Router) FetchUsers() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		filterStr := strings.TrimSpace(r.URL.Query().Get("search"))
@@ -62,7 +67,13 @@ func (ar *Router) FetchUsers() http.HandlerFunc {
 			return
 		}
 
-		users, total, err := ar.userStorage.FetchUsers(filterStr, skip, limit)
+		attributes, err := ar.parseAttributesFilter(r)
+		if err != nil {
+			ar.Error(w, ErrorWrongInput, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		users, total, err := ar.userStorage.FetchUsers(filterStr, attributes, skip, limit)
 		if err != nil {
 			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
 			return
@@ -101,6 +112,11 @@ func (ar *Router) CreateUser() http.HandlerFunc {
 			return
 		}
 
+		if err := ar.userAttributes.ValidateAttributes(rd.Attributes); err != nil {
+			ar.Error(w, err, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		user, err := ar.userStorage.AddUserByNameAndPassword(rd.Username, rd.Password, rd.AccessRole, false)
 		if err != nil {
 			ar.Error(w, err, http.StatusBadRequest, "")
@@ -108,6 +124,7 @@ func (ar *Router) CreateUser() http.HandlerFunc {
 		}
 
 		user.SetTFAInfo(rd.TFAInfo)
+		user.SetAttributes(rd.Attributes)
 
 		user, err = ar.userStorage.UpdateUser(user.ID(), user)
 		if err != nil {
@@ -115,6 +132,8 @@ func (ar *Router) CreateUser() http.HandlerFunc {
 			return
 		}
 
+		ar.audit(r, "user.create", "user", user.ID(), nil, user)
+		ar.webhookService.Notify(model.WebhookEventUserCreated, "", user)
 		user.Sanitize()
 		ar.ServeJSON(w, http.StatusOK, user)
 	}
@@ -136,6 +155,11 @@ func (ar *Router) UpdateUser() http.HandlerFunc {
 			return
 		}
 
+		if err := ar.userAttributes.ValidateAttributes(u.Attributes()); err != nil {
+			ar.Error(w, err, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		if u.TFAInfo().IsEnabled == existing.TFAInfo().IsEnabled {
 			u.SetTFAInfo(model.TFAInfo{
 				IsEnabled: existing.TFAInfo().IsEnabled,
@@ -149,7 +173,12 @@ func (ar *Router) UpdateUser() http.HandlerFunc {
 			return
 		}
 
-		ar.logger.Printf("User %s updated", userID)
+		ar.logger.WithRequest(r).Infof("User %s updated", userID)
+		ar.audit(r, "user.update", "user", userID, existing, user)
+		ar.webhookService.Notify(model.WebhookEventUserUpdated, "", user)
+		if existing.Active() && !user.Active() {
+			ar.webhookService.Notify(model.WebhookEventUserDeactivated, "", user)
+		}
 
 		user.Sanitize()
 		ar.ServeJSON(w, http.StatusOK, user)
@@ -160,12 +189,125 @@ func (ar *Router) UpdateUser() http.HandlerFunc {
 func (ar *Router) DeleteUser() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID := getRouteVar("id", r)
+		before, _ := ar.userStorage.UserByID(userID)
+
 		if err := ar.userStorage.DeleteUser(userID); err != nil {
 			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
 			return
 		}
 
-		ar.logger.Printf("User %s deleted", userID)
+		// Deleted user cannot stay the member of organizations.
+		memberships, err := ar.organizationStorage.UserMemberships(userID)
+		if err != nil {
+			ar.logger.WithRequest(r).Errorf("Cannot fetch memberships of deleted user %s: %s", userID, err)
+		}
+		for _, m := range memberships {
+			if err = ar.organizationStorage.RemoveMember(m.OrgID, userID); err != nil {
+				ar.logger.WithRequest(r).Errorf("Cannot remove deleted user %s from organization %s: %s", userID, m.OrgID, err)
+			}
+		}
+
+		// Neither can they keep roles in the apps.
+		apps, _, err := ar.appStorage.FetchApps("", 0, 0)
+		if err != nil {
+			ar.logger.WithRequest(r).Errorf("Cannot fetch apps to unassign roles of deleted user %s: %s", userID, err)
+		}
+		for _, app := range apps {
+			if err = ar.roleStorage.SetUserRoles(app.ID(), userID, nil); err != nil {
+				ar.logger.WithRequest(r).Errorf("Cannot unassign roles of deleted user %s in app %s: %s", userID, app.ID(), err)
+			}
+		}
+
+		ar.logger.WithRequest(r).Infof("User %s deleted", userID)
+		ar.audit(r, "user.delete", "user", userID, before, nil)
+		if before != nil {
+			ar.webhookService.Notify(model.WebhookEventUserDeleted, "", before)
+		}
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// RevokeUserTokens logs user out everywhere.
+// It ends all user sessions and invalidates all user tokens issued so far, including untracked ones.
+func (ar *Router) RevokeUserTokens() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := getRouteVar("id", r)
+
+		if _, err := ar.userStorage.UserByID(userID); err != nil {
+			if err == model.ErrUserNotFound {
+				ar.Error(w, err, http.StatusNotFound, "")
+			} else {
+				ar.Error(w, err, http.StatusInternalServerError, "")
+			}
+			return
+		}
+
+		sessions, err := ar.userSessionStorage.FetchUserSessions(userID)
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+		for _, session := range sessions {
+			if err = ar.userSessionService.RevokeSession(session); err != nil {
+				ar.Error(w, err, http.StatusInternalServerError, "Revoking session")
+				return
+			}
+		}
+
+		// Tokens issued in the current second are revoked too, as tokens are issued with second precision.
+		if err = ar.userStorage.SetTokensValidAfter(userID, time.Now().Unix()+1); err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "Revoking tokens")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("User %s tokens revoked", userID)
+		ar.audit(r, "user.revoke_tokens", "user", userID, nil, nil)
 		ar.ServeJSON(w, http.StatusOK, nil)
 	}
 }
+
+// ResetUserTFA disables two-factor authentication of the user, so the user can set it up again,
+// e.g. when the device with authenticator app is lost.
+func (ar *Router) ResetUserTFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := getRouteVar("id", r)
+
+		user, err := ar.userStorage.UserByID(userID)
+		if err != nil {
+			if err == model.ErrUserNotFound {
+				ar.Error(w, err, http.StatusNotFound, "")
+			} else {
+				ar.Error(w, err, http.StatusInternalServerError, "")
+			}
+			return
+		}
+
+		before := user.TFAInfo()
+		user.SetTFAInfo(model.TFAInfo{})
+		if _, err = ar.userStorage.UpdateUser(userID, user); err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "Resetting TFA")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("User %s TFA reset by admin %s", userID, adminFromContext(r.Context()).ID)
+		ar.audit(r, "user.reset_tfa", "user", userID, before, model.TFAInfo{})
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// parseAttributesFilter converts "attr.<name>" query parameters to the typed attribute values.
+func (ar *Router) parseAttributesFilter(r *http.Request) (map[string]interface{}, error) {
+	attributes := make(map[string]interface{})
+	for key, values := range r.URL.Query() {
+		if !strings.HasPrefix(key, attributeFilterPrefix) || len(values) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(key, attributeFilterPrefix)
+		value, err := ar.userAttributes.ParseValue(name, values[0])
+		if err != nil {
+			return nil, err
+		}
+		attributes[name] = value
+	}
+	return attributes, nil
+}
@@ -0,0 +1,252 @@
+package admin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+)
+
+const (
+	defaultWebhookDeliveriesSkip  = 0
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 500
+)
+
+type webhookData struct {
+	AppID  string                   `json:"app_id"`
+	URL    string                   `json:"url"`
+	Events []model.WebhookEventType `json:"events"`
+	Active *bool                    `json:"active"`
+	// Secret is generated if empty on creation and kept if empty on update.
+	Secret string `json:"secret"`
+}
+
+// FetchWebhooks returns all webhooks, optionally only the ones of the app.
+func (ar *Router) FetchWebhooks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhooks, err := ar.webhookStorage.FetchWebhooks()
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		if appID := strings.TrimSpace(r.URL.Query().Get("app_id")); appID != "" {
+			appWebhooks := []model.Webhook{}
+			for _, webhook := range webhooks {
+				if webhook.AppID == appID {
+					appWebhooks = append(appWebhooks, webhook)
+				}
+			}
+			webhooks = appWebhooks
+		}
+		ar.ServeJSON(w, http.StatusOK, webhooks)
+	}
+}
+
+// CreateWebhook adds new webhook. Webhook without app ID receives events of all apps.
+func (ar *Router) CreateWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := webhookData{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+
+		webhook := model.Webhook{Active: true, CreatedAt: time.Now().Unix()}
+		if err := ar.applyWebhookData(&webhook, d); err != nil {
+			ar.Error(w, err, http.StatusBadRequest, err.Error())
+			return
+		}
+		if webhook.Secret == "" {
+			secret, err := generateWebhookSecret()
+			if err != nil {
+				ar.Error(w, err, http.StatusInternalServerError, "Cannot create webhook secret")
+				return
+			}
+			webhook.Secret = secret
+		}
+
+		webhook, err := ar.webhookStorage.AddWebhook(webhook)
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Webhook %s created", webhook.ID)
+		ar.audit(r, "webhook.create", "webhook", webhook.ID, nil, webhook)
+		ar.ServeJSON(w, http.StatusOK, webhook)
+	}
+}
+
+// GetWebhook returns webhook by ID.
+func (ar *Router) GetWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhook, err := ar.webhookStorage.WebhookByID(getRouteVar("id", r))
+		if err == model.ErrWebhookNotFound {
+			ar.Error(w, err, http.StatusNotFound, "")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, webhook)
+	}
+}
+
+// UpdateWebhook replaces webhook settings.
+func (ar *Router) UpdateWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		before, err := ar.webhookStorage.WebhookByID(getRouteVar("id", r))
+		if err == model.ErrWebhookNotFound {
+			ar.Error(w, err, http.StatusNotFound, "")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		d := webhookData{}
+		if ar.mustParseJSON(w, r, &d) != nil {
+			return
+		}
+
+		webhook := before
+		if err = ar.applyWebhookData(&webhook, d); err != nil {
+			ar.Error(w, err, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if webhook, err = ar.webhookStorage.UpdateWebhook(webhook); err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Webhook %s updated", webhook.ID)
+		ar.audit(r, "webhook.update", "webhook", webhook.ID, before, webhook)
+		ar.ServeJSON(w, http.StatusOK, webhook)
+	}
+}
+
+// DeleteWebhook deletes webhook. Its pending deliveries become dead on the next attempt.
+func (ar *Router) DeleteWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhookID := getRouteVar("id", r)
+		before, _ := ar.webhookStorage.WebhookByID(webhookID)
+
+		err := ar.webhookStorage.DeleteWebhook(webhookID)
+		if err == model.ErrWebhookNotFound {
+			ar.Error(w, err, http.StatusNotFound, "")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Webhook %s deleted", webhookID)
+		ar.audit(r, "webhook.delete", "webhook", webhookID, before, nil)
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// FetchWebhookDeliveries returns webhook deliveries, optionally filtered by webhook and status.
+// Dead deliveries are the ones which ran out of attempts.
+func (ar *Router) FetchWebhookDeliveries() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		skip, limit, err := ar.parseSkipAndLimit(r, defaultWebhookDeliveriesSkip, defaultWebhookDeliveriesLimit, maxWebhookDeliveriesLimit)
+		if err != nil {
+			ar.Error(w, ErrorWrongInput, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		filter := model.WebhookDeliveryFilter{
+			WebhookID: strings.TrimSpace(q.Get("webhook_id")),
+			Status:    model.WebhookDeliveryStatus(strings.TrimSpace(q.Get("status"))),
+		}
+
+		deliveries, total, err := ar.webhookStorage.FetchWebhookDeliveries(filter, skip, limit)
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		searchResponse := struct {
+			Deliveries []model.WebhookDelivery `json:"deliveries"`
+			Total      int                     `json:"total"`
+		}{
+			Deliveries: deliveries,
+			Total:      total,
+		}
+		ar.ServeJSON(w, http.StatusOK, &searchResponse)
+	}
+}
+
+// ReplayWebhookDelivery queues the delivery to be sent again.
+func (ar *Router) ReplayWebhookDelivery() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deliveryID := getRouteVar("id", r)
+
+		delivery, err := ar.webhookService.Replay(deliveryID)
+		if err == model.ErrWebhookDeliveryNotFound {
+			ar.Error(w, err, http.StatusNotFound, "")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorInternalError, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Webhook delivery %s replayed", deliveryID)
+		ar.audit(r, "webhook_delivery.replay", "webhook_delivery", deliveryID, nil, nil)
+		ar.ServeJSON(w, http.StatusOK, delivery)
+	}
+}
+
+// applyWebhookData validates the data and sets it to the webhook.
+func (ar *Router) applyWebhookData(webhook *model.Webhook, d webhookData) error {
+	u, err := url.Parse(strings.TrimSpace(d.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Webhook URL should be an absolute http or https URL")
+	}
+	for _, e := range d.Events {
+		if !e.IsValid() {
+			return fmt.Errorf("Unknown webhook event %s", e)
+		}
+	}
+
+	appID := strings.TrimSpace(d.AppID)
+	if appID != "" {
+		if _, err := ar.appStorage.AppByID(appID); err != nil {
+			return fmt.Errorf("App %s not found", appID)
+		}
+	}
+
+	webhook.AppID = appID
+	webhook.URL = u.String()
+	webhook.Events = d.Events
+	if d.Active != nil {
+		webhook.Active = *d.Active
+	}
+	if d.Secret != "" {
+		webhook.Secret = d.Secret
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/madappgang/identifo/metrics"
 	"github.com/madappgang/identifo/model"
 )
 
@@ -26,6 +27,7 @@ func NewRouter(sfs model.StaticFilesStorage, options ...func(*Router) error) (mo
 		}
 	}
 
+	apr.router.Use(metrics.RouteMiddleware)
 	apr.initRoutes()
 	return apr, nil
 }
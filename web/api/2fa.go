@@ -134,6 +134,7 @@ func (ar *Router) FinalizeTFA() http.HandlerFunc {
 		dontNeedVerification := app.DebugTFACode() != "" && d.TFACode == app.DebugTFACode()
 
 		if verified := totp.Verify(d.TFACode, int(time.Now().Unix())); !(verified || dontNeedVerification) {
+			ar.authFailed(r, model.AuthEventTFAVerification, model.TFAAuthMethod(ar.tfaType), user.ID(), model.AuthFailureInvalidCode)
 			ar.Error(w, ErrorAPIRequestTFACodeInvalid, http.StatusUnauthorized, "", "FinalizeTFA.TOTP_Invalid")
 			return
 		}
@@ -148,13 +149,13 @@ func (ar *Router) FinalizeTFA() http.HandlerFunc {
 		offline := contains(scopes, jwtService.OfflineScope)
 		accessToken, refreshToken, err := ar.loginUser(user, d.Scopes, app, offline, false)
 		if err != nil {
-			ar.Error(w, ErrorAPIAppAccessTokenNotCreated, http.StatusInternalServerError, err.Error(), "LoginWithPassword.loginUser")
+			ar.tokenNotCreated(w, err, http.StatusInternalServerError, "LoginWithPassword.loginUser")
 			return
 		}
 
 		// Blacklist old access token.
 		if err := ar.tokenBlacklist.Add(oldAccessTokenString); err != nil {
-			ar.logger.Printf("Cannot blacklist old access token: %s\n", err)
+			ar.logger.WithRequest(r).Errorf("Cannot blacklist old access token: %s\n", err)
 		}
 
 		user.Sanitize()
@@ -164,7 +165,11 @@ func (ar *Router) FinalizeTFA() http.HandlerFunc {
 			User:         user,
 		}
 
+		ar.startUserSession(r, user.ID(), app, accessToken, refreshToken)
 		ar.userStorage.UpdateLoginMetadata(user.ID())
+		ar.authSucceeded(r, model.AuthEventTFAVerification, model.TFAAuthMethod(ar.tfaType), user.ID())
+		// Only login with password can require two-factor authentication.
+		ar.authSucceeded(r, model.AuthEventLogin, model.AuthMethodPassword, user.ID())
 		ar.ServeJSON(w, http.StatusOK, result)
 	}
 }
@@ -29,7 +29,7 @@ func (ar *Router) SignatureHandler() negroni.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 		app := middleware.AppFromContext(r.Context())
 		if app == nil {
-			ar.logger.Println("Error getting App")
+			ar.logger.WithRequest(r).Error("Error getting App")
 			ar.Error(rw, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App id is not in request header params.", "SignatureHandler.AppFromContext")
 			return
 		}
@@ -37,7 +37,7 @@ func (ar *Router) SignatureHandler() negroni.HandlerFunc {
 		// Read request signature from header and decode it.
 		reqMAC := extractSignature(r.Header.Get(SignatureHeaderKey))
 		if reqMAC == nil {
-			ar.logger.Println("Error extracting signature")
+			ar.logger.WithRequest(r).Error("Error extracting signature")
 			ar.Error(rw, ErrorAPIRequestSignatureInvalid, http.StatusBadRequest, "", "SignatureHandler.extractSignature")
 			return
 		}
@@ -47,24 +47,24 @@ func (ar *Router) SignatureHandler() negroni.HandlerFunc {
 
 		if r.Method == "GET" {
 			body = []byte(r.URL.RequestURI() + t)
-			ar.logger.Println("RequestURI to sign:", r.URL.RequestURI()+t, "(GET request)")
+			ar.logger.WithRequest(r).Debug("RequestURI to sign:", r.URL.RequestURI()+t, "(GET request)")
 		} else {
 			// Extract body.
 			b, err := ioutil.ReadAll(r.Body)
 			if err != nil {
-				ar.logger.Printf("Error reading body: %v", err)
+				ar.logger.WithRequest(r).Errorf("Error reading body: %v", err)
 				ar.Error(rw, ErrorAPIRequestBodyInvalid, http.StatusBadRequest, err.Error(), "SignatureHandler.readBody")
 				return
 			}
 			if len(b) == 0 {
 				b = []byte(r.URL.RequestURI() + t)
-				ar.logger.Println("RequestURI to sign:", r.URL.RequestURI()+t, "(POST request)")
+				ar.logger.WithRequest(r).Debug("RequestURI to sign:", r.URL.RequestURI()+t, "(POST request)")
 			}
 			body = b
 		}
 
 		if err := validateBodySignature(body, reqMAC, []byte(app.Secret())); err != nil {
-			ar.logger.Printf("Error validating request signature: %v\n", err)
+			ar.logger.WithRequest(r).Errorf("Error validating request signature: %v\n", err)
 			ar.Error(rw, ErrorAPIRequestSignatureInvalid, http.StatusBadRequest, err.Error(), "SignatureHandler.validateBodySignature")
 			return
 		}
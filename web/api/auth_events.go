@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/middleware"
+)
+
+const (
+	defaultActivityLimit = 20
+	maxActivityLimit     = 100
+)
+
+// AuthActivity is an authentication event representation returned to the user.
+type AuthActivity struct {
+	Type      model.AuthEventType `json:"type"`
+	Method    model.AuthMethod    `json:"method,omitempty"`
+	Provider  string              `json:"provider,omitempty"`
+	Success   bool                `json:"success"`
+	AppID     string              `json:"app_id,omitempty"`
+	IP        string              `json:"ip,omitempty"`
+	UserAgent string              `json:"user_agent,omitempty"`
+	CreatedAt int64               `json:"created_at"`
+}
+
+// UserActivity returns recent authentication events of the current user, newest first.
+func (ar *Router) UserActivity() http.HandlerFunc {
+	type activityResponse struct {
+		Activity []AuthActivity `json:"activity"`
+		Total    int            `json:"total"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		skip, err := queryInt(r, "skip", 0)
+		if err != nil || skip < 0 {
+			ar.Error(w, ErrorAPIRequestBodyParamsInvalid, http.StatusBadRequest, "Invalid skip", "UserActivity.skip")
+			return
+		}
+		limit, err := queryInt(r, "limit", defaultActivityLimit)
+		if err != nil || limit <= 0 || limit > maxActivityLimit {
+			ar.Error(w, ErrorAPIRequestBodyParamsInvalid, http.StatusBadRequest, "Invalid limit", "UserActivity.limit")
+			return
+		}
+
+		filter := model.AuthEventFilter{UserID: tokenFromContext(r.Context()).UserID()}
+		events, total, err := ar.authEventStorage.FetchAuthEvents(filter, skip, limit)
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "UserActivity.FetchAuthEvents")
+			return
+		}
+
+		result := activityResponse{Activity: make([]AuthActivity, len(events)), Total: total}
+		for i, e := range events {
+			result.Activity[i] = AuthActivity{
+				Type:      e.Type,
+				Method:    e.Method,
+				Provider:  e.Provider,
+				Success:   e.Success,
+				AppID:     e.AppID,
+				IP:        e.IP,
+				UserAgent: e.UserAgent,
+				CreatedAt: e.CreatedAt,
+			}
+		}
+		ar.ServeJSON(w, http.StatusOK, result)
+	}
+}
+
+// authSucceeded records successful authentication step.
+func (ar *Router) authSucceeded(r *http.Request, eventType model.AuthEventType, method model.AuthMethod, userID string) {
+	ar.recordAuthEvent(r, model.AuthEvent{Type: eventType, Method: method, UserID: userID, Success: true})
+}
+
+// authFailed records failed authentication step. User ID is empty if the user is not known.
+func (ar *Router) authFailed(r *http.Request, eventType model.AuthEventType, method model.AuthMethod, userID string, reason model.AuthFailureReason) {
+	ar.recordAuthEvent(r, model.AuthEvent{Type: eventType, Method: method, UserID: userID, FailureReason: reason})
+}
+
+// recordAuthEvent fills request details of the event and records it.
+func (ar *Router) recordAuthEvent(r *http.Request, event model.AuthEvent) {
+	if app := middleware.AppFromContext(r.Context()); app != nil {
+		event.AppID = app.ID()
+	}
+	event.IP = middleware.ClientIP(r)
+	event.UserAgent = r.UserAgent()
+	ar.authEventService.Record(event)
+}
+
+func queryInt(r *http.Request, name string, defaultValue int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madappgang/identifo/model"
+)
+
+func TestUserActivity(t *testing.T) {
+	ar := newTestRouter(t)
+	user, err := ar.userStorage.AddUserByNameAndPassword("user@example.com", "pass", "user", false)
+	if err != nil {
+		t.Fatalf("Error adding user: %s", err)
+	}
+	token := newAccessToken(t, ar, user, testApp("app"))
+
+	ar.authEventStorage.AddAuthEvent(model.AuthEvent{Type: model.AuthEventLogin, Method: model.AuthMethodPassword, UserID: user.ID(), FailureReason: model.AuthFailureInvalidCredentials, CreatedAt: 1})
+	ar.authEventStorage.AddAuthEvent(model.AuthEvent{Type: model.AuthEventLogin, Method: model.AuthMethodPassword, UserID: user.ID(), AppID: "app", IP: "203.0.113.7", UserAgent: "agent", Success: true, CreatedAt: 2})
+	ar.authEventStorage.AddAuthEvent(model.AuthEvent{Type: model.AuthEventLogin, UserID: "another-user", Success: true, CreatedAt: 3})
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{"all", "", http.StatusOK, 2},
+		{"limited", "?limit=1", http.StatusOK, 1},
+		{"skipped", "?skip=1", http.StatusOK, 1},
+		{"limit over maximum", "?limit=101", http.StatusBadRequest, 0},
+		{"negative skip", "?skip=-1", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			ar.UserActivity()(rec, withToken(httptest.NewRequest(http.MethodGet, "/me/activity"+tt.query, nil), token))
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Status = %d, expected %d: %s", rec.Code, tt.expectedStatus, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				Activity []AuthActivity `json:"activity"`
+				Total    int            `json:"total"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("Error decoding activity: %s", err)
+			}
+			if result.Total != 2 || len(result.Activity) != tt.expectedCount {
+				t.Errorf("Activity = %d of %d, expected %d of 2", len(result.Activity), result.Total, tt.expectedCount)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	ar.UserActivity()(rec, withToken(httptest.NewRequest(http.MethodGet, "/me/activity", nil), token))
+	var result struct {
+		Activity []AuthActivity `json:"activity"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	expected := AuthActivity{Type: model.AuthEventLogin, Method: model.AuthMethodPassword, Success: true, AppID: "app", IP: "203.0.113.7", UserAgent: "agent", CreatedAt: 2}
+	if len(result.Activity) != 2 || result.Activity[0] != expected || result.Activity[1].Success {
+		t.Errorf("Activity = %+v, expected the newest event first", result.Activity)
+	}
+
+	// Internal details are not returned to the user.
+	for _, field := range []string{`"id"`, `"user_id"`, `"failure_reason"`, user.ID()} {
+		if strings.Contains(rec.Body.String(), field) {
+			t.Errorf("Activity contains %s: %s", field, rec.Body.String())
+		}
+	}
+}
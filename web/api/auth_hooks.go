@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+
+	jwtService "github.com/madappgang/identifo/jwt/service"
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/middleware"
+)
+
+// preRegistrationHook lets the auth hook endpoint deny creation of the new user.
+func (ar *Router) preRegistrationHook(r *http.Request, req model.AuthHookRequest) error {
+	req.Hook = model.AuthHookPreRegistration
+	return ar.callAuthHook(r, req)
+}
+
+// preLoginHook lets the auth hook endpoint deny the login of the authenticated user.
+func (ar *Router) preLoginHook(r *http.Request, user model.User, method model.AuthMethod, provider model.FederatedIdentityProvider, scopes []string) error {
+	return ar.callAuthHook(r, model.AuthHookRequest{
+		Hook:     model.AuthHookPreLogin,
+		UserID:   user.ID(),
+		Username: user.Username(),
+		Email:    user.Email(),
+		Phone:    user.Phone(),
+		Role:     user.AccessRole(),
+		Method:   method,
+		Provider: provider,
+		Scopes:   scopes,
+	})
+}
+
+// callAuthHook fills request details of the hook request and calls it.
+func (ar *Router) callAuthHook(r *http.Request, req model.AuthHookRequest) error {
+	if app := middleware.AppFromContext(r.Context()); app != nil {
+		req.AppID = app.ID()
+	}
+	req.IP = middleware.ClientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	_, err := ar.authHookService.Call(req)
+	return err
+}
+
+// tokenNotCreated reports failed token issue. Token denied by the pre-token hook
+// or for the organization the user is no longer a member of is not an internal error.
+func (ar *Router) tokenNotCreated(w http.ResponseWriter, err error, status int, where string) {
+	if err == model.ErrAuthHookDenied || err == jwtService.ErrNotOrganizationMember {
+		ar.Error(w, ErrorAPIAppAccessDenied, http.StatusForbidden, err.Error(), where)
+		return
+	}
+	ar.Error(w, ErrorAPIAppAccessTokenNotCreated, status, err.Error(), where)
+}
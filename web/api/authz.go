@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/authorization"
+	"github.com/madappgang/identifo/web/middleware"
+)
+
+// CheckAuthorization tells the resource server whether the subject can perform the action on the object,
+// according to the authz model and policy of the requesting app.
+func (ar *Router) CheckAuthorization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := authorization.CheckRequest{}
+		if ar.MustParseJSON(w, r, &req) != nil {
+			return
+		}
+
+		result, ok := ar.checkAuthorization(w, middleware.AppFromContext(r.Context()), req)
+		if !ok {
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, result)
+	}
+}
+
+// CheckAuthorizationBatch evaluates several checks at once, results are in the order of the checks.
+func (ar *Router) CheckAuthorizationBatch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := authorization.BatchCheckRequest{}
+		if ar.MustParseJSON(w, r, &req) != nil {
+			return
+		}
+
+		app := middleware.AppFromContext(r.Context())
+		response := authorization.BatchCheckResult{Results: make([]authorization.CheckResult, len(req.Checks))}
+		for i, check := range req.Checks {
+			result, ok := ar.checkAuthorization(w, app, check)
+			if !ok {
+				return
+			}
+			response.Results[i] = result
+		}
+		ar.ServeJSON(w, http.StatusOK, response)
+	}
+}
+
+// checkAuthorization evaluates the check, writing error response on failure.
+func (ar *Router) checkAuthorization(w http.ResponseWriter, app model.AppData, req authorization.CheckRequest) (authorization.CheckResult, bool) {
+	if app.AuthzModel() == "" {
+		ar.Error(w, ErrorAPIAppAuthzModelEmpty, http.StatusBadRequest, "", "checkAuthorization.AuthzModel")
+		return authorization.CheckResult{}, false
+	}
+
+	subjects := []string{}
+	if req.Subject != "" {
+		subjects = append(subjects, req.Subject)
+	}
+	if req.UserID != "" {
+		user, err := ar.userStorage.UserByID(req.UserID)
+		if err == model.ErrUserNotFound {
+			ar.Error(w, ErrorAPIUserNotFound, http.StatusNotFound, req.UserID, "checkAuthorization.UserByID")
+			return authorization.CheckResult{}, false
+		}
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "checkAuthorization.UserByID")
+			return authorization.CheckResult{}, false
+		}
+		roles, err := ar.Authorizer.UserRoles(app, user.ID(), user.AccessRole())
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "checkAuthorization.UserRoles")
+			return authorization.CheckResult{}, false
+		}
+		subjects = append(append(subjects, user.ID()), roles...)
+	}
+
+	result, err := ar.Authorizer.Check(app, subjects, req.Object, req.Action)
+	if _, ok := err.(authorization.EvaluationError); ok {
+		ar.Error(w, ErrorAPIAppAuthzModelInvalid, http.StatusBadRequest, err.Error(), "checkAuthorization.Check")
+		return authorization.CheckResult{}, false
+	}
+	if err != nil {
+		ar.Error(w, ErrorAPIAppCannotInitAuthorizer, http.StatusInternalServerError, err.Error(), "checkAuthorization.Check")
+		return authorization.CheckResult{}, false
+	}
+	return result, true
+}
@@ -3,7 +3,6 @@ package api
 import (
 	"fmt"
 	"net/http"
-	"strings"
 
 	jwtService "github.com/madappgang/identifo/jwt/service"
 	"github.com/madappgang/identifo/model"
@@ -12,29 +11,27 @@ import (
 )
 
 // FederatedLoginData represents federated login input data.
+// Providers use different credentials: Facebook expects access token, Apple expects authorization code,
+// Google and OIDC accept either ID token or authorization code, GitHub accepts either access token or authorization code.
 type FederatedLoginData struct {
 	FederatedIDProvider string   `json:"provider,omitempty" validate:"required"`
 	AccessToken         string   `json:"access_token,omitempty"`
+	IDToken             string   `json:"id_token,omitempty"`
 	RegisterIfNew       bool     `json:"register_if_new,omitempty"`
 	Scopes              []string `json:"scopes,omitempty"`
-	AuthorizationCode   string   `json:"authorization_code,omitempty"` // Specific for Sign In with Apple.
+	AuthorizationCode   string   `json:"authorization_code,omitempty"`
+	RedirectURI         string   `json:"redirect_uri,omitempty"` // Must match the one used to obtain authorization code.
+	Nonce               string   `json:"nonce,omitempty"`
 }
 
 // FederatedLogin provides login/registration with federated identity.
-// First, user sends the identity provider access token to Identifo.
-// Then, Identifo sends request to identity provider to get user profile and identity user ID,
+// First, user sends the identity provider credentials to Identifo.
+// Then, Identifo asks the identity provider registered under the requested name for the user identity,
 // and then search for the user with this federated identity ID in the user pool.
 // If there is no user with such identity, function returns 404 (user not found).
 // If register_if_new presents - function creates new user without username/password,
 // there is a dedicated endpoint to link username/password to federated account.
 func (ar *Router) FederatedLogin() http.HandlerFunc {
-	var federatedProviders = map[string]bool{
-		strings.ToLower(string(model.FacebookIDProvider)): true,
-		strings.ToLower(string(model.AppleIDProvider)):    true,
-		strings.ToLower(string(model.GoogleIDProvider)):   false, //TODO: add later
-		strings.ToLower(string(model.TwitterIDProvider)):  false, //TODO: add later
-	}
-
 	return func(w http.ResponseWriter, r *http.Request) {
 		if !ar.SupportedLoginWays.Federated {
 			ar.Error(w, ErrorAPIAppFederatedLoginNotSupported, http.StatusBadRequest, "Application does not support federated login", "FederatedLogin.supportedLoginWays")
@@ -46,53 +43,71 @@ func (ar *Router) FederatedLogin() http.HandlerFunc {
 			return
 		}
 
-		if !federatedProviders[strings.ToLower(d.FederatedIDProvider)] {
-			ar.logger.Println("Federated provider is not supported:", d.FederatedIDProvider)
-			ar.Error(w, ErrorAPIAppFederatedProviderNotSupported, http.StatusBadRequest, fmt.Sprintf("UnsupportedProvider: %v", d.FederatedIDProvider), "FederatedLogin.federatedProviders[]")
+		fid, provider, err := ar.federatedProviders.Provider(d.FederatedIDProvider)
+		if err != nil {
+			ar.logger.WithRequest(r).Warn("Federated provider is not supported:", d.FederatedIDProvider)
+			ar.Error(w, ErrorAPIAppFederatedProviderNotSupported, http.StatusBadRequest, fmt.Sprintf("UnsupportedProvider: %v", d.FederatedIDProvider), "FederatedLogin.federatedProviders")
 			return
 		}
 
 		app := middleware.AppFromContext(r.Context())
 		if app == nil {
-			ar.logger.Println("Error getting App")
+			ar.logger.WithRequest(r).Error("Error getting App")
 			ar.Error(w, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App id is not specified.", "FederatedLogin.AppFromContext")
 			return
 		}
 
-		var federatedID string
-		var err error
+		// recordAuthEvent records successful event if there is no failure reason.
+		recordAuthEvent := func(eventType model.AuthEventType, userID string, reason model.AuthFailureReason) {
+			ar.recordAuthEvent(r, model.AuthEvent{
+				Type:          eventType,
+				Method:        model.AuthMethodFederated,
+				Provider:      string(fid),
+				UserID:        userID,
+				Success:       reason == "",
+				FailureReason: reason,
+			})
+		}
 
-		fid := model.FederatedIdentityProvider(strings.ToUpper(d.FederatedIDProvider))
-		switch fid {
-		case model.FacebookIDProvider:
-			federatedID, err = ar.FacebookUserID(d.AccessToken)
-		case model.AppleIDProvider:
-			if app.AppleInfo() == nil {
-				ar.logger.Println("Empty apple info")
-				ar.Error(w, ErrorAPIAppFederatedProviderEmptyAppleInfo, http.StatusBadRequest, "App does not have Apple info.", "FederatedLogin.switch_providers_apple")
-				return
-			}
-			federatedID, err = ar.AppleUserID(d.AuthorizationCode, app.AppleInfo())
-		default:
-			ar.Error(w, ErrorAPIAppFederatedProviderNotSupported, http.StatusBadRequest, fmt.Sprintf("UnsupportedProvider: %v", fid), "FederatedLogin.switch_providers_default")
+		identity, err := provider.Identity(app, model.FederatedCredentials{
+			AccessToken:       d.AccessToken,
+			AuthorizationCode: d.AuthorizationCode,
+			IDToken:           d.IDToken,
+			RedirectURI:       d.RedirectURI,
+			Nonce:             d.Nonce,
+		})
+		if err == model.ErrFederatedProviderNotConfigured {
+			ar.Error(w, ErrorAPIAppFederatedProviderNotConfigured, http.StatusBadRequest, fmt.Sprintf("App is not configured for %v", fid), "FederatedLogin.Identity")
 			return
 		}
-
 		if err != nil {
-			ar.logger.Println("Error getting federated user ID:", err)
-			ar.Error(w, ErrorAPIAppFederatedProviderEmptyUserID, http.StatusBadRequest, err.Error(), "FederatedLogin.switch_providers.err")
+			recordAuthEvent(model.AuthEventLogin, "", model.AuthFailureProviderError)
+			ar.logger.WithRequest(r).Error("Error getting federated user ID:", err)
+			ar.Error(w, ErrorAPIAppFederatedProviderEmptyUserID, http.StatusBadRequest, err.Error(), "FederatedLogin.Identity")
 			return
 		}
+		federatedID := identity.ID
 
 		user, err := ar.userStorage.UserByFederatedID(fid, federatedID)
 		// Check error not found, create new user.
 		if err == model.ErrUserNotFound && d.RegisterIfNew {
+			if err = ar.preRegistrationHook(r, model.AuthHookRequest{
+				Role:     app.NewUserDefaultRole(),
+				Method:   model.AuthMethodFederated,
+				Provider: fid,
+			}); err != nil {
+				recordAuthEvent(model.AuthEventRegistration, "", model.AuthFailureHookDenied)
+				ar.Error(w, ErrorAPIAppAccessDenied, http.StatusForbidden, err.Error(), "FederatedLogin.preRegistrationHook")
+				return
+			}
 			user, err = ar.userStorage.AddUserWithFederatedID(fid, federatedID, app.NewUserDefaultRole())
 			if err != nil {
 				ar.Error(w, ErrorAPIUserUnableToCreate, http.StatusInternalServerError, err.Error(), "FederatedLogin.UserByFederatedID.RegisterNew")
 				return
 			}
+			recordAuthEvent(model.AuthEventRegistration, user.ID(), "")
 		} else if err == model.ErrUserNotFound && !d.RegisterIfNew {
+			recordAuthEvent(model.AuthEventLogin, "", model.AuthFailureUserNotFound)
 			ar.Error(w, ErrorAPIUserNotFound, http.StatusNotFound, err.Error(), "FederatedLogin.UserByFederatedID.NotRegisterNew")
 			return
 		} else if err != nil {
@@ -103,11 +118,13 @@ func (ar *Router) FederatedLogin() http.HandlerFunc {
 		// Authorize user if the app requires authorization.
 		azi := authorization.AuthzInfo{
 			App:         app,
+			UserID:      user.ID(),
 			UserRole:    user.AccessRole(),
 			ResourceURI: r.RequestURI,
 			Method:      r.Method,
 		}
 		if err := ar.Authorizer.Authorize(azi); err != nil {
+			recordAuthEvent(model.AuthEventLogin, user.ID(), model.AuthFailureAccessDenied)
 			ar.Error(w, ErrorAPIAppAccessDenied, http.StatusForbidden, err.Error(), "FederatedLogin.Authorizer")
 			return
 		}
@@ -115,14 +132,21 @@ func (ar *Router) FederatedLogin() http.HandlerFunc {
 		// Request permissions for the user.
 		scopes, err := ar.userStorage.RequestScopes(user.ID(), d.Scopes)
 		if err != nil {
+			recordAuthEvent(model.AuthEventLogin, user.ID(), model.AuthFailureScopesForbidden)
 			ar.Error(w, ErrorAPIRequestScopesForbidden, http.StatusBadRequest, err.Error(), "FederatedLogin.RequestScopes")
 			return
 		}
 
+		if err = ar.preLoginHook(r, user, model.AuthMethodFederated, fid, scopes); err != nil {
+			recordAuthEvent(model.AuthEventLogin, user.ID(), model.AuthFailureHookDenied)
+			ar.Error(w, ErrorAPIAppAccessDenied, http.StatusForbidden, err.Error(), "FederatedLogin.preLoginHook")
+			return
+		}
+
 		// Generate access token.
 		token, err := ar.tokenService.NewAccessToken(user, scopes, app, false)
 		if err != nil {
-			ar.Error(w, ErrorAPIAppAccessTokenNotCreated, http.StatusUnauthorized, err.Error(), "FederatedLogin.tokenService_NewToken")
+			ar.tokenNotCreated(w, err, http.StatusUnauthorized, "FederatedLogin.tokenService_NewToken")
 			return
 		}
 		tokenString, err := ar.tokenService.String(token)
@@ -153,7 +177,9 @@ func (ar *Router) FederatedLogin() http.HandlerFunc {
 			User:         user,
 		}
 
+		ar.startUserSession(r, user.ID(), app, tokenString, refreshString)
 		ar.userStorage.UpdateLoginMetadata(user.ID())
+		recordAuthEvent(model.AuthEventLogin, user.ID(), "")
 		ar.ServeJSON(w, http.StatusOK, result)
 	}
 
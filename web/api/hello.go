@@ -13,7 +13,7 @@ func (ar *Router) HandleHello() http.HandlerFunc {
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		ar.logger.Println("trace Hello handler")
+		ar.logger.WithRequest(r).Debug("trace Hello handler")
 		hello := helloResponse{
 			Answer: "Hello, my name is Identifo",
 			Date:   time.Now(),
@@ -30,7 +30,7 @@ func (ar *Router) HandlePing() http.HandlerFunc {
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		ar.logger.Println("trace pong handler")
+		ar.logger.WithRequest(r).Debug("trace pong handler")
 		pong := pongResponse{
 			Message: "Pong!",
 			Date:    time.Now(),
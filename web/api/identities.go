@@ -0,0 +1,321 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/middleware"
+)
+
+// Identities describes all the login methods linked to the user.
+type Identities struct {
+	Username  string              `json:"username,omitempty"`
+	Password  bool                `json:"password"`
+	Phone     string              `json:"phone,omitempty"`
+	Federated []FederatedIdentity `json:"federated"`
+}
+
+// FederatedIdentity is a federated identity linked to the user.
+type FederatedIdentity struct {
+	Provider string `json:"provider"`
+	ID       string `json:"id"`
+}
+
+// LinkPhoneData represents phone number linking input data.
+type LinkPhoneData struct {
+	PhoneNumber string `json:"phone_number"`
+	Code        string `json:"code"`
+}
+
+// LinkPasswordData represents username and password linking input data.
+type LinkPasswordData struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password"`
+}
+
+// Identities returns login methods linked to the current user.
+func (ar *Router) Identities() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := ar.identitiesUser(w, r)
+		if !ok {
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, identitiesOf(user))
+	}
+}
+
+// LinkFederatedIdentity links federated identity to the current user.
+// Identity provider credentials are verified the same way as for the federated login.
+func (ar *Router) LinkFederatedIdentity() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !ar.SupportedLoginWays.Federated {
+			ar.Error(w, ErrorAPIAppFederatedLoginNotSupported, http.StatusBadRequest, "Application does not support federated login", "LinkFederatedIdentity.supportedLoginWays")
+			return
+		}
+
+		d := FederatedLoginData{}
+		if ar.MustParseJSON(w, r, &d) != nil {
+			return
+		}
+
+		fid, provider, err := ar.federatedProviders.Provider(d.FederatedIDProvider)
+		if err != nil {
+			ar.Error(w, ErrorAPIAppFederatedProviderNotSupported, http.StatusBadRequest, fmt.Sprintf("UnsupportedProvider: %v", d.FederatedIDProvider), "LinkFederatedIdentity.federatedProviders")
+			return
+		}
+
+		app := middleware.AppFromContext(r.Context())
+		if app == nil {
+			ar.Error(w, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App id is not specified.", "LinkFederatedIdentity.AppFromContext")
+			return
+		}
+
+		identity, err := provider.Identity(app, model.FederatedCredentials{
+			AccessToken:       d.AccessToken,
+			AuthorizationCode: d.AuthorizationCode,
+			IDToken:           d.IDToken,
+			RedirectURI:       d.RedirectURI,
+			Nonce:             d.Nonce,
+		})
+		if err == model.ErrFederatedProviderNotConfigured {
+			ar.Error(w, ErrorAPIAppFederatedProviderNotConfigured, http.StatusBadRequest, fmt.Sprintf("App is not configured for %v", fid), "LinkFederatedIdentity.Identity")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorAPIAppFederatedProviderEmptyUserID, http.StatusBadRequest, err.Error(), "LinkFederatedIdentity.Identity")
+			return
+		}
+
+		userID := tokenFromContext(r.Context()).UserID()
+		if err = ar.userStorage.LinkFederatedID(userID, fid, identity.ID); err != nil {
+			ar.identityLinkError(w, err, "LinkFederatedIdentity.LinkFederatedID")
+			return
+		}
+		ar.serveIdentities(w, r, userID)
+	}
+}
+
+// UnlinkFederatedIdentity removes federated identity from the current user.
+// If user has several identities of the same provider, the one to remove is specified with "id" query parameter.
+func (ar *Router) UnlinkFederatedIdentity() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := ar.identitiesUser(w, r)
+		if !ok {
+			return
+		}
+
+		fid := model.FederatedIdentityProvider(strings.ToUpper(mux.Vars(r)["provider"]))
+		id := r.URL.Query().Get("id")
+
+		linked := []string{}
+		for _, key := range user.FederatedIDs() {
+			p, pid := model.ParseFederatedIDKey(key)
+			if p == fid && (id == "" || id == pid) {
+				linked = append(linked, pid)
+			}
+		}
+		if len(linked) == 0 {
+			ar.Error(w, ErrorAPIIdentityNotFound, http.StatusNotFound, fmt.Sprintf("User has no linked %v identity", fid), "UnlinkFederatedIdentity.FederatedIDs")
+			return
+		}
+		if len(linked) > 1 {
+			ar.Error(w, ErrorAPIRequestBodyParamsInvalid, http.StatusBadRequest, "Several identities are linked, specify id", "UnlinkFederatedIdentity.FederatedIDs")
+			return
+		}
+		if loginMethodsCount(user) <= 1 {
+			ar.Error(w, ErrorAPIIdentityLast, http.StatusBadRequest, "", "UnlinkFederatedIdentity.loginMethodsCount")
+			return
+		}
+
+		if err := ar.userStorage.UnlinkFederatedID(user.ID(), fid, linked[0]); err != nil {
+			ar.identityLinkError(w, err, "UnlinkFederatedIdentity.UnlinkFederatedID")
+			return
+		}
+		ar.serveIdentities(w, r, user.ID())
+	}
+}
+
+// LinkPhone links phone number to the current user.
+// Phone ownership is proven with the code requested from /auth/request_phone_code.
+func (ar *Router) LinkPhone() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := LinkPhoneData{}
+		if ar.MustParseJSON(w, r, &d) != nil {
+			return
+		}
+		pl := PhoneLogin{PhoneNumber: d.PhoneNumber, Code: d.Code}
+		if err := pl.validateCodeAndPhone(); err != nil {
+			ar.Error(w, ErrorAPIRequestBodyParamsInvalid, http.StatusBadRequest, err.Error(), "LinkPhone.validateCodeAndPhone")
+			return
+		}
+
+		app := middleware.AppFromContext(r.Context())
+		if app == nil {
+			ar.Error(w, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App is not in context.", "LinkPhone.AppFromContext")
+			return
+		}
+
+		needVerification := app.DebugTFACode() == "" || d.Code != app.DebugTFACode()
+		if needVerification {
+			if exists, err := ar.verificationCodeStorage.IsVerificationCodeFound(d.PhoneNumber, d.Code); err != nil {
+				ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "LinkPhone.IsVerificationCodeFound.error")
+				return
+			} else if !exists {
+				ar.Error(w, ErrorAPIVerificationCodeInvalid, http.StatusUnauthorized, "Invalid phone or verification code", "LinkPhone.IsVerificationCodeFound.not_exists")
+				return
+			}
+		}
+
+		userID := tokenFromContext(r.Context()).UserID()
+		if err := ar.userStorage.LinkPhone(userID, d.PhoneNumber); err != nil {
+			ar.identityLinkError(w, err, "LinkPhone.LinkPhone")
+			return
+		}
+		ar.serveIdentities(w, r, userID)
+	}
+}
+
+// UnlinkPhone removes phone number from the current user.
+func (ar *Router) UnlinkPhone() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := ar.identitiesUser(w, r)
+		if !ok {
+			return
+		}
+		if user.Phone() == "" {
+			ar.Error(w, ErrorAPIIdentityNotFound, http.StatusNotFound, "User has no linked phone number", "UnlinkPhone.Phone")
+			return
+		}
+		if loginMethodsCount(user) <= 1 {
+			ar.Error(w, ErrorAPIIdentityLast, http.StatusBadRequest, "", "UnlinkPhone.loginMethodsCount")
+			return
+		}
+
+		if err := ar.userStorage.UnlinkPhone(user.ID()); err != nil {
+			ar.identityLinkError(w, err, "UnlinkPhone.UnlinkPhone")
+			return
+		}
+		ar.serveIdentities(w, r, user.ID())
+	}
+}
+
+// LinkPassword sets username and password for the current user, e.g. the one registered with federated identity.
+// Empty username keeps the current one. Changing existing password is done with PUT /me.
+func (ar *Router) LinkPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := LinkPasswordData{}
+		if ar.MustParseJSON(w, r, &d) != nil {
+			return
+		}
+
+		user, ok := ar.identitiesUser(w, r)
+		if !ok {
+			return
+		}
+		if user.PasswordHash() != "" {
+			ar.Error(w, ErrorAPIIdentityTaken, http.StatusBadRequest, "User already has password", "LinkPassword.PasswordHash")
+			return
+		}
+		if d.Username == "" && user.Username() == "" {
+			ar.Error(w, ErrorAPIRequestBodyParamsInvalid, http.StatusBadRequest, "Username is not specified", "LinkPassword.Username")
+			return
+		}
+		if err := model.StrongPswd(d.Password); err != nil {
+			ar.Error(w, ErrorAPIRequestPasswordWeak, http.StatusBadRequest, err.Error(), "LinkPassword.StrongPswd")
+			return
+		}
+
+		if err := ar.userStorage.LinkPassword(user.ID(), d.Username, d.Password); err != nil {
+			if err == model.ErrorUserExists {
+				ar.Error(w, ErrorAPIUsernameTaken, http.StatusBadRequest, "", "LinkPassword.LinkPassword")
+				return
+			}
+			ar.identityLinkError(w, err, "LinkPassword.LinkPassword")
+			return
+		}
+		ar.serveIdentities(w, r, user.ID())
+	}
+}
+
+// UnlinkPassword removes password from the current user.
+func (ar *Router) UnlinkPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := ar.identitiesUser(w, r)
+		if !ok {
+			return
+		}
+		if user.PasswordHash() == "" {
+			ar.Error(w, ErrorAPIIdentityNotFound, http.StatusNotFound, "User has no password", "UnlinkPassword.PasswordHash")
+			return
+		}
+		if loginMethodsCount(user) <= 1 {
+			ar.Error(w, ErrorAPIIdentityLast, http.StatusBadRequest, "", "UnlinkPassword.loginMethodsCount")
+			return
+		}
+
+		if err := ar.userStorage.UnlinkPassword(user.ID()); err != nil {
+			ar.identityLinkError(w, err, "UnlinkPassword.UnlinkPassword")
+			return
+		}
+		ar.serveIdentities(w, r, user.ID())
+	}
+}
+
+func (ar *Router) identitiesUser(w http.ResponseWriter, r *http.Request) (model.User, bool) {
+	userID := tokenFromContext(r.Context()).UserID()
+	user, err := ar.userStorage.UserByID(userID)
+	if err != nil {
+		ar.Error(w, ErrorAPIUserNotFound, http.StatusUnauthorized, err.Error(), "Identities.UserByID")
+		return nil, false
+	}
+	return user, true
+}
+
+func (ar *Router) serveIdentities(w http.ResponseWriter, r *http.Request, userID string) {
+	user, err := ar.userStorage.UserByID(userID)
+	if err != nil {
+		ar.Error(w, ErrorAPIUserNotFound, http.StatusUnauthorized, err.Error(), "Identities.UserByID")
+		return
+	}
+	ar.ServeJSON(w, http.StatusOK, identitiesOf(user))
+}
+
+func (ar *Router) identityLinkError(w http.ResponseWriter, err error, details string) {
+	switch err {
+	case model.ErrorUserExists:
+		ar.Error(w, ErrorAPIIdentityTaken, http.StatusBadRequest, "", details)
+	case model.ErrUserNotFound:
+		ar.Error(w, ErrorAPIUserNotFound, http.StatusNotFound, err.Error(), details)
+	default:
+		ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), details)
+	}
+}
+
+func identitiesOf(user model.User) Identities {
+	ids := Identities{
+		Username:  user.Username(),
+		Password:  user.PasswordHash() != "",
+		Phone:     user.Phone(),
+		Federated: []FederatedIdentity{},
+	}
+	for _, key := range user.FederatedIDs() {
+		p, id := model.ParseFederatedIDKey(key)
+		ids.Federated = append(ids.Federated, FederatedIdentity{Provider: strings.ToLower(string(p)), ID: id})
+	}
+	return ids
+}
+
+// loginMethodsCount returns how many ways user has to log in. It must never go down to zero.
+func loginMethodsCount(user model.User) int {
+	n := len(user.FederatedIDs())
+	if user.PasswordHash() != "" {
+		n++
+	}
+	if user.Phone() != "" {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/madappgang/identifo/model"
+)
+
+// testIdentityVerifier confirms identity with the ID sent as the access token.
+type testIdentityVerifier struct{}
+
+func (testIdentityVerifier) Identity(app model.AppData, credentials model.FederatedCredentials) (model.FederatedIdentity, error) {
+	if credentials.AccessToken == "" {
+		return model.FederatedIdentity{}, errors.New("invalid credentials")
+	}
+	return model.FederatedIdentity{ID: credentials.AccessToken}, nil
+}
+
+func newIdentitiesTestRouter(t *testing.T) *Router {
+	ar := newTestRouter(t)
+	ar.SupportedLoginWays = model.LoginWith{Federated: true}
+	ar.federatedProviders = model.NewFederatedProviderRegistry()
+	ar.federatedProviders.Register("TEST", testIdentityVerifier{})
+	return ar
+}
+
+func decodeIdentities(t *testing.T, rec *httptest.ResponseRecorder) Identities {
+	var ids Identities
+	if err := json.Unmarshal(rec.Body.Bytes(), &ids); err != nil {
+		t.Fatalf("Error decoding identities: %s", err)
+	}
+	return ids
+}
+
+func TestLinkFederatedIdentity(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"new identity", `{"provider":"test","access_token":"id-1"}`, http.StatusOK},
+		{"identity of another user", `{"provider":"test","access_token":"taken"}`, http.StatusBadRequest},
+		{"invalid credentials", `{"provider":"test"}`, http.StatusBadRequest},
+		{"unsupported provider", `{"provider":"unknown","access_token":"id-1"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar := newIdentitiesTestRouter(t)
+			app := testApp("app")
+			user, _ := ar.userStorage.AddUserByNameAndPassword("user@example.com", "pass", "user", false)
+			other, _ := ar.userStorage.AddUserByNameAndPassword("other@example.com", "pass", "user", false)
+			ar.userStorage.LinkFederatedID(other.ID(), "TEST", "taken")
+
+			req := httptest.NewRequest(http.MethodPost, "/me/identities/federated", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			ar.LinkFederatedIdentity()(rec, withToken(withApp(req, app), newAccessToken(t, ar, user, app)))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Status = %d, expected %d: %s", rec.Code, tt.expectedStatus, rec.Body.String())
+			}
+			if owner, _ := ar.userStorage.UserByFederatedID("TEST", "taken"); owner.ID() != other.ID() {
+				t.Error("Identity of another user is relinked")
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			ids := decodeIdentities(t, rec)
+			if len(ids.Federated) != 1 || ids.Federated[0] != (FederatedIdentity{Provider: "test", ID: "id-1"}) {
+				t.Errorf("Federated identities = %+v, expected the linked one", ids.Federated)
+			}
+			if linked, err := ar.userStorage.UserByFederatedID("TEST", "id-1"); err != nil || linked.ID() != user.ID() {
+				t.Errorf("Identity is not linked to the user: %v", err)
+			}
+		})
+	}
+}
+
+func TestUnlinkFederatedIdentity(t *testing.T) {
+	tests := []struct {
+		name           string
+		password       bool
+		linked         []string
+		id             string
+		expectedStatus int
+		expectedLeft   int
+	}{
+		{"identity with password", true, []string{"id-1"}, "", http.StatusOK, 0},
+		{"last login method", false, []string{"id-1"}, "", http.StatusBadRequest, 1},
+		{"not linked identity", true, nil, "", http.StatusNotFound, 0},
+		{"several identities without id", true, []string{"id-1", "id-2"}, "", http.StatusBadRequest, 2},
+		{"several identities with id", true, []string{"id-1", "id-2"}, "id-2", http.StatusOK, 1},
+		{"another id", true, []string{"id-1"}, "id-2", http.StatusNotFound, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar := newIdentitiesTestRouter(t)
+			app := testApp("app")
+
+			var user model.User
+			if tt.password {
+				user, _ = ar.userStorage.AddUserByNameAndPassword("user@example.com", "pass", "user", false)
+			} else {
+				user, _ = ar.userStorage.AddUserWithFederatedID("TEST", tt.linked[0], "user")
+			}
+			for _, id := range tt.linked {
+				if err := ar.userStorage.LinkFederatedID(user.ID(), "TEST", id); err != nil {
+					t.Fatalf("Error linking identity: %s", err)
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/me/identities/federated/test?id="+tt.id, nil)
+			req = mux.SetURLVars(withToken(withApp(req, app), newAccessToken(t, ar, user, app)), map[string]string{"provider": "test"})
+			rec := httptest.NewRecorder()
+			ar.UnlinkFederatedIdentity()(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Status = %d, expected %d: %s", rec.Code, tt.expectedStatus, rec.Body.String())
+			}
+			stored, _ := ar.userStorage.UserByID(user.ID())
+			if left := len(stored.FederatedIDs()); left != tt.expectedLeft {
+				t.Errorf("Linked identities = %v, expected %d", stored.FederatedIDs(), tt.expectedLeft)
+			}
+			if tt.expectedStatus == http.StatusOK && len(decodeIdentities(t, rec).Federated) != tt.expectedLeft {
+				t.Errorf("Response identities are not updated: %s", rec.Body.String())
+			}
+		})
+	}
+}
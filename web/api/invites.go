@@ -6,27 +6,65 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
+	jwtService "github.com/madappgang/identifo/jwt/service"
 	"github.com/madappgang/identifo/model"
 	"github.com/madappgang/identifo/web/middleware"
 )
 
-// RequestInviteLink requests invite link. Invite link will be returned in response even if email is not specified.
+// RequestInviteLink creates invite for the email and sends invite link to it.
+// Invite link is also returned in response.
+// Invite grants only the requested scopes of the app and does not allow registration if it is forbidden in the app.
 func (ar *Router) RequestInviteLink() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		d := struct {
-			Email string `json:"email"`
+			Email  string   `json:"email"`
+			Scopes []string `json:"scopes,omitempty"`
 		}{}
 		if err := ar.MustParseJSON(w, r, &d); err != nil {
 			ar.Error(w, ErrorAPIRequestBodyInvalid, http.StatusBadRequest, err.Error(), "RequestInviteLink.MustParseJSON")
 			return
 		}
-		if d.Email != "" && !model.EmailRegexp.MatchString(d.Email) {
+		d.Email = strings.ToLower(strings.TrimSpace(d.Email))
+		if !model.EmailRegexp.MatchString(d.Email) {
 			ar.Error(w, ErrorAPIRequestBodyInvalid, http.StatusBadRequest, "", "RequestInviteLink.emailRegexp_MatchString")
 			return
 		}
 
-		inviteToken, err := ar.tokenService.NewInviteToken()
+		app := middleware.AppFromContext(r.Context())
+		if app == nil {
+			ar.Error(w, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App is not in context.", "RequestInviteLink.AppFromContext")
+			return
+		}
+		for _, scope := range d.Scopes {
+			if !contains(app.Scopes(), scope) {
+				ar.Error(w, ErrorAPIRequestScopesForbidden, http.StatusBadRequest, "Scope "+scope+" is not allowed in the app.", "RequestInviteLink.Scopes")
+				return
+			}
+		}
+
+		lifespan := app.InviteTokenLifespan()
+		if lifespan == 0 {
+			lifespan = jwtService.InviteTokenLifespan
+		}
+		now := time.Now().Unix()
+
+		invite, err := ar.inviteStorage.AddInvite(model.Invite{
+			AppID:     app.ID(),
+			Email:     d.Email,
+			Role:      app.NewUserDefaultRole(),
+			Scopes:    d.Scopes,
+			InvitedBy: tokenFromContext(r.Context()).UserID(),
+			CreatedAt: now,
+			ExpiresAt: now + lifespan,
+		})
+		if err != nil {
+			ar.Error(w, ErrorAPIInviteTokenServerError, http.StatusInternalServerError, err.Error(), "RequestInviteLink.AddInvite")
+			return
+		}
+
+		inviteToken, err := ar.tokenService.NewInviteToken(invite)
 		if err != nil {
 			ar.Error(w, ErrorAPIInviteTokenServerError, http.StatusInternalServerError, err.Error(), "RequestInviteLink.NewInviteToken")
 			return
@@ -38,32 +76,36 @@ func (ar *Router) RequestInviteLink() http.HandlerFunc {
 			return
 		}
 
-		app := middleware.AppFromContext(r.Context())
-		scopes := strings.Replace(fmt.Sprintf("%q", app.Scopes()), " ", ",", -1)
-		query := url.PathEscape(fmt.Sprintf("appId=%s&scopes=%s&token=%s", app.ID(), scopes, inviteTokenString))
-
-		host, err := url.Parse(ar.Host)
+		link, err := ar.inviteLink(app, invite.Scopes, inviteTokenString)
 		if err != nil {
-			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "RequestInviteLink.URL_parse")
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "RequestInviteLink.inviteLink")
 			return
 		}
 
-		u := &url.URL{
-			Scheme:   host.Scheme,
-			Host:     host.Host,
-			Path:     path.Join(ar.WebRouterPrefix, "register"),
-			RawQuery: query,
-		}
-
-		// Send email only if it's specified.
-		if d.Email != "" {
-			err = ar.emailService.SendInviteEmail("Invitation", d.Email, u.String())
-			if err != nil {
-				ar.Error(w, ErrorAPIEmailNotSent, http.StatusInternalServerError, err.Error(), "RequestInviteLink.SendInviteEmail")
-				return
-			}
+		if err = ar.emailService.SendInviteEmail("Invitation", d.Email, link); err != nil {
+			ar.Error(w, ErrorAPIEmailNotSent, http.StatusInternalServerError, err.Error(), "RequestInviteLink.SendInviteEmail")
+			return
 		}
-		result := map[string]string{"link": u.String()}
+		result := map[string]string{"id": invite.ID, "link": link}
 		ar.ServeJSON(w, http.StatusOK, result)
 	}
 }
+
+// inviteLink returns the link to the registration page with the invite token and the scopes it grants.
+func (ar *Router) inviteLink(app model.AppData, inviteScopes []string, inviteTokenString string) (string, error) {
+	scopes := strings.Replace(fmt.Sprintf("%q", inviteScopes), " ", ",", -1)
+	query := url.PathEscape(fmt.Sprintf("appId=%s&scopes=%s&token=%s", app.ID(), scopes, inviteTokenString))
+
+	host, err := url.Parse(ar.Host)
+	if err != nil {
+		return "", err
+	}
+
+	u := &url.URL{
+		Scheme:   host.Scheme,
+		Host:     host.Host,
+		Path:     path.Join(ar.WebRouterPrefix, "register"),
+		RawQuery: query,
+	}
+	return u.String(), nil
+}
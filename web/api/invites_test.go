@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madappgang/identifo/external_services/mail/mock"
+)
+
+func TestRequestInviteLink(t *testing.T) {
+	ar := newTestRouter(t)
+	ar.emailService = mock.NewEmailService()
+	ar.Host = "https://auth.example.com"
+	ar.WebRouterPrefix = "/web"
+
+	app := testApp("app")
+	user, err := ar.userStorage.AddUserByNameAndPassword("user@example.com", "pass", "user", false)
+	if err != nil {
+		t.Fatalf("Error adding user: %s", err)
+	}
+	token := newAccessToken(t, ar, user, app)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedScopes []string
+	}{
+		{"no scopes", `{"email":"friend@example.com"}`, http.StatusOK, nil},
+		{"app scopes", `{"email":"friend@example.com","scopes":["offline"]}`, http.StatusOK, []string{"offline"}},
+		{"scopes not allowed in the app", `{"email":"friend@example.com","scopes":["admin"]}`, http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := withApp(httptest.NewRequest(http.MethodPost, "/auth/invite", strings.NewReader(tt.body)), app)
+			rec := httptest.NewRecorder()
+			ar.RequestInviteLink()(rec, withToken(r, token))
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Status = %d, expected %d: %s", rec.Code, tt.expectedStatus, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var result map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("Error decoding response: %s", err)
+			}
+			invite, err := ar.inviteStorage.InviteByID(result["id"])
+			if err != nil {
+				t.Fatalf("Error getting invite: %s", err)
+			}
+			if invite.ByAdmin || invite.InvitedBy != user.ID() {
+				t.Errorf("Invite = %+v, expected invited by the user", invite)
+			}
+			if strings.Join(invite.Scopes, ",") != strings.Join(tt.expectedScopes, ",") {
+				t.Errorf("Invite scopes = %v, expected %v", invite.Scopes, tt.expectedScopes)
+			}
+		})
+	}
+}
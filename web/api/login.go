@@ -65,19 +65,22 @@ func (ar *Router) LoginWithPassword() http.HandlerFunc {
 
 		user, err := ar.userStorage.UserByNamePassword(ld.Username, ld.Password)
 		if err != nil {
+			userID, _ := ar.userStorage.IDByName(ld.Username)
+			ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPassword, userID, model.AuthFailureInvalidCredentials)
 			ar.Error(w, ErrorAPIRequestIncorrectEmailOrPassword, http.StatusUnauthorized, err.Error(), "LoginWithPassword.UserByNamePassword")
 			return
 		}
 
 		scopes, err := ar.userStorage.RequestScopes(user.ID(), ld.Scopes)
 		if err != nil {
+			ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPassword, user.ID(), model.AuthFailureScopesForbidden)
 			ar.Error(w, ErrorAPIRequestScopesForbidden, http.StatusForbidden, err.Error(), "LoginWithPassword.RequestScopes")
 			return
 		}
 
 		app := middleware.AppFromContext(r.Context())
 		if app == nil {
-			ar.logger.Println("Error getting App")
+			ar.logger.WithRequest(r).Error("Error getting App")
 			ar.Error(w, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App is not in context.", "LoginWithPassword.AppFromContext")
 			return
 		}
@@ -85,25 +88,34 @@ func (ar *Router) LoginWithPassword() http.HandlerFunc {
 		// Authorize user if the app requires authorization.
 		azi := authorization.AuthzInfo{
 			App:         app,
+			UserID:      user.ID(),
 			UserRole:    user.AccessRole(),
 			ResourceURI: r.RequestURI,
 			Method:      r.Method,
 		}
 		if err := ar.Authorizer.Authorize(azi); err != nil {
+			ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPassword, user.ID(), model.AuthFailureAccessDenied)
 			ar.Error(w, ErrorAPIAppAccessDenied, http.StatusForbidden, err.Error(), "LoginWithPassword.Authorizer")
 			return
 		}
 
+		if err := ar.preLoginHook(r, user, model.AuthMethodPassword, "", scopes); err != nil {
+			ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPassword, user.ID(), model.AuthFailureHookDenied)
+			ar.Error(w, ErrorAPIAppAccessDenied, http.StatusForbidden, err.Error(), "LoginWithPassword.preLoginHook")
+			return
+		}
+
 		// Check if we should require user to authenticate with 2FA.
 		require2FA, err := ar.check2FA(w, app.TFAStatus(), user.TFAInfo())
 		if err != nil {
+			ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPassword, user.ID(), model.AuthFailureTFAPolicy)
 			return
 		}
 
 		offline := contains(scopes, jwtService.OfflineScope)
 		accessToken, refreshToken, err := ar.loginUser(user, scopes, app, offline, require2FA)
 		if err != nil {
-			ar.Error(w, ErrorAPIAppAccessTokenNotCreated, http.StatusInternalServerError, err.Error(), "LoginWithPassword.loginUser")
+			ar.tokenNotCreated(w, err, http.StatusInternalServerError, "LoginWithPassword.loginUser")
 			return
 		}
 
@@ -117,11 +129,15 @@ func (ar *Router) LoginWithPassword() http.HandlerFunc {
 			user.Sanitize()
 			result.User = user
 
+			ar.startUserSession(r, user.ID(), app, accessToken, refreshToken)
 			ar.userStorage.UpdateLoginMetadata(user.ID())
+			ar.authSucceeded(r, model.AuthEventLogin, model.AuthMethodPassword, user.ID())
 			ar.ServeJSON(w, http.StatusOK, result)
 			return
 		}
 
+		// Login is finished with FinalizeTFA.
+		ar.authSucceeded(r, model.AuthEventTFAChallenge, model.TFAAuthMethod(ar.tfaType), user.ID())
 		totp := gotp.NewDefaultTOTP(user.TFAInfo().Secret).Now()
 
 		user.Sanitize()
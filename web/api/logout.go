@@ -24,7 +24,7 @@ func (ar *Router) Logout() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		accessTokenBytes, ok := r.Context().Value(model.TokenRawContextKey).([]byte)
 		if !ok {
-			ar.logger.Println("Cannot fetch access token bytes from context")
+			ar.logger.WithRequest(r).Error("Cannot fetch access token bytes from context")
 			ar.ServeJSON(w, http.StatusNoContent, nil)
 			return
 		}
@@ -32,9 +32,13 @@ func (ar *Router) Logout() http.HandlerFunc {
 
 		// Blacklist current access token.
 		if err := ar.tokenBlacklist.Add(accessTokenString); err != nil {
-			ar.logger.Printf("Cannot blacklist access token: %s\n", err)
+			ar.logger.WithRequest(r).Errorf("Cannot blacklist access token: %s\n", err)
 		}
 
+		// End the session, so all tokens issued by it get invalidated.
+		ar.endUserSession(accessTokenString)
+		ar.authSucceeded(r, model.AuthEventLogout, "", tokenFromContext(r.Context()).UserID())
+
 		if r.Body == http.NoBody {
 			ar.ServeJSON(w, http.StatusOK, response)
 			return
@@ -47,14 +51,14 @@ func (ar *Router) Logout() http.HandlerFunc {
 
 		// Revoke refresh token, if present.
 		if err := ar.revokeRefreshToken(d.RefreshToken, accessTokenString); err != nil {
-			ar.logger.Printf("Cannot revoke refresh token: %s\n", err)
+			ar.logger.WithRequest(r).Errorf("Cannot revoke refresh token: %s\n", err)
 		}
 
 		// Detach device token, if present.
 		if len(d.DeviceToken) > 0 {
 			// TODO: check for ownership when device tokens are supported.
 			if err := ar.userStorage.DetachDeviceToken(d.DeviceToken); err != nil {
-				ar.logger.Println("Cannot detach device token")
+				ar.logger.WithRequest(r).Error("Cannot detach device token")
 			}
 		}
 
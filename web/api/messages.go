@@ -43,13 +43,25 @@ var messages = map[MessageID]string{
 	ErrorAPIAppRefreshTokenNotCreated:          "Unable to create refresh token",
 	ErrorAPIAppCannotExtractTokenSubject:       "Unable to extract Subject claim from token",
 	ErrorAPIAppCannotInitAuthorizer:            "Unable to init internal authorizer",
+	ErrorAPIAppAuthzModelEmpty:                 "App has no authorization model",
+	ErrorAPIAppAuthzModelInvalid:               "App authorization model cannot evaluate the check",
 	ErrorAPIAppFederatedProviderNotSupported:   "Federated provider is not supported",
 	ErrorAPIAppFederatedProviderEmptyUserID:    "Federated provider returns empty user ID",
 	ErrorAPIAppFederatedProviderEmptyAppleInfo: "Application does not have Apple info",
+	ErrorAPIAppFederatedProviderNotConfigured:  "Application is not configured for federated provider",
 	ErrorAPIAppFederatedLoginNotSupported:      "Login with federated identity provider is not supported by app",
 	ErrorAPIAppLoginWithUsernameNotSupported:   "Login with username is not supported by app",
 	ErrorAPIAppPhoneLoginNotSupported:          "Login with phone number is not supported by app",
 	ErrorAPIAppAccessDenied:                    "Access denied",
+	ErrorAPIIdentityLast:                       "Cannot remove the last login method",
+	ErrorAPIIdentityTaken:                      "Identity is already linked",
+	ErrorAPIIdentityNotFound:                   "Identity is not linked to the user",
+	ErrorAPIUserNotAnonymous:                   "User is not anonymous",
+	ErrorAPIUserSessionNotFound:                "Session not found",
+	ErrorAPIOrganizationNotFound:               "Organization not found",
+	ErrorAPIOrganizationForbidden:              "Not enough rights in the organization",
+	ErrorAPIOrganizationLastOwner:              "Cannot remove the last owner of the organization",
+	ErrorAPIInviteEmailMismatch:                "Invite was sent to another email",
 }
 
 const (
@@ -63,6 +75,8 @@ const (
 	ErrorAPIVerificationCodeInvalid = "error.api.verification_code.invalid"
 	// ErrorAPIUserNotFound is when user not found.
 	ErrorAPIUserNotFound = "error.api.user.not_found"
+	// ErrorAPIUserNotAnonymous is when registered user tries to upgrade their account.
+	ErrorAPIUserNotAnonymous = "error.api.user.not_anonymous"
 	// ErrorAPIUsernameTaken is when username is already taken.
 	ErrorAPIUsernameTaken = "error.api.username.taken"
 	// ErrorAPIEmailTaken is when email is already taken.
@@ -126,6 +140,10 @@ const (
 	ErrorAPIAppCannotExtractTokenSubject = "error.api.request.token.sub"
 	// ErrorAPIAppCannotInitAuthorizer is when we cannot init internal authorizer.
 	ErrorAPIAppCannotInitAuthorizer = "error.api.request.authorizer.internal.init"
+	// ErrorAPIAppAuthzModelEmpty is when the app has no authz model to check authorization with.
+	ErrorAPIAppAuthzModelEmpty = "error.api.app.authz_model.empty"
+	// ErrorAPIAppAuthzModelInvalid is when the app authz model fails evaluating the check, like when its matcher is invalid.
+	ErrorAPIAppAuthzModelInvalid = "error.api.app.authz_model.invalid"
 
 	// ErrorAPIAppFederatedProviderNotSupported means that the federated ID provider is not supported.
 	ErrorAPIAppFederatedProviderNotSupported = "api.app.federated.provider.not_supported"
@@ -133,6 +151,8 @@ const (
 	ErrorAPIAppFederatedProviderEmptyUserID = "api.app.federated.provider.empty_user_id"
 	// ErrorAPIAppFederatedProviderEmptyAppleInfo means that application does not have clientID and clientSecret needed for Sign In with Apple.
 	ErrorAPIAppFederatedProviderEmptyAppleInfo = "api.app.federated.provider.empty_apple_info"
+	// ErrorAPIAppFederatedProviderNotConfigured means that application does not have client credentials for the federated provider.
+	ErrorAPIAppFederatedProviderNotConfigured = "api.app.federated.provider.not_configured"
 
 	// ErrorAPIAppFederatedLoginNotSupported means that the app does not support federated login.
 	ErrorAPIAppFederatedLoginNotSupported = "api.app.federated.login.not_supported"
@@ -140,4 +160,23 @@ const (
 	ErrorAPIAppLoginWithUsernameNotSupported = "api.app.username.login.not_supported"
 	// ErrorAPIAppPhoneLoginNotSupported means that the app does not support login by phone number.
 	ErrorAPIAppPhoneLoginNotSupported = "api.app.phone.login.not_supported"
+
+	// ErrorAPIIdentityLast means that user tries to remove the only login method they have.
+	ErrorAPIIdentityLast = "error.api.identity.last"
+	// ErrorAPIIdentityTaken means that the identity is already linked to this or another user.
+	ErrorAPIIdentityTaken = "error.api.identity.taken"
+	// ErrorAPIIdentityNotFound means that the identity is not linked to the user.
+	ErrorAPIIdentityNotFound = "error.api.identity.not_found"
+
+	// ErrorAPIUserSessionNotFound means that the session does not exist or belongs to another user.
+	ErrorAPIUserSessionNotFound = "error.api.user_session.not_found"
+
+	// ErrorAPIOrganizationNotFound means that the organization does not exist or the user is not its member.
+	ErrorAPIOrganizationNotFound = "error.api.organization.not_found"
+	// ErrorAPIOrganizationForbidden means that the member role does not allow the action.
+	ErrorAPIOrganizationForbidden = "error.api.organization.forbidden"
+	// ErrorAPIOrganizationLastOwner means that the action would leave the organization without owners.
+	ErrorAPIOrganizationLastOwner = "error.api.organization.last_owner"
+	// ErrorAPIInviteEmailMismatch means that the invite was sent to another email than the one of the user.
+	ErrorAPIInviteEmailMismatch = "error.api.invite.email_mismatch"
 )
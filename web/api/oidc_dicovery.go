@@ -130,10 +130,10 @@ func (ar *Router) OIDCJwks() http.HandlerFunc {
 func (ar *Router) ServeADDAFile() http.HandlerFunc {
 	data, err := ar.staticFilesStorage.GetAppleFile(model.AppleFilenames.DeveloperDomainAssociation)
 	if err != nil {
-		ar.logger.Fatalln("Cannot read Apple Domain Association file path:", err)
+		ar.logger.Fatal("Cannot read Apple Domain Association file path:", err)
 	}
 	if data == nil {
-		ar.logger.Println("Apple Developer Domain Association file does not exist, so won't be served.")
+		ar.logger.Info("Apple Developer Domain Association file does not exist, so won't be served.")
 		return func(w http.ResponseWriter, r *http.Request) { ar.ServeJSON(w, http.StatusNotFound, nil) }
 	}
 
@@ -148,10 +148,10 @@ func (ar *Router) ServeADDAFile() http.HandlerFunc {
 func (ar *Router) ServeAASAFile() http.HandlerFunc {
 	data, err := ar.staticFilesStorage.GetAppleFile(model.AppleFilenames.AppSiteAssociation)
 	if err != nil {
-		ar.logger.Fatalln("Cannot read Apple App Site Association file path:", err)
+		ar.logger.Fatal("Cannot read Apple App Site Association file path:", err)
 	}
 	if data == nil {
-		ar.logger.Println("Apple App Site Association file does not exist, so won't be served.")
+		ar.logger.Info("Apple App Site Association file does not exist, so won't be served.")
 		return func(w http.ResponseWriter, r *http.Request) { ar.ServeJSON(w, http.StatusNotFound, nil) }
 	}
 
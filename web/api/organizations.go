@@ -0,0 +1,463 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	jwtService "github.com/madappgang/identifo/jwt/service"
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/authorization"
+	"github.com/madappgang/identifo/web/middleware"
+)
+
+// OrganizationMembership is a membership of the current user with the organization details.
+type OrganizationMembership struct {
+	model.Organization
+	Role     string `json:"role"`
+	JoinedAt int64  `json:"joined_at"`
+}
+
+// UserOrganizations returns organizations the current user is a member of.
+func (ar *Router) UserOrganizations() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := tokenFromContext(r.Context()).UserID()
+
+		memberships, err := ar.organizationStorage.UserMemberships(userID)
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "UserOrganizations.UserMemberships")
+			return
+		}
+
+		result := make([]OrganizationMembership, 0, len(memberships))
+		for _, m := range memberships {
+			org, err := ar.organizationStorage.OrganizationByID(m.OrgID)
+			if err == model.ErrOrganizationNotFound {
+				continue
+			}
+			if err != nil {
+				ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "UserOrganizations.OrganizationByID")
+				return
+			}
+			result = append(result, OrganizationMembership{Organization: org, Role: m.Role, JoinedAt: m.JoinedAt})
+		}
+		ar.ServeJSON(w, http.StatusOK, result)
+	}
+}
+
+// CreateOrganization creates new organization with the current user as its owner.
+func (ar *Router) CreateOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := struct {
+			Name string `json:"name"`
+		}{}
+		if ar.MustParseJSON(w, r, &d) != nil {
+			return
+		}
+		d.Name = strings.TrimSpace(d.Name)
+		if d.Name == "" {
+			ar.Error(w, ErrorAPIRequestBodyParamsInvalid, http.StatusBadRequest, "Organization name is empty", "CreateOrganization.validate")
+			return
+		}
+
+		userID := tokenFromContext(r.Context()).UserID()
+		now := time.Now().Unix()
+
+		org, err := ar.organizationStorage.AddOrganization(model.Organization{Name: d.Name, CreatedBy: userID, CreatedAt: now})
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "CreateOrganization.AddOrganization")
+			return
+		}
+
+		member, err := ar.organizationStorage.SetMember(model.OrganizationMember{
+			OrgID:    org.ID,
+			UserID:   userID,
+			Role:     model.OrganizationRoleOwner,
+			JoinedAt: now,
+		})
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "CreateOrganization.SetMember")
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, OrganizationMembership{Organization: org, Role: member.Role, JoinedAt: member.JoinedAt})
+	}
+}
+
+// OrganizationTokens switches the current user to the organization.
+// It issues access and, if requested, refresh tokens with the organization ID and the member role.
+func (ar *Router) OrganizationTokens() http.HandlerFunc {
+	type requestData struct {
+		Scopes []string `json:"scopes,omitempty"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		rd := requestData{}
+		if ar.MustParseJSON(w, r, &rd) != nil {
+			return
+		}
+
+		app := middleware.AppFromContext(r.Context())
+		if app == nil {
+			ar.Error(w, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App is not in context.", "OrganizationTokens.AppFromContext")
+			return
+		}
+
+		member, ok := ar.currentOrganizationMember(w, r, "OrganizationTokens")
+		if !ok {
+			return
+		}
+
+		user, err := ar.userStorage.UserByID(member.UserID)
+		if err != nil {
+			ar.Error(w, ErrorAPIUserNotFound, http.StatusUnauthorized, err.Error(), "OrganizationTokens.UserByID")
+			return
+		}
+
+		scopes, err := ar.userStorage.RequestScopes(user.ID(), rd.Scopes)
+		if err != nil {
+			ar.Error(w, ErrorAPIRequestScopesForbidden, http.StatusForbidden, err.Error(), "OrganizationTokens.RequestScopes")
+			return
+		}
+
+		// Authorize user in the organization if the app requires authorization.
+		azi := authorization.AuthzInfo{
+			App:         app,
+			UserID:      user.ID(),
+			UserRole:    user.AccessRole(),
+			OrgID:       member.OrgID,
+			OrgRole:     member.Role,
+			ResourceURI: r.RequestURI,
+			Method:      r.Method,
+		}
+		if err := ar.Authorizer.Authorize(azi); err != nil {
+			ar.Error(w, ErrorAPIAppAccessDenied, http.StatusForbidden, err.Error(), "OrganizationTokens.Authorizer")
+			return
+		}
+
+		accessToken, err := ar.tokenService.NewOrgAccessToken(user, scopes, app, member.OrgID)
+		if err != nil {
+			ar.tokenNotCreated(w, err, http.StatusInternalServerError, "OrganizationTokens.NewOrgAccessToken")
+			return
+		}
+		result := AuthResponse{}
+		if result.AccessToken, err = ar.tokenService.String(accessToken); err != nil {
+			ar.Error(w, ErrorAPIAppAccessTokenNotCreated, http.StatusInternalServerError, err.Error(), "OrganizationTokens.String")
+			return
+		}
+
+		if contains(scopes, jwtService.OfflineScope) {
+			refreshToken, err := ar.tokenService.NewOrgRefreshToken(user, scopes, app, member.OrgID)
+			if err != nil {
+				ar.Error(w, ErrorAPIAppRefreshTokenNotCreated, http.StatusInternalServerError, err.Error(), "OrganizationTokens.NewOrgRefreshToken")
+				return
+			}
+			if result.RefreshToken, err = ar.tokenService.String(refreshToken); err != nil {
+				ar.Error(w, ErrorAPIAppRefreshTokenNotCreated, http.StatusInternalServerError, err.Error(), "OrganizationTokens.String")
+				return
+			}
+		}
+
+		ar.startUserSession(r, user.ID(), app, result.AccessToken, result.RefreshToken)
+		ar.ServeJSON(w, http.StatusOK, result)
+	}
+}
+
+// OrganizationMembers returns members of the organization the current user is a member of.
+func (ar *Router) OrganizationMembers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		member, ok := ar.currentOrganizationMember(w, r, "OrganizationMembers")
+		if !ok {
+			return
+		}
+
+		members, err := ar.organizationStorage.FetchMembers(member.OrgID)
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "OrganizationMembers.FetchMembers")
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, members)
+	}
+}
+
+// InviteToOrganization creates the invite to join the organization and sends it to the email.
+// Only owners and admins can invite, and only owners can invite other owners.
+func (ar *Router) InviteToOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := struct {
+			Email string `json:"email"`
+			Role  string `json:"role,omitempty"`
+		}{}
+		if ar.MustParseJSON(w, r, &d) != nil {
+			return
+		}
+		d.Email = strings.ToLower(strings.TrimSpace(d.Email))
+		if !model.EmailRegexp.MatchString(d.Email) {
+			ar.Error(w, ErrorAPIRequestBodyEmailInvalid, http.StatusBadRequest, "", "InviteToOrganization.emailRegexp_MatchString")
+			return
+		}
+		if d.Role == "" {
+			d.Role = model.OrganizationRoleMember
+		}
+
+		app := middleware.AppFromContext(r.Context())
+		if app == nil {
+			ar.Error(w, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App is not in context.", "InviteToOrganization.AppFromContext")
+			return
+		}
+
+		member, ok := ar.currentOrganizationMember(w, r, "InviteToOrganization")
+		if !ok {
+			return
+		}
+		if !canAssignOrganizationRole(member, "", d.Role) {
+			ar.Error(w, ErrorAPIOrganizationForbidden, http.StatusForbidden, "", "InviteToOrganization.canAssignOrganizationRole")
+			return
+		}
+
+		lifespan := app.InviteTokenLifespan()
+		if lifespan == 0 {
+			lifespan = jwtService.InviteTokenLifespan
+		}
+		now := time.Now().Unix()
+
+		invite, err := ar.inviteStorage.AddInvite(model.Invite{
+			AppID:     app.ID(),
+			Email:     d.Email,
+			Role:      app.NewUserDefaultRole(),
+			Scopes:    app.Scopes(),
+			InvitedBy: member.UserID,
+			CreatedAt: now,
+			ExpiresAt: now + lifespan,
+			OrgID:     member.OrgID,
+			OrgRole:   d.Role,
+		})
+		if err != nil {
+			ar.Error(w, ErrorAPIInviteTokenServerError, http.StatusInternalServerError, err.Error(), "InviteToOrganization.AddInvite")
+			return
+		}
+
+		inviteToken, err := ar.tokenService.NewInviteToken(invite)
+		if err != nil {
+			ar.Error(w, ErrorAPIInviteTokenServerError, http.StatusInternalServerError, err.Error(), "InviteToOrganization.NewInviteToken")
+			return
+		}
+		inviteTokenString, err := ar.tokenService.String(inviteToken)
+		if err != nil {
+			ar.Error(w, ErrorAPIInviteTokenServerError, http.StatusInternalServerError, err.Error(), "InviteToOrganization.tokenService_String")
+			return
+		}
+
+		link, err := ar.inviteLink(app, invite.Scopes, inviteTokenString)
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "InviteToOrganization.inviteLink")
+			return
+		}
+
+		if err = ar.emailService.SendInviteEmail("Invitation", d.Email, link); err != nil {
+			ar.Error(w, ErrorAPIEmailNotSent, http.StatusInternalServerError, err.Error(), "InviteToOrganization.SendInviteEmail")
+			return
+		}
+		result := map[string]string{"id": invite.ID, "link": link}
+		ar.ServeJSON(w, http.StatusOK, result)
+	}
+}
+
+// JoinOrganization makes the current user a member of the organization with the invite token.
+func (ar *Router) JoinOrganization() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := struct {
+			Token string `json:"token"`
+		}{}
+		if ar.MustParseJSON(w, r, &d) != nil {
+			return
+		}
+
+		app := middleware.AppFromContext(r.Context())
+		if app == nil {
+			ar.Error(w, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App is not in context.", "JoinOrganization.AppFromContext")
+			return
+		}
+
+		token, err := ar.tokenService.Parse(d.Token)
+		if err != nil || token.Validate() != nil || token.Type() != jwtService.InviteTokenType {
+			ar.Error(w, ErrorAPIRequestTokenInvalid, http.StatusBadRequest, "", "JoinOrganization.Parse")
+			return
+		}
+
+		invite, err := ar.inviteStorage.InviteByID(token.ID())
+		if err == nil && (invite.AppID != app.ID() || invite.OrgID == "" || !invite.IsValid(time.Now().Unix())) {
+			err = model.ErrInviteNotValid
+		}
+		if err != nil {
+			ar.Error(w, ErrorAPIRequestTokenInvalid, http.StatusBadRequest, err.Error(), "JoinOrganization.InviteByID")
+			return
+		}
+
+		// The invite is for the email it was sent to, not for anyone holding the link.
+		userID := tokenFromContext(r.Context()).UserID()
+		user, err := ar.userStorage.UserByID(userID)
+		if err != nil {
+			ar.Error(w, ErrorAPIUserNotFound, http.StatusUnauthorized, err.Error(), "JoinOrganization.UserByID")
+			return
+		}
+		if !strings.EqualFold(invite.Email, user.Email()) {
+			ar.Error(w, ErrorAPIInviteEmailMismatch, http.StatusForbidden, "", "JoinOrganization.EqualFold")
+			return
+		}
+
+		if err = ar.inviteStorage.ConsumeInvite(invite.ID, userID); err != nil {
+			ar.Error(w, ErrorAPIRequestTokenInvalid, http.StatusBadRequest, err.Error(), "JoinOrganization.ConsumeInvite")
+			return
+		}
+
+		// Existing members keep their role.
+		member, err := ar.organizationStorage.Member(invite.OrgID, userID)
+		if err == model.ErrOrganizationMemberNotFound {
+			member, err = ar.organizationStorage.SetMember(model.OrganizationMember{
+				OrgID:    invite.OrgID,
+				UserID:   userID,
+				Role:     invite.OrgRole,
+				JoinedAt: time.Now().Unix(),
+			})
+		}
+		if err == model.ErrOrganizationNotFound {
+			ar.Error(w, ErrorAPIOrganizationNotFound, http.StatusNotFound, "", "JoinOrganization.SetMember")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "JoinOrganization.SetMember")
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, member)
+	}
+}
+
+// SetOrganizationMemberRole changes the role of the organization member.
+// Only owners and admins can change roles, and only owners can change roles of owners or make new ones.
+func (ar *Router) SetOrganizationMemberRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d := struct {
+			Role string `json:"role"`
+		}{}
+		if ar.MustParseJSON(w, r, &d) != nil {
+			return
+		}
+		if d.Role = strings.TrimSpace(d.Role); d.Role == "" {
+			ar.Error(w, ErrorAPIRequestBodyParamsInvalid, http.StatusBadRequest, "Role is empty", "SetOrganizationMemberRole.validate")
+			return
+		}
+
+		current, ok := ar.currentOrganizationMember(w, r, "SetOrganizationMemberRole")
+		if !ok {
+			return
+		}
+
+		member, err := ar.organizationStorage.Member(current.OrgID, mux.Vars(r)["user_id"])
+		if err == model.ErrOrganizationMemberNotFound {
+			ar.Error(w, ErrorAPIUserNotFound, http.StatusNotFound, "", "SetOrganizationMemberRole.Member")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "SetOrganizationMemberRole.Member")
+			return
+		}
+		if !canAssignOrganizationRole(current, member.Role, d.Role) {
+			ar.Error(w, ErrorAPIOrganizationForbidden, http.StatusForbidden, "", "SetOrganizationMemberRole.canAssignOrganizationRole")
+			return
+		}
+		if d.Role != model.OrganizationRoleOwner && !ar.keepsOrganizationOwner(w, member, "SetOrganizationMemberRole") {
+			return
+		}
+
+		member.Role = d.Role
+		if member, err = ar.organizationStorage.SetMember(member); err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "SetOrganizationMemberRole.SetMember")
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, member)
+	}
+}
+
+// RemoveOrganizationMember removes the member from the organization.
+// Owners and admins can remove members, only owners can remove owners, and any member can leave.
+func (ar *Router) RemoveOrganizationMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		current, ok := ar.currentOrganizationMember(w, r, "RemoveOrganizationMember")
+		if !ok {
+			return
+		}
+
+		member, err := ar.organizationStorage.Member(current.OrgID, mux.Vars(r)["user_id"])
+		if err == model.ErrOrganizationMemberNotFound {
+			ar.Error(w, ErrorAPIUserNotFound, http.StatusNotFound, "", "RemoveOrganizationMember.Member")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "RemoveOrganizationMember.Member")
+			return
+		}
+		if member.UserID != current.UserID && !canAssignOrganizationRole(current, member.Role, "") {
+			ar.Error(w, ErrorAPIOrganizationForbidden, http.StatusForbidden, "", "RemoveOrganizationMember.canAssignOrganizationRole")
+			return
+		}
+		if !ar.keepsOrganizationOwner(w, member, "RemoveOrganizationMember") {
+			return
+		}
+
+		if err = ar.organizationStorage.RemoveMember(member.OrgID, member.UserID); err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "RemoveOrganizationMember.RemoveMember")
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// currentOrganizationMember returns the membership of the current user in the organization from the route.
+// Organizations the user is not a member of are reported as not found.
+func (ar *Router) currentOrganizationMember(w http.ResponseWriter, r *http.Request, where string) (model.OrganizationMember, bool) {
+	userID := tokenFromContext(r.Context()).UserID()
+
+	member, err := ar.organizationStorage.Member(mux.Vars(r)["id"], userID)
+	if err == model.ErrOrganizationMemberNotFound {
+		ar.Error(w, ErrorAPIOrganizationNotFound, http.StatusNotFound, "", where+".Member")
+		return member, false
+	}
+	if err != nil {
+		ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), where+".Member")
+		return member, false
+	}
+	return member, true
+}
+
+// keepsOrganizationOwner checks that the organization has other owners if the member is an owner.
+func (ar *Router) keepsOrganizationOwner(w http.ResponseWriter, member model.OrganizationMember, where string) bool {
+	if member.Role != model.OrganizationRoleOwner {
+		return true
+	}
+
+	members, err := ar.organizationStorage.FetchMembers(member.OrgID)
+	if err != nil {
+		ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), where+".FetchMembers")
+		return false
+	}
+	for _, m := range members {
+		if m.Role == model.OrganizationRoleOwner && m.UserID != member.UserID {
+			return true
+		}
+	}
+	ar.Error(w, ErrorAPIOrganizationLastOwner, http.StatusConflict, "", where+".keepsOrganizationOwner")
+	return false
+}
+
+// canAssignOrganizationRole checks if the member can change the role of another member from one role to another.
+// Empty role means that the member is not in the organization yet, or is removed from it.
+func canAssignOrganizationRole(member model.OrganizationMember, from, to string) bool {
+	if !member.CanManageMembers() {
+		return false
+	}
+	if from == model.OrganizationRoleOwner || to == model.OrganizationRoleOwner {
+		return member.Role == model.OrganizationRoleOwner
+	}
+	return true
+}
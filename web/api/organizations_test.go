@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+)
+
+func TestCanAssignOrganizationRole(t *testing.T) {
+	owner := model.OrganizationMember{Role: model.OrganizationRoleOwner}
+	admin := model.OrganizationMember{Role: model.OrganizationRoleAdmin}
+	member := model.OrganizationMember{Role: model.OrganizationRoleMember}
+
+	tests := []struct {
+		name     string
+		member   model.OrganizationMember
+		from, to string
+		expected bool
+	}{
+		{"owner invites owner", owner, "", model.OrganizationRoleOwner, true},
+		{"owner demotes owner", owner, model.OrganizationRoleOwner, model.OrganizationRoleAdmin, true},
+		{"owner removes owner", owner, model.OrganizationRoleOwner, "", true},
+		{"admin invites member", admin, "", model.OrganizationRoleMember, true},
+		{"admin promotes member to admin", admin, model.OrganizationRoleMember, model.OrganizationRoleAdmin, true},
+		{"admin sets app-defined role", admin, model.OrganizationRoleMember, "billing", true},
+		{"admin removes admin", admin, model.OrganizationRoleAdmin, "", true},
+		{"admin invites owner", admin, "", model.OrganizationRoleOwner, false},
+		{"admin promotes to owner", admin, model.OrganizationRoleMember, model.OrganizationRoleOwner, false},
+		{"admin demotes owner", admin, model.OrganizationRoleOwner, model.OrganizationRoleMember, false},
+		{"admin removes owner", admin, model.OrganizationRoleOwner, "", false},
+		{"member invites member", member, "", model.OrganizationRoleMember, false},
+		{"member removes member", member, model.OrganizationRoleMember, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canAssignOrganizationRole(tt.member, tt.from, tt.to); got != tt.expected {
+				t.Errorf("canAssignOrganizationRole() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestKeepsOrganizationOwner(t *testing.T) {
+	ar := newTestRouter(t)
+	org, _ := ar.organizationStorage.AddOrganization(model.Organization{Name: "Acme"})
+	setMember := func(userID, role string) model.OrganizationMember {
+		member, err := ar.organizationStorage.SetMember(model.OrganizationMember{OrgID: org.ID, UserID: userID, Role: role})
+		if err != nil {
+			t.Fatalf("Error setting member: %s", err)
+		}
+		return member
+	}
+
+	first := setMember("first", model.OrganizationRoleOwner)
+	admin := setMember("admin", model.OrganizationRoleAdmin)
+
+	rec := httptest.NewRecorder()
+	if !ar.keepsOrganizationOwner(rec, admin, "test") {
+		t.Errorf("Not owner is reported as the last owner: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	if ar.keepsOrganizationOwner(rec, first, "test") {
+		t.Error("The last owner can be removed")
+	}
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Status = %d, expected %d", rec.Code, http.StatusConflict)
+	}
+
+	setMember("second", model.OrganizationRoleOwner)
+	rec = httptest.NewRecorder()
+	if !ar.keepsOrganizationOwner(rec, first, "test") {
+		t.Errorf("Owner with another owner is reported as the last owner: %s", rec.Body.String())
+	}
+}
+
+func TestJoinOrganizationChecksInviteEmail(t *testing.T) {
+	tests := []struct {
+		name           string
+		inviteEmail    string
+		expectedStatus int
+	}{
+		{"another email", "someone@example.com", http.StatusForbidden},
+		{"same email", "Member@Example.com", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar := newTestRouter(t)
+			app := testApp("app")
+			user, err := ar.userStorage.AddUserByNameAndPassword("member@example.com", "pass", "user", false)
+			if err != nil {
+				t.Fatalf("Error adding user: %s", err)
+			}
+			org, _ := ar.organizationStorage.AddOrganization(model.Organization{Name: "Acme"})
+			invite, _ := ar.inviteStorage.AddInvite(model.Invite{
+				AppID:     app.ID(),
+				Email:     tt.inviteEmail,
+				ExpiresAt: time.Now().Add(time.Hour).Unix(),
+				OrgID:     org.ID,
+				OrgRole:   model.OrganizationRoleMember,
+			})
+			inviteToken, _ := ar.tokenService.NewInviteToken(invite)
+			inviteTokenString, _ := ar.tokenService.String(inviteToken)
+
+			req := httptest.NewRequest(http.MethodPost, "/organizations/join", strings.NewReader(`{"token":"`+inviteTokenString+`"}`))
+			req = withToken(withApp(req, app), newAccessToken(t, ar, user, app))
+			rec := httptest.NewRecorder()
+			ar.JoinOrganization()(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Status = %d, expected %d: %s", rec.Code, tt.expectedStatus, rec.Body.String())
+			}
+			_, err = ar.organizationStorage.Member(org.ID, user.ID())
+			if joined := err == nil; joined != (tt.expectedStatus == http.StatusOK) {
+				t.Errorf("Joined = %v, expected %v", joined, !joined)
+			}
+			if stored, _ := ar.inviteStorage.InviteByID(invite.ID); stored.IsValid(time.Now().Unix()) != (tt.expectedStatus != http.StatusOK) {
+				t.Errorf("Invite is used = %v after status %d", !stored.IsValid(time.Now().Unix()), rec.Code)
+			}
+		})
+	}
+}
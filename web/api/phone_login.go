@@ -72,7 +72,7 @@ func (ar *Router) PhoneLogin() http.HandlerFunc {
 
 		app := middleware.AppFromContext(r.Context())
 		if app == nil {
-			ar.logger.Println("Error getting App")
+			ar.logger.WithRequest(r).Error("Error getting App")
 			ar.Error(w, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App is not in context.", "LoginWithPassword.AppFromContext")
 			return
 		}
@@ -83,6 +83,7 @@ func (ar *Router) PhoneLogin() http.HandlerFunc {
 				ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "PhoneLogin.IsVerificationCodeFound.error")
 				return
 			} else if !exists {
+				ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPhone, "", model.AuthFailureInvalidCode)
 				ar.Error(w, ErrorAPIVerificationCodeInvalid, http.StatusUnauthorized, "Invalid phone or verification code", "PhoneLogin.IsVerificationCodeFound.not_exists")
 				return
 			}
@@ -90,7 +91,18 @@ func (ar *Router) PhoneLogin() http.HandlerFunc {
 
 		user, err := ar.userStorage.UserByPhone(authData.PhoneNumber)
 		if err == model.ErrUserNotFound {
-			user, err = ar.userStorage.AddUserByPhone(authData.PhoneNumber, app.NewUserDefaultRole())
+			if err = ar.preRegistrationHook(r, model.AuthHookRequest{
+				Phone:  authData.PhoneNumber,
+				Role:   app.NewUserDefaultRole(),
+				Method: model.AuthMethodPhone,
+			}); err != nil {
+				ar.authFailed(r, model.AuthEventRegistration, model.AuthMethodPhone, "", model.AuthFailureHookDenied)
+				ar.Error(w, ErrorAPIAppAccessDenied, http.StatusForbidden, err.Error(), "PhoneLogin.preRegistrationHook")
+				return
+			}
+			if user, err = ar.userStorage.AddUserByPhone(authData.PhoneNumber, app.NewUserDefaultRole()); err == nil {
+				ar.authSucceeded(r, model.AuthEventRegistration, model.AuthMethodPhone, user.ID())
+			}
 		}
 		if err != nil {
 			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "PhoneLogin.UserByPhone")
@@ -100,25 +112,34 @@ func (ar *Router) PhoneLogin() http.HandlerFunc {
 		// Authorize user if the app requires authorization.
 		azi := authorization.AuthzInfo{
 			App:         app,
+			UserID:      user.ID(),
 			UserRole:    user.AccessRole(),
 			ResourceURI: r.RequestURI,
 			Method:      r.Method,
 		}
 		if err := ar.Authorizer.Authorize(azi); err != nil {
+			ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPhone, user.ID(), model.AuthFailureAccessDenied)
 			ar.Error(w, ErrorAPIAppAccessDenied, http.StatusForbidden, err.Error(), "PhoneLogin.Authorizer")
 			return
 		}
 
 		scopes, err := ar.userStorage.RequestScopes(user.ID(), authData.Scopes)
 		if err != nil {
+			ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPhone, user.ID(), model.AuthFailureScopesForbidden)
 			ar.Error(w, ErrorAPIRequestScopesForbidden, http.StatusForbidden, err.Error(), "PhoneLogin.RequestScopes")
 			return
 		}
 
+		if err := ar.preLoginHook(r, user, model.AuthMethodPhone, "", scopes); err != nil {
+			ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPhone, user.ID(), model.AuthFailureHookDenied)
+			ar.Error(w, ErrorAPIAppAccessDenied, http.StatusForbidden, err.Error(), "PhoneLogin.preLoginHook")
+			return
+		}
+
 		offline := contains(scopes, jwtService.OfflineScope)
 		accessToken, refreshToken, err := ar.loginUser(user, scopes, app, offline, false)
 		if err != nil {
-			ar.Error(w, ErrorAPIAppAccessTokenNotCreated, http.StatusInternalServerError, err.Error(), "PhoneLogin.loginUser")
+			ar.tokenNotCreated(w, err, http.StatusInternalServerError, "PhoneLogin.loginUser")
 			return
 		}
 
@@ -129,7 +150,9 @@ func (ar *Router) PhoneLogin() http.HandlerFunc {
 			User:         user,
 		}
 
+		ar.startUserSession(r, user.ID(), app, accessToken, refreshToken)
 		ar.userStorage.UpdateLoginMetadata(user.ID())
+		ar.authSucceeded(r, model.AuthEventLogin, model.AuthMethodPhone, user.ID())
 		ar.ServeJSON(w, http.StatusOK, result)
 	}
 }
@@ -3,6 +3,7 @@ package api
 import (
 	"net/http"
 
+	ijwt "github.com/madappgang/identifo/jwt"
 	jwtService "github.com/madappgang/identifo/jwt/service"
 	"github.com/madappgang/identifo/model"
 	"github.com/madappgang/identifo/web/middleware"
@@ -28,7 +29,7 @@ func (ar *Router) RefreshTokens() http.HandlerFunc {
 
 		app := middleware.AppFromContext(r.Context())
 		if app == nil {
-			ar.logger.Println("Error getting App")
+			ar.logger.WithRequest(r).Error("Error getting App")
 			ar.Error(w, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App ID is absent in header params", "RefreshTokens.AppFromContext")
 			return
 		}
@@ -39,7 +40,8 @@ func (ar *Router) RefreshTokens() http.HandlerFunc {
 		// Issue new access token and stringify it for response.
 		accessToken, err := ar.tokenService.RefreshAccessToken(oldRefreshToken)
 		if err != nil {
-			ar.Error(w, ErrorAPIAppAccessTokenNotCreated, http.StatusInternalServerError, err.Error(), "RefreshTokens.RefreshAccessToken")
+			ar.authFailed(r, model.AuthEventTokenRefresh, model.AuthMethodRefreshToken, oldRefreshToken.UserID(), model.AuthFailureTokenNotIssued)
+			ar.tokenNotCreated(w, err, http.StatusInternalServerError, "RefreshTokens.RefreshAccessToken")
 			return
 		}
 		accessTokenString, err := ar.tokenService.String(accessToken)
@@ -56,7 +58,7 @@ func (ar *Router) RefreshTokens() http.HandlerFunc {
 		}
 		oldRefreshTokenString := string(oldRefreshTokenBytes)
 
-		newRefreshTokenString, err := ar.issueNewRefreshToken(oldRefreshTokenString, rd.Scopes, app)
+		newRefreshTokenString, err := ar.issueNewRefreshToken(oldRefreshTokenString, oldRefreshToken.Payload(), rd.Scopes, app)
 		if err != nil {
 			ar.Error(w, ErrorAPIAppRefreshTokenNotCreated, http.StatusInternalServerError, err.Error(), "RefreshToken.newRefreshTokenString")
 			return
@@ -65,6 +67,16 @@ func (ar *Router) RefreshTokens() http.HandlerFunc {
 		// Invalidate old refresh token - delete it from token storage and add to blacklist.
 		ar.invalidateOldRefreshToken(oldRefreshTokenString)
 
+		// Keep new tokens in the session of the old refresh token.
+		err = ar.userSessionService.ReplaceSessionToken(oldRefreshTokenString, accessTokenString, newRefreshTokenString)
+		if err == model.ErrUserSessionNotFound {
+			ar.startUserSession(r, oldRefreshToken.UserID(), app, accessTokenString, newRefreshTokenString)
+		} else if err != nil {
+			ar.logger.WithRequest(r).Errorf("Cannot update user session: %s\n", err)
+		}
+
+		ar.authSucceeded(r, model.AuthEventTokenRefresh, model.AuthMethodRefreshToken, oldRefreshToken.UserID())
+
 		result := &responseData{
 			AccessToken:  accessTokenString,
 			RefreshToken: newRefreshTokenString,
@@ -74,7 +86,8 @@ func (ar *Router) RefreshTokens() http.HandlerFunc {
 	}
 }
 
-func (ar *Router) issueNewRefreshToken(oldRefreshTokenString string, scopes []string, app model.AppData) (string, error) {
+// issueNewRefreshToken keeps the organization of the old refresh token, if any.
+func (ar *Router) issueNewRefreshToken(oldRefreshTokenString string, oldPayload map[string]interface{}, scopes []string, app model.AppData) (string, error) {
 	if !contains(scopes, jwtService.OfflineScope) { // Don't issue new refresh token if not requested.
 		return "", nil
 	}
@@ -89,7 +102,12 @@ func (ar *Router) issueNewRefreshToken(oldRefreshTokenString string, scopes []st
 		return "", err
 	}
 
-	refreshToken, err := ar.tokenService.NewRefreshToken(user, scopes, app)
+	var refreshToken ijwt.Token
+	if orgID, _ := oldPayload[jwtService.PayloadOrgID].(string); orgID != "" {
+		refreshToken, err = ar.tokenService.NewOrgRefreshToken(user, scopes, app, orgID)
+	} else {
+		refreshToken, err = ar.tokenService.NewRefreshToken(user, scopes, app)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -104,10 +122,10 @@ func (ar *Router) issueNewRefreshToken(oldRefreshTokenString string, scopes []st
 
 func (ar *Router) invalidateOldRefreshToken(oldRefreshTokenString string) {
 	if err := ar.tokenStorage.DeleteToken(oldRefreshTokenString); err != nil {
-		ar.logger.Println("Cannot delete old refresh token from token storage:", err)
+		ar.logger.Error("Cannot delete old refresh token from token storage:", err)
 	}
 	if err := ar.tokenBlacklist.Add(oldRefreshTokenString); err != nil {
-		ar.logger.Println("Cannot blacklist old refresh token:", err)
+		ar.logger.Error("Cannot blacklist old refresh token:", err)
 	}
-	ar.logger.Println("Old refresh token successfully invalidated")
+	ar.logger.Info("Old refresh token successfully invalidated")
 }
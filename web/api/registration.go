@@ -11,10 +11,11 @@ import (
 )
 
 type registrationData struct {
-	Username  string   `json:"username,omitempty"`
-	Password  string   `json:"password,omitempty"`
-	Scopes    []string `json:"scopes,omitempty"`
-	Anonymous bool     `json:"anonymous,omitempty"`
+	Username   string                 `json:"username,omitempty"`
+	Password   string                 `json:"password,omitempty"`
+	Scopes     []string               `json:"scopes,omitempty"`
+	Anonymous  bool                   `json:"anonymous,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
 }
 
 func (rd *registrationData) validate() error {
@@ -46,7 +47,7 @@ func (ar *Router) RegisterWithPassword() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		app := middleware.AppFromContext(r.Context())
 		if app == nil {
-			ar.logger.Println("Error getting App")
+			ar.logger.WithRequest(r).Error("Error getting App")
 			ar.Error(w, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App is not in context.", "RegisterWithPassword.AppFromContext")
 			return
 		}
@@ -84,15 +85,33 @@ func (ar *Router) RegisterWithPassword() http.HandlerFunc {
 			return
 		}
 
+		attributes, err := ar.userAttributes.MergeUserAttributes(nil, rd.Attributes)
+		if err != nil {
+			ar.Error(w, ErrorAPIRequestBodyParamsInvalid, http.StatusBadRequest, err.Error(), "RegisterWithPassword.MergeUserAttributes")
+			return
+		}
+
 		// Validate password.
 		if err := model.StrongPswd(rd.Password); err != nil {
+			ar.authFailed(r, model.AuthEventRegistration, model.AuthMethodPassword, "", model.AuthFailureWeakPassword)
 			ar.Error(w, ErrorAPIRequestPasswordWeak, http.StatusBadRequest, err.Error(), "RegisterWithPassword.StrongPswd")
 			return
 		}
 
+		if err := ar.preRegistrationHook(r, model.AuthHookRequest{
+			Username: rd.Username,
+			Role:     app.NewUserDefaultRole(),
+			Method:   model.AuthMethodPassword,
+		}); err != nil {
+			ar.authFailed(r, model.AuthEventRegistration, model.AuthMethodPassword, "", model.AuthFailureHookDenied)
+			ar.Error(w, ErrorAPIAppAccessDenied, http.StatusForbidden, err.Error(), "RegisterWithPassword.preRegistrationHook")
+			return
+		}
+
 		// Create new user.
 		user, err := ar.userStorage.AddUserByNameAndPassword(rd.Username, rd.Password, app.NewUserDefaultRole(), rd.Anonymous)
 		if err == model.ErrorUserExists {
+			ar.authFailed(r, model.AuthEventRegistration, model.AuthMethodPassword, "", model.AuthFailureUserExists)
 			ar.Error(w, ErrorAPIUsernameTaken, http.StatusBadRequest, err.Error(), "RegisterWithPassword.AddUserByNameAndPassword")
 			return
 		}
@@ -101,6 +120,22 @@ func (ar *Router) RegisterWithPassword() http.HandlerFunc {
 			return
 		}
 
+		// User storage cannot create users with attributes, so the user is rolled back if they cannot be set.
+		if len(attributes) > 0 {
+			user.SetAttributes(attributes)
+			updated, err := ar.userStorage.UpdateUser(user.ID(), user)
+			if err != nil {
+				if derr := ar.userStorage.DeleteUser(user.ID()); derr != nil {
+					ar.logger.WithRequest(r).Errorf("Cannot delete user: %s\n", derr)
+				}
+				ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "RegisterWithPassword.UpdateUser")
+				return
+			}
+			user = updated
+		}
+
+		ar.authSucceeded(r, model.AuthEventRegistration, model.AuthMethodPassword, user.ID())
+
 		// Do login flow.
 		scopes, err := ar.userStorage.RequestScopes(user.ID(), rd.Scopes)
 		if err != nil {
@@ -110,7 +145,7 @@ func (ar *Router) RegisterWithPassword() http.HandlerFunc {
 
 		token, err := ar.tokenService.NewAccessToken(user, scopes, app, false)
 		if err != nil {
-			ar.Error(w, ErrorAPIAppAccessTokenNotCreated, http.StatusForbidden, err.Error(), "RegisterWithPassword.tokenService_NewToken")
+			ar.tokenNotCreated(w, err, http.StatusForbidden, "RegisterWithPassword.tokenService_NewToken")
 			return
 		}
 
@@ -142,6 +177,7 @@ func (ar *Router) RegisterWithPassword() http.HandlerFunc {
 			User:         user,
 		}
 
+		ar.startUserSession(r, user.ID(), app, tokenString, refreshString)
 		ar.ServeJSON(w, http.StatusOK, result)
 	}
 }
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madappgang/identifo/model"
+)
+
+// failingUpdateStorage is a user storage that cannot update users.
+type failingUpdateStorage struct {
+	model.UserStorage
+}
+
+func (us failingUpdateStorage) UpdateUser(userID string, user model.User) (model.User, error) {
+	return nil, errors.New("storage is unavailable")
+}
+
+func TestRegisterWithPasswordAttributes(t *testing.T) {
+	tests := []struct {
+		name           string
+		failUpdate     bool
+		expectedStatus int
+	}{
+		{"attributes are set", false, http.StatusOK},
+		{"user is rolled back when attributes cannot be set", true, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar := newTestRouter(t)
+			ar.userAttributes = model.UserAttributeSchema{{Name: "nickname", Type: model.UserAttributeTypeString}}
+			userStorage := ar.userStorage
+			if tt.failUpdate {
+				ar.userStorage = failingUpdateStorage{userStorage}
+			}
+
+			body := `{"username":"user@example.com","password":"Secret-pass1","attributes":{"nickname":"neo"}}`
+			req := withApp(httptest.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(body)), testApp("app"))
+			rec := httptest.NewRecorder()
+			ar.RegisterWithPassword()(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Status = %d, expected %d: %s", rec.Code, tt.expectedStatus, rec.Body.String())
+			}
+			user, err := userStorage.UserByUsername("user@example.com")
+			if tt.failUpdate {
+				if err != model.ErrUserNotFound {
+					t.Errorf("User without attributes is kept, error = %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error getting registered user: %s", err)
+			}
+			if user.Attributes()["nickname"] != "neo" {
+				t.Errorf("Attributes = %v, expected nickname to be set", user.Attributes())
+			}
+		})
+	}
+}
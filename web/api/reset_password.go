@@ -26,6 +26,7 @@ func (ar *Router) RequestResetPassword() http.HandlerFunc {
 		}
 
 		if userExists := ar.userStorage.UserExists(d.Email); !userExists {
+			ar.authFailed(r, model.AuthEventPasswordResetRequest, "", "", model.AuthFailureUserNotFound)
 			ar.Error(w, ErrorAPIUserNotFound, http.StatusBadRequest, "User with this email does not exist", "RequestResetPassword.UserExists")
 			return
 		}
@@ -68,6 +69,8 @@ func (ar *Router) RequestResetPassword() http.HandlerFunc {
 			return
 		}
 
+		ar.authSucceeded(r, model.AuthEventPasswordResetRequest, "", id)
+
 		result := map[string]string{"result": "ok"}
 		ar.ServeJSON(w, http.StatusOK, result)
 	}
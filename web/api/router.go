@@ -2,12 +2,12 @@ package api
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
-	"os"
 
 	"github.com/gorilla/mux"
 	jwtService "github.com/madappgang/identifo/jwt/service"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/metrics"
 	"github.com/madappgang/identifo/model"
 	"github.com/madappgang/identifo/server/utils/originchecker"
 	"github.com/madappgang/identifo/web/authorization"
@@ -19,18 +19,28 @@ import (
 type Router struct {
 	middleware              *negroni.Negroni
 	cors                    *cors.Cors
-	logger                  *log.Logger
+	logger                  *logging.Logger
 	router                  *mux.Router
 	appStorage              model.AppStorage
 	userStorage             model.UserStorage
 	tokenStorage            model.TokenStorage
 	tokenBlacklist          model.TokenBlacklist
 	verificationCodeStorage model.VerificationCodeStorage
+	inviteStorage           model.InviteStorage
+	userSessionStorage      model.UserSessionStorage
+	authEventStorage        model.AuthEventStorage
+	organizationStorage     model.OrganizationStorage
 	staticFilesStorage      model.StaticFilesStorage
 	tfaType                 model.TFAType
 	tokenService            jwtService.TokenService
 	smsService              model.SMSService
 	emailService            model.EmailService
+	userSessionService      model.UserSessionService
+	authEventService        model.AuthEventService
+	webhookService          model.WebhookService
+	authHookService         model.AuthHookService
+	federatedProviders      *model.FederatedProviderRegistry
+	userAttributes          model.UserAttributeSchema
 	oidcConfiguration       *OIDCConfiguration
 	jwk                     *jwk
 	Authorizer              *authorization.Authorizer
@@ -88,6 +98,14 @@ func TFATypeOption(tfaType model.TFAType) func(*Router) error {
 	}
 }
 
+// UserAttributesOption sets the schema of custom user profile attributes.
+func UserAttributesOption(schema model.UserAttributeSchema) func(*Router) error {
+	return func(r *Router) error {
+		r.userAttributes = schema
+		return nil
+	}
+}
+
 // WebRouterPrefixOption sets web prefix host value.
 func WebRouterPrefixOption(prefix string) func(*Router) error {
 	return func(r *Router) error {
@@ -96,20 +114,36 @@ func WebRouterPrefixOption(prefix string) func(*Router) error {
 	}
 }
 
+// FederatedProvidersOption sets the registry of supported federated identity providers.
+func FederatedProvidersOption(registry *model.FederatedProviderRegistry) func(*Router) error {
+	return func(r *Router) error {
+		r.federatedProviders = registry
+		return nil
+	}
+}
+
 // NewRouter creates and initilizes new router.
-func NewRouter(logger *log.Logger, as model.AppStorage, us model.UserStorage, ts model.TokenStorage, tb model.TokenBlacklist, vcs model.VerificationCodeStorage, sfs model.StaticFilesStorage, tServ jwtService.TokenService, smsServ model.SMSService, emailServ model.EmailService, authorizer *authorization.Authorizer, options ...func(*Router) error) (model.Router, error) {
+func NewRouter(logger *logging.Logger, as model.AppStorage, us model.UserStorage, ts model.TokenStorage, tb model.TokenBlacklist, vcs model.VerificationCodeStorage, is model.InviteStorage, uss model.UserSessionStorage, aes model.AuthEventStorage, ors model.OrganizationStorage, sfs model.StaticFilesStorage, tServ jwtService.TokenService, smsServ model.SMSService, emailServ model.EmailService, usServ model.UserSessionService, aeServ model.AuthEventService, whServ model.WebhookService, ahServ model.AuthHookService, authorizer *authorization.Authorizer, options ...func(*Router) error) (model.Router, error) {
 	ar := Router{
-		middleware:              negroni.Classic(),
+		middleware:              negroni.New(),
 		router:                  mux.NewRouter(),
 		appStorage:              as,
 		userStorage:             us,
 		tokenStorage:            ts,
 		tokenBlacklist:          tb,
 		verificationCodeStorage: vcs,
+		inviteStorage:           is,
+		userSessionStorage:      uss,
+		authEventStorage:        aes,
+		organizationStorage:     ors,
 		staticFilesStorage:      sfs,
 		tokenService:            tServ,
 		smsService:              smsServ,
 		emailService:            emailServ,
+		userSessionService:      usServ,
+		authEventService:        aeServ,
+		webhookService:          whServ,
+		authHookService:         ahServ,
 		Authorizer:              authorizer,
 	}
 
@@ -119,15 +153,17 @@ func NewRouter(logger *log.Logger, as model.AppStorage, us model.UserStorage, ts
 		}
 	}
 
-	// setup logger to stdout.
-	if logger == nil {
-		ar.logger = log.New(os.Stdout, "API_ROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	ar.logger = logger
+	if ar.logger == nil {
+		ar.logger = logging.Default().With("router", "api")
 	}
+	ar.middleware.Use(logging.Recovery(ar.logger))
 
 	if ar.cors != nil {
 		ar.middleware.Use(ar.cors)
 	}
 	ar.initRoutes()
+	ar.router.Use(metrics.RouteMiddleware)
 	ar.middleware.UseHandler(ar.router)
 
 	return &ar, nil
@@ -144,7 +180,7 @@ func (ar *Router) ServeJSON(w http.ResponseWriter, status int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if _, err = w.Write(data); err != nil {
-		log.Printf("error writing http response: %s", err)
+		logging.Errorf("error writing http response: %s", err)
 	}
 }
 
@@ -159,7 +195,7 @@ func (ar *Router) Error(w http.ResponseWriter, errID MessageID, status int, deta
 	}
 
 	// Log error.
-	ar.logger.Printf("api error: %v (status=%v). Details: %v. Where: %v.", errID, status, details, where)
+	ar.logger.Logf(logging.StatusLevel(status), "api error: %v (status=%v). Details: %v. Where: %v.", errID, status, details, where)
 
 	if errID == "" {
 		errID = ErrorAPIInternalServerError
@@ -179,6 +215,6 @@ func (ar *Router) Error(w http.ResponseWriter, errID MessageID, status int, deta
 		Status:          status,
 	}})
 	if encodeErr != nil {
-		ar.logger.Printf("error writing http response: %s", errID)
+		ar.logger.Errorf("error writing http response: %s", errID)
 	}
 }
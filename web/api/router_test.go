@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	authhooks "github.com/madappgang/identifo/external_services/auth_hooks"
+	ijwt "github.com/madappgang/identifo/jwt"
+	jwtService "github.com/madappgang/identifo/jwt/service"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/storage/boltdb"
+	"github.com/madappgang/identifo/storage/mem"
+	"github.com/madappgang/identifo/web/authorization"
+)
+
+// newTestRouter creates router with BoltDB user storage in a temporary file, in-memory storages
+// and the token service signing with the test keys.
+func newTestRouter(t *testing.T) *Router {
+	dir, err := ioutil.TempDir("", "identifo-api")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %s", err)
+	}
+	db, err := boltdb.InitDB(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatalf("Error opening database: %s", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(dir)
+	})
+
+	userStorage, err := boltdb.NewUserStorage(db)
+	if err != nil {
+		t.Fatalf("Error creating user storage: %s", err)
+	}
+	appStorage, _ := mem.NewAppStorage()
+	tokenStorage, _ := mem.NewTokenStorage()
+	tokenBlacklist, _ := mem.NewTokenBlacklist()
+	inviteStorage, _ := mem.NewInviteStorage()
+	organizationStorage, _ := mem.NewOrganizationStorage()
+	userSessionStorage, _ := mem.NewUserSessionStorage()
+	authEventStorage, _ := mem.NewAuthEventStorage()
+	authHookService := authhooks.NewAuthHookService(model.AuthHooksSettings{})
+	authEventService := model.NewAuthEventRecorder(authEventStorage)
+	t.Cleanup(authEventService.Close)
+
+	private, err := ijwt.LoadPrivateKeyFromPEM("../../jwt/private.pem", ijwt.TokenSignatureAlgorithmES256)
+	if err != nil {
+		t.Fatalf("Error loading private key: %s", err)
+	}
+	public, err := ijwt.LoadPublicKeyFromPEM("../../jwt/public.pem", ijwt.TokenSignatureAlgorithmES256)
+	if err != nil {
+		t.Fatalf("Error loading public key: %s", err)
+	}
+	keys := &model.JWTKeys{Private: private, Public: public, Algorithm: ijwt.TokenSignatureAlgorithmES256}
+	tokenService, err := jwtService.NewJWTokenService(keys, "identifo.test", tokenStorage, appStorage, userStorage)
+	if err != nil {
+		t.Fatalf("Error creating token service: %s", err)
+	}
+
+	return &Router{
+		logger:              logging.New(ioutil.Discard, logging.LevelError, 0),
+		appStorage:          appStorage,
+		userStorage:         userStorage,
+		tokenStorage:        tokenStorage,
+		tokenBlacklist:      tokenBlacklist,
+		inviteStorage:       inviteStorage,
+		organizationStorage: organizationStorage,
+		userSessionStorage:  userSessionStorage,
+		tokenService:        tokenService,
+		userSessionService:  model.NewUserSessionManager(userSessionStorage, tokenStorage, tokenBlacklist),
+		authEventStorage:    authEventStorage,
+		authEventService:    authEventService,
+		authHookService:     authHookService,
+		Authorizer:          authorization.NewAuthorizer(authHookService, nil, nil),
+	}
+}
+
+// testApp returns active app with default settings.
+func testApp(id string) model.AppData {
+	app := mem.MakeAppData(id, "secret", true, "test", "", []string{"offline"}, true, nil, 0, 0, 0, nil, false, true, model.TFAStatusDisabled, "", model.NoAuthz, "", "", nil, nil, "user")
+	return &app
+}
+
+// withApp returns the request with the app in its context, as the app middleware does.
+func withApp(r *http.Request, app model.AppData) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), model.AppDataContextKey, app))
+}
+
+// newAccessToken returns access token of the user, parsed as the token middleware does.
+func newAccessToken(t *testing.T, ar *Router, user model.User, app model.AppData) ijwt.Token {
+	token, err := ar.tokenService.NewAccessToken(user, nil, app, false)
+	if err != nil {
+		t.Fatalf("Error creating access token: %s", err)
+	}
+	tokenString, err := ar.tokenService.String(token)
+	if err != nil {
+		t.Fatalf("Error signing access token: %s", err)
+	}
+	if token, err = ar.tokenService.Parse(tokenString); err != nil {
+		t.Fatalf("Error parsing access token: %s", err)
+	}
+	return token
+}
+
+// withToken returns the request with the token in its context, as the token middleware does.
+func withToken(r *http.Request, token ijwt.Token) *http.Request {
+	ctx := context.WithValue(r.Context(), model.TokenContextKey, token)
+	if jt, ok := token.(*ijwt.JWToken); ok {
+		ctx = context.WithValue(ctx, model.TokenRawContextKey, []byte(jt.JWT.Raw))
+	}
+	return r.WithContext(ctx)
+}
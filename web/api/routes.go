@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/madappgang/identifo/metrics"
 	"github.com/urfave/negroni"
 )
 
@@ -12,11 +13,12 @@ func (ar *Router) initRoutes() {
 	}
 
 	// All requests to the API router should contain appID.
-	apiMiddlewares := ar.middleware.With(ar.DumpRequest(), ar.AppID())
+	apiMiddlewares := ar.middleware.With(ar.AppID())
 
 	ar.router.HandleFunc(`/{ping:ping/?}`, ar.HandlePing()).Methods("GET")
 
 	auth := mux.NewRouter().PathPrefix("/auth").Subrouter()
+	auth.Use(metrics.RouteMiddleware)
 	ar.router.PathPrefix("/auth").Handler(apiMiddlewares.With(
 		ar.SignatureHandler(),
 		negroni.Wrap(auth),
@@ -55,6 +57,7 @@ func (ar *Router) initRoutes() {
 	)).Methods("PUT")
 
 	meRouter := mux.NewRouter().PathPrefix("/me").Subrouter()
+	meRouter.Use(metrics.RouteMiddleware)
 	ar.router.PathPrefix("/me").Handler(apiMiddlewares.With(
 		ar.SignatureHandler(),
 		ar.Token(TokenTypeAccess),
@@ -63,11 +66,40 @@ func (ar *Router) initRoutes() {
 	meRouter.Path("").HandlerFunc(ar.IsLoggedIn()).Methods("GET")
 	meRouter.Path("").HandlerFunc(ar.UpdateUser()).Methods("PUT")
 	meRouter.Path(`/{logout:logout/?}`).HandlerFunc(ar.Logout()).Methods("POST")
+	meRouter.Path(`/{upgrade:upgrade/?}`).HandlerFunc(ar.UpgradeAnonymous()).Methods("POST")
+	meRouter.Path(`/{identities:identities/?}`).HandlerFunc(ar.Identities()).Methods("GET")
+	meRouter.Path(`/{identities/federated:identities/federated/?}`).HandlerFunc(ar.LinkFederatedIdentity()).Methods("POST")
+	meRouter.Path(`/identities/federated/{provider}`).HandlerFunc(ar.UnlinkFederatedIdentity()).Methods("DELETE")
+	meRouter.Path(`/{identities/phone:identities/phone/?}`).HandlerFunc(ar.LinkPhone()).Methods("POST")
+	meRouter.Path(`/{identities/phone:identities/phone/?}`).HandlerFunc(ar.UnlinkPhone()).Methods("DELETE")
+	meRouter.Path(`/{identities/password:identities/password/?}`).HandlerFunc(ar.LinkPassword()).Methods("POST")
+	meRouter.Path(`/{identities/password:identities/password/?}`).HandlerFunc(ar.UnlinkPassword()).Methods("DELETE")
+	meRouter.Path(`/{sessions:sessions/?}`).HandlerFunc(ar.UserSessions()).Methods("GET")
+	meRouter.Path(`/{sessions:sessions/?}`).HandlerFunc(ar.RevokeOtherUserSessions()).Methods("DELETE")
+	meRouter.Path(`/sessions/{id:[a-zA-Z0-9]+}`).HandlerFunc(ar.RevokeUserSession()).Methods("DELETE")
+	meRouter.Path(`/{activity:activity/?}`).HandlerFunc(ar.UserActivity()).Methods("GET")
+	meRouter.Path(`/{organizations:organizations/?}`).HandlerFunc(ar.UserOrganizations()).Methods("GET")
+	meRouter.Path(`/{organizations:organizations/?}`).HandlerFunc(ar.CreateOrganization()).Methods("POST")
+	meRouter.Path(`/{organizations/join:organizations/join/?}`).HandlerFunc(ar.JoinOrganization()).Methods("POST")
+	meRouter.Path(`/organizations/{id:[a-zA-Z0-9]+}/token`).HandlerFunc(ar.OrganizationTokens()).Methods("POST")
+	meRouter.Path(`/organizations/{id:[a-zA-Z0-9]+}/members`).HandlerFunc(ar.OrganizationMembers()).Methods("GET")
+	meRouter.Path(`/organizations/{id:[a-zA-Z0-9]+}/members/{user_id:[a-zA-Z0-9]+}`).HandlerFunc(ar.SetOrganizationMemberRole()).Methods("PUT")
+	meRouter.Path(`/organizations/{id:[a-zA-Z0-9]+}/members/{user_id:[a-zA-Z0-9]+}`).HandlerFunc(ar.RemoveOrganizationMember()).Methods("DELETE")
+	meRouter.Path(`/organizations/{id:[a-zA-Z0-9]+}/invites`).HandlerFunc(ar.InviteToOrganization()).Methods("POST")
+
+	authz := mux.NewRouter().PathPrefix("/authz").Subrouter()
+	authz.Use(metrics.RouteMiddleware)
+	ar.router.PathPrefix("/authz").Handler(apiMiddlewares.With(
+		ar.SignatureHandler(),
+		negroni.Wrap(authz),
+	))
+	authz.Path(`/{check:check/?}`).HandlerFunc(ar.CheckAuthorization()).Methods("POST")
+	authz.Path(`/{check/batch:check/batch/?}`).HandlerFunc(ar.CheckAuthorizationBatch()).Methods("POST")
 
 	oidc := mux.NewRouter().PathPrefix("/.well-known").Subrouter()
+	oidc.Use(metrics.RouteMiddleware)
 
 	ar.router.PathPrefix("/.well-known").Handler(ar.middleware.With(
-		ar.DumpRequest(),
 		negroni.Wrap(oidc),
 	))
 
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/middleware"
+)
+
+// UserSession is a user session representation returned to the user.
+type UserSession struct {
+	ID         string `json:"id"`
+	AppID      string `json:"app_id"`
+	UserAgent  string `json:"user_agent,omitempty"`
+	IP         string `json:"ip,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+	LastUsedAt int64  `json:"last_used_at"`
+	Current    bool   `json:"current"`
+}
+
+// UserSessions returns active sessions of the current user.
+func (ar *Router) UserSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := tokenFromContext(r.Context()).UserID()
+		accessToken := rawTokenFromContext(r)
+
+		sessions, err := ar.userSessionStorage.FetchUserSessions(userID)
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "UserSessions.FetchUserSessions")
+			return
+		}
+
+		result := make([]UserSession, len(sessions))
+		for i, s := range sessions {
+			result[i] = UserSession{
+				ID:         s.ID,
+				AppID:      s.AppID,
+				UserAgent:  s.UserAgent,
+				IP:         s.IP,
+				CreatedAt:  s.CreatedAt,
+				LastUsedAt: s.LastUsedAt,
+				Current:    s.HasToken(accessToken),
+			}
+		}
+		ar.ServeJSON(w, http.StatusOK, result)
+	}
+}
+
+// RevokeUserSession ends the session of the current user and invalidates its tokens.
+func (ar *Router) RevokeUserSession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := tokenFromContext(r.Context()).UserID()
+
+		session, err := ar.userSessionStorage.UserSessionByID(mux.Vars(r)["id"])
+		if err == model.ErrUserSessionNotFound || (err == nil && session.UserID != userID) {
+			ar.Error(w, ErrorAPIUserSessionNotFound, http.StatusNotFound, "", "RevokeUserSession.UserSessionByID")
+			return
+		}
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "RevokeUserSession.UserSessionByID")
+			return
+		}
+
+		if err = ar.userSessionService.RevokeSession(session); err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "RevokeUserSession.RevokeSession")
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// RevokeOtherUserSessions ends all sessions of the current user except the one the request is made from.
+func (ar *Router) RevokeOtherUserSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := tokenFromContext(r.Context()).UserID()
+		accessToken := rawTokenFromContext(r)
+
+		sessions, err := ar.userSessionStorage.FetchUserSessions(userID)
+		if err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "RevokeOtherUserSessions.FetchUserSessions")
+			return
+		}
+
+		for _, session := range sessions {
+			if session.HasToken(accessToken) {
+				continue
+			}
+			if err = ar.userSessionService.RevokeSession(session); err != nil {
+				ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "RevokeOtherUserSessions.RevokeSession")
+				return
+			}
+		}
+		ar.ServeJSON(w, http.StatusOK, nil)
+	}
+}
+
+// startUserSession records new session with the tokens issued on login.
+// Login does not fail if the session cannot be recorded.
+func (ar *Router) startUserSession(r *http.Request, userID string, app model.AppData, tokens ...string) {
+	if _, err := ar.userSessionService.StartSession(userID, app.ID(), r.UserAgent(), middleware.ClientIP(r), tokens...); err != nil {
+		ar.logger.WithRequest(r).Errorf("Cannot start user session: %s\n", err)
+	}
+}
+
+// endUserSession revokes the session which has issued the token, if any.
+func (ar *Router) endUserSession(token string) {
+	if err := ar.userSessionService.RevokeSessionByToken(token); err != nil && err != model.ErrUserSessionNotFound {
+		ar.logger.Errorf("Cannot revoke user session: %s\n", err)
+	}
+}
+
+func rawTokenFromContext(r *http.Request) string {
+	tokenBytes, _ := r.Context().Value(model.TokenRawContextKey).([]byte)
+	return string(tokenBytes)
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	ijwt "github.com/madappgang/identifo/jwt"
+	"github.com/madappgang/identifo/model"
+)
+
+// newSessionsTest creates user with the current session, another session of the user and a session of another user.
+func newSessionsTest(t *testing.T) (ar *Router, token ijwt.Token, current, other, foreign model.UserSession) {
+	ar = newTestRouter(t)
+	app := testApp("app")
+	user, err := ar.userStorage.AddUserByNameAndPassword("user@example.com", "pass", "user", false)
+	if err != nil {
+		t.Fatalf("Error adding user: %s", err)
+	}
+	token = newAccessToken(t, ar, user, app)
+
+	start := func(userID string, tokens ...string) model.UserSession {
+		session, err := ar.userSessionService.StartSession(userID, app.ID(), "agent", "203.0.113.7", tokens...)
+		if err != nil {
+			t.Fatalf("Error starting session: %s", err)
+		}
+		return session
+	}
+	current = start(user.ID(), token.(*ijwt.JWToken).JWT.Raw)
+	other = start(user.ID(), "other-access", "other-refresh")
+	foreign = start("another-user", "foreign-access")
+	ar.tokenStorage.SaveToken("other-refresh")
+	return
+}
+
+func TestUserSessions(t *testing.T) {
+	ar, token, current, other, _ := newSessionsTest(t)
+
+	rec := httptest.NewRecorder()
+	ar.UserSessions()(rec, withToken(httptest.NewRequest(http.MethodGet, "/me/sessions", nil), token))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, expected %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var sessions []UserSession
+	if err := json.Unmarshal(rec.Body.Bytes(), &sessions); err != nil {
+		t.Fatalf("Error decoding sessions: %s", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("Sessions = %+v, expected only the user's ones", sessions)
+	}
+	for _, s := range sessions {
+		if s.Current != (s.ID == current.ID) {
+			t.Errorf("Session %s current = %v", s.ID, s.Current)
+		}
+		if s.ID != current.ID && s.ID != other.ID {
+			t.Errorf("Unexpected session %s", s.ID)
+		}
+	}
+	if strings.Contains(rec.Body.String(), "other-access") {
+		t.Errorf("Session tokens are returned: %s", rec.Body.String())
+	}
+}
+
+func TestRevokeUserSession(t *testing.T) {
+	tests := []struct {
+		name           string
+		session        func(other, foreign model.UserSession) string
+		expectedStatus int
+	}{
+		{"own session", func(other, foreign model.UserSession) string { return other.ID }, http.StatusOK},
+		{"another user's session", func(other, foreign model.UserSession) string { return foreign.ID }, http.StatusNotFound},
+		{"unknown session", func(other, foreign model.UserSession) string { return "unknown" }, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar, token, _, other, foreign := newSessionsTest(t)
+			id := tt.session(other, foreign)
+
+			req := withToken(httptest.NewRequest(http.MethodDelete, "/me/sessions/"+id, nil), token)
+			rec := httptest.NewRecorder()
+			ar.RevokeUserSession()(rec, mux.SetURLVars(req, map[string]string{"id": id}))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Status = %d, expected %d: %s", rec.Code, tt.expectedStatus, rec.Body.String())
+			}
+			if _, err := ar.userSessionStorage.UserSessionByID(foreign.ID); err != nil {
+				t.Error("Session of another user is revoked")
+			}
+			_, err := ar.userSessionStorage.UserSessionByID(other.ID)
+			if revoked := err == model.ErrUserSessionNotFound; revoked != (tt.expectedStatus == http.StatusOK) {
+				t.Errorf("Session revoked = %v after status %d", revoked, rec.Code)
+			}
+			if tt.expectedStatus == http.StatusOK && (!ar.tokenBlacklist.IsBlacklisted("other-access") || ar.tokenStorage.HasToken("other-refresh")) {
+				t.Error("Tokens of the revoked session are still valid")
+			}
+		})
+	}
+}
+
+func TestRevokeOtherUserSessions(t *testing.T) {
+	ar, token, current, other, foreign := newSessionsTest(t)
+
+	rec := httptest.NewRecorder()
+	ar.RevokeOtherUserSessions()(rec, withToken(httptest.NewRequest(http.MethodDelete, "/me/sessions", nil), token))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, expected %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	if _, err := ar.userSessionStorage.UserSessionByID(current.ID); err != nil {
+		t.Error("Current session is revoked")
+	}
+	if _, err := ar.userSessionStorage.UserSessionByID(other.ID); err != model.ErrUserSessionNotFound {
+		t.Error("Other session is not revoked")
+	}
+	if _, err := ar.userSessionStorage.UserSessionByID(foreign.ID); err != nil {
+		t.Error("Session of another user is revoked")
+	}
+}
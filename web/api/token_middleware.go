@@ -26,7 +26,7 @@ func (ar *Router) Token(tokenType string) negroni.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 		app := middleware.AppFromContext(r.Context())
 		if app == nil {
-			ar.logger.Println("Error getting App")
+			ar.logger.WithRequest(r).Error("Error getting App")
 			ar.Error(rw, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App id is not in request header params.", "Token.AppFromContext")
 			return
 		}
@@ -59,6 +59,22 @@ func (ar *Router) Token(tokenType string) negroni.HandlerFunc {
 			return
 		}
 
+		// Tokens of deactivated users and tokens revoked by admin are not accepted.
+		// It takes a user storage lookup per request, so revocation works immediately, without waiting for tokens to expire.
+		user, err := ar.userStorage.UserByID(token.UserID())
+		if err != nil {
+			ar.Error(rw, ErrorAPIRequestTokenInvalid, http.StatusBadRequest, err.Error(), "Token.UserByID")
+			return
+		}
+		if !user.Active() {
+			ar.Error(rw, ErrorAPIRequestTokenInvalid, http.StatusBadRequest, "User is not active.", "Token.UserActive")
+			return
+		}
+		if token.IssuedAt() < user.TokensValidAfter() {
+			ar.Error(rw, ErrorAPIRequestTokenInvalid, http.StatusBadRequest, "Token has been revoked.", "Token.TokensValidAfter")
+			return
+		}
+
 		if strings.Trim(r.RequestURI, "/ ") != "auth/tfa/finalize" {
 			if payload := token.Payload(); payload != nil && payload["tfa_authorized"] == "false" {
 				ar.Error(rw, ErrorAPIRequestTokenInvalid, http.StatusBadRequest, "", "Token.IsTFAuthorized")
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/madappgang/identifo/model"
+)
+
+func TestTokenRevocation(t *testing.T) {
+	tests := []struct {
+		name           string
+		revoke         func(ar *Router, user model.User, tokenString string, issuedAt int64)
+		expectedStatus int
+	}{
+		{"valid token", func(ar *Router, user model.User, tokenString string, issuedAt int64) {}, http.StatusOK},
+		{"valid from the second of issue", func(ar *Router, user model.User, tokenString string, issuedAt int64) {
+			ar.userStorage.SetTokensValidAfter(user.ID(), issuedAt)
+		}, http.StatusOK},
+		{"revoked in the second of issue", func(ar *Router, user model.User, tokenString string, issuedAt int64) {
+			ar.userStorage.SetTokensValidAfter(user.ID(), issuedAt+1)
+		}, http.StatusBadRequest},
+		{"revoked after issue", func(ar *Router, user model.User, tokenString string, issuedAt int64) {
+			ar.userStorage.SetTokensValidAfter(user.ID(), issuedAt+2)
+		}, http.StatusBadRequest},
+		{"user deactivated", func(ar *Router, user model.User, tokenString string, issuedAt int64) {
+			user.SetActive(false)
+			ar.userStorage.UpdateUser(user.ID(), user)
+		}, http.StatusBadRequest},
+		{"token blacklisted", func(ar *Router, user model.User, tokenString string, issuedAt int64) {
+			ar.tokenBlacklist.Add(tokenString)
+		}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar := newTestRouter(t)
+			app := testApp("app")
+			user, err := ar.userStorage.AddUserByNameAndPassword("user@example.com", "pass", "user", false)
+			if err != nil {
+				t.Fatalf("Error adding user: %s", err)
+			}
+			token := newAccessToken(t, ar, user, app)
+			tokenString, _ := ar.tokenService.String(token)
+			tt.revoke(ar, user, tokenString, token.IssuedAt())
+
+			req := withApp(httptest.NewRequest(http.MethodGet, "/me", nil), app)
+			req.Header.Set(TokenHeaderKey, "Bearer "+tokenString)
+			rec := httptest.NewRecorder()
+			called := false
+			ar.Token(TokenTypeAccess)(rec, req, func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				if tokenFromContext(r.Context()).UserID() != user.ID() {
+					t.Errorf("Token in context is not the user's one")
+				}
+			})
+
+			if called != (tt.expectedStatus == http.StatusOK) {
+				t.Errorf("Accepted = %v, expected %v", called, !called)
+			}
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Status = %d, expected %d: %s", rec.Code, tt.expectedStatus, rec.Body.String())
+			}
+		})
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"strings"
 
 	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/middleware"
 )
 
-// UpdateUser allows to change user login and password.
+// UpdateUser allows to change user login, password, user metadata and custom attributes.
+// App metadata and admin only attributes are editable only by admins. Null attribute value removes the attribute.
 func (ar *Router) UpdateUser() http.HandlerFunc {
 	type updateResponse struct {
 		Message string `json:"message"`
@@ -76,13 +78,34 @@ func (ar *Router) UpdateUser() http.HandlerFunc {
 			user.SetEmail(d.NewEmail)
 		}
 
-		if d.updateUsername || d.updateEmail {
+		if d.updateMetadata {
+			user.SetUserMetadata(d.UserMetadata)
+		}
+
+		if d.updateAttributes {
+			attributes, err := ar.userAttributes.MergeUserAttributes(user.Attributes(), d.Attributes)
+			if err != nil {
+				ar.Error(w, ErrorAPIRequestBodyParamsInvalid, http.StatusBadRequest, err.Error(), "UpdateUser.MergeUserAttributes")
+				return
+			}
+			user.SetAttributes(attributes)
+		}
+
+		if d.updateUsername || d.updateEmail || d.updateMetadata || d.updateAttributes {
 			if _, err = ar.userStorage.UpdateUser(userID, user); err != nil {
-				ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, "Unable to update username or email. Error:"+err.Error(), "UpdateUser.UpdateUser")
+				ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, "Unable to update user. Error:"+err.Error(), "UpdateUser.UpdateUser")
 				return
 			}
 		}
 
+		if d.updateUsername || d.updateEmail || d.updatePassword || d.updateMetadata || d.updateAttributes {
+			appID := ""
+			if app := middleware.AppFromContext(r.Context()); app != nil {
+				appID = app.ID()
+			}
+			ar.webhookService.Notify(model.WebhookEventUserUpdated, appID, user)
+		}
+
 		// Prepare response.
 		updatedFields := []string{}
 		if d.updateUsername {
@@ -94,6 +117,12 @@ func (ar *Router) UpdateUser() http.HandlerFunc {
 		if d.updatePassword {
 			updatedFields = append(updatedFields, "password")
 		}
+		if d.updateMetadata {
+			updatedFields = append(updatedFields, "user metadata")
+		}
+		if d.updateAttributes {
+			updatedFields = append(updatedFields, "attributes")
+		}
 
 		msg := "Nothing changed."
 		if len(updatedFields) > 0 {
@@ -108,13 +137,17 @@ func (ar *Router) UpdateUser() http.HandlerFunc {
 }
 
 type updateData struct {
-	NewEmail       string `json:"new_email"`
-	NewUsername    string `json:"new_username,omitempty"`
-	NewPassword    string `json:"new_password,omitempty"`
-	OldPassword    string `json:"old_password,omitempty"`
-	updatePassword bool
-	updateEmail    bool
-	updateUsername bool
+	NewEmail         string                 `json:"new_email"`
+	NewUsername      string                 `json:"new_username,omitempty"`
+	NewPassword      string                 `json:"new_password,omitempty"`
+	OldPassword      string                 `json:"old_password,omitempty"`
+	UserMetadata     map[string]interface{} `json:"user_metadata,omitempty"`
+	Attributes       map[string]interface{} `json:"attributes,omitempty"`
+	updatePassword   bool
+	updateEmail      bool
+	updateUsername   bool
+	updateMetadata   bool
+	updateAttributes bool
 }
 
 func (d *updateData) validate(user model.User) error {
@@ -127,6 +160,12 @@ func (d *updateData) validate(user model.User) error {
 	if d.NewPassword != "" && d.NewPassword != d.OldPassword {
 		d.updatePassword = true
 	}
+	if d.UserMetadata != nil {
+		d.updateMetadata = true
+	}
+	if len(d.Attributes) > 0 {
+		d.updateAttributes = true
+	}
 
 	if d.updatePassword {
 		if d.OldPassword == "" {
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	jwtService "github.com/madappgang/identifo/jwt/service"
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/authorization"
+	"github.com/madappgang/identifo/web/middleware"
+)
+
+// UpgradeData represents anonymous user upgrade input data.
+// Exactly one of username/password, phone number/code or federated provider credentials is expected.
+type UpgradeData struct {
+	Username          string   `json:"username,omitempty"`
+	Password          string   `json:"password,omitempty"`
+	PhoneNumber       string   `json:"phone_number,omitempty"`
+	Code              string   `json:"code,omitempty"`
+	Provider          string   `json:"provider,omitempty"`
+	AccessToken       string   `json:"access_token,omitempty"`
+	IDToken           string   `json:"id_token,omitempty"`
+	AuthorizationCode string   `json:"authorization_code,omitempty"`
+	RedirectURI       string   `json:"redirect_uri,omitempty"`
+	Nonce             string   `json:"nonce,omitempty"`
+	RefreshToken      string   `json:"refresh_token,omitempty"` // Anonymous refresh token to revoke, if it is issued in the second of the upgrade.
+	Scopes            []string `json:"scopes,omitempty"`
+}
+
+func (d *UpgradeData) validate() error {
+	methods := 0
+	if d.Password != "" {
+		methods++
+		rd := registrationData{Username: d.Username, Password: d.Password}
+		if err := rd.validate(); err != nil {
+			return err
+		}
+	}
+	if d.PhoneNumber != "" {
+		methods++
+		pl := PhoneLogin{PhoneNumber: d.PhoneNumber, Code: d.Code}
+		if err := pl.validateCodeAndPhone(); err != nil {
+			return err
+		}
+	}
+	if d.Provider != "" {
+		methods++
+	}
+	if methods != 1 {
+		return fmt.Errorf("Expected exactly one of password, phone number or federated provider, got %d", methods)
+	}
+	return nil
+}
+
+// UpgradeAnonymous turns anonymous user into the registered one, keeping user ID and data.
+// User attaches username and password, phone number or federated identity.
+// Anonymous tokens are revoked and the new ones are issued.
+func (ar *Router) UpgradeAnonymous() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		app := middleware.AppFromContext(r.Context())
+		if app == nil {
+			ar.logger.WithRequest(r).Error("Error getting App")
+			ar.Error(w, ErrorAPIRequestAppIDInvalid, http.StatusBadRequest, "App is not in context.", "UpgradeAnonymous.AppFromContext")
+			return
+		}
+
+		if app.RegistrationForbidden() {
+			ar.Error(w, ErrorAPIAppRegistrationForbidden, http.StatusForbidden, "Registration is forbidden in app.", "UpgradeAnonymous.RegistrationForbidden")
+			return
+		}
+
+		d := UpgradeData{}
+		if ar.MustParseJSON(w, r, &d) != nil {
+			return
+		}
+		if err := d.validate(); err != nil {
+			ar.Error(w, ErrorAPIRequestBodyParamsInvalid, http.StatusBadRequest, err.Error(), "UpgradeAnonymous.validate")
+			return
+		}
+
+		userID := tokenFromContext(r.Context()).UserID()
+		user, err := ar.userStorage.UserByID(userID)
+		if err != nil {
+			ar.Error(w, ErrorAPIUserNotFound, http.StatusUnauthorized, err.Error(), "UpgradeAnonymous.UserByID")
+			return
+		}
+		if !user.IsAnonymous() {
+			ar.Error(w, ErrorAPIUserNotAnonymous, http.StatusBadRequest, "", "UpgradeAnonymous.IsAnonymous")
+			return
+		}
+
+		// Authorize user if the app requires authorization.
+		azi := authorization.AuthzInfo{
+			App:         app,
+			UserID:      user.ID(),
+			UserRole:    user.AccessRole(),
+			ResourceURI: r.RequestURI,
+			Method:      r.Method,
+		}
+		if err := ar.Authorizer.Authorize(azi); err != nil {
+			ar.Error(w, ErrorAPIAppAccessDenied, http.StatusForbidden, err.Error(), "UpgradeAnonymous.Authorizer")
+			return
+		}
+
+		switch {
+		case d.Password != "":
+			if !ar.upgradeWithPassword(w, userID, d) {
+				return
+			}
+		case d.PhoneNumber != "":
+			if !ar.upgradeWithPhone(w, app, userID, d) {
+				return
+			}
+		default:
+			if !ar.upgradeWithFederatedID(w, app, userID, d) {
+				return
+			}
+		}
+
+		if err = ar.userStorage.DeanonimizeUser(userID); err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "UpgradeAnonymous.DeanonimizeUser")
+			return
+		}
+
+		// Refetch upgraded user.
+		if user, err = ar.userStorage.UserByID(userID); err != nil {
+			ar.Error(w, ErrorAPIUserNotFound, http.StatusUnauthorized, err.Error(), "UpgradeAnonymous.RefetchUser")
+			return
+		}
+
+		scopes, err := ar.userStorage.RequestScopes(user.ID(), d.Scopes)
+		if err != nil {
+			ar.Error(w, ErrorAPIRequestScopesForbidden, http.StatusBadRequest, err.Error(), "UpgradeAnonymous.RequestScopes")
+			return
+		}
+
+		// Anonymous tokens must not be used anymore, including the refresh tokens the client has not sent.
+		// Tokens issued in the current second are kept valid, as the new ones are issued in it.
+		if err = ar.userStorage.SetTokensValidAfter(userID, time.Now().Unix()); err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "UpgradeAnonymous.SetTokensValidAfter")
+			return
+		}
+
+		offline := contains(scopes, jwtService.OfflineScope)
+		accessToken, refreshToken, err := ar.loginUser(user, scopes, app, offline, false)
+		if err != nil {
+			ar.tokenNotCreated(w, err, http.StatusInternalServerError, "UpgradeAnonymous.loginUser")
+			return
+		}
+
+		// Tokens of the request could be issued in the current second.
+		if accessTokenBytes, ok := r.Context().Value(model.TokenRawContextKey).([]byte); ok {
+			if err := ar.tokenBlacklist.Add(string(accessTokenBytes)); err != nil {
+				ar.logger.WithRequest(r).Errorf("Cannot blacklist access token: %s\n", err)
+			}
+			if err := ar.revokeRefreshToken(d.RefreshToken, string(accessTokenBytes)); err != nil {
+				ar.logger.WithRequest(r).Errorf("Cannot revoke refresh token: %s\n", err)
+			}
+			ar.endUserSession(string(accessTokenBytes))
+		}
+
+		user.Sanitize()
+		result := AuthResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+			User:         user,
+		}
+
+		ar.startUserSession(r, user.ID(), app, accessToken, refreshToken)
+		ar.userStorage.UpdateLoginMetadata(user.ID())
+		ar.ServeJSON(w, http.StatusOK, result)
+	}
+}
+
+func (ar *Router) upgradeWithPassword(w http.ResponseWriter, userID string, d UpgradeData) bool {
+	if err := model.StrongPswd(d.Password); err != nil {
+		ar.Error(w, ErrorAPIRequestPasswordWeak, http.StatusBadRequest, err.Error(), "UpgradeAnonymous.StrongPswd")
+		return false
+	}
+
+	err := ar.userStorage.LinkPassword(userID, d.Username, d.Password)
+	if err == model.ErrorUserExists {
+		ar.Error(w, ErrorAPIUsernameTaken, http.StatusBadRequest, err.Error(), "UpgradeAnonymous.LinkPassword")
+		return false
+	}
+	if err != nil {
+		ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "UpgradeAnonymous.LinkPassword")
+		return false
+	}
+	return true
+}
+
+func (ar *Router) upgradeWithPhone(w http.ResponseWriter, app model.AppData, userID string, d UpgradeData) bool {
+	needVerification := app.DebugTFACode() == "" || d.Code != app.DebugTFACode()
+	if needVerification {
+		if exists, err := ar.verificationCodeStorage.IsVerificationCodeFound(d.PhoneNumber, d.Code); err != nil {
+			ar.Error(w, ErrorAPIInternalServerError, http.StatusInternalServerError, err.Error(), "UpgradeAnonymous.IsVerificationCodeFound.error")
+			return false
+		} else if !exists {
+			ar.Error(w, ErrorAPIVerificationCodeInvalid, http.StatusUnauthorized, "Invalid phone or verification code", "UpgradeAnonymous.IsVerificationCodeFound.not_exists")
+			return false
+		}
+	}
+
+	if err := ar.userStorage.LinkPhone(userID, d.PhoneNumber); err != nil {
+		ar.identityLinkError(w, err, "UpgradeAnonymous.LinkPhone")
+		return false
+	}
+	return true
+}
+
+func (ar *Router) upgradeWithFederatedID(w http.ResponseWriter, app model.AppData, userID string, d UpgradeData) bool {
+	if !ar.SupportedLoginWays.Federated {
+		ar.Error(w, ErrorAPIAppFederatedLoginNotSupported, http.StatusBadRequest, "Application does not support federated login", "UpgradeAnonymous.supportedLoginWays")
+		return false
+	}
+
+	fid, provider, err := ar.federatedProviders.Provider(d.Provider)
+	if err != nil {
+		ar.Error(w, ErrorAPIAppFederatedProviderNotSupported, http.StatusBadRequest, fmt.Sprintf("UnsupportedProvider: %v", d.Provider), "UpgradeAnonymous.federatedProviders")
+		return false
+	}
+
+	identity, err := provider.Identity(app, model.FederatedCredentials{
+		AccessToken:       d.AccessToken,
+		AuthorizationCode: d.AuthorizationCode,
+		IDToken:           d.IDToken,
+		RedirectURI:       d.RedirectURI,
+		Nonce:             d.Nonce,
+	})
+	if err == model.ErrFederatedProviderNotConfigured {
+		ar.Error(w, ErrorAPIAppFederatedProviderNotConfigured, http.StatusBadRequest, fmt.Sprintf("App is not configured for %v", fid), "UpgradeAnonymous.Identity")
+		return false
+	}
+	if err != nil {
+		ar.Error(w, ErrorAPIAppFederatedProviderEmptyUserID, http.StatusBadRequest, err.Error(), "UpgradeAnonymous.Identity")
+		return false
+	}
+
+	if err = ar.userStorage.LinkFederatedID(userID, fid, identity.ID); err != nil {
+		ar.identityLinkError(w, err, "UpgradeAnonymous.LinkFederatedID")
+		return false
+	}
+	return true
+}
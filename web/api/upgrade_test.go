@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ijwt "github.com/madappgang/identifo/jwt"
+	jwtService "github.com/madappgang/identifo/jwt/service"
+)
+
+func TestUpgradeAnonymousRevokesAnonymousTokens(t *testing.T) {
+	ar := newTestRouter(t)
+	app := testApp("app")
+	ar.appStorage.CreateApp(app)
+	user, err := ar.userStorage.AddUserByNameAndPassword("device-1", "", "user", true)
+	if err != nil {
+		t.Fatalf("Error adding user: %s", err)
+	}
+
+	// Anonymous tokens are issued before the upgrade, the refresh token is not sent with it.
+	ijwt.TimeFunc = func() time.Time { return time.Now().Add(-time.Minute) }
+	accessToken := newAccessToken(t, ar, user, app)
+	anonymousRefresh, err := ar.tokenService.NewRefreshToken(user, []string{jwtService.OfflineScope}, app)
+	ijwt.TimeFunc = time.Now
+	if err != nil {
+		t.Fatalf("Error creating refresh token: %s", err)
+	}
+	anonymousRefreshString, _ := ar.tokenService.String(anonymousRefresh)
+	accessTokenString := accessToken.(*ijwt.JWToken).JWT.Raw
+
+	body := `{"username":"user@example.com","password":"Password1!","scopes":["offline"]}`
+	req := withToken(withApp(httptest.NewRequest(http.MethodPost, "/me/upgrade", strings.NewReader(body)), app), accessToken)
+	rec := httptest.NewRecorder()
+	ar.UpgradeAnonymous()(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, expected %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	refresh := func(tokenString string) error {
+		token, err := ar.tokenService.Parse(tokenString)
+		if err != nil {
+			return err
+		}
+		_, err = ar.tokenService.RefreshAccessToken(token)
+		return err
+	}
+	if err = refresh(anonymousRefreshString); err != jwtService.ErrTokenRevoked {
+		t.Errorf("Refreshing with anonymous token error = %v, expected %v", err, jwtService.ErrTokenRevoked)
+	}
+	if !ar.tokenBlacklist.IsBlacklisted(accessTokenString) {
+		t.Error("Anonymous access token is not blacklisted")
+	}
+
+	var result AuthResponse
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	if err = refresh(result.RefreshToken); err != nil {
+		t.Errorf("Error refreshing with new token: %s", err)
+	}
+}
@@ -0,0 +1,132 @@
+package authorization
+
+import (
+	"fmt"
+	"strings"
+
+	casbinModel "github.com/casbin/casbin/model"
+	"github.com/madappgang/identifo/model"
+)
+
+// PolicyAdapter is a Casbin adapter which keeps the app policy rules in the policy storage.
+// Rules from the legacy authz policy string of the app are loaded too, but they cannot be changed by the adapter.
+type PolicyAdapter struct {
+	storage      model.PolicyStorage
+	appID        string
+	legacyPolicy string
+}
+
+// NewPolicyAdapter creates the adapter for the app policy. Storage may be nil, then only legacy policy is used.
+func NewPolicyAdapter(storage model.PolicyStorage, appID, legacyPolicy string) *PolicyAdapter {
+	return &PolicyAdapter{storage: storage, appID: appID, legacyPolicy: legacyPolicy}
+}
+
+// LoadPolicy loads all policy rules of the app.
+func (pa *PolicyAdapter) LoadPolicy(m casbinModel.Model) error {
+	for _, line := range strings.Split(pa.legacyPolicy, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens := strings.Split(line, ",")
+		for i := range tokens {
+			tokens[i] = strings.TrimSpace(tokens[i])
+		}
+		if err := loadRule(m, tokens[0], tokens[1:]); err != nil {
+			return err
+		}
+	}
+
+	if pa.storage == nil {
+		return nil
+	}
+	rules, err := pa.storage.FetchPolicyRules(pa.appID)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if err = loadRule(m, rule.PType, rule.Values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadRule(m casbinModel.Model, ptype string, values []string) error {
+	if ptype == "" {
+		return fmt.Errorf("Policy rule has no type")
+	}
+	assertion, ok := m[ptype[:1]][ptype]
+	if !ok {
+		return fmt.Errorf("Policy type %s is not defined in the model", ptype)
+	}
+	assertion.Policy = append(assertion.Policy, values)
+	return nil
+}
+
+// SavePolicy is not supported, rules are saved one by one.
+func (pa *PolicyAdapter) SavePolicy(m casbinModel.Model) error {
+	return fmt.Errorf("Saving the whole policy is not supported")
+}
+
+// AddPolicy saves the policy rule.
+func (pa *PolicyAdapter) AddPolicy(sec string, ptype string, rule []string) error {
+	if pa.storage == nil {
+		return fmt.Errorf("Policy storage is not set")
+	}
+	_, err := pa.storage.AddPolicyRule(model.PolicyRule{AppID: pa.appID, PType: ptype, Values: rule})
+	return err
+}
+
+// RemovePolicy removes the stored policy rule.
+func (pa *PolicyAdapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return pa.removeRules(ptype, func(values []string) bool {
+		return equal(values, rule)
+	})
+}
+
+// RemoveFilteredPolicy removes the stored policy rules which match the filter.
+func (pa *PolicyAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return pa.removeRules(ptype, func(values []string) bool {
+		for i, fv := range fieldValues {
+			if fv == "" {
+				continue
+			}
+			if fieldIndex+i >= len(values) || values[fieldIndex+i] != fv {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (pa *PolicyAdapter) removeRules(ptype string, match func([]string) bool) error {
+	if pa.storage == nil {
+		return fmt.Errorf("Policy storage is not set")
+	}
+	rules, err := pa.storage.FetchPolicyRules(pa.appID)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if rule.PType != ptype || !match(rule.Values) {
+			continue
+		}
+		if err = pa.storage.DeletePolicyRule(rule.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -2,34 +2,96 @@ package authorization
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/casbin/casbin"
 	"github.com/madappgang/identifo/model"
-	strCasbin "github.com/qiangmzsx/string-adapter"
 )
 
 // NewAuthorizer creates a new Authorizer.
-func NewAuthorizer() *Authorizer {
+// Auth hook service is used for apps with external authorization,
+// policy storage keeps policy rules of apps with internal authorization,
+// role storage keeps the app roles, so the user roles are checked with the roles they inherit.
+func NewAuthorizer(authHooks model.AuthHookService, policyStorage model.PolicyStorage, roleStorage model.RoleStorage) *Authorizer {
 	return &Authorizer{
-		internalAuthorizers: make(map[string]*casbin.Enforcer),
+		internalAuthorizers: make(map[string]internalAuthorizer),
+		authHooks:           authHooks,
+		policyStorage:       policyStorage,
+		roleStorage:         roleStorage,
 	}
 }
 
 // Authorizer is an entity that authorizes users to an app.
 type Authorizer struct {
-	internalAuthorizers map[string]*casbin.Enforcer
+	mu                  sync.RWMutex
+	internalAuthorizers map[string]internalAuthorizer
+	authHooks           model.AuthHookService
+	policyStorage       model.PolicyStorage
+	roleStorage         model.RoleStorage
+}
+
+// internalAuthorizer is a cached app enforcer with the model and legacy policy it was built with.
+type internalAuthorizer struct {
+	enforcer *casbin.SyncedEnforcer
+	model    string
+	policy   string
 }
 
 const anonymousRole = "anonymous"
 
+// OrganizationRolePrefix prefixes organization roles in app whitelists, blacklists and policies,
+// so "org:admin" is an admin of the organization the token is issued for.
+const OrganizationRolePrefix = "org:"
+
 // AuthzInfo holds all the data to perform authorization.
+// User ID is empty when the user is not created yet, like on registration.
+// Organization ID and role are set when the user acts on behalf of the organization.
 type AuthzInfo struct {
 	App         model.AppData
+	UserID      string
 	UserRole    string
+	OrgID       string
+	OrgRole     string
 	ResourceURI string
 	Method      string
 }
 
+// roles returns the user role, or anonymous role if it is empty, and the prefixed organization role if it is set.
+func (azi AuthzInfo) roles() []string {
+	role := azi.UserRole
+	if role == "" {
+		role = anonymousRole
+	}
+	roles := []string{role}
+	if azi.OrgRole != "" {
+		roles = append(roles, OrganizationRolePrefix+azi.OrgRole)
+	}
+	return roles
+}
+
+// UserRoles returns the access role and the roles assigned to the user in the app, with all the roles they inherit.
+// Without role storage it is just the access role.
+func (az *Authorizer) UserRoles(app model.AppData, userID, accessRole string) ([]string, error) {
+	if az == nil || az.roleStorage == nil {
+		if accessRole == "" {
+			return []string{}, nil
+		}
+		return []string{accessRole}, nil
+	}
+	roles, _, err := model.EffectiveRoles(az.roleStorage, app.ID(), userID, accessRole)
+	return roles, err
+}
+
+// expandedRoles returns roles of the request with the roles assigned to the user and the inherited ones.
+func (az *Authorizer) expandedRoles(azi AuthzInfo) ([]string, error) {
+	roles := azi.roles()
+	expanded, err := az.UserRoles(azi.App, azi.UserID, roles[0])
+	if err != nil {
+		return nil, err
+	}
+	return append(expanded, roles[1:]...), nil
+}
+
 // Authorize performs authorization.
 func (az *Authorizer) Authorize(azi AuthzInfo) error {
 	if az == nil {
@@ -45,7 +107,7 @@ func (az *Authorizer) Authorize(azi AuthzInfo) error {
 	case model.Internal:
 		return az.authorizeInternal(azi)
 	case model.External:
-		return model.ErrorNotImplemented
+		return az.authorizeExternal(azi)
 	}
 	return nil
 }
@@ -57,16 +119,16 @@ func (az *Authorizer) authorizeWhitelist(azi AuthzInfo) error {
 		return err
 	}
 
-	role := azi.UserRole
-	if role == "" {
-		role = anonymousRole
-	}
-
-	if accessGranted := contains(whitelist, role); !accessGranted {
-		err := fmt.Errorf("Access denied")
+	roles, err := az.expandedRoles(azi)
+	if err != nil {
 		return err
 	}
-	return nil
+	for _, role := range roles {
+		if contains(whitelist, role) {
+			return nil
+		}
+	}
+	return fmt.Errorf("Access denied")
 }
 
 func (az *Authorizer) authorizeBlacklist(azi AuthzInfo) error {
@@ -75,20 +137,20 @@ func (az *Authorizer) authorizeBlacklist(azi AuthzInfo) error {
 		return nil
 	}
 
-	role := azi.UserRole
-	if role == "" {
-		role = anonymousRole
-	}
-
-	if accessDenied := contains(blacklist, role); accessDenied {
-		err := fmt.Errorf("Access denied")
+	roles, err := az.expandedRoles(azi)
+	if err != nil {
 		return err
 	}
+	for _, role := range roles {
+		if contains(blacklist, role) {
+			return fmt.Errorf("Access denied")
+		}
+	}
 	return nil
 }
 
-// authorizeInternal performs authorization based on the model and policy rules,
-// which are stored in the application entity as strings.
+// authorizeInternal performs authorization based on the model stored in the application entity,
+// and the policy rules stored in the policy storage and in the application entity as string.
 
 /* Example model:
 `[request_definition]
@@ -117,36 +179,101 @@ func (az *Authorizer) authorizeInternal(azi AuthzInfo) error {
 		return err
 	}
 
-	sub := azi.UserRole
-	if sub == "" {
-		sub = anonymousRole
-	}
 	obj := azi.ResourceURI
 	act := azi.Method
 
-	if accessGranted := authorizer.Enforce(sub, obj, act); !accessGranted {
-		err := fmt.Errorf("Access denied")
+	// Access is granted if any of the user roles is allowed.
+	// User ID is a subject too, so role definition rules can assign roles to particular users.
+	subjects, err := az.expandedRoles(azi)
+	if err != nil {
 		return err
 	}
-	return nil
+	if azi.UserID != "" {
+		subjects = append(subjects, azi.UserID)
+	}
+	for _, sub := range subjects {
+		allowed, err := authorizer.EnforceSafe(sub, obj, act)
+		if err != nil {
+			return EvaluationError{Err: err}
+		}
+		if allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("Access denied")
 }
 
-func (az *Authorizer) initInternalAuthorizer(app model.AppData) (*casbin.Enforcer, error) {
-	authorizer, ok := az.internalAuthorizers[app.ID()]
-	if ok {
-		return authorizer, nil
+// authorizeExternal asks the auth hook endpoint to authorize the request.
+func (az *Authorizer) authorizeExternal(azi AuthzInfo) error {
+	if az.authHooks == nil || !az.authHooks.Enabled(model.AuthHookAuthorization) {
+		return fmt.Errorf("External authorization is not configured for app %s", azi.App.ID())
 	}
 
-	// If authorizer has not been initialized already, try initializing it.
+	role := azi.UserRole
+	if role == "" {
+		role = anonymousRole
+	}
+
+	_, err := az.authHooks.Call(model.AuthHookRequest{
+		Hook:        model.AuthHookAuthorization,
+		AppID:       azi.App.ID(),
+		UserID:      azi.UserID,
+		Role:        role,
+		OrgID:       azi.OrgID,
+		OrgRole:     azi.OrgRole,
+		ResourceURI: azi.ResourceURI,
+		HTTPMethod:  azi.Method,
+	})
+	if err == model.ErrAuthHookDenied {
+		return fmt.Errorf("Access denied")
+	}
+	return err
+}
+
+// InvalidateApp drops the cached enforcer of the app,
+// so it is rebuilt with the current model and policy rules on the next use.
+func (az *Authorizer) InvalidateApp(appID string) {
+	if az == nil {
+		return
+	}
+	az.mu.Lock()
+	defer az.mu.Unlock()
+	delete(az.internalAuthorizers, appID)
+}
+
+func (az *Authorizer) initInternalAuthorizer(app model.AppData) (*casbin.SyncedEnforcer, error) {
 	modelStr, policyStr := app.AuthzModel(), app.AuthzPolicy()
-	if len(modelStr) == 0 || len(policyStr) == 0 {
-		return nil, fmt.Errorf("Either authz model or policy is empty for app %s, or both", app.ID())
+
+	az.mu.RLock()
+	cached, ok := az.internalAuthorizers[app.ID()]
+	az.mu.RUnlock()
+	// App model or legacy policy might be changed by another identifo instance.
+	if ok && cached.model == modelStr && cached.policy == policyStr {
+		return cached.enforcer, nil
 	}
-	strAdapter := strCasbin.NewAdapter(policyStr)
 
-	authorizer = casbin.NewEnforcer(casbin.NewModel(modelStr), strAdapter)
-	az.internalAuthorizers[app.ID()] = authorizer
+	// If authorizer has not been initialized already, try initializing it.
+	if len(modelStr) == 0 {
+		return nil, fmt.Errorf("Authz model is empty for app %s", app.ID())
+	}
+
+	m, err := newModel(modelStr)
+	if err != nil {
+		return nil, err
+	}
+	authorizer, err := casbin.NewSyncedEnforcerSafe(m)
+	if err != nil {
+		return nil, err
+	}
+	authorizer.SetAdapter(NewPolicyAdapter(az.policyStorage, app.ID(), policyStr))
+	if err = authorizer.LoadPolicy(); err != nil {
+		return nil, err
+	}
 	authorizer.EnableLog(true)
 
+	az.mu.Lock()
+	az.internalAuthorizers[app.ID()] = internalAuthorizer{enforcer: authorizer, model: modelStr, policy: policyStr}
+	az.mu.Unlock()
+
 	return authorizer, nil
 }
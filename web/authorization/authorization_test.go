@@ -0,0 +1,198 @@
+package authorization
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/storage/mem"
+)
+
+func TestAuthorizeOrganizationRoles(t *testing.T) {
+	whitelistApp := mem.MakeAppData("1", "1", true, "test", "", nil, false, nil, 0, 0, 0, nil, true, true, model.TFAStatusDisabled, "", model.RolesWhitelist, "", "", []string{"admin", "org:owner"}, nil, "user")
+	blacklistApp := mem.MakeAppData("2", "1", true, "test", "", nil, false, nil, 0, 0, 0, nil, true, true, model.TFAStatusDisabled, "", model.RolesBlacklist, "", "", nil, []string{"org:guest"}, "user")
+
+	tests := []struct {
+		app     model.AppData
+		azi     AuthzInfo
+		granted bool
+	}{
+		{&whitelistApp, AuthzInfo{UserRole: "admin"}, true},
+		{&whitelistApp, AuthzInfo{UserRole: "user"}, false},
+		{&whitelistApp, AuthzInfo{UserRole: "user", OrgID: "org", OrgRole: "owner"}, true},
+		{&whitelistApp, AuthzInfo{UserRole: "user", OrgID: "org", OrgRole: "member"}, false},
+		{&whitelistApp, AuthzInfo{UserRole: "owner"}, false},
+		{&blacklistApp, AuthzInfo{UserRole: "user", OrgID: "org", OrgRole: "member"}, true},
+		{&blacklistApp, AuthzInfo{UserRole: "user", OrgID: "org", OrgRole: "guest"}, false},
+	}
+
+	az := NewAuthorizer(nil, nil, nil)
+	for _, tt := range tests {
+		tt.azi.App = tt.app
+		if err := az.Authorize(tt.azi); (err == nil) != tt.granted {
+			t.Errorf("Authorize(%+v) = %v, want granted %v", tt.azi, err, tt.granted)
+		}
+	}
+}
+
+func TestAuthorizeInternalStoredPolicy(t *testing.T) {
+	authzModel := `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act`
+	app := mem.MakeAppData("1", "1", true, "test", "", nil, false, nil, 0, 0, 0, nil, true, true, model.TFAStatusDisabled, "", model.Internal, authzModel, "p, admin, /auth/login, POST", nil, nil, "user")
+
+	ps, _ := mem.NewPolicyStorage()
+	az := NewAuthorizer(nil, ps, nil)
+	login := AuthzInfo{App: &app, UserRole: "manager", ResourceURI: "/auth/login", Method: "POST"}
+
+	if err := az.Authorize(login); err == nil {
+		t.Fatalf("Authorize() granted access without policy rule")
+	}
+
+	rule := model.PolicyRule{AppID: app.ID(), PType: "g", Values: []string{"manager", "admin"}}
+	if err := ValidatePolicyRule(&app, rule); err != nil {
+		t.Fatalf("ValidatePolicyRule() = %v", err)
+	}
+	if err := ValidatePolicyRule(&app, model.PolicyRule{PType: "p", Values: []string{"manager", "/auth/login"}}); err == nil {
+		t.Errorf("ValidatePolicyRule() accepted rule with missing value")
+	}
+	if _, err := ps.AddPolicyRule(rule); err != nil {
+		t.Fatal(err)
+	}
+
+	// Enforcer is cached until the app is invalidated.
+	if err := az.Authorize(login); err == nil {
+		t.Errorf("Authorize() used policy rule before invalidation")
+	}
+	az.InvalidateApp(app.ID())
+	if err := az.Authorize(login); err != nil {
+		t.Errorf("Authorize() = %v, want inherited admin access", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	authzModel := `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && r.obj == p.obj && r.act == p.act`
+	policy := "p, editor, article, write\np, viewer, article, read\ng, editor, viewer\ng, alice, editor"
+	app := mem.MakeAppData("1", "1", true, "test", "", nil, false, nil, 0, 0, 0, nil, true, true, model.TFAStatusDisabled, "", model.Internal, authzModel, policy, nil, nil, "user")
+	az := NewAuthorizer(nil, nil, nil)
+
+	tests := []struct {
+		subjects []string
+		action   string
+		allowed  bool
+		roles    []string
+	}{
+		{[]string{"viewer"}, "read", true, nil},
+		{[]string{"viewer"}, "write", false, nil},
+		{[]string{"editor"}, "read", true, []string{"viewer"}},
+		{[]string{"bob", "viewer"}, "read", true, nil},
+		{[]string{"alice"}, "write", true, []string{"editor", "viewer"}},
+	}
+	for _, tt := range tests {
+		got, err := az.Check(&app, tt.subjects, "article", tt.action)
+		if err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+		if got.Allowed != tt.allowed || !reflect.DeepEqual(got.Roles, tt.roles) {
+			t.Errorf("Check(%v, %s) = %+v, want allowed %v with roles %v", tt.subjects, tt.action, got, tt.allowed, tt.roles)
+		}
+	}
+
+	noModel := mem.MakeAppData("2", "1", true, "test", "", nil, false, nil, 0, 0, 0, nil, true, true, model.TFAStatusDisabled, "", model.Internal, "", "", nil, nil, "user")
+	if _, err := az.Check(&noModel, []string{"viewer"}, "article", "read"); err == nil {
+		t.Errorf("Check() succeeded for app without authz model")
+	}
+}
+
+func TestAuthorizeInheritedRoles(t *testing.T) {
+	whitelistApp := mem.MakeAppData("1", "1", true, "test", "", nil, false, nil, 0, 0, 0, nil, true, true, model.TFAStatusDisabled, "", model.RolesWhitelist, "", "", []string{"staff"}, nil, "user")
+	blacklistApp := mem.MakeAppData("2", "1", true, "test", "", nil, false, nil, 0, 0, 0, nil, true, true, model.TFAStatusDisabled, "", model.RolesBlacklist, "", "", nil, []string{"suspended"}, "user")
+
+	rs, _ := mem.NewRoleStorage()
+	for _, app := range []string{"1", "2"} {
+		if _, err := rs.AddRole(model.Role{AppID: app, Name: "staff"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rs.AddRole(model.Role{AppID: app, Name: "manager", Inherits: []string{"staff"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := rs.AddRole(model.Role{AppID: "2", Name: "trial", Inherits: []string{"suspended"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rs.SetUserRoles("1", "bob", []string{"manager"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rs.SetUserRoles("2", "carol", []string{"trial"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		app     model.AppData
+		azi     AuthzInfo
+		granted bool
+	}{
+		{&whitelistApp, AuthzInfo{UserRole: "manager"}, true},
+		{&whitelistApp, AuthzInfo{UserRole: "user"}, false},
+		{&whitelistApp, AuthzInfo{UserID: "bob", UserRole: "user"}, true},
+		{&blacklistApp, AuthzInfo{UserID: "bob", UserRole: "user"}, true},
+		{&blacklistApp, AuthzInfo{UserID: "carol", UserRole: "user"}, false},
+	}
+
+	az := NewAuthorizer(nil, nil, rs)
+	for _, tt := range tests {
+		tt.azi.App = tt.app
+		if err := az.Authorize(tt.azi); (err == nil) != tt.granted {
+			t.Errorf("Authorize(%+v) = %v, want granted %v", tt.azi, err, tt.granted)
+		}
+	}
+}
+
+func TestCheckEvaluationError(t *testing.T) {
+	authzModel := `[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = r.sub == p.sub && undefinedMatch(r.obj, p.obj) && r.act == p.act`
+	app := mem.MakeAppData("1", "1", true, "test", "", nil, false, nil, 0, 0, 0, nil, true, true, model.TFAStatusDisabled, "", model.Internal, authzModel, "p, viewer, article, read", nil, nil, "user")
+	az := NewAuthorizer(nil, nil, nil)
+
+	if _, err := az.Check(&app, []string{"viewer"}, "article", "read"); err == nil {
+		t.Fatal("Check() evaluated invalid matcher")
+	} else if _, ok := err.(EvaluationError); !ok {
+		t.Errorf("Check() error = %T, expected EvaluationError", err)
+	}
+	if err := az.Authorize(AuthzInfo{App: &app, UserRole: "viewer", ResourceURI: "article", Method: "read"}); err == nil {
+		t.Error("Authorize() granted access with invalid matcher")
+	}
+}
@@ -0,0 +1,85 @@
+package authorization
+
+import (
+	"fmt"
+
+	"github.com/madappgang/identifo/model"
+)
+
+// MaxBatchChecks is the maximum number of checks in one batch request.
+const MaxBatchChecks = 100
+
+// CheckRequest asks whether the subject can perform the action on the object.
+// Subject is a role or any other policy subject. When user ID is set,
+// the user ID and all the user roles are checked as subjects too, so "g" rules may assign roles to particular users.
+type CheckRequest struct {
+	Subject string `json:"subject,omitempty" validate:"required_without=UserID"`
+	UserID  string `json:"user_id,omitempty" validate:"required_without=Subject"`
+	Object  string `json:"object" validate:"required"`
+	Action  string `json:"action" validate:"required"`
+}
+
+// CheckResult is the authorization decision.
+type CheckResult struct {
+	Allowed bool `json:"allowed"`
+	// Roles are all roles of the subjects, including inherited ones, when the app authz model defines roles.
+	Roles []string `json:"roles,omitempty"`
+}
+
+// EvaluationError is when the app authz model cannot evaluate the check, like when its matcher calls undefined function.
+type EvaluationError struct {
+	Err error
+}
+
+// Error implements error interface.
+func (e EvaluationError) Error() string {
+	return "Cannot evaluate authz model: " + e.Err.Error()
+}
+
+// BatchCheckRequest holds several checks, evaluated in one request.
+type BatchCheckRequest struct {
+	Checks []CheckRequest `json:"checks" validate:"required,min=1,max=100,dive"`
+}
+
+// BatchCheckResult holds results in the order of the checks.
+type BatchCheckResult struct {
+	Results []CheckResult `json:"results"`
+}
+
+// Check evaluates the app authz model and policy rules. Access is granted if any of the subjects is allowed.
+// It works for any app with authz model, regardless of the authorization way used for the app login.
+func (az *Authorizer) Check(app model.AppData, subjects []string, object, action string) (CheckResult, error) {
+	if az == nil || app.AuthzModel() == "" {
+		return CheckResult{}, fmt.Errorf("App %s has no authz model", app.ID())
+	}
+	enforcer, err := az.initInternalAuthorizer(app)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	result := CheckResult{}
+	for _, sub := range subjects {
+		allowed, err := enforcer.EnforceSafe(sub, object, action)
+		if err != nil {
+			return CheckResult{}, EvaluationError{Err: err}
+		}
+		if allowed {
+			result.Allowed = true
+			break
+		}
+	}
+
+	// Role manager is only set up when the model has role definition.
+	if _, ok := enforcer.GetModel()["g"]["g"]; ok {
+		seen := map[string]bool{}
+		for _, sub := range subjects {
+			for _, role := range enforcer.GetImplicitRolesForUser(sub) {
+				if !seen[role] {
+					seen[role] = true
+					result.Roles = append(result.Roles, role)
+				}
+			}
+		}
+	}
+	return result, nil
+}
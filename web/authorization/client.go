@@ -0,0 +1,113 @@
+package authorization
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Request headers expected by the Identifo API, see web/api for details.
+const (
+	headerKeyAppID     = "X-Identifo-Clientid"
+	headerKeySignature = "Digest"
+	signaturePrefix    = "SHA-256="
+)
+
+// Client checks authorization with the Identifo authorization check API.
+// It is intended for embedding into resource servers, and signs requests with the app secret.
+type Client struct {
+	BaseURL    string
+	AppID      string
+	Secret     string
+	HTTPClient *http.Client
+}
+
+// NewClient creates new authorization check client for the app.
+func NewClient(baseURL, appID, secret string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		AppID:      appID,
+		Secret:     secret,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ClientError is an error returned by the authorization check API.
+type ClientError struct {
+	ID              string `json:"id"`
+	Message         string `json:"message,omitempty"`
+	DetailedMessage string `json:"detailed_message,omitempty"`
+	Status          int    `json:"status"`
+}
+
+func (e *ClientError) Error() string {
+	if e.DetailedMessage != "" {
+		return fmt.Sprintf("%s (status %d): %s", e.ID, e.Status, e.DetailedMessage)
+	}
+	return fmt.Sprintf("%s (status %d)", e.ID, e.Status)
+}
+
+// Check asks whether the subject or user can perform the action on the object.
+func (c *Client) Check(check CheckRequest) (CheckResult, error) {
+	result := CheckResult{}
+	err := c.post("/authz/check", check, &result)
+	return result, err
+}
+
+// CheckBatch evaluates several checks in one request. Results are in the order of the checks.
+func (c *Client) CheckBatch(checks []CheckRequest) ([]CheckResult, error) {
+	if len(checks) > MaxBatchChecks {
+		return nil, fmt.Errorf("Too many checks: %d, maximum is %d", len(checks), MaxBatchChecks)
+	}
+	result := BatchCheckResult{}
+	if err := c.post("/authz/check/batch", BatchCheckRequest{Checks: checks}, &result); err != nil {
+		return nil, err
+	}
+	return result.Results, nil
+}
+
+func (c *Client) post(path string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, []byte(c.Secret))
+	if _, err = mac.Write(body); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerKeyAppID, c.AppID)
+	req.Header.Set(headerKeySignature, signaturePrefix+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		e := struct {
+			Error *ClientError `json:"error"`
+		}{}
+		if err = json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Error == nil {
+			return fmt.Errorf("Unexpected response status %d", resp.StatusCode)
+		}
+		return e.Error
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
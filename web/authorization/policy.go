@@ -0,0 +1,49 @@
+package authorization
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/casbin/casbin"
+	casbinModel "github.com/casbin/casbin/model"
+	"github.com/madappgang/identifo/model"
+)
+
+// newModel parses Casbin model, returning error instead of the panic Casbin causes.
+func newModel(text string) (m casbinModel.Model, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Invalid authz model: %v", r)
+		}
+	}()
+	return casbin.NewModel(text), nil
+}
+
+// ValidatePolicyRule checks that the rule is well-formed and matches the app authz model, if it is set.
+func ValidatePolicyRule(app model.AppData, rule model.PolicyRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if app.AuthzModel() == "" {
+		return nil
+	}
+
+	m, err := newModel(app.AuthzModel())
+	if err != nil {
+		return err
+	}
+	assertion, ok := m[rule.PType[:1]][rule.PType]
+	if !ok {
+		return fmt.Errorf("Policy type %s is not defined in the app authz model", rule.PType)
+	}
+
+	// Policy definitions have named tokens, role definitions are like "_, _".
+	expected := len(assertion.Tokens)
+	if rule.PType[:1] == "g" {
+		expected = strings.Count(assertion.Value, "_")
+	}
+	if len(rule.Values) != expected {
+		return fmt.Errorf("Policy type %s expects %d values, got %d", rule.PType, expected, len(rule.Values))
+	}
+	return nil
+}
@@ -16,8 +16,8 @@ func (ar *Router) DisableTFA() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tokenBytes, ok := r.Context().Value(model.TokenRawContextKey).([]byte)
 		if !ok {
-			ar.Logger.Println("Error getting token from context")
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.Logger.WithRequest(r).Error("Error getting token from context")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
@@ -25,15 +25,15 @@ func (ar *Router) DisableTFA() http.HandlerFunc {
 
 		token, ok := r.Context().Value(model.TokenContextKey).(ijwt.Token)
 		if !ok {
-			ar.Logger.Println("Error getting token from context")
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.Logger.WithRequest(r).Error("Error getting token from context")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
 
 		user, err := ar.UserStorage.UserByID(token.UserID())
 		if err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
@@ -45,14 +45,14 @@ func (ar *Router) DisableTFA() http.HandlerFunc {
 		user.SetTFAInfo(tfa)
 
 		if _, err := ar.UserStorage.UpdateUser(token.UserID(), user); err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
 
 		// Invalidate reset token after use.
 		if err := ar.TokenBlacklist.Add(tokenString); err != nil {
-			ar.Logger.Printf("Cannot blacklist reset token after use: %s\n", err)
+			ar.Logger.WithRequest(r).Errorf("Cannot blacklist reset token after use: %s\n", err)
 		}
 
 		successPath := path.Join(ar.PathPrefix, "tfa/disable/success")
@@ -64,10 +64,10 @@ func (ar *Router) DisableTFA() http.HandlerFunc {
 func (ar *Router) DisableTFAHandler() http.HandlerFunc {
 	tmpl, err := ar.staticFilesStorage.ParseTemplate(model.StaticPagesNames.DisableTFA)
 	if err != nil {
-		ar.Logger.Fatalln("Cannot parse DisableTFA template.", err)
+		ar.Logger.Fatal("Cannot parse DisableTFA template.", err)
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
-		errorMessage, err := GetFlash(w, r, FlashErrorMessageKey)
+		errorMessage, err := ar.GetFlash(w, r, FlashErrorMessageKey)
 		if err != nil {
 			ar.Error(w, err, http.StatusInternalServerError, "")
 			return
@@ -91,8 +91,8 @@ func (ar *Router) ResetTFA() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tokenBytes, ok := r.Context().Value(model.TokenRawContextKey).([]byte)
 		if !ok {
-			ar.Logger.Println("Error getting token bytes from context")
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.Logger.WithRequest(r).Error("Error getting token bytes from context")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
@@ -100,30 +100,30 @@ func (ar *Router) ResetTFA() http.HandlerFunc {
 
 		token, ok := r.Context().Value(model.TokenContextKey).(ijwt.Token)
 		if !ok {
-			ar.Logger.Println("Error getting token from context")
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.Logger.WithRequest(r).Error("Error getting token from context")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
 
 		user, err := ar.UserStorage.UserByID(token.UserID())
 		if err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
 
 		app := middleware.AppFromContext(r.Context())
 		if app == nil {
-			ar.Logger.Println("Error getting app from context")
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.Logger.WithRequest(r).Error("Error getting app from context")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
 
 		tfaCode := r.FormValue("tfa_code")
 		if len(tfaCode) == 0 {
-			SetFlash(w, FlashErrorMessageKey, "Empty TFA code")
+			ar.SetFlash(w, FlashErrorMessageKey, "Empty TFA code")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
@@ -132,20 +132,20 @@ func (ar *Router) ResetTFA() http.HandlerFunc {
 		dontNeedVerification := app.DebugTFACode() != "" && tfaCode == app.DebugTFACode()
 
 		if verified := totp.Verify(tfaCode, int(time.Now().Unix())); !(verified || dontNeedVerification) {
-			SetFlash(w, FlashErrorMessageKey, "Invalid TFA code")
+			ar.SetFlash(w, FlashErrorMessageKey, "Invalid TFA code")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
 
 		if _, err := ar.UserStorage.UpdateUser(token.UserID(), user); err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
 
 		// Invalidate reset token after use.
 		if err := ar.TokenBlacklist.Add(tokenString); err != nil {
-			ar.Logger.Printf("Cannot blacklist reset token after use: %s\n", err)
+			ar.Logger.WithRequest(r).Errorf("Cannot blacklist reset token after use: %s\n", err)
 		}
 
 		successPath := path.Join(ar.PathPrefix, "tfa/reset/success")
@@ -157,11 +157,11 @@ func (ar *Router) ResetTFA() http.HandlerFunc {
 func (ar *Router) ResetTFAHandler() http.HandlerFunc {
 	tmpl, err := ar.staticFilesStorage.ParseTemplate(model.StaticPagesNames.ResetTFA)
 	if err != nil {
-		ar.Logger.Fatalln("Cannot parse ResetTFA template.", err)
+		ar.Logger.Fatal("Cannot parse ResetTFA template.", err)
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		errorMessage, err := GetFlash(w, r, FlashErrorMessageKey)
+		errorMessage, err := ar.GetFlash(w, r, FlashErrorMessageKey)
 		if err != nil {
 			ar.Error(w, err, http.StatusInternalServerError, "")
 			return
@@ -175,7 +175,7 @@ func (ar *Router) ResetTFAHandler() http.HandlerFunc {
 
 		user, err := ar.UserStorage.UserByID(token.UserID())
 		if err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
@@ -187,7 +187,7 @@ func (ar *Router) ResetTFAHandler() http.HandlerFunc {
 		user.SetTFAInfo(tfa)
 
 		if _, err := ar.UserStorage.UpdateUser(user.ID(), user); err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
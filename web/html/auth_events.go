@@ -0,0 +1,29 @@
+package html
+
+import (
+	"net/http"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/middleware"
+)
+
+// authSucceeded records successful authentication step.
+func (ar *Router) authSucceeded(r *http.Request, eventType model.AuthEventType, method model.AuthMethod, userID string) {
+	ar.recordAuthEvent(r, model.AuthEvent{Type: eventType, Method: method, UserID: userID, Success: true})
+}
+
+// authFailed records failed authentication step. User ID is empty if the user is not known.
+func (ar *Router) authFailed(r *http.Request, eventType model.AuthEventType, method model.AuthMethod, userID string, reason model.AuthFailureReason) {
+	ar.recordAuthEvent(r, model.AuthEvent{Type: eventType, Method: method, UserID: userID, FailureReason: reason})
+}
+
+// recordAuthEvent fills request details of the event and records it.
+// App is taken from the context, unless the event has it already.
+func (ar *Router) recordAuthEvent(r *http.Request, event model.AuthEvent) {
+	if app := middleware.AppFromContext(r.Context()); app != nil && event.AppID == "" {
+		event.AppID = app.ID()
+	}
+	event.IP = middleware.ClientIP(r)
+	event.UserAgent = r.UserAgent()
+	ar.AuthEventService.Record(event)
+}
@@ -0,0 +1,43 @@
+package html
+
+import (
+	"net/http"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/middleware"
+)
+
+// preRegistrationHook lets the auth hook endpoint deny creation of the new user.
+func (ar *Router) preRegistrationHook(r *http.Request, req model.AuthHookRequest) error {
+	req.Hook = model.AuthHookPreRegistration
+	return ar.callAuthHook(r, req)
+}
+
+// preLoginHook lets the auth hook endpoint deny the login of the authenticated user.
+func (ar *Router) preLoginHook(r *http.Request, appID string, user model.User, method model.AuthMethod, provider model.FederatedIdentityProvider, scopes []string) error {
+	return ar.callAuthHook(r, model.AuthHookRequest{
+		Hook:     model.AuthHookPreLogin,
+		AppID:    appID,
+		UserID:   user.ID(),
+		Username: user.Username(),
+		Email:    user.Email(),
+		Phone:    user.Phone(),
+		Role:     user.AccessRole(),
+		Method:   method,
+		Provider: provider,
+		Scopes:   scopes,
+	})
+}
+
+// callAuthHook fills request details of the hook request and calls it.
+// App is taken from the context, unless the request has it already.
+func (ar *Router) callAuthHook(r *http.Request, req model.AuthHookRequest) error {
+	if app := middleware.AppFromContext(r.Context()); app != nil && req.AppID == "" {
+		req.AppID = app.ID()
+	}
+	req.IP = middleware.ClientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	_, err := ar.AuthHookService.Call(req)
+	return err
+}
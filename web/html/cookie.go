@@ -9,6 +9,8 @@ import (
 const (
 	// CookieKeyWebCookieToken cookie key to keep the web cookie token.
 	CookieKeyWebCookieToken = "identifo-user"
+	// CookieKeyFederatedState cookie key to keep federated login state between redirects.
+	CookieKeyFederatedState = "identifo-federated-state"
 )
 
 func encode(src string) string {
@@ -24,8 +26,10 @@ func decode(src string) (string, error) {
 	return string(b), nil
 }
 
-func setCookie(w http.ResponseWriter, name, value string, maxAge int) {
-	c := &http.Cookie{Name: name, Value: encode(value), MaxAge: maxAge, HttpOnly: true}
+// setCookie sets cookie visible to all the router pages, so cookies set from nested pages,
+// like federated login callbacks, are seen and deleted by the others.
+func (ar *Router) setCookie(w http.ResponseWriter, name, value string, maxAge int) {
+	c := &http.Cookie{Name: name, Value: encode(value), Path: ar.cookiePath(), MaxAge: maxAge, HttpOnly: true}
 	http.SetCookie(w, c)
 }
 
@@ -47,7 +51,15 @@ func getCookie(r *http.Request, name string) (string, error) {
 	return value, nil
 }
 
-func deleteCookie(w http.ResponseWriter, name string) {
-	c := &http.Cookie{Name: name, Value: "", Expires: time.Unix(0, 0), MaxAge: -1}
+func (ar *Router) deleteCookie(w http.ResponseWriter, name string) {
+	c := &http.Cookie{Name: name, Value: "", Path: ar.cookiePath(), Expires: time.Unix(0, 0), MaxAge: -1}
 	http.SetCookie(w, c)
 }
+
+// cookiePath is the path of all the router pages.
+func (ar *Router) cookiePath() string {
+	if ar.PathPrefix == "" {
+		return "/"
+	}
+	return ar.PathPrefix
+}
@@ -9,4 +9,14 @@ func (e Error) Error() string { return string(e) }
 const (
 	// ErrorRegistrationForbidden means that registration is forbidden.
 	ErrorRegistrationForbidden = Error("Registration in this app is forbidden.")
+	// ErrorFederatedProviderNotSupported means that the app cannot log in with the requested provider.
+	ErrorFederatedProviderNotSupported = Error("Login with this provider is not supported.")
+	// ErrorFederatedLoginFailed means that the provider did not confirm user identity.
+	ErrorFederatedLoginFailed = Error("Login with this provider failed, try again please.")
+	// ErrorFederatedStateInvalid means that federated login callback does not match the started login.
+	ErrorFederatedStateInvalid = Error("Federated login state is invalid or expired.")
+	// ErrorInviteInvalid means that invite token is invalid, or the invite is used, revoked or expired.
+	ErrorInviteInvalid = Error("Invitation is invalid or expired.")
+	// ErrorInviteEmailMismatch means that user tries to register with an email other than the invited one.
+	ErrorInviteEmailMismatch = Error("Invitation was sent to another email.")
 )
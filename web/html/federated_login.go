@@ -0,0 +1,328 @@
+package html
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/authorization"
+	"github.com/madappgang/identifo/web/middleware"
+)
+
+// federatedStateLifespan is how long user has to complete the login on the provider's side, in seconds.
+const federatedStateLifespan = 600
+
+// federatedState is kept in the cookie between redirects to the provider and back.
+type federatedState struct {
+	State       string `json:"state"`
+	Nonce       string `json:"nonce"`
+	Provider    string `json:"provider"`
+	AppID       string `json:"app_id"`
+	Scopes      string `json:"scopes"`
+	CallbackURL string `json:"callback_url"`
+}
+
+// federatedProviderTitles are human-friendly provider names for the login page.
+var federatedProviderTitles = map[string]string{
+	"apple":  "Apple",
+	"github": "GitHub",
+	"google": "Google",
+	"oidc":   "SSO",
+}
+
+// federatedButton is a login page button for the federated identity provider.
+type federatedButton struct {
+	Name string
+	URL  string
+}
+
+// FederatedLoginStart redirects user to the federated identity provider's consent page.
+func (ar *Router) FederatedLoginStart() http.HandlerFunc {
+	errorPath := path.Join(ar.PathPrefix, "/misconfiguration")
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		app := middleware.AppFromContext(r.Context())
+		scopesJSON := strings.TrimSpace(r.URL.Query().Get(scopesKey))
+		callbackURL := strings.TrimSpace(r.URL.Query().Get(callbackURLKey))
+
+		if !contains(app.RedirectURLs(), callbackURL) {
+			ar.Logger.WithRequest(r).Warnf("Unauthorized redirect url %v for app %v", callbackURL, app.ID())
+			http.Redirect(w, r, errorPath, http.StatusFound)
+			return
+		}
+
+		name := strings.ToLower(mux.Vars(r)["provider"])
+		provider, ok := ar.redirectProvider(name, app)
+		if !ok {
+			ar.redirectToLogin(w, r, app.ID(), scopesJSON, callbackURL, ErrorFederatedProviderNotSupported.Error())
+			return
+		}
+
+		state, err := randomString()
+		if err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+		nonce, err := randomString()
+		if err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		authURL, err := provider.AuthCodeURL(app, ar.federatedRedirectURI(name), state, nonce)
+		if err != nil {
+			ar.Logger.WithRequest(r).Errorf("Error building %s authorization url: %v", name, err)
+			ar.redirectToLogin(w, r, app.ID(), scopesJSON, callbackURL, ErrorFederatedProviderNotSupported.Error())
+			return
+		}
+
+		fs, err := json.Marshal(federatedState{
+			State:       state,
+			Nonce:       nonce,
+			Provider:    name,
+			AppID:       app.ID(),
+			Scopes:      scopesJSON,
+			CallbackURL: callbackURL,
+		})
+		if err != nil {
+			ar.Error(w, err, http.StatusInternalServerError, "")
+			return
+		}
+
+		ar.setCookie(w, CookieKeyFederatedState, string(fs), federatedStateLifespan)
+		http.Redirect(w, r, authURL, http.StatusFound)
+	}
+}
+
+// FederatedLoginCallback handles the provider's redirect back with the authorization code.
+// It finds or registers the user with federated identity, sets the web cookie token like Login does
+// and redirects to the login page, which sends access token to the app's callback URL.
+func (ar *Router) FederatedLoginCallback() http.HandlerFunc {
+	errorPath := path.Join(ar.PathPrefix, "/misconfiguration")
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := strings.ToLower(mux.Vars(r)["provider"])
+		q := r.URL.Query()
+
+		stateJSON, err := getCookie(r, CookieKeyFederatedState)
+		ar.deleteCookie(w, CookieKeyFederatedState)
+
+		fs := federatedState{}
+		if err != nil || stateJSON == "" || json.Unmarshal([]byte(stateJSON), &fs) != nil {
+			ar.Error(w, ErrorFederatedStateInvalid, http.StatusBadRequest, "")
+			return
+		}
+		if fs.Provider != name || subtle.ConstantTimeCompare([]byte(q.Get("state")), []byte(fs.State)) != 1 {
+			ar.Error(w, ErrorFederatedStateInvalid, http.StatusBadRequest, "")
+			return
+		}
+
+		app, err := ar.AppStorage.ActiveAppByID(fs.AppID)
+		if err != nil {
+			ar.Logger.WithRequest(r).Errorf("Error: getting app by id. %s", err)
+			http.Redirect(w, r, errorPath, http.StatusFound)
+			return
+		}
+
+		redirectToLogin := func(message string) {
+			ar.redirectToLogin(w, r, app.ID(), fs.Scopes, fs.CallbackURL, message)
+		}
+
+		// recordAuthEvent records successful event if there is no failure reason.
+		recordAuthEvent := func(eventType model.AuthEventType, userID string, reason model.AuthFailureReason) {
+			ar.recordAuthEvent(r, model.AuthEvent{
+				Type:          eventType,
+				Method:        model.AuthMethodFederated,
+				Provider:      strings.ToUpper(name),
+				UserID:        userID,
+				AppID:         app.ID(),
+				Success:       reason == "",
+				FailureReason: reason,
+			})
+		}
+
+		if providerErr := q.Get("error"); providerErr != "" {
+			ar.Logger.WithRequest(r).Warnf("Federated login with %s failed: %s", name, providerErr)
+			redirectToLogin(ErrorFederatedLoginFailed.Error())
+			return
+		}
+
+		provider, ok := ar.redirectProvider(name, app)
+		if !ok {
+			redirectToLogin(ErrorFederatedProviderNotSupported.Error())
+			return
+		}
+
+		identity, err := provider.Identity(app, model.FederatedCredentials{
+			AuthorizationCode: q.Get("code"),
+			RedirectURI:       ar.federatedRedirectURI(name),
+			Nonce:             fs.Nonce,
+		})
+		if err != nil {
+			recordAuthEvent(model.AuthEventLogin, "", model.AuthFailureProviderError)
+			ar.Logger.WithRequest(r).Errorf("Error getting %s identity: %v", name, err)
+			redirectToLogin(ErrorFederatedLoginFailed.Error())
+			return
+		}
+
+		fid := model.FederatedIdentityProvider(strings.ToUpper(name))
+		user, err := ar.UserStorage.UserByFederatedID(fid, identity.ID)
+		if err == model.ErrUserNotFound {
+			if app.RegistrationForbidden() {
+				redirectToLogin(ErrorRegistrationForbidden.Error())
+				return
+			}
+			if err = ar.preRegistrationHook(r, model.AuthHookRequest{
+				AppID:    app.ID(),
+				Role:     app.NewUserDefaultRole(),
+				Method:   model.AuthMethodFederated,
+				Provider: fid,
+			}); err != nil {
+				recordAuthEvent(model.AuthEventRegistration, "", model.AuthFailureHookDenied)
+				redirectToLogin(err.Error())
+				return
+			}
+			if user, err = ar.UserStorage.AddUserWithFederatedID(fid, identity.ID, app.NewUserDefaultRole()); err == nil {
+				recordAuthEvent(model.AuthEventRegistration, user.ID(), "")
+			}
+		}
+		if err != nil {
+			ar.Logger.WithRequest(r).Errorf("Error getting user by %s identity: %v", name, err)
+			http.Redirect(w, r, errorPath, http.StatusFound)
+			return
+		}
+
+		scopes := []string{}
+		if err = json.Unmarshal([]byte(fs.Scopes), &scopes); err != nil {
+			ar.Logger.WithRequest(r).Errorf("Error: Invalid scopes %v", fs.Scopes)
+			http.Redirect(w, r, errorPath, http.StatusFound)
+			return
+		}
+		if _, err = ar.UserStorage.RequestScopes(user.ID(), scopes); err != nil {
+			recordAuthEvent(model.AuthEventLogin, user.ID(), model.AuthFailureScopesForbidden)
+			ar.Logger.WithRequest(r).Errorf("Error: invalid scopes %v for userID: %v", scopes, user.ID())
+			http.Redirect(w, r, errorPath, http.StatusFound)
+			return
+		}
+
+		// Authorize user if the app requires authorization.
+		azi := authorization.AuthzInfo{
+			App:         app,
+			UserID:      user.ID(),
+			UserRole:    user.AccessRole(),
+			ResourceURI: r.RequestURI,
+			Method:      r.Method,
+		}
+		if err = ar.Authorizer.Authorize(azi); err != nil {
+			recordAuthEvent(model.AuthEventLogin, user.ID(), model.AuthFailureAccessDenied)
+			redirectToLogin(err.Error())
+			return
+		}
+		if err = ar.preLoginHook(r, app.ID(), user, model.AuthMethodFederated, fid, scopes); err != nil {
+			recordAuthEvent(model.AuthEventLogin, user.ID(), model.AuthFailureHookDenied)
+			redirectToLogin(err.Error())
+			return
+		}
+
+		token, err := ar.TokenService.NewWebCookieToken(user)
+		if err != nil {
+			ar.Logger.WithRequest(r).Errorf("Error creating auth token %v", err)
+			http.Redirect(w, r, errorPath, http.StatusFound)
+			return
+		}
+
+		tokenString, err := ar.TokenService.String(token)
+		if err != nil {
+			ar.Logger.WithRequest(r).Errorf("Error stringifying token: %v", err)
+			http.Redirect(w, r, errorPath, http.StatusFound)
+			return
+		}
+
+		ar.startUserSession(r, user.ID(), app.ID(), tokenString)
+		ar.UserStorage.UpdateLoginMetadata(user.ID())
+		recordAuthEvent(model.AuthEventLogin, user.ID(), "")
+		ar.setCookie(w, CookieKeyWebCookieToken, tokenString, int(ar.TokenService.WebCookieTokenLifespan()))
+		redirectToLogin("")
+	}
+}
+
+// federatedButtons returns login buttons for the providers configured for the app.
+func (ar *Router) federatedButtons(app model.AppData, scopesJSON, callbackURL string) []federatedButton {
+	if !ar.SupportedLoginWays.Federated {
+		return nil
+	}
+
+	q := url.Values{}
+	q.Set(FormKeyAppID, app.ID())
+	q.Set(scopesKey, scopesJSON)
+	q.Set(callbackURLKey, callbackURL)
+
+	buttons := []federatedButton{}
+	for _, fid := range ar.FederatedProviders.Names() {
+		name := strings.ToLower(string(fid))
+		if _, ok := ar.redirectProvider(name, app); !ok {
+			continue
+		}
+		title, ok := federatedProviderTitles[name]
+		if !ok {
+			title = strings.Title(name)
+		}
+		buttons = append(buttons, federatedButton{
+			Name: title,
+			URL:  path.Join(ar.PathPrefix, "federated", name, "start") + "?" + q.Encode(),
+		})
+	}
+	sort.Slice(buttons, func(i, j int) bool { return buttons[i].Name < buttons[j].Name })
+	return buttons
+}
+
+// redirectProvider returns provider if it supports redirect flow and is configured for the app.
+func (ar *Router) redirectProvider(name string, app model.AppData) (model.FederatedRedirectProvider, bool) {
+	if !ar.SupportedLoginWays.Federated {
+		return nil, false
+	}
+	_, p, err := ar.FederatedProviders.Provider(name)
+	if err != nil {
+		return nil, false
+	}
+	rp, ok := p.(model.FederatedRedirectProvider)
+	if !ok || !rp.IsConfigured(app) {
+		return nil, false
+	}
+	return rp, true
+}
+
+// federatedRedirectURI is where the provider redirects user back. It has to be registered in provider's app settings.
+func (ar *Router) federatedRedirectURI(name string) string {
+	return strings.TrimSuffix(ar.Host, "/") + path.Join(ar.PathPrefix, "federated", name, "callback")
+}
+
+func (ar *Router) redirectToLogin(w http.ResponseWriter, r *http.Request, appID, scopesJSON, callbackURL, message string) {
+	if message != "" {
+		ar.SetFlash(w, FlashErrorMessageKey, message)
+	}
+
+	q := url.Values{}
+	q.Set(FormKeyAppID, appID)
+	q.Set(scopesKey, scopesJSON)
+	q.Set(callbackURLKey, callbackURL)
+
+	http.Redirect(w, r, path.Join(ar.PathPrefix, "login")+"?"+q.Encode(), http.StatusFound)
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
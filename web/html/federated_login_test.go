@@ -0,0 +1,131 @@
+package html
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/madappgang/identifo/model"
+)
+
+const testCallbackURL = "https://app.example.com/callback"
+
+// testProvider records the state and nonce sent to the provider and the credentials it is asked to verify.
+type testProvider struct {
+	state, nonce string
+	credentials  *model.FederatedCredentials
+}
+
+func (p *testProvider) IsConfigured(app model.AppData) bool { return true }
+
+func (p *testProvider) AuthCodeURL(app model.AppData, redirectURI, state, nonce string) (string, error) {
+	p.state, p.nonce = state, nonce
+	return "https://idp.example.com/authorize?state=" + url.QueryEscape(state), nil
+}
+
+func (p *testProvider) Identity(app model.AppData, credentials model.FederatedCredentials) (model.FederatedIdentity, error) {
+	p.credentials = &credentials
+	return model.FederatedIdentity{}, errors.New("identity is not checked in this test")
+}
+
+func newFederatedTestRouter(t *testing.T) (*Router, *testProvider, model.AppData) {
+	ar := newTestRouter(t)
+	provider := &testProvider{}
+	ar.FederatedProviders = model.NewFederatedProviderRegistry()
+	ar.FederatedProviders.Register("test", provider)
+	ar.FederatedProviders.Register("other", &testProvider{})
+	ar.SupportedLoginWays = model.LoginWith{Federated: true}
+	return ar, provider, addTestApp(t, ar)
+}
+
+// startFederatedLogin starts login with the test provider and returns the state cookie.
+func startFederatedLogin(t *testing.T, ar *Router, app model.AppData) *http.Cookie {
+	q := url.Values{}
+	q.Set(scopesKey, "[]")
+	q.Set(callbackURLKey, testCallbackURL)
+	req := httptest.NewRequest(http.MethodGet, "/federated/test/start?"+q.Encode(), nil)
+	req = req.WithContext(context.WithValue(req.Context(), model.AppDataContextKey, app))
+	rec := httptest.NewRecorder()
+	ar.FederatedLoginStart()(rec, mux.SetURLVars(req, map[string]string{"provider": "test"}))
+
+	if rec.Code != http.StatusFound || !strings.HasPrefix(rec.Header().Get("Location"), "https://idp.example.com/") {
+		t.Fatalf("Start status = %d, location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == CookieKeyFederatedState {
+			if c.Path != "/web" {
+				t.Errorf("State cookie path = %q, expected the router path", c.Path)
+			}
+			return c
+		}
+	}
+	t.Fatal("State cookie is not set")
+	return nil
+}
+
+func TestFederatedLoginState(t *testing.T) {
+	tests := []struct {
+		name          string
+		provider      string
+		withCookie    bool
+		state         func(sent string) string
+		expectedValid bool
+	}{
+		{"valid state", "test", true, func(sent string) string { return sent }, true},
+		{"no state cookie", "test", false, func(sent string) string { return sent }, false},
+		{"wrong state", "test", true, func(sent string) string { return sent + "x" }, false},
+		{"empty state", "test", true, func(sent string) string { return "" }, false},
+		{"another provider", "other", true, func(sent string) string { return sent }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar, provider, app := newFederatedTestRouter(t)
+			cookie := startFederatedLogin(t, ar, app)
+
+			q := url.Values{}
+			q.Set("state", tt.state(provider.state))
+			q.Set("code", "code")
+			req := httptest.NewRequest(http.MethodGet, "/federated/"+tt.provider+"/callback?"+q.Encode(), nil)
+			if tt.withCookie {
+				req.AddCookie(cookie)
+			}
+			rec := httptest.NewRecorder()
+			ar.FederatedLoginCallback()(rec, mux.SetURLVars(req, map[string]string{"provider": tt.provider}))
+
+			deleted := false
+			for _, c := range rec.Result().Cookies() {
+				if c.Name == CookieKeyFederatedState && c.MaxAge < 0 && c.Path == "/web" {
+					deleted = true
+				}
+			}
+			if !deleted {
+				t.Error("State cookie is not deleted from the router path")
+			}
+
+			if !tt.expectedValid {
+				if rec.Code != http.StatusBadRequest {
+					t.Errorf("Status = %d, expected %d", rec.Code, http.StatusBadRequest)
+				}
+				if provider.credentials != nil {
+					t.Error("Provider is asked for identity with invalid state")
+				}
+				return
+			}
+
+			if provider.credentials == nil {
+				t.Fatalf("Provider is not asked for identity, status = %d", rec.Code)
+			}
+			if provider.credentials.Nonce == "" || provider.credentials.Nonce != provider.nonce {
+				t.Errorf("Nonce = %q, expected the one sent to the provider %q", provider.credentials.Nonce, provider.nonce)
+			}
+			if provider.credentials.AuthorizationCode != "code" {
+				t.Errorf("Authorization code = %q, expected %q", provider.credentials.AuthorizationCode, "code")
+			}
+		})
+	}
+}
@@ -10,13 +10,13 @@ const (
 )
 
 // SetFlash sets new flash message
-func SetFlash(w http.ResponseWriter, name, value string) {
-	setCookie(w, name, value, 600)
+func (ar *Router) SetFlash(w http.ResponseWriter, name, value string) {
+	ar.setCookie(w, name, value, 600)
 }
 
 // GetFlash gets flash message
-func GetFlash(w http.ResponseWriter, r *http.Request, name string) (string, error) {
+func (ar *Router) GetFlash(w http.ResponseWriter, r *http.Request, name string) (string, error) {
 	value, err := getCookie(r, name)
-	deleteCookie(w, name)
+	ar.deleteCookie(w, name)
 	return value, err
 }
@@ -20,6 +20,7 @@ const (
 	isAnonymousKey = "anonymous"
 	callbackURLKey = "callbackUrl"
 	redirectURIKey = "redirectUri"
+	inviteTokenKey = "token"
 )
 
 // Login logs user in with email and password.
@@ -45,20 +46,23 @@ func (ar *Router) Login() http.HandlerFunc {
 		}
 
 		if err := json.Unmarshal([]byte(scopesJSON), &scopes); err != nil {
-			ar.Logger.Printf("Error: Invalid scopes %v", scopesJSON)
+			ar.Logger.WithRequest(r).Errorf("Error: Invalid scopes %v", scopesJSON)
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			return
 		}
 
 		user, err := ar.UserStorage.UserByNamePassword(username, password)
 		if err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Invalid Username or Password")
+			userID, _ := ar.UserStorage.IDByName(username)
+			ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPassword, userID, model.AuthFailureInvalidCredentials)
+			ar.SetFlash(w, FlashErrorMessageKey, "Invalid Username or Password")
 			redirectToLogin()
 			return
 		}
 
 		if _, err = ar.UserStorage.RequestScopes(user.ID(), scopes); err != nil {
-			ar.Logger.Printf("Error: invalid scopes %v for userID: %v", scopes, user.ID())
+			ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPassword, user.ID(), model.AuthFailureScopesForbidden)
+			ar.Logger.WithRequest(r).Errorf("Error: invalid scopes %v for userID: %v", scopes, user.ID())
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			redirectToLogin()
 			return
@@ -67,33 +71,44 @@ func (ar *Router) Login() http.HandlerFunc {
 		// Authorize user if the app requires authorization.
 		azi := authorization.AuthzInfo{
 			App:         app,
+			UserID:      user.ID(),
 			UserRole:    user.AccessRole(),
 			ResourceURI: r.RequestURI,
 			Method:      r.Method,
 		}
 
 		if err := ar.Authorizer.Authorize(azi); err != nil {
-			SetFlash(w, FlashErrorMessageKey, err.Error())
+			ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPassword, user.ID(), model.AuthFailureAccessDenied)
+			ar.SetFlash(w, FlashErrorMessageKey, err.Error())
+			redirectToLogin()
+			return
+		}
+
+		if err := ar.preLoginHook(r, app.ID(), user, model.AuthMethodPassword, "", scopes); err != nil {
+			ar.authFailed(r, model.AuthEventLogin, model.AuthMethodPassword, user.ID(), model.AuthFailureHookDenied)
+			ar.SetFlash(w, FlashErrorMessageKey, err.Error())
 			redirectToLogin()
 			return
 		}
 
 		token, err := ar.TokenService.NewWebCookieToken(user)
 		if err != nil {
-			ar.Logger.Printf("Error creating auth token %v", err)
+			ar.Logger.WithRequest(r).Errorf("Error creating auth token %v", err)
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			return
 		}
 
 		tokenString, err := ar.TokenService.String(token)
 		if err != nil {
-			ar.Logger.Printf("Error stringifying token: %v", err)
+			ar.Logger.WithRequest(r).Errorf("Error stringifying token: %v", err)
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			return
 		}
 
+		ar.startUserSession(r, user.ID(), app.ID(), tokenString)
 		ar.UserStorage.UpdateLoginMetadata(user.ID())
-		setCookie(w, CookieKeyWebCookieToken, tokenString, int(ar.TokenService.WebCookieTokenLifespan()))
+		ar.authSucceeded(r, model.AuthEventLogin, model.AuthMethodPassword, user.ID())
+		ar.setCookie(w, CookieKeyWebCookieToken, tokenString, int(ar.TokenService.WebCookieTokenLifespan()))
 		redirectToLogin()
 	}
 }
@@ -102,7 +117,7 @@ func (ar *Router) Login() http.HandlerFunc {
 func (ar *Router) LoginHandler() http.HandlerFunc {
 	tmpl, err := ar.staticFilesStorage.ParseTemplate(model.StaticPagesNames.Login)
 	if err != nil {
-		ar.Logger.Fatalln("Cannot parse Login template.", err)
+		ar.Logger.Fatal("Cannot parse Login template.", err)
 	}
 	errorPath := path.Join(ar.PathPrefix, "/misconfiguration")
 	tokenValidator := jwtValidator.NewValidator(
@@ -115,7 +130,7 @@ func (ar *Router) LoginHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		app := middleware.AppFromContext(r.Context())
 		if app == nil {
-			ar.Logger.Printf("Error: App not found.")
+			ar.Logger.WithRequest(r).Errorf("Error: App not found.")
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			return
 		}
@@ -123,20 +138,20 @@ func (ar *Router) LoginHandler() http.HandlerFunc {
 		scopesJSON := strings.TrimSpace(r.URL.Query().Get(scopesKey))
 		scopes := []string{}
 		if err := json.Unmarshal([]byte(scopesJSON), &scopes); err != nil {
-			ar.Logger.Printf("Error: Invalid scopes %v", scopesJSON)
+			ar.Logger.WithRequest(r).Errorf("Error: Invalid scopes %v", scopesJSON)
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			return
 		}
 
 		callbackURL := strings.TrimSpace(r.URL.Query().Get(callbackURLKey))
 		if !contains(app.RedirectURLs(), callbackURL) {
-			ar.Logger.Printf("Unauthorized redirect url %v for app %v", callbackURL, app.ID())
+			ar.Logger.WithRequest(r).Warnf("Unauthorized redirect url %v for app %v", callbackURL, app.ID())
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			return
 		}
 
 		serveTemplate := func() {
-			errorMessage, err := GetFlash(w, r, FlashErrorMessageKey)
+			errorMessage, err := ar.GetFlash(w, r, FlashErrorMessageKey)
 			if err != nil {
 				ar.Error(w, err, http.StatusInternalServerError, "")
 				return
@@ -148,6 +163,7 @@ func (ar *Router) LoginHandler() http.HandlerFunc {
 				"Scopes":      scopesJSON,
 				"CallbackURL": callbackURL,
 				"AppId":       app.ID(),
+				"Federated":   ar.federatedButtons(app, scopesJSON, callbackURL),
 			}
 
 			if err = tmpl.Execute(w, data); err != nil {
@@ -157,23 +173,30 @@ func (ar *Router) LoginHandler() http.HandlerFunc {
 
 		tstr, err := getCookie(r, CookieKeyWebCookieToken)
 		if err != nil || tstr == "" {
-			ar.Logger.Printf("Error getting auth token cookie: %v", err)
-			deleteCookie(w, CookieKeyWebCookieToken)
+			ar.Logger.WithRequest(r).Errorf("Error getting auth token cookie: %v", err)
+			ar.deleteCookie(w, CookieKeyWebCookieToken)
 			serveTemplate()
 			return
 		}
 
 		webCookieToken, err := ar.TokenService.Parse(tstr)
 		if err != nil {
-			ar.Logger.Printf("Error invalid token %v", err)
-			deleteCookie(w, CookieKeyWebCookieToken)
+			ar.Logger.WithRequest(r).Errorf("Error invalid token %v", err)
+			ar.deleteCookie(w, CookieKeyWebCookieToken)
 			serveTemplate()
 			return
 		}
 
 		if err = tokenValidator.Validate(webCookieToken); err != nil {
-			ar.Logger.Printf("Error invalid token %v", err)
-			deleteCookie(w, CookieKeyWebCookieToken)
+			ar.Logger.WithRequest(r).Errorf("Error invalid token %v", err)
+			ar.deleteCookie(w, CookieKeyWebCookieToken)
+			serveTemplate()
+			return
+		}
+
+		if ar.TokenBlacklist.IsBlacklisted(tstr) {
+			ar.Logger.WithRequest(r).Errorf("Error: token is revoked")
+			ar.deleteCookie(w, CookieKeyWebCookieToken)
 			serveTemplate()
 			return
 		}
@@ -181,14 +204,21 @@ func (ar *Router) LoginHandler() http.HandlerFunc {
 		userID := webCookieToken.UserID()
 		user, err := ar.UserStorage.UserByID(userID)
 		if err != nil {
-			ar.Logger.Printf("Error: getting UserByID: %v, userID: %v", err, userID)
+			ar.Logger.WithRequest(r).Errorf("Error: getting UserByID: %v, userID: %v", err, userID)
+			serveTemplate()
+			return
+		}
+
+		if !user.Active() || webCookieToken.IssuedAt() < user.TokensValidAfter() {
+			ar.Logger.WithRequest(r).Errorf("Error: user %v is not active or the token is revoked", userID)
+			ar.deleteCookie(w, CookieKeyWebCookieToken)
 			serveTemplate()
 			return
 		}
 
 		scopes, err = ar.UserStorage.RequestScopes(userID, scopes)
 		if err != nil {
-			ar.Logger.Printf("Error: invalid scopes %v for userID: %v", scopes, userID)
+			ar.Logger.WithRequest(r).Errorf("Error: invalid scopes %v for userID: %v", scopes, userID)
 			serveTemplate()
 			return
 		}
@@ -196,18 +226,20 @@ func (ar *Router) LoginHandler() http.HandlerFunc {
 		// TODO: Add TFA support.
 		token, err := ar.TokenService.NewAccessToken(user, scopes, app, false)
 		if err != nil {
-			ar.Logger.Printf("Error creating token: %v", err)
+			ar.Logger.WithRequest(r).Errorf("Error creating token: %v", err)
 			serveTemplate()
 			return
 		}
 
 		tokenString, err := ar.TokenService.String(token)
 		if err != nil {
-			ar.Logger.Printf("Error stringifying token: %v", err)
+			ar.Logger.WithRequest(r).Errorf("Error stringifying token: %v", err)
 			serveTemplate()
 			return
 		}
 
+		ar.addUserSessionTokens(r, tstr, userID, app.ID(), tokenString)
+
 		redirectURL := callbackURL + "#" + tokenString
 		http.Redirect(w, r, redirectURL, http.StatusFound)
 	}
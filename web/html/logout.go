@@ -6,6 +6,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/madappgang/identifo/model"
 	"github.com/madappgang/identifo/web/middleware"
 )
 
@@ -14,7 +15,16 @@ func (ar *Router) Logout() http.HandlerFunc {
 	errorPath := path.Join(ar.PathPrefix, "/misconfiguration")
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		deleteCookie(w, CookieKeyWebCookieToken)
+		// End the session, so the tokens issued by web cookie get invalidated too.
+		if tstr, err := getCookie(r, CookieKeyWebCookieToken); err == nil && tstr != "" {
+			if err = ar.UserSessionService.RevokeSessionByToken(tstr); err != nil && err != model.ErrUserSessionNotFound {
+				ar.Logger.WithRequest(r).Errorf("Error: revoking user session %v", err)
+			}
+			if token, err := ar.TokenService.Parse(tstr); err == nil {
+				ar.authSucceeded(r, model.AuthEventLogout, "", token.UserID())
+			}
+		}
+		ar.deleteCookie(w, CookieKeyWebCookieToken)
 
 		app := middleware.AppFromContext(r.Context())
 		if app == nil {
@@ -25,7 +35,7 @@ func (ar *Router) Logout() http.HandlerFunc {
 		scopesJSON := strings.TrimSpace(r.URL.Query().Get("scopes"))
 		scopes := []string{}
 		if err := json.Unmarshal([]byte(scopesJSON), &scopes); err != nil {
-			ar.Logger.Printf("Invalid scopes %v", scopesJSON)
+			ar.Logger.WithRequest(r).Errorf("Invalid scopes %v", scopesJSON)
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			return
 		}
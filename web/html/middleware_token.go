@@ -40,13 +40,13 @@ func (ar *Router) ResetTokenMiddleware() negroni.HandlerFunc {
 
 		token, err := ar.TokenService.Parse(tstr)
 		if err != nil {
-			ar.Logger.Printf("Error invalid token: %v", err)
+			ar.Logger.WithRequest(r).Errorf("Error invalid token: %v", err)
 			http.Redirect(w, r, errorPath, http.StatusMovedPermanently)
 			return
 		}
 
 		if err = tokenValidator.Validate(token); err != nil {
-			ar.Logger.Printf("Error invalid token: %v", err)
+			ar.Logger.WithRequest(r).Errorf("Error invalid token: %v", err)
 			http.Redirect(w, r, errorPath, http.StatusMovedPermanently)
 			return
 		}
@@ -6,12 +6,17 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
+	jwtService "github.com/madappgang/identifo/jwt/service"
 	"github.com/madappgang/identifo/model"
 	"github.com/madappgang/identifo/web/authorization"
 	"github.com/madappgang/identifo/web/middleware"
 )
 
+// attributeFormKeyPrefix is a prefix of registration form fields with custom user attributes.
+const attributeFormKeyPrefix = "attr."
+
 // Register creates user.
 func (ar *Router) Register() http.HandlerFunc {
 	errorPath := path.Join(ar.PathPrefix, "/misconfiguration")
@@ -21,10 +26,11 @@ func (ar *Router) Register() http.HandlerFunc {
 		password := r.FormValue(passwordKey)
 		scopesJSON := r.FormValue(scopesKey)
 		callbackURL := r.FormValue(callbackURLKey)
+		inviteToken := r.FormValue(inviteTokenKey)
 		scopes := []string{}
 
 		if err := json.Unmarshal([]byte(scopesJSON), &scopes); err != nil {
-			ar.Logger.Printf("Error: Invalid scopes %v", scopesJSON)
+			ar.Logger.WithRequest(r).Errorf("Error: Invalid scopes %v", scopesJSON)
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			return
 		}
@@ -34,7 +40,7 @@ func (ar *Router) Register() http.HandlerFunc {
 		if isAnonymousStr := r.FormValue(isAnonymousKey); len(isAnonymousStr) > 0 {
 			isAnonymous, err = strconv.ParseBool(isAnonymousStr)
 			if err != nil {
-				ar.Logger.Printf("Error: Invalid anonymous parameter %s", isAnonymousStr)
+				ar.Logger.WithRequest(r).Errorf("Error: Invalid anonymous parameter %s", isAnonymousStr)
 				http.Redirect(w, r, errorPath, http.StatusFound)
 				return
 			}
@@ -50,6 +56,9 @@ func (ar *Router) Register() http.HandlerFunc {
 			q.Set(FormKeyAppID, app.ID())
 			q.Set(scopesKey, scopesJSON)
 			q.Set(callbackURLKey, callbackURL)
+			if inviteToken != "" {
+				q.Set(inviteTokenKey, inviteToken)
+			}
 			r.URL.RawQuery = q.Encode()
 
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusFound)
@@ -67,76 +76,178 @@ func (ar *Router) Register() http.HandlerFunc {
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusFound)
 		}
 
-		if app.RegistrationForbidden() {
-			SetFlash(w, FlashErrorMessageKey, ErrorRegistrationForbidden.Error())
+		// Users invited by admins can register even if registration in the app is forbidden.
+		var invite *model.Invite
+		if inviteToken != "" {
+			invite, err = ar.inviteFromToken(inviteToken, app.ID())
+			if err != nil {
+				ar.Logger.WithRequest(r).Errorf("Error: invalid invite %v", err)
+				ar.SetFlash(w, FlashErrorMessageKey, ErrorInviteInvalid.Error())
+				redirectToRegister()
+				return
+			}
+			if !strings.EqualFold(invite.Email, strings.TrimSpace(username)) {
+				ar.SetFlash(w, FlashErrorMessageKey, ErrorInviteEmailMismatch.Error())
+				redirectToRegister()
+				return
+			}
+		}
+
+		if (invite == nil || !invite.ByAdmin) && app.RegistrationForbidden() {
+			ar.SetFlash(w, FlashErrorMessageKey, ErrorRegistrationForbidden.Error())
 			redirectToRegister()
 			return
 		}
 
-		if isAnonymous && !app.AnonymousRegistrationAllowed() {
-			SetFlash(w, FlashErrorMessageKey, ErrorRegistrationForbidden.Error())
+		if isAnonymous && (invite != nil || !app.AnonymousRegistrationAllowed()) {
+			ar.SetFlash(w, FlashErrorMessageKey, ErrorRegistrationForbidden.Error())
 			redirectToRegister()
 			return
 		}
 
+		role := app.NewUserDefaultRole()
+		if invite != nil {
+			if invite.Role != "" {
+				role = invite.Role
+			}
+			if len(invite.Scopes) > 0 {
+				scopes = invite.Scopes
+			}
+		}
+
 		// Authorize user if the app requires authorization.
 		azi := authorization.AuthzInfo{
 			App:         app,
-			UserRole:    app.NewUserDefaultRole(),
+			UserRole:    role,
 			ResourceURI: r.RequestURI,
 			Method:      r.Method,
 		}
 
 		if err := ar.Authorizer.Authorize(azi); err != nil {
-			SetFlash(w, FlashErrorMessageKey, err.Error())
+			ar.SetFlash(w, FlashErrorMessageKey, err.Error())
 			redirectToRegister()
 			return
 		}
 
 		// Validate password.
 		if err := model.StrongPswd(password); err != nil {
-			SetFlash(w, FlashErrorMessageKey, err.Error())
+			ar.authFailed(r, model.AuthEventRegistration, model.AuthMethodPassword, "", model.AuthFailureWeakPassword)
+			ar.SetFlash(w, FlashErrorMessageKey, err.Error())
+			redirectToRegister()
+			return
+		}
+
+		attributes, err := ar.attributesFromForm(r)
+		if err != nil {
+			ar.SetFlash(w, FlashErrorMessageKey, err.Error())
+			redirectToRegister()
+			return
+		}
+
+		if err := ar.preRegistrationHook(r, model.AuthHookRequest{
+			AppID:    app.ID(),
+			Username: username,
+			Role:     role,
+			Method:   model.AuthMethodPassword,
+		}); err != nil {
+			ar.authFailed(r, model.AuthEventRegistration, model.AuthMethodPassword, "", model.AuthFailureHookDenied)
+			ar.SetFlash(w, FlashErrorMessageKey, err.Error())
 			redirectToRegister()
 			return
 		}
 
 		// Create new user.
-		user, err := ar.UserStorage.AddUserByNameAndPassword(username, password, app.NewUserDefaultRole(), isAnonymous)
+		user, err := ar.UserStorage.AddUserByNameAndPassword(username, password, role, isAnonymous)
 		if err != nil {
 			if err == model.ErrorUserExists {
-				SetFlash(w, FlashErrorMessageKey, err.Error())
+				ar.authFailed(r, model.AuthEventRegistration, model.AuthMethodPassword, "", model.AuthFailureUserExists)
+				ar.SetFlash(w, FlashErrorMessageKey, err.Error())
 				redirectToRegister()
 				return
 			}
 
-			ar.Logger.Printf("Error: creating user by name and password %v.", err)
+			ar.Logger.WithRequest(r).Errorf("Error: creating user by name and password %v.", err)
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			return
 		}
 
+		// User storage cannot create users with attributes, so the user is rolled back if they cannot be set.
+		if len(attributes) > 0 {
+			user.SetAttributes(attributes)
+			updated, err := ar.UserStorage.UpdateUser(user.ID(), user)
+			if err != nil {
+				ar.Logger.WithRequest(r).Errorf("Error: setting user attributes %v.", err)
+				if err = ar.UserStorage.DeleteUser(user.ID()); err != nil {
+					ar.Logger.WithRequest(r).Errorf("Error: deleting user %v.", err)
+				}
+				http.Redirect(w, r, errorPath, http.StatusFound)
+				return
+			}
+			user = updated
+		}
+
+		// The user is rolled back if they cannot join the organization they are invited to,
+		// or if someone has used the invite in the meantime, as invite can be used only once.
+		// Membership is added first, so the invite is not used up when it fails.
+		if invite != nil {
+			if invite.OrgID != "" {
+				if _, err = ar.OrganizationStorage.SetMember(model.OrganizationMember{
+					OrgID:    invite.OrgID,
+					UserID:   user.ID(),
+					Role:     invite.OrgRole,
+					JoinedAt: time.Now().Unix(),
+				}); err != nil {
+					ar.Logger.WithRequest(r).Errorf("Error: adding organization member %v.", err)
+					if err = ar.UserStorage.DeleteUser(user.ID()); err != nil {
+						ar.Logger.WithRequest(r).Errorf("Error: deleting user %v.", err)
+					}
+					http.Redirect(w, r, errorPath, http.StatusFound)
+					return
+				}
+			}
+
+			if err = ar.InviteStorage.ConsumeInvite(invite.ID, user.ID()); err != nil {
+				ar.Logger.WithRequest(r).Errorf("Error: consuming invite %v.", err)
+				if invite.OrgID != "" {
+					if err = ar.OrganizationStorage.RemoveMember(invite.OrgID, user.ID()); err != nil {
+						ar.Logger.WithRequest(r).Errorf("Error: removing organization member %v.", err)
+					}
+				}
+				if err = ar.UserStorage.DeleteUser(user.ID()); err != nil {
+					ar.Logger.WithRequest(r).Errorf("Error: deleting user %v.", err)
+				}
+				ar.SetFlash(w, FlashErrorMessageKey, ErrorInviteInvalid.Error())
+				redirectToRegister()
+				return
+			}
+		}
+
+		ar.authSucceeded(r, model.AuthEventRegistration, model.AuthMethodPassword, user.ID())
+
 		// Do login flow.
 		scopes, err = ar.UserStorage.RequestScopes(user.ID(), scopes)
 		if err != nil {
-			ar.Logger.Printf("Error: requesting scopes %v.", err)
+			ar.Logger.WithRequest(r).Errorf("Error: requesting scopes %v.", err)
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			return
 		}
 
 		token, err := ar.TokenService.NewWebCookieToken(user)
 		if err != nil {
-			ar.Logger.Printf("Error creating auth token %v", err)
+			ar.Logger.WithRequest(r).Errorf("Error creating auth token %v", err)
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			return
 		}
 
 		tokenString, err := ar.TokenService.String(token)
 		if err != nil {
-			ar.Logger.Printf("Error stringifying token: %v", err)
+			ar.Logger.WithRequest(r).Errorf("Error stringifying token: %v", err)
 			http.Redirect(w, r, errorPath, http.StatusFound)
 			return
 		}
 
-		setCookie(w, CookieKeyWebCookieToken, tokenString, int(ar.TokenService.WebCookieTokenLifespan()))
+		ar.startUserSession(r, user.ID(), app.ID(), tokenString)
+		ar.setCookie(w, CookieKeyWebCookieToken, tokenString, int(ar.TokenService.WebCookieTokenLifespan()))
 		redirectToLogin()
 	}
 }
@@ -145,7 +256,7 @@ func (ar *Router) Register() http.HandlerFunc {
 func (ar *Router) RegistrationHandler() http.HandlerFunc {
 	tmpl, err := ar.staticFilesStorage.ParseTemplate(model.StaticPagesNames.Registration)
 	if err != nil {
-		ar.Logger.Fatalln("Cannot parse Registration template.", err)
+		ar.Logger.Fatal("Cannot parse Registration template.", err)
 	}
 	errorPath := path.Join(ar.PathPrefix, "/misconfiguration")
 
@@ -159,15 +270,15 @@ func (ar *Router) RegistrationHandler() http.HandlerFunc {
 		scopes := []string{}
 		if scopesJSON != "" {
 			if err := json.Unmarshal([]byte(scopesJSON), &scopes); err != nil {
-				ar.Logger.Printf("Error: Invalid scopes %v. Error: %v", scopesJSON, err)
+				ar.Logger.WithRequest(r).Errorf("Error: Invalid scopes %v. Error: %v", scopesJSON, err)
 				http.Redirect(w, r, errorPath, http.StatusFound)
 				return
 			}
 		}
 
-		errorMessage, err := GetFlash(w, r, FlashErrorMessageKey)
+		errorMessage, err := ar.GetFlash(w, r, FlashErrorMessageKey)
 		if err != nil {
-			ar.Logger.Printf("Error: getting flash message %v", err)
+			ar.Logger.WithRequest(r).Errorf("Error: getting flash message %v", err)
 			ar.Error(w, err, http.StatusInternalServerError, "")
 			return
 		}
@@ -178,6 +289,8 @@ func (ar *Router) RegistrationHandler() http.HandlerFunc {
 			"Scopes":      scopesJSON,
 			"CallbackUrl": strings.TrimSpace(r.URL.Query().Get(callbackURLKey)),
 			"AppId":       app.ID(),
+			"InviteToken": strings.TrimSpace(r.URL.Query().Get(inviteTokenKey)),
+			"Attributes":  ar.UserAttributes.RegistrationAttributes(),
 		}
 
 		if err = tmpl.Execute(w, data); err != nil {
@@ -185,3 +298,48 @@ func (ar *Router) RegistrationHandler() http.HandlerFunc {
 		}
 	}
 }
+
+// inviteFromToken returns valid invite for the app referred by the invite token.
+func (ar *Router) inviteFromToken(tokenString, appID string) (*model.Invite, error) {
+	token, err := ar.TokenService.Parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if err = token.Validate(); err != nil {
+		return nil, err
+	}
+	if token.Type() != jwtService.InviteTokenType {
+		return nil, ErrorInviteInvalid
+	}
+
+	invite, err := ar.InviteStorage.InviteByID(token.ID())
+	if err != nil {
+		return nil, err
+	}
+	if invite.AppID != appID || !invite.IsValid(time.Now().Unix()) {
+		return nil, model.ErrInviteNotValid
+	}
+	return &invite, nil
+}
+
+// attributesFromForm reads and validates custom attributes of the registration form.
+// Unchecked boolean attributes are false.
+func (ar *Router) attributesFromForm(r *http.Request) (map[string]interface{}, error) {
+	attributes := make(map[string]interface{})
+	for _, a := range ar.UserAttributes.RegistrationAttributes() {
+		value := strings.TrimSpace(r.FormValue(attributeFormKeyPrefix + a.Name))
+		if a.Type == model.UserAttributeTypeBoolean {
+			value = strconv.FormatBool(value == "on" || value == "true")
+		}
+		if value == "" {
+			continue
+		}
+
+		v, err := ar.UserAttributes.ParseValue(a.Name, value)
+		if err != nil {
+			return nil, err
+		}
+		attributes[a.Name] = v
+	}
+	return ar.UserAttributes.MergeUserAttributes(nil, attributes)
+}
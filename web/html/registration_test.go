@@ -0,0 +1,170 @@
+package html
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/storage/mem"
+)
+
+// racingInviteStorage lets someone else consume the invite just before the registration does.
+type racingInviteStorage struct {
+	model.InviteStorage
+}
+
+func (s racingInviteStorage) ConsumeInvite(id, userID string) error {
+	if err := s.InviteStorage.ConsumeInvite(id, "someone-else"); err != nil {
+		return err
+	}
+	return s.InviteStorage.ConsumeInvite(id, userID)
+}
+
+// registerWithInvite posts the registration form with the invite token for the invite email.
+func registerWithInvite(t *testing.T, ar *Router, app model.AppData, invite model.Invite) *httptest.ResponseRecorder {
+	token, err := ar.TokenService.NewInviteToken(invite)
+	if err != nil {
+		t.Fatalf("Error creating invite token: %s", err)
+	}
+	tokenString, err := ar.TokenService.String(token)
+	if err != nil {
+		t.Fatalf("Error stringifying invite token: %s", err)
+	}
+
+	form := url.Values{}
+	form.Set(usernameKey, invite.Email)
+	form.Set(passwordKey, "Secret123!")
+	form.Set(scopesKey, "[]")
+	form.Set(callbackURLKey, testCallbackURL)
+	form.Set(inviteTokenKey, tokenString)
+
+	r := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), model.AppDataContextKey, app))
+	w := httptest.NewRecorder()
+	ar.Register()(w, r)
+	return w
+}
+
+func addTestInvite(t *testing.T, ar *Router, app model.AppData, email string) model.Invite {
+	return addInvite(t, ar, model.Invite{AppID: app.ID(), Email: email})
+}
+
+// addInvite adds the invite valid for an hour.
+func addInvite(t *testing.T, ar *Router, invite model.Invite) model.Invite {
+	now := time.Now().Unix()
+	invite.CreatedAt = now
+	invite.ExpiresAt = now + 3600
+	invite, err := ar.InviteStorage.AddInvite(invite)
+	if err != nil {
+		t.Fatalf("Error adding invite: %s", err)
+	}
+	return invite
+}
+
+func TestRegisterWithInvite(t *testing.T) {
+	ar := newTestRouter(t)
+	app := addTestApp(t, ar)
+	invite := addTestInvite(t, ar, app, "invited@example.com")
+
+	w := registerWithInvite(t, ar, app, invite)
+	if w.Code != http.StatusFound || !strings.Contains(w.Header().Get("Location"), "login") {
+		t.Fatalf("Register() = %d %s, expected redirect to login", w.Code, w.Header().Get("Location"))
+	}
+
+	user, err := ar.UserStorage.UserByUsername(invite.Email)
+	if err != nil {
+		t.Fatalf("Error getting registered user: %s", err)
+	}
+	stored, _ := ar.InviteStorage.InviteByID(invite.ID)
+	if stored.UsedBy != user.ID() {
+		t.Errorf("Invite used by = %s, expected %s", stored.UsedBy, user.ID())
+	}
+
+	// The invite cannot be used again.
+	ar.UserStorage.DeleteUser(user.ID())
+	w = registerWithInvite(t, ar, app, invite)
+	if !strings.Contains(w.Header().Get("Location"), "register") {
+		t.Errorf("Register() with used invite redirects to %s, expected registration page", w.Header().Get("Location"))
+	}
+	if _, err = ar.UserStorage.UserByUsername(invite.Email); err != model.ErrUserNotFound {
+		t.Errorf("User registered with used invite, error = %v", err)
+	}
+}
+
+func TestRegisterWithConsumedInviteRollsBack(t *testing.T) {
+	ar := newTestRouter(t)
+	app := addTestApp(t, ar)
+	invite := addTestInvite(t, ar, app, "invited@example.com")
+	ar.InviteStorage = racingInviteStorage{ar.InviteStorage}
+
+	w := registerWithInvite(t, ar, app, invite)
+	if w.Code != http.StatusFound || !strings.Contains(w.Header().Get("Location"), "register") {
+		t.Errorf("Register() = %d %s, expected redirect to registration page", w.Code, w.Header().Get("Location"))
+	}
+	if _, err := ar.UserStorage.UserByUsername(invite.Email); err != model.ErrUserNotFound {
+		t.Errorf("User with consumed invite has not been deleted, error = %v", err)
+	}
+	stored, _ := ar.InviteStorage.InviteByID(invite.ID)
+	if stored.UsedBy != "someone-else" {
+		t.Errorf("Invite used by = %s, expected someone-else", stored.UsedBy)
+	}
+	for _, c := range w.Result().Cookies() {
+		if c.Name == CookieKeyWebCookieToken && c.Value != "" {
+			t.Errorf("Web cookie token set for rolled back user")
+		}
+	}
+}
+
+func TestRegisterWithInviteWhenRegistrationForbidden(t *testing.T) {
+	ar := newTestRouter(t)
+	appData := mem.MakeAppData("closed", "secret", true, "closed", "", nil, false, []string{testCallbackURL}, 0, 0, 0, nil, true, false, model.TFAStatusDisabled, "", model.NoAuthz, "", "", nil, nil, "user")
+	app, err := ar.AppStorage.CreateApp(&appData)
+	if err != nil {
+		t.Fatalf("Error creating app: %s", err)
+	}
+
+	// Invites users request for each other do not open closed registration.
+	userInvite := addInvite(t, ar, model.Invite{AppID: app.ID(), Email: "friend@example.com", InvitedBy: "user"})
+	w := registerWithInvite(t, ar, app, userInvite)
+	if !strings.Contains(w.Header().Get("Location"), "register") {
+		t.Errorf("Register() with user invite redirects to %s, expected registration page", w.Header().Get("Location"))
+	}
+	if _, err = ar.UserStorage.UserByUsername(userInvite.Email); err != model.ErrUserNotFound {
+		t.Errorf("User registered with user invite while registration is forbidden, error = %v", err)
+	}
+	if stored, _ := ar.InviteStorage.InviteByID(userInvite.ID); stored.UsedBy != "" {
+		t.Errorf("Rejected invite is used by %s", stored.UsedBy)
+	}
+
+	adminInvite := addInvite(t, ar, model.Invite{AppID: app.ID(), Email: "employee@example.com", InvitedBy: "admin", ByAdmin: true})
+	w = registerWithInvite(t, ar, app, adminInvite)
+	if !strings.Contains(w.Header().Get("Location"), "login") {
+		t.Errorf("Register() with admin invite redirects to %s, expected login", w.Header().Get("Location"))
+	}
+	if _, err = ar.UserStorage.UserByUsername(adminInvite.Email); err != nil {
+		t.Errorf("User is not registered with admin invite: %v", err)
+	}
+}
+
+func TestRegisterWithOrganizationInviteRollsBack(t *testing.T) {
+	ar := newTestRouter(t)
+	app := addTestApp(t, ar)
+	invite := addInvite(t, ar, model.Invite{AppID: app.ID(), Email: "member@example.com", OrgID: "unknown", OrgRole: model.OrganizationRoleMember})
+
+	w := registerWithInvite(t, ar, app, invite)
+	if w.Code != http.StatusFound || !strings.Contains(w.Header().Get("Location"), "misconfiguration") {
+		t.Errorf("Register() = %d %s, expected redirect to error page", w.Code, w.Header().Get("Location"))
+	}
+	if _, err := ar.UserStorage.UserByUsername(invite.Email); err != model.ErrUserNotFound {
+		t.Errorf("User who cannot join the organization has not been deleted, error = %v", err)
+	}
+	if stored, _ := ar.InviteStorage.InviteByID(invite.ID); stored.UsedBy != "" {
+		t.Errorf("Invite is used up by the rolled back user")
+	}
+}
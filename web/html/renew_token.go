@@ -15,7 +15,7 @@ import (
 func (ar *Router) RenewToken() http.HandlerFunc {
 	tmpl, err := ar.staticFilesStorage.ParseTemplate(model.StaticPagesNames.WebMessage)
 	if err != nil {
-		ar.Logger.Fatalln("Cannot parse WebMessage template.", err)
+		ar.Logger.Fatal("Cannot parse WebMessage template.", err)
 	}
 	tokenValidator := jwtValidator.NewValidator(
 		[]string{"identifo"},
@@ -27,7 +27,7 @@ func (ar *Router) RenewToken() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		serveTemplate := func(errorMessage, AccessToken, redirectURI string) {
 			if err != nil {
-				ar.Logger.Printf("Error parsing template: %v", err)
+				ar.Logger.WithRequest(r).Errorf("Error parsing template: %v", err)
 				ar.Error(w, err, http.StatusInternalServerError, "Error parsing template")
 				return
 			}
@@ -39,7 +39,7 @@ func (ar *Router) RenewToken() http.HandlerFunc {
 			}
 
 			if err := tmpl.Execute(w, data); err != nil {
-				ar.Logger.Printf("Error executing template: %v", err)
+				ar.Logger.WithRequest(r).Errorf("Error executing template: %v", err)
 				ar.Error(w, err, http.StatusInternalServerError, "Error executing template")
 				return
 			}
@@ -49,7 +49,7 @@ func (ar *Router) RenewToken() http.HandlerFunc {
 		app, err := ar.AppStorage.ActiveAppByID(appID)
 		if err != nil {
 			message := fmt.Sprintf("Error getting App by ID: %v", err)
-			ar.Logger.Printf(message)
+			ar.Logger.WithRequest(r).Error(message)
 			serveTemplate(message, "", "")
 			return
 		}
@@ -58,22 +58,29 @@ func (ar *Router) RenewToken() http.HandlerFunc {
 
 		tstr, err := getCookie(r, CookieKeyWebCookieToken)
 		if err != nil || tstr == "" {
-			ar.Logger.Printf("Error getting token from cookie: %v", err)
-			deleteCookie(w, CookieKeyWebCookieToken)
+			ar.Logger.WithRequest(r).Errorf("Error getting token from cookie: %v", err)
+			ar.deleteCookie(w, CookieKeyWebCookieToken)
 			serveTemplate("not authorized", "", redirectURI)
 			return
 		}
 		webCookieToken, err := ar.TokenService.Parse(tstr)
 		if err != nil {
-			ar.Logger.Printf("Error invalid token: %v", err)
-			deleteCookie(w, CookieKeyWebCookieToken)
+			ar.Logger.WithRequest(r).Errorf("Error invalid token: %v", err)
+			ar.deleteCookie(w, CookieKeyWebCookieToken)
 			serveTemplate("not authorized", "", redirectURI)
 			return
 		}
 
 		if err = tokenValidator.Validate(webCookieToken); err != nil {
-			ar.Logger.Printf("Error invalid token: %v", err)
-			deleteCookie(w, CookieKeyWebCookieToken)
+			ar.Logger.WithRequest(r).Errorf("Error invalid token: %v", err)
+			ar.deleteCookie(w, CookieKeyWebCookieToken)
+			serveTemplate("not authorized", "", redirectURI)
+			return
+		}
+
+		if ar.TokenBlacklist.IsBlacklisted(tstr) {
+			ar.Logger.WithRequest(r).Errorf("Error: token is revoked")
+			ar.deleteCookie(w, CookieKeyWebCookieToken)
 			serveTemplate("not authorized", "", redirectURI)
 			return
 		}
@@ -82,23 +89,30 @@ func (ar *Router) RenewToken() http.HandlerFunc {
 
 		user, err := ar.UserStorage.UserByID(userID)
 		if err != nil {
-			ar.Logger.Printf("Error: getting UserByID: %v, userID: %v", err, userID)
-			deleteCookie(w, CookieKeyWebCookieToken)
+			ar.Logger.WithRequest(r).Errorf("Error: getting UserByID: %v, userID: %v", err, userID)
+			ar.deleteCookie(w, CookieKeyWebCookieToken)
 			serveTemplate("invalid user token", "", redirectURI)
 			return
 		}
 
+		if !user.Active() || webCookieToken.IssuedAt() < user.TokensValidAfter() {
+			ar.Logger.WithRequest(r).Errorf("Error: user %v is not active or the token is revoked", userID)
+			ar.deleteCookie(w, CookieKeyWebCookieToken)
+			serveTemplate("not authorized", "", redirectURI)
+			return
+		}
+
 		scopesJSON := strings.TrimSpace(r.URL.Query().Get(scopesKey))
 		scopes := []string{}
 		if err := json.Unmarshal([]byte(scopesJSON), &scopes); err != nil {
-			ar.Logger.Printf("Error: Invalid scopes %v", scopesJSON)
+			ar.Logger.WithRequest(r).Errorf("Error: Invalid scopes %v", scopesJSON)
 			serveTemplate("invalid scopes", "", redirectURI)
 			return
 		}
 
 		scopes, err = ar.UserStorage.RequestScopes(userID, scopes)
 		if err != nil {
-			ar.Logger.Printf("Error: invalid scopes %v for userID: %v", scopes, userID)
+			ar.Logger.WithRequest(r).Errorf("Error: invalid scopes %v for userID: %v", scopes, userID)
 			message := fmt.Sprintf("user not allowed to access this scopes %v", scopes)
 			serveTemplate(message, "", redirectURI)
 			return
@@ -106,18 +120,19 @@ func (ar *Router) RenewToken() http.HandlerFunc {
 
 		token, err := ar.TokenService.NewAccessToken(user, scopes, app, false)
 		if err != nil {
-			ar.Logger.Printf("Error creating token: %v", err)
+			ar.Logger.WithRequest(r).Errorf("Error creating token: %v", err)
 			serveTemplate("server error", "", redirectURI)
 			return
 		}
 
 		tokenString, err := ar.TokenService.String(token)
 		if err != nil {
-			ar.Logger.Printf("Error stringifying token: %v", err)
+			ar.Logger.WithRequest(r).Errorf("Error stringifying token: %v", err)
 			serveTemplate("server error", "", redirectURI)
 			return
 		}
 
+		ar.addUserSessionTokens(r, tstr, userID, app.ID(), tokenString)
 		serveTemplate("", tokenString, redirectURI)
 	}
 }
@@ -12,7 +12,7 @@ func (ar *Router) ResetPassword() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		password := r.FormValue("password")
 		if err := model.StrongPswd(password); err != nil {
-			SetFlash(w, FlashErrorMessageKey, err.Error())
+			ar.SetFlash(w, FlashErrorMessageKey, err.Error())
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
@@ -20,18 +20,21 @@ func (ar *Router) ResetPassword() http.HandlerFunc {
 		tokenString := r.Context().Value(model.TokenRawContextKey).(string)
 		token, err := ar.TokenService.Parse(tokenString)
 		if err != nil {
-			ar.Logger.Println("Error parsing token. ", err)
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.Logger.WithRequest(r).Error("Error parsing token. ", err)
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
 
 		if err = ar.UserStorage.ResetPassword(token.UserID(), password); err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Server Error")
+			ar.authFailed(r, model.AuthEventPasswordReset, model.AuthMethodPassword, token.UserID(), model.AuthFailureUserNotFound)
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error")
 			http.Redirect(w, r, path.Join(ar.PathPrefix, r.URL.String()), http.StatusMovedPermanently)
 			return
 		}
 
+		ar.authSucceeded(r, model.AuthEventPasswordReset, model.AuthMethodPassword, token.UserID())
+
 		successPath := path.Join(ar.PathPrefix, "password/reset/success")
 		http.Redirect(w, r, successPath, http.StatusMovedPermanently)
 	}
@@ -41,11 +44,11 @@ func (ar *Router) ResetPassword() http.HandlerFunc {
 func (ar *Router) ResetPasswordHandler() http.HandlerFunc {
 	tmpl, err := ar.staticFilesStorage.ParseTemplate(model.StaticPagesNames.ResetPassword)
 	if err != nil {
-		ar.Logger.Fatalln("Cannot parse ResetPassword template.", err)
+		ar.Logger.Fatal("Cannot parse ResetPassword template.", err)
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		errorMessage, err := GetFlash(w, r, FlashErrorMessageKey)
+		errorMessage, err := ar.GetFlash(w, r, FlashErrorMessageKey)
 		if err != nil {
 			ar.Error(w, err, http.StatusInternalServerError, "")
 			return
@@ -8,6 +8,8 @@ import (
 	"net/url"
 	"path"
 	"regexp"
+
+	"github.com/madappgang/identifo/model"
 )
 
 const emailExpr = "^[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$"
@@ -20,47 +22,48 @@ func (ar *Router) SendResetToken() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		upath := path.Join(ar.PathPrefix, r.URL.String())
 		if err != nil || regexpErr != nil {
-			SetFlash(w, FlashErrorMessageKey, "Server Error. Try later please")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error. Try later please")
 			http.Redirect(w, r, upath, http.StatusMovedPermanently)
 			return
 		}
 
 		err = r.ParseForm()
 		if err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Invalid request")
+			ar.SetFlash(w, FlashErrorMessageKey, "Invalid request")
 			http.Redirect(w, r, upath, http.StatusMovedPermanently)
 		}
 
 		name := r.FormValue("email")
 		if !emailRegexp.MatchString(name) {
-			SetFlash(w, FlashErrorMessageKey, "Invalid email")
+			ar.SetFlash(w, FlashErrorMessageKey, "Invalid email")
 			http.Redirect(w, r, upath, http.StatusMovedPermanently)
 			return
 		}
 
 		if userExists := ar.UserStorage.UserExists(name); !userExists {
-			SetFlash(w, FlashErrorMessageKey, "This Email is unregistered")
+			ar.authFailed(r, model.AuthEventPasswordResetRequest, "", "", model.AuthFailureUserNotFound)
+			ar.SetFlash(w, FlashErrorMessageKey, "This Email is unregistered")
 			http.Redirect(w, r, upath, http.StatusMovedPermanently)
 			return
 		}
 
 		id, err := ar.UserStorage.IDByName(name)
 		if err != nil {
-			SetFlash(w, FlashErrorMessageKey, "This Email is unregistered")
+			ar.SetFlash(w, FlashErrorMessageKey, "This Email is unregistered")
 			http.Redirect(w, r, upath, http.StatusMovedPermanently)
 			return
 		}
 
 		t, err := ar.TokenService.NewResetToken(id)
 		if err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Server Error. Try later please")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error. Try later please")
 			http.Redirect(w, r, upath, http.StatusMovedPermanently)
 			return
 		}
 
 		token, err := ar.TokenService.String(t)
 		if err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Server Error. Try later please")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error. Try later please")
 			http.Redirect(w, r, upath, http.StatusMovedPermanently)
 			return
 		}
@@ -77,18 +80,20 @@ func (ar *Router) SendResetToken() http.HandlerFunc {
 
 		var tpl bytes.Buffer
 		if err = tmpl.Execute(&tpl, u.String()); err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Server Error. Try later please")
+			ar.SetFlash(w, FlashErrorMessageKey, "Server Error. Try later please")
 			http.Redirect(w, r, upath, http.StatusMovedPermanently)
 			return
 		}
 
 		err = ar.EmailService.SendHTML("Reset Password", tpl.String(), name)
 		if err != nil {
-			SetFlash(w, FlashErrorMessageKey, "Error sending email")
+			ar.SetFlash(w, FlashErrorMessageKey, "Error sending email")
 			http.Redirect(w, r, upath, http.StatusMovedPermanently)
 			return
 		}
 
+		ar.authSucceeded(r, model.AuthEventPasswordResetRequest, "", id)
+
 		url := path.Join(ar.PathPrefix, r.URL.String(), "success")
 		http.Redirect(w, r, url, http.StatusMovedPermanently)
 	}
@@ -3,12 +3,12 @@ package html
 import (
 	"fmt"
 	"io"
-	"log"
 	"net/http"
-	"os"
 
 	"github.com/gorilla/mux"
 	jwtService "github.com/madappgang/identifo/jwt/service"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/metrics"
 	"github.com/madappgang/identifo/model"
 	"github.com/madappgang/identifo/web/authorization"
 	"github.com/rs/cors"
@@ -17,21 +17,29 @@ import (
 
 // Router handles incoming http connections.
 type Router struct {
-	Middleware         *negroni.Negroni
-	Logger             *log.Logger
-	Router             *mux.Router
-	AppStorage         model.AppStorage
-	UserStorage        model.UserStorage
-	TokenStorage       model.TokenStorage
-	TokenBlacklist     model.TokenBlacklist
-	TokenService       jwtService.TokenService
-	SMSService         model.SMSService
-	EmailService       model.EmailService
-	staticFilesStorage model.StaticFilesStorage
-	Authorizer         *authorization.Authorizer
-	PathPrefix         string
-	Host               string
-	cors               *cors.Cors
+	Middleware          *negroni.Negroni
+	Logger              *logging.Logger
+	Router              *mux.Router
+	AppStorage          model.AppStorage
+	UserStorage         model.UserStorage
+	TokenStorage        model.TokenStorage
+	TokenBlacklist      model.TokenBlacklist
+	InviteStorage       model.InviteStorage
+	OrganizationStorage model.OrganizationStorage
+	TokenService        jwtService.TokenService
+	SMSService          model.SMSService
+	EmailService        model.EmailService
+	UserSessionService  model.UserSessionService
+	AuthEventService    model.AuthEventService
+	AuthHookService     model.AuthHookService
+	staticFilesStorage  model.StaticFilesStorage
+	Authorizer          *authorization.Authorizer
+	FederatedProviders  *model.FederatedProviderRegistry
+	SupportedLoginWays  model.LoginWith
+	UserAttributes      model.UserAttributeSchema
+	PathPrefix          string
+	Host                string
+	cors                *cors.Cors
 }
 
 func defaultOptions() []func(*Router) error {
@@ -66,20 +74,49 @@ func CorsOption(corsOptions *model.CorsOptions) func(*Router) error {
 	}
 }
 
+// SupportedLoginWaysOption is for setting supported ways of logging in into the app.
+func SupportedLoginWaysOption(loginWays model.LoginWith) func(*Router) error {
+	return func(r *Router) error {
+		r.SupportedLoginWays = loginWays
+		return nil
+	}
+}
+
+// FederatedProvidersOption sets the registry of supported federated identity providers.
+func FederatedProvidersOption(registry *model.FederatedProviderRegistry) func(*Router) error {
+	return func(r *Router) error {
+		r.FederatedProviders = registry
+		return nil
+	}
+}
+
+// UserAttributesOption sets the schema of custom user profile attributes.
+func UserAttributesOption(schema model.UserAttributeSchema) func(*Router) error {
+	return func(r *Router) error {
+		r.UserAttributes = schema
+		return nil
+	}
+}
+
 // NewRouter creates and initializes new router.
-func NewRouter(logger *log.Logger, as model.AppStorage, us model.UserStorage, sfs model.StaticFilesStorage, ts model.TokenStorage, tb model.TokenBlacklist, tServ jwtService.TokenService, smsServ model.SMSService, emailServ model.EmailService, authorizer *authorization.Authorizer, options ...func(*Router) error) (model.Router, error) {
+func NewRouter(logger *logging.Logger, as model.AppStorage, us model.UserStorage, sfs model.StaticFilesStorage, ts model.TokenStorage, tb model.TokenBlacklist, is model.InviteStorage, ors model.OrganizationStorage, tServ jwtService.TokenService, smsServ model.SMSService, emailServ model.EmailService, usServ model.UserSessionService, aeServ model.AuthEventService, ahServ model.AuthHookService, authorizer *authorization.Authorizer, options ...func(*Router) error) (model.Router, error) {
 	ar := Router{
-		Middleware:         negroni.Classic(),
-		Router:             mux.NewRouter(),
-		AppStorage:         as,
-		UserStorage:        us,
-		TokenStorage:       ts,
-		TokenBlacklist:     tb,
-		TokenService:       tServ,
-		SMSService:         smsServ,
-		EmailService:       emailServ,
-		staticFilesStorage: sfs,
-		Authorizer:         authorizer,
+		Middleware:          negroni.New(),
+		Router:              mux.NewRouter(),
+		AppStorage:          as,
+		UserStorage:         us,
+		TokenStorage:        ts,
+		TokenBlacklist:      tb,
+		InviteStorage:       is,
+		OrganizationStorage: ors,
+		TokenService:        tServ,
+		SMSService:          smsServ,
+		EmailService:        emailServ,
+		UserSessionService:  usServ,
+		AuthEventService:    aeServ,
+		AuthHookService:     ahServ,
+		staticFilesStorage:  sfs,
+		Authorizer:          authorizer,
 	}
 
 	for _, option := range append(defaultOptions(), options...) {
@@ -88,15 +125,17 @@ func NewRouter(logger *log.Logger, as model.AppStorage, us model.UserStorage, sf
 		}
 	}
 
-	// Setup logger to stdout.
-	if logger == nil {
-		ar.Logger = log.New(os.Stdout, "HTML_ROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	ar.Logger = logger
+	if ar.Logger == nil {
+		ar.Logger = logging.Default().With("router", "web")
 	}
+	ar.Middleware.Use(logging.Recovery(ar.Logger))
 
 	if ar.cors != nil {
 		ar.Middleware.Use(ar.cors)
 	}
 	ar.initRoutes()
+	ar.Router.Use(metrics.RouteMiddleware)
 	ar.Middleware.UseHandler(ar.Router)
 
 	return &ar, nil
@@ -105,7 +144,7 @@ func NewRouter(logger *log.Logger, as model.AppStorage, us model.UserStorage, sf
 // Error writes an API error message to the response and logger.
 func (ar *Router) Error(w http.ResponseWriter, err error, code int, userInfo string) {
 	// Log error.
-	ar.Logger.Printf("http error: %s (code=%d)\n", err, code)
+	ar.Logger.Logf(logging.StatusLevel(code), "http error: %s (code=%d)", err, code)
 
 	// Hide error from client if it is internal.
 	if code == http.StatusInternalServerError {
@@ -131,7 +170,7 @@ func (ar *Router) Error(w http.ResponseWriter, err error, code int, userInfo str
 	`
 	w.WriteHeader(code)
 	if _, wrErr := io.WriteString(w, responseString); wrErr != nil {
-		ar.Logger.Println("Error writing response string:", wrErr)
+		ar.Logger.Error("Error writing response string:", wrErr)
 	}
 }
 
@@ -0,0 +1,90 @@
+package html
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	authhooks "github.com/madappgang/identifo/external_services/auth_hooks"
+	ijwt "github.com/madappgang/identifo/jwt"
+	jwtService "github.com/madappgang/identifo/jwt/service"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/storage/boltdb"
+	"github.com/madappgang/identifo/storage/mem"
+	"github.com/madappgang/identifo/web/authorization"
+)
+
+// newTestRouter creates router with BoltDB user storage in a temporary file, in-memory storages
+// and the token service signing with the test keys.
+func newTestRouter(t *testing.T) *Router {
+	dir, err := ioutil.TempDir("", "identifo-html")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %s", err)
+	}
+	db, err := boltdb.InitDB(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatalf("Error opening database: %s", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		os.RemoveAll(dir)
+	})
+
+	userStorage, err := boltdb.NewUserStorage(db)
+	if err != nil {
+		t.Fatalf("Error creating user storage: %s", err)
+	}
+	appStorage, _ := mem.NewAppStorage()
+	tokenStorage, _ := mem.NewTokenStorage()
+	tokenBlacklist, _ := mem.NewTokenBlacklist()
+	inviteStorage, _ := mem.NewInviteStorage()
+	organizationStorage, _ := mem.NewOrganizationStorage()
+	userSessionStorage, _ := mem.NewUserSessionStorage()
+	authEventStorage, _ := mem.NewAuthEventStorage()
+	authHookService := authhooks.NewAuthHookService(model.AuthHooksSettings{})
+	authEventService := model.NewAuthEventRecorder(authEventStorage)
+	t.Cleanup(authEventService.Close)
+
+	private, err := ijwt.LoadPrivateKeyFromPEM("../../jwt/private.pem", ijwt.TokenSignatureAlgorithmES256)
+	if err != nil {
+		t.Fatalf("Error loading private key: %s", err)
+	}
+	public, err := ijwt.LoadPublicKeyFromPEM("../../jwt/public.pem", ijwt.TokenSignatureAlgorithmES256)
+	if err != nil {
+		t.Fatalf("Error loading public key: %s", err)
+	}
+	keys := &model.JWTKeys{Private: private, Public: public, Algorithm: ijwt.TokenSignatureAlgorithmES256}
+	tokenService, err := jwtService.NewJWTokenService(keys, "identifo.test", tokenStorage, appStorage, userStorage)
+	if err != nil {
+		t.Fatalf("Error creating token service: %s", err)
+	}
+
+	return &Router{
+		Logger:              logging.New(ioutil.Discard, logging.LevelError, 0),
+		AppStorage:          appStorage,
+		UserStorage:         userStorage,
+		TokenStorage:        tokenStorage,
+		TokenBlacklist:      tokenBlacklist,
+		InviteStorage:       inviteStorage,
+		OrganizationStorage: organizationStorage,
+		TokenService:        tokenService,
+		UserSessionService:  model.NewUserSessionManager(userSessionStorage, tokenStorage, tokenBlacklist),
+		AuthEventService:    authEventService,
+		AuthHookService:     authHookService,
+		Authorizer:          authorization.NewAuthorizer(authHookService, nil, nil),
+		PathPrefix:          "/web",
+		Host:                "https://auth.example.com",
+	}
+}
+
+// addTestApp adds active app with the callback URL allowed.
+func addTestApp(t *testing.T, ar *Router) model.AppData {
+	appData := mem.MakeAppData("app", "secret", true, "test", "", nil, false, []string{testCallbackURL}, 0, 0, 0, nil, false, false, model.TFAStatusDisabled, "", model.NoAuthz, "", "", nil, nil, "user")
+	app, err := ar.AppStorage.CreateApp(&appData)
+	if err != nil {
+		t.Fatalf("Error creating app: %s", err)
+	}
+	return app
+}
@@ -63,6 +63,13 @@ func (ar *Router) initRoutes() {
 		negroni.WrapFunc(ar.RegistrationHandler()),
 	)).Methods("GET")
 
+	ar.Router.Path(`/federated/{provider}/{start:start/?}`).Handler(negroni.New(
+		ar.AppID(),
+		negroni.WrapFunc(ar.FederatedLoginStart()),
+	)).Methods("GET")
+
+	ar.Router.HandleFunc(`/federated/{provider}/{callback:callback/?}`, ar.FederatedLoginCallback()).Methods("GET")
+
 	ar.Router.HandleFunc(`/token/{renew:renew/?}`, ar.RenewToken()).Methods("GET")
 	ar.Router.Path(`/{logout:logout/?}`).Handler(negroni.New(
 		ar.AppID(),
@@ -0,0 +1,29 @@
+package html
+
+import (
+	"net/http"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/web/middleware"
+)
+
+// startUserSession records new session with the web cookie token issued on login.
+// Login does not fail if the session cannot be recorded.
+func (ar *Router) startUserSession(r *http.Request, userID, appID string, tokens ...string) {
+	if _, err := ar.UserSessionService.StartSession(userID, appID, r.UserAgent(), middleware.ClientIP(r), tokens...); err != nil {
+		ar.Logger.WithRequest(r).Errorf("Error: starting user session %v", err)
+	}
+}
+
+// addUserSessionTokens adds tokens issued with the web cookie token to its session.
+func (ar *Router) addUserSessionTokens(r *http.Request, webCookieToken, userID, appID string, tokens ...string) {
+	err := ar.UserSessionService.AddSessionTokens(webCookieToken, tokens...)
+	if err == model.ErrUserSessionNotFound {
+		// Web cookie has been issued before sessions were tracked.
+		ar.startUserSession(r, userID, appID, append([]string{webCookieToken}, tokens...)...)
+		return
+	}
+	if err != nil {
+		ar.Logger.WithRequest(r).Errorf("Error: adding user session tokens %v", err)
+	}
+}
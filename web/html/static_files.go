@@ -19,7 +19,7 @@ func (ar *Router) HTMLFileHandler(templateName string) http.HandlerFunc {
 			return
 		}
 
-		errorMessage, err := GetFlash(w, r, FlashErrorMessageKey)
+		errorMessage, err := ar.GetFlash(w, r, FlashErrorMessageKey)
 		if err != nil {
 			ar.Error(w, err, http.StatusInternalServerError, "")
 			return
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/urfave/negroni"
+)
+
+// ClientIPResolver returns middleware that puts IP address of the client to the request context.
+// Proxy headers can be set by anyone, so they are honoured only in requests from the trusted proxies.
+func ClientIPResolver(trustedProxies []*net.IPNet) negroni.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		ctx := context.WithValue(r.Context(), model.ClientIPContextKey, resolveClientIP(r, trustedProxies))
+		next(rw, r.WithContext(ctx))
+	}
+}
+
+// ClientIP returns IP address of the client, resolved by ClientIPResolver.
+// Without it, the address of the connection peer is returned.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(model.ClientIPContextKey).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// resolveClientIP walks X-Forwarded-For from the nearest hop and returns the first address that is not a trusted proxy.
+func resolveClientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	ip := remoteIP(r)
+	if !isTrusted(ip, trustedProxies) {
+		return ip
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip = strings.TrimSpace(hops[i])
+			if !isTrusted(ip, trustedProxies) {
+				return ip
+			}
+		}
+		return ip
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-Ip")); realIP != "" {
+		return realIP
+	}
+	return ip
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func isTrusted(ip string, trustedProxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
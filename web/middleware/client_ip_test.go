@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/madappgang/identifo/model"
+)
+
+func TestClientIPResolver(t *testing.T) {
+	trusted, err := model.GeneralServerSettings{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"}}.TrustedProxyNetworks()
+	if err != nil {
+		t.Fatalf("Error parsing trusted proxies: %s", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		expected   string
+	}{
+		{"direct request", "203.0.113.7:1234", "", "", "203.0.113.7"},
+		{"forged forwarded header", "203.0.113.7:1234", "198.51.100.1", "", "203.0.113.7"},
+		{"forged real ip header", "203.0.113.7:1234", "", "198.51.100.1", "203.0.113.7"},
+		{"trusted proxy", "10.1.2.3:1234", "198.51.100.1", "", "198.51.100.1"},
+		{"trusted proxy address", "192.168.1.1:1234", "198.51.100.1", "", "198.51.100.1"},
+		{"chain of trusted proxies", "10.1.2.3:1234", "198.51.100.1, 10.0.0.5", "", "198.51.100.1"},
+		{"address spoofed by the client", "10.1.2.3:1234", "1.1.1.1, 198.51.100.1", "", "198.51.100.1"},
+		{"only trusted hops", "10.1.2.3:1234", "10.0.0.6, 10.0.0.5", "", "10.0.0.6"},
+		{"real ip from trusted proxy", "10.1.2.3:1234", "", "198.51.100.1", "198.51.100.1"},
+		{"trusted proxy without headers", "10.1.2.3:1234", "", "", "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-Ip", tt.realIP)
+			}
+
+			var got string
+			ClientIPResolver(trusted)(httptest.NewRecorder(), req, func(w http.ResponseWriter, r *http.Request) {
+				got = ClientIP(r)
+			})
+			if got != tt.expected {
+				t.Errorf("ClientIP() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClientIPWithoutResolver(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.7:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	if got := ClientIP(req); got != "203.0.113.7" {
+		t.Errorf("ClientIP() = %q, expected the connection address", got)
+	}
+}
@@ -1,16 +1,23 @@
 package web
 
 import (
-	"log"
+	"net"
 	"net/http"
 
+	"github.com/madappgang/identifo/health"
 	jwtService "github.com/madappgang/identifo/jwt/service"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/metrics"
 	"github.com/madappgang/identifo/model"
+	"github.com/madappgang/identifo/tracing"
 	"github.com/madappgang/identifo/web/admin"
 	"github.com/madappgang/identifo/web/adminpanel"
 	"github.com/madappgang/identifo/web/api"
 	"github.com/madappgang/identifo/web/authorization"
 	"github.com/madappgang/identifo/web/html"
+	"github.com/madappgang/identifo/web/middleware"
+	"github.com/madappgang/identifo/web/scim"
+	"github.com/urfave/negroni"
 )
 
 // RouterSetting contains settings for root http router.
@@ -20,37 +27,68 @@ type RouterSetting struct {
 	TokenStorage            model.TokenStorage
 	TokenBlacklist          model.TokenBlacklist
 	VerificationCodeStorage model.VerificationCodeStorage
+	InviteStorage           model.InviteStorage
+	UserSessionStorage      model.UserSessionStorage
+	AdminStorage            model.AdminStorage
+	AuditStorage            model.AuditStorage
+	AuthEventStorage        model.AuthEventStorage
+	WebhookStorage          model.WebhookStorage
+	OrganizationStorage     model.OrganizationStorage
+	SCIMTokenStorage        model.SCIMTokenStorage
+	PolicyStorage           model.PolicyStorage
+	RoleStorage             model.RoleStorage
 	TokenService            jwtService.TokenService
 	SMSService              model.SMSService
 	EmailService            model.EmailService
+	UserSessionService      model.UserSessionService
+	AuthEventService        model.AuthEventService
+	WebhookService          model.WebhookService
+	AuthHookService         model.AuthHookService
 	SessionService          model.SessionService
 	SessionStorage          model.SessionStorage
 	StaticFilesStorage      model.StaticFilesStorage
 	ConfigurationStorage    model.ConfigurationStorage
-	Logger                  *log.Logger
+	Logger                  *logging.Logger
 	ServeAdminPanel         bool
+	Metrics                 model.MetricsSettings
+	TrustedProxies          []*net.IPNet
+	HealthChecker           *health.Checker
 	APIRouterSettings       []func(*api.Router) error
 	WebRouterSettings       []func(*html.Router) error
 	AdminRouterSettings     []func(*admin.Router) error
+	SCIMRouterSettings      []func(*scim.Router) error
 }
 
 // NewRouter creates and inits root http router.
 func NewRouter(settings RouterSetting) (model.Router, error) {
 	r := Router{}
 	var err error
-	authorizer := authorization.NewAuthorizer()
+	authorizer := authorization.NewAuthorizer(settings.AuthHookService, settings.PolicyStorage, settings.RoleStorage)
+
+	logger := settings.Logger
+	if logger == nil {
+		logger = logging.Default()
+	}
 
 	r.APIRouter, err = api.NewRouter(
-		settings.Logger,
+		logger.With("router", "api"),
 		settings.AppStorage,
 		settings.UserStorage,
 		settings.TokenStorage,
 		settings.TokenBlacklist,
 		settings.VerificationCodeStorage,
+		settings.InviteStorage,
+		settings.UserSessionStorage,
+		settings.AuthEventStorage,
+		settings.OrganizationStorage,
 		settings.StaticFilesStorage,
 		settings.TokenService,
 		settings.SMSService,
 		settings.EmailService,
+		settings.UserSessionService,
+		settings.AuthEventService,
+		settings.WebhookService,
+		settings.AuthHookService,
 		authorizer,
 		settings.APIRouterSettings...,
 	)
@@ -59,15 +97,20 @@ func NewRouter(settings RouterSetting) (model.Router, error) {
 	}
 
 	r.WebRouter, err = html.NewRouter(
-		settings.Logger,
+		logger.With("router", "web"),
 		settings.AppStorage,
 		settings.UserStorage,
 		settings.StaticFilesStorage,
 		settings.TokenStorage,
 		settings.TokenBlacklist,
+		settings.InviteStorage,
+		settings.OrganizationStorage,
 		settings.TokenService,
 		settings.SMSService,
 		settings.EmailService,
+		settings.UserSessionService,
+		settings.AuthEventService,
+		settings.AuthHookService,
 		authorizer,
 		settings.WebRouterSettings...,
 	)
@@ -77,13 +120,28 @@ func NewRouter(settings RouterSetting) (model.Router, error) {
 
 	if settings.ServeAdminPanel {
 		r.AdminRouter, err = admin.NewRouter(
-			settings.Logger,
+			logger.With("router", "admin"),
 			settings.SessionService,
 			settings.SessionStorage,
 			settings.AppStorage,
 			settings.UserStorage,
+			settings.InviteStorage,
+			settings.UserSessionStorage,
+			settings.AdminStorage,
+			settings.AuditStorage,
+			settings.AuthEventStorage,
+			settings.WebhookStorage,
+			settings.OrganizationStorage,
+			settings.SCIMTokenStorage,
+			settings.PolicyStorage,
+			settings.RoleStorage,
 			settings.ConfigurationStorage,
 			settings.StaticFilesStorage,
+			settings.TokenService,
+			settings.EmailService,
+			settings.UserSessionService,
+			settings.WebhookService,
+			authorizer,
 			settings.AdminRouterSettings...,
 		)
 		if err != nil {
@@ -98,10 +156,25 @@ func NewRouter(settings RouterSetting) (model.Router, error) {
 		r.AdminPanelRouterPath = "/adminpanel"
 	}
 
+	r.SCIMRouter, err = scim.NewRouter(
+		logger.With("router", "scim"),
+		settings.UserStorage,
+		settings.OrganizationStorage,
+		settings.SCIMTokenStorage,
+		settings.WebhookService,
+		settings.SCIMRouterSettings...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	r.APIRouterPath = "/api"
 	r.WebRouterPath = "/web"
+	r.SCIMRouterPath = "/scim/v2"
 
 	r.setupRoutes()
+	r.setupMiddleware(logger, settings.Metrics, settings.TrustedProxies)
+	r.setupHealth(settings.HealthChecker)
 	return &r, nil
 }
 
@@ -111,26 +184,62 @@ type Router struct {
 	WebRouter        model.Router
 	AdminRouter      model.Router
 	AdminPanelRouter model.Router
+	SCIMRouter       model.Router
 	RootRouter       *http.ServeMux
+	// handler is the root router, wrapped with logging and metrics middleware.
+	handler http.Handler
 
 	APIRouterPath        string
 	WebRouterPath        string
 	AdminRouterPath      string
 	AdminPanelRouterPath string
+	SCIMRouterPath       string
 }
 
 // ServeHTTP implements identifo.Router interface.
 func (ar *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Reroute to our internal implementation.
-	ar.RootRouter.ServeHTTP(w, r)
+	ar.handler.ServeHTTP(w, r)
 }
 
 func (ar *Router) setupRoutes() {
 	ar.RootRouter = http.NewServeMux()
 	ar.RootRouter.Handle("/", ar.APIRouter)
 	ar.RootRouter.Handle(ar.WebRouterPath+"/", http.StripPrefix(ar.WebRouterPath, ar.WebRouter))
+	ar.RootRouter.Handle(ar.SCIMRouterPath+"/", http.StripPrefix(ar.SCIMRouterPath, ar.SCIMRouter))
 	if ar.AdminRouter != nil && ar.AdminPanelRouter != nil {
 		ar.RootRouter.Handle(ar.AdminRouterPath+"/", http.StripPrefix(ar.AdminRouterPath, ar.AdminRouter))
 		ar.RootRouter.Handle(ar.AdminPanelRouterPath+"/", http.StripPrefix(ar.AdminPanelRouterPath, ar.AdminPanelRouter))
 	}
 }
+
+// setupMiddleware sets up request tracing, client IP resolution and logging and, if they are enabled, exposes the metrics and starts collecting HTTP request metrics.
+// Tracing goes first, so request logs carry the trace ID.
+func (ar *Router) setupMiddleware(logger *logging.Logger, metricsSettings model.MetricsSettings, trustedProxies []*net.IPNet) {
+	n := negroni.New(tracing.Middleware(), middleware.ClientIPResolver(trustedProxies), logging.Middleware(logger))
+
+	if metricsSettings.Enabled {
+		path := metricsSettings.Path
+		if len(path) == 0 {
+			path = model.DefaultMetricsPath
+		}
+		ar.RootRouter.Handle(path, metrics.Handler(metricsSettings.Token))
+		n.Use(metrics.Middleware())
+	}
+
+	n.UseHandler(ar.RootRouter)
+	ar.handler = n
+}
+
+// setupHealth exposes liveness and readiness endpoints, if the checker is set.
+// They bypass the middleware, so frequent probes do not flood the log.
+func (ar *Router) setupHealth(checker *health.Checker) {
+	if checker == nil {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle(health.LivenessPath, checker.LivenessHandler())
+	mux.Handle(health.ReadinessPath, checker.ReadinessHandler())
+	mux.Handle("/", ar.handler)
+	ar.handler = mux
+}
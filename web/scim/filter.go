@@ -0,0 +1,114 @@
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// scimError is an error with SCIM status and error type.
+type scimError struct {
+	status   int
+	scimType string
+	detail   string
+}
+
+func (e *scimError) Error() string {
+	return e.detail
+}
+
+func badRequest(scimType, format string, args ...interface{}) error {
+	return &scimError{status: http.StatusBadRequest, scimType: scimType, detail: fmt.Sprintf(format, args...)}
+}
+
+// writeError writes the error response, using status of scimError or 500 for others.
+func (ar *Router) writeError(w http.ResponseWriter, err error) {
+	if e, ok := err.(*scimError); ok {
+		ar.Error(w, e.status, e.scimType, e.detail)
+		return
+	}
+	ar.Error(w, http.StatusInternalServerError, "", err.Error())
+}
+
+var filterRegexp = regexp.MustCompile(`^\s*([A-Za-z][\w.:]*)\s+(?i:eq)\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// filter is the only supported filter expression, attribute equality.
+type filter struct {
+	attribute string
+	value     string
+}
+
+// parseFilter parses the `attr eq "value"` filter expression. Empty expression gives nil filter.
+func parseFilter(s string) (*filter, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	m := filterRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return nil, badRequest(scimTypeInvalidFilter, "Unsupported filter %q, only `attribute eq \"value\"` is supported", s)
+	}
+	value, err := strconv.Unquote(m[2])
+	if err != nil {
+		return nil, badRequest(scimTypeInvalidFilter, "Invalid filter value %s", m[2])
+	}
+	return &filter{attribute: stripSchema(m[1]), value: value}, nil
+}
+
+// stripSchema removes schema URI prefix from the attribute path.
+func stripSchema(path string) string {
+	for _, s := range []string{schemaUser, schemaGroup} {
+		if len(path) > len(s) && strings.EqualFold(path[:len(s)+1], s+":") {
+			return path[len(s)+1:]
+		}
+	}
+	return path
+}
+
+// pagination reads 1-based startIndex and count query parameters.
+func pagination(r *http.Request) (startIndex, count int, err error) {
+	startIndex, count = 1, defaultCount
+
+	if v := r.URL.Query().Get("startIndex"); v != "" {
+		if startIndex, err = strconv.Atoi(v); err != nil {
+			return 0, 0, badRequest(scimTypeInvalidValue, "Invalid startIndex %q", v)
+		}
+		if startIndex < 1 {
+			startIndex = 1
+		}
+	}
+	if v := r.URL.Query().Get("count"); v != "" {
+		if count, err = strconv.Atoi(v); err != nil {
+			return 0, 0, badRequest(scimTypeInvalidValue, "Invalid count %q", v)
+		}
+		if count < 0 {
+			count = 0
+		}
+	}
+	if count > maxCount {
+		count = maxCount
+	}
+	return startIndex, count, nil
+}
+
+// page returns the part of the slice of n items selected by startIndex and count.
+func page(n, startIndex, count int) (from, to int) {
+	from = startIndex - 1
+	if from > n {
+		from = n
+	}
+	to = from + count
+	if to > n {
+		to = n
+	}
+	return from, to
+}
+
+// storageLimit converts count to storage limit, where zero means no limit.
+func storageLimit(count int) int {
+	if count == 0 {
+		return 1
+	}
+	return count
+}
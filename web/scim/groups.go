@@ -0,0 +1,327 @@
+package scim
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/madappgang/identifo/model"
+)
+
+type groupResource struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	DisplayName string      `json:"displayName"`
+	Members     []reference `json:"members,omitempty"`
+	Meta        *meta       `json:"meta,omitempty"`
+}
+
+func (ar *Router) groupResource(org model.Organization, withMembers bool) (groupResource, error) {
+	res := groupResource{
+		Schemas:     []string{schemaGroup},
+		ID:          org.ID,
+		DisplayName: org.Name,
+		Meta:        &meta{ResourceType: "Group", Location: ar.location("/Groups", org.ID)},
+	}
+	if !withMembers {
+		return res, nil
+	}
+
+	members, err := ar.organizationStorage.FetchMembers(org.ID)
+	if err != nil {
+		return res, err
+	}
+	for _, m := range members {
+		member := reference{Value: m.UserID, Ref: ar.location("/Users", m.UserID)}
+		if user, err := ar.userStorage.UserByID(m.UserID); err == nil {
+			member.Display = user.Username()
+		}
+		res.Members = append(res.Members, member)
+	}
+	return res, nil
+}
+
+// setMembers makes the organization members to be exactly the given users.
+// New members get the member role, roles of the existing members are kept.
+func (ar *Router) setMembers(orgID string, members []reference) error {
+	desired := map[string]bool{}
+	for _, m := range members {
+		desired[m.Value] = true
+	}
+
+	current, err := ar.organizationStorage.FetchMembers(orgID)
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, m := range current {
+		existing[m.UserID] = true
+		if desired[m.UserID] {
+			continue
+		}
+		if err = ar.organizationStorage.RemoveMember(orgID, m.UserID); err != nil {
+			return err
+		}
+	}
+
+	for userID := range desired {
+		if existing[userID] {
+			continue
+		}
+		if _, err = ar.userStorage.UserByID(userID); err == model.ErrUserNotFound {
+			return badRequest(scimTypeInvalidValue, "User %s not found", userID)
+		} else if err != nil {
+			return err
+		}
+		if _, err = ar.organizationStorage.SetMember(model.OrganizationMember{
+			OrgID:    orgID,
+			UserID:   userID,
+			Role:     model.OrganizationRoleMember,
+			JoinedAt: time.Now().Unix(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupByID fetches the organization from route variable, writing error response on failure.
+func (ar *Router) groupByID(w http.ResponseWriter, r *http.Request) (model.Organization, error) {
+	org, err := ar.organizationStorage.OrganizationByID(mux.Vars(r)["id"])
+	if err == model.ErrOrganizationNotFound {
+		ar.Error(w, http.StatusNotFound, "", "Group not found")
+		return org, err
+	}
+	if err != nil {
+		ar.Error(w, http.StatusInternalServerError, "", err.Error())
+		return org, err
+	}
+	return org, nil
+}
+
+// excludesMembers checks if the client asked not to return group members, which may be expensive.
+func excludesMembers(r *http.Request) bool {
+	for _, a := range strings.Split(r.URL.Query().Get("excludedAttributes"), ",") {
+		if strings.EqualFold(stripSchema(strings.TrimSpace(a)), "members") {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchGroups lists groups, optionally filtered by displayName or id.
+func (ar *Router) FetchGroups() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		startIndex, count, err := pagination(r)
+		if err != nil {
+			ar.writeError(w, err)
+			return
+		}
+		f, err := parseFilter(r.URL.Query().Get("filter"))
+		if err != nil {
+			ar.writeError(w, err)
+			return
+		}
+
+		var orgs []model.Organization
+		var total int
+
+		switch {
+		case f == nil:
+			orgs, total, err = ar.organizationStorage.FetchOrganizations("", startIndex-1, storageLimit(count))
+			if len(orgs) > count {
+				orgs = orgs[:count]
+			}
+		case strings.EqualFold(f.attribute, "displayName"):
+			var found []model.Organization
+			if found, _, err = ar.organizationStorage.FetchOrganizations(f.value, 0, 0); err == nil {
+				for _, o := range found {
+					if o.Name == f.value {
+						orgs = append(orgs, o)
+					}
+				}
+			}
+		case strings.EqualFold(f.attribute, "id"):
+			var o model.Organization
+			if o, err = ar.organizationStorage.OrganizationByID(f.value); err == nil {
+				orgs = []model.Organization{o}
+			}
+		default:
+			ar.Error(w, http.StatusBadRequest, scimTypeInvalidFilter, "Filtering by "+f.attribute+" is not supported")
+			return
+		}
+		if err == model.ErrOrganizationNotFound {
+			err = nil
+		}
+		if err != nil {
+			ar.Error(w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+
+		if f != nil {
+			total = len(orgs)
+			from, to := page(total, startIndex, count)
+			orgs = orgs[from:to]
+		}
+
+		resources := make([]groupResource, len(orgs))
+		for i, o := range orgs {
+			if resources[i], err = ar.groupResource(o, !excludesMembers(r)); err != nil {
+				ar.Error(w, http.StatusInternalServerError, "", err.Error())
+				return
+			}
+		}
+		ar.ServeJSON(w, http.StatusOK, newListResponse(resources, total, startIndex, len(resources)))
+	}
+}
+
+// GetGroup returns the group.
+func (ar *Router) GetGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		org, err := ar.groupByID(w, r)
+		if err != nil {
+			return
+		}
+		ar.serveGroup(w, http.StatusOK, org, !excludesMembers(r))
+	}
+}
+
+// CreateGroup provisions new group as the organization.
+func (ar *Router) CreateGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res := groupResource{}
+		if ar.parseJSON(w, r, &res) != nil {
+			return
+		}
+		if res.DisplayName = strings.TrimSpace(res.DisplayName); res.DisplayName == "" {
+			ar.Error(w, http.StatusBadRequest, scimTypeInvalidValue, "displayName is required")
+			return
+		}
+
+		org, err := ar.organizationStorage.AddOrganization(model.Organization{
+			Name:      res.DisplayName,
+			CreatedAt: time.Now().Unix(),
+		})
+		if err != nil {
+			ar.Error(w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+		if err = ar.setMembers(org.ID, res.Members); err != nil {
+			ar.writeError(w, err)
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Group %s provisioned", org.ID)
+		ar.serveGroup(w, http.StatusCreated, org, true)
+	}
+}
+
+// ReplaceGroup replaces group name and members.
+func (ar *Router) ReplaceGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		org, err := ar.groupByID(w, r)
+		if err != nil {
+			return
+		}
+		res := groupResource{}
+		if ar.parseJSON(w, r, &res) != nil {
+			return
+		}
+		ar.updateGroup(w, org, res)
+	}
+}
+
+// PatchGroup modifies group name and members with PATCH operations.
+func (ar *Router) PatchGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		org, err := ar.groupByID(w, r)
+		if err != nil {
+			return
+		}
+		p := patchRequest{}
+		if ar.parseJSON(w, r, &p) != nil {
+			return
+		}
+		if err = p.validate(); err != nil {
+			ar.writeError(w, err)
+			return
+		}
+
+		existing, err := ar.groupResource(org, true)
+		if err != nil {
+			ar.Error(w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+		current, err := toMap(existing)
+		if err != nil {
+			ar.Error(w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+		if err = applyPatch(current, p.Operations); err != nil {
+			ar.writeError(w, err)
+			return
+		}
+
+		res := groupResource{}
+		if err = fromMap(current, &res); err != nil {
+			ar.writeError(w, err)
+			return
+		}
+		ar.updateGroup(w, org, res)
+	}
+}
+
+func (ar *Router) updateGroup(w http.ResponseWriter, org model.Organization, res groupResource) {
+	if res.ID != "" && res.ID != org.ID {
+		ar.Error(w, http.StatusBadRequest, scimTypeMutability, "id cannot be changed")
+		return
+	}
+	if res.DisplayName = strings.TrimSpace(res.DisplayName); res.DisplayName == "" {
+		ar.Error(w, http.StatusBadRequest, scimTypeInvalidValue, "displayName is required")
+		return
+	}
+
+	if res.DisplayName != org.Name {
+		org.Name = res.DisplayName
+		updated, err := ar.organizationStorage.UpdateOrganization(org)
+		if err != nil {
+			ar.Error(w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+		org = updated
+	}
+	if err := ar.setMembers(org.ID, res.Members); err != nil {
+		ar.writeError(w, err)
+		return
+	}
+
+	ar.logger.Infof("Group %s updated", org.ID)
+	ar.serveGroup(w, http.StatusOK, org, true)
+}
+
+// DeleteGroup deletes the organization with all its memberships.
+func (ar *Router) DeleteGroup() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		org, err := ar.groupByID(w, r)
+		if err != nil {
+			return
+		}
+		if err = ar.organizationStorage.DeleteOrganization(org.ID); err != nil {
+			ar.Error(w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("Group %s deprovisioned", org.ID)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (ar *Router) serveGroup(w http.ResponseWriter, status int, org model.Organization, withMembers bool) {
+	res, err := ar.groupResource(org, withMembers)
+	if err != nil {
+		ar.Error(w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	ar.ServeJSON(w, status, res)
+}
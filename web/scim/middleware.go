@@ -0,0 +1,33 @@
+package scim
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/madappgang/identifo/model"
+	"github.com/urfave/negroni"
+)
+
+const bearerPrefix = "Bearer "
+
+// Token is a middleware which checks the admin-issued SCIM bearer token.
+func (ar *Router) Token() negroni.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		header := r.Header.Get("Authorization")
+		if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+			ar.Error(w, http.StatusUnauthorized, "", "Bearer token is required")
+			return
+		}
+
+		_, err := ar.scimTokenStorage.SCIMTokenByHash(model.HashSCIMToken(strings.TrimSpace(header[len(bearerPrefix):])))
+		if err == model.ErrSCIMTokenNotFound {
+			ar.Error(w, http.StatusUnauthorized, "", "Invalid bearer token")
+			return
+		}
+		if err != nil {
+			ar.Error(w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+		next(w, r)
+	}
+}
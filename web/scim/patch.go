@@ -0,0 +1,300 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations"`
+}
+
+func (p patchRequest) validate() error {
+	supported := false
+	for _, s := range p.Schemas {
+		if s == schemaPatchOp {
+			supported = true
+		}
+	}
+	if !supported {
+		return badRequest(scimTypeInvalidSyntax, "PATCH request must use %s schema", schemaPatchOp)
+	}
+	if len(p.Operations) == 0 {
+		return badRequest(scimTypeInvalidSyntax, "PATCH request has no operations")
+	}
+	return nil
+}
+
+var pathRegexp = regexp.MustCompile(`^(\w+)(?:\[\s*(\w+)\s+(?i:eq)\s+"([^"]*)"\s*\])?(?:\.(\w+))?$`)
+
+// patchPath is a parsed operation path, like `emails[type eq "work"].value`.
+type patchPath struct {
+	attribute   string
+	filterAttr  string
+	filterValue string
+	subAttr     string
+}
+
+func parsePatchPath(path string) (patchPath, error) {
+	m := pathRegexp.FindStringSubmatch(stripSchema(strings.TrimSpace(path)))
+	if m == nil {
+		return patchPath{}, badRequest(scimTypeInvalidPath, "Unsupported path %q", path)
+	}
+	return patchPath{attribute: m[1], filterAttr: m[2], filterValue: m[3], subAttr: m[4]}, nil
+}
+
+// toMap converts the resource to its JSON object representation.
+func toMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// fromMap converts JSON object representation back to the resource.
+func fromMap(m map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return badRequest(scimTypeInvalidValue, "Invalid value after patch: %s", err)
+	}
+	return nil
+}
+
+// applyPatch applies PATCH operations to JSON object representation of the resource.
+func applyPatch(resource map[string]interface{}, operations []patchOperation) error {
+	for _, op := range operations {
+		var err error
+		switch strings.ToLower(op.Op) {
+		case "add":
+			err = patchSet(resource, op, true)
+		case "replace":
+			err = patchSet(resource, op, false)
+		case "remove":
+			err = patchRemove(resource, op)
+		default:
+			err = badRequest(scimTypeInvalidSyntax, "Unsupported operation %q", op.Op)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func patchSet(resource map[string]interface{}, op patchOperation, add bool) error {
+	if op.Path == "" {
+		values, ok := op.Value.(map[string]interface{})
+		if !ok {
+			return badRequest(scimTypeInvalidValue, "Operation without path requires object value")
+		}
+		for k, v := range values {
+			if err := setAttribute(resource, patchPath{attribute: stripSchema(k)}, v, add); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	p, err := parsePatchPath(op.Path)
+	if err != nil {
+		return err
+	}
+	return setAttribute(resource, p, op.Value, add)
+}
+
+func setAttribute(resource map[string]interface{}, p patchPath, value interface{}, add bool) error {
+	key := attributeKey(resource, p.attribute)
+
+	// Value of the complex attribute is merged with existing one.
+	if values, ok := value.(map[string]interface{}); ok && p.filterAttr == "" && p.subAttr == "" {
+		if existing, ok := resource[key].(map[string]interface{}); ok {
+			for k, v := range values {
+				existing[attributeKey(existing, k)] = v
+			}
+			return nil
+		}
+	}
+
+	if p.filterAttr == "" {
+		if p.subAttr == "" {
+			resource[key] = addValues(resource[key], value, add)
+			return nil
+		}
+		complex, ok := resource[key].(map[string]interface{})
+		if !ok {
+			complex = map[string]interface{}{}
+			resource[key] = complex
+		}
+		complex[attributeKey(complex, p.subAttr)] = value
+		return nil
+	}
+
+	items, _ := resource[key].([]interface{})
+	matched := false
+	for i, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok || !matches(obj, p) {
+			continue
+		}
+		matched = true
+		if p.subAttr == "" {
+			items[i] = value
+		} else {
+			obj[attributeKey(obj, p.subAttr)] = value
+		}
+	}
+	if matched {
+		return nil
+	}
+	if p.subAttr == "" && !add {
+		return &scimError{status: http.StatusBadRequest, scimType: scimTypeNoTarget, detail: "No values match the path filter"}
+	}
+
+	// Nothing matched, add new value which satisfies the filter.
+	item := map[string]interface{}{p.filterAttr: p.filterValue}
+	if p.subAttr == "" {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return badRequest(scimTypeInvalidValue, "Value of %s must be an object", p.attribute)
+		}
+		for k, v := range obj {
+			item[k] = v
+		}
+	} else {
+		item[p.subAttr] = value
+	}
+	resource[key] = append(items, item)
+	return nil
+}
+
+// addValues adds the value to multi-valued attribute, or replaces the attribute.
+func addValues(existing, value interface{}, add bool) interface{} {
+	items, isMulti := existing.([]interface{})
+	if !add || !isMulti {
+		return value
+	}
+	newItems, ok := value.([]interface{})
+	if !ok {
+		newItems = []interface{}{value}
+	}
+	for _, n := range newItems {
+		if !containsValue(items, n) {
+			items = append(items, n)
+		}
+	}
+	return items
+}
+
+func patchRemove(resource map[string]interface{}, op patchOperation) error {
+	if op.Path == "" {
+		return &scimError{status: http.StatusBadRequest, scimType: scimTypeNoTarget, detail: "Remove operation requires path"}
+	}
+	p, err := parsePatchPath(op.Path)
+	if err != nil {
+		return err
+	}
+	key := attributeKey(resource, p.attribute)
+
+	if p.filterAttr == "" {
+		if p.subAttr != "" {
+			if complex, ok := resource[key].(map[string]interface{}); ok {
+				delete(complex, attributeKey(complex, p.subAttr))
+			}
+			return nil
+		}
+		// Some clients send the values to remove from multi-valued attribute, like group members.
+		if items, ok := resource[key].([]interface{}); ok && op.Value != nil {
+			toRemove, ok := op.Value.([]interface{})
+			if !ok {
+				toRemove = []interface{}{op.Value}
+			}
+			kept := []interface{}{}
+			for _, item := range items {
+				if !containsValue(toRemove, item) {
+					kept = append(kept, item)
+				}
+			}
+			resource[key] = kept
+			return nil
+		}
+		delete(resource, key)
+		return nil
+	}
+
+	items, _ := resource[key].([]interface{})
+	kept := []interface{}{}
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok || !matches(obj, p) {
+			kept = append(kept, item)
+			continue
+		}
+		if p.subAttr != "" {
+			delete(obj, attributeKey(obj, p.subAttr))
+			kept = append(kept, obj)
+		}
+	}
+	resource[key] = kept
+	return nil
+}
+
+// attributeKey finds existing key case-insensitively, attribute names are case-insensitive in SCIM.
+func attributeKey(m map[string]interface{}, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+func matches(obj map[string]interface{}, p patchPath) bool {
+	v, ok := obj[attributeKey(obj, p.filterAttr)]
+	if !ok {
+		return false
+	}
+	if s, ok := v.(string); ok {
+		return strings.EqualFold(s, p.filterValue)
+	}
+	data, _ := json.Marshal(v)
+	return string(data) == p.filterValue
+}
+
+// containsValue checks if items contain the item. Complex values are compared by their "value" sub-attribute.
+func containsValue(items []interface{}, item interface{}) bool {
+	value := itemValue(item)
+	for _, i := range items {
+		if itemValue(i) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func itemValue(item interface{}) string {
+	if obj, ok := item.(map[string]interface{}); ok {
+		if v, ok := obj[attributeKey(obj, "value")]; ok {
+			item = v
+		}
+	}
+	data, _ := json.Marshal(item)
+	return string(data)
+}
@@ -0,0 +1,101 @@
+package scim
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_applyPatch(t *testing.T) {
+	resource := func() map[string]interface{} {
+		m := map[string]interface{}{}
+		_ = json.Unmarshal([]byte(`{
+			"userName": "bob",
+			"active": true,
+			"name": {"givenName": "Bob"},
+			"emails": [{"value": "bob@example.com", "type": "work", "primary": true}],
+			"members": [{"value": "1"}, {"value": "2"}]
+		}`), &m)
+		return m
+	}
+	ops := func(s string) []patchOperation {
+		p := []patchOperation{}
+		_ = json.Unmarshal([]byte(s), &p)
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		ops     string
+		path    []string
+		want    interface{}
+		wantErr bool
+	}{
+		{"replace simple", `[{"op": "Replace", "path": "active", "value": false}]`, []string{"active"}, false, false},
+		{"replace sub-attribute", `[{"op": "replace", "path": "name.familyName", "value": "Smith"}]`, []string{"name", "familyName"}, "Smith", false},
+		{"replace with schema", `[{"op": "replace", "path": "urn:ietf:params:scim:schemas:core:2.0:User:userName", "value": "alice"}]`, []string{"userName"}, "alice", false},
+		{"no path merges", `[{"op": "replace", "value": {"active": false}}]`, []string{"active"}, false, false},
+		{"filtered sub-attribute", `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "b@example.com"}]`, []string{"emails", "0", "value"}, "b@example.com", false},
+		{"add members", `[{"op": "add", "path": "members", "value": [{"value": "2"}, {"value": "3"}]}]`, []string{"members", "2", "value"}, "3", false},
+		{"remove filtered", `[{"op": "remove", "path": "members[value eq \"1\"]"}]`, []string{"members", "0", "value"}, "2", false},
+		{"remove by value", `[{"op": "remove", "path": "members", "value": [{"value": "2"}]}]`, []string{"members", "0", "value"}, "1", false},
+		{"replace missing target", `[{"op": "replace", "path": "emails[type eq \"home\"]", "value": "x"}]`, nil, nil, true},
+		{"unsupported op", `[{"op": "move", "path": "active"}]`, nil, nil, true},
+		{"invalid path", `[{"op": "replace", "path": "emails[type ne \"work\"]", "value": "x"}]`, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := resource()
+			err := applyPatch(r, ops(tt.ops))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyPatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var got interface{} = r
+			for _, p := range tt.path {
+				switch v := got.(type) {
+				case map[string]interface{}:
+					got = v[p]
+				case []interface{}:
+					i := int(p[0] - '0')
+					if i >= len(v) {
+						t.Fatalf("index %d is out of range of %v", i, v)
+					}
+					got = v[i]
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyPatch() %v = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseFilter(t *testing.T) {
+	tests := []struct {
+		filter  string
+		want    *filter
+		wantErr bool
+	}{
+		{``, nil, false},
+		{`userName eq "bob@example.com"`, &filter{"userName", "bob@example.com"}, false},
+		{`displayName EQ "Sales \"EU\""`, &filter{"displayName", `Sales "EU"`}, false},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "bob"`, &filter{"userName", "bob"}, false},
+		{`userName sw "bob"`, nil, true},
+		{`userName eq "bob" and active eq true`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			got, err := parseFilter(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/metrics"
+	"github.com/madappgang/identifo/model"
+	"github.com/urfave/negroni"
+)
+
+// Router is a router that handles SCIM 2.0 provisioning requests.
+type Router struct {
+	middleware          *negroni.Negroni
+	logger              *logging.Logger
+	router              *mux.Router
+	userStorage         model.UserStorage
+	organizationStorage model.OrganizationStorage
+	scimTokenStorage    model.SCIMTokenStorage
+	webhookService      model.WebhookService
+	Host                string
+	PathPrefix          string
+}
+
+func defaultOptions() []func(*Router) error {
+	return []func(*Router) error{
+		PathPrefixOptions("/scim/v2"),
+	}
+}
+
+// HostOption sets host value, used in resource locations.
+func HostOption(host string) func(*Router) error {
+	return func(r *Router) error {
+		r.Host = host
+		return nil
+	}
+}
+
+// PathPrefixOptions sets path prefix the router is mounted at, used in resource locations.
+func PathPrefixOptions(prefix string) func(*Router) error {
+	return func(r *Router) error {
+		r.PathPrefix = prefix
+		return nil
+	}
+}
+
+// NewRouter creates and initializes new SCIM router.
+func NewRouter(logger *logging.Logger, us model.UserStorage, ors model.OrganizationStorage, sts model.SCIMTokenStorage, whServ model.WebhookService, options ...func(*Router) error) (model.Router, error) {
+	ar := Router{
+		middleware:          negroni.New(),
+		router:              mux.NewRouter(),
+		userStorage:         us,
+		organizationStorage: ors,
+		scimTokenStorage:    sts,
+		webhookService:      whServ,
+	}
+
+	for _, option := range append(defaultOptions(), options...) {
+		if err := option(&ar); err != nil {
+			return nil, err
+		}
+	}
+
+	ar.logger = logger
+	if ar.logger == nil {
+		ar.logger = logging.Default().With("router", "scim")
+	}
+	ar.middleware.Use(logging.Recovery(ar.logger))
+
+	ar.initRoutes()
+	ar.router.Use(metrics.RouteMiddleware)
+	ar.middleware.UseHandler(ar.router)
+
+	return &ar, nil
+}
+
+// ServeHTTP implements identifo.Router interface.
+func (ar *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Reroute to our internal implementation.
+	ar.middleware.ServeHTTP(w, r)
+}
+
+// ServeJSON sends SCIM resource with the status code.
+func (ar *Router) ServeJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		ar.Error(w, http.StatusInternalServerError, "", "Unable to marshall response: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if _, err = w.Write(data); err != nil {
+		ar.logger.Errorf("error writing http response: %s", err)
+	}
+}
+
+// Error writes SCIM error response. ScimType is a SCIM error type, like "invalidFilter", if any.
+func (ar *Router) Error(w http.ResponseWriter, status int, scimType, detail string) {
+	ar.logger.Logf(logging.StatusLevel(status), "scim error: %s (status=%d). Details: %s.", scimType, status, detail)
+
+	// Hide error from client if it is internal.
+	if status == http.StatusInternalServerError {
+		detail = "Internal server error"
+	}
+
+	response := struct {
+		Schemas  []string `json:"schemas"`
+		ScimType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail,omitempty"`
+		Status   string   `json:"status"`
+	}{
+		Schemas:  []string{schemaError},
+		ScimType: scimType,
+		Detail:   detail,
+		Status:   strconv.Itoa(status),
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		ar.logger.Errorf("error writing http response: %s", err)
+	}
+}
+
+// parseJSON decodes SCIM request body, writing error response on failure.
+func (ar *Router) parseJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		ar.Error(w, http.StatusBadRequest, scimTypeInvalidSyntax, "Cannot parse request body: "+err.Error())
+		return err
+	}
+	return nil
+}
+
+// location returns the URL of the resource.
+func (ar *Router) location(endpoint, id string) string {
+	return ar.Host + ar.PathPrefix + endpoint + "/" + id
+}
@@ -0,0 +1,75 @@
+package scim
+
+import (
+	"github.com/urfave/negroni"
+)
+
+// Setup all routes for SCIM router.
+func (ar *Router) initRoutes() {
+	if ar.router == nil {
+		panic("Empty SCIM router")
+	}
+
+	ar.router.Handle("/ServiceProviderConfig", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.ServiceProviderConfig()),
+	)).Methods("GET")
+	ar.router.Handle("/ResourceTypes", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.ResourceTypes()),
+	)).Methods("GET")
+	ar.router.Handle("/Schemas", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.Schemas()),
+	)).Methods("GET")
+
+	ar.router.Handle("/Users", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.FetchUsers()),
+	)).Methods("GET")
+	ar.router.Handle("/Users", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.CreateUser()),
+	)).Methods("POST")
+	ar.router.Handle("/Users/{id}", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.GetUser()),
+	)).Methods("GET")
+	ar.router.Handle("/Users/{id}", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.ReplaceUser()),
+	)).Methods("PUT")
+	ar.router.Handle("/Users/{id}", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.PatchUser()),
+	)).Methods("PATCH")
+	ar.router.Handle("/Users/{id}", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.DeleteUser()),
+	)).Methods("DELETE")
+
+	ar.router.Handle("/Groups", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.FetchGroups()),
+	)).Methods("GET")
+	ar.router.Handle("/Groups", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.CreateGroup()),
+	)).Methods("POST")
+	ar.router.Handle("/Groups/{id}", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.GetGroup()),
+	)).Methods("GET")
+	ar.router.Handle("/Groups/{id}", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.ReplaceGroup()),
+	)).Methods("PUT")
+	ar.router.Handle("/Groups/{id}", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.PatchGroup()),
+	)).Methods("PATCH")
+	ar.router.Handle("/Groups/{id}", negroni.New(
+		ar.Token(),
+		negroni.WrapFunc(ar.DeleteGroup()),
+	)).Methods("DELETE")
+}
@@ -0,0 +1,245 @@
+package scim
+
+import (
+	"net/http"
+)
+
+const contentType = "application/scim+json"
+
+// SCIM schema URIs, RFC 7643 and RFC 7644.
+const (
+	schemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	schemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	schemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	schemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	schemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+)
+
+// SCIM error types.
+const (
+	scimTypeInvalidFilter = "invalidFilter"
+	scimTypeInvalidSyntax = "invalidSyntax"
+	scimTypeInvalidPath   = "invalidPath"
+	scimTypeInvalidValue  = "invalidValue"
+	scimTypeNoTarget      = "noTarget"
+	scimTypeUniqueness    = "uniqueness"
+	scimTypeMutability    = "mutability"
+)
+
+const (
+	defaultCount = 100
+	maxCount     = 200
+)
+
+type meta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location,omitempty"`
+}
+
+type listResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+func newListResponse(resources interface{}, total, startIndex, count int) listResponse {
+	return listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: count,
+		Resources:    resources,
+	}
+}
+
+// ServiceProviderConfig describes supported SCIM features.
+func (ar *Router) ServiceProviderConfig() http.HandlerFunc {
+	type supported struct {
+		Supported bool `json:"supported"`
+	}
+	type filter struct {
+		Supported  bool `json:"supported"`
+		MaxResults int  `json:"maxResults"`
+	}
+	type bulk struct {
+		Supported      bool `json:"supported"`
+		MaxOperations  int  `json:"maxOperations"`
+		MaxPayloadSize int  `json:"maxPayloadSize"`
+	}
+	type authenticationScheme struct {
+		Type        string `json:"type"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Primary     bool   `json:"primary"`
+	}
+
+	config := struct {
+		Schemas               []string               `json:"schemas"`
+		Patch                 supported              `json:"patch"`
+		Bulk                  bulk                   `json:"bulk"`
+		Filter                filter                 `json:"filter"`
+		ChangePassword        supported              `json:"changePassword"`
+		Sort                  supported              `json:"sort"`
+		ETag                  supported              `json:"etag"`
+		AuthenticationSchemes []authenticationScheme `json:"authenticationSchemes"`
+		Meta                  meta                   `json:"meta"`
+	}{
+		Schemas:        []string{schemaServiceProviderConfig},
+		Patch:          supported{true},
+		Filter:         filter{Supported: true, MaxResults: maxCount},
+		ChangePassword: supported{true},
+		AuthenticationSchemes: []authenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "OAuth Bearer Token",
+			Description: "Bearer token issued in the admin panel",
+			Primary:     true,
+		}},
+		Meta: meta{ResourceType: "ServiceProviderConfig"},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		config.Meta.Location = ar.Host + ar.PathPrefix + "/ServiceProviderConfig"
+		ar.ServeJSON(w, http.StatusOK, config)
+	}
+}
+
+type resourceType struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Endpoint    string   `json:"endpoint"`
+	Description string   `json:"description"`
+	Schema      string   `json:"schema"`
+	Meta        meta     `json:"meta"`
+}
+
+// ResourceTypes lists supported resource types.
+func (ar *Router) ResourceTypes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		types := []resourceType{
+			{
+				Schemas:     []string{schemaResourceType},
+				ID:          "User",
+				Name:        "User",
+				Endpoint:    "/Users",
+				Description: "User account",
+				Schema:      schemaUser,
+				Meta:        meta{ResourceType: "ResourceType", Location: ar.location("/ResourceTypes", "User")},
+			},
+			{
+				Schemas:     []string{schemaResourceType},
+				ID:          "Group",
+				Name:        "Group",
+				Endpoint:    "/Groups",
+				Description: "Group of users, stored as organization",
+				Schema:      schemaGroup,
+				Meta:        meta{ResourceType: "ResourceType", Location: ar.location("/ResourceTypes", "Group")},
+			},
+		}
+		ar.ServeJSON(w, http.StatusOK, newListResponse(types, len(types), 1, len(types)))
+	}
+}
+
+type schemaAttribute struct {
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	MultiValued   bool              `json:"multiValued"`
+	Required      bool              `json:"required"`
+	CaseExact     bool              `json:"caseExact"`
+	Mutability    string            `json:"mutability"`
+	Returned      string            `json:"returned"`
+	Uniqueness    string            `json:"uniqueness"`
+	SubAttributes []schemaAttribute `json:"subAttributes,omitempty"`
+}
+
+type schema struct {
+	Schemas     []string          `json:"schemas"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Attributes  []schemaAttribute `json:"attributes"`
+	Meta        meta              `json:"meta"`
+}
+
+func attribute(name, typ string, multiValued, required bool, mutability string, subAttributes ...schemaAttribute) schemaAttribute {
+	a := schemaAttribute{
+		Name:          name,
+		Type:          typ,
+		MultiValued:   multiValued,
+		Required:      required,
+		Mutability:    mutability,
+		Returned:      "default",
+		Uniqueness:    "none",
+		SubAttributes: subAttributes,
+	}
+	if typ == "reference" || name == "id" {
+		a.CaseExact = true
+	}
+	return a
+}
+
+// Schemas describes attributes of the supported resources.
+func (ar *Router) Schemas() http.HandlerFunc {
+	multiValue := []schemaAttribute{
+		attribute("value", "string", false, false, "readWrite"),
+		attribute("type", "string", false, false, "readWrite"),
+		attribute("primary", "boolean", false, false, "readWrite"),
+	}
+
+	userName := attribute("userName", "string", false, true, "readWrite")
+	userName.Uniqueness = "server"
+	password := attribute("password", "string", false, false, "writeOnly")
+	password.Returned = "never"
+
+	userAttributes := []schemaAttribute{
+		userName,
+		attribute("name", "complex", false, false, "readWrite",
+			attribute("formatted", "string", false, false, "readWrite"),
+			attribute("familyName", "string", false, false, "readWrite"),
+			attribute("givenName", "string", false, false, "readWrite"),
+		),
+		attribute("displayName", "string", false, false, "readWrite"),
+		attribute("active", "boolean", false, false, "readWrite"),
+		password,
+		attribute("emails", "complex", true, false, "readWrite", multiValue...),
+		attribute("phoneNumbers", "complex", true, false, "readWrite", multiValue...),
+		attribute("groups", "complex", true, false, "readOnly",
+			attribute("value", "string", false, false, "readOnly"),
+			attribute("display", "string", false, false, "readOnly"),
+		),
+	}
+	groupAttributes := []schemaAttribute{
+		attribute("displayName", "string", false, true, "readWrite"),
+		attribute("members", "complex", true, false, "readWrite",
+			attribute("value", "string", false, false, "immutable"),
+			attribute("display", "string", false, false, "readOnly"),
+		),
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		schemas := []schema{
+			{
+				Schemas:     []string{schemaSchema},
+				ID:          schemaUser,
+				Name:        "User",
+				Description: "User account",
+				Attributes:  userAttributes,
+				Meta:        meta{ResourceType: "Schema", Location: ar.location("/Schemas", schemaUser)},
+			},
+			{
+				Schemas:     []string{schemaSchema},
+				ID:          schemaGroup,
+				Name:        "Group",
+				Description: "Group of users",
+				Attributes:  groupAttributes,
+				Meta:        meta{ResourceType: "Schema", Location: ar.location("/Schemas", schemaGroup)},
+			},
+		}
+		ar.ServeJSON(w, http.StatusOK, newListResponse(schemas, len(schemas), 1, len(schemas)))
+	}
+}
@@ -0,0 +1,429 @@
+package scim
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/madappgang/identifo/model"
+)
+
+// scimMetadataKey is the user app metadata key which keeps SCIM attributes with no user field.
+const scimMetadataKey = "scim"
+
+type userName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+}
+
+type multiValue struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type reference struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type userResource struct {
+	Schemas      []string     `json:"schemas"`
+	ID           string       `json:"id,omitempty"`
+	ExternalID   string       `json:"externalId,omitempty"`
+	UserName     string       `json:"userName"`
+	Name         *userName    `json:"name,omitempty"`
+	DisplayName  string       `json:"displayName,omitempty"`
+	Active       *bool        `json:"active,omitempty"`
+	Password     string       `json:"password,omitempty"`
+	Emails       []multiValue `json:"emails,omitempty"`
+	PhoneNumbers []multiValue `json:"phoneNumbers,omitempty"`
+	Groups       []reference  `json:"groups,omitempty"`
+	Meta         *meta        `json:"meta,omitempty"`
+}
+
+// scimUserData are SCIM attributes stored in the user app metadata.
+type scimUserData struct {
+	ExternalID  string    `json:"externalId,omitempty"`
+	Name        *userName `json:"name,omitempty"`
+	DisplayName string    `json:"displayName,omitempty"`
+}
+
+// primaryValue returns the primary value of multi-valued attribute, or the first one.
+func primaryValue(values []multiValue) string {
+	for _, v := range values {
+		if v.Primary {
+			return v.Value
+		}
+	}
+	if len(values) > 0 {
+		return values[0].Value
+	}
+	return ""
+}
+
+func (ar *Router) userResource(user model.User, withGroups bool) userResource {
+	active := user.Active()
+	res := userResource{
+		Schemas:  []string{schemaUser},
+		ID:       user.ID(),
+		UserName: user.Username(),
+		Active:   &active,
+		Meta:     &meta{ResourceType: "User", Location: ar.location("/Users", user.ID())},
+	}
+
+	if stored, ok := user.AppMetadata()[scimMetadataKey]; ok {
+		sd := scimUserData{}
+		if data, err := json.Marshal(stored); err == nil && json.Unmarshal(data, &sd) == nil {
+			res.ExternalID, res.Name, res.DisplayName = sd.ExternalID, sd.Name, sd.DisplayName
+		}
+	}
+	if user.Email() != "" {
+		res.Emails = []multiValue{{Value: user.Email(), Type: "work", Primary: true}}
+	}
+	if user.Phone() != "" {
+		res.PhoneNumbers = []multiValue{{Value: user.Phone(), Type: "mobile", Primary: true}}
+	}
+
+	if withGroups {
+		memberships, err := ar.organizationStorage.UserMemberships(user.ID())
+		if err != nil {
+			ar.logger.Errorf("Cannot fetch memberships of user %s: %s", user.ID(), err)
+		}
+		for _, m := range memberships {
+			group := reference{Value: m.OrgID, Ref: ar.location("/Groups", m.OrgID)}
+			if org, err := ar.organizationStorage.OrganizationByID(m.OrgID); err == nil {
+				group.Display = org.Name
+			}
+			res.Groups = append(res.Groups, group)
+		}
+	}
+	return res
+}
+
+// applyUser saves resource attributes to the existing user.
+func (ar *Router) applyUser(user model.User, res userResource) (model.User, error) {
+	res.UserName = strings.TrimSpace(res.UserName)
+	if res.UserName == "" {
+		return nil, badRequest(scimTypeInvalidValue, "userName is required")
+	}
+	if res.UserName != user.Username() {
+		if !strings.EqualFold(res.UserName, user.Username()) && ar.userStorage.UserExists(res.UserName) {
+			return nil, &scimError{status: http.StatusConflict, scimType: scimTypeUniqueness, detail: "userName is already taken"}
+		}
+		user.SetUsername(res.UserName)
+	}
+
+	email := primaryValue(res.Emails)
+	if email != "" && !model.EmailRegexp.MatchString(email) {
+		return nil, badRequest(scimTypeInvalidValue, "Invalid email %q", email)
+	}
+	phone := primaryValue(res.PhoneNumbers)
+	if phone != "" && !model.PhoneRegexp.MatchString(phone) {
+		return nil, badRequest(scimTypeInvalidValue, "Invalid phone number %q", phone)
+	}
+	if res.Password != "" {
+		if err := model.StrongPswd(res.Password); err != nil {
+			return nil, badRequest(scimTypeInvalidValue, err.Error())
+		}
+	}
+
+	user.SetEmail(email)
+	if res.Active != nil {
+		user.SetActive(*res.Active)
+	}
+
+	metadata := map[string]interface{}{}
+	for k, v := range user.AppMetadata() {
+		metadata[k] = v
+	}
+	metadata[scimMetadataKey] = scimUserData{ExternalID: res.ExternalID, Name: res.Name, DisplayName: res.DisplayName}
+	user.SetAppMetadata(metadata)
+
+	oldPhone := user.Phone()
+	user, err := ar.userStorage.UpdateUser(user.ID(), user)
+	if err != nil {
+		return nil, err
+	}
+
+	if phone != oldPhone {
+		if phone == "" {
+			err = ar.userStorage.UnlinkPhone(user.ID())
+		} else {
+			err = ar.userStorage.LinkPhone(user.ID(), phone)
+		}
+		if err == model.ErrorUserExists {
+			return nil, &scimError{status: http.StatusConflict, scimType: scimTypeUniqueness, detail: "Phone number is already taken"}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if res.Password != "" {
+		if err = ar.userStorage.ResetPassword(user.ID(), res.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	return ar.userStorage.UserByID(user.ID())
+}
+
+// userByID fetches the user from route variable, writing error response on failure.
+func (ar *Router) userByID(w http.ResponseWriter, r *http.Request) (model.User, error) {
+	user, err := ar.userStorage.UserByID(mux.Vars(r)["id"])
+	if err == model.ErrUserNotFound {
+		ar.Error(w, http.StatusNotFound, "", "User not found")
+		return nil, err
+	}
+	if err != nil {
+		ar.Error(w, http.StatusInternalServerError, "", err.Error())
+		return nil, err
+	}
+	return user, nil
+}
+
+// FetchUsers lists users, optionally filtered by userName, email or id.
+func (ar *Router) FetchUsers() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		startIndex, count, err := pagination(r)
+		if err != nil {
+			ar.writeError(w, err)
+			return
+		}
+		f, err := parseFilter(r.URL.Query().Get("filter"))
+		if err != nil {
+			ar.writeError(w, err)
+			return
+		}
+
+		var users []model.User
+		var total int
+
+		switch {
+		case f == nil:
+			users, total, err = ar.userStorage.FetchUsers("", nil, startIndex-1, storageLimit(count))
+			if len(users) > count {
+				users = users[:count]
+			}
+		case strings.EqualFold(f.attribute, "userName"):
+			var u model.User
+			if u, err = ar.userStorage.UserByUsername(f.value); err == nil {
+				users = []model.User{u}
+			}
+		case strings.EqualFold(f.attribute, "emails"), strings.EqualFold(f.attribute, "emails.value"):
+			var u model.User
+			if u, err = ar.userStorage.UserByEmail(f.value); err == nil {
+				users = []model.User{u}
+			}
+		case strings.EqualFold(f.attribute, "id"):
+			var u model.User
+			if u, err = ar.userStorage.UserByID(f.value); err == nil {
+				users = []model.User{u}
+			}
+		default:
+			ar.Error(w, http.StatusBadRequest, scimTypeInvalidFilter, "Filtering by "+f.attribute+" is not supported")
+			return
+		}
+		if err == model.ErrUserNotFound {
+			err = nil
+		}
+		if err != nil {
+			ar.Error(w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+
+		if f != nil {
+			total = len(users)
+			from, to := page(total, startIndex, count)
+			users = users[from:to]
+		}
+
+		resources := make([]userResource, len(users))
+		for i, u := range users {
+			resources[i] = ar.userResource(u, false)
+		}
+		ar.ServeJSON(w, http.StatusOK, newListResponse(resources, total, startIndex, len(resources)))
+	}
+}
+
+// GetUser returns the user.
+func (ar *Router) GetUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := ar.userByID(w, r)
+		if err != nil {
+			return
+		}
+		ar.ServeJSON(w, http.StatusOK, ar.userResource(user, true))
+	}
+}
+
+// CreateUser provisions new user.
+func (ar *Router) CreateUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res := userResource{}
+		if ar.parseJSON(w, r, &res) != nil {
+			return
+		}
+		if res.UserName = strings.TrimSpace(res.UserName); res.UserName == "" {
+			ar.Error(w, http.StatusBadRequest, scimTypeInvalidValue, "userName is required")
+			return
+		}
+		if ar.userStorage.UserExists(res.UserName) {
+			ar.Error(w, http.StatusConflict, scimTypeUniqueness, "userName is already taken")
+			return
+		}
+
+		password := res.Password
+		if password != "" {
+			if err := model.StrongPswd(password); err != nil {
+				ar.Error(w, http.StatusBadRequest, scimTypeInvalidValue, err.Error())
+				return
+			}
+		} else {
+			// Provisioned users without password sign in with federated identity or reset the password.
+			b := make([]byte, 32)
+			if _, err := io.ReadFull(rand.Reader, b); err != nil {
+				ar.Error(w, http.StatusInternalServerError, "", err.Error())
+				return
+			}
+			password = base64.RawURLEncoding.EncodeToString(b)
+		}
+
+		user, err := ar.userStorage.AddUserByNameAndPassword(res.UserName, password, "", false)
+		if err == model.ErrorUserExists {
+			ar.Error(w, http.StatusConflict, scimTypeUniqueness, "userName is already taken")
+			return
+		}
+		if err != nil {
+			ar.Error(w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+
+		res.Password = ""
+		if user, err = ar.applyUser(user, res); err != nil {
+			ar.writeError(w, err)
+			return
+		}
+
+		ar.logger.WithRequest(r).Infof("User %s provisioned", user.ID())
+		ar.webhookService.Notify(model.WebhookEventUserCreated, "", user)
+		ar.ServeJSON(w, http.StatusCreated, ar.userResource(user, true))
+	}
+}
+
+// ReplaceUser replaces all user attributes.
+func (ar *Router) ReplaceUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		existing, err := ar.userByID(w, r)
+		if err != nil {
+			return
+		}
+		res := userResource{}
+		if ar.parseJSON(w, r, &res) != nil {
+			return
+		}
+		ar.updateUser(w, existing, res)
+	}
+}
+
+// PatchUser modifies user attributes with PATCH operations.
+func (ar *Router) PatchUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		existing, err := ar.userByID(w, r)
+		if err != nil {
+			return
+		}
+		p := patchRequest{}
+		if ar.parseJSON(w, r, &p) != nil {
+			return
+		}
+		if err = p.validate(); err != nil {
+			ar.writeError(w, err)
+			return
+		}
+
+		current, err := toMap(ar.userResource(existing, false))
+		if err != nil {
+			ar.Error(w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+		if err = applyPatch(current, p.Operations); err != nil {
+			ar.writeError(w, err)
+			return
+		}
+		// Some clients send booleans as strings, like "False".
+		key := attributeKey(current, "active")
+		if s, ok := current[key].(string); ok {
+			if current[key], err = strconv.ParseBool(s); err != nil {
+				ar.Error(w, http.StatusBadRequest, scimTypeInvalidValue, "Invalid active value "+s)
+				return
+			}
+		}
+
+		res := userResource{}
+		if err = fromMap(current, &res); err != nil {
+			ar.writeError(w, err)
+			return
+		}
+		ar.updateUser(w, existing, res)
+	}
+}
+
+func (ar *Router) updateUser(w http.ResponseWriter, existing model.User, res userResource) {
+	if res.ID != "" && res.ID != existing.ID() {
+		ar.Error(w, http.StatusBadRequest, scimTypeMutability, "id cannot be changed")
+		return
+	}
+	wasActive := existing.Active()
+
+	user, err := ar.applyUser(existing, res)
+	if err != nil {
+		ar.writeError(w, err)
+		return
+	}
+
+	ar.logger.Infof("User %s updated", user.ID())
+	ar.webhookService.Notify(model.WebhookEventUserUpdated, "", user)
+	if wasActive && !user.Active() {
+		ar.webhookService.Notify(model.WebhookEventUserDeactivated, "", user)
+	}
+	ar.ServeJSON(w, http.StatusOK, ar.userResource(user, true))
+}
+
+// DeleteUser deprovisions the user.
+func (ar *Router) DeleteUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := ar.userByID(w, r)
+		if err != nil {
+			return
+		}
+
+		if err = ar.userStorage.DeleteUser(user.ID()); err != nil {
+			ar.Error(w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+
+		// Deleted user cannot stay the member of organizations.
+		memberships, err := ar.organizationStorage.UserMemberships(user.ID())
+		if err != nil {
+			ar.logger.WithRequest(r).Errorf("Cannot fetch memberships of deleted user %s: %s", user.ID(), err)
+		}
+		for _, m := range memberships {
+			if err = ar.organizationStorage.RemoveMember(m.OrgID, user.ID()); err != nil {
+				ar.logger.WithRequest(r).Errorf("Cannot remove deleted user %s from organization %s: %s", user.ID(), m.OrgID, err)
+			}
+		}
+
+		ar.logger.WithRequest(r).Infof("User %s deprovisioned", user.ID())
+		ar.webhookService.Notify(model.WebhookEventUserDeleted, "", user)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
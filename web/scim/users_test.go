@@ -0,0 +1,71 @@
+package scim
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/madappgang/identifo/logging"
+	"github.com/madappgang/identifo/storage/boltdb"
+)
+
+func TestFetchUsersByUserName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "identifo-scim")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := boltdb.InitDB(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatalf("Error opening database: %s", err)
+	}
+	defer db.Close()
+
+	us, _ := boltdb.NewUserStorage(db)
+	us.AddUserByNameAndPassword("alice", "pass", "user", false)
+	us.AddUserByNameAndPassword("alice.smith", "pass", "user", false)
+	ar := &Router{logger: logging.New(ioutil.Discard, logging.LevelError, 0), userStorage: us, PathPrefix: "/scim/v2"}
+
+	tests := []struct {
+		userName string
+		expected []string
+	}{
+		{"alice", []string{"alice"}},
+		{"alice.smith", []string{"alice.smith"}},
+		{"alice.*", nil},
+		{"unknown", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.userName, func(t *testing.T) {
+			filter := url.QueryEscape(`userName eq "` + tt.userName + `"`)
+			rec := httptest.NewRecorder()
+			ar.FetchUsers()(rec, httptest.NewRequest(http.MethodGet, "/Users?filter="+filter, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Status = %d, expected %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+			}
+
+			var list struct {
+				TotalResults int `json:"totalResults"`
+				Resources    []struct {
+					UserName string `json:"userName"`
+				} `json:"Resources"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+				t.Fatalf("Error decoding response: %s", err)
+			}
+			if list.TotalResults != len(tt.expected) || len(list.Resources) != len(tt.expected) {
+				t.Fatalf("Found %+v, expected %v", list, tt.expected)
+			}
+			for i, userName := range tt.expected {
+				if list.Resources[i].UserName != userName {
+					t.Errorf("Found %q, expected %q", list.Resources[i].UserName, userName)
+				}
+			}
+		})
+	}
+}